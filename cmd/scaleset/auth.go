@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// clockSkewMarkers are fragments of the messages GitHub returns when it
+// rejects a GitHub App JWT because its iat/exp claims fall outside the
+// accepted window, which in practice means the local clock is off.
+var clockSkewMarkers = []string{
+	"'Issued at' claim ('iat') must be",
+	"'Expiration time' claim ('exp') is too far in the future",
+	"'Expiration time' claim ('exp') must be",
+}
+
+// isClockSkewError reports whether err is a GitHub App JWT rejection
+// caused by the iat/exp claims.
+func isClockSkewError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, marker := range clockSkewMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnoseAuthError annotates GitHub App authentication failures that
+// look like clock skew.  The scaleset SDK backdates the JWT by 60s and
+// gives it a 9 minute lifetime with no way to tune either, so a host
+// clock drifting further than that is rejected with an opaque 401; this
+// logs an explicit diagnostic and adds a hint to the returned error.
+// Other errors are returned unchanged.
+func diagnoseAuthError(err error, appAuth bool, logger *slog.Logger) error {
+	if !appAuth || !isClockSkewError(err) {
+		return err
+	}
+	logger.Error("GitHub App JWT rejected due to clock skew; check that the host clock is synchronized (e.g. NTP)",
+		slog.String("error", err.Error()),
+	)
+	return fmt.Errorf("%w (possible clock skew: GitHub rejected the App JWT issued-at/expiry claims; synchronize the host clock)", err)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errClockSkew = errors.New(`failed to get access token for GitHub App auth (401 Unauthorized): {"message":"'Issued at' claim ('iat') must be an Integer representing a time in the past.","documentation_url":"https://docs.github.com/rest"}`)
+
+func TestDiagnoseAuthError_ClockSkew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	api := &mockScaleSetAPI{createErrs: []error{errClockSkew}}
+	_, err := ensureScaleSet(context.Background(), api, testDesiredScaleSet(), 3, time.Millisecond, logger)
+	require.Error(t, err)
+
+	err = diagnoseAuthError(err, true, logger)
+	assert.ErrorIs(t, err, errClockSkew)
+	assert.Contains(t, err.Error(), "possible clock skew")
+	assert.Contains(t, buf.String(), "clock skew")
+	assert.Contains(t, buf.String(), "level=ERROR")
+}
+
+func TestDiagnoseAuthError_ExpiryTooFarInFuture(t *testing.T) {
+	err := errors.New(`401 Unauthorized: {"message":"'Expiration time' claim ('exp') is too far in the future"}`)
+	assert.True(t, isClockSkewError(err))
+}
+
+func TestDiagnoseAuthError_PassesThroughOtherErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	other := errors.New("401 Unauthorized: Bad credentials")
+	assert.Same(t, other, diagnoseAuthError(other, true, logger))
+	assert.Empty(t, buf.String())
+}
+
+func TestDiagnoseAuthError_IgnoredForPAT(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	assert.Same(t, errClockSkew, diagnoseAuthError(errClockSkew, false, logger))
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+var (
+	cleanupRunID  string
+	cleanupDryRun bool
+)
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Destroy runner resources left behind by a crashed run",
+	Long: `cleanup destroys scaleset-managed runner resources (containers, VMs)
+in the configured engine.  Each scaleset process logs its run ID at
+startup ("runID" in "configuration loaded"); pass it with --run-id to
+reclaim only the runners of a process that exited without shutting down
+cleanly.  Without --run-id every managed runner is destroyed, including
+those of processes that are still running.
+
+Resources without the scaleset-managed label are never touched.  The
+engine is taken from the same configuration file as a normal run; with
+scale_sets, the engine of every entry is cleaned up.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+		return runCleanup(ctx, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	f := cleanupCmd.Flags()
+	f.StringVar(&cfgPath, "config", "config.yaml", "Path to YAML configuration file")
+	f.StringVar(&cleanupRunID, "run-id", "", "Only destroy runner resources of this run ID (default: all managed runners)")
+	f.BoolVar(&cleanupDryRun, "dry-run", false, "List the runner resources that would be destroyed without destroying them")
+
+	rootCmd.AddCommand(cleanupCmd)
+}
+
+func runCleanup(ctx context.Context, out io.Writer) error {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return fmt.Errorf("loading config from environment: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	logger, err := cfg.NewLogger()
+	if err != nil {
+		return fmt.Errorf("creating logger: %w", err)
+	}
+
+	// With scale_sets, each entry's engine is cleaned up; entries with
+	// the same engine settings share it.
+	var (
+		total cleanupResult
+		errs  []error
+	)
+	for _, sc := range distinctEngines(cfg.ScaleSetConfigs()) {
+		res, err := cleanupEngine(ctx, sc, logger)
+		total.Found += res.Found
+		total.Destroyed += res.Destroyed
+		total.Failed += res.Failed
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if total.Found > 0 || len(errs) == 0 {
+		fmt.Fprintf(out, "found %d, destroyed %d, failed %d\n", total.Found, total.Destroyed, total.Failed)
+	}
+	return errors.Join(errs...)
+}
+
+// cleanupEngine runs cleanupRunners against the engine of cfg.
+func cleanupEngine(ctx context.Context, cfg *config.Config, logger *slog.Logger) (cleanupResult, error) {
+	eng, err := cfg.NewEngine(ctx, logger)
+	if err != nil {
+		return cleanupResult{}, fmt.Errorf("initializing engine: %w", err)
+	}
+	// Nothing is tracked, so Shutdown only releases the engine's clients.
+	defer eng.Shutdown(context.WithoutCancel(ctx))
+
+	return cleanupRunners(ctx, eng, cleanupRunID, cleanupDryRun, logger)
+}
+
+// cleanupResult counts the runner resources handled by cleanupRunners.
+type cleanupResult struct {
+	Found     int
+	Destroyed int
+	Failed    int
+}
+
+// cleanupRunners destroys every scaleset-managed runner resource, or
+// only those of runID when it is set.  A failure to destroy one runner
+// does not stop the others; all failures are returned joined.  With
+// dryRun set the runners are only listed.
+func cleanupRunners(ctx context.Context, eng engine.Engine, runID string, dryRun bool, logger *slog.Logger) (cleanupResult, error) {
+	var res cleanupResult
+
+	lister, ok := eng.(engine.RunnerLister)
+	if !ok {
+		return res, fmt.Errorf("engine does not support listing runners")
+	}
+
+	runners, err := lister.ListRunners(ctx, engine.RunnerLabels(runID))
+	if err != nil {
+		return res, fmt.Errorf("listing runners: %w", err)
+	}
+
+	var errs []error
+	for _, r := range runners {
+		// The engine filters by label already; check again so a lister
+		// that over-matches can never destroy unmanaged resources.
+		if r.Labels[engine.ManagedLabel] != "true" || (runID != "" && r.Labels[engine.RunIDLabel] != runID) {
+			continue
+		}
+		res.Found++
+
+		attrs := []any{
+			slog.String("name", r.Name),
+			slog.String("id", r.ID),
+			slog.String("runID", r.Labels[engine.RunIDLabel]),
+		}
+		if dryRun {
+			logger.Info("would destroy leftover runner", attrs...)
+			continue
+		}
+		logger.Info("destroying leftover runner", attrs...)
+		if err := eng.DestroyRunner(ctx, r.ID); err != nil {
+			res.Failed++
+			errs = append(errs, fmt.Errorf("destroying runner %s: %w", r.Name, err))
+			continue
+		}
+		res.Destroyed++
+	}
+
+	logger.Info("cleanup complete",
+		slog.String("runID", runID),
+		slog.Bool("dryRun", dryRun),
+		slog.Int("found", res.Found),
+		slog.Int("destroyed", res.Destroyed),
+		slog.Int("failed", res.Failed),
+	)
+	return res, errors.Join(errs...)
+}
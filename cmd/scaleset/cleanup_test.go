@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// mockListerEngine holds a fixed set of resources and implements
+// engine.RunnerLister by matching their labels.
+type mockListerEngine struct {
+	runners    []engine.ListedRunner
+	destroyed  []string
+	destroyErr map[string]error
+}
+
+func (m *mockListerEngine) StartRunner(context.Context, string, string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *mockListerEngine) DestroyRunner(_ context.Context, id string) error {
+	if err := m.destroyErr[id]; err != nil {
+		return err
+	}
+	m.destroyed = append(m.destroyed, id)
+	return nil
+}
+
+func (m *mockListerEngine) Shutdown(context.Context) error { return nil }
+
+func (m *mockListerEngine) ListRunners(_ context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	var out []engine.ListedRunner
+	for _, r := range m.runners {
+		match := true
+		for k, v := range labels {
+			if r.Labels[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+type engineOnly struct{ engine.Engine }
+
+// unmanaged returns labels of a resource scaleset did not create.
+func unmanaged() map[string]string {
+	return map[string]string{"app": "database"}
+}
+
+func mixedEngine() *mockListerEngine {
+	return &mockListerEngine{runners: []engine.ListedRunner{
+		{ID: "a", Name: "runner-a", Labels: engine.RunnerLabels("crashed")},
+		{ID: "b", Name: "runner-b", Labels: engine.RunnerLabels("crashed")},
+		{ID: "c", Name: "runner-c", Labels: engine.RunnerLabels("live")},
+		{ID: "d", Name: "postgres", Labels: unmanaged()},
+		{ID: "e", Name: "unlabelled"},
+	}}
+}
+
+func TestCleanupRunners_DestroysRunnersOfRunID(t *testing.T) {
+	eng := mixedEngine()
+
+	res, err := cleanupRunners(context.Background(), eng, "crashed", false, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, cleanupResult{Found: 2, Destroyed: 2}, res)
+	assert.ElementsMatch(t, []string{"a", "b"}, eng.destroyed)
+}
+
+func TestCleanupRunners_WithoutRunIDDestroysOnlyManaged(t *testing.T) {
+	eng := mixedEngine()
+
+	res, err := cleanupRunners(context.Background(), eng, "", false, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, cleanupResult{Found: 3, Destroyed: 3}, res)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, eng.destroyed)
+}
+
+// overMatchingEngine ignores the label filter and lists everything.
+type overMatchingEngine struct{ *mockListerEngine }
+
+func (m overMatchingEngine) ListRunners(context.Context, map[string]string) ([]engine.ListedRunner, error) {
+	return m.runners, nil
+}
+
+func TestCleanupRunners_IgnoresUnmanagedFromOverMatchingLister(t *testing.T) {
+	eng := mixedEngine()
+
+	res, err := cleanupRunners(context.Background(), overMatchingEngine{eng}, "crashed", false, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Found)
+	assert.ElementsMatch(t, []string{"a", "b"}, eng.destroyed)
+}
+
+func TestCleanupRunners_DryRunDestroysNothing(t *testing.T) {
+	eng := mixedEngine()
+
+	res, err := cleanupRunners(context.Background(), eng, "", true, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, cleanupResult{Found: 3}, res)
+	assert.Empty(t, eng.destroyed)
+}
+
+func TestCleanupRunners_ContinuesPastFailures(t *testing.T) {
+	eng := mixedEngine()
+	eng.destroyErr = map[string]error{"a": errors.New("permission denied")}
+
+	res, err := cleanupRunners(context.Background(), eng, "crashed", false, discardLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "runner-a")
+	assert.Equal(t, cleanupResult{Found: 2, Destroyed: 1, Failed: 1}, res)
+	assert.Equal(t, []string{"b"}, eng.destroyed)
+}
+
+func TestCleanupRunners_EngineWithoutLister(t *testing.T) {
+	_, err := cleanupRunners(context.Background(), engineOnly{}, "crashed", false, discardLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support listing runners")
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/terrpan/scaleset/internal/admin"
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+var drainWait bool
+
+var drainCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "Drain a running scaleset process and make it exit",
+	Long: `drain asks the scaleset process running with the same configuration to
+drain gracefully, through its admin API (admin.enable must be set): the
+process stops taking jobs and starting runners, destroys its idle
+runners, waits for the busy ones to finish their jobs and exits.
+Sending the process SIGUSR1 does the same.
+
+With --wait, drain reports the busy runners left until the process has
+exited.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+		return runDrain(ctx, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	f := drainCmd.Flags()
+	f.StringVar(&cfgPath, "config", "config.yaml", "Path to YAML configuration file")
+	f.BoolVar(&drainWait, "wait", false, "Wait until the process has exited")
+
+	rootCmd.AddCommand(drainCmd)
+}
+
+// drainPollInterval is how often drain --wait polls the process.
+var drainPollInterval = 2 * time.Second
+
+func runDrain(ctx context.Context, out io.Writer) error {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return fmt.Errorf("loading config from environment: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if !cfg.Admin.Enable {
+		return errors.New("drain needs the admin API (admin.enable); send the process SIGUSR1 instead")
+	}
+	return requestDrain(ctx, http.DefaultClient, adminURL(&cfg.Admin), cfg.Admin.Token, drainWait, out)
+}
+
+// adminURL returns the base URL of the admin API configured by a.  An
+// unspecified listen address is reached through the loopback interface.
+func adminURL(a *config.AdminConfig) string {
+	host := a.Address
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(a.Port))
+}
+
+// requestDrain starts a graceful drain through the admin API at base and,
+// with wait, polls the scale sets until the process stops answering.
+func requestDrain(ctx context.Context, client *http.Client, base, token string, wait bool, out io.Writer) error {
+	resp, err := adminRequest(ctx, client, http.MethodPost, base+"/drain", token)
+	if err != nil {
+		return fmt.Errorf("requesting drain: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("requesting drain: %w", adminError(resp))
+	}
+	_, _ = fmt.Fprintln(out, "draining")
+	if !wait {
+		return nil
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	lastBusy := -1
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		resp, err := adminRequest(ctx, client, http.MethodGet, base+"/scale-sets", token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The admin API shuts down with the process.
+			_, _ = fmt.Fprintln(out, "drained, process exited")
+			return nil
+		}
+		var scaleSets []admin.ScaleSetResponse
+		err = json.NewDecoder(resp.Body).Decode(&scaleSets)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("reading scale sets: %w", err)
+		}
+		busy := 0
+		for _, ss := range scaleSets {
+			busy += ss.Busy
+		}
+		if busy != lastBusy {
+			_, _ = fmt.Fprintf(out, "waiting for %d busy runner(s)\n", busy)
+			lastBusy = busy
+		}
+	}
+}
+
+// adminRequest sends a request without a body to the admin API.
+func adminRequest(ctx context.Context, client *http.Client, method, url, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return client.Do(req)
+}
+
+// adminError returns the error of a failed admin API response.
+func adminError(resp *http.Response) error {
+	var body admin.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		return fmt.Errorf("admin API: %s", resp.Status)
+	}
+	return fmt.Errorf("admin API: %s: %s", resp.Status, body.Error)
+}
+
+// errDrained is the cancellation cause once a graceful drain finished.
+var errDrained = errors.New("graceful drain finished")
+
+// idleDestroyer is the subset of *scaler.Scaler a graceful drain uses
+// besides Drain.
+type idleDestroyer interface {
+	DestroyIdleRunners(ctx context.Context) (int, error)
+}
+
+// capacityGate passes the capacity the listener reports to GitHub
+// through until a graceful drain closes it.  From then on the capacity
+// stays 0, so GitHub assigns no more jobs to the scale set, whatever a
+// reload, a schedule or the admin API sets.
+type capacityGate struct {
+	listener maxRunnersSetter
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (g *capacityGate) SetMaxRunners(count int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.closed {
+		g.listener.SetMaxRunners(count)
+	}
+}
+
+// close sets the capacity to 0 for good.
+func (g *capacityGate) close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+	g.listener.SetMaxRunners(0)
+}
+
+// gracefulDrain drains the process for an upgrade without failing jobs:
+// it stops taking jobs and starting runners, destroys the idle runners,
+// waits for the busy ones to finish their jobs and then shuts the
+// process down as on an interrupt.  Unlike the health /drain endpoint,
+// which only waits, it does not need anything else to stop the process.
+type gracefulDrain struct {
+	// ctx bounds the destroys and the wait; cancel shuts the process
+	// down.
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	drains *drainGroup
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	started bool
+	targets []drainTarget
+}
+
+// drainTarget is a running scale set a graceful drain applies to.
+type drainTarget struct {
+	scaler idleDestroyer
+	gate   *capacityGate
+}
+
+// withGracefulDrain returns a context that is cancelled with cause
+// errDrained once a graceful drain started on the returned gracefulDrain
+// has finished, draining the scalers of drains.  The returned cancel
+// func must be called.
+func withGracefulDrain(ctx context.Context, drains *drainGroup, logger *slog.Logger) (context.Context, *gracefulDrain, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancelCause(ctx)
+	g := &gracefulDrain{ctx: ctx, cancel: cancel, drains: drains, logger: logger}
+	return drainCtx, g, func() { cancel(nil) }
+}
+
+// run starts the drain when sig receives (SIGUSR1), until ctx is done.
+func (g *gracefulDrain) run(ctx context.Context, sig <-chan os.Signal) {
+	select {
+	case <-ctx.Done():
+	case <-sig:
+		g.start("signal")
+	}
+}
+
+// add makes a running scale set subject to the drain, draining it at
+// once if the drain has started.
+func (g *gracefulDrain) add(s idleDestroyer, gate *capacityGate) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t := drainTarget{scaler: s, gate: gate}
+	g.targets = append(g.targets, t)
+	if g.started {
+		gate.close()
+		go g.destroyIdle(t)
+	}
+}
+
+// start starts the drain, triggered by trigger (for the log); it does
+// not wait for it.  Calling it again has no effect.
+func (g *gracefulDrain) start(trigger string) {
+	g.mu.Lock()
+	if g.started {
+		g.mu.Unlock()
+		return
+	}
+	g.started = true
+	targets := slices.Clone(g.targets)
+	g.mu.Unlock()
+
+	g.logger.Info("graceful drain started: not taking new jobs, destroying idle runners and exiting once busy runners finish",
+		slog.String("trigger", trigger),
+	)
+	for _, t := range targets {
+		t.gate.close()
+	}
+	drained := g.drains.Drain()
+	go func() {
+		for _, t := range targets {
+			g.destroyIdle(t)
+		}
+		select {
+		case <-drained:
+		case <-g.ctx.Done():
+			return
+		}
+		g.logger.Info("graceful drain finished, shutting down")
+		g.cancel(errDrained)
+	}()
+}
+
+// destroyIdle destroys the idle runners of t.  A runner that cannot be
+// destroyed is left to the shutdown, which retries it.
+func (g *gracefulDrain) destroyIdle(t drainTarget) {
+	if _, err := t.scaler.DestroyIdleRunners(g.ctx); err != nil {
+		g.logger.Error("graceful drain: destroying idle runners",
+			slog.String("error", err.Error()),
+		)
+	}
+}
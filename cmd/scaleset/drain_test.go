@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/admin"
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+type fakeIdleDestroyer struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (f *fakeIdleDestroyer) DestroyIdleRunners(context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return 1, f.err
+}
+
+func (f *fakeIdleDestroyer) destroyCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestCapacityGate(t *testing.T) {
+	l := &fakeLimits{}
+	g := &capacityGate{listener: l}
+
+	g.SetMaxRunners(5)
+	assert.Equal(t, 5, l.listenerMax)
+
+	g.close()
+	assert.Equal(t, 0, l.listenerMax)
+	g.SetMaxRunners(8)
+	assert.Equal(t, 0, l.listenerMax, "a closed gate keeps the capacity at 0")
+}
+
+func TestGracefulDrain_ExitsOnceDrained(t *testing.T) {
+	drains := &drainGroup{}
+	drainer := &fakeDrainer{drained: make(chan struct{})}
+	drains.add(drainer)
+	ctx, g, stop := withGracefulDrain(context.Background(), drains, discardLogger())
+	defer stop()
+
+	scaler := &fakeIdleDestroyer{err: errors.New("backend down")}
+	listener := &fakeLimits{listenerMax: 5}
+	g.add(scaler, &capacityGate{listener: listener})
+
+	g.start("test")
+	g.start("test")
+	assert.Equal(t, 1, drainer.calls)
+	assert.Equal(t, 0, listener.listenerMax)
+	require.Eventually(t, func() bool { return scaler.destroyCalls() == 1 }, time.Second, time.Millisecond,
+		"a failed destroy is logged and left to the shutdown")
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("exited while runners are busy")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(drainer.drained)
+	select {
+	case <-ctx.Done():
+		assert.ErrorIs(t, context.Cause(ctx), errDrained)
+	case <-time.After(time.Second):
+		t.Fatal("not exited once drained")
+	}
+}
+
+func TestGracefulDrain_DrainsScaleSetsAddedLater(t *testing.T) {
+	_, g, stop := withGracefulDrain(context.Background(), &drainGroup{}, discardLogger())
+	defer stop()
+	g.start("test")
+
+	scaler := &fakeIdleDestroyer{}
+	listener := &fakeLimits{listenerMax: 5}
+	g.add(scaler, &capacityGate{listener: listener})
+	assert.Equal(t, 0, listener.listenerMax)
+	require.Eventually(t, func() bool { return scaler.destroyCalls() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestGracefulDrain_RunStartsOnSignal(t *testing.T) {
+	drains := &drainGroup{}
+	ctx, g, stop := withGracefulDrain(context.Background(), drains, discardLogger())
+	defer stop()
+	sig := make(chan os.Signal, 1)
+	go g.run(ctx, sig)
+
+	sig <- syscall.SIGUSR1
+	select {
+	case <-ctx.Done():
+		assert.ErrorIs(t, context.Cause(ctx), errDrained, "nothing to drain exits at once")
+	case <-time.After(time.Second):
+		t.Fatal("drain not started")
+	}
+}
+
+func TestAdminURL(t *testing.T) {
+	assert.Equal(t, "http://127.0.0.1:9092", adminURL(&config.AdminConfig{Address: "127.0.0.1", Port: 9092}))
+	assert.Equal(t, "http://127.0.0.1:9000", adminURL(&config.AdminConfig{Address: "0.0.0.0", Port: 9000}))
+	assert.Equal(t, "http://[::1]:9000", adminURL(&config.AdminConfig{Address: "::1", Port: 9000}))
+	assert.Equal(t, "http://scaleset.internal:9000", adminURL(&config.AdminConfig{Address: "scaleset.internal", Port: 9000}))
+}
+
+func TestRequestDrain_Wait(t *testing.T) {
+	drainPollInterval = time.Millisecond
+	t.Cleanup(func() { drainPollInterval = 2 * time.Second })
+
+	var (
+		mu   sync.Mutex
+		busy = 2
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer s3cret", req.Header.Get("Authorization"))
+		switch req.Method + " " + req.URL.Path {
+		case "POST /drain":
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(admin.DrainResponse{Status: "draining"})
+		case "GET /scale-sets":
+			mu.Lock()
+			defer mu.Unlock()
+			if busy < 0 {
+				panic(http.ErrAbortHandler) // the process has exited
+			}
+			_ = json.NewEncoder(w).Encode([]admin.ScaleSetResponse{{Name: "ci", Busy: busy, Draining: true}})
+			busy--
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, requestDrain(context.Background(), srv.Client(), srv.URL, "s3cret", true, &out))
+	assert.Equal(t, "draining\n"+
+		"waiting for 2 busy runner(s)\n"+
+		"waiting for 1 busy runner(s)\n"+
+		"waiting for 0 busy runner(s)\n"+
+		"drained, process exited\n", out.String())
+}
+
+func TestRequestDrain_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(admin.ErrorResponse{Error: "missing or invalid bearer token"})
+	}))
+	defer srv.Close()
+
+	err := requestDrain(context.Background(), srv.Client(), srv.URL, "", false, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Equal(t, "requesting drain: admin API: 401 Unauthorized: missing or invalid bearer token", err.Error())
+
+	srv.Close()
+	err = requestDrain(context.Background(), srv.Client(), srv.URL, "", false, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requesting drain:")
+}
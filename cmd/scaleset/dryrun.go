@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/actions/scaleset"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/engine/dryrun"
+)
+
+// dryRun replaces the engine with one that only logs, and the runner
+// registrations with placeholders, so that a configuration can be tried
+// against a real repository without creating runners.  The scale set
+// and its message session are real: jobs for its labels are assigned to
+// it and wait.
+var dryRun bool
+
+// newDryRunEngine returns the engine standing in for the one cfg
+// configures.
+func newDryRunEngine(cfg *config.Config, logger *slog.Logger) engine.Engine {
+	return dryrun.New(dryrun.Config{
+		Engine:       cfg.Engine.EnabledEngine(),
+		RunID:        cfg.ScaleSet.RunID,
+		ScaleSetName: cfg.ScaleSet.Name,
+		Logger:       logger,
+	})
+}
+
+// dryRunJIT hands out JIT configs without registering runners with
+// GitHub, which would leave offline runners behind.
+type dryRunJIT struct {
+	lastID atomic.Int64
+}
+
+func (d *dryRunJIT) GenerateJitRunnerConfig(_ context.Context, setting *scaleset.RunnerScaleSetJitRunnerSetting, scaleSetID int) (*scaleset.RunnerScaleSetJitRunnerConfig, error) {
+	return &scaleset.RunnerScaleSetJitRunnerConfig{
+		Runner: &scaleset.RunnerReference{
+			ID:               int(d.lastID.Add(1)),
+			Name:             setting.Name,
+			RunnerScaleSetID: scaleSetID,
+		},
+		EncodedJITConfig: "dry-run",
+	}, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+func TestDryRunJIT(t *testing.T) {
+	var jit dryRunJIT
+	for i, name := range []string{"runner-a", "runner-b"} {
+		cfg, err := jit.GenerateJitRunnerConfig(context.Background(), &scaleset.RunnerScaleSetJitRunnerSetting{Name: name}, 7)
+		require.NoError(t, err)
+		assert.Equal(t, &scaleset.RunnerReference{ID: i + 1, Name: name, RunnerScaleSetID: 7}, cfg.Runner)
+		assert.NotEmpty(t, cfg.EncodedJITConfig)
+	}
+}
+
+func TestNewDryRunEngine_CreatesNothing(t *testing.T) {
+	cfg := &config.Config{
+		ScaleSet: config.ScaleSetConfig{Name: "linux", RunID: "run-1"},
+		Engine:   config.EngineConfig{Docker: config.DockerEngineConfig{Enable: true, Image: "runner"}},
+	}
+	eng := newDryRunEngine(cfg, discardLogger())
+	id, err := eng.StartRunner(context.Background(), "runner-a", "dry-run")
+	require.NoError(t, err)
+
+	listed, err := eng.(engine.RunnerLister).ListRunners(context.Background(), engine.RunnerLabels("run-1"))
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, id, listed[0].ID)
+	details, err := eng.(engine.Checker).Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "docker", details["engine"])
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// minGHESVersion is the oldest GitHub Enterprise Server release that
+// offers runner scale sets.
+var minGHESVersion = [2]int{3, 9}
+
+// ghesMetaTimeout bounds the server version request.
+const ghesMetaTimeout = 10 * time.Second
+
+// errNotGHES is returned by ghesVersion when the server answers like
+// GitHub.com, without an Enterprise Server version.
+var errNotGHES = errors.New("server does not report a GitHub Enterprise Server version")
+
+// ghesVersion returns the release of the GitHub Enterprise Server whose
+// REST API is at apiURL.  Every GHES API response carries the
+// X-GitHub-Enterprise-Version header, even one refused for lack of
+// authentication (private mode), so the unauthenticated /meta request is
+// enough; the installed_version field of /meta is the fallback.
+func ghesVersion(ctx context.Context, client *http.Client, apiURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ghesMetaTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(apiURL, "/")+"/meta", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("requesting %s: %w", req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if v := resp.Header.Get("X-GitHub-Enterprise-Version"); v != "" {
+		return v, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("requesting %s: %s", req.URL, resp.Status)
+	}
+	var meta struct {
+		InstalledVersion string `json:"installed_version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return "", fmt.Errorf("decoding %s: %w", req.URL, err)
+	}
+	if meta.InstalledVersion == "" {
+		return "", errNotGHES
+	}
+	return meta.InstalledVersion, nil
+}
+
+// checkGHESVersion reports whether version (e.g. "3.12.4") is at least
+// minGHESVersion.
+func checkGHESVersion(version string) error {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return fmt.Errorf("unrecognised GitHub Enterprise Server version %q", version)
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("unrecognised GitHub Enterprise Server version %q", version)
+	}
+	if major < minGHESVersion[0] || major == minGHESVersion[0] && minor < minGHESVersion[1] {
+		return fmt.Errorf("GitHub Enterprise Server %s does not support runner scale sets; %d.%d or later is required", version, minGHESVersion[0], minGHESVersion[1])
+	}
+	return nil
+}
+
+// verifyGHES checks the server version when cfg registers with a GitHub
+// Enterprise Server.  A server that is too old is an error.  A version
+// that cannot be determined is an error only with github.enterprise set;
+// otherwise it is logged and the scale set API reports any real problem.
+// It returns the version, or "" for GitHub-hosted servers.
+func verifyGHES(ctx context.Context, cfg *config.Config, client *http.Client, logger *slog.Logger) (string, error) {
+	if !cfg.GitHub.IsEnterprise() {
+		return "", nil
+	}
+
+	apiURL := cfg.GitHub.ResolveAPIURL()
+	version, err := ghesVersion(ctx, client, apiURL)
+	if err != nil {
+		if cfg.GitHub.Enterprise {
+			return "", fmt.Errorf("github.enterprise: checking the server version: %w", err)
+		}
+		logger.Warn("could not determine the GitHub Enterprise Server version",
+			slog.String("apiURL", apiURL),
+			slog.String("error", err.Error()),
+		)
+		return "", nil
+	}
+	if err := checkGHESVersion(version); err != nil {
+		return "", err
+	}
+	logger.Info("GitHub Enterprise Server detected",
+		slog.String("apiURL", apiURL),
+		slog.String("version", version),
+	)
+	return version, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// ghesServer serves /api/v3/meta with status, reporting the version in
+// the X-GitHub-Enterprise-Version header and in installed_version when
+// they are set.  With neither it answers like GitHub.com.
+func ghesServer(t *testing.T, status int, header, installed string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/meta", r.URL.Path)
+		if header != "" {
+			w.Header().Set("X-GitHub-Enterprise-Version", header)
+		}
+		w.WriteHeader(status)
+		if installed != "" {
+			_, _ = io.WriteString(w, `{"installed_version":"`+installed+`"}`)
+		} else {
+			_, _ = io.WriteString(w, `{"verifiable_password_authentication":true}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGHESVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		header    string
+		installed string
+		want      string
+		wantErr   string
+	}{
+		{name: "header", status: http.StatusOK, header: "3.14.2", installed: "3.14.2", want: "3.14.2"},
+		{name: "header on private mode 401", status: http.StatusUnauthorized, header: "3.12.0", want: "3.12.0"},
+		{name: "installed_version", status: http.StatusOK, installed: "3.10.1", want: "3.10.1"},
+		{name: "not GHES", status: http.StatusOK, wantErr: errNotGHES.Error()},
+		{name: "error status", status: http.StatusBadGateway, wantErr: "502"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := ghesServer(t, tt.status, tt.header, tt.installed)
+			got, err := ghesVersion(context.Background(), srv.Client(), srv.URL+"/api/v3/")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckGHESVersion(t *testing.T) {
+	for _, v := range []string{"3.9.0", "3.10.4", "3.14", "4.0.0"} {
+		assert.NoError(t, checkGHESVersion(v), v)
+	}
+	for _, v := range []string{"3.8.9", "2.22.0", "3", "latest"} {
+		assert.Error(t, checkGHESVersion(v), v)
+	}
+}
+
+func TestVerifyGHES(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ghesConfig := func(url string, enterprise bool) *config.Config {
+		return &config.Config{GitHub: config.GitHubConfig{URL: url + "/my-org", Enterprise: enterprise}}
+	}
+
+	t.Run("hosted is not checked", func(t *testing.T) {
+		version, err := verifyGHES(context.Background(), ghesConfig("https://github.com", false), nil, logger)
+		require.NoError(t, err)
+		assert.Empty(t, version)
+	})
+
+	t.Run("supported", func(t *testing.T) {
+		srv := ghesServer(t, http.StatusOK, "3.13.1", "")
+		version, err := verifyGHES(context.Background(), ghesConfig(srv.URL, false), srv.Client(), logger)
+		require.NoError(t, err)
+		assert.Equal(t, "3.13.1", version)
+	})
+
+	t.Run("too old", func(t *testing.T) {
+		srv := ghesServer(t, http.StatusOK, "3.7.2", "")
+		_, err := verifyGHES(context.Background(), ghesConfig(srv.URL, false), srv.Client(), logger)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3.9 or later is required")
+	})
+
+	t.Run("unknown version only warns", func(t *testing.T) {
+		srv := ghesServer(t, http.StatusOK, "", "")
+		version, err := verifyGHES(context.Background(), ghesConfig(srv.URL, false), srv.Client(), logger)
+		require.NoError(t, err)
+		assert.Empty(t, version)
+	})
+
+	t.Run("unknown version fails with enterprise", func(t *testing.T) {
+		srv := ghesServer(t, http.StatusOK, "", "")
+		_, err := verifyGHES(context.Background(), ghesConfig(srv.URL, true), srv.Client(), logger)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "github.enterprise")
+	})
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/pprof"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// healthRoutes are the handlers of the health server.
+type healthRoutes struct {
+	healthz http.Handler
+	readyz  http.Handler
+	drain   http.Handler
+}
+
+// httpServer is the mux of one listening port and the paths it serves.
+type httpServer struct {
+	mux   *http.ServeMux
+	paths []string
+}
+
+func (s *httpServer) handle(path string, h http.Handler) {
+	s.mux.Handle(path, h)
+	s.paths = append(s.paths, path)
+}
+
+// httpServers returns the health and metrics servers to run, by port.
+// Health and metrics share one server when health.port equals
+// prometheus.port, which is the default.
+func httpServers(cfg *config.Config, routes healthRoutes) map[int]*httpServer {
+	servers := make(map[int]*httpServer)
+	server := func(port int) *httpServer {
+		if servers[port] == nil {
+			servers[port] = &httpServer{mux: http.NewServeMux()}
+		}
+		return servers[port]
+	}
+
+	if cfg.Health.IsEnabled() {
+		srv := server(cfg.Health.Port)
+		srv.handle("/healthz", routes.healthz)
+		srv.handle("/readyz", routes.readyz)
+		if cfg.Health.DrainEndpoint {
+			srv.handle("/drain", routes.drain)
+		}
+		if cfg.Health.Pprof {
+			srv.handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
+			srv.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+			srv.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+			srv.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+			srv.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		}
+	}
+	if cfg.Prometheus.Enable {
+		server(cfg.Prometheus.Port).handle("/metrics", promhttp.Handler())
+	}
+	return servers
+}
+
+// startHTTPServers starts servers in the background and returns a
+// function that shuts them all down.
+func startHTTPServers(servers map[int]*httpServer, logger *slog.Logger) func(context.Context) error {
+	var running []*http.Server
+	for _, port := range slices.Sorted(maps.Keys(servers)) {
+		srv := &http.Server{
+			Addr:    fmt.Sprintf(":%d", port),
+			Handler: servers[port].mux,
+		}
+		go func() {
+			if srvErr := srv.ListenAndServe(); srvErr != nil && !errors.Is(srvErr, http.ErrServerClosed) {
+				logger.Error("HTTP server error", slog.String("error", srvErr.Error()))
+			}
+		}()
+		running = append(running, srv)
+		logger.Info("HTTP server started",
+			slog.String("endpoint", fmt.Sprintf("http://0.0.0.0:%d", port)),
+			slog.String("endpoints", strings.Join(servers[port].paths, ", ")),
+		)
+	}
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, srv := range running {
+			errs = append(errs, srv.Shutdown(ctx))
+		}
+		return errors.Join(errs...)
+	}
+}
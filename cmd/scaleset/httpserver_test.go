@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+func testHealthRoutes() healthRoutes {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	return healthRoutes{healthz: ok, readyz: ok, drain: ok}
+}
+
+// status returns the status code srv answers a GET of path with.
+func status(srv *httpServer, path string) int {
+	w := httptest.NewRecorder()
+	srv.mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w.Code
+}
+
+func TestHTTPServers_SharedPortByDefault(t *testing.T) {
+	cfg := &config.Config{Prometheus: config.PrometheusConfig{Enable: true}}
+	cfg.ApplyDefaults()
+
+	servers := httpServers(cfg, testHealthRoutes())
+	require.Len(t, servers, 1)
+	srv := servers[9090]
+	require.NotNil(t, srv)
+	assert.Equal(t, []string{"/healthz", "/readyz", "/metrics"}, srv.paths)
+	assert.Equal(t, http.StatusOK, status(srv, "/metrics"))
+	assert.Equal(t, http.StatusNotFound, status(srv, "/drain"), "the drain endpoint is opt-in")
+	assert.Equal(t, http.StatusNotFound, status(srv, "/debug/pprof/"), "pprof is off by default")
+}
+
+func TestHTTPServers_SeparatePortsAndPprof(t *testing.T) {
+	cfg := &config.Config{
+		Prometheus: config.PrometheusConfig{Enable: true},
+		Health:     config.HealthConfig{Port: 8080, Pprof: true, DrainEndpoint: true},
+	}
+	cfg.ApplyDefaults()
+
+	servers := httpServers(cfg, testHealthRoutes())
+	require.Len(t, servers, 2)
+	assert.Equal(t, []string{"/metrics"}, servers[9090].paths)
+	assert.Equal(t, http.StatusOK, status(servers[8080], "/drain"))
+	assert.Equal(t, http.StatusNotFound, status(servers[9090], "/healthz"))
+	assert.Equal(t, http.StatusOK, status(servers[8080], "/healthz"))
+	assert.Equal(t, http.StatusOK, status(servers[8080], "/debug/pprof/"))
+}
+
+func TestHTTPServers_Disabled(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{Health: config.HealthConfig{Enable: &disabled}}
+	cfg.ApplyDefaults()
+	assert.Empty(t, httpServers(cfg, testHealthRoutes()))
+
+	cfg.Prometheus.Enable = true
+	servers := httpServers(cfg, testHealthRoutes())
+	require.Len(t, servers, 1)
+	assert.Equal(t, []string{"/metrics"}, servers[9090].paths)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/leader"
+)
+
+// runLeading runs lead once this replica is elected leader per
+// leader_election, or right away when leader election is disabled.  A
+// replica that loses leadership returns an error, so its supervisor
+// restarts it as a follower with nothing left over from leading.
+func runLeading(ctx context.Context, lc *config.LeaderConfig, logger *slog.Logger, lead func(ctx context.Context) error) error {
+	if !lc.Enable {
+		return lead(ctx)
+	}
+	identity, _, err := sessionOwner(lc.Identity, os.Hostname, uuid.NewString)
+	if err != nil {
+		logger.Warn("could not get hostname, using uuid as leader identity",
+			slog.String("fallback", identity),
+			slog.String("error", err.Error()),
+		)
+	}
+	elector, err := lc.NewElector(identity, logger.WithGroup("leader"))
+	if err != nil {
+		return fmt.Errorf("setting up leader election: %w", err)
+	}
+	err = elector.Run(ctx, lead)
+	if errors.Is(err, leader.ErrLostLeadership) {
+		return fmt.Errorf("leader election: %w, exiting", err)
+	}
+	return err
+}
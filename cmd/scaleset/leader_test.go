@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+func TestRunLeading_Disabled(t *testing.T) {
+	errLead := errors.New("lead done")
+	err := runLeading(context.Background(), &config.LeaderConfig{}, discardLogger(), func(context.Context) error {
+		return errLead
+	})
+	assert.ErrorIs(t, err, errLead)
+}
+
+func TestRunLeading_OneLeaderAtATime(t *testing.T) {
+	lc := &config.LeaderConfig{
+		Enable:        true,
+		Backend:       "file",
+		RenewDeadline: 50 * time.Millisecond,
+		RetryPeriod:   5 * time.Millisecond,
+		File:          config.LeaderFileConfig{Path: filepath.Join(t.TempDir(), "leader.lock")},
+	}
+	first := *lc
+	first.Identity = "replica-1"
+	second := *lc
+	second.Identity = "replica-2"
+
+	leading := make(chan struct{})
+	stepDown := make(chan struct{})
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- runLeading(context.Background(), &first, discardLogger(), func(context.Context) error {
+			close(leading)
+			<-stepDown
+			return nil
+		})
+	}()
+	<-leading
+
+	secondLed := make(chan struct{})
+	secondDone := make(chan error, 1)
+	go func() {
+		secondDone <- runLeading(context.Background(), &second, discardLogger(), func(context.Context) error {
+			close(secondLed)
+			return nil
+		})
+	}()
+	select {
+	case <-secondLed:
+		t.Fatal("second replica led while the first held the lock")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	close(stepDown)
+	require.NoError(t, <-firstDone)
+	select {
+	case <-secondLed:
+	case <-time.After(time.Second):
+		t.Fatal("second replica did not take over")
+	}
+	assert.NoError(t, <-secondDone)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// errMaxLifetime is the cancellation cause once scaleset.max_process_lifetime
+// has elapsed.
+var errMaxLifetime = errors.New("max process lifetime reached")
+
+// withMaxLifetime returns a context that is cancelled with cause
+// errMaxLifetime once lifetime has elapsed, so the process shuts down
+// gracefully and its supervisor starts a fresh one (picking up rotated
+// credentials or a new image).  drain is called first and waited for,
+// letting busy runners finish before teardown.  A zero lifetime never
+// cancels.  The returned cancel func stops the timer and must be called.
+func withMaxLifetime(ctx context.Context, lifetime time.Duration, drain func(), logger *slog.Logger) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if lifetime <= 0 {
+		return ctx, func() { cancel(nil) }
+	}
+
+	timer := time.AfterFunc(lifetime, func() {
+		logger.Info("max process lifetime reached, draining before exit",
+			slog.Duration("lifetime", lifetime),
+		)
+		drain()
+		cancel(errMaxLifetime)
+	})
+	return ctx, func() {
+		timer.Stop()
+		cancel(nil)
+	}
+}
+
+// processLifetime returns the max_process_lifetime of the process: the
+// shortest one set on its scale sets, or zero if none sets one.  The
+// lifetime bounds the whole process, so every scale set drains and the
+// process exits once it elapses.
+func processLifetime(cfgs []*config.Config) time.Duration {
+	var lifetime time.Duration
+	for _, cfg := range cfgs {
+		if l := cfg.ScaleSet.MaxProcessLifetime; l > 0 && (lifetime == 0 || l < lifetime) {
+			lifetime = l
+		}
+	}
+	return lifetime
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+func TestWithMaxLifetime_CancelsAfterLifetime(t *testing.T) {
+	var drained atomic.Bool
+	ctx, stop := withMaxLifetime(context.Background(), 20*time.Millisecond, func() {
+		drained.Store(true)
+	}, discardLogger())
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context not cancelled after max lifetime")
+	}
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "must look like a graceful shutdown")
+	assert.ErrorIs(t, context.Cause(ctx), errMaxLifetime)
+	assert.True(t, drained.Load(), "drain runs before cancellation")
+}
+
+func TestWithMaxLifetime_WaitsForDrain(t *testing.T) {
+	release := make(chan struct{})
+	ctx, stop := withMaxLifetime(context.Background(), time.Millisecond, func() {
+		<-release
+	}, discardLogger())
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("cancelled before drain finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context not cancelled after drain")
+	}
+}
+
+func TestWithMaxLifetime_ZeroNeverCancels(t *testing.T) {
+	ctx, stop := withMaxLifetime(context.Background(), 0, func() {
+		t.Error("drain must not be called")
+	}, discardLogger())
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("context cancelled without a lifetime")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stop()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.NotErrorIs(t, context.Cause(ctx), errMaxLifetime)
+}
+
+func TestWaitDrained_Timeout(t *testing.T) {
+	start := time.Now()
+	waitDrained(context.Background(), make(chan struct{}), 20*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestProcessLifetime_ShortestOfScaleSets(t *testing.T) {
+	cfg := func(lifetime time.Duration) *config.Config {
+		c := &config.Config{}
+		c.ScaleSet.MaxProcessLifetime = lifetime
+		return c
+	}
+
+	assert.Equal(t, time.Duration(0), processLifetime([]*config.Config{cfg(0), cfg(0)}))
+	assert.Equal(t, 12*time.Hour, processLifetime([]*config.Config{cfg(0), cfg(24 * time.Hour), cfg(12 * time.Hour)}))
+}
@@ -5,22 +5,35 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	// Embed the time zone database for scaleset.schedules[].timezone:
+	// the container image has none.
+	_ "time/tzdata"
 
 	"github.com/actions/scaleset"
 	"github.com/actions/scaleset/listener"
 	"github.com/google/uuid"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/terrpan/scaleset/internal/admin"
+	"github.com/terrpan/scaleset/internal/audit"
 	"github.com/terrpan/scaleset/internal/buildinfo"
 	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/engine"
 	"github.com/terrpan/scaleset/internal/health"
+	"github.com/terrpan/scaleset/internal/leader"
 	"github.com/terrpan/scaleset/internal/otel"
 	"github.com/terrpan/scaleset/internal/scaler"
+	"github.com/terrpan/scaleset/internal/state"
+	"github.com/terrpan/scaleset/internal/webhook"
 )
 
 var (
@@ -28,6 +41,13 @@ var (
 	flagOverrides config.Config
 )
 
+// otelShutdownTimeout bounds the final telemetry flush on exit.
+const otelShutdownTimeout = 15 * time.Second
+
+// secretsTimeout bounds fetching the credentials that refer to a secrets
+// manager.
+const secretsTimeout = 30 * time.Second
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -42,7 +62,9 @@ var rootCmd = &cobra.Command{
 ephemeral runners using a pluggable compute engine (Docker, EC2, etc.).
 
 Configuration is read from a YAML file (--config) with optional CLI
-flag overrides for the most common settings.`,
+flag overrides for the most common settings.  Every setting can also be
+set with a SCALESET_* environment variable named after its YAML path
+(e.g. SCALESET_GITHUB_TOKEN); precedence is environment > flag > file.`,
 	Version:      buildinfo.Version,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -75,6 +97,10 @@ func init() {
 	// Logging overrides
 	f.StringVar(&flagOverrides.Logging.Level, "log-level", "", "Log level (debug, info, warn, error)")
 	f.StringVar(&flagOverrides.Logging.Format, "log-format", "", "Log format (text, json)")
+	f.StringVar(&flagOverrides.Logging.Output, "log-output", "", "Log output (stdout, stderr, or a file path)")
+
+	// Simulation
+	f.BoolVar(&dryRun, "dry-run", false, "Log the runners that would be started and destroyed instead of creating them")
 }
 
 // applyFlagOverrides merges non-zero CLI flag values into the loaded config.
@@ -115,33 +141,54 @@ func applyFlagOverrides(cfg *config.Config) {
 	if flagOverrides.Logging.Format != "" {
 		cfg.Logging.Format = flagOverrides.Logging.Format
 	}
+	if flagOverrides.Logging.Output != "" {
+		cfg.Logging.Output = flagOverrides.Logging.Output
+	}
 }
 
-func run(ctx context.Context) error {
-	// ---------------------------------------------------------------
-	// 1. Load configuration
-	// ---------------------------------------------------------------
+// loadConfig reads the config file, applies CLI flags and environment
+// variables on top, fetches the credentials kept in a secrets manager
+// and validates the result.
+func loadConfig() (*config.Config, error) {
 	cfg, err := config.Load(cfgPath)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return nil, fmt.Errorf("loading config: %w", err)
 	}
 	applyFlagOverrides(cfg)
-
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, fmt.Errorf("loading config from environment: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+	if err := cfg.ResolveSecrets(ctx); err != nil {
+		return nil, fmt.Errorf("resolving secrets: %w", err)
+	}
 	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	return cfg, nil
+}
+
+func run(ctx context.Context) error {
+	// ---------------------------------------------------------------
+	// 1. Load configuration
+	// ---------------------------------------------------------------
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	// One per scale set, as loaded, for comparison on reload.
+	cfgs := cfg.ScaleSetConfigs()
 
 	// ---------------------------------------------------------------
 	// 2. Create logger
 	// ---------------------------------------------------------------
-	logger := cfg.NewLogger()
-	logger.Info("configuration loaded",
-		slog.String("configFile", cfgPath),
-		slog.String("engine", cfg.Engine.EnabledEngine()),
-		slog.String("scaleSetName", cfg.ScaleSet.Name),
-		slog.Int("minRunners", cfg.ScaleSet.MinRunners),
-		slog.Int("maxRunners", cfg.ScaleSet.MaxRunners),
-	)
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.SlogLevel())
+	logger, err := cfg.NewLoggerWithLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("creating logger: %w", err)
+	}
 
 	// ---------------------------------------------------------------
 	// 2.5. Initialize OpenTelemetry / Prometheus (if enabled)
@@ -152,18 +199,26 @@ func run(ctx context.Context) error {
 		if cfg.Prometheus.Enable {
 			promPort = cfg.Prometheus.Port
 		}
-		otelShutdown, err := otel.SetupOTelSDK(ctx, "scaleset", otel.Config{
+		otelCfg := otel.Config{
 			Enabled:        cfg.OTel.Enabled,
 			Endpoint:       cfg.OTel.Endpoint,
 			Insecure:       cfg.OTel.Insecure,
 			StdOut:         cfg.OTel.StdOut,
 			PrometheusPort: promPort,
-		})
+		}
+		if cfg.OTel.EngineAttributes {
+			otelCfg.Engine, otelCfg.Region, otelCfg.Zone = sharedEngineAttributes(cfgs)
+		}
+		otelShutdown, err := otel.SetupOTelSDK(ctx, "scaleset", otelCfg)
 		if err != nil {
 			return fmt.Errorf("setting up OpenTelemetry: %w", err)
 		}
 		defer func() {
-			if err := otelShutdown(context.WithoutCancel(ctx)); err != nil {
+			// Bound the final flush so an unreachable collector cannot
+			// hang exit, but leave room for a full export.
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), otelShutdownTimeout)
+			defer cancel()
+			if err := otelShutdown(shutdownCtx); err != nil {
 				logger.Error("OpenTelemetry shutdown error", slog.String("error", err.Error()))
 			}
 		}()
@@ -181,35 +236,226 @@ func run(ctx context.Context) error {
 	}
 
 	// ---------------------------------------------------------------
-	// 2.6. Start HTTP server for /healthz and optionally /metrics
+	// 2.6. Start the health and metrics servers (if enabled)
 	// ---------------------------------------------------------------
-	if cfg.Prometheus.Enable || true { // Always start for at least /healthz
-		mux := http.NewServeMux()
-		mux.HandleFunc("/healthz", health.Handler(cfg.Engine.EnabledEngine()))
-		if cfg.Prometheus.Enable {
-			mux.Handle("/metrics", promhttp.Handler())
+	readiness := health.NewReadiness(engineNames(cfgs), cfg.Health.Diagnostics)
+	drain := health.NewDrain(cfg.Health.DrainTimeout)
+	drains := &drainGroup{}
+	drain.SetDrainer(drains)
+	if servers := httpServers(cfg, healthRoutes{
+		healthz: health.Handler(engineNames(cfgs), cfg.Health.Labels),
+		readyz:  readiness.Handler(),
+		drain:   drain.Handler(),
+	}); len(servers) > 0 {
+		httpSrvShutdown = startHTTPServers(servers, logger)
+	}
+	if httpSrvShutdown != nil {
+		defer func() {
+			if err := httpSrvShutdown(context.WithoutCancel(ctx)); err != nil {
+				logger.Error("HTTP server shutdown error", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
+	// ---------------------------------------------------------------
+	// 2.7. Start the admin API (if enabled)
+	// ---------------------------------------------------------------
+	var adminAPI *admin.API
+	if cfg.Admin.Enable {
+		adminAPI = admin.New(cfg.Admin.Token)
+		adminSrv := &http.Server{
+			Addr:    net.JoinHostPort(cfg.Admin.Address, strconv.Itoa(cfg.Admin.Port)),
+			Handler: adminAPI.Handler(),
 		}
-		httpSrv := &http.Server{
-			Addr:    fmt.Sprintf(":%d", cfg.Prometheus.Port),
-			Handler: mux,
+		go func() {
+			if srvErr := adminSrv.ListenAndServe(); srvErr != nil && !errors.Is(srvErr, http.ErrServerClosed) {
+				logger.Error("admin server error", slog.String("error", srvErr.Error()))
+			}
+		}()
+		defer func() {
+			if err := adminSrv.Shutdown(context.WithoutCancel(ctx)); err != nil {
+				logger.Error("admin server shutdown error", slog.String("error", err.Error()))
+			}
+		}()
+		logger.Info("admin server started",
+			slog.String("endpoint", "http://"+adminSrv.Addr),
+			slog.Bool("token", cfg.Admin.Token != ""),
+		)
+	}
+
+	// ---------------------------------------------------------------
+	// 2.8. Start the webhook receiver (if enabled)
+	// ---------------------------------------------------------------
+	var hooks *webhook.Receiver
+	if cfg.Webhook.Enable {
+		hooks = webhook.New(cfg.Webhook.Secret, logger.WithGroup("webhook"))
+		mux := http.NewServeMux()
+		mux.Handle(cfg.Webhook.Path, hooks)
+		webhookSrv := &http.Server{
+			Addr:              net.JoinHostPort(cfg.Webhook.Address, strconv.Itoa(cfg.Webhook.Port)),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
 		}
 		go func() {
-			if srvErr := httpSrv.ListenAndServe(); srvErr != nil && !errors.Is(srvErr, http.ErrServerClosed) {
-				logger.Error("HTTP server error", slog.String("error", srvErr.Error()))
+			if srvErr := webhookSrv.ListenAndServe(); srvErr != nil && !errors.Is(srvErr, http.ErrServerClosed) {
+				logger.Error("webhook server error", slog.String("error", srvErr.Error()))
+			}
+		}()
+		defer func() {
+			if err := webhookSrv.Shutdown(context.WithoutCancel(ctx)); err != nil {
+				logger.Error("webhook server shutdown error", slog.String("error", err.Error()))
 			}
 		}()
-		httpSrvShutdown = httpSrv.Shutdown
-		logger.Info("HTTP server started",
-			slog.String("endpoint", fmt.Sprintf("http://0.0.0.0:%d", cfg.Prometheus.Port)),
-			slog.String("endpoints", "/healthz, /metrics (if enabled)"),
+		logger.Info("webhook server started",
+			slog.String("endpoint", "http://"+webhookSrv.Addr+cfg.Webhook.Path),
+			slog.String("mode", cfg.Webhook.Mode),
 		)
 	}
-	if httpSrvShutdown != nil {
+
+	// ---------------------------------------------------------------
+	// 2.9. Open the audit log (if enabled)
+	// ---------------------------------------------------------------
+	// Like the state file, it records nothing in a dry run.
+	var auditLog *audit.Log
+	if cfg.Audit.Path != "" && !dryRun {
+		auditLog, err = audit.Open(cfg.Audit.Path)
+		if err != nil {
+			return err
+		}
 		defer func() {
-			if err := httpSrvShutdown(context.WithoutCancel(ctx)); err != nil {
-				logger.Error("HTTP server shutdown error", slog.String("error", err.Error()))
+			if err := auditLog.Close(); err != nil {
+				logger.Error("audit log close error", slog.String("error", err.Error()))
 			}
 		}()
+		logger.Info("audit log enabled", slog.String("path", cfg.Audit.Path))
+	}
+
+	// SIGHUP reloads the safe-to-change settings.
+	reload := newReloader(cfg, loadConfig, logLevel, logger)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go reload.run(ctx, hup)
+	go reload.runSchedules(ctx)
+
+	// SIGUSR1, POST /drain on the admin API and "scaleset drain" drain
+	// the process gracefully and exit.
+	ctx, graceful, stopGraceful := withGracefulDrain(ctx, drains, logger)
+	defer stopGraceful()
+	usr1 := make(chan os.Signal, 1)
+	signal.Notify(usr1, syscall.SIGUSR1)
+	defer signal.Stop(usr1)
+	go graceful.run(ctx, usr1)
+	if adminAPI != nil {
+		adminAPI.SetDrain(func() { graceful.start("admin API") })
+	}
+
+	// scaleset.max_process_lifetime drains every scale set and exits.
+	lifetimeCtx := ctx
+	ctx, stopLifetime := withMaxLifetime(ctx, processLifetime(cfgs), func() {
+		waitDrained(lifetimeCtx, drains.Drain(), cfg.Health.DrainTimeout)
+	}, logger)
+	defer stopLifetime()
+
+	deps := &runDeps{
+		logger:      logger,
+		multi:       len(cfgs) > 1,
+		ready:       newReadyGroup(readiness, len(cfgs)),
+		drains:      drains,
+		graceful:    graceful,
+		reload:      reload,
+		admin:       adminAPI,
+		webhook:     hooks,
+		webhookOnly: cfg.Webhook.Enable && cfg.Webhook.Mode == config.WebhookModeOnly,
+		audit:       auditLog,
+	}
+	// Only the leader registers the scale sets and scales; standby
+	// replicas serve /healthz but are not ready.
+	return runLeading(ctx, &cfg.Leader, logger, func(ctx context.Context) error {
+		return runScaleSets(ctx, cfgs, deps)
+	})
+}
+
+// runScaleSets runs the scale sets of the process until ctx is done.
+func runScaleSets(ctx context.Context, cfgs []*config.Config, deps *runDeps) error {
+	if len(cfgs) == 1 {
+		return runScaleSet(ctx, 0, cfgs[0], deps)
+	}
+
+	// One scale set failing stops the others.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make([]error, len(cfgs))
+	var wg sync.WaitGroup
+	for i, sc := range cfgs {
+		wg.Go(func() {
+			if err := runScaleSet(ctx, i, sc, deps); err != nil {
+				errs[i] = fmt.Errorf("scale set %s: %w", sc.ScaleSet.Name, err)
+				cancel()
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// runDeps are the parts of the process shared by its scale sets.
+type runDeps struct {
+	logger *slog.Logger
+	// multi reports that the process runs several scale sets.
+	multi  bool
+	ready  *readyGroup
+	drains *drainGroup
+	// graceful is the graceful drain of the process.
+	graceful *gracefulDrain
+	reload   *reloader
+	// admin is nil when the admin API is disabled.
+	admin *admin.API
+	// webhook is nil when the webhook receiver is disabled.
+	webhook *webhook.Receiver
+	// webhookOnly scales on webhooks alone, without a message session.
+	webhookOnly bool
+	// audit is nil when the audit log is disabled.
+	audit *audit.Log
+}
+
+// runScaleSet runs the i-th scale set of the process, configured by
+// loaded, until ctx is done.
+func runScaleSet(ctx context.Context, i int, loaded *config.Config, deps *runDeps) error {
+	running := *loaded
+	cfg := &running
+	var err error
+	if cfg.ScaleSet.RunID == "" {
+		cfg.ScaleSet.RunID = uuid.NewString()
+	}
+	// The suffixed name is the scale set's name from here on: it is
+	// created, labelled, logged and deleted under it.
+	cfg.ScaleSet.Name, err = suffixedName(cfg.ScaleSet.Name, cfg.ScaleSet.NameSuffix, defaultSuffixSources)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Component loggers derive from root, tagged by ScaleSetLogger; the
+	// entries logged here always carry the name when there are several
+	// scale sets.
+	root, logger := deps.logger, deps.logger
+	var telemetryAttrs []attribute.KeyValue
+	if deps.multi {
+		logger = logger.With(slog.String("scale_set", cfg.ScaleSet.Name))
+		telemetryAttrs = []attribute.KeyValue{attribute.String("scale_set", cfg.ScaleSet.Name)}
+	}
+	logger.Info("configuration loaded",
+		slog.String("configFile", cfgPath),
+		slog.String("apiURL", cfg.GitHub.ResolveAPIURL()),
+		slog.String("engine", cfg.Engine.EnabledEngine()),
+		slog.String("scaleSetName", cfg.ScaleSet.Name),
+		slog.Int("minRunners", cfg.ScaleSet.MinRunners),
+		slog.Int("maxRunners", cfg.ScaleSet.MaxRunners),
+		slog.String("runID", cfg.ScaleSet.RunID),
+		slog.Bool("dryRun", dryRun),
+	)
+	if dryRun {
+		logger.Warn("dry run: runners are only logged, not created; jobs assigned to the scale set will wait")
 	}
 
 	// ---------------------------------------------------------------
@@ -219,140 +465,317 @@ func run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("creating scaleset client: %w", err)
 	}
+	appAuth := cfg.GitHub.App.ClientID != ""
+	httpClient, err := cfg.Network.HTTPClient()
+	if err != nil {
+		return err
+	}
+	if _, err := verifyGHES(ctx, cfg, httpClient, logger); err != nil {
+		return err
+	}
 
 	// ---------------------------------------------------------------
-	// 4. Resolve runner group
+	// 4. Resolve runner group and create or get the runner scale set
 	// ---------------------------------------------------------------
-	var runnerGroupID int
-	switch cfg.ScaleSet.RunnerGroup {
-	case scaleset.DefaultRunnerGroup:
-		runnerGroupID = 1
-	default:
-		rg, err := scalesetClient.GetRunnerGroupByName(ctx, cfg.ScaleSet.RunnerGroup)
+	setupScaleSet := func(ctx context.Context) (*scaleset.RunnerScaleSet, error) {
+		var runnerGroupID int
+		switch cfg.ScaleSet.RunnerGroup {
+		case scaleset.DefaultRunnerGroup:
+			runnerGroupID = 1
+		default:
+			rg, err := scalesetClient.GetRunnerGroupByName(ctx, cfg.ScaleSet.RunnerGroup)
+			if err != nil {
+				err = diagnoseAuthError(err, appAuth, logger)
+				return nil, fmt.Errorf("looking up runner group %q: %w", cfg.ScaleSet.RunnerGroup, err)
+			}
+			runnerGroupID = rg.ID
+		}
+
+		setup := ensureScaleSet
+		if cfg.ScaleSet.AdoptExisting {
+			setup = adoptScaleSet
+		}
+		ss, err := setup(ctx, scalesetClient, desiredScaleSet(cfg, runnerGroupID),
+			cfg.ScaleSet.CreateRetries, cfg.ScaleSet.CreateRetryDelay, logger)
 		if err != nil {
-			return fmt.Errorf("looking up runner group %q: %w", cfg.ScaleSet.RunnerGroup, err)
+			return nil, diagnoseAuthError(err, appAuth, logger)
 		}
-		runnerGroupID = rg.ID
+		logger.Info("runner scale set ready",
+			slog.Int("scaleSetID", ss.ID),
+			slog.String("name", ss.Name),
+		)
+		return ss, nil
 	}
 
 	// ---------------------------------------------------------------
-	// 5. Create or get existing runner scale set
+	// 5. Initialize compute engine
 	// ---------------------------------------------------------------
-	desiredScaleSet := &scaleset.RunnerScaleSet{
-		Name:          cfg.ScaleSet.Name,
-		RunnerGroupID: runnerGroupID,
-		Labels:        cfg.BuildLabels(),
-		RunnerSetting: scaleset.RunnerSetting{
-			DisableUpdate: true,
-		},
+	newEngine := func(ctx context.Context, engLogger *slog.Logger) (engine.Engine, error) {
+		if dryRun {
+			return newDryRunEngine(cfg, engLogger), nil
+		}
+		eng, err := cfg.NewEngine(ctx, engLogger)
+		if err != nil {
+			return nil, fmt.Errorf("initializing engine: %w", err)
+		}
+		return eng, nil
 	}
 
-	scaleSet, err := scalesetClient.CreateRunnerScaleSet(ctx, desiredScaleSet)
-	if err != nil {
-		// If the scale set already exists, get the existing one and update it.
-		if !strings.Contains(err.Error(), "ExistsException") {
-			return fmt.Errorf("creating runner scale set: %w", err)
+	// With concurrent_init the engine starts before the scale set ID is
+	// known, so its log entries can carry only the scale set name.
+	var (
+		scaleSet *scaleset.RunnerScaleSet
+		eng      engine.Engine
+	)
+	if cfg.ScaleSet.ConcurrentInit {
+		engLogger := root
+		if cfg.Logging.IncludeScaleSet {
+			engLogger = root.With(slog.String("scale_set", cfg.ScaleSet.Name))
+		}
+		scaleSet, eng, err = initConcurrently(ctx, setupScaleSet, func(ctx context.Context) (engine.Engine, error) {
+			return newEngine(ctx, engLogger)
+		})
+	} else {
+		scaleSet, err = setupScaleSet(ctx)
+		if err == nil {
+			eng, err = newEngine(ctx, cfg.ScaleSetLogger(root, scaleSet.ID))
 		}
+	}
 
-		logger.Info("runner scale set already exists, reusing",
-			slog.String("name", cfg.ScaleSet.Name),
-		)
+	// ---------------------------------------------------------------
+	// 6. Register cleanup of whatever was set up
+	// ---------------------------------------------------------------
+	if scaleSet != nil {
+		scalesetClient.SetSystemInfo(scaleset.SystemInfo{
+			System:     "terrpan-scaleset",
+			Subsystem:  "cli",
+			Version:    buildinfo.Version,
+			CommitSHA:  buildinfo.Commit,
+			ScaleSetID: scaleSet.ID,
+		})
 
-		scaleSet, err = scalesetClient.GetRunnerScaleSet(ctx, runnerGroupID, cfg.ScaleSet.Name)
-		if err != nil {
-			return fmt.Errorf("getting existing runner scale set: %w", err)
+		if cfg.ScaleSet.KeepOnShutdown {
+			defer logger.Info("keeping runner scale set registered (scaleset.keep_on_shutdown)",
+				slog.Int("scaleSetID", scaleSet.ID),
+				slog.String("name", scaleSet.Name),
+			)
+		} else {
+			defer func() {
+				// The new leader may already use the scale set.
+				if errors.Is(context.Cause(ctx), leader.ErrLostLeadership) {
+					logger.Warn("lost leadership, keeping the runner scale set registered for the new leader",
+						slog.Int("scaleSetID", scaleSet.ID),
+						slog.String("name", scaleSet.Name),
+					)
+					return
+				}
+				deleteScaleSet(context.WithoutCancel(ctx), scalesetClient, scaleSet, logger)
+			}()
 		}
-
-		// Update the existing scale set to ensure labels and settings are current.
-		scaleSet, err = scalesetClient.UpdateRunnerScaleSet(ctx, scaleSet.ID, desiredScaleSet)
-		if err != nil {
-			return fmt.Errorf("updating runner scale set: %w", err)
+	}
+	if err != nil {
+		if eng != nil {
+			if serr := eng.Shutdown(context.WithoutCancel(ctx)); serr != nil {
+				logger.Error("engine shutdown error", slog.String("error", serr.Error()))
+			}
 		}
+		return err
 	}
 
-	logger.Info("runner scale set ready",
-		slog.Int("scaleSetID", scaleSet.ID),
-		slog.String("name", scaleSet.Name),
-	)
+	// The engine labels its resources with the scale set's ID, which is
+	// only known now when it was created concurrently.
+	if ider, ok := eng.(engine.ScaleSetIdentifier); ok {
+		ider.SetScaleSetID(scaleSet.ID)
+	}
 
-	scalesetClient.SetSystemInfo(scaleset.SystemInfo{
-		System:     "terrpan-scaleset",
-		Subsystem:  "cli",
-		Version:    buildinfo.Version,
-		CommitSHA:  buildinfo.Commit,
-		ScaleSetID: scaleSet.ID,
-	})
+	// From here on, component loggers carry the scale set identity when
+	// logging.include_scale_set is enabled.
+	ssLogger := cfg.ScaleSetLogger(root, scaleSet.ID)
 
-	defer func() {
-		logger.Info("deleting runner scale set", slog.Int("scaleSetID", scaleSet.ID))
-		if err := scalesetClient.DeleteRunnerScaleSet(context.WithoutCancel(ctx), scaleSet.ID); err != nil {
-			logger.Error("failed to delete runner scale set",
-				slog.Int("scaleSetID", scaleSet.ID),
+	// ---------------------------------------------------------------
+	// 7. Create message session (none when scaling on webhooks only)
+	// ---------------------------------------------------------------
+	var sessionClient *scaleset.MessageSessionClient
+	if !deps.webhookOnly {
+		owner, source, err := sessionOwner(cfg.ScaleSet.SessionOwner, os.Hostname, uuid.NewString)
+		if err != nil {
+			logger.Warn("could not get hostname, using uuid",
+				slog.String("fallback", owner),
 				slog.String("error", err.Error()),
 			)
 		}
-	}()
+		logger.Info("session owner", slog.String("owner", owner), slog.String("source", source))
 
-	// ---------------------------------------------------------------
-	// 6. Initialize compute engine
-	// ---------------------------------------------------------------
-	eng, err := cfg.NewEngine(ctx, logger)
-	if err != nil {
-		return fmt.Errorf("initializing engine: %w", err)
+		sessionClient, err = scalesetClient.MessageSessionClient(ctx, scaleSet.ID, owner)
+		if err != nil {
+			return fmt.Errorf("creating message session: %w", err)
+		}
+		defer func() {
+			if err := sessionClient.Close(context.Background()); err != nil {
+				logger.Error("session client close error", slog.String("error", err.Error()))
+			}
+		}()
 	}
 
 	// ---------------------------------------------------------------
-	// 7. Create message session
+	// 8. Create listener + scaler
 	// ---------------------------------------------------------------
-	hostname, err := os.Hostname()
-	if err != nil {
-		hostname = uuid.NewString()
-		logger.Warn("could not get hostname, using uuid",
-			slog.String("fallback", hostname),
+	// A dry run records nothing a real run would restore.
+	var stateStore scaler.StateStore
+	if cfg.State.Path != "" && !dryRun {
+		stateStore = state.NewFile(cfg.State.Path)
+	}
+	var auditLog scaler.AuditLog
+	if deps.audit != nil {
+		auditLog = deps.audit.WithScaleSet(cfg.ScaleSet.Name)
+	}
+	var jitConfigs scaler.JitConfigGenerator = scalesetClient
+	if dryRun {
+		jitConfigs = &dryRunJIT{}
+	}
+	limits := limitsAt(&cfg.ScaleSet, deps.reload.now())
+	if limits.schedule != "" {
+		logger.Info("runner schedule active",
+			slog.String("schedule", limits.schedule),
+			slog.Int("min_runners", limits.min),
+			slog.Int("max_runners", limits.max),
+		)
+	}
+	s := scaler.New(scaler.Config{
+		ScaleSetID:             scaleSet.ID,
+		MinRunners:             limits.min,
+		MaxRunners:             limits.max,
+		ScalesetClient:         jitConfigs,
+		Engine:                 eng,
+		Logger:                 ssLogger.WithGroup("scaler"),
+		MaxConcurrentDestroys:  cfg.ScaleSet.MaxConcurrentDestroys,
+		StartRate:              cfg.ScaleSet.StartRate,
+		StartBurst:             cfg.ScaleSet.StartBurst,
+		SlowMessageThreshold:   cfg.ScaleSet.SlowMessageThreshold,
+		StartupDurationBuckets: cfg.ScaleSet.StartupDurationBuckets,
+		ShutdownRetries:        cfg.ScaleSet.ShutdownRetries,
+		ShutdownRetryDelay:     cfg.ScaleSet.ShutdownRetryDelay,
+		AdmissionCheck:         cfg.ScaleSet.AdmissionCheck,
+		AdmissionCheckTimeout:  cfg.ScaleSet.AdmissionCheckTimeout,
+		MaxRunnersPerRepo:      cfg.ScaleSet.MaxRunnersPerRepo,
+		RepoRunnerLimits:       cfg.ScaleSet.RepoRunnerLimits,
+		ReconcileInterval:      cfg.ScaleSet.ReconcileInterval,
+		RunnerRegistry:         scalesetClient,
+		BusyCheckInterval:      cfg.ScaleSet.BusyCheckInterval,
+		CleanupOnStartup:       cfg.ScaleSet.CleanupOnStartup,
+		RunID:                  cfg.ScaleSet.RunID,
+		IdempotentStarts:       cfg.ScaleSet.IdempotentStarts,
+		StartDeadline:          cfg.ScaleSet.StartDeadline,
+		StartParallelism:       cfg.ScaleSet.StartParallelism,
+		StartRetries:           cfg.ScaleSet.StartRetries,
+		StartRetryDelay:        cfg.ScaleSet.StartRetryDelay,
+		StartRetryMaxDelay:     cfg.ScaleSet.StartRetryMaxDelay,
+		StartupTimeout:         cfg.ScaleSet.StartupTimeout,
+		MaxRunnerLifetime:      cfg.ScaleSet.MaxRunnerLifetime,
+		CapacityProbeInterval:  cfg.ScaleSet.CapacityProbeInterval,
+		RunnerWorkFolder:       cfg.ScaleSet.RunnerWorkFolder,
+		StateStore:             stateStore,
+		TelemetryAttributes:    telemetryAttrs,
+		EngineName:             cfg.Engine.EnabledEngine(),
+		AuditLog:               auditLog,
+	})
+	defer s.Shutdown(context.WithoutCancel(ctx))
+	deps.drains.add(s)
+	if _, err := s.Restore(ctx); err != nil {
+		logger.Error("restoring runner state",
+			slog.String("path", cfg.State.Path),
 			slog.String("error", err.Error()),
 		)
 	}
-
-	sessionClient, err := scalesetClient.MessageSessionClient(ctx, scaleSet.ID, hostname)
-	if err != nil {
-		return fmt.Errorf("creating message session: %w", err)
+	if _, err := s.CleanupOrphans(ctx); err != nil {
+		logger.Error("cleaning up leftover runners", slog.String("error", err.Error()))
 	}
-	defer func() {
-		if err := sessionClient.Close(context.Background()); err != nil {
-			logger.Error("session client close error", slog.String("error", err.Error()))
+	stopReconciler := s.StartReconciler(ctx)
+	defer stopReconciler()
+	stopStartupWatch := s.StartStartupWatch(ctx)
+	defer stopStartupWatch()
+	stopLifetimeReaper := s.StartLifetimeReaper(ctx)
+	defer stopLifetimeReaper()
+	stopBusyCheck := s.StartBusyCheck(ctx)
+	defer stopBusyCheck()
+
+	var l *listener.Listener
+	var sessionCapacity maxRunnersSetter = noSession{}
+	if sessionClient != nil {
+		l, err = listener.New(s.InstrumentClient(sessionClient), listener.Config{
+			ScaleSetID: scaleSet.ID,
+			MaxRunners: limits.max,
+			Logger:     ssLogger.WithGroup("listener"),
+		})
+		if err != nil {
+			return fmt.Errorf("creating listener: %w", err)
 		}
-	}()
+		sessionCapacity = l
+	}
 
-	// ---------------------------------------------------------------
-	// 8. Create listener + scaler
-	// ---------------------------------------------------------------
-	s := scaler.New(scaler.Config{
-		ScaleSetID:     scaleSet.ID,
-		MinRunners:     cfg.ScaleSet.MinRunners,
-		MaxRunners:     cfg.ScaleSet.MaxRunners,
-		ScalesetClient: scalesetClient,
-		Engine:         eng,
-		Logger:         logger.WithGroup("scaler"),
+	// Ready once the scale set is registered and its message session is
+	// established (or at once without one); from then on /readyz checks
+	// the engine and GitHub.
+	engineChecker, _ := eng.(engine.Checker)
+	deps.ready.markReady(cfg.ScaleSet.Name, scaleSetChecker{
+		engine: engineChecker,
+		github: newGitHubChecker(scalesetClient, scaleSet.ID),
 	})
-	defer s.Shutdown(context.WithoutCancel(ctx))
 
-	l, err := listener.New(sessionClient, listener.Config{
-		ScaleSetID: scaleSet.ID,
-		MaxRunners: cfg.ScaleSet.MaxRunners,
-		Logger:     logger.WithGroup("listener"),
+	// The listener's capacity goes through gate, which a graceful drain
+	// closes.
+	gate := &capacityGate{listener: sessionCapacity}
+	deps.graceful.add(s, gate)
+	deps.reload.register(i, &reloadTarget{
+		cfg:       loaded,
+		limits:    limits,
+		scaler:    s,
+		listener:  gate,
+		scaleSets: scalesetClient,
+		scaleSet:  scaleSet,
 	})
-	if err != nil {
-		return fmt.Errorf("creating listener: %w", err)
+	if deps.admin != nil {
+		deps.admin.Register(cfg.ScaleSet.Name, s, gate)
+	}
+	if deps.webhook != nil {
+		// Jobs may also ask for the implicit self-hosted label.
+		labels := []string{"self-hosted"}
+		for _, label := range cfg.BuildLabels() {
+			labels = append(labels, label.Name)
+		}
+		stopWebhook := deps.webhook.Register(ctx, cfg.ScaleSet.Name, labels, s, deps.webhookOnly)
+		defer stopWebhook()
 	}
 
 	// ---------------------------------------------------------------
 	// 9. Run
 	// ---------------------------------------------------------------
-	logger.Info("starting listener")
-	if err := l.Run(ctx, s); !errors.Is(err, context.Canceled) {
-		return fmt.Errorf("listener: %w", err)
+	if deps.webhookOnly {
+		// Without a message session the listener is never run: webhooks
+		// drive the scaler until the process stops.
+		logger.Info("scaling on webhooks only")
+		<-ctx.Done()
+	} else {
+		logger.Info("starting listener")
+		if err := l.Run(ctx, s); !errors.Is(err, context.Canceled) {
+			return fmt.Errorf("listener: %w", err)
+		}
 	}
 
 	logger.Info("shutting down gracefully")
 	return nil
 }
+
+// waitDrained waits until drained is closed, timeout elapses (zero waits
+// indefinitely) or ctx is done.
+func waitDrained(ctx context.Context, drained <-chan struct{}, timeout time.Duration) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	select {
+	case <-drained:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/health"
+)
+
+// engineNames returns the distinct engines of cfgs, comma-separated, for
+// /healthz and /readyz.
+func engineNames(cfgs []*config.Config) string {
+	var names []string
+	for _, cfg := range cfgs {
+		if name := cfg.Engine.EnabledEngine(); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// readyGroup marks a Readiness ready once every scale set of the process
+// is, with a checker that checks all of their engines.
+type readyGroup struct {
+	readiness *health.Readiness
+	count     int
+
+	mu       sync.Mutex
+	checkers map[string]health.Checker // by scale set name; nil values allowed
+}
+
+func newReadyGroup(readiness *health.Readiness, count int) *readyGroup {
+	return &readyGroup{readiness: readiness, count: count, checkers: make(map[string]health.Checker, count)}
+}
+
+// markReady records that the named scale set is ready, with its engine's
+// checker (nil if it has none).  With a single scale set the checker is
+// installed as is.
+func (g *readyGroup) markReady(name string, checker health.Checker) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.checkers[name] = checker
+	if len(g.checkers) < g.count {
+		return
+	}
+	if g.count == 1 {
+		g.readiness.MarkReady(checker)
+		return
+	}
+	g.readiness.MarkReady(multiChecker(maps.Clone(g.checkers)))
+}
+
+// multiChecker checks the engines of several scale sets, keyed by scale
+// set name.  Diagnostics are prefixed with the name, and the scale sets
+// whose check fails are named in the error.
+type multiChecker map[string]health.Checker
+
+func (m multiChecker) Check(ctx context.Context) (map[string]string, error) {
+	diags := make(map[string]string)
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(m)) {
+		if m[name] == nil {
+			continue
+		}
+		d, err := m[name].Check(ctx)
+		for k, v := range d {
+			diags[name+"."+k] = v
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return diags, errors.Join(errs...)
+}
+
+// drainGroup drains the scalers of every scale set of the process as
+// one health.Drainer.  Scalers added after a drain was requested are
+// drained as they are added.
+type drainGroup struct {
+	mu       sync.Mutex
+	draining bool
+	drainers []health.Drainer
+}
+
+// add adds a scaler to the group.
+func (g *drainGroup) add(d health.Drainer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.drainers = append(g.drainers, d)
+	if g.draining {
+		d.Drain()
+	}
+}
+
+// Drain drains every scaler added so far and returns a channel closed
+// once all of them have drained.
+func (g *drainGroup) Drain() <-chan struct{} {
+	g.mu.Lock()
+	g.draining = true
+	chans := make([]<-chan struct{}, len(g.drainers))
+	for i, d := range g.drainers {
+		chans[i] = d.Drain()
+	}
+	g.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		for _, ch := range chans {
+			<-ch
+		}
+		close(drained)
+	}()
+	return drained
+}
+
+// sharedEngineAttributes returns the engine, region and zone of cfgs for
+// the process's telemetry resource, or empty values if the scale sets
+// differ in them.
+func sharedEngineAttributes(cfgs []*config.Config) (engine, region, zone string) {
+	engine = cfgs[0].Engine.EnabledEngine()
+	region, zone = cfgs[0].Engine.Location()
+	for _, cfg := range cfgs[1:] {
+		r, z := cfg.Engine.Location()
+		if cfg.Engine.EnabledEngine() != engine || r != region || z != zone {
+			return "", "", ""
+		}
+	}
+	return engine, region, zone
+}
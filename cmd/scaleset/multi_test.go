@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/health"
+)
+
+type fakeChecker struct {
+	diags map[string]string
+	err   error
+}
+
+func (f *fakeChecker) Check(context.Context) (map[string]string, error) {
+	return f.diags, f.err
+}
+
+func serveReady(t *testing.T, r *health.Readiness) (int, health.ReadyResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.Handler()(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	var resp health.ReadyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestReadyGroup_ReadyOnceAllScaleSetsAre(t *testing.T) {
+	readiness := health.NewReadiness("docker,gcp", true)
+	g := newReadyGroup(readiness, 2)
+
+	g.markReady("linux", &fakeChecker{diags: map[string]string{"version": "27.0"}})
+	code, resp := serveReady(t, readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "starting", resp.Status)
+
+	g.markReady("gpu", &fakeChecker{diags: map[string]string{"quota": "8"}, err: errors.New("quota exhausted")})
+	code, resp = serveReady(t, readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "degraded", resp.Status)
+	assert.Equal(t, "gpu: quota exhausted", resp.Error)
+	assert.Equal(t, map[string]string{"linux.version": "27.0", "gpu.quota": "8"}, resp.Diagnostics)
+}
+
+func TestReadyGroup_SingleScaleSetKeepsChecker(t *testing.T) {
+	readiness := health.NewReadiness("docker", true)
+	newReadyGroup(readiness, 1).markReady("ci", &fakeChecker{diags: map[string]string{"version": "27.0"}})
+
+	code, resp := serveReady(t, readiness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]string{"version": "27.0"}, resp.Diagnostics)
+}
+
+type fakeDrainer struct {
+	calls   int
+	drained chan struct{}
+}
+
+func (f *fakeDrainer) Drain() <-chan struct{} {
+	f.calls++
+	return f.drained
+}
+
+func TestDrainGroup_DrainedWhenAllAre(t *testing.T) {
+	g := &drainGroup{}
+	first := &fakeDrainer{drained: make(chan struct{})}
+	g.add(first)
+
+	drained := g.Drain()
+	assert.Equal(t, 1, first.calls)
+
+	// A scaler added while draining is drained at once.
+	second := &fakeDrainer{drained: make(chan struct{})}
+	g.add(second)
+	assert.Equal(t, 1, second.calls)
+
+	close(first.drained)
+	drained = g.Drain()
+	select {
+	case <-drained:
+		t.Fatal("drained before every scaler")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(second.drained)
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("not drained")
+	}
+}
+
+func TestDrainGroup_EmptyIsDrained(t *testing.T) {
+	select {
+	case <-(&drainGroup{}).Drain():
+	case <-time.After(time.Second):
+		t.Fatal("no scaler means nothing to drain")
+	}
+}
+
+func TestEngineNames(t *testing.T) {
+	docker := &config.Config{Engine: config.EngineConfig{Docker: config.DockerEngineConfig{Enable: true}}}
+	gcp := &config.Config{Engine: config.EngineConfig{GCP: config.GCPEngineConfig{Enable: true, Zone: "europe-west1-b"}}}
+	assert.Equal(t, "docker", engineNames([]*config.Config{docker, docker}))
+	assert.Equal(t, "docker,gcp", engineNames([]*config.Config{docker, gcp, docker}))
+}
+
+func TestSharedEngineAttributes(t *testing.T) {
+	gcp := &config.Config{Engine: config.EngineConfig{GCP: config.GCPEngineConfig{Enable: true, Zone: "europe-west1-b"}}}
+	engine, region, zone := sharedEngineAttributes([]*config.Config{gcp, gcp})
+	assert.Equal(t, []string{"gcp", "europe-west1", "europe-west1-b"}, []string{engine, region, zone})
+
+	other := &config.Config{Engine: config.EngineConfig{GCP: config.GCPEngineConfig{Enable: true, Zone: "us-east1-c"}}}
+	engine, region, zone = sharedEngineAttributes([]*config.Config{gcp, other})
+	assert.Equal(t, []string{"", "", ""}, []string{engine, region, zone}, "differing scale sets leave the attributes out")
+}
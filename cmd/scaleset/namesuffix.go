@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// suffixSources supplies the values scaleset.name_suffix can draw from.
+// They are fields so tests can substitute them.
+type suffixSources struct {
+	getenv func(string) string
+	gitSHA func() (string, error)
+	random func() string
+}
+
+// defaultSuffixSources reads the process environment and the git
+// repository of the working directory.
+var defaultSuffixSources = suffixSources{
+	getenv: os.Getenv,
+	gitSHA: func() (string, error) {
+		out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		return strings.TrimSpace(string(out)), err
+	},
+	random: func() string { return uuid.NewString()[:8] },
+}
+
+// shortSHALength is how many characters of a commit SHA form the suffix.
+const shortSHALength = 7
+
+// suffixedName returns name with the suffix described by spec (see
+// config.ScaleSetConfig.NameSuffix) appended, or name unchanged when
+// spec is empty.  The suffix is lowercased and reduced to letters,
+// digits and '-'.
+func suffixedName(name, spec string, src suffixSources) (string, error) {
+	var suffix string
+	switch {
+	case spec == "":
+		return name, nil
+	case spec == "random":
+		suffix = src.random()
+	case spec == "git-sha":
+		suffix = src.getenv("GITHUB_SHA")
+		if suffix == "" {
+			sha, err := src.gitSHA()
+			if err != nil {
+				return "", fmt.Errorf("scaleset.name_suffix: resolving git SHA: %w", err)
+			}
+			suffix = sha
+		}
+		suffix = suffix[:min(len(suffix), shortSHALength)]
+	case strings.HasPrefix(spec, "env:"):
+		suffix = src.getenv(strings.TrimPrefix(spec, "env:"))
+	default:
+		return "", fmt.Errorf("scaleset.name_suffix: unsupported value %q", spec)
+	}
+
+	suffix = sanitizeSuffix(suffix)
+	if suffix == "" {
+		return "", fmt.Errorf("scaleset.name_suffix: %q resolved to an empty suffix", spec)
+	}
+	return name + "-" + suffix, nil
+}
+
+// sanitizeSuffix lowercases s and replaces runs of characters other than
+// letters and digits with a single '-', trimming any at either end, so
+// "Feature/Foo_Bar" becomes "feature-foo-bar".
+func sanitizeSuffix(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
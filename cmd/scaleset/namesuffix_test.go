@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// fakeSuffixSources returns sources backed by env, a fixed git SHA (or
+// error) and a fixed random value.
+func fakeSuffixSources(env map[string]string, sha string, shaErr error) suffixSources {
+	return suffixSources{
+		getenv: func(k string) string { return env[k] },
+		gitSHA: func() (string, error) { return sha, shaErr },
+		random: func() string { return "a1b2c3d4" },
+	}
+}
+
+func TestSuffixedName(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	cases := []struct {
+		name    string
+		spec    string
+		env     map[string]string
+		shaErr  error
+		want    string
+		wantErr string
+	}{
+		{name: "none", spec: "", want: "ci-runners"},
+		{name: "random", spec: "random", want: "ci-runners-a1b2c3d4"},
+		{name: "env", spec: "env:PR_NUMBER", env: map[string]string{"PR_NUMBER": "123"}, want: "ci-runners-123"},
+		{name: "env sanitized", spec: "env:BRANCH", env: map[string]string{"BRANCH": "Feature/Foo_Bar"}, want: "ci-runners-feature-foo-bar"},
+		{name: "env unset", spec: "env:PR_NUMBER", wantErr: "resolved to an empty suffix"},
+		{name: "git sha from GITHUB_SHA", spec: "git-sha", env: map[string]string{"GITHUB_SHA": "fedcba9876543210"}, want: "ci-runners-fedcba9"},
+		{name: "git sha from repository", spec: "git-sha", want: "ci-runners-0123456"},
+		{name: "git sha unavailable", spec: "git-sha", shaErr: errors.New("not a git repository"), wantErr: "not a git repository"},
+		{name: "unsupported", spec: "pr", wantErr: "unsupported"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := suffixedName("ci-runners", tc.spec, fakeSuffixSources(tc.env, sha, tc.shaErr))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSuffixedName_UsedForCreateAndDelete(t *testing.T) {
+	cfg := &config.Config{ScaleSet: config.ScaleSetConfig{Name: "ci-runners", NameSuffix: "env:PR_NUMBER"}}
+	name, err := suffixedName(cfg.ScaleSet.Name, cfg.ScaleSet.NameSuffix,
+		fakeSuffixSources(map[string]string{"PR_NUMBER": "42"}, "", nil))
+	require.NoError(t, err)
+	cfg.ScaleSet.Name = name
+
+	desired := desiredScaleSet(cfg, 1)
+	assert.Equal(t, "ci-runners-42", desired.Name)
+	require.Len(t, desired.Labels, 1)
+	assert.Equal(t, "ci-runners-42", desired.Labels[0].Name, "default label follows the effective name")
+
+	api := &mockScaleSetAPI{}
+	created, err := ensureScaleSet(context.Background(), api, desired, 0, 0, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "ci-runners-42", created.Name)
+
+	deleter := &mockDeleter{}
+	deleteScaleSet(context.Background(), deleter, created, discardLogger())
+	assert.Equal(t, []int{created.ID}, deleter.deleted)
+}
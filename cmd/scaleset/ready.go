@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/terrpan/scaleset/internal/health"
+)
+
+// githubCheckInterval is how long the result of a GitHub API check is
+// reused, so frequent readiness probes do not eat into the API rate
+// limit.
+const githubCheckInterval = 30 * time.Second
+
+// scaleSetGetter is the part of the scale set client the GitHub check
+// uses.  *scaleset.Client satisfies it.
+type scaleSetGetter interface {
+	GetRunnerScaleSetByID(ctx context.Context, runnerScaleSetID int) (*scaleset.RunnerScaleSet, error)
+}
+
+// githubChecker checks that GitHub's Actions service is reachable by
+// reading the registered scale set back.
+type githubChecker struct {
+	client     scaleSetGetter
+	scaleSetID int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+func newGitHubChecker(client scaleSetGetter, scaleSetID int) *githubChecker {
+	return &githubChecker{client: client, scaleSetID: scaleSetID, now: time.Now}
+}
+
+func (g *githubChecker) Check(ctx context.Context) (map[string]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.checked.IsZero() || g.now().Sub(g.checked) >= githubCheckInterval {
+		_, g.err = g.client.GetRunnerScaleSetByID(ctx, g.scaleSetID)
+		g.checked = g.now()
+	}
+	return map[string]string{"github.checked_at": g.checked.UTC().Format(time.RFC3339)}, g.err
+}
+
+// scaleSetChecker checks the dependencies of one scale set: its engine
+// (nil if the engine has no checker) and GitHub.  The error names the
+// dependencies that failed.
+type scaleSetChecker struct {
+	engine health.Checker
+	github health.Checker
+}
+
+func (c scaleSetChecker) Check(ctx context.Context) (map[string]string, error) {
+	diags := make(map[string]string)
+	var errs []error
+	if c.engine != nil {
+		d, err := c.engine.Check(ctx)
+		maps.Copy(diags, d)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("engine: %w", err))
+		}
+	}
+	d, err := c.github.Check(ctx)
+	maps.Copy(diags, d)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("github: %w", err))
+	}
+	return diags, errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeScaleSetGetter struct {
+	calls int
+	err   error
+}
+
+func (f *fakeScaleSetGetter) GetRunnerScaleSetByID(_ context.Context, id int) (*scaleset.RunnerScaleSet, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &scaleset.RunnerScaleSet{ID: id}, nil
+}
+
+func TestGitHubChecker_CachesResult(t *testing.T) {
+	client := &fakeScaleSetGetter{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	g := newGitHubChecker(client, 7)
+	g.now = func() time.Time { return now }
+
+	diags, err := g.Check(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-01T12:00:00Z", diags["github.checked_at"])
+
+	client.err = errors.New("connection refused")
+	now = now.Add(githubCheckInterval / 2)
+	_, err = g.Check(t.Context())
+	require.NoError(t, err, "the cached result is reused")
+	assert.Equal(t, 1, client.calls)
+
+	now = now.Add(githubCheckInterval)
+	_, err = g.Check(t.Context())
+	require.EqualError(t, err, "connection refused")
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestScaleSetChecker_NamesFailedDependency(t *testing.T) {
+	c := scaleSetChecker{
+		engine: &fakeChecker{diags: map[string]string{"docker.version": "27.0"}, err: errors.New("daemon unreachable")},
+		github: &fakeChecker{diags: map[string]string{"github.checked_at": "t"}},
+	}
+	diags, err := c.Check(t.Context())
+	require.EqualError(t, err, "engine: daemon unreachable")
+	assert.Equal(t, map[string]string{"docker.version": "27.0", "github.checked_at": "t"}, diags)
+
+	c.engine = nil
+	c.github = &fakeChecker{err: errors.New("401 Unauthorized")}
+	_, err = c.Check(t.Context())
+	require.EqualError(t, err, "github: 401 Unauthorized")
+}
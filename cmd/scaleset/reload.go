@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/actions/scaleset"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// runnerLimiter is the subset of *scaler.Scaler a reload changes.
+type runnerLimiter interface {
+	SetRunnerLimits(minRunners, maxRunners int)
+}
+
+// maxRunnersSetter is the subset of *listener.Listener a reload changes.
+type maxRunnersSetter interface {
+	SetMaxRunners(count int)
+}
+
+// noSession stands in for the listener when scaling on webhooks only:
+// without a message session there is no capacity to report to GitHub.
+type noSession struct{}
+
+func (noSession) SetMaxRunners(int) {}
+
+// scaleSetUpdater is the subset of *scaleset.Client used to update the
+// scale set's labels on reload.
+type scaleSetUpdater interface {
+	UpdateRunnerScaleSet(ctx context.Context, runnerScaleSetID int, runnerScaleSet *scaleset.RunnerScaleSet) (*scaleset.RunnerScaleSet, error)
+}
+
+// reloader applies a reloaded configuration to the running process.
+// Only fields that are safe to change without tearing down a scale set
+// or its runners are applied:
+//
+//   - scaleset.min_runners, scaleset.max_runners and scaleset.schedules,
+//     to the scaler and the listener's capacity;
+//   - logging.level;
+//   - scaleset.labels, by updating the scale set.
+//
+// With scale_sets the scaleset fields are applied per entry.  Any other
+// change, including adding or removing entries, is logged as requiring
+// a restart and ignored.
+type reloader struct {
+	load     func() (*config.Config, error)
+	logLevel *slog.LevelVar
+	logger   *slog.Logger
+	// now returns the time scaleset.schedules are evaluated at.
+	now func() time.Time
+
+	mu sync.Mutex
+	// level is the logging.level in effect.
+	level string
+	// targets are the running scale sets, in the order of
+	// Config.ScaleSetConfigs; nil until a scale set has started.
+	targets []*reloadTarget
+}
+
+// reloadTarget is a running scale set a reload applies to.
+type reloadTarget struct {
+	// cfg is the scale set's configuration in effect, as loaded (before
+	// the run ID and name suffix are resolved) with the reloaded fields
+	// applied.
+	cfg *config.Config
+	// limits are the runner limits last applied to the scale set.
+	limits scheduledLimits
+
+	scaler    runnerLimiter
+	listener  maxRunnersSetter
+	scaleSets scaleSetUpdater
+	scaleSet  *scaleset.RunnerScaleSet
+}
+
+// newReloader returns a reloader for the scale sets of cfg, as loaded.
+func newReloader(cfg *config.Config, load func() (*config.Config, error), logLevel *slog.LevelVar, logger *slog.Logger) *reloader {
+	return &reloader{
+		load:     load,
+		logLevel: logLevel,
+		logger:   logger,
+		now:      time.Now,
+		level:    cfg.Logging.Level,
+		targets:  make([]*reloadTarget, len(cfg.ScaleSetConfigs())),
+	}
+}
+
+// register makes the i-th scale set subject to reloads once it runs.
+func (r *reloader) register(i int, t *reloadTarget) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets[i] = t
+}
+
+// run reloads the configuration each time sig receives (SIGHUP) until
+// ctx is done.  A failed reload is logged and the configuration in
+// effect is kept.
+func (r *reloader) run(ctx context.Context, sig <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			r.logger.Info("reloading configuration")
+			if err := r.reload(ctx); err != nil {
+				r.logger.Error("configuration reload failed, keeping the current configuration",
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+}
+
+// reload loads the configuration and applies its safe-to-change fields.
+// A scale set whose labels cannot be updated keeps its configuration;
+// the others are still reloaded and the errors are returned joined.
+func (r *reloader) reload(ctx context.Context) error {
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+	nextCfgs := next.ScaleSetConfigs()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(nextCfgs) != len(r.targets) {
+		return fmt.Errorf("the number of scale sets changed from %d to %d, which requires a restart", len(r.targets), len(nextCfgs))
+	}
+
+	var (
+		applied []string
+		errs    []error
+		restart bool
+	)
+	for i, t := range r.targets {
+		if t == nil {
+			continue // not started yet
+		}
+		// Fields are named after the entry, or the flavor, they apply to.
+		prefix, suffix, where := "", "", ""
+		switch {
+		case len(next.ScaleSets) > 0:
+			prefix, where = fmt.Sprintf("scale_sets[%d].", i), fmt.Sprintf("scale_sets[%d]", i)
+		case len(next.ScaleSet.Flavors) > 0:
+			suffix = fmt.Sprintf(" (%s)", nextCfgs[i].ScaleSet.Name)
+			where = fmt.Sprintf("scaleset.flavors[%d]", i)
+		}
+		fields, changed, err := t.apply(ctx, nextCfgs[i], r.now())
+		if err != nil {
+			if where != "" {
+				err = fmt.Errorf("%s: %w", where, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		for _, f := range fields {
+			applied = append(applied, prefix+f+suffix)
+		}
+		restart = restart || changed
+	}
+	if next.Logging.Level != r.level {
+		r.logLevel.Set(next.SlogLevel())
+		r.level = next.Logging.Level
+		applied = append(applied, "logging.level")
+	}
+
+	if restart {
+		r.logger.Warn("configuration changes other than scaleset.min_runners, scaleset.max_runners, scaleset.schedules, scaleset.labels and logging.level require a restart and were ignored")
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	r.logger.Info("configuration reloaded", slog.Any("applied", applied))
+	return nil
+}
+
+// apply applies the safe-to-change fields of next to the scale set and
+// returns their paths, and whether next differs in fields that need a
+// restart.  The runner limits applied are those in effect at now.
+// logging.level is left to the reloader, which applies it once for all
+// scale sets.
+func (t *reloadTarget) apply(ctx context.Context, next *config.Config, now time.Time) (applied []string, restart bool, err error) {
+	cur := *t.cfg
+
+	if !slices.Equal(next.ScaleSet.Labels, cur.ScaleSet.Labels) {
+		desired := *next
+		desired.ScaleSet.Name = t.scaleSet.Name
+		if _, err := t.scaleSets.UpdateRunnerScaleSet(ctx, t.scaleSet.ID, desiredScaleSet(&desired, t.scaleSet.RunnerGroupID)); err != nil {
+			return nil, false, fmt.Errorf("updating scale set labels: %w", err)
+		}
+		cur.ScaleSet.Labels = next.ScaleSet.Labels
+		applied = append(applied, "scaleset.labels")
+	}
+	limitsChanged := next.ScaleSet.MinRunners != cur.ScaleSet.MinRunners || next.ScaleSet.MaxRunners != cur.ScaleSet.MaxRunners
+	schedulesChanged := !reflect.DeepEqual(next.ScaleSet.Schedules, cur.ScaleSet.Schedules)
+	if limitsChanged || schedulesChanged {
+		cur.ScaleSet.MinRunners = next.ScaleSet.MinRunners
+		cur.ScaleSet.MaxRunners = next.ScaleSet.MaxRunners
+		cur.ScaleSet.Schedules = next.ScaleSet.Schedules
+		t.setLimits(limitsAt(&cur.ScaleSet, now))
+		if limitsChanged {
+			applied = append(applied, "scaleset.min_runners", "scaleset.max_runners")
+		}
+		if schedulesChanged {
+			applied = append(applied, "scaleset.schedules")
+		}
+	}
+	cur.Logging.Level = next.Logging.Level
+	t.cfg = &cur
+
+	// With the applied fields equal, any remaining difference is in a
+	// field that needs a restart.
+	return applied, !reflect.DeepEqual(*next, cur), nil
+}
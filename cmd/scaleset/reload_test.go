@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+type fakeLimits struct {
+	min, max    int
+	listenerMax int
+}
+
+func (f *fakeLimits) SetRunnerLimits(minRunners, maxRunners int) {
+	f.min, f.max = minRunners, maxRunners
+}
+
+func (f *fakeLimits) SetMaxRunners(count int) { f.listenerMax = count }
+
+type failingUpdater struct{}
+
+func (failingUpdater) UpdateRunnerScaleSet(context.Context, int, *scaleset.RunnerScaleSet) (*scaleset.RunnerScaleSet, error) {
+	return nil, errors.New("forbidden")
+}
+
+func reloadTestConfig() *config.Config {
+	return &config.Config{
+		ScaleSet: config.ScaleSetConfig{Name: "ci", MinRunners: 1, MaxRunners: 5, Labels: []string{"linux"}},
+		Logging:  config.LoggingConfig{Level: "info"},
+	}
+}
+
+// newTestReloader returns a reloader over a copy of cfg whose load
+// returns next.
+func newTestReloader(cfg *config.Config, next func() (*config.Config, error), limits *fakeLimits, api scaleSetUpdater, logs *bytes.Buffer) *reloader {
+	cur := *cfg
+	r := newReloader(&cur, next, new(slog.LevelVar), slog.New(slog.NewTextHandler(logs, nil)))
+	r.register(0, &reloadTarget{
+		cfg:       &cur,
+		scaler:    limits,
+		listener:  limits,
+		scaleSets: api,
+		scaleSet:  &scaleset.RunnerScaleSet{ID: 7, Name: "ci-abc123", RunnerGroupID: 3},
+	})
+	return r
+}
+
+func TestReload_AppliesSafeFields(t *testing.T) {
+	next := reloadTestConfig()
+	next.ScaleSet.MinRunners = 2
+	next.ScaleSet.MaxRunners = 8
+	next.ScaleSet.Labels = []string{"linux", "gpu"}
+	next.Logging.Level = "debug"
+
+	limits := &fakeLimits{}
+	api := &mockScaleSetAPI{}
+	var logs bytes.Buffer
+	r := newTestReloader(reloadTestConfig(), func() (*config.Config, error) { return next, nil }, limits, api, &logs)
+
+	require.NoError(t, r.reload(context.Background()))
+	assert.Equal(t, &fakeLimits{min: 2, max: 8, listenerMax: 8}, limits)
+	assert.Equal(t, slog.LevelDebug, r.logLevel.Level())
+
+	require.NotNil(t, api.updated)
+	assert.Equal(t, 7, api.updated.ID)
+	assert.Equal(t, "ci-abc123", api.updated.Name, "the running scale set keeps its suffixed name")
+	assert.Equal(t, 3, api.updated.RunnerGroupID)
+	assert.Equal(t, []string{"linux", "gpu"}, labelNames(api.updated.Labels))
+
+	assert.Equal(t, next, r.targets[0].cfg)
+	assert.NotContains(t, logs.String(), "require a restart")
+}
+
+func TestReload_WarnsAboutOtherChanges(t *testing.T) {
+	next := reloadTestConfig()
+	next.ScaleSet.MaxRunners = 9
+	next.Engine.Docker.Image = "runner:new"
+
+	limits := &fakeLimits{}
+	var logs bytes.Buffer
+	r := newTestReloader(reloadTestConfig(), func() (*config.Config, error) { return next, nil }, limits, &mockScaleSetAPI{}, &logs)
+
+	require.NoError(t, r.reload(context.Background()))
+	assert.Equal(t, 9, limits.max)
+	assert.Contains(t, logs.String(), "require a restart and were ignored")
+	assert.Empty(t, r.targets[0].cfg.Engine.Docker.Image, "ignored changes are not recorded as in effect")
+}
+
+func TestReload_FailureKeepsConfig(t *testing.T) {
+	var logs bytes.Buffer
+
+	// The config does not load.
+	limits := &fakeLimits{}
+	r := newTestReloader(reloadTestConfig(), func() (*config.Config, error) {
+		return nil, errors.New("invalid configuration: scaleset.max_runners must be >= 1")
+	}, limits, &mockScaleSetAPI{}, &logs)
+	require.Error(t, r.reload(context.Background()))
+	assert.Equal(t, &fakeLimits{}, limits)
+
+	// The labels cannot be updated: nothing is applied.
+	next := reloadTestConfig()
+	next.ScaleSet.MaxRunners = 9
+	next.ScaleSet.Labels = []string{"gpu"}
+	r = newTestReloader(reloadTestConfig(), func() (*config.Config, error) { return next, nil }, limits, failingUpdater{}, &logs)
+	err := r.reload(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "updating scale set labels: forbidden")
+	assert.Equal(t, &fakeLimits{}, limits)
+	assert.Equal(t, reloadTestConfig(), r.targets[0].cfg)
+}
+
+func TestReload_ScaleSets(t *testing.T) {
+	multi := func(maxRunners ...int) *config.Config {
+		cfg := &config.Config{Logging: config.LoggingConfig{Level: "info"}}
+		for i, n := range maxRunners {
+			cfg.ScaleSets = append(cfg.ScaleSets, config.ScaleSetEntry{
+				ScaleSet: config.ScaleSetConfig{Name: fmt.Sprintf("ci-%d", i), MaxRunners: n, Labels: []string{"linux"}},
+			})
+		}
+		return cfg
+	}
+	next := multi(5, 8)
+	next.ScaleSets[0].ScaleSet.Labels = []string{"gpu"}
+
+	var logs bytes.Buffer
+	cur := multi(5, 5)
+	r := newReloader(cur, func() (*config.Config, error) { return next, nil }, new(slog.LevelVar), slog.New(slog.NewTextHandler(&logs, nil)))
+	first, second := &fakeLimits{}, &fakeLimits{}
+	cfgs := cur.ScaleSetConfigs()
+	r.register(0, &reloadTarget{cfg: cfgs[0], scaler: first, listener: first, scaleSets: failingUpdater{}, scaleSet: &scaleset.RunnerScaleSet{ID: 1}})
+	r.register(1, &reloadTarget{cfg: cfgs[1], scaler: second, listener: second, scaleSets: &mockScaleSetAPI{}, scaleSet: &scaleset.RunnerScaleSet{ID: 2}})
+
+	// The scale set whose labels cannot be updated keeps its
+	// configuration; the other is reloaded.
+	err := r.reload(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scale_sets[0]: updating scale set labels: forbidden")
+	assert.Equal(t, &fakeLimits{}, first)
+	assert.Equal(t, &fakeLimits{max: 8, listenerMax: 8}, second)
+	assert.Equal(t, 8, r.targets[1].cfg.ScaleSet.MaxRunners)
+
+	// Adding a scale set needs a restart.
+	next = multi(5, 8, 3)
+	err = r.reload(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changed from 2 to 3")
+}
+
+func TestReloader_RunReloadsOnSignal(t *testing.T) {
+	loads := make(chan struct{}, 1)
+	var logs bytes.Buffer
+	r := newTestReloader(reloadTestConfig(), func() (*config.Config, error) {
+		loads <- struct{}{}
+		return reloadTestConfig(), nil
+	}, &fakeLimits{}, &mockScaleSetAPI{}, &logs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		r.run(ctx, sig)
+		close(done)
+	}()
+
+	sig <- syscall.SIGHUP
+	select {
+	case <-loads:
+	case <-time.After(time.Second):
+		t.Fatal("config not reloaded on signal")
+	}
+	cancel()
+	<-done
+}
+
+func labelNames(labels []scaleset.Label) []string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	return names
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+var (
+	runnersScaleSet string
+	runnersOutput   string
+)
+
+var runnersCmd = &cobra.Command{
+	Use:   "runners",
+	Short: "List or destroy the runner resources in the engine",
+	Long: `runners works on the scaleset-managed runner resources (containers,
+VMs) in the configured engine, whichever process started them, for
+manual cleanup after an incident.  The engine is taken from the same
+configuration file as a normal run; with scale_sets, the engine of every
+entry is used.  Resources without the scaleset-managed label are never
+listed or touched.`,
+}
+
+var runnersListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the managed runner resources",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+		return runRunnersList(ctx, cmd.OutOrStdout())
+	},
+}
+
+var runnersDestroyCmd = &cobra.Command{
+	Use:   "destroy NAME...",
+	Short: "Force-destroy managed runner resources by name or id",
+	Long: `destroy destroys the named managed runner resources, busy or not:
+a busy runner's job fails.  The process that started a runner forgets it
+on its next reconcile (scaleset.reconcile_interval), and GitHub removes
+the runner's registration once it is offline.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+		return runRunnersDestroy(ctx, args, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{runnersListCmd, runnersDestroyCmd} {
+		f := c.Flags()
+		f.StringVar(&cfgPath, "config", "config.yaml", "Path to YAML configuration file")
+		f.StringVar(&runnersScaleSet, "scale-set", "", "Only runner resources labelled with this scale set name (default: all managed)")
+	}
+	runnersListCmd.Flags().StringVarP(&runnersOutput, "output", "o", "table", "Output format (table, json)")
+
+	runnersCmd.AddCommand(runnersListCmd, runnersDestroyCmd)
+	rootCmd.AddCommand(runnersCmd)
+}
+
+// runnerResource is a managed runner resource in an engine.
+type runnerResource struct {
+	Name       string `json:"name"`
+	ID         string `json:"id"`
+	Engine     string `json:"engine"`
+	ScaleSet   string `json:"scale_set,omitempty"`
+	ScaleSetID string `json:"scale_set_id,omitempty"`
+	RunID      string `json:"run_id,omitempty"`
+	Preempted  bool   `json:"preempted,omitempty"`
+}
+
+func runRunnersList(ctx context.Context, out io.Writer) error {
+	if runnersOutput != "table" && runnersOutput != "json" {
+		return fmt.Errorf("--output must be table or json, got %q", runnersOutput)
+	}
+	all := []runnerResource{}
+	err := forEachEngine(ctx, func(ctx context.Context, eng engine.Engine, name string) error {
+		found, err := listRunnerResources(ctx, eng, name, runnersScaleSet)
+		all = append(all, found...)
+		return err
+	})
+	// The engines that could be listed are printed even if another
+	// failed.
+	return errors.Join(err, writeRunnerResources(out, all, runnersOutput))
+}
+
+// writeRunnerResources writes runners as a table or as JSON.
+func writeRunnerResources(out io.Writer, runners []runnerResource, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(runners)
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tID\tENGINE\tSCALE SET\tRUN ID\tPREEMPTED")
+	for _, r := range runners {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\n",
+			r.Name, r.ID, r.Engine, cmp.Or(r.ScaleSet, "-"), cmp.Or(r.RunID, "-"), r.Preempted)
+	}
+	return tw.Flush()
+}
+
+func runRunnersDestroy(ctx context.Context, names []string, out io.Writer) error {
+	remaining := names
+	err := forEachEngine(ctx, func(ctx context.Context, eng engine.Engine, name string) error {
+		if len(remaining) == 0 {
+			return nil
+		}
+		var err error
+		remaining, err = destroyRunnerResources(ctx, eng, name, runnersScaleSet, remaining, out)
+		return err
+	})
+	for _, n := range remaining {
+		err = errors.Join(err, fmt.Errorf("no managed runner resource named %q", n))
+	}
+	return err
+}
+
+// forEachEngine calls fn with the engine of every scale set configured
+// in cfgPath and its name; entries with the same engine settings share
+// one.  Errors do not stop the other engines and are returned joined.
+func forEachEngine(ctx context.Context, fn func(ctx context.Context, eng engine.Engine, name string) error) error {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return fmt.Errorf("loading config from environment: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	logger, err := cfg.NewLogger()
+	if err != nil {
+		return fmt.Errorf("creating logger: %w", err)
+	}
+
+	var errs []error
+	for _, sc := range distinctEngines(cfg.ScaleSetConfigs()) {
+		if err := withEngine(ctx, sc, logger, fn); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withEngine initializes the engine of cfg for fn and shuts it down
+// after.
+func withEngine(ctx context.Context, cfg *config.Config, logger *slog.Logger, fn func(ctx context.Context, eng engine.Engine, name string) error) error {
+	eng, err := cfg.NewEngine(ctx, logger)
+	if err != nil {
+		return fmt.Errorf("initializing engine: %w", err)
+	}
+	// Nothing is tracked, so Shutdown only releases the engine's clients.
+	defer eng.Shutdown(context.WithoutCancel(ctx))
+	return fn(ctx, eng, cfg.Engine.EnabledEngine())
+}
+
+// distinctEngines returns the configs of cfgs with distinct engine
+// settings, the first of each.
+func distinctEngines(cfgs []*config.Config) []*config.Config {
+	var out []*config.Config
+	for _, c := range cfgs {
+		if !slices.ContainsFunc(out, func(o *config.Config) bool { return reflect.DeepEqual(o.Engine, c.Engine) }) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// listRunnerResources returns the managed runner resources of eng,
+// sorted by name, only those labelled with scaleSet if it is set.
+func listRunnerResources(ctx context.Context, eng engine.Engine, engineName, scaleSet string) ([]runnerResource, error) {
+	lister, ok := eng.(engine.RunnerLister)
+	if !ok {
+		return nil, fmt.Errorf("%s engine does not support listing runners", engineName)
+	}
+	labels := engine.RunnerLabels("")
+	if scaleSet != "" {
+		labels[engine.ScaleSetNameLabel] = engine.ScaleSetLabels(scaleSet, 0)[engine.ScaleSetNameLabel]
+	}
+	listed, err := lister.ListRunners(ctx, labels)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s runners: %w", engineName, err)
+	}
+
+	var out []runnerResource
+	for _, r := range listed {
+		// As in cleanup, never trust the lister's filter alone.
+		if r.Labels[engine.ManagedLabel] != "true" ||
+			(scaleSet != "" && r.Labels[engine.ScaleSetNameLabel] != labels[engine.ScaleSetNameLabel]) {
+			continue
+		}
+		out = append(out, runnerResource{
+			Name:       r.Name,
+			ID:         r.ID,
+			Engine:     engineName,
+			ScaleSet:   r.Labels[engine.ScaleSetNameLabel],
+			ScaleSetID: r.Labels[engine.ScaleSetIDLabel],
+			RunID:      r.Labels[engine.RunIDLabel],
+			Preempted:  r.Preempted,
+		})
+	}
+	slices.SortFunc(out, func(a, b runnerResource) int { return cmp.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+// destroyRunnerResources destroys the managed runner resources of eng
+// whose name or id is in names, reporting each to out, and returns the
+// names that matched none.  A failure to destroy one does not stop the
+// others.
+func destroyRunnerResources(ctx context.Context, eng engine.Engine, engineName, scaleSet string, names []string, out io.Writer) ([]string, error) {
+	listed, err := listRunnerResources(ctx, eng, engineName, scaleSet)
+	if err != nil {
+		return names, err
+	}
+	var errs []error
+	rest := slices.Clone(names)
+	for _, r := range listed {
+		matches := func(n string) bool { return n == r.Name || n == r.ID }
+		if !slices.ContainsFunc(rest, matches) {
+			continue
+		}
+		rest = slices.DeleteFunc(rest, matches)
+		if err := eng.DestroyRunner(ctx, r.ID); err != nil {
+			errs = append(errs, fmt.Errorf("destroying runner %s: %w", r.Name, err))
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "destroyed %s (%s)\n", r.Name, r.ID)
+	}
+	return rest, errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// scaleSetEngine holds runners of two scale sets and an unmanaged
+// resource.
+func scaleSetEngine() *mockListerEngine {
+	labelled := func(runID, scaleSet string, id int) map[string]string {
+		labels := engine.RunnerLabels(runID)
+		maps.Copy(labels, engine.ScaleSetLabels(scaleSet, id))
+		return labels
+	}
+	return &mockListerEngine{runners: []engine.ListedRunner{
+		{ID: "c3", Name: "runner-c", Labels: labelled("r2", "GPU Runners", 8)},
+		{ID: "c1", Name: "runner-a", Labels: labelled("r1", "linux", 7)},
+		{ID: "c2", Name: "runner-b", Labels: engine.RunnerLabels("r0"), Preempted: true},
+		{ID: "d", Name: "postgres", Labels: unmanaged()},
+	}}
+}
+
+func TestListRunnerResources(t *testing.T) {
+	found, err := listRunnerResources(context.Background(), scaleSetEngine(), "docker", "")
+	require.NoError(t, err)
+	assert.Equal(t, []runnerResource{
+		{Name: "runner-a", ID: "c1", Engine: "docker", ScaleSet: "linux", ScaleSetID: "7", RunID: "r1"},
+		{Name: "runner-b", ID: "c2", Engine: "docker", RunID: "r0", Preempted: true},
+		{Name: "runner-c", ID: "c3", Engine: "docker", ScaleSet: "gpu-runners", ScaleSetID: "8", RunID: "r2"},
+	}, found)
+
+	// The scale set name is matched as the engines label it.
+	found, err = listRunnerResources(context.Background(), overMatchingEngine{scaleSetEngine()}, "docker", "GPU Runners")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "runner-c", found[0].Name)
+
+	_, err = listRunnerResources(context.Background(), engineOnly{}, "plugin", "")
+	assert.EqualError(t, err, "plugin engine does not support listing runners")
+}
+
+func TestWriteRunnerResources(t *testing.T) {
+	runners := []runnerResource{
+		{Name: "runner-a", ID: "c1", Engine: "docker", ScaleSet: "linux", RunID: "r1"},
+		{Name: "runner-b", ID: "c2", Engine: "docker", Preempted: true},
+	}
+	var out bytes.Buffer
+	require.NoError(t, writeRunnerResources(&out, runners, "table"))
+	assert.Equal(t, `NAME      ID  ENGINE  SCALE SET  RUN ID  PREEMPTED
+runner-a  c1  docker  linux      r1      false
+runner-b  c2  docker  -          -       true
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeRunnerResources(&out, []runnerResource{}, "json"))
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestDestroyRunnerResources(t *testing.T) {
+	eng := scaleSetEngine()
+	eng.destroyErr = map[string]error{"c3": errors.New("permission denied")}
+
+	var out bytes.Buffer
+	rest, err := destroyRunnerResources(context.Background(), eng, "docker", "",
+		[]string{"runner-a", "c2", "runner-c", "postgres", "runner-z"}, &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destroying runner runner-c: permission denied")
+	assert.Equal(t, []string{"c1", "c2"}, eng.destroyed)
+	assert.Equal(t, []string{"postgres", "runner-z"}, rest, "unmanaged and unknown names are left")
+	assert.Equal(t, "destroyed runner-a (c1)\ndestroyed runner-b (c2)\n", out.String())
+}
+
+func TestDistinctEngines(t *testing.T) {
+	docker := config.EngineConfig{Docker: config.DockerEngineConfig{Image: "runner"}}
+	a := &config.Config{ScaleSet: config.ScaleSetConfig{Name: "a"}, Engine: docker}
+	b := &config.Config{ScaleSet: config.ScaleSetConfig{Name: "b"}, Engine: docker}
+	c := &config.Config{ScaleSet: config.ScaleSetConfig{Name: "c"}, Engine: config.EngineConfig{Docker: config.DockerEngineConfig{Image: "other"}}}
+	assert.Equal(t, []*config.Config{a, c}, distinctEngines([]*config.Config{a, b, c}))
+}
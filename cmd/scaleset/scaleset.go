@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/actions/scaleset"
+
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/retry"
+)
+
+// scaleSetAPI is the subset of *scaleset.Client used to create or reuse
+// the runner scale set.
+type scaleSetAPI interface {
+	CreateRunnerScaleSet(ctx context.Context, runnerScaleSet *scaleset.RunnerScaleSet) (*scaleset.RunnerScaleSet, error)
+	GetRunnerScaleSet(ctx context.Context, runnerGroupID int, runnerScaleSetName string) (*scaleset.RunnerScaleSet, error)
+	UpdateRunnerScaleSet(ctx context.Context, runnerScaleSetID int, runnerScaleSet *scaleset.RunnerScaleSet) (*scaleset.RunnerScaleSet, error)
+}
+
+// desiredScaleSet describes the runner scale set to create from cfg.
+func desiredScaleSet(cfg *config.Config, runnerGroupID int) *scaleset.RunnerScaleSet {
+	return &scaleset.RunnerScaleSet{
+		Name:          cfg.ScaleSet.Name,
+		RunnerGroupID: runnerGroupID,
+		Labels:        cfg.BuildLabels(),
+		RunnerSetting: scaleset.RunnerSetting{
+			DisableUpdate: true,
+		},
+	}
+}
+
+// ensureScaleSet creates the runner scale set described by desired.
+//
+// During a rapid restart GitHub may still report the set as existing
+// because the previous process's delete has not propagated, so "already
+// exists" errors are retried up to retries times, waiting delay, 2*delay,
+// 4*delay, ... between attempts.  If the set still exists after that it
+// is a genuine leftover: it is fetched and updated so labels and
+// settings are current.
+func ensureScaleSet(
+	ctx context.Context,
+	client scaleSetAPI,
+	desired *scaleset.RunnerScaleSet,
+	retries int,
+	delay time.Duration,
+	logger *slog.Logger,
+) (*scaleset.RunnerScaleSet, error) {
+	var scaleSet *scaleset.RunnerScaleSet
+	err := retry.Do(ctx, func() error {
+		var err error
+		scaleSet, err = client.CreateRunnerScaleSet(ctx, desired)
+		return err
+	}, retry.Options{
+		Retries:   retries,
+		Base:      delay,
+		Retryable: isAlreadyExists,
+		OnRetry: func(attempt int, _ error, wait time.Duration) {
+			logger.Info("runner scale set already exists, retrying create",
+				slog.String("name", desired.Name),
+				slog.Int("attempt", attempt),
+				slog.Duration("backoff", wait),
+			)
+		},
+	})
+	switch {
+	case err == nil:
+		return scaleSet, nil
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case !isAlreadyExists(err):
+		return nil, fmt.Errorf("creating runner scale set: %w", err)
+	}
+
+	logger.Info("runner scale set already exists, reusing",
+		slog.String("name", desired.Name),
+	)
+
+	scaleSet, err = client.GetRunnerScaleSet(ctx, desired.RunnerGroupID, desired.Name)
+	if err != nil {
+		return nil, fmt.Errorf("getting existing runner scale set: %w", err)
+	}
+	if scaleSet == nil {
+		return nil, fmt.Errorf("getting existing runner scale set: %q not found", desired.Name)
+	}
+	return updateScaleSet(ctx, client, scaleSet.ID, desired)
+}
+
+// adoptScaleSet reuses the runner scale set named like desired when it
+// is already registered -- by a previous process that kept it or
+// crashed, or by another replica -- and creates it with ensureScaleSet
+// otherwise.  An adopted scale set is updated so labels and settings are
+// current.
+func adoptScaleSet(
+	ctx context.Context,
+	client scaleSetAPI,
+	desired *scaleset.RunnerScaleSet,
+	retries int,
+	delay time.Duration,
+	logger *slog.Logger,
+) (*scaleset.RunnerScaleSet, error) {
+	existing, err := client.GetRunnerScaleSet(ctx, desired.RunnerGroupID, desired.Name)
+	if err != nil {
+		return nil, fmt.Errorf("looking up runner scale set: %w", err)
+	}
+	if existing == nil {
+		return ensureScaleSet(ctx, client, desired, retries, delay, logger)
+	}
+	logger.Info("adopting existing runner scale set",
+		slog.String("name", desired.Name),
+		slog.Int("scaleSetID", existing.ID),
+	)
+	return updateScaleSet(ctx, client, existing.ID, desired)
+}
+
+// updateScaleSet updates an existing scale set to ensure labels and
+// settings are current.
+func updateScaleSet(ctx context.Context, client scaleSetAPI, id int, desired *scaleset.RunnerScaleSet) (*scaleset.RunnerScaleSet, error) {
+	scaleSet, err := client.UpdateRunnerScaleSet(ctx, id, desired)
+	if err != nil {
+		return nil, fmt.Errorf("updating runner scale set: %w", err)
+	}
+	return scaleSet, nil
+}
+
+// scaleSetDeleter is the subset of *scaleset.Client used on shutdown.
+type scaleSetDeleter interface {
+	DeleteRunnerScaleSet(ctx context.Context, runnerScaleSetID int) error
+}
+
+// deleteScaleSet deletes the runner scale set on shutdown.  A scale set
+// that is already gone (double shutdown, deleted in the UI) is the
+// desired end state, so not-found is logged at info rather than error.
+func deleteScaleSet(ctx context.Context, client scaleSetDeleter, ss *scaleset.RunnerScaleSet, logger *slog.Logger) {
+	logger = logger.With(slog.Int("scaleSetID", ss.ID), slog.String("name", ss.Name))
+	logger.Info("deleting runner scale set")
+
+	err := client.DeleteRunnerScaleSet(ctx, ss.ID)
+	switch {
+	case err == nil:
+	case isNotFound(err):
+		logger.Info("runner scale set already deleted")
+	default:
+		logger.Error("failed to delete runner scale set",
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// isNotFound reports whether err is GitHub's "scale set not found" error.
+func isNotFound(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "NotFoundException") ||
+		strings.Contains(msg, "status code: 404")
+}
+
+// isAlreadyExists reports whether err is GitHub's "scale set already
+// exists" error.
+func isAlreadyExists(err error) bool {
+	return strings.Contains(err.Error(), "ExistsException")
+}
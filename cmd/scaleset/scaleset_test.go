@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errExists = errors.New(`unexpected status code: 409: {"typeName":"RunnerScaleSetExistsException"}`)
+
+// mockScaleSetAPI returns createErrs in order from CreateRunnerScaleSet,
+// then succeeds.
+type mockScaleSetAPI struct {
+	createErrs  []error
+	createCalls int
+	existing    *scaleset.RunnerScaleSet
+	updated     *scaleset.RunnerScaleSet
+}
+
+func (m *mockScaleSetAPI) CreateRunnerScaleSet(_ context.Context, ss *scaleset.RunnerScaleSet) (*scaleset.RunnerScaleSet, error) {
+	m.createCalls++
+	if m.createCalls <= len(m.createErrs) {
+		return nil, m.createErrs[m.createCalls-1]
+	}
+	created := *ss
+	created.ID = 42
+	return &created, nil
+}
+
+func (m *mockScaleSetAPI) GetRunnerScaleSet(context.Context, int, string) (*scaleset.RunnerScaleSet, error) {
+	return m.existing, nil
+}
+
+func (m *mockScaleSetAPI) UpdateRunnerScaleSet(_ context.Context, id int, ss *scaleset.RunnerScaleSet) (*scaleset.RunnerScaleSet, error) {
+	updated := *ss
+	updated.ID = id
+	m.updated = &updated
+	return &updated, nil
+}
+
+func testDesiredScaleSet() *scaleset.RunnerScaleSet {
+	return &scaleset.RunnerScaleSet{Name: "my-scaleset", RunnerGroupID: 1}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestEnsureScaleSet_Created(t *testing.T) {
+	api := &mockScaleSetAPI{}
+
+	ss, err := ensureScaleSet(context.Background(), api, testDesiredScaleSet(), 3, time.Millisecond, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 42, ss.ID)
+	assert.Equal(t, 1, api.createCalls)
+	assert.Nil(t, api.updated)
+}
+
+func TestEnsureScaleSet_TransientExistsThenCreated(t *testing.T) {
+	api := &mockScaleSetAPI{createErrs: []error{errExists, errExists}}
+
+	ss, err := ensureScaleSet(context.Background(), api, testDesiredScaleSet(), 3, time.Millisecond, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 42, ss.ID)
+	assert.Equal(t, 3, api.createCalls)
+	assert.Nil(t, api.updated, "should not fall back to reuse")
+}
+
+func TestEnsureScaleSet_ConsistentlyExistsReuses(t *testing.T) {
+	api := &mockScaleSetAPI{
+		createErrs: []error{errExists, errExists, errExists, errExists},
+		existing:   &scaleset.RunnerScaleSet{ID: 7, Name: "my-scaleset"},
+	}
+
+	ss, err := ensureScaleSet(context.Background(), api, testDesiredScaleSet(), 3, time.Millisecond, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 7, ss.ID)
+	assert.Equal(t, 4, api.createCalls, "initial attempt plus 3 retries")
+	require.NotNil(t, api.updated)
+	assert.Equal(t, 7, api.updated.ID)
+}
+
+func TestEnsureScaleSet_ExistingVanished(t *testing.T) {
+	api := &mockScaleSetAPI{createErrs: []error{errExists}}
+
+	_, err := ensureScaleSet(context.Background(), api, testDesiredScaleSet(), 0, time.Millisecond, discardLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestEnsureScaleSet_OtherErrorNotRetried(t *testing.T) {
+	api := &mockScaleSetAPI{createErrs: []error{errors.New("unexpected status code: 500")}}
+
+	_, err := ensureScaleSet(context.Background(), api, testDesiredScaleSet(), 3, time.Millisecond, discardLogger())
+	require.Error(t, err)
+	assert.Equal(t, 1, api.createCalls)
+}
+
+func TestEnsureScaleSet_ContextCancelledDuringBackoff(t *testing.T) {
+	api := &mockScaleSetAPI{createErrs: []error{errExists}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ensureScaleSet(ctx, api, testDesiredScaleSet(), 3, time.Hour, discardLogger())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAdoptScaleSet_Existing(t *testing.T) {
+	api := &mockScaleSetAPI{existing: &scaleset.RunnerScaleSet{ID: 7, Name: "my-scaleset"}}
+	desired := testDesiredScaleSet()
+	desired.Labels = []scaleset.Label{{Name: "gpu"}}
+
+	ss, err := adoptScaleSet(context.Background(), api, desired, 3, time.Millisecond, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 7, ss.ID)
+	assert.Zero(t, api.createCalls, "an existing scale set is not created")
+	require.NotNil(t, api.updated)
+	assert.Equal(t, []scaleset.Label{{Name: "gpu"}}, api.updated.Labels)
+}
+
+func TestAdoptScaleSet_Missing(t *testing.T) {
+	api := &mockScaleSetAPI{}
+
+	ss, err := adoptScaleSet(context.Background(), api, testDesiredScaleSet(), 3, time.Millisecond, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 42, ss.ID)
+	assert.Equal(t, 1, api.createCalls)
+	assert.Nil(t, api.updated)
+}
+
+func TestAdoptScaleSet_LookupError(t *testing.T) {
+	api := &failingLookupAPI{}
+
+	_, err := adoptScaleSet(context.Background(), api, testDesiredScaleSet(), 3, time.Millisecond, discardLogger())
+	require.EqualError(t, err, "looking up runner scale set: unexpected status code: 500")
+	assert.Zero(t, api.createCalls)
+}
+
+// failingLookupAPI fails GetRunnerScaleSet.
+type failingLookupAPI struct{ mockScaleSetAPI }
+
+func (*failingLookupAPI) GetRunnerScaleSet(context.Context, int, string) (*scaleset.RunnerScaleSet, error) {
+	return nil, errors.New("unexpected status code: 500")
+}
+
+type mockDeleter struct {
+	err     error
+	deleted []int
+}
+
+func (m *mockDeleter) DeleteRunnerScaleSet(_ context.Context, id int) error {
+	m.deleted = append(m.deleted, id)
+	return m.err
+}
+
+func TestDeleteScaleSet(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		wantError bool
+		wantLog   string
+	}{
+		{"deleted", nil, false, "deleting runner scale set"},
+		{"status not found", errors.New(`request DELETE https://example/_apis/runtime/runnerscalesets/7 failed(status="404 Not Found"): unexpected status code: 404: unknown error`), false, "already deleted"},
+		{"exception not found", errors.New(`unexpected status code: 400: {"typeName":"RunnerScaleSetNotFoundException"}`), false, "already deleted"},
+		{"server error", errors.New("unexpected status code: 500"), true, "failed to delete"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+			deleteScaleSet(context.Background(), &mockDeleter{err: tc.err}, &scaleset.RunnerScaleSet{ID: 7, Name: "my-scaleset"}, logger)
+
+			assert.Contains(t, buf.String(), tc.wantLog)
+			assert.Equal(t, tc.wantError, strings.Contains(buf.String(), "level=ERROR"), buf.String())
+		})
+	}
+}
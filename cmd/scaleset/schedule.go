@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// scheduleInterval is how often scaleset.schedules are evaluated.  Cron
+// has minute resolution, so windows start and end within a minute.
+const scheduleInterval = time.Minute
+
+// scheduledLimits are the runner limits a scale set runs with and the
+// schedule that set them ("" for scaleset.min_runners/max_runners).
+type scheduledLimits struct {
+	min, max int
+	schedule string
+}
+
+// limitsAt returns the limits sc sets at t.
+func limitsAt(sc *config.ScaleSetConfig, t time.Time) scheduledLimits {
+	minRunners, maxRunners, schedule := sc.RunnerLimitsAt(t)
+	return scheduledLimits{min: minRunners, max: maxRunners, schedule: schedule}
+}
+
+// runSchedules applies scaleset.schedules to the running scale sets every
+// scheduleInterval until ctx is done.
+func (r *reloader) runSchedules(ctx context.Context) {
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.applySchedules()
+		}
+	}
+}
+
+// applySchedules applies the runner limits in effect now to each running
+// scale set whose limits changed since they were last applied, so
+// limits set through the admin API hold until the next window starts or
+// ends.
+func (r *reloader) applySchedules() {
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.targets {
+		if t == nil {
+			continue // not started yet
+		}
+		next := limitsAt(&t.cfg.ScaleSet, now)
+		if next == t.limits {
+			continue
+		}
+		attrs := []any{
+			slog.String("scale_set", t.cfg.ScaleSet.Name),
+			slog.Int("min_runners", next.min),
+			slog.Int("max_runners", next.max),
+		}
+		if next.schedule != "" {
+			r.logger.Info("runner schedule started", append(attrs, slog.String("schedule", next.schedule))...)
+		} else {
+			r.logger.Info("runner schedule ended", append(attrs, slog.String("schedule", t.limits.schedule))...)
+		}
+		t.setLimits(next)
+	}
+}
+
+// setLimits applies limits to the scaler and the listener's capacity.
+func (t *reloadTarget) setLimits(limits scheduledLimits) {
+	t.scaler.SetRunnerLimits(limits.min, limits.max)
+	t.listener.SetMaxRunners(limits.max)
+	t.limits = limits
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+// businessHours keeps 4 runners warm from 08:00 to 18:00 UTC on weekdays.
+func businessHours() []config.RunnerSchedule {
+	four := 4
+	return []config.RunnerSchedule{{
+		Name:       "business-hours",
+		Cron:       "0 8 * * mon-fri",
+		Duration:   10 * time.Hour,
+		Timezone:   "UTC",
+		MinRunners: &four,
+	}}
+}
+
+func TestApplySchedules_StartsAndEndsWindows(t *testing.T) {
+	cfg := reloadTestConfig()
+	cfg.ScaleSet.Schedules = businessHours()
+	limits := &fakeLimits{}
+	var logs bytes.Buffer
+	r := newTestReloader(cfg, nil, limits, &mockScaleSetAPI{}, &logs)
+	r.targets[0].limits = scheduledLimits{min: 1, max: 5}
+
+	now := time.Date(2025, time.June, 2, 7, 59, 0, 0, time.UTC) // a Monday
+	r.now = func() time.Time { return now }
+
+	r.applySchedules()
+	assert.Equal(t, &fakeLimits{}, limits, "nothing changes before the window")
+
+	now = now.Add(time.Minute)
+	r.applySchedules()
+	assert.Equal(t, &fakeLimits{min: 4, max: 5, listenerMax: 5}, limits)
+	assert.Contains(t, logs.String(), "runner schedule started")
+	assert.Contains(t, logs.String(), "schedule=business-hours")
+
+	// Limits changed through the admin API hold until the window ends.
+	limits.min = 2
+	now = now.Add(time.Hour)
+	r.applySchedules()
+	assert.Equal(t, 2, limits.min)
+
+	now = time.Date(2025, time.June, 2, 18, 0, 0, 0, time.UTC)
+	r.applySchedules()
+	assert.Equal(t, &fakeLimits{min: 1, max: 5, listenerMax: 5}, limits)
+	assert.Contains(t, logs.String(), "runner schedule ended")
+}
+
+func TestApplySchedules_SkipsUnstartedScaleSets(t *testing.T) {
+	cfg := reloadTestConfig()
+	cfg.ScaleSet.Schedules = businessHours()
+	r := newReloader(cfg, nil, nil, discardLogger())
+	r.now = func() time.Time { return time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC) }
+
+	assert.NotPanics(t, r.applySchedules)
+}
+
+func TestReload_AppliesScheduleInEffect(t *testing.T) {
+	next := reloadTestConfig()
+	next.ScaleSet.Schedules = businessHours()
+	limits := &fakeLimits{}
+	var logs bytes.Buffer
+	r := newTestReloader(reloadTestConfig(), func() (*config.Config, error) { return next, nil }, limits, &mockScaleSetAPI{}, &logs)
+	r.now = func() time.Time { return time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, r.reload(context.Background()))
+	assert.Equal(t, &fakeLimits{min: 4, max: 5, listenerMax: 5}, limits)
+	assert.Contains(t, logs.String(), "scaleset.schedules")
+	assert.NotContains(t, logs.String(), "scaleset.min_runners")
+	assert.NotContains(t, logs.String(), "require a restart")
+
+	// Raising max_runners during the window keeps the schedule's minimum.
+	next = reloadTestConfig()
+	next.ScaleSet.Schedules = businessHours()
+	next.ScaleSet.MaxRunners = 8
+	require.NoError(t, r.reload(context.Background()))
+	assert.Equal(t, &fakeLimits{min: 4, max: 8, listenerMax: 8}, limits)
+}
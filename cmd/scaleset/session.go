@@ -0,0 +1,15 @@
+package main
+
+// sessionOwner returns the owner name of the message session and where
+// it came from: configured (scaleset.session_owner) when set, else the
+// hostname, else a random UUID when the hostname cannot be read.
+func sessionOwner(configured string, hostname func() (string, error), newID func() string) (owner, source string, err error) {
+	if configured != "" {
+		return configured, "config", nil
+	}
+	h, err := hostname()
+	if err == nil && h != "" {
+		return h, "hostname", nil
+	}
+	return newID(), "uuid", err
+}
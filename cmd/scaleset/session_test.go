@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionOwner(t *testing.T) {
+	hostname := func() (string, error) { return "build-host", nil }
+	noHostname := func() (string, error) { return "", errors.New("uname failed") }
+	newID := func() string { return "0b5f1c1e-uuid" }
+
+	cases := []struct {
+		name       string
+		configured string
+		hostname   func() (string, error)
+		want       string
+		wantSource string
+		wantErr    bool
+	}{
+		{name: "config wins over hostname", configured: "listener-0", hostname: hostname, want: "listener-0", wantSource: "config"},
+		{name: "config wins without hostname", configured: "listener-0", hostname: noHostname, want: "listener-0", wantSource: "config"},
+		{name: "hostname", hostname: hostname, want: "build-host", wantSource: "hostname"},
+		{name: "uuid when hostname fails", hostname: noHostname, want: "0b5f1c1e-uuid", wantSource: "uuid", wantErr: true},
+		{name: "uuid when hostname empty", hostname: func() (string, error) { return "", nil }, want: "0b5f1c1e-uuid", wantSource: "uuid"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			owner, source, err := sessionOwner(tc.configured, tc.hostname, newID)
+			assert.Equal(t, tc.want, owner)
+			assert.Equal(t, tc.wantSource, source)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/actions/scaleset"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// initConcurrently runs the GitHub setup (runner group lookup and scale
+// set registration) and the engine initialization (client creation,
+// image pulls) at the same time, since neither depends on the other.
+//
+// The first path to fail cancels the other, and the cancellation error
+// that path returns as a result is dropped; every other failure is
+// returned joined.  Whatever did succeed is returned alongside the error
+// so the caller can clean it up.
+func initConcurrently(
+	ctx context.Context,
+	setupScaleSet func(context.Context) (*scaleset.RunnerScaleSet, error),
+	newEngine func(context.Context) (engine.Engine, error),
+) (*scaleset.RunnerScaleSet, engine.Engine, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool // a path has failed and cancelled the other
+		ss     *scaleset.RunnerScaleSet
+		eng    engine.Engine
+		ssErr  error
+		engErr error
+	)
+	// fail records err from one path and reports it unless it is only
+	// the cancellation caused by the other path failing first.
+	fail := func(err error) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failed && errors.Is(err, context.Canceled) {
+			return nil
+		}
+		failed = true
+		cancel()
+		return err
+	}
+
+	wg.Go(func() {
+		var err error
+		if ss, err = setupScaleSet(ctx); err != nil {
+			ssErr = fail(err)
+		}
+	})
+	wg.Go(func() {
+		var err error
+		if eng, err = newEngine(ctx); err != nil {
+			engErr = fail(err)
+		}
+	})
+	wg.Wait()
+
+	return ss, eng, errors.Join(ssErr, engErr)
+}
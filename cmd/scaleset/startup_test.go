@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+func TestInitConcurrently_RunsBothPathsAtOnce(t *testing.T) {
+	ssStarted, engStarted := make(chan struct{}), make(chan struct{})
+	// Each path waits for the other to have started, so serial execution
+	// would time out.
+	await := func(ch chan struct{}) error {
+		select {
+		case <-ch:
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("paths did not run concurrently")
+		}
+	}
+
+	ss, eng, err := initConcurrently(context.Background(),
+		func(context.Context) (*scaleset.RunnerScaleSet, error) {
+			close(ssStarted)
+			if err := await(engStarted); err != nil {
+				return nil, err
+			}
+			return &scaleset.RunnerScaleSet{ID: 7}, nil
+		},
+		func(context.Context) (engine.Engine, error) {
+			close(engStarted)
+			if err := await(ssStarted); err != nil {
+				return nil, err
+			}
+			return engineOnly{}, nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 7, ss.ID)
+	assert.NotNil(t, eng)
+}
+
+func TestInitConcurrently_ScaleSetErrorCancelsEngine(t *testing.T) {
+	ss, eng, err := initConcurrently(context.Background(),
+		func(context.Context) (*scaleset.RunnerScaleSet, error) {
+			return nil, errors.New("looking up runner group \"ci\": not found")
+		},
+		func(ctx context.Context) (engine.Engine, error) {
+			<-ctx.Done() // a long image pull, cut short
+			return nil, ctx.Err()
+		},
+	)
+	require.Error(t, err)
+	assert.Equal(t, "looking up runner group \"ci\": not found", err.Error(),
+		"the engine's cancellation is not reported")
+	assert.Nil(t, ss)
+	assert.Nil(t, eng)
+}
+
+func TestInitConcurrently_EngineErrorKeepsScaleSetForCleanup(t *testing.T) {
+	ss, eng, err := initConcurrently(context.Background(),
+		func(context.Context) (*scaleset.RunnerScaleSet, error) {
+			return &scaleset.RunnerScaleSet{ID: 7}, nil
+		},
+		func(context.Context) (engine.Engine, error) {
+			return nil, errors.New("initializing engine: pulling image: denied")
+		},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pulling image: denied")
+	require.NotNil(t, ss, "the registered scale set must be returned so it can be deleted")
+	assert.Equal(t, 7, ss.ID)
+	assert.Nil(t, eng)
+}
+
+func TestInitConcurrently_BothErrorsSurfaced(t *testing.T) {
+	ssErr := errors.New("creating runner scale set: forbidden")
+	engErr := errors.New("initializing engine: docker daemon unavailable")
+	engFailed := make(chan struct{})
+
+	_, _, err := initConcurrently(context.Background(),
+		func(context.Context) (*scaleset.RunnerScaleSet, error) {
+			<-engFailed // fails on its own, after the engine
+			return nil, ssErr
+		},
+		func(context.Context) (engine.Engine, error) {
+			defer close(engFailed)
+			return nil, engErr
+		},
+	)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ssErr)
+	assert.ErrorIs(t, err, engErr)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/terrpan/scaleset/internal/admin"
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/state"
+)
+
+var statusOutput string
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a running scaleset process",
+	Long: `status prints the idle and busy runners of each scale set of the
+scaleset process running with the same configuration, the desired runner
+count, the recent scale events and the health of the engine.
+
+It asks the process through its admin API (admin.enable).  Without one
+it reads the runner state file (state.path) instead, which only records
+the runners.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+		return runStatus(ctx, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	f := statusCmd.Flags()
+	f.StringVar(&cfgPath, "config", "config.yaml", "Path to YAML configuration file")
+	f.StringVarP(&statusOutput, "output", "o", "table", "Output format (table, json)")
+
+	rootCmd.AddCommand(statusCmd)
+}
+
+// statusReport is the status of a process's scale sets.
+type statusReport struct {
+	// Source is "admin" (the admin API) or "state" (the state files,
+	// which record the runners only).
+	Source    string                 `json:"source"`
+	ScaleSets []admin.StatusResponse `json:"scale_sets"`
+}
+
+func runStatus(ctx context.Context, out io.Writer) error {
+	if statusOutput != "table" && statusOutput != "json" {
+		return fmt.Errorf("--output must be table or json, got %q", statusOutput)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return fmt.Errorf("loading config from environment: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	var report *statusReport
+	if cfg.Admin.Enable {
+		report, err = fetchStatus(ctx, http.DefaultClient, adminURL(&cfg.Admin), cfg.Admin.Token)
+	} else {
+		report, err = statusFromState(cfg.ScaleSetConfigs())
+	}
+	if err != nil {
+		return err
+	}
+	if statusOutput == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return writeStatusTable(out, report)
+}
+
+// fetchStatus asks the admin API at base for the status of the scale
+// sets.
+func fetchStatus(ctx context.Context, client *http.Client, base, token string) (*statusReport, error) {
+	resp, err := adminRequest(ctx, client, http.MethodGet, base+"/status", token)
+	if err != nil {
+		return nil, fmt.Errorf("requesting status: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("requesting status: %w", adminError(resp))
+	}
+	report := &statusReport{Source: "admin"}
+	if err := json.NewDecoder(resp.Body).Decode(&report.ScaleSets); err != nil {
+		return nil, fmt.Errorf("reading status: %w", err)
+	}
+	return report, nil
+}
+
+// statusFromState counts the runners recorded in the state files of the
+// scale sets that have one.
+func statusFromState(cfgs []*config.Config) (*statusReport, error) {
+	report := &statusReport{Source: "state", ScaleSets: []admin.StatusResponse{}}
+	for _, c := range cfgs {
+		if c.State.Path == "" {
+			continue
+		}
+		snap, err := state.NewFile(c.State.Path).Load()
+		if err != nil {
+			return nil, fmt.Errorf("scale set %s: %w", c.ScaleSet.Name, err)
+		}
+		ss := admin.StatusResponse{
+			ScaleSetResponse: admin.ScaleSetResponse{
+				Name:       c.ScaleSet.Name,
+				MinRunners: c.ScaleSet.MinRunners,
+				MaxRunners: c.ScaleSet.MaxRunners,
+			},
+			Events: []admin.ScaleEventResponse{},
+			Engine: admin.EngineHealthResponse{Status: "unknown"},
+		}
+		if snap != nil {
+			for _, r := range snap.Runners {
+				if r.Busy {
+					ss.Busy++
+				} else {
+					ss.Idle++
+				}
+			}
+		}
+		report.ScaleSets = append(report.ScaleSets, ss)
+	}
+	if len(report.ScaleSets) == 0 {
+		return nil, errors.New("status needs the admin API (admin.enable) or a state file (state.path)")
+	}
+	return report, nil
+}
+
+// writeStatusTable writes report as a table of the scale sets followed
+// by one of their recent scale events.
+func writeStatusTable(out io.Writer, report *statusReport) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SCALE SET\tIDLE\tBUSY\tDESIRED\tTARGET\tMIN\tMAX\tSTATE\tENGINE")
+	fromAdmin := report.Source == "admin"
+	for _, ss := range report.ScaleSets {
+		desired, target, engine := "-", "-", "-"
+		if fromAdmin {
+			desired, target = strconv.Itoa(ss.Desired), strconv.Itoa(ss.Target)
+			engine = ss.Engine.Status
+			if ss.Engine.Error != "" {
+				engine += ": " + ss.Engine.Error
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			ss.Name, ss.Idle, ss.Busy, desired, target, ss.MinRunners, ss.MaxRunners, scaleSetState(ss, fromAdmin), engine)
+	}
+
+	if !fromAdmin {
+		_, _ = fmt.Fprintln(tw, "\nRead from the state files: enable the admin API for the desired count, scale events and engine health.")
+		return tw.Flush()
+	}
+	_, _ = fmt.Fprintln(tw, "\nSCALE SET\tTIME\tACTION\tDESIRED\tCURRENT\tTARGET\tDELTA")
+	events := 0
+	for _, ss := range report.ScaleSets {
+		for _, e := range ss.Events {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%+d\n",
+				ss.Name, e.Time.Format(time.RFC3339), e.Action, e.Desired, e.Current, e.Target, e.Delta)
+			events++
+		}
+	}
+	if events == 0 {
+		_, _ = fmt.Fprintln(tw, "(no scale events yet)")
+	}
+	return tw.Flush()
+}
+
+// scaleSetState describes whether a scale set is scaling, paused or
+// draining.
+func scaleSetState(ss admin.StatusResponse, known bool) string {
+	switch {
+	case !known:
+		return "-"
+	case ss.Draining:
+		return "draining"
+	case ss.Paused:
+		return "paused"
+	default:
+		return "running"
+	}
+}
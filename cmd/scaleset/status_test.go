@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/admin"
+	"github.com/terrpan/scaleset/internal/config"
+	"github.com/terrpan/scaleset/internal/state"
+)
+
+func TestFetchStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/status" || req.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"missing or invalid bearer token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name":"linux","max_runners":5,"idle":1,"busy":2,"desired":2,"target":2,
+			"events":[{"time":"2026-05-04T12:00:00Z","action":"up","desired":2,"current":1,"target":2,"delta":1}],
+			"engine":{"status":"unhealthy","error":"daemon unreachable"}}]`))
+	}))
+	defer srv.Close()
+
+	report, err := fetchStatus(context.Background(), srv.Client(), srv.URL, "s3cret")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", report.Source)
+	require.Len(t, report.ScaleSets, 1)
+	assert.Equal(t, 2, report.ScaleSets[0].Busy)
+
+	var out bytes.Buffer
+	require.NoError(t, writeStatusTable(&out, report))
+	assert.Equal(t, `SCALE SET  IDLE  BUSY  DESIRED  TARGET  MIN  MAX  STATE    ENGINE
+linux      1     2     2        2       0    5    running  unhealthy: daemon unreachable
+
+SCALE SET  TIME                  ACTION  DESIRED  CURRENT  TARGET  DELTA
+linux      2026-05-04T12:00:00Z  up      2        1        2       +1
+`, out.String())
+
+	_, err = fetchStatus(context.Background(), srv.Client(), srv.URL, "wrong")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized: missing or invalid bearer token")
+}
+
+func TestStatusFromState(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "linux.json")
+	require.NoError(t, state.NewFile(path).Save(&state.Snapshot{Runners: map[string]state.Runner{
+		"runner-a": {ID: "c1"},
+		"runner-b": {ID: "c2", Busy: true},
+		"runner-c": {ID: "c3", Busy: true},
+	}}))
+	cfgs := []*config.Config{
+		{ScaleSet: config.ScaleSetConfig{Name: "linux", MaxRunners: 5}, State: config.StateConfig{Path: path}},
+		{ScaleSet: config.ScaleSetConfig{Name: "fresh", MaxRunners: 2}, State: config.StateConfig{Path: filepath.Join(dir, "none.json")}},
+		{ScaleSet: config.ScaleSetConfig{Name: "stateless"}},
+	}
+
+	report, err := statusFromState(cfgs)
+	require.NoError(t, err)
+	assert.Equal(t, "state", report.Source)
+	require.Len(t, report.ScaleSets, 2)
+	assert.Equal(t, admin.ScaleSetResponse{Name: "linux", MaxRunners: 5, Idle: 1, Busy: 2}, report.ScaleSets[0].ScaleSetResponse)
+	assert.Equal(t, admin.ScaleSetResponse{Name: "fresh", MaxRunners: 2}, report.ScaleSets[1].ScaleSetResponse)
+
+	var out bytes.Buffer
+	require.NoError(t, writeStatusTable(&out, report))
+	assert.Contains(t, out.String(), "linux      1     2     -        -       0    5    -      -\n")
+	assert.Contains(t, out.String(), "Read from the state files")
+
+	_, err = statusFromState(cfgs[2:])
+	assert.EqualError(t, err, "status needs the admin API (admin.enable) or a state file (state.path)")
+}
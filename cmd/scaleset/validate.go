@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/actions/scaleset"
+	"github.com/spf13/cobra"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration and its access to GitHub and the engine",
+	Long: `validate loads and validates the configuration like a normal run, then
+checks it against the live services without registering or starting
+anything: the server version (GitHub Enterprise Server only), GitHub
+authentication, the runner group and whether the scale set already
+exists, and the engine's backend (Docker daemon reachable and runner
+image present; GCP project, zone, machine type and image).
+Each check is reported as PASS or FAIL, and the command fails if any
+check does.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+		return runValidate(ctx, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	f := validateCmd.Flags()
+	f.StringVar(&cfgPath, "config", "config.yaml", "Path to YAML configuration file")
+
+	rootCmd.AddCommand(validateCmd)
+}
+
+// check is one line of the validate report.
+type check struct {
+	name   string
+	detail string
+	err    error
+}
+
+// preflightAPI is the subset of *scaleset.Client used by the GitHub
+// checks.  It only reads.
+type preflightAPI interface {
+	GetRunnerGroupByName(ctx context.Context, runnerGroup string) (*scaleset.RunnerGroup, error)
+	GetRunnerScaleSet(ctx context.Context, runnerGroupID int, runnerScaleSetName string) (*scaleset.RunnerScaleSet, error)
+}
+
+func runValidate(ctx context.Context, out io.Writer) error {
+	cfg, err := config.Load(cfgPath)
+	if err == nil {
+		err = cfg.ApplyEnv(os.LookupEnv)
+	}
+	if err == nil {
+		secretsCtx, cancel := context.WithTimeout(ctx, secretsTimeout)
+		err = cfg.ResolveSecrets(secretsCtx)
+		cancel()
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return writeReport(out, []check{{name: "config", err: err}})
+	}
+	checks := []check{{name: "config", detail: cfgPath}}
+
+	logger, err := cfg.NewLogger()
+	if err != nil {
+		return fmt.Errorf("creating logger: %w", err)
+	}
+
+	cfgs := cfg.ScaleSetConfigs()
+	for i, sc := range cfgs {
+		var scChecks []check
+		if sc.GitHub.IsEnterprise() {
+			scChecks = append(scChecks, preflightGHES(ctx, sc, logger))
+		}
+		client, err := sc.NewScalesetClient()
+		if err != nil {
+			scChecks = append(scChecks, check{name: "github.client", err: err})
+		} else {
+			scChecks = append(scChecks, preflightGitHub(ctx, sc, client, logger)...)
+		}
+		scChecks = append(scChecks, preflightEngines(ctx, sc, logger)...)
+
+		for _, c := range scChecks {
+			if len(cfgs) > 1 {
+				c.name = fmt.Sprintf("scale_sets[%d].%s", i, c.name)
+			}
+			checks = append(checks, c)
+		}
+	}
+	return writeReport(out, checks)
+}
+
+// preflightGitHub checks that cfg's scale set can be set up through
+// api: the credentials are accepted and the runner group exists.  It
+// also reports whether the scale set is registered already.
+func preflightGitHub(ctx context.Context, cfg *config.Config, api preflightAPI, logger *slog.Logger) []check {
+	appAuth := cfg.GitHub.App.ClientID != ""
+
+	group := check{name: "github.runner_group"}
+	groupID := 1
+	if cfg.ScaleSet.RunnerGroup == scaleset.DefaultRunnerGroup {
+		group.detail = fmt.Sprintf("%s (id %d)", cfg.ScaleSet.RunnerGroup, groupID)
+	} else {
+		rg, err := api.GetRunnerGroupByName(ctx, cfg.ScaleSet.RunnerGroup)
+		if err != nil {
+			group.err = diagnoseAuthError(err, appAuth, logger)
+			return []check{group}
+		}
+		groupID = rg.ID
+		group.detail = fmt.Sprintf("%s (id %d)", cfg.ScaleSet.RunnerGroup, groupID)
+	}
+
+	set := check{name: "github.scale_set"}
+	ss, err := api.GetRunnerScaleSet(ctx, groupID, cfg.ScaleSet.Name)
+	switch {
+	case err != nil:
+		set.err = diagnoseAuthError(err, appAuth, logger)
+	case cfg.ScaleSet.NameSuffix != "":
+		set.detail = fmt.Sprintf("%s gets a %s name suffix at startup", cfg.ScaleSet.Name, cfg.ScaleSet.NameSuffix)
+	case ss != nil && cfg.ScaleSet.AdoptExisting:
+		set.detail = fmt.Sprintf("%s already registered (id %d), adopted at startup", ss.Name, ss.ID)
+	case ss != nil:
+		set.detail = fmt.Sprintf("%s already registered (id %d), updated at startup", ss.Name, ss.ID)
+	default:
+		set.detail = fmt.Sprintf("%s not registered, created at startup", cfg.ScaleSet.Name)
+	}
+	return []check{group, set}
+}
+
+// preflightGHES checks the version of cfg's GitHub Enterprise Server.
+func preflightGHES(ctx context.Context, cfg *config.Config, logger *slog.Logger) check {
+	c := check{name: "github.server"}
+	httpClient, err := cfg.Network.HTTPClient()
+	if err != nil {
+		c.err = err
+		return c
+	}
+	version, err := verifyGHES(ctx, cfg, httpClient, logger)
+	switch {
+	case err != nil:
+		c.err = err
+	case version == "":
+		c.detail = "GitHub Enterprise Server, version unknown"
+	default:
+		c.detail = "GitHub Enterprise Server " + version
+	}
+	return c
+}
+
+// preflightEngines checks cfg's engines with config.PreflightEngines.
+func preflightEngines(ctx context.Context, cfg *config.Config, logger *slog.Logger) []check {
+	results, err := cfg.PreflightEngines(ctx, logger)
+	if err != nil {
+		return []check{{name: "engine", err: err}}
+	}
+	checks := make([]check, len(results))
+	for i, r := range results {
+		checks[i] = check{
+			name:   fmt.Sprintf("%s (%s)", r.Path, r.Engine),
+			detail: formatDiagnostics(r.Diagnostics),
+			err:    r.Err,
+		}
+	}
+	return checks
+}
+
+// formatDiagnostics renders diags as sorted key=value pairs.
+func formatDiagnostics(diags map[string]string) string {
+	pairs := make([]string, 0, len(diags))
+	for _, k := range slices.Sorted(maps.Keys(diags)) {
+		pairs = append(pairs, k+"="+diags[k])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// writeReport writes one PASS or FAIL line per check and returns an
+// error if any check failed.
+func writeReport(out io.Writer, checks []check) error {
+	var failed int
+	for _, c := range checks {
+		switch {
+		case c.err != nil:
+			failed++
+			fmt.Fprintf(out, "FAIL  %s: %v\n", c.name, c.err)
+		case c.detail != "":
+			fmt.Fprintf(out, "PASS  %s: %s\n", c.name, c.detail)
+		default:
+			fmt.Fprintf(out, "PASS  %s\n", c.name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
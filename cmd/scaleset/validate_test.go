@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/config"
+)
+
+type fakePreflightAPI struct {
+	group    *scaleset.RunnerGroup
+	groupErr error
+	existing *scaleset.RunnerScaleSet
+	getErr   error
+
+	groupID int
+}
+
+func (f *fakePreflightAPI) GetRunnerGroupByName(context.Context, string) (*scaleset.RunnerGroup, error) {
+	return f.group, f.groupErr
+}
+
+func (f *fakePreflightAPI) GetRunnerScaleSet(_ context.Context, runnerGroupID int, _ string) (*scaleset.RunnerScaleSet, error) {
+	f.groupID = runnerGroupID
+	return f.existing, f.getErr
+}
+
+func preflightTestConfig() *config.Config {
+	return &config.Config{ScaleSet: config.ScaleSetConfig{Name: "ci", RunnerGroup: scaleset.DefaultRunnerGroup}}
+}
+
+func TestPreflightGitHub(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("default group, new scale set", func(t *testing.T) {
+		api := &fakePreflightAPI{}
+		checks := preflightGitHub(context.Background(), preflightTestConfig(), api, logger)
+		assert.Equal(t, []check{
+			{name: "github.runner_group", detail: "default (id 1)"},
+			{name: "github.scale_set", detail: "ci not registered, created at startup"},
+		}, checks)
+	})
+
+	t.Run("named group, existing scale set", func(t *testing.T) {
+		cfg := preflightTestConfig()
+		cfg.ScaleSet.RunnerGroup = "linux"
+		api := &fakePreflightAPI{group: &scaleset.RunnerGroup{ID: 4}, existing: &scaleset.RunnerScaleSet{ID: 9, Name: "ci"}}
+		checks := preflightGitHub(context.Background(), cfg, api, logger)
+		assert.Equal(t, 4, api.groupID)
+		assert.Equal(t, []check{
+			{name: "github.runner_group", detail: "linux (id 4)"},
+			{name: "github.scale_set", detail: "ci already registered (id 9), updated at startup"},
+		}, checks)
+	})
+
+	t.Run("existing scale set adopted", func(t *testing.T) {
+		cfg := preflightTestConfig()
+		cfg.ScaleSet.AdoptExisting = true
+		api := &fakePreflightAPI{existing: &scaleset.RunnerScaleSet{ID: 9, Name: "ci"}}
+		checks := preflightGitHub(context.Background(), cfg, api, logger)
+		assert.Equal(t, check{name: "github.scale_set", detail: "ci already registered (id 9), adopted at startup"}, checks[1])
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		cfg := preflightTestConfig()
+		cfg.ScaleSet.RunnerGroup = "missing"
+		api := &fakePreflightAPI{groupErr: errors.New(`no runner group found with name "missing"`)}
+		checks := preflightGitHub(context.Background(), cfg, api, logger)
+		require.Len(t, checks, 1)
+		assert.EqualError(t, checks[0].err, `no runner group found with name "missing"`)
+	})
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		api := &fakePreflightAPI{getErr: errors.New("401 Unauthorized")}
+		checks := preflightGitHub(context.Background(), preflightTestConfig(), api, logger)
+		require.Len(t, checks, 2)
+		assert.EqualError(t, checks[1].err, "401 Unauthorized")
+	})
+}
+
+func TestWriteReport(t *testing.T) {
+	var out bytes.Buffer
+	err := writeReport(&out, []check{
+		{name: "config", detail: "config.yaml"},
+		{name: "github.runner_group", detail: "default (id 1)"},
+		{name: "engine (docker)", err: errors.New("docker daemon unreachable: connection refused")},
+	})
+	require.EqualError(t, err, "1 of 3 checks failed")
+	assert.Equal(t, `PASS  config: config.yaml
+PASS  github.runner_group: default (id 1)
+FAIL  engine (docker): docker daemon unreachable: connection refused
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeReport(&out, []check{{name: "config"}}))
+	assert.Equal(t, "PASS  config\n", out.String())
+}
+
+func TestFormatDiagnostics(t *testing.T) {
+	assert.Equal(t, "docker.api_version=1.51, docker.version=28.5.2",
+		formatDiagnostics(map[string]string{"docker.version": "28.5.2", "docker.api_version": "1.51"}))
+	assert.Empty(t, formatDiagnostics(nil))
+}
+
+func TestRunValidate_InvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("github:\n  url: https://github.com/org\n"), 0o600))
+	prev := cfgPath
+	cfgPath = path
+	t.Cleanup(func() { cfgPath = prev })
+
+	var out bytes.Buffer
+	err := runValidate(context.Background(), &out)
+	require.EqualError(t, err, "1 of 1 checks failed")
+	assert.Contains(t, out.String(), "FAIL  config: ")
+}
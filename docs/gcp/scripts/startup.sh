@@ -6,13 +6,35 @@
 # the GitHub Actions runner agent.
 set -euo pipefail
 
-JITCONFIG=$(curl -sf -H "Metadata-Flavor: Google" \
-  "http://metadata.google.internal/computeMetadata/v1/instance/attributes/ACTIONS_RUNNER_INPUT_JITCONFIG")
+# VMs created through bulkInsert receive their JIT config via SetMetadata
+# shortly after boot, so poll for up to 5 minutes before giving up.
+JITCONFIG=""
+for _ in $(seq 1 60); do
+  JITCONFIG=$(curl -sf -H "Metadata-Flavor: Google" \
+    "http://metadata.google.internal/computeMetadata/v1/instance/attributes/ACTIONS_RUNNER_INPUT_JITCONFIG" || true)
+  [ -n "$JITCONFIG" ] && break
+  sleep 5
+done
 
 if [ -z "$JITCONFIG" ]; then
   echo "ERROR: No JIT config found in instance metadata" >&2
   exit 1
 fi
 
+# engine.gcp.startup_script customizes the VM (install tools, mount
+# disks) before the runner starts; if it fails, the runner does not start.
+SCRIPT=$(curl -sf -H "Metadata-Flavor: Google" \
+  "http://metadata.google.internal/computeMetadata/v1/instance/attributes/scaleset-startup-script" || true)
+if [ -n "$SCRIPT" ]; then
+  SCRIPT_FILE=$(mktemp /tmp/scaleset-startup.XXXXXX)
+  printf '%s\n' "$SCRIPT" > "$SCRIPT_FILE"
+  chmod 700 "$SCRIPT_FILE"
+  case "$SCRIPT" in
+    '#!'*) "$SCRIPT_FILE" ;;
+    *) bash "$SCRIPT_FILE" ;;
+  esac
+  rm -f "$SCRIPT_FILE"
+fi
+
 cd /home/runner
 exec runuser -u runner -- env "ACTIONS_RUNNER_INPUT_JITCONFIG=$JITCONFIG" ./run.sh
@@ -6,15 +6,21 @@
 
 $ErrorActionPreference = "Stop"
 
-# Read JIT config from GCP instance metadata.
-try {
-    $jitConfig = Invoke-RestMethod `
-        -Uri "http://metadata.google.internal/computeMetadata/v1/instance/attributes/ACTIONS_RUNNER_INPUT_JITCONFIG" `
-        -Headers @{"Metadata-Flavor" = "Google"} `
-        -UseBasicParsing
-} catch {
-    Write-Error "Failed to read JIT config from instance metadata: $_"
-    exit 1
+# Read JIT config from GCP instance metadata.  VMs created through
+# bulkInsert receive it via SetMetadata shortly after boot, so poll for
+# up to 5 minutes before giving up.
+$jitConfig = $null
+for ($i = 0; $i -lt 60; $i++) {
+    try {
+        $jitConfig = Invoke-RestMethod `
+            -Uri "http://metadata.google.internal/computeMetadata/v1/instance/attributes/ACTIONS_RUNNER_INPUT_JITCONFIG" `
+            -Headers @{"Metadata-Flavor" = "Google"} `
+            -UseBasicParsing
+    } catch {
+        $jitConfig = $null
+    }
+    if ($jitConfig) { break }
+    Start-Sleep -Seconds 5
 }
 
 if (-not $jitConfig) {
@@ -22,6 +28,28 @@ if (-not $jitConfig) {
     exit 1
 }
 
+# engine.gcp.startup_script customizes the VM (install tools, mount
+# disks) before the runner starts; if it fails, the runner does not start.
+$script = $null
+try {
+    $script = Invoke-RestMethod `
+        -Uri "http://metadata.google.internal/computeMetadata/v1/instance/attributes/scaleset-startup-script" `
+        -Headers @{"Metadata-Flavor" = "Google"} `
+        -UseBasicParsing
+} catch {
+    $script = $null
+}
+if ($script) {
+    $scriptFile = Join-Path $env:TEMP "scaleset-startup.ps1"
+    Set-Content -Path $scriptFile -Value $script
+    & $scriptFile
+    if (-not $?) {
+        Write-Error "startup_script failed"
+        exit 1
+    }
+    Remove-Item $scriptFile
+}
+
 $env:ACTIONS_RUNNER_INPUT_JITCONFIG = $jitConfig
 
 Set-Location "C:\actions-runner"
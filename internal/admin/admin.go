@@ -0,0 +1,387 @@
+// Package admin provides the HTTP handlers of the optional admin API,
+// which inspects and controls the running scale sets: it reports their
+// status, lists the tracked runners, force-destroys a runner, pauses and
+// resumes scaling,
+// changes min_runners and max_runners at runtime and starts a graceful
+// drain of the process.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/scaler"
+)
+
+// Scaler is the subset of *scaler.Scaler the admin API controls.
+type Scaler interface {
+	Runners() []scaler.RunnerInfo
+	DestroyRunner(ctx context.Context, name string) error
+	Pause()
+	Resume()
+	Paused() bool
+	Draining() bool
+	RunnerLimits() (minRunners, maxRunners int)
+	SetRunnerLimits(minRunners, maxRunners int)
+	Status() scaler.Status
+	CheckEngine(ctx context.Context) (map[string]string, error)
+}
+
+// engineCheckTimeout bounds the engine health check of GET /status.
+const engineCheckTimeout = 5 * time.Second
+
+// MaxRunnersSetter is the subset of *listener.Listener told about a new
+// max_runners, so the capacity it reports to GitHub follows the scaler.
+type MaxRunnersSetter interface {
+	SetMaxRunners(count int)
+}
+
+// ScaleSetResponse describes a scale set.
+type ScaleSetResponse struct {
+	Name       string `json:"name"`
+	MinRunners int    `json:"min_runners"`
+	MaxRunners int    `json:"max_runners"`
+	Paused     bool   `json:"paused"`
+	Draining   bool   `json:"draining"`
+	Idle       int    `json:"idle"`
+	Busy       int    `json:"busy"`
+}
+
+// StatusResponse is the status of a scale set: its runners, its scaling
+// state and the health of its engine.
+type StatusResponse struct {
+	ScaleSetResponse
+	// Desired is the desired count last reported, and Target the runner
+	// count the last scaling decision aimed for.
+	Desired int                  `json:"desired"`
+	Target  int                  `json:"target"`
+	Events  []ScaleEventResponse `json:"events"`
+	Engine  EngineHealthResponse `json:"engine"`
+}
+
+// ScaleEventResponse describes a recent scale event (see
+// scaler.ScaleEvent).
+type ScaleEventResponse struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"` // "up" or "down"
+	Desired int       `json:"desired"`
+	Current int       `json:"current"`
+	Target  int       `json:"target"`
+	Delta   int       `json:"delta"`
+}
+
+// EngineHealthResponse is the result of the engine's health check.
+type EngineHealthResponse struct {
+	Status  string            `json:"status"` // "ok", "unhealthy" or "unknown"
+	Error   string            `json:"error,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// RunnerResponse describes a tracked runner.
+type RunnerResponse struct {
+	ScaleSet string `json:"scale_set"`
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	State    string `json:"state"` // "idle" or "busy"
+	RunID    string `json:"run_id"`
+}
+
+// LimitsRequest is the body of a limits change.  An omitted field keeps
+// its current value.
+type LimitsRequest struct {
+	MinRunners *int `json:"min_runners"`
+	MaxRunners *int `json:"max_runners"`
+}
+
+// DrainResponse is the body of a drain request.
+type DrainResponse struct {
+	Status string `json:"status"` // "draining"
+}
+
+// ErrorResponse is the body of a failed request.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// API serves the admin endpoints for the scale sets registered with it:
+//
+//	GET    /status                      report the scale sets' status
+//	GET    /scale-sets                  list the scale sets
+//	POST   /scale-sets/{name}/pause     stop starting runners
+//	POST   /scale-sets/{name}/resume    start runners again
+//	PUT    /scale-sets/{name}/limits    change min_runners / max_runners
+//	GET    /runners                     list the tracked runners
+//	DELETE /runners/{name}              destroy a runner, idle or busy
+//	POST   /drain                       drain the process and exit
+//
+// Changes last until the process exits, or until a configuration reload
+// changes the same setting.
+type API struct {
+	token string
+
+	mu        sync.RWMutex
+	scaleSets map[string]*scaleSet // by scale set name
+	drain     func()
+}
+
+type scaleSet struct {
+	scaler   Scaler
+	listener MaxRunnersSetter
+}
+
+// New creates an API.  When token is not empty, every request must carry
+// it as a bearer token.
+func New(token string) *API {
+	return &API{token: token, scaleSets: make(map[string]*scaleSet)}
+}
+
+// Register makes a running scale set, by name, controllable through the
+// API.
+func (a *API) Register(name string, s Scaler, l MaxRunnersSetter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scaleSets[name] = &scaleSet{scaler: s, listener: l}
+}
+
+// SetDrain installs the function starting a graceful drain of the
+// process: no new runners, idle runners destroyed, exit once the busy
+// ones have finished.  It must return without waiting for the drain.
+// Until it is set, POST /drain responds 503.
+func (a *API) SetDrain(start func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.drain = start
+}
+
+// Handler returns the handler serving the endpoints.
+func (a *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", a.status)
+	mux.HandleFunc("GET /scale-sets", a.listScaleSets)
+	mux.HandleFunc("POST /scale-sets/{name}/pause", a.withScaleSet(func(w http.ResponseWriter, req *http.Request, name string, ss *scaleSet) {
+		ss.scaler.Pause()
+		writeJSON(w, http.StatusOK, describe(name, ss))
+	}))
+	mux.HandleFunc("POST /scale-sets/{name}/resume", a.withScaleSet(func(w http.ResponseWriter, req *http.Request, name string, ss *scaleSet) {
+		ss.scaler.Resume()
+		writeJSON(w, http.StatusOK, describe(name, ss))
+	}))
+	mux.HandleFunc("PUT /scale-sets/{name}/limits", a.withScaleSet(setLimits))
+	mux.HandleFunc("GET /runners", a.listRunners)
+	mux.HandleFunc("DELETE /runners/{name}", a.destroyRunner)
+	mux.HandleFunc("POST /drain", a.startDrain)
+	return a.authorize(mux)
+}
+
+// authorize rejects requests without the API's token, if it has one.
+func (a *API) authorize(next http.Handler) http.Handler {
+	if a.token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// sorted returns the registered scale sets sorted by name.
+func (a *API) sorted() (names []string, scaleSets []*scaleSet) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	names = slices.Sorted(maps.Keys(a.scaleSets))
+	scaleSets = make([]*scaleSet, len(names))
+	for i, name := range names {
+		scaleSets[i] = a.scaleSets[name]
+	}
+	return names, scaleSets
+}
+
+func (a *API) listScaleSets(w http.ResponseWriter, _ *http.Request) {
+	names, scaleSets := a.sorted()
+	resp := make([]ScaleSetResponse, len(names))
+	for i, name := range names {
+		resp[i] = describe(name, scaleSets[i])
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// status reports every scale set's status, checking the engines'
+// health concurrently.
+func (a *API) status(w http.ResponseWriter, req *http.Request) {
+	names, scaleSets := a.sorted()
+	resp := make([]StatusResponse, len(names))
+	ctx, cancel := context.WithTimeout(req.Context(), engineCheckTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() {
+			ss := scaleSets[i]
+			st := ss.scaler.Status()
+			resp[i] = StatusResponse{
+				ScaleSetResponse: describe(name, ss),
+				Desired:          st.Desired,
+				Target:           st.Target,
+				Events:           make([]ScaleEventResponse, len(st.Events)),
+				Engine:           checkEngine(ctx, ss.scaler),
+			}
+			for j, e := range st.Events {
+				resp[i].Events[j] = ScaleEventResponse(e)
+			}
+		})
+	}
+	wg.Wait()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func checkEngine(ctx context.Context, s Scaler) EngineHealthResponse {
+	details, err := s.CheckEngine(ctx)
+	switch {
+	case errors.Is(err, scaler.ErrNoEngineCheck):
+		return EngineHealthResponse{Status: "unknown"}
+	case err != nil:
+		return EngineHealthResponse{Status: "unhealthy", Error: err.Error(), Details: details}
+	default:
+		return EngineHealthResponse{Status: "ok", Details: details}
+	}
+}
+
+// withScaleSet resolves the {name} path value to a registered scale set
+// for h, responding 404 if there is none.
+func (a *API) withScaleSet(h func(w http.ResponseWriter, req *http.Request, name string, ss *scaleSet)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := req.PathValue("name")
+		a.mu.RLock()
+		ss, ok := a.scaleSets[name]
+		a.mu.RUnlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("unknown scale set %q", name))
+			return
+		}
+		h(w, req, name, ss)
+	}
+}
+
+func setLimits(w http.ResponseWriter, req *http.Request, name string, ss *scaleSet) {
+	var body LimitsRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	minRunners, maxRunners := ss.scaler.RunnerLimits()
+	if body.MinRunners != nil {
+		minRunners = *body.MinRunners
+	}
+	if body.MaxRunners != nil {
+		maxRunners = *body.MaxRunners
+	}
+	switch {
+	case minRunners < 0:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("min_runners must be >= 0, got %d", minRunners))
+		return
+	case maxRunners < minRunners:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("max_runners (%d) < min_runners (%d)", maxRunners, minRunners))
+		return
+	}
+
+	ss.scaler.SetRunnerLimits(minRunners, maxRunners)
+	ss.listener.SetMaxRunners(maxRunners)
+	writeJSON(w, http.StatusOK, describe(name, ss))
+}
+
+func (a *API) listRunners(w http.ResponseWriter, _ *http.Request) {
+	names, scaleSets := a.sorted()
+	resp := []RunnerResponse{}
+	for i, name := range names {
+		for _, r := range scaleSets[i].scaler.Runners() {
+			resp = append(resp, RunnerResponse{
+				ScaleSet: name,
+				Name:     r.Name,
+				ID:       r.ID,
+				State:    runnerState(r),
+				RunID:    r.RunID,
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// destroyRunner destroys the named runner in whichever scale set tracks
+// it.  The destroy outlives a client that goes away, since the runner is
+// no longer tracked once it begins.
+func (a *API) destroyRunner(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+	ctx := context.WithoutCancel(req.Context())
+	_, scaleSets := a.sorted()
+	for _, ss := range scaleSets {
+		err := ss.scaler.DestroyRunner(ctx, name)
+		switch {
+		case errors.Is(err, scaler.ErrUnknownRunner):
+			continue
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("unknown runner %q", name))
+}
+
+// startDrain starts a graceful drain and responds 202 without waiting
+// for it; GET /scale-sets reports its progress until the process exits.
+func (a *API) startDrain(w http.ResponseWriter, _ *http.Request) {
+	a.mu.RLock()
+	start := a.drain
+	a.mu.RUnlock()
+	if start == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("drain is not available yet"))
+		return
+	}
+	start()
+	writeJSON(w, http.StatusAccepted, DrainResponse{Status: "draining"})
+}
+
+// describe reports the current state of a scale set.
+func describe(name string, ss *scaleSet) ScaleSetResponse {
+	resp := ScaleSetResponse{Name: name, Paused: ss.scaler.Paused(), Draining: ss.scaler.Draining()}
+	resp.MinRunners, resp.MaxRunners = ss.scaler.RunnerLimits()
+	for _, r := range ss.scaler.Runners() {
+		if r.Busy {
+			resp.Busy++
+		} else {
+			resp.Idle++
+		}
+	}
+	return resp
+}
+
+func runnerState(r scaler.RunnerInfo) string {
+	if r.Busy {
+		return "busy"
+	}
+	return "idle"
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, ErrorResponse{Error: err.Error()})
+}
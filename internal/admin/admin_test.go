@@ -0,0 +1,260 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/scaler"
+)
+
+type fakeScaler struct {
+	runners    []scaler.RunnerInfo
+	destroyErr error
+	destroyed  []string
+	paused     bool
+	draining   bool
+	min, max   int
+	status     scaler.Status
+	engineErr  error
+}
+
+func (f *fakeScaler) Runners() []scaler.RunnerInfo { return f.runners }
+
+func (f *fakeScaler) DestroyRunner(_ context.Context, name string) error {
+	i := slices.IndexFunc(f.runners, func(r scaler.RunnerInfo) bool { return r.Name == name })
+	if i < 0 {
+		return fmt.Errorf("%w: %s", scaler.ErrUnknownRunner, name)
+	}
+	if f.destroyErr != nil {
+		return f.destroyErr
+	}
+	f.destroyed = append(f.destroyed, name)
+	f.runners = slices.Delete(f.runners, i, i+1)
+	return nil
+}
+
+func (f *fakeScaler) Pause()       { f.paused = true }
+func (f *fakeScaler) Resume()      { f.paused = false }
+func (f *fakeScaler) Paused() bool { return f.paused }
+
+func (f *fakeScaler) Draining() bool { return f.draining }
+
+func (f *fakeScaler) RunnerLimits() (int, int) { return f.min, f.max }
+
+func (f *fakeScaler) SetRunnerLimits(minRunners, maxRunners int) {
+	f.min, f.max = minRunners, maxRunners
+}
+
+func (f *fakeScaler) Status() scaler.Status { return f.status }
+
+func (f *fakeScaler) CheckEngine(context.Context) (map[string]string, error) {
+	if f.engineErr != nil {
+		return nil, f.engineErr
+	}
+	return map[string]string{"daemon": "24.0.7"}, nil
+}
+
+type fakeListener struct{ max int }
+
+func (f *fakeListener) SetMaxRunners(count int) { f.max = count }
+
+func serve(t *testing.T, h http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v))
+	return v
+}
+
+func TestListScaleSetsAndRunners(t *testing.T) {
+	api := New("")
+	api.Register("linux", &fakeScaler{min: 1, max: 5, runners: []scaler.RunnerInfo{
+		{Name: "runner-a", ID: "c1", RunID: "r1"},
+		{Name: "runner-b", ID: "c2", Busy: true, RunID: "r1"},
+	}}, &fakeListener{})
+	api.Register("gpu", &fakeScaler{max: 2, paused: true}, &fakeListener{})
+	h := api.Handler()
+
+	w := serve(t, h, "GET", "/scale-sets", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, []ScaleSetResponse{
+		{Name: "gpu", MaxRunners: 2, Paused: true},
+		{Name: "linux", MinRunners: 1, MaxRunners: 5, Idle: 1, Busy: 1},
+	}, decode[[]ScaleSetResponse](t, w))
+
+	w = serve(t, h, "GET", "/runners", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []RunnerResponse{
+		{ScaleSet: "linux", Name: "runner-a", ID: "c1", State: "idle", RunID: "r1"},
+		{ScaleSet: "linux", Name: "runner-b", ID: "c2", State: "busy", RunID: "r1"},
+	}, decode[[]RunnerResponse](t, w))
+}
+
+func TestStatus(t *testing.T) {
+	at := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	api := New("")
+	api.Register("linux", &fakeScaler{max: 5, runners: []scaler.RunnerInfo{{Name: "runner-a", Busy: true}}, status: scaler.Status{
+		Desired: 1,
+		Target:  1,
+		Events:  []scaler.ScaleEvent{{Time: at, Action: "up", Desired: 1, Target: 1, Delta: 1}},
+	}}, &fakeListener{})
+	api.Register("gpu", &fakeScaler{max: 2, engineErr: errors.New("quota exceeded")}, &fakeListener{})
+	api.Register("plugin", &fakeScaler{max: 2, engineErr: fmt.Errorf("wrapped: %w", scaler.ErrNoEngineCheck)}, &fakeListener{})
+
+	w := serve(t, api.Handler(), "GET", "/status", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []StatusResponse{
+		{
+			ScaleSetResponse: ScaleSetResponse{Name: "gpu", MaxRunners: 2},
+			Events:           []ScaleEventResponse{},
+			Engine:           EngineHealthResponse{Status: "unhealthy", Error: "quota exceeded"},
+		},
+		{
+			ScaleSetResponse: ScaleSetResponse{Name: "linux", MaxRunners: 5, Busy: 1},
+			Desired:          1,
+			Target:           1,
+			Events:           []ScaleEventResponse{{Time: at, Action: "up", Desired: 1, Target: 1, Delta: 1}},
+			Engine:           EngineHealthResponse{Status: "ok", Details: map[string]string{"daemon": "24.0.7"}},
+		},
+		{
+			ScaleSetResponse: ScaleSetResponse{Name: "plugin", MaxRunners: 2},
+			Events:           []ScaleEventResponse{},
+			Engine:           EngineHealthResponse{Status: "unknown"},
+		},
+	}, decode[[]StatusResponse](t, w))
+}
+
+func TestPauseResume(t *testing.T) {
+	api := New("")
+	s := &fakeScaler{max: 5}
+	api.Register("linux", s, &fakeListener{})
+	h := api.Handler()
+
+	w := serve(t, h, "POST", "/scale-sets/linux/pause", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, decode[ScaleSetResponse](t, w).Paused)
+	assert.True(t, s.paused)
+
+	w = serve(t, h, "POST", "/scale-sets/linux/resume", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, s.paused)
+
+	w = serve(t, h, "POST", "/scale-sets/other/pause", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `unknown scale set "other"`, decode[ErrorResponse](t, w).Error)
+
+	w = serve(t, h, "GET", "/scale-sets/linux/pause", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestSetLimits(t *testing.T) {
+	api := New("")
+	s := &fakeScaler{min: 1, max: 5}
+	l := &fakeListener{max: 5}
+	api.Register("linux", s, l)
+	h := api.Handler()
+
+	// An omitted field keeps its value.
+	w := serve(t, h, "PUT", "/scale-sets/linux/limits", `{"max_runners": 8}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp := decode[ScaleSetResponse](t, w)
+	assert.Equal(t, []int{1, 8}, []int{resp.MinRunners, resp.MaxRunners})
+	assert.Equal(t, 8, l.max)
+
+	w = serve(t, h, "PUT", "/scale-sets/linux/limits", `{"min_runners": 9}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "max_runners (8) < min_runners (9)", decode[ErrorResponse](t, w).Error)
+
+	w = serve(t, h, "PUT", "/scale-sets/linux/limits", `{"min_runners": -1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(t, h, "PUT", "/scale-sets/linux/limits", `not json`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []int{1, 8}, []int{s.min, s.max}, "rejected changes are not applied")
+}
+
+func TestDestroyRunner(t *testing.T) {
+	api := New("")
+	linux := &fakeScaler{runners: []scaler.RunnerInfo{{Name: "runner-a"}}}
+	gpu := &fakeScaler{runners: []scaler.RunnerInfo{{Name: "runner-b", Busy: true}}}
+	api.Register("linux", linux, &fakeListener{})
+	api.Register("gpu", gpu, &fakeListener{})
+	h := api.Handler()
+
+	w := serve(t, h, "DELETE", "/runners/runner-a", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{"runner-a"}, linux.destroyed)
+
+	w = serve(t, h, "DELETE", "/runners/runner-a", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	gpu.destroyErr = errors.New("destroy runner runner-b (c2): backend down")
+	w = serve(t, h, "DELETE", "/runners/runner-b", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "destroy runner runner-b (c2): backend down", decode[ErrorResponse](t, w).Error)
+}
+
+func TestToken(t *testing.T) {
+	api := New("s3cret")
+	api.Register("linux", &fakeScaler{}, &fakeListener{})
+	h := api.Handler()
+
+	w := serve(t, h, "GET", "/scale-sets", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	w = serve(t, h, "GET", "/scale-sets", "", "Authorization", "Bearer wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve(t, h, "GET", "/scale-sets", "", "Authorization", "Bearer s3cret")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDrain(t *testing.T) {
+	api := New("")
+	s := &fakeScaler{max: 5}
+	api.Register("linux", s, &fakeListener{})
+	h := api.Handler()
+
+	w := serve(t, h, "POST", "/drain", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	starts := 0
+	api.SetDrain(func() {
+		starts++
+		s.draining = true
+	})
+	w = serve(t, h, "POST", "/drain", "")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, DrainResponse{Status: "draining"}, decode[DrainResponse](t, w))
+	assert.Equal(t, 1, starts)
+
+	w = serve(t, h, "GET", "/scale-sets", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, decode[[]ScaleSetResponse](t, w)[0].Draining)
+
+	w = serve(t, h, "GET", "/drain", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,158 @@
+// Package audit writes the audit log: an append-only file of JSON lines,
+// one per scaling decision, runner created or destroyed, job started or
+// completed, and failed engine call.  Unlike the process log it has a
+// fixed schema and no levels, so that it can be kept and queried for
+// postmortems and compliance independently of logging.level and
+// logging.format.
+//
+// Lines are only ever appended.  The file may be rotated by copying and
+// truncating it (logrotate's copytruncate): writes go to its end,
+// wherever that is.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	// TypeScaleDecision is a scaling decision, taken on every desired
+	// runner count, whether or not it started runners.
+	TypeScaleDecision = "scale_decision"
+	// TypeRunnerCreated is a runner started by the engine.
+	TypeRunnerCreated = "runner_created"
+	// TypeRunnerDestroyed is a runner destroyed by the engine.
+	TypeRunnerDestroyed = "runner_destroyed"
+	// TypeJobStarted is a job GitHub assigned to a runner.
+	TypeJobStarted = "job_started"
+	// TypeJobCompleted is a job that finished on a runner.
+	TypeJobCompleted = "job_completed"
+	// TypeError is a failed runner start, destroy or scale-up.
+	TypeError = "error"
+)
+
+// Event is one line of the audit log.  Only the fields that apply to
+// its type are set.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+
+	ScaleSet   string `json:"scale_set,omitempty"`
+	ScaleSetID int    `json:"scale_set_id,omitempty"`
+
+	Runner   string `json:"runner,omitempty"`
+	RunnerID string `json:"runner_id,omitempty"`
+
+	JobID      string `json:"job_id,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	// Result is a completed job's result (e.g. "succeeded").
+	Result string `json:"result,omitempty"`
+
+	// Reason is why a runner was created or destroyed (e.g.
+	// "job_completed", "startup_timeout"), or was being destroyed when an
+	// error occurred.
+	Reason string `json:"reason,omitempty"`
+
+	// Decision is set for TypeScaleDecision.
+	Decision *Decision `json:"decision,omitempty"`
+
+	// Op is the operation that failed for TypeError: "start_runner",
+	// "destroy_runner" or "scale_up".
+	Op string `json:"op,omitempty"`
+	// Error is the error message of TypeError.
+	Error string `json:"error,omitempty"`
+}
+
+// Decision is a scaling decision: Target = min(MaxRunners, MinRunners +
+// Desired).
+type Decision struct {
+	MinRunners int `json:"min_runners"`
+	MaxRunners int `json:"max_runners"`
+	// Desired is the count of jobs GitHub wanted runners for, Current
+	// the runners held and Target the runner count aimed for.
+	Desired int `json:"desired"`
+	Current int `json:"current"`
+	Target  int `json:"target"`
+	// Action is "up", "down" or "none".
+	Action string `json:"action"`
+	// Delta is the runners started (up) or the shortfall left to drain
+	// (down, negative).
+	Delta int `json:"delta"`
+}
+
+// Log appends events to an audit log file.  It is safe for concurrent
+// use; the Logs returned by WithScaleSet share its file.
+type Log struct {
+	file     *file
+	scaleSet string
+}
+
+type file struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// Open opens the audit log at path for appending, creating it and its
+// directory if needed.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &Log{file: &file{f: f}}, nil
+}
+
+// WithScaleSet returns a Log that records events with ScaleSet set to
+// name, for processes running several scale sets.
+func (l *Log) WithScaleSet(name string) *Log {
+	return &Log{file: l.file, scaleSet: name}
+}
+
+// Record appends ev as a line, setting its Time to now if it is zero and
+// its ScaleSet to the Log's if it is empty.
+func (l *Log) Record(ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC()
+	if ev.ScaleSet == "" {
+		ev.ScaleSet = l.scaleSet
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode audit event: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.file.mu.Lock()
+	defer l.file.mu.Unlock()
+	if l.file.f == nil {
+		return fmt.Errorf("write audit event: %w", os.ErrClosed)
+	}
+	if _, err := l.file.f.Write(line); err != nil {
+		return fmt.Errorf("write audit event: %w", err)
+	}
+	return nil
+}
+
+// Close flushes the file to disk and closes it.  Events recorded after
+// Close fail.
+func (l *Log) Close() error {
+	l.file.mu.Lock()
+	defer l.file.mu.Unlock()
+	if l.file.f == nil {
+		return nil
+	}
+	f := l.file.f
+	l.file.f = nil
+	return errors.Join(f.Sync(), f.Close())
+}
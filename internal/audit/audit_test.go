@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, path string) []Event {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var out []Event
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var ev Event
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &ev), sc.Text())
+		out = append(out, ev)
+	}
+	require.NoError(t, sc.Err())
+	return out
+}
+
+func TestLog_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "scaleset.jsonl")
+	l, err := Open(path)
+	require.NoError(t, err)
+
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	require.NoError(t, l.Record(Event{Time: at, Type: TypeScaleDecision, ScaleSetID: 3,
+		Decision: &Decision{MaxRunners: 5, Desired: 2, Target: 2, Action: "up", Delta: 2}}))
+	require.NoError(t, l.WithScaleSet("linux").Record(Event{Type: TypeRunnerDestroyed, Runner: "runner-1", Reason: "job_completed"}))
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `{"time":"2026-10-16T10:00:00Z","type":"scale_decision","scale_set_id":3,"decision":{"min_runners":0,"max_runners":5,"desired":2,"current":0,"target":2,"action":"up","delta":2}}`+"\n")
+
+	events := readEvents(t, path)
+	require.Len(t, events, 2)
+	assert.Equal(t, "linux", events[1].ScaleSet)
+	assert.False(t, events[1].Time.IsZero())
+
+	// Reopening appends.
+	l, err = Open(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Record(Event{Type: TypeJobStarted, JobID: "42"}))
+	require.NoError(t, l.Close())
+	assert.Len(t, readEvents(t, path), 3)
+
+	assert.ErrorIs(t, l.Record(Event{Type: TypeJobCompleted}), os.ErrClosed)
+}
+
+func TestLog_ConcurrentRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		scoped := l.WithScaleSet(string(rune('a' + i)))
+		wg.Go(func() {
+			for range 50 {
+				assert.NoError(t, scoped.Record(Event{Type: TypeRunnerCreated, Runner: "runner"}))
+			}
+		})
+	}
+	wg.Wait()
+	require.NoError(t, l.Close())
+	assert.Len(t, readEvents(t, path), 400)
+}
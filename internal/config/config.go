@@ -1,23 +1,43 @@
 // Package config handles loading, validating, and applying
 // configuration for the scaleset runner.  Configuration is read from a
-// YAML file and can be overridden by CLI flags.
+// YAML file and can be overridden by CLI flags and SCALESET_*
+// environment variables.
 package config
 
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/actions/scaleset"
+	"github.com/docker/docker/api/types/container"
+	"google.golang.org/protobuf/types/known/structpb"
 	"gopkg.in/yaml.v3"
 
 	"github.com/terrpan/scaleset/internal/buildinfo"
+	"github.com/terrpan/scaleset/internal/cron"
+	"github.com/terrpan/scaleset/internal/egress"
 	"github.com/terrpan/scaleset/internal/engine"
 	"github.com/terrpan/scaleset/internal/engine/docker"
+	"github.com/terrpan/scaleset/internal/engine/failover"
+	"github.com/terrpan/scaleset/internal/engine/fake"
 	"github.com/terrpan/scaleset/internal/engine/gcp"
+	"github.com/terrpan/scaleset/internal/engine/hcloud"
+	"github.com/terrpan/scaleset/internal/engine/kubernetes"
+	"github.com/terrpan/scaleset/internal/engine/plugin"
+	"github.com/terrpan/scaleset/internal/engine/podman"
+	"github.com/terrpan/scaleset/internal/leader"
+	"github.com/terrpan/scaleset/internal/secrets"
 )
 
 // ---------------------------------------------------------------------------
@@ -32,6 +52,18 @@ type Config struct {
 	Logging    LoggingConfig    `yaml:"logging"`
 	OTel       OTelConfig       `yaml:"otel"`
 	Prometheus PrometheusConfig `yaml:"prometheus"`
+	Health     HealthConfig     `yaml:"health"`
+	Admin      AdminConfig      `yaml:"admin"`
+	Webhook    WebhookConfig    `yaml:"webhook"`
+	State      StateConfig      `yaml:"state"`
+	Audit      AuditConfig      `yaml:"audit"`
+	Network    NetworkConfig    `yaml:"network"`
+	Leader     LeaderConfig     `yaml:"leader_election"`
+
+	// ScaleSets runs several scale sets in one process (see
+	// ScaleSetEntry).  When set, scaleset, engine and state are
+	// configured per entry instead of at the top level.
+	ScaleSets []ScaleSetEntry `yaml:"scale_sets"`
 }
 
 // ---------------------------------------------------------------------------
@@ -44,10 +76,23 @@ type GitHubConfig struct {
 	// (e.g. https://github.com/org/repo).
 	URL string `yaml:"url"`
 
+	// APIURL optionally overrides the GitHub API base URL.  When empty it
+	// is derived from URL the same way the scaleset SDK does
+	// (https://api.github.com for github.com, <host>/api/v3 for GHES).
+	APIURL string `yaml:"api_url"`
+
+	// Enterprise declares that URL is a GitHub Enterprise Server.  GHES
+	// is recognised from any host other than github.com and *.ghe.com
+	// anyway; with Enterprise set, startup also fails when the server
+	// does not report a supported GHES version instead of only warning.
+	Enterprise bool `yaml:"enterprise"`
+
 	// App holds GitHub App credentials (recommended).
 	App GitHubAppConfig `yaml:"app"`
 
-	// Token is a personal access token (alternative to App).
+	// Token is a personal access token (alternative to App).  Like
+	// App.PrivateKey, it may refer to a secrets manager instead (e.g.
+	// "vault:secret/data/github#token"; see ResolveSecrets).
 	Token string `yaml:"token"`
 }
 
@@ -73,6 +118,195 @@ type ScaleSetConfig struct {
 	RunnerGroup string   `yaml:"runner_group"`
 	MinRunners  int      `yaml:"min_runners"`
 	MaxRunners  int      `yaml:"max_runners"`
+
+	// MaxConcurrentDestroys bounds how many runners may be destroyed
+	// in parallel: on shutdown and drain, and by the background watchers
+	// and the admin API.  Job completions are handled one at a time, so
+	// they never reach it on their own.  Default: 10.
+	MaxConcurrentDestroys int `yaml:"max_concurrent_destroys"`
+
+	// CreateRetries is how many times creating the scale set is retried
+	// when GitHub reports it already exists (e.g. the previous process's
+	// delete has not propagated yet) before the existing one is reused.
+	// Default: 3.
+	CreateRetries int `yaml:"create_retries"`
+
+	// CreateRetryDelay is the base backoff between those retries; it
+	// doubles after each retry.  Default: 2s.
+	CreateRetryDelay time.Duration `yaml:"create_retry_delay"`
+
+	// ShutdownRetries is how many times destroying a runner is retried
+	// during shutdown before it is reported as leaked.  Default: 3.
+	ShutdownRetries int `yaml:"shutdown_retries"`
+
+	// ShutdownRetryDelay is the base backoff between those retries; it
+	// doubles after each retry.  Default: 1s.
+	ShutdownRetryDelay time.Duration `yaml:"shutdown_retry_delay"`
+
+	// StartParallelism is how many runners of a scale-up are started
+	// concurrently (each VM insert or container create is waited on
+	// separately).  1 starts them one at a time.  Default: 5.
+	StartParallelism int `yaml:"start_parallelism"`
+
+	// StartRetries is how many times a failed runner start is retried
+	// before the scale-up is abandoned until the next message.
+	// Default: 0 (no retries).
+	StartRetries int `yaml:"start_retries"`
+
+	// StartRetryDelay is the base backoff between start retries; it
+	// doubles after each retry, with jitter.  Default: 1s.
+	StartRetryDelay time.Duration `yaml:"start_retry_delay"`
+
+	// StartRetryMaxDelay caps the backoff between start retries.
+	// Default: 30s.
+	StartRetryMaxDelay time.Duration `yaml:"start_retry_max_delay"`
+
+	// StartRate caps runner starts per second across all scale-ups
+	// (token bucket).  Default: 0 (unlimited).
+	StartRate float64 `yaml:"start_rate"`
+
+	// StartBurst is how many runners may start back to back before
+	// StartRate applies.  Default: 1 when start_rate is set.
+	StartBurst int `yaml:"start_burst"`
+
+	// SlowMessageThreshold logs a warning when handling a listener
+	// message takes longer than this.  Default: 0 (no warning).
+	SlowMessageThreshold time.Duration `yaml:"slow_message_threshold"`
+
+	// StartupDurationBuckets are the bucket boundaries (seconds) of the
+	// scaleset.runner.startup.duration histogram, in increasing order.
+	// Default: tuned to the primary engine (seconds for docker, minutes
+	// for gcp).
+	StartupDurationBuckets []float64 `yaml:"startup_duration_buckets"`
+
+	// IdempotentStarts retries a failed runner start under the same name
+	// and adopts the runner if the backend created it after all, instead
+	// of starting a duplicate.  Default: false.
+	IdempotentStarts bool `yaml:"idempotent_starts"`
+
+	// StartDeadline is the longest an engine start may take before it is
+	// cancelled, its partial resource destroyed and the start counted as
+	// failed.  Default: 0 (no deadline).
+	StartDeadline time.Duration `yaml:"start_deadline"`
+
+	// StartupTimeout destroys and replaces a runner that has stayed idle
+	// this long after starting, assuming it never came online (bad
+	// image, lost JIT config).  Idle min_runners runners that wait longer
+	// for a job are replaced too.  Default: 0 (disabled).
+	StartupTimeout time.Duration `yaml:"startup_timeout"`
+
+	// MaxRunnerLifetime destroys and replaces any runner, idle or busy,
+	// that has existed this long, so a hung job or zombie VM cannot run
+	// up costs indefinitely.  A busy runner's job is cancelled.
+	// Default: 0 (disabled).
+	MaxRunnerLifetime time.Duration `yaml:"max_runner_lifetime"`
+
+	// CapacityProbeInterval caps scale-up at the number of runners held
+	// when an engine start fails, retrying with a single start once per
+	// interval until capacity returns.  Default: 0 (retry every message).
+	CapacityProbeInterval time.Duration `yaml:"capacity_probe_interval"`
+
+	// RunID tags every runner resource this process creates (Docker
+	// label, GCP label) so `scaleset cleanup --run-id` can find them
+	// after a crash.  Up to 63 lowercase letters, digits, '-' or '_'.
+	// Default: a random ID generated at startup.
+	RunID string `yaml:"run_id"`
+
+	// ReconcileInterval periodically compares the engine's runner
+	// resources carrying RunID with the scaler's state and GitHub's
+	// runners, destroying orphans and adopting stragglers.  Pin RunID so
+	// a restarted process reconciles its predecessor's resources.
+	// Default: 0 (disabled).
+	ReconcileInterval time.Duration `yaml:"reconcile_interval"`
+
+	// BusyCheckInterval periodically looks up the busy runners with
+	// GitHub and destroys those it no longer lists in two consecutive
+	// checks: their job finished but the JobCompleted message was lost.
+	// Default: 0 (disabled).
+	BusyCheckInterval time.Duration `yaml:"busy_check_interval"`
+
+	// CleanupOnStartup destroys, before scaling starts, the managed
+	// runner resources of any run ID that the restored state does not
+	// account for and that are not registered with GitHub as runners of
+	// another scale set: the leftovers of a crashed process.
+	// Default: false.
+	CleanupOnStartup bool `yaml:"cleanup_on_startup"`
+
+	// NameSuffix appends "-<suffix>" to name when the scale set is
+	// created, so deployments sharing a config (e.g. one per pull
+	// request) get isolated scale sets: "random" (8 hex characters),
+	// "env:VAR" (the value of $VAR, e.g. "env:PR_NUMBER") or "git-sha"
+	// ($GITHUB_SHA, else the working directory's HEAD, shortened).  The
+	// default label follows the suffixed name.  Default: "" (none).
+	NameSuffix string `yaml:"name_suffix"`
+
+	// MaxProcessLifetime makes the process drain and exit cleanly after
+	// running this long, relying on its supervisor to restart it (e.g.
+	// to pick up rotated credentials).  With scale_sets, the shortest
+	// lifetime of the entries bounds the process.  Default: 0 (no limit).
+	MaxProcessLifetime time.Duration `yaml:"max_process_lifetime"`
+
+	// RunnerWorkFolder sets the runner's work folder through its JIT
+	// settings, for images with a custom disk layout.  Relative paths
+	// are resolved against the runner directory.  Default: "" (the
+	// runner's "_work").
+	RunnerWorkFolder string `yaml:"runner_work_folder"`
+
+	// SessionOwner is the owner name of the listener's message session.
+	// A deterministic value (pod name, deployment name) identifies the
+	// process better than the hostname.  Default: the hostname, or a random UUID when
+	// it cannot be read.
+	SessionOwner string `yaml:"session_owner"`
+
+	// ConcurrentInit initializes the engine (client creation, image
+	// pulls) while the runner group is looked up and the scale set
+	// registered, instead of afterwards, to shorten startup.  Engine log
+	// entries then carry scale_set but not scale_set_id when
+	// logging.include_scale_set is enabled.  Default: false.
+	ConcurrentInit bool `yaml:"concurrent_init"`
+
+	// AdmissionCheck runs the engine's health check before each
+	// scale-up and skips the scale-up while the engine is unhealthy,
+	// instead of failing each runner start.  Default: false.
+	AdmissionCheck bool `yaml:"admission_check"`
+
+	// AdmissionCheckTimeout bounds each admission check.  Default: 5s.
+	AdmissionCheckTimeout time.Duration `yaml:"admission_check_timeout"`
+
+	// MaxRunnersPerRepo caps how many runners jobs from one repository
+	// may hold busy at once, so one repository cannot consume an
+	// organization scale set.  Runners are provisioned before a job's
+	// repository is known, so a job beyond the cap still runs; the
+	// scaler logs it and does not replace the runner it occupies.
+	// Default: 0 (unlimited).
+	MaxRunnersPerRepo int `yaml:"max_runners_per_repo"`
+
+	// RepoRunnerLimits overrides MaxRunnersPerRepo per repository name;
+	// 0 exempts a repository.
+	RepoRunnerLimits map[string]int `yaml:"repo_runner_limits"`
+
+	// Schedules change min_runners and max_runners during recurring time
+	// windows (see RunnerSchedule), e.g. to keep warm runners during
+	// business hours only.  The first active schedule wins; outside all
+	// of them MinRunners and MaxRunners apply.
+	Schedules []RunnerSchedule `yaml:"schedules"`
+
+	// AdoptExisting reuses a scale set already registered under Name
+	// (kept by a previous process, left behind by a crash, or registered
+	// by another replica) instead of creating it, updating its labels.
+	// Default: false (create, and reuse only after CreateRetries).
+	AdoptExisting bool `yaml:"adopt_existing"`
+
+	// KeepOnShutdown leaves the scale set registered when the process
+	// exits instead of deleting it, so the next process, or another
+	// replica, adopts it (see AdoptExisting) and queued jobs are not
+	// dropped.  Default: false.
+	KeepOnShutdown bool `yaml:"keep_on_shutdown"`
+
+	// Flavors register one scale set per runner flavor (see Flavor)
+	// instead of this one, so jobs pick a machine size with runs-on.
+	// Not allowed with scale_sets or engine.profiles.
+	Flavors []Flavor `yaml:"flavors"`
 }
 
 // ---------------------------------------------------------------------------
@@ -88,11 +322,93 @@ type EngineConfig struct {
 	// GCP holds GCP Compute Engine settings.
 	GCP GCPEngineConfig `yaml:"gcp"`
 
+	// Kubernetes holds Kubernetes pod settings.
+	Kubernetes KubernetesEngineConfig `yaml:"kubernetes"`
+
+	// HCloud holds Hetzner Cloud server settings.
+	HCloud HCloudEngineConfig `yaml:"hcloud"`
+
+	// Podman holds Podman container settings.
+	Podman PodmanEngineConfig `yaml:"podman"`
+
+	// Plugin holds the settings of an out-of-tree engine plugin.
+	Plugin PluginEngineConfig `yaml:"plugin"`
+
+	// Fake holds the settings of the in-memory test engine.
+	Fake FakeEngineConfig `yaml:"fake"`
+
 	// AWS holds AWS EC2 settings (not yet implemented).
 	AWS AWSEngineConfig `yaml:"aws"`
 
 	// Azure holds Azure VM settings (not yet implemented).
 	Azure AzureEngineConfig `yaml:"azure"`
+
+	// Fallback optionally lists engines to fail over to when this one
+	// keeps failing to start runners.  Only valid on the primary engine.
+	Fallback *EngineFallbackConfig `yaml:"fallback"`
+
+	// Profiles override engine resources by scale set label.  Only
+	// valid on the primary engine; the selected profile applies to the
+	// fallback engines too.
+	Profiles []EngineProfile `yaml:"profiles"`
+}
+
+// EngineProfile overrides engine resource settings (machine type, disk,
+// accelerators, image) for a scale set whose labels include all of
+// Labels, e.g. a "gpu" profile giving VMs a GPU.
+//
+// A job's labels are only known once it is assigned, but runners are
+// provisioned before that, so a profile cannot be picked per job.  It
+// is picked once per scale set from the scale set's own labels: run one
+// scale set per profile (e.g. "ci-gpu" labelled gpu, "ci" unlabelled),
+// and jobs select one with runs-on.  At most one profile may match.
+type EngineProfile struct {
+	// Name identifies the profile in logs and errors.
+	Name string `yaml:"name"`
+
+	// Labels must all be among the scale set's labels (compared
+	// case-insensitively) for the profile to apply.
+	Labels []string `yaml:"labels"`
+
+	// Docker overrides Docker engine settings.
+	Docker DockerProfile `yaml:"docker"`
+
+	// GCP overrides GCP engine settings.
+	GCP GCPProfile `yaml:"gcp"`
+}
+
+// DockerProfile holds the Docker settings a profile can override.
+// Empty fields keep the engine's value.
+type DockerProfile struct {
+	Image string `yaml:"image"`
+	GPUs  string `yaml:"gpus"`
+}
+
+// GCPProfile holds the GCP settings a profile can override.  Zero
+// fields keep the engine's value.
+type GCPProfile struct {
+	MachineType    string           `yaml:"machine_type"`
+	Image          string           `yaml:"image"`
+	DiskSizeGB     int64            `yaml:"disk_size_gb"`
+	DiskType       string           `yaml:"disk_type"`
+	DiskIOPS       int64            `yaml:"disk_iops"`
+	DiskThroughput int64            `yaml:"disk_throughput"`
+	Accelerators   []GCPAccelerator `yaml:"accelerators"`
+}
+
+// EngineFallbackConfig configures the engine failover chain.
+type EngineFallbackConfig struct {
+	// AfterFailures is the number of consecutive start failures after
+	// which new runners go to the next engine.  Default: 3.
+	AfterFailures int `yaml:"after_failures"`
+
+	// RetryPrimaryAfter is how long to stay on a fallback before trying
+	// the primary again.  Default: 0 (until restart).
+	RetryPrimaryAfter time.Duration `yaml:"retry_primary_after"`
+
+	// Engines are tried in order after the primary.  Each enables
+	// exactly one engine, like the primary.
+	Engines []EngineConfig `yaml:"engines"`
 }
 
 // DockerEngineConfig holds Docker-specific engine settings.
@@ -103,9 +419,96 @@ type DockerEngineConfig struct {
 	// the newest release, or pin a specific version (e.g. "ghcr.io/actions/actions-runner:2.323.0").
 	// Default: "ghcr.io/actions/actions-runner:latest"
 	Image string `yaml:"image"`
-	// Dind enables Docker-in-Docker by bind-mounting the host's
-	// Docker socket into each runner container.
+	// PinImageDigest resolves image to its digest at startup and creates
+	// every runner from it, so a moving tag such as ":latest" cannot
+	// change the runner version mid-process.  Default: false.
+	PinImageDigest bool `yaml:"pin_image_digest"`
+	// Host is the Docker daemon address (e.g. "tcp://10.0.0.5:2376").
+	// Default: DOCKER_HOST, else the local socket.
+	Host string `yaml:"host"`
+	// Dind enables Docker-in-Docker for workflows; dind_mode selects how.
 	Dind bool `yaml:"dind"`
+	// DindMode is "socket" (default) or "sidecar".  Socket mode
+	// bind-mounts the host's Docker socket into each runner container
+	// and requires a local daemon (unix socket, named pipe or loopback
+	// TCP).  Sidecar mode gives each runner a private dind_image daemon
+	// on its own network, isolating workflows from the host daemon.
+	DindMode string `yaml:"dind_mode"`
+	// DindImage is the daemon image of sidecar mode.
+	// Default: "docker:dind".
+	DindImage string `yaml:"dind_image"`
+	// DindCleanup removes containers labelled "scaleset.parent=<runner>"
+	// from the host daemon when a DinD runner is destroyed.  Requires dind
+	// in socket mode.
+	DindCleanup bool `yaml:"dind_cleanup"`
+	// StopTimeout is the grace period a runner container gets to exit
+	// before being force-removed (e.g. "30s").  Default: 0 (immediate).
+	StopTimeout time.Duration `yaml:"stop_timeout"`
+	// StartRetries is how many times a failed container start is retried
+	// before the runner is given up on.  Default: 0 (no retry).
+	StartRetries int `yaml:"start_retries"`
+	// Init runs Docker's init process as PID 1 in runner containers for
+	// signal forwarding and zombie reaping.  Default: false.
+	Init bool `yaml:"init"`
+	// ContextEnv injects scale set context (SCALESET_NAME,
+	// SCALESET_GITHUB_URL, SCALESET_ORG, ...) into every runner's
+	// environment.  Default: false.
+	ContextEnv bool `yaml:"context_env"`
+	// Env holds extra environment variables set in every runner
+	// container (e.g. a repository allowlist for workflow tooling).
+	Env map[string]string `yaml:"env"`
+	// PreloadImages are pulled at startup alongside the runner image
+	// (e.g. job container images used through dind).
+	PreloadImages []DockerPreloadImage `yaml:"preload_images"`
+	// PreloadConcurrency bounds concurrent startup pulls.  Default: 4.
+	PreloadConcurrency int `yaml:"preload_concurrency"`
+	// PreloadTimeout bounds each startup pull, including the runner
+	// image (e.g. "5m").  Default: 0 (no timeout).
+	PreloadTimeout time.Duration `yaml:"preload_timeout"`
+
+	// CPUs caps the CPUs each runner container may use (e.g. 2 or 1.5,
+	// as docker run --cpus).  Default: 0 (unlimited).
+	CPUs float64 `yaml:"cpus"`
+	// Memory caps each runner container's memory (e.g. "4g", "512m").
+	// Default: "" (unlimited).
+	Memory string `yaml:"memory"`
+	// PidsLimit caps the number of processes in each runner container,
+	// e.g. against fork bombs.  Default: 0 (unlimited).
+	PidsLimit int64 `yaml:"pids_limit"`
+	// ShmSize is the size of /dev/shm in each runner container (e.g.
+	// "1g" for browser tests).  Default: "" (Docker's 64m).
+	ShmSize string `yaml:"shm_size"`
+
+	// Network is an existing Docker network runner containers join
+	// instead of the default bridge (e.g. a dedicated bridge shared with
+	// a cache proxy).  Not allowed with dind_mode "sidecar".  Default: ""
+	// (the daemon's default bridge).
+	Network string `yaml:"network"`
+	// ExtraHosts adds hosts file entries ("name:ip", or
+	// "name:host-gateway") to every runner container, as docker run
+	// --add-host.
+	ExtraHosts []string `yaml:"extra_hosts"`
+	// Mounts mounts volumes into every runner container, as docker run
+	// --volume: "source:target[:options]", where source is a path on
+	// the daemon's host or a named volume (e.g.
+	// "/var/cache/go-mod:/home/runner/go/pkg/mod:rw").  Runners share
+	// them, so mount only caches that tolerate concurrent use.
+	Mounts []string `yaml:"mounts"`
+	// GPUs passes host GPUs through to every runner container, as
+	// docker run --gpus: "all" or comma-separated device indexes or
+	// UUIDs (e.g. "0,1").  Needs the NVIDIA Container Toolkit on the
+	// daemon's host.  Usually set per flavor or profile (e.g. a "gpu"
+	// flavor).  Default: "" (none).
+	GPUs string `yaml:"gpus"`
+}
+
+// DockerPreloadImage is one entry of engine.docker.preload_images.
+type DockerPreloadImage struct {
+	// Image is the image reference, e.g. "node:20".
+	Image string `yaml:"image"`
+	// Required aborts startup when the image cannot be pulled.
+	// Default: false (log and continue).
+	Required bool `yaml:"required"`
 }
 
 // GCPEngineConfig holds GCP Compute Engine engine settings.
@@ -119,9 +522,16 @@ type GCPEngineConfig struct {
 	// Project is the GCP project ID (required when GCP is enabled).
 	Project string `yaml:"project"`
 
-	// Zone is the GCP zone for runner VMs (required).
+	// Zone is the GCP zone for runner VMs (required unless zones is
+	// set).
 	Zone string `yaml:"zone"`
 
+	// Zones spreads runner VMs round-robin across several zones of one
+	// region, retrying in the next zone when one is out of capacity
+	// (ZONE_RESOURCE_POOL_EXHAUSTED).  zone defaults to the first and
+	// must be one of them.  Default: zone alone.
+	Zones []string `yaml:"zones"`
+
 	// MachineType is the Compute Engine machine type.  Default: "e2-medium".
 	MachineType string `yaml:"machine_type"`
 
@@ -134,6 +544,20 @@ type GCPEngineConfig struct {
 	// DiskSizeGB is the boot disk size in GB.  Default: 50.
 	DiskSizeGB int64 `yaml:"disk_size_gb"`
 
+	// DiskType is the boot disk type (e.g. "pd-balanced", "pd-extreme",
+	// "hyperdisk-balanced").  Default: "pd-ssd".
+	DiskType string `yaml:"disk_type"`
+
+	// DiskIOPS is the boot disk's provisioned IOPS (pd-extreme,
+	// hyperdisk-balanced, hyperdisk-extreme).  Default: 0 (the disk
+	// type's default).
+	DiskIOPS int64 `yaml:"disk_iops"`
+
+	// DiskThroughput is the boot disk's provisioned throughput in MiB/s
+	// (hyperdisk-balanced, hyperdisk-throughput).  Default: 0 (the disk
+	// type's default).
+	DiskThroughput int64 `yaml:"disk_throughput"`
+
 	// Network is the VPC network name.  Default: "default".
 	Network string `yaml:"network"`
 
@@ -141,6 +565,10 @@ type GCPEngineConfig struct {
 	// subnet for the zone is used.
 	Subnet string `yaml:"subnet"`
 
+	// AutoSubnet picks the network's subnet in the zone's region when
+	// subnet is empty and the network is custom-mode.  Default: false.
+	AutoSubnet bool `yaml:"auto_subnet"`
+
 	// PublicIP controls whether runner VMs get an external IP address.
 	// Default: true.  Use a *bool so we can distinguish "not set"
 	// (nil -> default true) from "explicitly set to false".
@@ -149,6 +577,216 @@ type GCPEngineConfig struct {
 	// ServiceAccount is the GCP service account email to attach to
 	// runner VMs (optional).
 	ServiceAccount string `yaml:"service_account"`
+
+	// UseBulkInsert creates large scale-ups with a single bulkInsert
+	// call instead of one insert per VM.  Default: false.
+	UseBulkInsert bool `yaml:"use_bulk_insert"`
+
+	// BulkInsertThreshold is the minimum scale-up size that uses
+	// bulkInsert.  Default: 5.
+	BulkInsertThreshold int `yaml:"bulk_insert_threshold"`
+
+	// ZoneInRunnerName appends the zone to runner names (e.g.
+	// "runner-1a2b3c4d-us-central1-a") to ease debugging across zones.
+	ZoneInRunnerName bool `yaml:"zone_in_runner_name"`
+
+	// QuotaCheck verifies at startup that the region's free CPUS and
+	// INSTANCES quota covers max_runners VMs of machine_type.  "warn"
+	// logs a shortfall, "error" refuses to start.  Default: "" (off).
+	QuotaCheck string `yaml:"quota_check"`
+
+	// Metadata is extra instance metadata set on every runner VM (e.g.
+	// "startup-script").  ACTIONS_RUNNER_INPUT_JITCONFIG is reserved.
+	Metadata map[string]string `yaml:"metadata"`
+
+	// MetadataFromFile sets metadata values from files, read when the
+	// config is loaded, for content too large to inline (e.g.
+	// user-data: /etc/scaleset/cloud-init.yaml).  A key may not also be
+	// set in metadata.  It has no environment variable, since the files
+	// are read before the environment is applied.
+	MetadataFromFile map[string]string `yaml:"metadata_from_file" env:"-"`
+
+	// StartupScript is run as root by the runner image's startup script
+	// (see docs/gcp) before the runner starts, e.g. to install tools or
+	// mount disks; if it fails, the runner does not start.
+	StartupScript string `yaml:"startup_script"`
+
+	// StartupScriptPath reads startup_script from a file when the config
+	// is loaded.  It cannot be combined with startup_script.
+	StartupScriptPath string `yaml:"startup_script_path" env:"-"`
+
+	// Accelerators are attached to every runner VM (e.g.
+	// {type: nvidia-tesla-t4, count: 1}); the machine type must support
+	// them.  Such VMs terminate on host maintenance.
+	Accelerators []GCPAccelerator `yaml:"accelerators"`
+
+	// Spot creates runner VMs as spot VMs, which cost far less but can
+	// be preempted at any time.  The reconciler replaces preempted
+	// runners, so scaleset.reconcile_interval must be set.
+	// Default: false.
+	Spot bool `yaml:"spot"`
+}
+
+// GCPAccelerator is a GPU type and count to attach to runner VMs.
+type GCPAccelerator struct {
+	// Type is the accelerator type, e.g. "nvidia-tesla-t4".
+	Type string `yaml:"type"`
+	// Count is how many to attach.
+	Count int32 `yaml:"count"`
+}
+
+// KubernetesEngineConfig holds Kubernetes engine settings.  Runners run
+// as pods in the cluster scaleset itself runs in; the pod template
+// fields apply to every runner pod.
+type KubernetesEngineConfig struct {
+	// Enable activates the Kubernetes engine.
+	Enable bool `yaml:"enable"`
+	// Namespace is where runner pods are created.  Default: the
+	// namespace scaleset runs in.
+	Namespace string `yaml:"namespace"`
+	// Image is the runner container image.
+	// Default: "ghcr.io/actions/actions-runner:latest"
+	Image string `yaml:"image"`
+	// Resources are the runner container's requests and limits.
+	Resources KubernetesResources `yaml:"resources"`
+	// NodeSelector constrains runner pods to nodes with these labels.
+	NodeSelector map[string]string `yaml:"node_selector"`
+	// Tolerations let runner pods schedule onto tainted nodes.
+	Tolerations []KubernetesToleration `yaml:"tolerations"`
+	// ServiceAccountName is the service account runner pods run as.
+	// Default: none, and no API token is mounted into runner pods.
+	ServiceAccountName string `yaml:"service_account_name"`
+	// Env holds extra environment variables set in every runner
+	// container.
+	Env map[string]string `yaml:"env"`
+}
+
+// KubernetesResources are container resource requests and limits, e.g.
+// {cpu: "2", memory: "4Gi"}.
+type KubernetesResources struct {
+	Requests map[string]string `yaml:"requests"`
+	Limits   map[string]string `yaml:"limits"`
+}
+
+// KubernetesToleration is a pod toleration.
+type KubernetesToleration struct {
+	Key string `yaml:"key"`
+	// Operator is "Equal" (default) or "Exists".
+	Operator string `yaml:"operator"`
+	Value    string `yaml:"value"`
+	// Effect is "NoSchedule", "PreferNoSchedule" or "NoExecute"; empty
+	// matches all effects.
+	Effect string `yaml:"effect"`
+}
+
+// HCloudEngineConfig holds Hetzner Cloud engine settings.  Each runner
+// is an ephemeral server that receives its JIT config via cloud-init
+// user data, so the image must have cloud-init and the runner installed.
+type HCloudEngineConfig struct {
+	// Enable activates the Hetzner Cloud engine.
+	Enable bool `yaml:"enable"`
+	// Token is the API token of the Hetzner Cloud project.  Default:
+	// the HCLOUD_TOKEN environment variable.
+	Token string `yaml:"token"`
+	// ServerType is the server type, e.g. "cx22" or "cax21".
+	// Default: "cx22".
+	ServerType string `yaml:"server_type"`
+	// Image is the image name, ID or snapshot ID.
+	// Default: "ubuntu-24.04".
+	Image string `yaml:"image"`
+	// Location is the location servers are created in, e.g. "fsn1".
+	// Default: chosen by Hetzner.
+	Location string `yaml:"location"`
+	// SSHKeys are names or IDs of project SSH keys added to servers.
+	// Optional; Hetzner emails a root password when none is set.
+	SSHKeys []string `yaml:"ssh_keys"`
+	// PublicIPv4 gives servers a public IPv4 address.  Default: true.
+	PublicIPv4 *bool `yaml:"public_ipv4"`
+	// RunnerDir is the directory of the runner on the image.
+	// Default: "/home/runner".
+	RunnerDir string `yaml:"runner_dir"`
+	// RunnerUser is the user the runner runs as.  Default: "runner".
+	RunnerUser string `yaml:"runner_user"`
+}
+
+// PodmanEngineConfig holds Podman engine settings.  Runners are
+// containers created through the Docker-compatible API of the Podman
+// socket, which can belong to an unprivileged user (rootless Podman).
+type PodmanEngineConfig struct {
+	// Enable activates the Podman engine.
+	Enable bool `yaml:"enable"`
+	// Socket is the Podman socket address.  Default: CONTAINER_HOST,
+	// else the user's socket (unix://$XDG_RUNTIME_DIR/podman/podman.sock,
+	// or unix:///run/podman/podman.sock for root).
+	Socket string `yaml:"socket"`
+	// Image is the container image for the runner.
+	// Default: "ghcr.io/actions/actions-runner:latest"
+	Image string `yaml:"image"`
+	// PinImageDigest creates every runner from the digest image resolves
+	// to at startup.  Default: false.
+	PinImageDigest bool `yaml:"pin_image_digest"`
+	// UserNS is the user namespace mode of runner containers, as podman
+	// run --userns (e.g. "keep-id", "auto").  Default: Podman's.
+	UserNS string `yaml:"userns"`
+	// StopTimeout is the grace period a runner container gets to exit
+	// before being force-removed (e.g. "30s").  Default: 0 (immediate).
+	StopTimeout time.Duration `yaml:"stop_timeout"`
+	// Init runs an init process as PID 1 in runner containers.
+	// Default: false.
+	Init bool `yaml:"init"`
+	// Env holds extra environment variables set in every runner
+	// container.
+	Env map[string]string `yaml:"env"`
+
+	// CPUs, Memory and PidsLimit cap each runner container through its
+	// cgroup, which rootless Podman only supports on cgroup v2.  They
+	// take the same values as the docker engine's.  Default: unlimited.
+	CPUs      float64 `yaml:"cpus"`
+	Memory    string  `yaml:"memory"`
+	PidsLimit int64   `yaml:"pids_limit"`
+	// ShmSize is the size of /dev/shm in each runner container.
+	// Default: "" (Podman's 64m).
+	ShmSize string `yaml:"shm_size"`
+}
+
+// PluginEngineConfig holds the settings of an engine plugin: a binary
+// scaleset starts and talks to over gRPC.  See the plugin package for
+// the protocol.
+type PluginEngineConfig struct {
+	// Enable activates the plugin engine.
+	Enable bool `yaml:"enable"`
+	// Path is the plugin binary (required).
+	Path string `yaml:"path"`
+	// Args are passed to the plugin binary.
+	Args []string `yaml:"args"`
+	// Env names the variables of scaleset's environment passed through
+	// to the plugin (e.g. its cloud credentials).  The plugin otherwise
+	// only gets PATH and HOME, so scaleset's secrets stay out of it.
+	Env []string `yaml:"env"`
+	// Config is passed to the plugin as is; its schema is the plugin's.
+	Config map[string]any `yaml:"config"`
+	// StartTimeout bounds how long the plugin may take to start and
+	// accept its config (e.g. "1m").  Default: 30s.
+	StartTimeout time.Duration `yaml:"start_timeout"`
+}
+
+// FakeEngineConfig holds the settings of the fake engine, which tracks
+// runners in memory and starts nothing.  It is meant for end-to-end tests
+// of the scaler: its runners never pick up jobs.
+type FakeEngineConfig struct {
+	// Enable activates the fake engine.
+	Enable bool `yaml:"enable"`
+	// StartLatency and DestroyLatency delay each runner start and
+	// destroy (e.g. "2s"); Jitter adds up to that much more at random.
+	StartLatency   time.Duration `yaml:"start_latency"`
+	DestroyLatency time.Duration `yaml:"destroy_latency"`
+	Jitter         time.Duration `yaml:"jitter"`
+	// StartFailureRate and DestroyFailureRate are the share of starts
+	// and destroys, from 0 to 1, that fail.  Default: 0.
+	StartFailureRate   float64 `yaml:"start_failure_rate"`
+	DestroyFailureRate float64 `yaml:"destroy_failure_rate"`
+	// Seed makes the jitter and failures reproducible.  Default: random.
+	Seed uint64 `yaml:"seed"`
 }
 
 // AWSEngineConfig holds AWS EC2 engine settings (not yet implemented).
@@ -190,7 +828,8 @@ type AzureEngineConfig struct {
 	DiskSizeGB int64 `yaml:"disk_size_gb"`
 }
 
-// EnabledEngine returns the name of the enabled engine ("docker", "gcp", "aws", or "azure"),
+// EnabledEngine returns the name of the enabled engine ("docker", "gcp",
+// "kubernetes", "hcloud", "podman", "plugin", "fake", "aws", or "azure"),
 // or an empty string if no engine is enabled.
 func (e *EngineConfig) EnabledEngine() string {
 	if e.Docker.Enable {
@@ -199,6 +838,21 @@ func (e *EngineConfig) EnabledEngine() string {
 	if e.GCP.Enable {
 		return "gcp"
 	}
+	if e.Kubernetes.Enable {
+		return "kubernetes"
+	}
+	if e.HCloud.Enable {
+		return "hcloud"
+	}
+	if e.Podman.Enable {
+		return "podman"
+	}
+	if e.Plugin.Enable {
+		return "plugin"
+	}
+	if e.Fake.Enable {
+		return "fake"
+	}
 	if e.AWS.Enable {
 		return "aws"
 	}
@@ -208,6 +862,20 @@ func (e *EngineConfig) EnabledEngine() string {
 	return ""
 }
 
+// Location returns the region and zone the enabled engine runs runners
+// in.  Either may be empty (e.g. Docker has neither).
+func (e *EngineConfig) Location() (region, zone string) {
+	switch {
+	case e.GCP.Enable:
+		return gcp.RegionFromZone(e.GCP.Zone), e.GCP.Zone
+	case e.HCloud.Enable:
+		return e.HCloud.Location, ""
+	case e.AWS.Enable:
+		return e.AWS.Region, ""
+	}
+	return "", ""
+}
+
 // ---------------------------------------------------------------------------
 // Logging
 // ---------------------------------------------------------------------------
@@ -218,6 +886,13 @@ type LoggingConfig struct {
 	Level string `yaml:"level"`
 	// Format: text, json.  Default: text.
 	Format string `yaml:"format"`
+	// Output: stdout, stderr, or a file path (opened in append mode).
+	// Default: stdout.  Rotation of file output is left to external
+	// tooling such as logrotate (use copytruncate).
+	Output string `yaml:"output"`
+	// IncludeScaleSet tags every scaler, engine, and listener log entry
+	// with scale_set and scale_set_id.  Default: false.
+	IncludeScaleSet bool `yaml:"include_scale_set"`
 }
 
 // ---------------------------------------------------------------------------
@@ -239,6 +914,10 @@ type OTelConfig struct {
 
 	// StdOut also prints traces and metrics to stdout (for debugging).  Default: false.
 	StdOut bool `yaml:"stdout"`
+
+	// EngineAttributes adds the engine type and its region/zone as
+	// resource attributes on all metrics and traces.  Default: false.
+	EngineAttributes bool `yaml:"engine_attributes"`
 }
 
 // ---------------------------------------------------------------------------
@@ -256,192 +935,1816 @@ type PrometheusConfig struct {
 }
 
 // ---------------------------------------------------------------------------
-// Loading
+// Health
 // ---------------------------------------------------------------------------
 
-// Load reads a YAML config file from path and returns the parsed Config.
-// If the file does not exist the returned Config will contain zero values
-// which must be filled via flag overrides before calling Validate.
-func Load(path string) (*Config, error) {
-	cfg := &Config{}
+// HealthConfig controls the health server: /healthz, /readyz and
+// optionally POST /drain and the pprof profiles.
+type HealthConfig struct {
+	// Enable starts the health server.  Default: true.  Use a *bool so
+	// we can distinguish "not set" (nil -> default true) from
+	// "explicitly set to false".
+	Enable *bool `yaml:"enable"`
+	// Port is the health server's port.  Default: prometheus.port, so
+	// health and metrics share one server.
+	Port int `yaml:"port"`
+	// Pprof serves the net/http/pprof profiles under /debug/pprof/ on
+	// the health server.  Profiles expose process internals, so keep the
+	// port off untrusted networks.  Default: false.
+	Pprof bool `yaml:"pprof"`
+
+	// Diagnostics includes engine diagnostics (daemon version, free disk,
+	// quota headroom, ...) in the /readyz response.  Default: false.
+	Diagnostics bool `yaml:"diagnostics"`
+	// Labels are static key/value pairs (environment, region, team, ...)
+	// echoed in the /healthz response so status pages can show
+	// deployment context.  They must not contain secrets.
+	Labels map[string]string `yaml:"labels"`
+
+	// DrainEndpoint serves POST /drain on the health server, for a
+	// Kubernetes preStop hook.  The health server listens on all
+	// interfaces without authentication, so anyone who can reach it
+	// could stop scaling: keep the port off untrusted networks, or
+	// drain through the admin API instead.  Default: false.
+	DrainEndpoint bool `yaml:"drain_endpoint"`
+
+	// DrainTimeout bounds how long a POST /drain request waits for busy
+	// runners to finish.  Default: 0 (until drained or the client
+	// disconnects).
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Config file is optional -- flags can supply everything.
-			return cfg, nil
-		}
-		return nil, fmt.Errorf("reading config %s: %w", path, err)
-	}
+// IsEnabled reports whether the health server runs.
+func (h *HealthConfig) IsEnabled() bool {
+	return h.Enable == nil || *h.Enable
+}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("parsing config %s: %w", path, err)
-	}
+// ---------------------------------------------------------------------------
+// Admin
+// ---------------------------------------------------------------------------
 
-	return cfg, nil
+// AdminConfig controls the admin HTTP API, served on a port of its own so
+// it can be kept off the network that scrapes /metrics and probes /healthz.
+type AdminConfig struct {
+	// Enable starts the admin API.  Default: false.
+	Enable bool `yaml:"enable"`
+	// Address is the address the admin API listens on.  Default:
+	// "127.0.0.1" (local connections only).
+	Address string `yaml:"address"`
+	// Port is the admin API's port.  Default: 9092.
+	Port int `yaml:"port"`
+	// Token, when set, must be sent as a bearer token with every request.
+	Token string `yaml:"token"`
 }
 
 // ---------------------------------------------------------------------------
-// Defaults & validation
+// Webhook
 // ---------------------------------------------------------------------------
 
-// ApplyDefaults fills in sensible defaults for any unset fields.
-func (c *Config) ApplyDefaults() {
-	if c.ScaleSet.RunnerGroup == "" {
-		c.ScaleSet.RunnerGroup = scaleset.DefaultRunnerGroup
-	}
-	if c.ScaleSet.MaxRunners == 0 {
-		c.ScaleSet.MaxRunners = 10
+// Webhook modes.
+const (
+	// WebhookModeAssist scales on webhooks next to the message session,
+	// which stays the authority on the desired count and job events.
+	WebhookModeAssist = "assist"
+	// WebhookModeOnly scales on webhooks alone, without a message
+	// session.
+	WebhookModeOnly = "only"
+)
+
+// WebhookConfig controls the receiver for GitHub workflow_job webhooks,
+// served on a port of its own.  The webhook (repository, organization or
+// enterprise) must send workflow_job events as JSON, signed with Secret.
+type WebhookConfig struct {
+	// Enable starts the webhook receiver.  Default: false.
+	Enable bool `yaml:"enable"`
+	// Address is the address the receiver listens on.  Default: "" (all
+	// interfaces).
+	Address string `yaml:"address"`
+	// Port is the receiver's port.  Default: 9093.
+	Port int `yaml:"port"`
+	// Path is the URL path deliveries are posted to.  Default:
+	// "/webhook".
+	Path string `yaml:"path"`
+	// Secret is the webhook's secret, used to check each delivery's
+	// X-Hub-Signature-256.  Required.  May be a secret reference (see
+	// github.token).
+	Secret string `yaml:"secret"`
+	// Mode is "assist" (default) to scale on webhooks as well as the
+	// message session, for lower latency, or "only" to scale on
+	// webhooks alone.
+	Mode string `yaml:"mode"`
+}
+
+// ---------------------------------------------------------------------------
+// State
+// ---------------------------------------------------------------------------
+
+// StateConfig controls persistence of the scaler's runner state for
+// crash recovery.
+type StateConfig struct {
+	// Path is the file the scaler records its runners in (name, engine
+	// id, idle or busy) on every change.  On startup the runners recorded
+	// by a previous process that did not shut down cleanly are re-adopted
+	// instead of leaked.  The file must not be shared between processes.
+	// Default: "" (disabled).
+	Path string `yaml:"path"`
+}
+
+// AuditConfig controls the audit log: an append-only file of JSON lines
+// recording every scaling decision, runner created or destroyed (with
+// the reason), job started or completed, and failed runner start or
+// destroy, separate from the process log.  It is shared by the scale
+// sets of the process; each line names its scale set.
+type AuditConfig struct {
+	// Path is the audit log file, created if needed and appended to.
+	// Default: "" (disabled).
+	Path string `yaml:"path"`
+}
+
+// ---------------------------------------------------------------------------
+// Leader election
+// ---------------------------------------------------------------------------
+
+// LeaderConfig controls leader election between replicas of the
+// process.  Replicas stand by until they hold the lock; only the leader
+// registers the scale sets, opens their message sessions and scales.
+type LeaderConfig struct {
+	// Enable runs for leadership before starting the scale sets.
+	// Default: false.
+	Enable bool `yaml:"enable"`
+	// Backend is the lock: "kubernetes" (a Lease), "file" (flock(2)) or
+	// "redis" (a key with an expiry).
+	Backend string `yaml:"backend"`
+	// Identity names this replica in the lock and in logs; replicas
+	// must not share it.  Default: the hostname (the pod name in
+	// Kubernetes), or a random UUID when it cannot be read.
+	Identity string `yaml:"identity"`
+	// LeaseDuration is how long a leader that stopped renewing keeps the
+	// lock before a follower takes over.  Not used by the file backend,
+	// whose lock the kernel releases when the holder exits.
+	// Default: 15s.
+	LeaseDuration time.Duration `yaml:"lease_duration"`
+	// RenewDeadline is how long the leader keeps leading without a
+	// successful renewal before it steps down and exits.  Must be less
+	// than lease_duration.  Default: 10s.
+	RenewDeadline time.Duration `yaml:"renew_deadline"`
+	// RetryPeriod is how often followers try the lock and the leader
+	// renews it.  Must be less than renew_deadline.  Default: 2s.
+	RetryPeriod time.Duration `yaml:"retry_period"`
+
+	Kubernetes LeaderKubernetesConfig `yaml:"kubernetes"`
+	File       LeaderFileConfig       `yaml:"file"`
+	Redis      LeaderRedisConfig      `yaml:"redis"`
+}
+
+// LeaderKubernetesConfig is the Lease of the kubernetes backend.  The
+// pod's service account needs get, create and update on leases in
+// Namespace.
+type LeaderKubernetesConfig struct {
+	// Namespace of the Lease.  Default: the pod's namespace.
+	Namespace string `yaml:"namespace"`
+	// Name of the Lease.  Default: "scaleset-leader".
+	Name string `yaml:"name"`
+}
+
+// LeaderFileConfig is the lock file of the file backend.
+type LeaderFileConfig struct {
+	// Path of the lock file, created if missing.  Replicas on different
+	// hosts need a shared file system with working flock(2).
+	Path string `yaml:"path"`
+}
+
+// LeaderRedisConfig is the key and server of the redis backend.
+type LeaderRedisConfig struct {
+	// Address is the server's host:port.
+	Address string `yaml:"address"`
+	// Username and Password authenticate with AUTH.  Prefer
+	// SCALESET_LEADER_ELECTION_REDIS_PASSWORD over the file.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// DB is the database number.  Default: 0.
+	DB int `yaml:"db"`
+	// TLS connects over TLS.  Default: false.
+	TLS bool `yaml:"tls"`
+	// Key holds the leader's identity.  Default: "scaleset-leader".
+	Key string `yaml:"key"`
+}
+
+// NewElector returns an elector for the configured backend that runs
+// for leadership as identity.
+func (l *LeaderConfig) NewElector(identity string, logger *slog.Logger) (*leader.Elector, error) {
+	var lock leader.Lock
+	switch l.Backend {
+	case "kubernetes":
+		lease, err := leader.NewLeaseLock(l.Kubernetes.Namespace, l.Kubernetes.Name, identity, l.LeaseDuration)
+		if err != nil {
+			return nil, err
+		}
+		lock = lease
+	case "file":
+		lock = leader.NewFileLock(l.File.Path, identity)
+	case "redis":
+		lock = leader.NewRedisLock(leader.RedisConfig{
+			Address:  l.Redis.Address,
+			Username: l.Redis.Username,
+			Password: l.Redis.Password,
+			DB:       l.Redis.DB,
+			TLS:      l.Redis.TLS,
+		}, l.Redis.Key, identity, l.LeaseDuration)
+	default:
+		return nil, fmt.Errorf("leader_election.backend: unknown backend %q", l.Backend)
 	}
-	if c.Engine.Docker.Image == "" {
-		c.Engine.Docker.Image = "ghcr.io/actions/actions-runner:latest"
+	return leader.New(leader.Config{
+		Lock:          lock,
+		Identity:      identity,
+		RenewDeadline: l.RenewDeadline,
+		RetryPeriod:   l.RetryPeriod,
+		Logger:        logger,
+	}), nil
+}
+
+// applyDefaults fills in the unset durations and lock names.
+func (l *LeaderConfig) applyDefaults() {
+	if l.LeaseDuration == 0 {
+		l.LeaseDuration = 15 * time.Second
 	}
-	if c.Engine.GCP.MachineType == "" {
-		c.Engine.GCP.MachineType = "e2-medium"
+	if l.RenewDeadline == 0 {
+		l.RenewDeadline = 10 * time.Second
 	}
-	if c.Engine.GCP.DiskSizeGB == 0 {
-		c.Engine.GCP.DiskSizeGB = 50
+	if l.RetryPeriod == 0 {
+		l.RetryPeriod = 2 * time.Second
 	}
-	if c.Engine.GCP.PublicIP == nil {
-		t := true
-		c.Engine.GCP.PublicIP = &t
+	if l.Kubernetes.Name == "" {
+		l.Kubernetes.Name = "scaleset-leader"
 	}
-	if c.Logging.Level == "" {
-		c.Logging.Level = "info"
+	if l.Redis.Key == "" {
+		l.Redis.Key = "scaleset-leader"
 	}
-	if c.Logging.Format == "" {
-		c.Logging.Format = "text"
+}
+
+func (l *LeaderConfig) validate() error {
+	if !l.Enable {
+		return nil
 	}
-	// OTel defaults: disabled by default, insecure=true for local dev
-	if !c.OTel.Enabled {
-		// If explicitly disabled, ensure insecure defaults to true for when enabled
-		if !c.OTel.Insecure && c.OTel.Endpoint == "" {
-			c.OTel.Insecure = true
+	switch l.Backend {
+	case "kubernetes":
+	case "file":
+		if l.File.Path == "" {
+			return fmt.Errorf("leader_election.file.path is required with the file backend")
+		}
+	case "redis":
+		if _, _, err := net.SplitHostPort(l.Redis.Address); err != nil {
+			return fmt.Errorf("leader_election.redis.address: must be host:port, got %q", l.Redis.Address)
 		}
+		if l.Redis.DB < 0 {
+			return fmt.Errorf("leader_election.redis.db must be >= 0, got %d", l.Redis.DB)
+		}
+	case "":
+		return fmt.Errorf("leader_election.backend is required: kubernetes, file or redis")
+	default:
+		return fmt.Errorf("leader_election.backend: unknown backend %q, want kubernetes, file or redis", l.Backend)
 	}
-	// Prometheus defaults
-	if c.Prometheus.Port == 0 {
-		c.Prometheus.Port = 9090
+	if l.RetryPeriod <= 0 {
+		return fmt.Errorf("leader_election.retry_period must be > 0, got %s", l.RetryPeriod)
+	}
+	if l.RenewDeadline <= l.RetryPeriod {
+		return fmt.Errorf("leader_election.renew_deadline (%s) must be greater than retry_period (%s)", l.RenewDeadline, l.RetryPeriod)
+	}
+	if l.Backend != "file" && l.LeaseDuration <= l.RenewDeadline {
+		return fmt.Errorf("leader_election.lease_duration (%s) must be greater than renew_deadline (%s)", l.LeaseDuration, l.RenewDeadline)
+	}
+	if l.Backend == "kubernetes" && l.LeaseDuration%time.Second != 0 {
+		return fmt.Errorf("leader_election.lease_duration must be whole seconds with the kubernetes backend, got %s", l.LeaseDuration)
 	}
+	return nil
 }
 
-// Validate checks that all required fields are present and consistent.
-func (c *Config) Validate() error {
-	c.ApplyDefaults()
+// ---------------------------------------------------------------------------
+// Network
+// ---------------------------------------------------------------------------
 
-	if _, err := url.ParseRequestURI(c.GitHub.URL); err != nil {
-		return fmt.Errorf("github.url: invalid URL %q: %w", c.GitHub.URL, err)
-	}
+// NetworkConfig controls how scaleset's own connections leave the host:
+// the scaleset client (GitHub), the Docker client and the GCP SDK.
+// Runners do not inherit it; pass proxy settings to them through
+// engine.docker.env or the VM image.
+type NetworkConfig struct {
+	// ProxyURL is the proxy for HTTP and HTTPS requests (e.g.
+	// "http://proxy.corp.example:3128").  Default: "" (HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY from the environment).
+	ProxyURL string `yaml:"proxy_url"`
+	// NoProxy lists hosts that bypass proxy_url, in NO_PROXY syntax
+	// (e.g. "localhost,.internal.example,10.0.0.0/8").
+	NoProxy string `yaml:"no_proxy"`
+	// CABundlePath is a PEM file of CA certificates trusted in addition
+	// to the system roots, e.g. a TLS-inspecting proxy's CA.
+	CABundlePath string `yaml:"ca_bundle_path"`
+}
 
-	if err := c.validateAuth(); err != nil {
-		return err
+// Egress returns the network settings as an egress.Config.
+func (n *NetworkConfig) Egress() egress.Config {
+	return egress.Config{
+		ProxyURL:     n.ProxyURL,
+		NoProxy:      n.NoProxy,
+		CABundlePath: n.CABundlePath,
 	}
+}
 
-	if c.ScaleSet.Name == "" {
-		return fmt.Errorf("scaleset.name is required")
-	}
-	for i, l := range c.ScaleSet.Labels {
-		if strings.TrimSpace(l) == "" {
-			return fmt.Errorf("scaleset.labels[%d] is empty", i)
-		}
-	}
-	if c.ScaleSet.MaxRunners < c.ScaleSet.MinRunners {
-		return fmt.Errorf("scaleset.max_runners (%d) < scaleset.min_runners (%d)", c.ScaleSet.MaxRunners, c.ScaleSet.MinRunners)
+// HTTPClient returns an HTTP client that uses the network settings, for
+// scaleset's own requests to GitHub outside the scaleset client.
+func (n *NetworkConfig) HTTPClient() (*http.Client, error) {
+	t, err := n.Egress().Transport()
+	if err != nil {
+		return nil, fmt.Errorf("network.ca_bundle_path: %w", err)
 	}
+	return &http.Client{Transport: t}, nil
+}
 
-	// Validate exactly one engine is enabled
-	enabled := []string{}
-	if c.Engine.Docker.Enable {
-		enabled = append(enabled, "docker")
-	}
-	if c.Engine.GCP.Enable {
-		enabled = append(enabled, "gcp")
-	}
-	if c.Engine.AWS.Enable {
-		enabled = append(enabled, "aws")
-	}
-	if c.Engine.Azure.Enable {
-		enabled = append(enabled, "azure")
+// ---------------------------------------------------------------------------
+// Multiple scale sets
+// ---------------------------------------------------------------------------
+
+// RunnerSchedule overrides the runner limits during a recurring time
+// window: from each time Cron matches, for Duration.  Windows are
+// evaluated once a minute, so a change takes effect within a minute of
+// its start or end.  Limits set through the admin API hold until the
+// next window starts or ends.
+type RunnerSchedule struct {
+	// Name identifies the schedule in logs.
+	Name string `yaml:"name"`
+
+	// Cron is a five-field cron expression ("minute hour day-of-month
+	// month day-of-week") for the start of each window, e.g.
+	// "0 8 * * mon-fri".
+	Cron string `yaml:"cron"`
+
+	// Duration is how long each window lasts; at most 31 days.
+	Duration time.Duration `yaml:"duration"`
+
+	// Timezone is the IANA time zone Cron is evaluated in, e.g.
+	// "Europe/Stockholm".  Default: the process's local time zone.
+	Timezone string `yaml:"timezone"`
+
+	// MinRunners and MaxRunners override the scale set's during the
+	// window; unset keeps the scale set's.
+	MinRunners *int `yaml:"min_runners"`
+	MaxRunners *int `yaml:"max_runners"`
+}
+
+// maxScheduleDuration bounds RunnerSchedule.Duration, since windows are
+// found by scanning back minute by minute.
+const maxScheduleDuration = 31 * 24 * time.Hour
+
+// location returns the time zone the schedule is evaluated in.
+func (r *RunnerSchedule) location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.Local, nil
 	}
+	return time.LoadLocation(r.Timezone)
+}
 
-	if len(enabled) == 0 {
-		return fmt.Errorf("at least one engine must have enable: true (supported: docker, gcp; planned: aws, azure)")
+// active reports whether t falls in one of the schedule's windows.  An
+// invalid schedule is never active; Validate reports it.
+func (r *RunnerSchedule) active(t time.Time) bool {
+	spec, err := cron.Parse(r.Cron)
+	if err != nil {
+		return false
 	}
-	if len(enabled) > 1 {
-		return fmt.Errorf("only one engine can be enabled at a time, but %d are enabled: %v", len(enabled), enabled)
+	loc, err := r.location()
+	if err != nil {
+		return false
 	}
+	return spec.Active(t.In(loc), r.Duration)
+}
 
-	// Validate the enabled engine's required fields
-	switch enabled[0] {
-	case "docker":
-		// No required fields for Docker
-	case "gcp":
-		if c.Engine.GCP.Project == "" {
-			return fmt.Errorf("engine.gcp.project is required when GCP engine is enabled")
+// RunnerLimitsAt returns the min_runners and max_runners in effect at t,
+// and the name of the schedule that sets them ("" for the scale set's
+// own).
+func (s *ScaleSetConfig) RunnerLimitsAt(t time.Time) (minRunners, maxRunners int, schedule string) {
+	minRunners, maxRunners = s.MinRunners, s.MaxRunners
+	for i := range s.Schedules {
+		r := &s.Schedules[i]
+		if !r.active(t) {
+			continue
 		}
-		if c.Engine.GCP.Zone == "" {
-			return fmt.Errorf("engine.gcp.zone is required when GCP engine is enabled")
+		if r.MinRunners != nil {
+			minRunners = *r.MinRunners
 		}
-		if c.Engine.GCP.Image == "" {
-			return fmt.Errorf("engine.gcp.image is required when GCP engine is enabled")
+		if r.MaxRunners != nil {
+			maxRunners = *r.MaxRunners
 		}
-	case "aws":
-		return fmt.Errorf("aws engine is not yet implemented")
-	case "azure":
-		return fmt.Errorf("azure engine is not yet implemented")
+		return minRunners, maxRunners, r.Name
 	}
+	return minRunners, maxRunners, ""
+}
 
+// validateSchedules checks scaleset.schedules.  Each schedule's limits
+// must hold on their own, as for the scale set's.
+func (s *ScaleSetConfig) validateSchedules() error {
+	seen := make(map[string]bool, len(s.Schedules))
+	for i := range s.Schedules {
+		r := &s.Schedules[i]
+		path := fmt.Sprintf("scaleset.schedules[%d]", i)
+		if r.Name == "" {
+			return fmt.Errorf("%s.name is required", path)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("%s.name: duplicate schedule %q", path, r.Name)
+		}
+		seen[r.Name] = true
+		if _, err := cron.Parse(r.Cron); err != nil {
+			return fmt.Errorf("%s.cron: %w", path, err)
+		}
+		if r.Duration <= 0 || r.Duration > maxScheduleDuration {
+			return fmt.Errorf("%s.duration must be > 0 and at most %s, got %s", path, maxScheduleDuration, r.Duration)
+		}
+		if _, err := r.location(); err != nil {
+			return fmt.Errorf("%s.timezone: %w", path, err)
+		}
+		if r.MinRunners == nil && r.MaxRunners == nil {
+			return fmt.Errorf("%s: set min_runners or max_runners", path)
+		}
+		minRunners, maxRunners := s.MinRunners, s.MaxRunners
+		if r.MinRunners != nil {
+			minRunners = *r.MinRunners
+		}
+		if r.MaxRunners != nil {
+			maxRunners = *r.MaxRunners
+		}
+		if minRunners < 0 {
+			return fmt.Errorf("%s.min_runners must be >= 0, got %d", path, minRunners)
+		}
+		if maxRunners < minRunners {
+			return fmt.Errorf("%s: max_runners (%d) < min_runners (%d)", path, maxRunners, minRunners)
+		}
+	}
 	return nil
 }
 
-func (c *Config) validateAuth() error {
-	hasToken := c.GitHub.Token != ""
-	hasApp := c.GitHub.App.ClientID != "" ||
-		c.GitHub.App.InstallationID != 0 ||
-		c.GitHub.App.PrivateKey != "" ||
-		c.GitHub.App.PrivateKeyPath != ""
+// Flavor is a named runner size of a scale set, e.g. "large" mapped to
+// a bigger machine type or "gpu" to an accelerator.
+//
+// A job's labels are only known once GitHub assigns it to a runner, and
+// any idle runner of a scale set may take any of its jobs, so the
+// scaler cannot provision per job.  Instead each flavor is a scale set
+// of its own: named "<scaleset.name>-<flavor>", labelled with the scale
+// set's labels plus the flavor's, and running the engine with the
+// flavor's overrides.  GitHub then routes each job by its runs-on labels
+// to the flavor that has them all.
+type Flavor struct {
+	// Name is appended to the scale set name (required, unique).
+	Name string `yaml:"name"`
+
+	// Labels are added to the scale set's labels.  Default: [name].
+	Labels []string `yaml:"labels"`
+
+	// MinRunners and MaxRunners override the scale set's.
+	MinRunners *int `yaml:"min_runners"`
+	MaxRunners *int `yaml:"max_runners"`
+
+	// Docker and GCP override engine settings like an engine profile.
+	Docker DockerProfile `yaml:"docker"`
+	GCP    GCPProfile    `yaml:"gcp"`
+}
 
-	if !hasToken && !hasApp {
-		return fmt.Errorf("no credentials: provide github.app (recommended) or github.token")
+// flavorLabels returns the labels f adds to its scale set.
+func (f *Flavor) flavorLabels() []string {
+	if len(f.Labels) > 0 {
+		return f.Labels
 	}
+	return []string{f.Name}
+}
 
-	if hasApp {
-		if c.GitHub.App.ClientID == "" {
-			return fmt.Errorf("github.app.client_id is required when using GitHub App auth")
+// flavorConfigs returns one Config per flavor of c: a copy of c with the
+// flavor's name suffix, labels, runner limits and engine overrides (as
+// the engine's only profile).  A pinned run_id and the state file get
+// the flavor name as suffix so the flavors' runners stay apart.
+func (c *Config) flavorConfigs() []*Config {
+	out := make([]*Config, len(c.ScaleSet.Flavors))
+	for i := range c.ScaleSet.Flavors {
+		f := &c.ScaleSet.Flavors[i]
+		sc := *c
+		sc.ScaleSet.Flavors = nil
+		sc.ScaleSet.Name = c.ScaleSet.Name + "-" + f.Name
+		labels := make([]string, 0, len(c.ScaleSet.Labels)+len(f.flavorLabels()))
+		for _, l := range c.BuildLabels() {
+			labels = append(labels, l.Name)
 		}
-		if c.GitHub.App.InstallationID == 0 {
-			return fmt.Errorf("github.app.installation_id is required when using GitHub App auth")
+		sc.ScaleSet.Labels = append(labels, f.flavorLabels()...)
+		if f.MinRunners != nil {
+			sc.ScaleSet.MinRunners = *f.MinRunners
 		}
-		if c.GitHub.App.PrivateKey == "" && c.GitHub.App.PrivateKeyPath == "" {
-			return fmt.Errorf("github.app.private_key or github.app.private_key_path is required")
+		if f.MaxRunners != nil {
+			sc.ScaleSet.MaxRunners = *f.MaxRunners
+		}
+		if c.ScaleSet.RunID != "" {
+			sc.ScaleSet.RunID = c.ScaleSet.RunID + "-" + f.Name
 		}
+		if p := c.State.Path; p != "" {
+			ext := filepath.Ext(p)
+			sc.State.Path = strings.TrimSuffix(p, ext) + "-" + f.Name + ext
+		}
+		sc.Engine.Profiles = []EngineProfile{{
+			Name:   f.Name,
+			Labels: f.flavorLabels(),
+			Docker: f.Docker,
+			GCP:    f.GCP,
+		}}
+		out[i] = &sc
 	}
+	return out
+}
 
+// validateFlavors checks scaleset.flavors and validates the Config of
+// each flavor (see flavorConfigs).
+func (c *Config) validateFlavors() error {
+	if c.ScaleSet.Name == "" {
+		return fmt.Errorf("scaleset.name is required")
+	}
+	if len(c.Engine.Profiles) > 0 {
+		return fmt.Errorf("engine.profiles: not allowed with scaleset.flavors, set the overrides per flavor")
+	}
+	names := make(map[string]bool, len(c.ScaleSet.Flavors))
+	for i, f := range c.ScaleSet.Flavors {
+		path := fmt.Sprintf("scaleset.flavors[%d]", i)
+		switch {
+		case f.Name == "":
+			return fmt.Errorf("%s.name is required", path)
+		case !validLabelValue(f.Name):
+			return fmt.Errorf("%s.name %q must be at most 63 lowercase letters, digits, '-' or '_'", path, f.Name)
+		case names[f.Name]:
+			return fmt.Errorf("%s.name: duplicate flavor %q", path, f.Name)
+		}
+		names[f.Name] = true
+	}
+	for i, sc := range c.flavorConfigs() {
+		if err := sc.Validate(); err != nil {
+			return fmt.Errorf("scaleset.flavors[%d] (%s): %w", i, c.ScaleSet.Flavors[i].Name, err)
+		}
+	}
 	return nil
 }
 
-// ---------------------------------------------------------------------------
-// Factories
-// ---------------------------------------------------------------------------
+// ScaleSetEntry is one scale set of a process running several.  Each
+// entry gets its own listener, scaler and engine; logging, otel,
+// prometheus, health and admin are shared.
+type ScaleSetEntry struct {
+	// GitHub overrides the top-level github section field by field, so
+	// entries can register in different repositories or organizations
+	// with shared credentials.  Setting token or any app field replaces
+	// the top-level credentials as a whole.
+	GitHub   GitHubConfig   `yaml:"github"`
+	ScaleSet ScaleSetConfig `yaml:"scaleset"`
+	Engine   EngineConfig   `yaml:"engine"`
+	State    StateConfig    `yaml:"state"`
+}
 
-// NewLogger creates a *slog.Logger from the Logging configuration.
-func (c *Config) NewLogger() *slog.Logger {
-	opts := &slog.HandlerOptions{
-		AddSource: true,
-		Level:     c.slogLevel(),
+// ScaleSetConfigs returns one Config per scale set the process runs: a
+// copy of c for each entry of scale_sets, with the entry's sections in
+// place of the top-level ones, one per flavor (see Flavor), or c itself.
+// The copies share c's logging, otel, prometheus, health and admin
+// sections.
+func (c *Config) ScaleSetConfigs() []*Config {
+	if len(c.ScaleSet.Flavors) > 0 {
+		return c.flavorConfigs()
+	}
+	if len(c.ScaleSets) == 0 {
+		return []*Config{c}
+	}
+	out := make([]*Config, len(c.ScaleSets))
+	for i, e := range c.ScaleSets {
+		sc := *c
+		sc.ScaleSets = nil
+		sc.GitHub = c.GitHub.overlay(e.GitHub)
+		sc.ScaleSet = e.ScaleSet
+		sc.Engine = e.Engine
+		sc.State = e.State
+		out[i] = &sc
+	}
+	return out
+}
+
+// overlay returns g with the fields set in o replacing its own.
+func (g GitHubConfig) overlay(o GitHubConfig) GitHubConfig {
+	if o.URL != "" {
+		g.URL = o.URL
+	}
+	if o.APIURL != "" {
+		g.APIURL = o.APIURL
+	}
+	if o.Enterprise {
+		g.Enterprise = true
+	}
+	if o.Token != "" || o.App != (GitHubAppConfig{}) {
+		g.Token, g.App = o.Token, o.App
+	}
+	return g
+}
+
+// validateScaleSets validates each entry of scale_sets as a Config of
+// its own (see ScaleSetConfigs), recording the defaults applied, and
+// checks that no two entries register the same scale set or share a
+// state file.
+func (c *Config) validateScaleSets() error {
+	if !reflect.ValueOf(c.ScaleSet).IsZero() {
+		return fmt.Errorf("scaleset: not allowed with scale_sets, configure it per entry")
+	}
+	if !reflect.ValueOf(c.Engine).IsZero() {
+		return fmt.Errorf("engine: not allowed with scale_sets, configure it per entry")
+	}
+	if c.State.Path != "" {
+		return fmt.Errorf("state.path: not allowed with scale_sets, configure it per entry")
+	}
+
+	names := make(map[string]int, len(c.ScaleSets))
+	statePaths := make(map[string]int, len(c.ScaleSets))
+	for i, sc := range c.ScaleSetConfigs() {
+		if len(sc.ScaleSet.Flavors) > 0 {
+			return fmt.Errorf("scale_sets[%d].scaleset.flavors: not allowed with scale_sets, add an entry per flavor", i)
+		}
+		if err := sc.Validate(); err != nil {
+			return fmt.Errorf("scale_sets[%d]: %w", i, err)
+		}
+		c.ScaleSets[i].ScaleSet = sc.ScaleSet
+		c.ScaleSets[i].Engine = sc.Engine
+
+		key := sc.GitHub.URL + " " + sc.ScaleSet.Name
+		if j, ok := names[key]; ok {
+			return fmt.Errorf("scale_sets[%d].scaleset.name: %q is also registered at %s by scale_sets[%d]", i, sc.ScaleSet.Name, sc.GitHub.URL, j)
+		}
+		names[key] = i
+		if p := sc.State.Path; p != "" {
+			if j, ok := statePaths[p]; ok {
+				return fmt.Errorf("scale_sets[%d].state.path: %q is also used by scale_sets[%d]", i, p, j)
+			}
+			statePaths[p] = i
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Loading
+// ---------------------------------------------------------------------------
+
+// Load reads a YAML config file from path and returns the parsed Config.
+// If the file does not exist the returned Config will contain zero values
+// which must be filled via flag overrides before calling Validate.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Config file is optional -- flags can supply everything.
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if err := cfg.resolveMetadataFiles(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// resolveMetadataFiles reads the gcp.metadata_from_file entries and
+// gcp.startup_script_path of the primary and fallback engines into their
+// gcp.metadata and gcp.startup_script.
+func (c *Config) resolveMetadataFiles() error {
+	if err := c.Engine.resolveMetadataFiles("engine"); err != nil {
+		return err
+	}
+	for i := range c.ScaleSets {
+		if err := c.ScaleSets[i].Engine.resolveMetadataFiles(fmt.Sprintf("scale_sets[%d].engine", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *EngineConfig) resolveMetadataFiles(path string) error {
+	if err := e.GCP.resolveMetadataFiles(path); err != nil {
+		return err
+	}
+	if fb := e.Fallback; fb != nil {
+		for i := range fb.Engines {
+			if err := fb.Engines[i].GCP.resolveMetadataFiles(fmt.Sprintf("%s.fallback.engines[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *GCPEngineConfig) resolveMetadataFiles(path string) error {
+	if g.StartupScriptPath != "" {
+		if g.StartupScript != "" {
+			return fmt.Errorf("%s.gcp: set startup_script or startup_script_path, not both", path)
+		}
+		data, err := os.ReadFile(g.StartupScriptPath)
+		if err != nil {
+			return fmt.Errorf("%s.gcp.startup_script_path: %w", path, err)
+		}
+		g.StartupScript = string(data)
+	}
+	keys := make([]string, 0, len(g.MetadataFromFile))
+	for k := range g.MetadataFromFile {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, ok := g.Metadata[k]; ok {
+			return fmt.Errorf("%s.gcp.metadata_from_file: key %q is also set in %s.gcp.metadata", path, k, path)
+		}
+		data, err := os.ReadFile(g.MetadataFromFile[k])
+		if err != nil {
+			return fmt.Errorf("%s.gcp.metadata_from_file: reading %q: %w", path, k, err)
+		}
+		if g.Metadata == nil {
+			g.Metadata = make(map[string]string, len(keys))
+		}
+		g.Metadata[k] = string(data)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Environment
+// ---------------------------------------------------------------------------
+
+// EnvPrefix starts the name of every environment variable read by
+// ApplyEnv.
+const EnvPrefix = "SCALESET_"
+
+// ApplyEnv overrides config fields with environment variables named
+// after their YAML path: EnvPrefix followed by the path's keys in upper
+// case, joined by "_" (github.token is SCALESET_GITHUB_TOKEN,
+// engine.docker.image is SCALESET_ENGINE_DOCKER_IMAGE).  String fields
+// take the value verbatim; other fields parse it as YAML, so lists and
+// maps are written in flow style (SCALESET_SCALESET_LABELS="[gpu,
+// linux]") and replace the file's value as a whole.  Empty variables
+// are ignored.  It is applied after CLI flags, so the precedence is
+// environment > flag > file.  lookup is os.LookupEnv outside tests.
+func (c *Config) ApplyEnv(lookup func(key string) (string, bool)) error {
+	_, err := applyEnv(reflect.ValueOf(c).Elem(), strings.TrimSuffix(EnvPrefix, "_"), "", lookup)
+	return err
+}
+
+// applyEnv sets the fields of the struct v from the environment, where
+// name and path are the variable name and YAML path of v.  It reports
+// whether any field was set.
+func applyEnv(v reflect.Value, name, path string, lookup func(string) (string, bool)) (bool, error) {
+	var set bool
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || key == "" || key == "-" || f.Tag.Get("env") == "-" {
+			continue
+		}
+		fieldName, fieldPath := name+"_"+strings.ToUpper(key), key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+
+		fv := v.Field(i)
+		switch {
+		case fv.Kind() == reflect.Struct:
+			ok, err := applyEnv(fv, fieldName, fieldPath, lookup)
+			if err != nil {
+				return false, err
+			}
+			set = set || ok
+		case fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct:
+			// Allocate an unset section only if a variable sets it.
+			target := fv
+			if fv.IsNil() {
+				target = reflect.New(fv.Type().Elem())
+			}
+			ok, err := applyEnv(target.Elem(), fieldName, fieldPath, lookup)
+			if err != nil {
+				return false, err
+			}
+			if ok && fv.IsNil() {
+				fv.Set(target)
+			}
+			set = set || ok
+		default:
+			val, ok := lookup(fieldName)
+			if !ok || val == "" {
+				continue
+			}
+			if err := setEnvValue(fv, val); err != nil {
+				return false, fmt.Errorf("%s (%s): %w", fieldName, fieldPath, err)
+			}
+			set = true
+		}
+	}
+	return set, nil
+}
+
+// setEnvValue sets fv from an environment variable's value.
+func setEnvValue(fv reflect.Value, val string) error {
+	if fv.Kind() == reflect.String {
+		fv.SetString(val)
+		return nil
+	}
+	ptr := reflect.New(fv.Type())
+	if err := yaml.Unmarshal([]byte(val), ptr.Interface()); err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	fv.Set(ptr.Elem())
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Defaults & validation
+// ---------------------------------------------------------------------------
+
+// ApplyDefaults fills in sensible defaults for any unset fields.  With
+// scale_sets, the entries get theirs when validated.
+func (c *Config) ApplyDefaults() {
+	if len(c.ScaleSets) == 0 {
+		c.applyScaleSetDefaults()
+	}
+	if c.Logging.Level == "" {
+		c.Logging.Level = "info"
+	}
+	if c.Logging.Format == "" {
+		c.Logging.Format = "text"
+	}
+	if c.Logging.Output == "" {
+		c.Logging.Output = "stdout"
+	}
+	// OTel defaults: disabled by default, insecure=true for local dev
+	if !c.OTel.Enabled {
+		// If explicitly disabled, ensure insecure defaults to true for when enabled
+		if !c.OTel.Insecure && c.OTel.Endpoint == "" {
+			c.OTel.Insecure = true
+		}
+	}
+	// Prometheus defaults
+	if c.Prometheus.Port == 0 {
+		c.Prometheus.Port = 9090
+	}
+	// Health defaults: enabled, sharing the metrics server
+	if c.Health.Enable == nil {
+		t := true
+		c.Health.Enable = &t
+	}
+	if c.Health.Port == 0 {
+		c.Health.Port = c.Prometheus.Port
+	}
+	// Admin defaults
+	if c.Admin.Address == "" {
+		c.Admin.Address = "127.0.0.1"
+	}
+	if c.Admin.Port == 0 {
+		c.Admin.Port = 9092
+	}
+	// Webhook defaults
+	if c.Webhook.Port == 0 {
+		c.Webhook.Port = 9093
+	}
+	if c.Webhook.Path == "" {
+		c.Webhook.Path = "/webhook"
+	}
+	if c.Webhook.Mode == "" {
+		c.Webhook.Mode = WebhookModeAssist
+	}
+	c.Leader.applyDefaults()
+}
+
+// applyScaleSetDefaults fills in the defaults of the scaleset and engine
+// sections.
+func (c *Config) applyScaleSetDefaults() {
+	if c.ScaleSet.RunnerGroup == "" {
+		c.ScaleSet.RunnerGroup = scaleset.DefaultRunnerGroup
+	}
+	if c.ScaleSet.MaxRunners == 0 {
+		c.ScaleSet.MaxRunners = 10
+	}
+	if c.ScaleSet.MaxConcurrentDestroys == 0 {
+		c.ScaleSet.MaxConcurrentDestroys = 10
+	}
+	if c.ScaleSet.CreateRetries == 0 {
+		c.ScaleSet.CreateRetries = 3
+	}
+	if c.ScaleSet.CreateRetryDelay == 0 {
+		c.ScaleSet.CreateRetryDelay = 2 * time.Second
+	}
+	if c.ScaleSet.ShutdownRetries == 0 {
+		c.ScaleSet.ShutdownRetries = 3
+	}
+	if c.ScaleSet.ShutdownRetryDelay == 0 {
+		c.ScaleSet.ShutdownRetryDelay = time.Second
+	}
+	if c.ScaleSet.StartParallelism == 0 {
+		c.ScaleSet.StartParallelism = 5
+	}
+	if c.ScaleSet.StartRetryDelay == 0 {
+		c.ScaleSet.StartRetryDelay = time.Second
+	}
+	if c.ScaleSet.StartRetryMaxDelay == 0 {
+		c.ScaleSet.StartRetryMaxDelay = 30 * time.Second
+	}
+	if c.ScaleSet.AdmissionCheckTimeout == 0 {
+		c.ScaleSet.AdmissionCheckTimeout = 5 * time.Second
+	}
+	if len(c.ScaleSet.StartupDurationBuckets) == 0 {
+		c.ScaleSet.StartupDurationBuckets = defaultStartupDurationBuckets(c.Engine.EnabledEngine())
+	}
+	c.Engine.applyDefaults()
+	if fb := c.Engine.Fallback; fb != nil {
+		if fb.AfterFailures == 0 {
+			fb.AfterFailures = 3
+		}
+		for i := range fb.Engines {
+			fb.Engines[i].applyDefaults()
+		}
+	}
+}
+
+// defaultStartupDurationBuckets returns runner startup histogram buckets
+// (seconds) suited to engine: containers are up in seconds, VMs take
+// minutes to boot.  Other engines get the scaler's defaults (nil).
+func defaultStartupDurationBuckets(engine string) []float64 {
+	switch engine {
+	case "docker", "podman":
+		return []float64{0.5, 1, 2, 5, 10, 20, 30, 60}
+	case "gcp":
+		return []float64{10, 20, 30, 45, 60, 90, 120, 180, 300, 600}
+	case "kubernetes":
+		return []float64{1, 2, 5, 10, 20, 30, 60, 120, 300}
+	case "hcloud":
+		return []float64{10, 20, 30, 45, 60, 90, 120, 180, 300}
+	}
+	return nil
+}
+
+// applyDefaults fills in defaults for the engine settings.
+func (e *EngineConfig) applyDefaults() {
+	if e.Docker.Image == "" {
+		e.Docker.Image = "ghcr.io/actions/actions-runner:latest"
+	}
+	if e.Docker.DindMode == "" {
+		e.Docker.DindMode = docker.DindModeSocket
+	}
+	if e.Docker.DindImage == "" {
+		e.Docker.DindImage = docker.DefaultDindImage
+	}
+	if e.GCP.Zone == "" && len(e.GCP.Zones) > 0 {
+		e.GCP.Zone = e.GCP.Zones[0]
+	}
+	if e.GCP.MachineType == "" {
+		e.GCP.MachineType = "e2-medium"
+	}
+	if e.GCP.DiskSizeGB == 0 {
+		e.GCP.DiskSizeGB = 50
+	}
+	if e.GCP.DiskType == "" {
+		e.GCP.DiskType = "pd-ssd"
+	}
+	if e.GCP.BulkInsertThreshold == 0 {
+		e.GCP.BulkInsertThreshold = 5
+	}
+	if e.GCP.PublicIP == nil {
+		t := true
+		e.GCP.PublicIP = &t
+	}
+	if e.HCloud.PublicIPv4 == nil {
+		t := true
+		e.HCloud.PublicIPv4 = &t
+	}
+}
+
+// Validate checks that all required fields are present and consistent.
+func (c *Config) Validate() error {
+	c.ApplyDefaults()
+	if len(c.ScaleSets) > 0 {
+		return c.validateScaleSets()
+	}
+	if len(c.ScaleSet.Flavors) > 0 {
+		return c.validateFlavors()
+	}
+
+	if _, err := url.ParseRequestURI(c.GitHub.URL); err != nil {
+		return fmt.Errorf("github.url: invalid URL %q: %w", c.GitHub.URL, err)
+	}
+
+	if c.GitHub.APIURL != "" {
+		u, err := url.ParseRequestURI(c.GitHub.APIURL)
+		if err != nil {
+			return fmt.Errorf("github.api_url: invalid URL %q: %w", c.GitHub.APIURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("github.api_url: scheme must be http or https, got %q", u.Scheme)
+		}
+	}
+
+	if c.Network.ProxyURL != "" {
+		u, err := url.Parse(c.Network.ProxyURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("network.proxy_url: invalid URL %q", c.Network.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("network.proxy_url: scheme must be http, https or socks5, got %q", u.Scheme)
+		}
+	}
+	if c.Network.NoProxy != "" && c.Network.ProxyURL == "" {
+		return fmt.Errorf("network.no_proxy requires network.proxy_url (without it NO_PROXY from the environment applies)")
+	}
+	if _, err := c.Network.Egress().RootCAs(); err != nil {
+		return fmt.Errorf("network.ca_bundle_path: %w", err)
+	}
+
+	if c.GitHub.Enterprise && !c.GitHub.IsEnterprise() {
+		return fmt.Errorf("github.enterprise is for GitHub Enterprise Server, but github.url %q is hosted by GitHub", c.GitHub.URL)
+	}
+
+	if err := c.validateAuth(); err != nil {
+		return err
+	}
+
+	if c.ScaleSet.Name == "" {
+		return fmt.Errorf("scaleset.name is required")
+	}
+	for i, l := range c.ScaleSet.Labels {
+		if strings.TrimSpace(l) == "" {
+			return fmt.Errorf("scaleset.labels[%d] is empty", i)
+		}
+	}
+	if err := validateLabels(c.BuildLabels()); err != nil {
+		return err
+	}
+	if c.ScaleSet.MaxRunners < c.ScaleSet.MinRunners {
+		return fmt.Errorf("scaleset.max_runners (%d) < scaleset.min_runners (%d)", c.ScaleSet.MaxRunners, c.ScaleSet.MinRunners)
+	}
+	if c.ScaleSet.MaxConcurrentDestroys < 0 {
+		return fmt.Errorf("scaleset.max_concurrent_destroys must be >= 0, got %d", c.ScaleSet.MaxConcurrentDestroys)
+	}
+	if c.ScaleSet.CreateRetries < 0 {
+		return fmt.Errorf("scaleset.create_retries must be >= 0, got %d", c.ScaleSet.CreateRetries)
+	}
+	if c.ScaleSet.StartRate < 0 {
+		return fmt.Errorf("scaleset.start_rate must be >= 0, got %g", c.ScaleSet.StartRate)
+	}
+	if c.ScaleSet.SlowMessageThreshold < 0 {
+		return fmt.Errorf("scaleset.slow_message_threshold must be >= 0, got %s", c.ScaleSet.SlowMessageThreshold)
+	}
+	for i, b := range c.ScaleSet.StartupDurationBuckets {
+		if b < 0 || (i > 0 && b <= c.ScaleSet.StartupDurationBuckets[i-1]) {
+			return fmt.Errorf("scaleset.startup_duration_buckets must be non-negative and strictly increasing, got %v", c.ScaleSet.StartupDurationBuckets)
+		}
+	}
+	if c.ScaleSet.StartBurst < 0 {
+		return fmt.Errorf("scaleset.start_burst must be >= 0, got %d", c.ScaleSet.StartBurst)
+	}
+	if c.ScaleSet.StartDeadline < 0 {
+		return fmt.Errorf("scaleset.start_deadline must be >= 0, got %s", c.ScaleSet.StartDeadline)
+	}
+	if c.ScaleSet.CapacityProbeInterval < 0 {
+		return fmt.Errorf("scaleset.capacity_probe_interval must be >= 0, got %s", c.ScaleSet.CapacityProbeInterval)
+	}
+	switch s := c.ScaleSet.NameSuffix; {
+	case s == "", s == "random", s == "git-sha":
+	case strings.HasPrefix(s, "env:") && len(s) > len("env:"):
+	default:
+		return fmt.Errorf("scaleset.name_suffix must be \"random\", \"git-sha\" or \"env:VAR\", got %q", s)
+	}
+	if c.ScaleSet.MaxProcessLifetime < 0 {
+		return fmt.Errorf("scaleset.max_process_lifetime must be >= 0, got %s", c.ScaleSet.MaxProcessLifetime)
+	}
+	if !validLabelValue(c.ScaleSet.RunID) {
+		return fmt.Errorf("scaleset.run_id %q must be at most 63 lowercase letters, digits, '-' or '_'", c.ScaleSet.RunID)
+	}
+	if c.ScaleSet.SessionOwner != "" && strings.TrimSpace(c.ScaleSet.SessionOwner) == "" {
+		return fmt.Errorf("scaleset.session_owner must not be blank")
+	}
+	if c.ScaleSet.StartParallelism < 1 {
+		return fmt.Errorf("scaleset.start_parallelism must be >= 1, got %d", c.ScaleSet.StartParallelism)
+	}
+	if c.ScaleSet.StartRetries < 0 {
+		return fmt.Errorf("scaleset.start_retries must be >= 0, got %d", c.ScaleSet.StartRetries)
+	}
+	if c.ScaleSet.StartRetryDelay < 0 {
+		return fmt.Errorf("scaleset.start_retry_delay must be >= 0, got %s", c.ScaleSet.StartRetryDelay)
+	}
+	if c.ScaleSet.StartRetryMaxDelay < 0 {
+		return fmt.Errorf("scaleset.start_retry_max_delay must be >= 0, got %s", c.ScaleSet.StartRetryMaxDelay)
+	}
+	if c.ScaleSet.StartupTimeout < 0 {
+		return fmt.Errorf("scaleset.startup_timeout must be >= 0, got %s", c.ScaleSet.StartupTimeout)
+	}
+	if c.ScaleSet.MaxRunnerLifetime < 0 {
+		return fmt.Errorf("scaleset.max_runner_lifetime must be >= 0, got %s", c.ScaleSet.MaxRunnerLifetime)
+	}
+	if c.ScaleSet.CreateRetryDelay < 0 {
+		return fmt.Errorf("scaleset.create_retry_delay must be >= 0, got %s", c.ScaleSet.CreateRetryDelay)
+	}
+	if c.ScaleSet.ShutdownRetries < 0 {
+		return fmt.Errorf("scaleset.shutdown_retries must be >= 0, got %d", c.ScaleSet.ShutdownRetries)
+	}
+	if c.ScaleSet.ShutdownRetryDelay < 0 {
+		return fmt.Errorf("scaleset.shutdown_retry_delay must be >= 0, got %s", c.ScaleSet.ShutdownRetryDelay)
+	}
+	if c.ScaleSet.AdmissionCheckTimeout < 0 {
+		return fmt.Errorf("scaleset.admission_check_timeout must be >= 0, got %s", c.ScaleSet.AdmissionCheckTimeout)
+	}
+	if c.ScaleSet.ReconcileInterval < 0 {
+		return fmt.Errorf("scaleset.reconcile_interval must be >= 0, got %s", c.ScaleSet.ReconcileInterval)
+	}
+	if c.ScaleSet.BusyCheckInterval < 0 {
+		return fmt.Errorf("scaleset.busy_check_interval must be >= 0, got %s", c.ScaleSet.BusyCheckInterval)
+	}
+	if c.ScaleSet.MaxRunnersPerRepo < 0 {
+		return fmt.Errorf("scaleset.max_runners_per_repo must be >= 0, got %d", c.ScaleSet.MaxRunnersPerRepo)
+	}
+	for repo, limit := range c.ScaleSet.RepoRunnerLimits {
+		if strings.TrimSpace(repo) == "" {
+			return fmt.Errorf("scaleset.repo_runner_limits: repository name must not be empty")
+		}
+		if limit < 0 {
+			return fmt.Errorf("scaleset.repo_runner_limits[%s] must be >= 0, got %d", repo, limit)
+		}
+	}
+	if err := c.ScaleSet.validateSchedules(); err != nil {
+		return err
+	}
+
+	if c.Health.IsEnabled() {
+		if c.Health.Port < 1 || c.Health.Port > 65535 {
+			return fmt.Errorf("health.port must be between 1 and 65535, got %d", c.Health.Port)
+		}
+	} else if c.Health.Pprof {
+		return fmt.Errorf("health.pprof requires health.enable")
+	}
+	if c.Health.DrainTimeout < 0 {
+		return fmt.Errorf("health.drain_timeout must be >= 0, got %s", c.Health.DrainTimeout)
+	}
+	for k := range c.Health.Labels {
+		if strings.TrimSpace(k) == "" {
+			return fmt.Errorf("health.labels: label name must not be empty")
+		}
+	}
+	if c.Admin.Enable {
+		if c.Admin.Port < 1 || c.Admin.Port > 65535 {
+			return fmt.Errorf("admin.port must be between 1 and 65535, got %d", c.Admin.Port)
+		}
+		if c.Prometheus.Enable && c.Admin.Port == c.Prometheus.Port {
+			return fmt.Errorf("admin.port: %d is already used by the metrics server (prometheus.port)", c.Admin.Port)
+		}
+		if c.Health.IsEnabled() && c.Admin.Port == c.Health.Port {
+			return fmt.Errorf("admin.port: %d is already used by the health server (health.port)", c.Admin.Port)
+		}
+	}
+	if err := c.validateWebhook(); err != nil {
+		return err
+	}
+	if c.Audit.Path != "" && c.State.Path != "" && filepath.Clean(c.Audit.Path) == filepath.Clean(c.State.Path) {
+		return fmt.Errorf("audit.path: %s is already the state file (state.path)", c.Audit.Path)
+	}
+	if err := c.Leader.validate(); err != nil {
+		return err
+	}
+	// A leader that loses its lease shuts down while the new leader
+	// already uses the scale set; deleting it on shutdown, or registering
+	// a fresh one, would pull it out from under the new leader.
+	if c.Leader.Enable && (!c.ScaleSet.KeepOnShutdown || !c.ScaleSet.AdoptExisting) {
+		return fmt.Errorf("leader_election.enable requires scaleset.keep_on_shutdown and scaleset.adopt_existing, so that replicas share the scale set")
+	}
+
+	if err := c.Engine.validate("engine"); err != nil {
+		return err
+	}
+	if fb := c.Engine.Fallback; fb != nil {
+		if len(fb.Engines) == 0 {
+			return fmt.Errorf("engine.fallback.engines must list at least one engine")
+		}
+		if fb.AfterFailures < 1 {
+			return fmt.Errorf("engine.fallback.after_failures must be >= 1, got %d", fb.AfterFailures)
+		}
+		if fb.RetryPrimaryAfter < 0 {
+			return fmt.Errorf("engine.fallback.retry_primary_after must be >= 0, got %s", fb.RetryPrimaryAfter)
+		}
+		for i := range fb.Engines {
+			path := fmt.Sprintf("engine.fallback.engines[%d]", i)
+			if fb.Engines[i].Fallback != nil {
+				return fmt.Errorf("%s.fallback: only the primary engine can have fallbacks", path)
+			}
+			if len(fb.Engines[i].Profiles) > 0 {
+				return fmt.Errorf("%s.profiles: only the primary engine can have profiles", path)
+			}
+			if err := fb.Engines[i].validate(path); err != nil {
+				return err
+			}
+		}
+	}
+	if path := c.Engine.spotPath(); path != "" && c.ScaleSet.ReconcileInterval == 0 {
+		return fmt.Errorf("%s.gcp.spot requires scaleset.reconcile_interval, which replaces preempted runners", path)
+	}
+	if err := c.validateProfiles(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateProfiles checks engine.profiles and that the engines are
+// still valid with the selected profile applied.
+func (c *Config) validateProfiles() error {
+	names := make(map[string]bool, len(c.Engine.Profiles))
+	for i, p := range c.Engine.Profiles {
+		path := fmt.Sprintf("engine.profiles[%d]", i)
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("%s.name is required", path)
+		case names[p.Name]:
+			return fmt.Errorf("%s.name: duplicate profile %q", path, p.Name)
+		case len(p.Labels) == 0:
+			return fmt.Errorf("%s.labels: profile %q must list at least one label", path, p.Name)
+		case p.GCP.DiskSizeGB < 0:
+			return fmt.Errorf("%s.gcp.disk_size_gb must be >= 0, got %d", path, p.GCP.DiskSizeGB)
+		}
+		names[p.Name] = true
+		if err := validateAccelerators(path+".gcp.accelerators", p.GCP.Accelerators); err != nil {
+			return err
+		}
+	}
+
+	profile, err := c.selectProfile()
+	if err != nil || profile == nil {
+		return err
+	}
+	applied := profile.apply(c.Engine)
+	if err := applied.validate("engine"); err != nil {
+		return fmt.Errorf("engine profile %q: %w", profile.Name, err)
+	}
+	if fb := c.Engine.Fallback; fb != nil {
+		for i := range fb.Engines {
+			applied := profile.apply(fb.Engines[i])
+			if err := applied.validate(fmt.Sprintf("engine.fallback.engines[%d]", i)); err != nil {
+				return fmt.Errorf("engine profile %q: %w", profile.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// selectProfile returns the engine profile whose labels are all among
+// the scale set's labels, or nil if none matches.  More than one match
+// is an error.
+func (c *Config) selectProfile() (*EngineProfile, error) {
+	have := make(map[string]bool)
+	for _, l := range c.BuildLabels() {
+		have[strings.ToLower(l.Name)] = true
+	}
+
+	var selected *EngineProfile
+	for i := range c.Engine.Profiles {
+		p := &c.Engine.Profiles[i]
+		if !profileMatches(p, have) {
+			continue
+		}
+		if selected != nil {
+			return nil, fmt.Errorf("engine.profiles: scale set labels match both %q and %q; make the profiles' labels distinct", selected.Name, p.Name)
+		}
+		selected = p
+	}
+	return selected, nil
+}
+
+func profileMatches(p *EngineProfile, have map[string]bool) bool {
+	if len(p.Labels) == 0 {
+		return false
+	}
+	for _, l := range p.Labels {
+		if !have[strings.ToLower(strings.TrimSpace(l))] {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns a copy of ec with p's overrides applied.
+func (p *EngineProfile) apply(ec EngineConfig) EngineConfig {
+	if p.Docker.Image != "" {
+		ec.Docker.Image = p.Docker.Image
+	}
+	if p.Docker.GPUs != "" {
+		ec.Docker.GPUs = p.Docker.GPUs
+	}
+	g := p.GCP
+	if g.MachineType != "" {
+		ec.GCP.MachineType = g.MachineType
+	}
+	if g.Image != "" {
+		ec.GCP.Image = g.Image
+	}
+	if g.DiskSizeGB != 0 {
+		ec.GCP.DiskSizeGB = g.DiskSizeGB
+	}
+	if g.DiskType != "" {
+		ec.GCP.DiskType = g.DiskType
+	}
+	if g.DiskIOPS != 0 {
+		ec.GCP.DiskIOPS = g.DiskIOPS
+	}
+	if g.DiskThroughput != 0 {
+		ec.GCP.DiskThroughput = g.DiskThroughput
+	}
+	if len(g.Accelerators) > 0 {
+		ec.GCP.Accelerators = g.Accelerators
+	}
+	return ec
+}
+
+// validateZones checks that zones, if set, are distinct zones of one
+// region and include zone.
+func validateZones(path, zone string, zones []string) error {
+	if len(zones) == 0 {
+		return nil
+	}
+	region := gcp.RegionFromZone(zone)
+	seen := make(map[string]bool, len(zones))
+	for i, z := range zones {
+		switch {
+		case z == "":
+			return fmt.Errorf("%s.zones[%d] is empty", path, i)
+		case seen[z]:
+			return fmt.Errorf("%s.zones[%d]: %q is listed twice", path, i, z)
+		case gcp.RegionFromZone(z) != region:
+			return fmt.Errorf("%s.zones[%d]: %q is not in region %s of zone %s", path, i, z, region, zone)
+		}
+		seen[z] = true
+	}
+	if !seen[zone] {
+		return fmt.Errorf("%s.zone %q must be one of zones", path, zone)
+	}
+	return nil
+}
+
+// validateAccelerators checks GCP accelerator entries at path.
+func validateAccelerators(path string, accs []GCPAccelerator) error {
+	for i, a := range accs {
+		if a.Type == "" {
+			return fmt.Errorf("%s[%d].type is required", path, i)
+		}
+		if a.Count < 1 {
+			return fmt.Errorf("%s[%d].count must be >= 1, got %d", path, i, a.Count)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateAuth() error {
+	hasToken := c.GitHub.Token != ""
+	hasApp := c.GitHub.App.ClientID != "" ||
+		c.GitHub.App.InstallationID != 0 ||
+		c.GitHub.App.PrivateKey != "" ||
+		c.GitHub.App.PrivateKeyPath != ""
+
+	if !hasToken && !hasApp {
+		return fmt.Errorf("no credentials: provide github.app (recommended) or github.token")
+	}
+
+	if hasApp {
+		if c.GitHub.App.ClientID == "" {
+			return fmt.Errorf("github.app.client_id is required when using GitHub App auth")
+		}
+		if c.GitHub.App.InstallationID == 0 {
+			return fmt.Errorf("github.app.installation_id is required when using GitHub App auth")
+		}
+		if c.GitHub.App.PrivateKey == "" && c.GitHub.App.PrivateKeyPath == "" {
+			return fmt.Errorf("github.app.private_key or github.app.private_key_path is required")
+		}
+	}
+
+	return nil
+}
+
+// spotPath returns the path of the first engine, the primary or a
+// fallback, that creates GCP spot VMs, or "" if none does.
+func (e *EngineConfig) spotPath() string {
+	if e.GCP.Enable && e.GCP.Spot {
+		return "engine"
+	}
+	if fb := e.Fallback; fb != nil {
+		for i, f := range fb.Engines {
+			if f.GCP.Enable && f.GCP.Spot {
+				return fmt.Sprintf("engine.fallback.engines[%d]", i)
+			}
+		}
+	}
+	return ""
+}
+
+// validate checks that exactly one engine is enabled and that its
+// required settings are present.  path prefixes error messages, e.g.
+// "engine" or "engine.fallback.engines[0]".
+func (e *EngineConfig) validate(path string) error {
+	enabled := []string{}
+	if e.Docker.Enable {
+		enabled = append(enabled, "docker")
+	}
+	if e.GCP.Enable {
+		enabled = append(enabled, "gcp")
+	}
+	if e.Kubernetes.Enable {
+		enabled = append(enabled, "kubernetes")
+	}
+	if e.HCloud.Enable {
+		enabled = append(enabled, "hcloud")
+	}
+	if e.Podman.Enable {
+		enabled = append(enabled, "podman")
+	}
+	if e.Plugin.Enable {
+		enabled = append(enabled, "plugin")
+	}
+	if e.Fake.Enable {
+		enabled = append(enabled, "fake")
+	}
+	if e.AWS.Enable {
+		enabled = append(enabled, "aws")
+	}
+	if e.Azure.Enable {
+		enabled = append(enabled, "azure")
+	}
+
+	if len(enabled) == 0 {
+		return fmt.Errorf("%s: at least one engine must have enable: true (supported: docker, gcp, kubernetes, hcloud, podman, plugin, fake; planned: aws, azure)", path)
+	}
+	if len(enabled) > 1 {
+		return fmt.Errorf("%s: only one engine can be enabled at a time, but %d are enabled: %v", path, len(enabled), enabled)
+	}
+
+	// Validate the enabled engine's required fields
+	switch enabled[0] {
+	case "docker":
+		switch e.Docker.DindMode {
+		case docker.DindModeSocket, docker.DindModeSidecar:
+		default:
+			return fmt.Errorf("%s.docker.dind_mode must be %q or %q, got %q", path, docker.DindModeSocket, docker.DindModeSidecar, e.Docker.DindMode)
+		}
+		if e.Docker.DindMode == docker.DindModeSidecar && !e.Docker.Dind {
+			return fmt.Errorf("%s.docker.dind_mode %q requires %s.docker.dind", path, e.Docker.DindMode, path)
+		}
+		if e.Docker.DindCleanup && !e.Docker.Dind {
+			return fmt.Errorf("%s.docker.dind_cleanup requires %s.docker.dind", path, path)
+		}
+		if e.Docker.DindCleanup && e.Docker.DindMode == docker.DindModeSidecar {
+			return fmt.Errorf("%s.docker.dind_cleanup applies to dind_mode %q; sidecar containers are removed with their daemon", path, docker.DindModeSocket)
+		}
+		if e.Docker.Dind && e.Docker.DindMode == docker.DindModeSocket && e.Docker.Host != "" && !docker.IsLocalHost(e.Docker.Host) {
+			return fmt.Errorf("%s.docker.dind mounts the local /var/run/docker.sock and requires a local daemon, but %s.docker.host is %q", path, path, e.Docker.Host)
+		}
+		if e.Docker.StopTimeout < 0 {
+			return fmt.Errorf("%s.docker.stop_timeout must be >= 0, got %s", path, e.Docker.StopTimeout)
+		}
+		if e.Docker.StartRetries < 0 {
+			return fmt.Errorf("%s.docker.start_retries must be >= 0, got %d", path, e.Docker.StartRetries)
+		}
+		for k := range e.Docker.Env {
+			if k == "" || strings.ContainsAny(k, "= ") {
+				return fmt.Errorf("%s.docker.env: invalid variable name %q", path, k)
+			}
+			if k == "ACTIONS_RUNNER_INPUT_JITCONFIG" {
+				return fmt.Errorf("%s.docker.env: %s is set by scaleset", path, k)
+			}
+		}
+		if e.Docker.PreloadConcurrency < 0 {
+			return fmt.Errorf("%s.docker.preload_concurrency must be >= 0, got %d", path, e.Docker.PreloadConcurrency)
+		}
+		if e.Docker.PreloadTimeout < 0 {
+			return fmt.Errorf("%s.docker.preload_timeout must be >= 0, got %s", path, e.Docker.PreloadTimeout)
+		}
+		if e.Docker.CPUs < 0 {
+			return fmt.Errorf("%s.docker.cpus must be >= 0, got %g", path, e.Docker.CPUs)
+		}
+		if _, err := docker.ParseSize(e.Docker.Memory); err != nil {
+			return fmt.Errorf("%s.docker.memory: %w", path, err)
+		}
+		if e.Docker.PidsLimit < 0 {
+			return fmt.Errorf("%s.docker.pids_limit must be >= 0, got %d", path, e.Docker.PidsLimit)
+		}
+		if _, err := docker.ParseSize(e.Docker.ShmSize); err != nil {
+			return fmt.Errorf("%s.docker.shm_size: %w", path, err)
+		}
+		if e.Docker.Network != "" && e.Docker.Dind && e.Docker.DindMode == docker.DindModeSidecar {
+			return fmt.Errorf("%s.docker.network: not allowed with dind_mode %q, runners join their daemon's network", path, docker.DindModeSidecar)
+		}
+		for i, h := range e.Docker.ExtraHosts {
+			if err := docker.ValidateExtraHost(h); err != nil {
+				return fmt.Errorf("%s.docker.extra_hosts[%d]: %w", path, i, err)
+			}
+		}
+		for i, m := range e.Docker.Mounts {
+			if err := docker.ValidateMount(m); err != nil {
+				return fmt.Errorf("%s.docker.mounts[%d]: %w", path, i, err)
+			}
+		}
+		if _, err := docker.ParseGPUs(e.Docker.GPUs); err != nil {
+			return fmt.Errorf("%s.docker.gpus: %w", path, err)
+		}
+		seen := map[string]bool{e.Docker.Image: true}
+		if e.Docker.Dind && e.Docker.DindMode == docker.DindModeSidecar {
+			seen[e.Docker.DindImage] = true
+		}
+		for i, img := range e.Docker.PreloadImages {
+			if img.Image == "" {
+				return fmt.Errorf("%s.docker.preload_images[%d].image is required", path, i)
+			}
+			if seen[img.Image] {
+				return fmt.Errorf("%s.docker.preload_images[%d]: %q is already pulled", path, i, img.Image)
+			}
+			seen[img.Image] = true
+		}
+	case "gcp":
+		if e.GCP.Project == "" {
+			return fmt.Errorf("%s.gcp.project is required when GCP engine is enabled", path)
+		}
+		if e.GCP.Zone == "" {
+			return fmt.Errorf("%s.gcp.zone is required when GCP engine is enabled", path)
+		}
+		if err := validateZones(path+".gcp", e.GCP.Zone, e.GCP.Zones); err != nil {
+			return err
+		}
+		if len(e.GCP.Zones) > 1 && e.GCP.ZoneInRunnerName {
+			return fmt.Errorf("%s.gcp.zone_in_runner_name cannot be used with several zones: the zone is picked after the name", path)
+		}
+		if e.GCP.Image == "" {
+			return fmt.Errorf("%s.gcp.image is required when GCP engine is enabled", path)
+		}
+		switch e.GCP.QuotaCheck {
+		case "", "warn", "error":
+		default:
+			return fmt.Errorf("%s.gcp.quota_check must be \"warn\" or \"error\", got %q", path, e.GCP.QuotaCheck)
+		}
+		if e.GCP.BulkInsertThreshold < 1 {
+			return fmt.Errorf("%s.gcp.bulk_insert_threshold must be >= 1, got %d", path, e.GCP.BulkInsertThreshold)
+		}
+		if e.GCP.DiskIOPS < 0 {
+			return fmt.Errorf("%s.gcp.disk_iops must be >= 0, got %d", path, e.GCP.DiskIOPS)
+		}
+		if e.GCP.DiskIOPS > 0 && !gcp.SupportsProvisionedIOPS(e.GCP.DiskType) {
+			return fmt.Errorf("%s.gcp.disk_iops is not supported by disk type %q (use pd-extreme, hyperdisk-balanced or hyperdisk-extreme)", path, e.GCP.DiskType)
+		}
+		if e.GCP.DiskThroughput < 0 {
+			return fmt.Errorf("%s.gcp.disk_throughput must be >= 0, got %d", path, e.GCP.DiskThroughput)
+		}
+		if e.GCP.DiskThroughput > 0 && !gcp.SupportsProvisionedThroughput(e.GCP.DiskType) {
+			return fmt.Errorf("%s.gcp.disk_throughput is not supported by disk type %q (use hyperdisk-balanced or hyperdisk-throughput)", path, e.GCP.DiskType)
+		}
+		for k, v := range e.GCP.Metadata {
+			switch {
+			case k == "ACTIONS_RUNNER_INPUT_JITCONFIG", k == gcp.StartupScriptKey:
+				return fmt.Errorf("%s.gcp.metadata: %s is set by the engine", path, k)
+			case !validMetadataKey(k):
+				return fmt.Errorf("%s.gcp.metadata: key %q must be 1-%d letters, digits, '-' or '_'", path, k, maxGCPMetadataKeyLength)
+			case len(v) > maxGCPMetadataValueSize:
+				return fmt.Errorf("%s.gcp.metadata: value of %q is %d bytes, GCP allows at most %d", path, k, len(v), maxGCPMetadataValueSize)
+			}
+		}
+		if len(e.GCP.StartupScript) > maxGCPMetadataValueSize {
+			return fmt.Errorf("%s.gcp.startup_script is %d bytes, GCP allows at most %d", path, len(e.GCP.StartupScript), maxGCPMetadataValueSize)
+		}
+		if err := validateAccelerators(path+".gcp.accelerators", e.GCP.Accelerators); err != nil {
+			return err
+		}
+	case "kubernetes":
+		for i, t := range e.Kubernetes.Tolerations {
+			switch t.Operator {
+			case "", "Equal":
+				if t.Key == "" {
+					return fmt.Errorf("%s.kubernetes.tolerations[%d]: key is required with operator Equal", path, i)
+				}
+			case "Exists":
+				if t.Value != "" {
+					return fmt.Errorf("%s.kubernetes.tolerations[%d]: value must be empty with operator Exists", path, i)
+				}
+			default:
+				return fmt.Errorf("%s.kubernetes.tolerations[%d].operator must be \"Equal\" or \"Exists\", got %q", path, i, t.Operator)
+			}
+			switch t.Effect {
+			case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+			default:
+				return fmt.Errorf("%s.kubernetes.tolerations[%d].effect must be NoSchedule, PreferNoSchedule or NoExecute, got %q", path, i, t.Effect)
+			}
+		}
+		for k := range e.Kubernetes.Env {
+			if k == "" || strings.ContainsAny(k, "= ") {
+				return fmt.Errorf("%s.kubernetes.env: invalid variable name %q", path, k)
+			}
+			if k == "ACTIONS_RUNNER_INPUT_JITCONFIG" {
+				return fmt.Errorf("%s.kubernetes.env: %s is set by scaleset", path, k)
+			}
+		}
+	case "hcloud":
+		if e.HCloud.Token == "" && os.Getenv("HCLOUD_TOKEN") == "" {
+			return fmt.Errorf("%s.hcloud.token is required when Hetzner Cloud engine is enabled (or set HCLOUD_TOKEN)", path)
+		}
+	case "podman":
+		if !podman.ValidUserNS(e.Podman.UserNS) {
+			return fmt.Errorf("%s.podman.userns: unknown mode %q (use keep-id, auto, nomap or host)", path, e.Podman.UserNS)
+		}
+		if e.Podman.StopTimeout < 0 {
+			return fmt.Errorf("%s.podman.stop_timeout must be >= 0, got %s", path, e.Podman.StopTimeout)
+		}
+		for k := range e.Podman.Env {
+			if k == "" || strings.ContainsAny(k, "= ") {
+				return fmt.Errorf("%s.podman.env: invalid variable name %q", path, k)
+			}
+			if k == "ACTIONS_RUNNER_INPUT_JITCONFIG" {
+				return fmt.Errorf("%s.podman.env: %s is set by scaleset", path, k)
+			}
+		}
+		if e.Podman.CPUs < 0 {
+			return fmt.Errorf("%s.podman.cpus must be >= 0, got %g", path, e.Podman.CPUs)
+		}
+		if _, err := docker.ParseSize(e.Podman.Memory); err != nil {
+			return fmt.Errorf("%s.podman.memory: %w", path, err)
+		}
+		if e.Podman.PidsLimit < 0 {
+			return fmt.Errorf("%s.podman.pids_limit must be >= 0, got %d", path, e.Podman.PidsLimit)
+		}
+		if _, err := docker.ParseSize(e.Podman.ShmSize); err != nil {
+			return fmt.Errorf("%s.podman.shm_size: %w", path, err)
+		}
+	case "plugin":
+		if e.Plugin.Path == "" {
+			return fmt.Errorf("%s.plugin.path is required when the plugin engine is enabled", path)
+		}
+		if e.Plugin.StartTimeout < 0 {
+			return fmt.Errorf("%s.plugin.start_timeout must be >= 0, got %s", path, e.Plugin.StartTimeout)
+		}
+		for _, name := range e.Plugin.Env {
+			if name == "" || strings.Contains(name, "=") {
+				return fmt.Errorf("%s.plugin.env: invalid variable name %q", path, name)
+			}
+		}
+		if _, err := structpb.NewStruct(e.Plugin.Config); err != nil {
+			return fmt.Errorf("%s.plugin.config: %w", path, err)
+		}
+	case "fake":
+		if e.Fake.StartLatency < 0 {
+			return fmt.Errorf("%s.fake.start_latency must be >= 0, got %s", path, e.Fake.StartLatency)
+		}
+		if e.Fake.DestroyLatency < 0 {
+			return fmt.Errorf("%s.fake.destroy_latency must be >= 0, got %s", path, e.Fake.DestroyLatency)
+		}
+		if e.Fake.Jitter < 0 {
+			return fmt.Errorf("%s.fake.jitter must be >= 0, got %s", path, e.Fake.Jitter)
+		}
+		if r := e.Fake.StartFailureRate; r < 0 || r > 1 {
+			return fmt.Errorf("%s.fake.start_failure_rate must be between 0 and 1, got %g", path, r)
+		}
+		if r := e.Fake.DestroyFailureRate; r < 0 || r > 1 {
+			return fmt.Errorf("%s.fake.destroy_failure_rate must be between 0 and 1, got %g", path, r)
+		}
+	case "aws":
+		return fmt.Errorf("aws engine is not yet implemented")
+	case "azure":
+		return fmt.Errorf("azure engine is not yet implemented")
+	}
+
+	return nil
+}
+
+// GCP's limits on instance metadata entries.
+const (
+	maxGCPMetadataKeyLength = 128
+	maxGCPMetadataValueSize = 256 << 10
+)
+
+// validMetadataKey reports whether k is a valid GCP metadata key.
+func validMetadataKey(k string) bool {
+	if k == "" || len(k) > maxGCPMetadataKeyLength {
+		return false
+	}
+	for _, r := range k {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// GitHub's limits on the labels of a runner scale set.  Violations are
+// rejected by the API when the scale set is created, after the process
+// has already authenticated and looked up the runner group.
+const (
+	maxScaleSetLabels      = 100
+	maxScaleSetLabelLength = 256
+)
+
+// validateLabels checks the labels the scale set will be registered with
+// (scaleset.labels, or the name when none are set) against GitHub's
+// limits.  GitHub compares labels case-insensitively, so labels that
+// differ only in case are duplicates.
+func validateLabels(labels []scaleset.Label) error {
+	if len(labels) > maxScaleSetLabels {
+		return fmt.Errorf("scaleset.labels: %d labels exceeds GitHub's limit of %d", len(labels), maxScaleSetLabels)
+	}
+	seen := make(map[string]int, len(labels))
+	for i, l := range labels {
+		if n := len(l.Name); n > maxScaleSetLabelLength {
+			return fmt.Errorf("scaleset.labels[%d]: %d characters exceeds GitHub's limit of %d", i, n, maxScaleSetLabelLength)
+		}
+		key := strings.ToLower(l.Name)
+		if j, ok := seen[key]; ok {
+			return fmt.Errorf("scaleset.labels[%d]: %q duplicates scaleset.labels[%d]", i, l.Name, j)
+		}
+		seen[key] = i
+	}
+	return nil
+}
+
+// validLabelValue reports whether v can be used as a label value by
+// every engine; GCP is the strictest, allowing at most 63 lowercase
+// letters, digits, '-' and '_'.
+func validLabelValue(v string) bool {
+	if len(v) > 63 {
+		return false
+	}
+	for _, r := range v {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------------------------------------------------------------------------
+// Factories
+// ---------------------------------------------------------------------------
+
+// NewLogger creates a *slog.Logger from the Logging configuration.
+func (c *Config) NewLogger() (*slog.Logger, error) {
+	return c.NewLoggerWithLevel(c.SlogLevel())
+}
+
+// NewLoggerWithLevel is NewLogger with the minimum level taken from level
+// instead of logging.level, e.g. a *slog.LevelVar so that the level can
+// be changed on config reload.
+func (c *Config) NewLoggerWithLevel(level slog.Leveler) (*slog.Logger, error) {
+	w, err := c.logOutput()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
 	}
 
 	switch strings.ToLower(c.Logging.Format) {
 	case "json":
-		return slog.New(slog.NewJSONHandler(os.Stdout, opts))
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
 	case "text":
-		return slog.New(slog.NewTextHandler(os.Stdout, opts))
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	}
+}
+
+// logOutput resolves Logging.Output to a writer.  Anything other than
+// "stdout" or "stderr" is treated as a file path and opened for append.
+func (c *Config) logOutput() (io.Writer, error) {
+	switch strings.ToLower(c.Logging.Output) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
 	default:
-		return slog.New(slog.NewTextHandler(os.Stdout, opts))
+		f, err := os.OpenFile(c.Logging.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening log file %s: %w", c.Logging.Output, err)
+		}
+		return f, nil
+	}
+}
+
+// ScaleSetLogger returns logger tagged with the scale set name and ID
+// when Logging.IncludeScaleSet is enabled, or logger unchanged otherwise.
+func (c *Config) ScaleSetLogger(logger *slog.Logger, scaleSetID int) *slog.Logger {
+	if !c.Logging.IncludeScaleSet {
+		return logger
 	}
+	return logger.With(
+		slog.String("scale_set", c.ScaleSet.Name),
+		slog.Int("scale_set_id", scaleSetID),
+	)
 }
 
-func (c *Config) slogLevel() slog.Level {
+// SlogLevel returns logging.level as a slog.Level (info when unset).
+func (c *Config) SlogLevel() slog.Level {
 	switch strings.ToLower(c.Logging.Level) {
 	case "debug":
 		return slog.LevelDebug
@@ -454,6 +2757,60 @@ func (c *Config) slogLevel() slog.Level {
 	}
 }
 
+// ResolveAPIURL returns the GitHub API base URL.  An explicit github.api_url
+// takes precedence; otherwise the URL is derived from github.url using
+// the same rules as the scaleset SDK.
+func (g *GitHubConfig) ResolveAPIURL() string {
+	if g.APIURL != "" {
+		return strings.TrimRight(g.APIURL, "/")
+	}
+	return deriveAPIURL(g.URL)
+}
+
+// IsEnterprise reports whether URL points at a GitHub Enterprise Server
+// rather than at GitHub-hosted github.com or GHE.com.
+func (g *GitHubConfig) IsEnterprise() bool {
+	u, err := url.Parse(strings.Trim(g.URL, "/"))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return !isHostedGitHub(u.Host)
+}
+
+// isHostedGitHub mirrors the scaleset SDK's check for GitHub-hosted
+// hosts (github.com, *.ghe.com); GITHUB_ACTIONS_FORCE_GHES makes every
+// host GHES.
+func isHostedGitHub(host string) bool {
+	if _, forceGHES := os.LookupEnv("GITHUB_ACTIONS_FORCE_GHES"); forceGHES {
+		return false
+	}
+	host = strings.ToLower(host)
+	return host == "github.com" ||
+		host == "www.github.com" ||
+		host == "github.localhost" ||
+		strings.HasSuffix(host, ".ghe.com")
+}
+
+// deriveAPIURL mirrors the scaleset SDK's API URL inference: hosted
+// GitHub (github.com, *.ghe.com) uses the api. subdomain, anything else
+// is treated as GHES and uses <host>/api/v3.
+func deriveAPIURL(configURL string) string {
+	u, err := url.Parse(strings.Trim(configURL, "/"))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	hosted := isHostedGitHub(u.Host)
+	switch {
+	case hosted && strings.EqualFold(u.Host, "www.github.com"):
+		return u.Scheme + "://api.github.com"
+	case hosted:
+		return u.Scheme + "://api." + u.Host
+	default:
+		return u.Scheme + "://" + u.Host + "/api/v3"
+	}
+}
+
 // NewScalesetClient creates a scaleset.Client using the configured
 // credentials (GitHub App or PAT).
 func (c *Config) NewScalesetClient() (*scaleset.Client, error) {
@@ -461,6 +2818,29 @@ func (c *Config) NewScalesetClient() (*scaleset.Client, error) {
 		return nil, err
 	}
 
+	// The scaleset SDK always derives the API base from the config URL
+	// and offers no way to redirect it, so an override that disagrees
+	// with the derived URL cannot be honoured.  Fail loudly rather than
+	// silently talking to a different endpoint than configured.
+	if c.GitHub.APIURL != "" {
+		if derived := deriveAPIURL(c.GitHub.URL); !strings.EqualFold(c.GitHub.ResolveAPIURL(), derived) {
+			return nil, fmt.Errorf("github.api_url %q differs from the API URL derived from github.url (%q); the scaleset client cannot use a separate API base", c.GitHub.APIURL, derived)
+		}
+	}
+
+	// The SDK's transport uses no proxy unless given one, so the
+	// environment's is passed explicitly when proxy_url is unset.  The
+	// message session client inherits these options.
+	egressCfg := c.Network.Egress()
+	roots, err := egressCfg.RootCAs()
+	if err != nil {
+		return nil, fmt.Errorf("network.ca_bundle_path: %w", err)
+	}
+	opts := []scaleset.HTTPOption{scaleset.WithProxy(egressCfg.Proxy())}
+	if roots != nil {
+		opts = append(opts, scaleset.WithRootCAs(roots))
+	}
+
 	sysInfo := scaleset.SystemInfo{
 		System:    "terrpan-scaleset",
 		Subsystem: "cli",
@@ -477,14 +2857,92 @@ func (c *Config) NewScalesetClient() (*scaleset.Client, error) {
 				PrivateKey:     c.GitHub.App.PrivateKey,
 			},
 			SystemInfo: sysInfo,
-		})
+		}, opts...)
 	}
 
 	return scaleset.NewClientWithPersonalAccessToken(scaleset.NewClientWithPersonalAccessTokenConfig{
 		GitHubConfigURL:     c.GitHub.URL,
 		PersonalAccessToken: c.GitHub.Token,
 		SystemInfo:          sysInfo,
-	})
+	}, opts...)
+}
+
+// ResolveSecrets replaces the GitHub credentials that refer to a
+// secrets manager (github.token and github.app.private_key, top-level
+// and per scale_sets entry; see package secrets), and webhook.secret,
+// with the secrets.
+func (c *Config) ResolveSecrets(ctx context.Context) error {
+	r := secrets.NewResolver(c.Network.Egress())
+	if err := c.GitHub.resolveSecrets(ctx, r, "github"); err != nil {
+		return err
+	}
+	for i := range c.ScaleSets {
+		if err := c.ScaleSets[i].GitHub.resolveSecrets(ctx, r, fmt.Sprintf("scale_sets[%d].github", i)); err != nil {
+			return err
+		}
+	}
+	if secrets.IsReference(c.Webhook.Secret) {
+		v, err := r.Resolve(ctx, c.Webhook.Secret)
+		if err != nil {
+			return fmt.Errorf("webhook.secret: %w", err)
+		}
+		c.Webhook.Secret = v
+	}
+	return nil
+}
+
+func (g *GitHubConfig) resolveSecrets(ctx context.Context, r *secrets.Resolver, path string) error {
+	for _, f := range []struct {
+		name  string
+		value *string
+	}{
+		{"token", &g.Token},
+		{"app.private_key", &g.App.PrivateKey},
+	} {
+		if !secrets.IsReference(*f.value) {
+			continue
+		}
+		v, err := r.Resolve(ctx, *f.value)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", path, f.name, err)
+		}
+		*f.value = v
+	}
+	return nil
+}
+
+// validateWebhook checks the webhook section.
+func (c *Config) validateWebhook() error {
+	w := &c.Webhook
+	if !w.Enable {
+		return nil
+	}
+	if w.Port < 1 || w.Port > 65535 {
+		return fmt.Errorf("webhook.port must be between 1 and 65535, got %d", w.Port)
+	}
+	for _, other := range []struct {
+		used bool
+		port int
+		name string
+	}{
+		{c.Prometheus.Enable, c.Prometheus.Port, "the metrics server (prometheus.port)"},
+		{c.Health.IsEnabled(), c.Health.Port, "the health server (health.port)"},
+		{c.Admin.Enable, c.Admin.Port, "the admin API (admin.port)"},
+	} {
+		if other.used && w.Port == other.port {
+			return fmt.Errorf("webhook.port: %d is already used by %s", w.Port, other.name)
+		}
+	}
+	if !strings.HasPrefix(w.Path, "/") {
+		return fmt.Errorf("webhook.path must start with '/', got %q", w.Path)
+	}
+	if w.Secret == "" {
+		return fmt.Errorf("webhook.secret is required when webhook.enable is set")
+	}
+	if w.Mode != WebhookModeAssist && w.Mode != WebhookModeOnly {
+		return fmt.Errorf("webhook.mode must be %q or %q, got %q", WebhookModeAssist, WebhookModeOnly, w.Mode)
+	}
+	return nil
 }
 
 // resolvePrivateKey reads the private key from PrivateKeyPath if
@@ -502,36 +2960,409 @@ func (c *Config) resolvePrivateKey() error {
 }
 
 // NewEngine creates the compute engine based on which engine is enabled.
+// With engine.fallback configured it returns a failover engine over the
+// primary and its fallbacks.
 func (c *Config) NewEngine(ctx context.Context, logger *slog.Logger) (engine.Engine, error) {
-	if c.Engine.Docker.Enable {
-		return docker.New(ctx, docker.Config{
-			Image: c.Engine.Docker.Image,
-			Dind:  c.Engine.Docker.Dind,
-		}, logger.WithGroup("engine.docker"))
-	}
-	if c.Engine.GCP.Enable {
-		return gcp.New(ctx, gcp.Config{
-			Project:        c.Engine.GCP.Project,
-			Zone:           c.Engine.GCP.Zone,
-			MachineType:    c.Engine.GCP.MachineType,
-			Image:          c.Engine.GCP.Image,
-			DiskSizeGB:     c.Engine.GCP.DiskSizeGB,
-			Network:        c.Engine.GCP.Network,
-			Subnet:         c.Engine.GCP.Subnet,
-			PublicIP:       *c.Engine.GCP.PublicIP,
-			ServiceAccount: c.Engine.GCP.ServiceAccount,
-		}, logger.WithGroup("engine.gcp"))
-	}
-	if c.Engine.AWS.Enable {
+	profile, err := c.selectProfile()
+	if err != nil {
+		return nil, err
+	}
+	withProfile := func(ec *EngineConfig) *EngineConfig {
+		return applyProfile(profile, ec)
+	}
+	if profile != nil {
+		logger.Info("engine profile selected",
+			slog.String("profile", profile.Name),
+			slog.Any("labels", profile.Labels),
+		)
+	}
+
+	primary, err := c.newEngine(ctx, withProfile(&c.Engine), logger)
+	if err != nil {
+		return nil, err
+	}
+	fb := c.Engine.Fallback
+	if fb == nil {
+		return primary, nil
+	}
+
+	members := []failover.Member{{Name: c.Engine.EnabledEngine(), Engine: primary}}
+	for i := range fb.Engines {
+		eng, err := c.newEngine(ctx, withProfile(&fb.Engines[i]), logger.With(slog.Int("fallback", i)))
+		if err != nil {
+			for _, m := range members {
+				_ = m.Engine.Shutdown(ctx)
+			}
+			return nil, fmt.Errorf("engine.fallback.engines[%d]: %w", i, err)
+		}
+		members = append(members, failover.Member{Name: fb.Engines[i].EnabledEngine(), Engine: eng})
+	}
+	return failover.New(members, failover.Config{
+		AfterFailures:     fb.AfterFailures,
+		RetryPrimaryAfter: fb.RetryPrimaryAfter,
+	}, logger.WithGroup("engine.failover"))
+}
+
+// applyProfile returns ec with profile applied, or ec itself when
+// profile is nil.
+func applyProfile(profile *EngineProfile, ec *EngineConfig) *EngineConfig {
+	if profile == nil {
+		return ec
+	}
+	applied := profile.apply(*ec)
+	return &applied
+}
+
+// EngineCheck is the outcome of preflighting one engine.
+type EngineCheck struct {
+	// Path is the engine's config path: "engine" or
+	// "engine.fallback.engines[N]".
+	Path string
+	// Engine is the engine's name, e.g. "docker".
+	Engine      string
+	Diagnostics map[string]string
+	Err         error
+}
+
+// PreflightEngines checks, without starting runners, that each engine
+// NewEngine would create -- the primary with the selected profile
+// applied, and its fallbacks -- works against its backend.  Docker and
+// GCP run their Preflight, which pulls and creates nothing; other
+// engines are created and their engine.Checker, if any, is run.
+func (c *Config) PreflightEngines(ctx context.Context, logger *slog.Logger) ([]EngineCheck, error) {
+	profile, err := c.selectProfile()
+	if err != nil {
+		return nil, err
+	}
+	checks := []EngineCheck{{Path: "engine", Engine: c.Engine.EnabledEngine()}}
+	ecs := []*EngineConfig{applyProfile(profile, &c.Engine)}
+	if fb := c.Engine.Fallback; fb != nil {
+		for i := range fb.Engines {
+			checks = append(checks, EngineCheck{
+				Path:   fmt.Sprintf("engine.fallback.engines[%d]", i),
+				Engine: fb.Engines[i].EnabledEngine(),
+			})
+			ecs = append(ecs, applyProfile(profile, &fb.Engines[i]))
+		}
+	}
+	for i := range checks {
+		checks[i].Diagnostics, checks[i].Err = c.preflightEngine(ctx, ecs[i], logger)
+	}
+	return checks, nil
+}
+
+// preflightEngine checks the single engine enabled in ec.
+func (c *Config) preflightEngine(ctx context.Context, ec *EngineConfig, logger *slog.Logger) (map[string]string, error) {
+	switch {
+	case ec.Docker.Enable:
+		return docker.Preflight(ctx, c.dockerConfig(ec))
+	case ec.GCP.Enable:
+		return gcp.Preflight(ctx, c.gcpConfig(ec))
+	case ec.Podman.Enable:
+		return podman.Preflight(ctx, c.podmanConfig(ec))
+	}
+	eng, err := c.newEngine(ctx, ec, logger)
+	if err != nil {
+		return nil, err
+	}
+	// Nothing is tracked, so Shutdown only releases the engine's clients.
+	defer eng.Shutdown(context.WithoutCancel(ctx))
+	checker, ok := eng.(engine.Checker)
+	if !ok {
+		return nil, nil
+	}
+	return checker.Check(ctx)
+}
+
+// newEngine creates the single engine enabled in ec.
+func (c *Config) newEngine(ctx context.Context, ec *EngineConfig, logger *slog.Logger) (engine.Engine, error) {
+	if ec.Docker.Enable {
+		return docker.New(ctx, c.dockerConfig(ec), logger.WithGroup("engine.docker"))
+	}
+	if ec.GCP.Enable {
+		eng, err := gcp.New(ctx, c.gcpConfig(ec), logger.WithGroup("engine.gcp"))
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkQuota(ctx, ec.GCP.QuotaCheck, eng, logger); err != nil {
+			_ = eng.Shutdown(ctx)
+			return nil, err
+		}
+		return eng, nil
+	}
+	if ec.Kubernetes.Enable {
+		return kubernetes.New(ctx, kubernetes.Config{
+			Namespace: ec.Kubernetes.Namespace,
+			Image:     ec.Kubernetes.Image,
+			Resources: kubernetes.Resources{
+				Requests: ec.Kubernetes.Resources.Requests,
+				Limits:   ec.Kubernetes.Resources.Limits,
+			},
+			NodeSelector:       ec.Kubernetes.NodeSelector,
+			Tolerations:        kubernetesTolerations(ec.Kubernetes.Tolerations),
+			ServiceAccountName: ec.Kubernetes.ServiceAccountName,
+			RunID:              c.ScaleSet.RunID,
+			Env:                ec.Kubernetes.Env,
+		}, logger.WithGroup("engine.kubernetes"))
+	}
+	if ec.HCloud.Enable {
+		return hcloud.New(ctx, hcloud.Config{
+			Token:      ec.HCloud.Token,
+			ServerType: ec.HCloud.ServerType,
+			Image:      ec.HCloud.Image,
+			Location:   ec.HCloud.Location,
+			SSHKeys:    ec.HCloud.SSHKeys,
+			PublicIPv4: ec.HCloud.PublicIPv4 == nil || *ec.HCloud.PublicIPv4,
+			RunnerDir:  ec.HCloud.RunnerDir,
+			RunnerUser: ec.HCloud.RunnerUser,
+			RunID:      c.ScaleSet.RunID,
+			Egress:     c.Network.Egress(),
+		}, logger.WithGroup("engine.hcloud"))
+	}
+	if ec.Podman.Enable {
+		return podman.New(ctx, c.podmanConfig(ec), logger.WithGroup("engine.podman"))
+	}
+	if ec.Plugin.Enable {
+		return plugin.New(ctx, plugin.Config{
+			Path:         ec.Plugin.Path,
+			Args:         ec.Plugin.Args,
+			Env:          ec.Plugin.Env,
+			Config:       ec.Plugin.Config,
+			RunID:        c.ScaleSet.RunID,
+			StartTimeout: ec.Plugin.StartTimeout,
+		}, logger.WithGroup("engine.plugin"))
+	}
+	if ec.Fake.Enable {
+		return fake.New(fake.Config{
+			StartLatency:       ec.Fake.StartLatency,
+			DestroyLatency:     ec.Fake.DestroyLatency,
+			Jitter:             ec.Fake.Jitter,
+			StartFailureRate:   ec.Fake.StartFailureRate,
+			DestroyFailureRate: ec.Fake.DestroyFailureRate,
+			Seed:               ec.Fake.Seed,
+			RunID:              c.ScaleSet.RunID,
+			ScaleSetName:       c.ScaleSet.Name,
+		}, logger.WithGroup("engine.fake"))
+	}
+	if ec.AWS.Enable {
 		return nil, fmt.Errorf("aws engine is not yet implemented")
 	}
-	if c.Engine.Azure.Enable {
+	if ec.Azure.Enable {
 		return nil, fmt.Errorf("azure engine is not yet implemented")
 	}
 
 	return nil, fmt.Errorf("no engine is enabled")
 }
 
+// dockerConfig returns the docker engine settings of ec.
+func (c *Config) dockerConfig(ec *EngineConfig) docker.Config {
+	return docker.Config{
+		Image:        ec.Docker.Image,
+		Host:         ec.Docker.Host,
+		Dind:         ec.Docker.Dind,
+		DindMode:     ec.Docker.DindMode,
+		DindImage:    ec.Docker.DindImage,
+		DindCleanup:  ec.Docker.DindCleanup,
+		StopTimeout:  ec.Docker.StopTimeout,
+		StartRetries: ec.Docker.StartRetries,
+		Init:         ec.Docker.Init,
+		RunID:        c.ScaleSet.RunID,
+		ScaleSetName: c.ScaleSet.Name,
+		Env:          c.runnerEnv(&ec.Docker),
+		PinDigest:    ec.Docker.PinImageDigest,
+		Egress:       c.Network.Egress(),
+
+		PreloadImages:      preloadImages(ec.Docker.PreloadImages),
+		PreloadConcurrency: ec.Docker.PreloadConcurrency,
+		PreloadTimeout:     ec.Docker.PreloadTimeout,
+
+		Limits: dockerLimits(&ec.Docker),
+
+		Network:    ec.Docker.Network,
+		ExtraHosts: ec.Docker.ExtraHosts,
+		Mounts:     ec.Docker.Mounts,
+		GPUs:       dockerGPUs(&ec.Docker),
+	}
+}
+
+// dockerGPUs returns the GPU request of d.  The spec was checked by
+// Validate.
+func dockerGPUs(d *DockerEngineConfig) *container.DeviceRequest {
+	gpus, _ := docker.ParseGPUs(d.GPUs)
+	return gpus
+}
+
+// dockerLimits returns the container resource limits of d.  The sizes
+// were checked by Validate.
+func dockerLimits(d *DockerEngineConfig) docker.Limits {
+	memory, _ := docker.ParseSize(d.Memory)
+	shmSize, _ := docker.ParseSize(d.ShmSize)
+	return docker.Limits{
+		CPUs:      d.CPUs,
+		Memory:    memory,
+		PidsLimit: d.PidsLimit,
+		ShmSize:   shmSize,
+	}
+}
+
+// podmanConfig returns the podman engine settings of ec.  The sizes
+// were checked by Validate.
+func (c *Config) podmanConfig(ec *EngineConfig) podman.Config {
+	memory, _ := docker.ParseSize(ec.Podman.Memory)
+	shmSize, _ := docker.ParseSize(ec.Podman.ShmSize)
+	return podman.Config{
+		Image:        ec.Podman.Image,
+		Socket:       ec.Podman.Socket,
+		UserNS:       ec.Podman.UserNS,
+		Init:         ec.Podman.Init,
+		StopTimeout:  ec.Podman.StopTimeout,
+		RunID:        c.ScaleSet.RunID,
+		ScaleSetName: c.ScaleSet.Name,
+		Env:          ec.Podman.Env,
+		PinDigest:    ec.Podman.PinImageDigest,
+		Limits: docker.Limits{
+			CPUs:      ec.Podman.CPUs,
+			Memory:    memory,
+			PidsLimit: ec.Podman.PidsLimit,
+			ShmSize:   shmSize,
+		},
+	}
+}
+
+// gcpConfig returns the gcp engine settings of ec.
+func (c *Config) gcpConfig(ec *EngineConfig) gcp.Config {
+	return gcp.Config{
+		Project:             ec.GCP.Project,
+		Zone:                ec.GCP.Zone,
+		Zones:               ec.GCP.Zones,
+		MachineType:         ec.GCP.MachineType,
+		Image:               ec.GCP.Image,
+		DiskSizeGB:          ec.GCP.DiskSizeGB,
+		DiskType:            ec.GCP.DiskType,
+		DiskIOPS:            ec.GCP.DiskIOPS,
+		DiskThroughput:      ec.GCP.DiskThroughput,
+		Network:             ec.GCP.Network,
+		Subnet:              ec.GCP.Subnet,
+		AutoSubnet:          ec.GCP.AutoSubnet,
+		PublicIP:            *ec.GCP.PublicIP,
+		ServiceAccount:      ec.GCP.ServiceAccount,
+		UseBulkInsert:       ec.GCP.UseBulkInsert,
+		ZoneInRunnerName:    ec.GCP.ZoneInRunnerName,
+		BulkInsertThreshold: ec.GCP.BulkInsertThreshold,
+		RunID:               c.ScaleSet.RunID,
+		ScaleSetName:        c.ScaleSet.Name,
+		Metadata:            ec.GCP.Metadata,
+		StartupScript:       ec.GCP.StartupScript,
+		Accelerators:        gcpAccelerators(ec.GCP.Accelerators),
+		Spot:                ec.GCP.Spot,
+		Egress:              c.Network.Egress(),
+	}
+}
+
+// RunnerEnv returns the extra environment for runner containers: the
+// scale set context when engine.docker.context_env is set, overlaid with
+// engine.docker.env so operators can override any of it.
+func (c *Config) RunnerEnv() map[string]string {
+	return c.runnerEnv(&c.Engine.Docker)
+}
+
+// preloadImages converts engine.docker.preload_images to engine settings.
+func preloadImages(in []DockerPreloadImage) []docker.PreloadImage {
+	out := make([]docker.PreloadImage, len(in))
+	for i, img := range in {
+		out[i] = docker.PreloadImage{Image: img.Image, Required: img.Required}
+	}
+	return out
+}
+
+// gcpAccelerators converts the configured accelerators to the engine's
+// form.
+func gcpAccelerators(in []GCPAccelerator) []gcp.Accelerator {
+	out := make([]gcp.Accelerator, len(in))
+	for i, a := range in {
+		out[i] = gcp.Accelerator{Type: a.Type, Count: a.Count}
+	}
+	return out
+}
+
+// kubernetesTolerations converts the configured tolerations to the
+// engine's form.
+func kubernetesTolerations(in []KubernetesToleration) []kubernetes.Toleration {
+	out := make([]kubernetes.Toleration, len(in))
+	for i, t := range in {
+		out[i] = kubernetes.Toleration{Key: t.Key, Operator: t.Operator, Value: t.Value, Effect: t.Effect}
+	}
+	return out
+}
+
+// runnerEnv returns the runner environment for the Docker settings d.
+func (c *Config) runnerEnv(d *DockerEngineConfig) map[string]string {
+	env := make(map[string]string, len(d.Env)+6)
+	if d.ContextEnv {
+		labels := make([]string, 0, len(c.ScaleSet.Labels))
+		for _, l := range c.BuildLabels() {
+			labels = append(labels, l.Name)
+		}
+		env["SCALESET_NAME"] = c.ScaleSet.Name
+		env["SCALESET_GITHUB_URL"] = c.GitHub.URL
+		env["SCALESET_RUNNER_GROUP"] = c.ScaleSet.RunnerGroup
+		env["SCALESET_LABELS"] = strings.Join(labels, ",")
+		for k, v := range githubScope(c.GitHub.URL) {
+			env[k] = v
+		}
+	}
+	for k, v := range d.Env {
+		env[k] = v
+	}
+	return env
+}
+
+// githubScope splits a registration URL into SCALESET_ENTERPRISE, or
+// SCALESET_ORG and (for repository URLs) SCALESET_REPO ("org/repo").
+func githubScope(configURL string) map[string]string {
+	u, err := url.Parse(configURL)
+	if err != nil {
+		return nil
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "enterprises":
+		return map[string]string{"SCALESET_ENTERPRISE": parts[1]}
+	case len(parts) >= 2:
+		return map[string]string{
+			"SCALESET_ORG":  parts[0],
+			"SCALESET_REPO": parts[0] + "/" + parts[1],
+		}
+	case len(parts) == 1 && parts[0] != "":
+		return map[string]string{"SCALESET_ORG": parts[0]}
+	}
+	return nil
+}
+
+// quotaChecker is implemented by engines that can verify quota headroom
+// for max_runners at startup.
+type quotaChecker interface {
+	CheckQuotaHeadroom(ctx context.Context, maxRunners int) error
+}
+
+// checkQuota runs the opt-in engine.gcp.quota_check in the given mode.
+// In "warn" mode a shortfall (or a failed lookup) is logged; in "error"
+// mode it is returned.
+func (c *Config) checkQuota(ctx context.Context, mode string, qc quotaChecker, logger *slog.Logger) error {
+	if mode == "" {
+		return nil
+	}
+	err := qc.CheckQuotaHeadroom(ctx, c.ScaleSet.MaxRunners)
+	if err == nil {
+		return nil
+	}
+	if mode == "error" {
+		return fmt.Errorf("engine.gcp.quota_check: %w", err)
+	}
+	logger.Warn("gcp quota headroom check failed", slog.String("error", err.Error()))
+	return nil
+}
+
 // BuildLabels returns scaleset.Label values from the configured labels.
 // If no labels are configured, the scale set name is used as the label.
 func (c *Config) BuildLabels() []scaleset.Label {
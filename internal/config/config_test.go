@@ -1,11 +1,27 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/container"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+
+	"github.com/terrpan/scaleset/internal/egress"
+	"github.com/terrpan/scaleset/internal/engine/docker"
 )
 
 // ---------------------------------------------------------------------------
@@ -113,6 +129,135 @@ func (s *ConfigValidationSuite) TestValidate_InvalidURL() {
 	assert.Contains(s.T(), err.Error(), "github.url")
 }
 
+func (s *ConfigValidationSuite) TestValidate_InvalidAPIURL() {
+	cfg := validDockerConfig()
+	cfg.GitHub.APIURL = "not-a-url"
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "github.api_url")
+}
+
+func (s *ConfigValidationSuite) TestValidate_APIURLBadScheme() {
+	cfg := validDockerConfig()
+	cfg.GitHub.APIURL = "ftp://api.example.com"
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "scheme")
+}
+
+func (s *ConfigValidationSuite) TestResolveAPIURL() {
+	tests := []struct {
+		name   string
+		url    string
+		apiURL string
+		expect string
+	}{
+		{"github.com derived", "https://github.com/org/repo", "", "https://api.github.com"},
+		{"www.github.com derived", "https://www.github.com/org", "", "https://api.github.com"},
+		{"ghe.com derived", "https://acme.ghe.com/org", "", "https://api.acme.ghe.com"},
+		{"ghes derived", "https://ghes.example.com/org/repo", "", "https://ghes.example.com/api/v3"},
+		{"override wins", "https://ghes.example.com/org", "https://api.internal.example.com/", "https://api.internal.example.com"},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			g := GitHubConfig{URL: tc.url, APIURL: tc.apiURL}
+			assert.Equal(s.T(), tc.expect, g.ResolveAPIURL())
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestIsEnterprise() {
+	tests := []struct {
+		url    string
+		expect bool
+	}{
+		{"https://github.com/org/repo", false},
+		{"https://WWW.GitHub.com/org", false},
+		{"https://acme.ghe.com/org", false},
+		{"https://ghes.example.com/org/repo", true},
+		{"http://10.0.0.5:8080/enterprises/acme", true},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.url, func() {
+			g := GitHubConfig{URL: tc.url}
+			assert.Equal(s.T(), tc.expect, g.IsEnterprise())
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_EnterpriseRequiresGHESURL() {
+	cfg := validDockerConfig()
+	cfg.GitHub.Enterprise = true
+	err := cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "github.enterprise")
+
+	cfg.GitHub.URL = "https://ghes.example.com/my-org"
+	require.NoError(s.T(), cfg.Validate())
+}
+
+func (s *ConfigValidationSuite) TestValidate_Network() {
+	missing := filepath.Join(s.T().TempDir(), "missing.pem")
+	tests := []struct {
+		name    string
+		network NetworkConfig
+		errMsg  string
+	}{
+		{name: "empty uses the environment"},
+		{name: "http proxy", network: NetworkConfig{ProxyURL: "http://proxy.corp:3128", NoProxy: ".internal"}},
+		{name: "socks5 proxy", network: NetworkConfig{ProxyURL: "socks5://proxy.corp:1080"}},
+		{name: "bad scheme", network: NetworkConfig{ProxyURL: "ftp://proxy.corp"}, errMsg: "network.proxy_url: scheme"},
+		{name: "no host", network: NetworkConfig{ProxyURL: "proxy.corp:3128"}, errMsg: "network.proxy_url"},
+		{name: "no_proxy without proxy_url", network: NetworkConfig{NoProxy: ".internal"}, errMsg: "network.no_proxy requires network.proxy_url"},
+		{name: "missing CA bundle", network: NetworkConfig{CABundlePath: missing}, errMsg: "network.ca_bundle_path"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Network = tt.network
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestNetwork_PassedToEngines() {
+	cfg := validDockerConfig()
+	cfg.Network = NetworkConfig{ProxyURL: "http://proxy.corp:3128", NoProxy: ".internal"}
+	require.NoError(s.T(), cfg.Validate())
+
+	want := egress.Config{ProxyURL: "http://proxy.corp:3128", NoProxy: ".internal"}
+	assert.Equal(s.T(), want, cfg.dockerConfig(&cfg.Engine).Egress)
+
+	assert.Equal(s.T(), want, cfg.gcpConfig(&cfg.Engine).Egress)
+
+	_, err := cfg.NewScalesetClient()
+	assert.NoError(s.T(), err)
+}
+
+func (s *ConfigValidationSuite) TestNewScalesetClient_APIURLMatchingDerived() {
+	cfg := validDockerConfig()
+	cfg.GitHub.APIURL = "https://api.github.com"
+	_, err := cfg.NewScalesetClient()
+	require.NoError(s.T(), err)
+}
+
+func (s *ConfigValidationSuite) TestNewScalesetClient_APIURLConflictsWithDerived() {
+	cfg := validDockerConfig()
+	cfg.GitHub.APIURL = "https://proxy.example.com/github-api"
+	_, err := cfg.NewScalesetClient()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "github.api_url")
+}
+
 // ---------------------------------------------------------------------------
 // Auth validation
 // ---------------------------------------------------------------------------
@@ -183,6 +328,14 @@ func (s *ConfigValidationSuite) TestValidate_MaxLessThanMin() {
 	assert.Contains(s.T(), err.Error(), "max_runners")
 }
 
+func (s *ConfigValidationSuite) TestValidate_NegativeMaxConcurrentDestroys() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.MaxConcurrentDestroys = -1
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "max_concurrent_destroys")
+}
+
 func (s *ConfigValidationSuite) TestValidate_EmptyLabel() {
 	cfg := validDockerConfig()
 	cfg.ScaleSet.Labels = []string{"good", "  ", "also-good"}
@@ -191,6 +344,116 @@ func (s *ConfigValidationSuite) TestValidate_EmptyLabel() {
 	assert.Contains(s.T(), err.Error(), "labels")
 }
 
+func (s *ConfigValidationSuite) TestValidate_NegativeAdmissionCheckTimeout() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.AdmissionCheckTimeout = -time.Second
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "admission_check_timeout")
+}
+
+func (s *ConfigValidationSuite) TestValidate_NegativeReconcileInterval() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.ReconcileInterval = -time.Minute
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "reconcile_interval")
+}
+
+func (s *ConfigValidationSuite) TestValidate_RepoRunnerLimits() {
+	cases := []struct {
+		name      string
+		perRepo   int
+		overrides map[string]int
+		wantErr   string
+	}{
+		{name: "unset"},
+		{name: "default and overrides", perRepo: 2, overrides: map[string]int{"monorepo": 5, "trusted": 0}},
+		{name: "negative default", perRepo: -1, wantErr: "max_runners_per_repo"},
+		{name: "negative override", overrides: map[string]int{"monorepo": -1}, wantErr: "repo_runner_limits[monorepo]"},
+		{name: "empty repository", overrides: map[string]int{" ": 1}, wantErr: "repository name must not be empty"},
+	}
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			cfg := validDockerConfig()
+			cfg.ScaleSet.MaxRunnersPerRepo = tc.perRepo
+			cfg.ScaleSet.RepoRunnerLimits = tc.overrides
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			assert.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_SessionOwner() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.SessionOwner = "ci-runners-listener-0"
+	assert.NoError(s.T(), cfg.Validate())
+
+	cfg.ScaleSet.SessionOwner = "   "
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "session_owner")
+}
+
+func (s *ConfigValidationSuite) TestValidate_NameSuffix() {
+	for _, v := range []string{"", "random", "git-sha", "env:PR_NUMBER"} {
+		cfg := validDockerConfig()
+		cfg.ScaleSet.NameSuffix = v
+		assert.NoError(s.T(), cfg.Validate(), v)
+	}
+	for _, v := range []string{"env:", "sha", "pr-123"} {
+		cfg := validDockerConfig()
+		cfg.ScaleSet.NameSuffix = v
+		err := cfg.Validate()
+		require.Error(s.T(), err, v)
+		assert.Contains(s.T(), err.Error(), "scaleset.name_suffix")
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_LabelLimits() {
+	many := make([]string, maxScaleSetLabels+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("label-%d", i)
+	}
+	long := strings.Repeat("x", maxScaleSetLabelLength+1)
+
+	tests := []struct {
+		name   string
+		labels []string
+		setup  func(*Config)
+		errMsg string
+	}{
+		{name: "max labels", labels: many[:maxScaleSetLabels]},
+		{name: "max length", labels: []string{strings.Repeat("y", maxScaleSetLabelLength)}},
+		{name: "too many labels", labels: many, errMsg: "101 labels exceeds GitHub's limit of 100"},
+		{name: "over-length label", labels: []string{"ok", long}, errMsg: "scaleset.labels[1]: 257 characters"},
+		{name: "over-length name as default label", setup: func(c *Config) { c.ScaleSet.Name = long }, errMsg: "257 characters"},
+		{name: "duplicate label", labels: []string{"linux", "x64", "Linux"}, errMsg: `scaleset.labels[2]: "Linux" duplicates scaleset.labels[0]`},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.ScaleSet.Labels = tt.labels
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Engine validation
 // ---------------------------------------------------------------------------
@@ -216,116 +479,2083 @@ func (s *ConfigValidationSuite) TestValidate_MultipleEnginesEnabled() {
 	assert.Contains(s.T(), err.Error(), "only one engine")
 }
 
-func (s *ConfigValidationSuite) TestValidate_GCP_MissingProject() {
-	cfg := validGCPConfig()
-	cfg.Engine.GCP.Project = ""
+func (s *ConfigValidationSuite) TestValidate_Docker_DindCleanupRequiresDind() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.DindCleanup = true
 	err := cfg.Validate()
 	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "project")
+	assert.Contains(s.T(), err.Error(), "dind_cleanup")
+
+	cfg.Engine.Docker.Dind = true
+	require.NoError(s.T(), cfg.Validate())
 }
 
-func (s *ConfigValidationSuite) TestValidate_GCP_MissingZone() {
-	cfg := validGCPConfig()
-	cfg.Engine.GCP.Zone = ""
+func (s *ConfigValidationSuite) TestValidate_Docker_NegativeStopTimeout() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.StopTimeout = -time.Second
 	err := cfg.Validate()
 	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "zone")
+	assert.Contains(s.T(), err.Error(), "stop_timeout")
 }
 
-func (s *ConfigValidationSuite) TestValidate_GCP_MissingImage() {
-	cfg := validGCPConfig()
-	cfg.Engine.GCP.Image = ""
+func (s *ConfigValidationSuite) TestValidate_NegativeSlowMessageThreshold() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.SlowMessageThreshold = -time.Second
 	err := cfg.Validate()
 	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "image")
+	assert.Contains(s.T(), err.Error(), "slow_message_threshold")
 }
 
-func (s *ConfigValidationSuite) TestValidate_AWSNotImplemented() {
+func (s *ConfigValidationSuite) TestValidate_NegativeStartRate() {
 	cfg := validDockerConfig()
-	cfg.Engine.Docker.Enable = false
-	cfg.Engine.AWS = AWSEngineConfig{Enable: true}
+	cfg.ScaleSet.StartRate = -1
 	err := cfg.Validate()
 	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "not yet implemented")
+	assert.Contains(s.T(), err.Error(), "start_rate")
 }
 
-func (s *ConfigValidationSuite) TestValidate_AzureNotImplemented() {
+func (s *ConfigValidationSuite) TestValidate_NegativeStartDeadline() {
 	cfg := validDockerConfig()
-	cfg.Engine.Docker.Enable = false
-	cfg.Engine.Azure = AzureEngineConfig{Enable: true}
+	cfg.ScaleSet.StartDeadline = -time.Second
 	err := cfg.Validate()
 	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "not yet implemented")
+	assert.Contains(s.T(), err.Error(), "start_deadline")
 }
 
-// ---------------------------------------------------------------------------
-// Defaults
-// ---------------------------------------------------------------------------
+func (s *ConfigValidationSuite) TestValidate_NegativeStartupTimeout() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.StartupTimeout = -time.Minute
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "startup_timeout")
+}
 
-func (s *ConfigValidationSuite) TestApplyDefaults_SetsExpectedValues() {
-	cfg := &Config{}
-	cfg.ApplyDefaults()
+func (s *ConfigValidationSuite) TestValidate_NegativeMaxRunnerLifetime() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.MaxRunnerLifetime = -time.Hour
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "max_runner_lifetime")
+}
 
-	assert.Equal(s.T(), 10, cfg.ScaleSet.MaxRunners)
-	assert.Equal(s.T(), "ghcr.io/actions/actions-runner:latest", cfg.Engine.Docker.Image)
-	assert.Equal(s.T(), "e2-medium", cfg.Engine.GCP.MachineType)
-	assert.Equal(s.T(), int64(50), cfg.Engine.GCP.DiskSizeGB)
-	assert.NotNil(s.T(), cfg.Engine.GCP.PublicIP)
-	assert.True(s.T(), *cfg.Engine.GCP.PublicIP)
-	assert.Equal(s.T(), "info", cfg.Logging.Level)
-	assert.Equal(s.T(), "text", cfg.Logging.Format)
-	assert.Equal(s.T(), 9090, cfg.Prometheus.Port)
+func (s *ConfigValidationSuite) TestValidate_NegativeBusyCheckInterval() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.BusyCheckInterval = -time.Minute
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "busy_check_interval")
 }
 
-// ---------------------------------------------------------------------------
-// EnabledEngine helper
-// ---------------------------------------------------------------------------
+func (s *ConfigValidationSuite) TestValidate_NegativeCapacityProbeInterval() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.CapacityProbeInterval = -time.Second
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "capacity_probe_interval")
+}
 
-func (s *ConfigValidationSuite) TestEnabledEngine() {
+func (s *ConfigValidationSuite) TestValidate_NegativeMaxProcessLifetime() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.MaxProcessLifetime = -time.Hour
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "max_process_lifetime")
+}
+
+func (s *ConfigValidationSuite) TestValidate_NegativeCreateRetries() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.CreateRetries = -1
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "create_retries")
+}
+
+func (s *ConfigValidationSuite) TestValidate_NegativeShutdownRetries() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.ShutdownRetries = -1
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "shutdown_retries")
+
+	cfg = validDockerConfig()
+	cfg.ScaleSet.ShutdownRetryDelay = -time.Second
+	err = cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "shutdown_retry_delay")
+}
+
+func (s *ConfigValidationSuite) TestValidate_NegativeStartParallelism() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.StartParallelism = -1
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "start_parallelism")
+}
+
+func (s *ConfigValidationSuite) TestValidate_NegativeStartRetries() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.StartRetries = -1
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "start_retries")
+
+	cfg = validDockerConfig()
+	cfg.ScaleSet.StartRetryMaxDelay = -time.Second
+	err = cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "start_retry_max_delay")
+}
+
+func (s *ConfigValidationSuite) TestValidate_StartupDurationBuckets() {
+	tests := []struct {
+		name    string
+		buckets []float64
+		wantErr bool
+	}{
+		{"increasing", []float64{0.5, 1, 5}, false},
+		{"decreasing", []float64{5, 1}, true},
+		{"duplicate", []float64{1, 1}, true},
+		{"negative", []float64{-1, 1}, true},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.ScaleSet.StartupDurationBuckets = tt.buckets
+			err := cfg.Validate()
+			if !tt.wantErr {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), "startup_duration_buckets")
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestApplyDefaults_StartupDurationBucketsPerEngine() {
+	docker := validDockerConfig()
+	docker.ApplyDefaults()
+	gcp := validGCPConfig()
+	gcp.ApplyDefaults()
+
+	assert.Less(s.T(), docker.ScaleSet.StartupDurationBuckets[0], 1.0, "docker buckets resolve sub-second starts")
+	assert.GreaterOrEqual(s.T(), gcp.ScaleSet.StartupDurationBuckets[len(gcp.ScaleSet.StartupDurationBuckets)-1], 600.0, "gcp buckets cover slow VM boots")
+
+	custom := validGCPConfig()
+	custom.ScaleSet.StartupDurationBuckets = []float64{1, 2}
+	custom.ApplyDefaults()
+	assert.Equal(s.T(), []float64{1, 2}, custom.ScaleSet.StartupDurationBuckets)
+}
+
+func (s *ConfigValidationSuite) TestValidate_Docker_NegativeStartRetries() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.StartRetries = -1
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "start_retries")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Docker_Preload() {
 	tests := []struct {
 		name   string
-		cfg    EngineConfig
-		expect string
+		modify func(*DockerEngineConfig)
+		errMsg string
 	}{
-		{"docker", EngineConfig{Docker: DockerEngineConfig{Enable: true}}, "docker"},
-		{"gcp", EngineConfig{GCP: GCPEngineConfig{Enable: true}}, "gcp"},
-		{"aws", EngineConfig{AWS: AWSEngineConfig{Enable: true}}, "aws"},
-		{"azure", EngineConfig{Azure: AzureEngineConfig{Enable: true}}, "azure"},
-		{"none", EngineConfig{}, ""},
+		{"negative concurrency", func(d *DockerEngineConfig) { d.PreloadConcurrency = -1 }, "preload_concurrency"},
+		{"negative timeout", func(d *DockerEngineConfig) { d.PreloadTimeout = -time.Second }, "preload_timeout"},
+		{"missing image", func(d *DockerEngineConfig) {
+			d.PreloadImages = []DockerPreloadImage{{Image: "node:20"}, {Required: true}}
+		}, "engine.docker.preload_images[1].image is required"},
+		{"duplicate image", func(d *DockerEngineConfig) {
+			d.PreloadImages = []DockerPreloadImage{{Image: "node:20"}, {Image: "node:20"}}
+		}, "engine.docker.preload_images[1]"},
+		{"runner image", func(d *DockerEngineConfig) {
+			d.Image = "ghcr.io/actions/actions-runner:latest"
+			d.PreloadImages = []DockerPreloadImage{{Image: d.Image}}
+		}, "already pulled"},
+		{"valid", func(d *DockerEngineConfig) {
+			d.PreloadImages = []DockerPreloadImage{{Image: "node:20", Required: true}, {Image: "postgres:16"}}
+			d.PreloadConcurrency = 2
+			d.PreloadTimeout = 5 * time.Minute
+		}, ""},
 	}
 
-	for _, tc := range tests {
-		s.Run(tc.name, func() {
-			assert.Equal(s.T(), tc.expect, tc.cfg.EnabledEngine())
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			tt.modify(&cfg.Engine.Docker)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
 		})
 	}
 }
 
-// ---------------------------------------------------------------------------
-// BuildLabels
-// ---------------------------------------------------------------------------
+func (s *ConfigValidationSuite) TestLoad_DockerPreloadImages() {
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+engine:
+  docker:
+    enable: true
+    preload_concurrency: 2
+    preload_timeout: 5m
+    preload_images:
+      - image: node:20
+        required: true
+      - image: postgres:16
+`), 0o600))
 
-func (s *ConfigValidationSuite) TestBuildLabels_WithLabels() {
-	cfg := validDockerConfig()
-	cfg.ScaleSet.Labels = []string{"linux", "x64", "gpu"}
-	labels := cfg.BuildLabels()
-	assert.Len(s.T(), labels, 3)
-	assert.Equal(s.T(), "linux", labels[0].Name)
-	assert.Equal(s.T(), "x64", labels[1].Name)
-	assert.Equal(s.T(), "gpu", labels[2].Name)
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	d := cfg.Engine.Docker
+	assert.Equal(s.T(), 2, d.PreloadConcurrency)
+	assert.Equal(s.T(), 5*time.Minute, d.PreloadTimeout)
+	assert.Equal(s.T(), []DockerPreloadImage{
+		{Image: "node:20", Required: true},
+		{Image: "postgres:16"},
+	}, d.PreloadImages)
 }
 
-func (s *ConfigValidationSuite) TestBuildLabels_FallsBackToName() {
+func (s *ConfigValidationSuite) TestValidate_Docker_Limits() {
+	tests := []struct {
+		name   string
+		modify func(*DockerEngineConfig)
+		errMsg string
+	}{
+		{"negative cpus", func(d *DockerEngineConfig) { d.CPUs = -1 }, "engine.docker.cpus must be >= 0"},
+		{"invalid memory", func(d *DockerEngineConfig) { d.Memory = "lots" }, "engine.docker.memory: invalid size"},
+		{"negative pids limit", func(d *DockerEngineConfig) { d.PidsLimit = -1 }, "engine.docker.pids_limit must be >= 0"},
+		{"invalid shm size", func(d *DockerEngineConfig) { d.ShmSize = "1x" }, "engine.docker.shm_size: invalid size"},
+		{"valid", func(d *DockerEngineConfig) {
+			d.CPUs = 1.5
+			d.Memory = "4g"
+			d.PidsLimit = 4096
+			d.ShmSize = "1g"
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			tt.modify(&cfg.Engine.Docker)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_Docker_NetworkAndMounts() {
+	tests := []struct {
+		name   string
+		modify func(*DockerEngineConfig)
+		errMsg string
+	}{
+		{"invalid extra host", func(d *DockerEngineConfig) { d.ExtraHosts = []string{"cache"} }, "engine.docker.extra_hosts[0]: extra host \"cache\": want name:ip"},
+		{"invalid mount", func(d *DockerEngineConfig) { d.Mounts = []string{"/ok:/ok", "/cache"} }, "engine.docker.mounts[1]: mount \"/cache\""},
+		{"network with sidecar", func(d *DockerEngineConfig) {
+			d.Network = "ci"
+			d.Dind = true
+			d.DindMode = "sidecar"
+		}, "engine.docker.network: not allowed with dind_mode \"sidecar\""},
+		{"valid", func(d *DockerEngineConfig) {
+			d.Network = "ci"
+			d.ExtraHosts = []string{"cache.internal:10.0.0.5"}
+			d.Mounts = []string{"gomodcache:/home/runner/go/pkg/mod"}
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			tt.modify(&cfg.Engine.Docker)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				dc := cfg.dockerConfig(&cfg.Engine)
+				assert.Equal(s.T(), "ci", dc.Network)
+				assert.Equal(s.T(), []string{"cache.internal:10.0.0.5"}, dc.ExtraHosts)
+				assert.Equal(s.T(), []string{"gomodcache:/home/runner/go/pkg/mod"}, dc.Mounts)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestDockerConfig_GPUFlavor() {
 	cfg := validDockerConfig()
-	cfg.ScaleSet.Labels = nil
-	labels := cfg.BuildLabels()
-	assert.Len(s.T(), labels, 1)
-	assert.Equal(s.T(), "test-scaleset", labels[0].Name)
+	cfg.ScaleSet.Flavors = []Flavor{
+		{Name: "cpu"},
+		{Name: "gpu", Docker: DockerProfile{GPUs: "all"}},
+	}
+	require.NoError(s.T(), cfg.Validate())
+
+	cfgs := cfg.ScaleSetConfigs()
+	require.Len(s.T(), cfgs, 2)
+	for i, want := range []*container.DeviceRequest{nil, {Count: -1, Capabilities: [][]string{{"gpu"}}}} {
+		profile, err := cfgs[i].selectProfile()
+		require.NoError(s.T(), err)
+		ec := profile.apply(cfgs[i].Engine)
+		assert.Equal(s.T(), want, cfgs[i].dockerConfig(&ec).GPUs)
+	}
+
+	cfg = validDockerConfig()
+	cfg.Engine.Docker.GPUs = "0,"
+	err := cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), `engine.docker.gpus: invalid gpus "0,"`)
 }
 
-func (s *ConfigValidationSuite) TestBuildLabels_TrimsWhitespace() {
+func (s *ConfigValidationSuite) TestDockerConfig_Limits() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.CPUs = 2
+	cfg.Engine.Docker.Memory = "512m"
+	cfg.Engine.Docker.PidsLimit = 1024
+	cfg.Engine.Docker.ShmSize = "1g"
+	require.NoError(s.T(), cfg.Validate())
+
+	assert.Equal(s.T(), docker.Limits{
+		CPUs:      2,
+		Memory:    512 << 20,
+		PidsLimit: 1024,
+		ShmSize:   1 << 30,
+	}, cfg.dockerConfig(&cfg.Engine).Limits)
+}
+
+func (s *ConfigValidationSuite) TestDockerConfig_DindSidecar() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.Dind = true
+	cfg.Engine.Docker.DindMode = "sidecar"
+	require.NoError(s.T(), cfg.Validate())
+
+	dc := cfg.dockerConfig(&cfg.Engine)
+	assert.Equal(s.T(), docker.DindModeSidecar, dc.DindMode)
+	assert.Equal(s.T(), "docker:dind", dc.DindImage)
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_DiskPerformance() {
+	tests := []struct {
+		name       string
+		diskType   string
+		iops       int64
+		throughput int64
+		errMsg     string
+	}{
+		{name: "defaults", diskType: ""},
+		{name: "hyperdisk-balanced iops and throughput", diskType: "hyperdisk-balanced", iops: 6000, throughput: 290},
+		{name: "pd-extreme iops", diskType: "pd-extreme", iops: 10000},
+		{name: "hyperdisk-throughput throughput", diskType: "hyperdisk-throughput", throughput: 180},
+		{name: "iops on default pd-ssd", iops: 6000, errMsg: `engine.gcp.disk_iops is not supported by disk type "pd-ssd"`},
+		{name: "throughput on pd-extreme", diskType: "pd-extreme", throughput: 200, errMsg: "engine.gcp.disk_throughput is not supported"},
+		{name: "negative iops", diskType: "pd-extreme", iops: -1, errMsg: "disk_iops must be >= 0"},
+		{name: "negative throughput", diskType: "hyperdisk-throughput", throughput: -1, errMsg: "disk_throughput must be >= 0"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validGCPConfig()
+			cfg.Engine.GCP.DiskType = tt.diskType
+			cfg.Engine.GCP.DiskIOPS = tt.iops
+			cfg.Engine.GCP.DiskThroughput = tt.throughput
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_Metadata() {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		errMsg   string
+	}{
+		{name: "valid", metadata: map[string]string{"startup-script": "#!/bin/bash", "user_data": ""}},
+		{name: "reserved key", metadata: map[string]string{"ACTIONS_RUNNER_INPUT_JITCONFIG": "x"}, errMsg: "is set by the engine"},
+		{name: "invalid key", metadata: map[string]string{"user data": "x"}, errMsg: `key "user data" must be`},
+		{name: "value too large", metadata: map[string]string{"user-data": strings.Repeat("x", 256<<10+1)}, errMsg: "GCP allows at most"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validGCPConfig()
+			cfg.Engine.GCP.Metadata = tt.metadata
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), "engine.gcp.metadata")
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestLoad_GCPMetadataFromFile() {
+	dir := s.T().TempDir()
+	userData := filepath.Join(dir, "cloud-init.yaml")
+	require.NoError(s.T(), os.WriteFile(userData, []byte("#cloud-config\npackages: [jq]\n"), 0o600))
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+engine:
+  gcp:
+    enable: true
+    metadata:
+      enable-oslogin: "TRUE"
+    metadata_from_file:
+      user-data: `+userData+`
+`), 0o600))
+
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), map[string]string{
+		"enable-oslogin": "TRUE",
+		"user-data":      "#cloud-config\npackages: [jq]\n",
+	}, cfg.Engine.GCP.Metadata)
+}
+
+func (s *ConfigValidationSuite) TestLoad_GCPStartupScript() {
+	dir := s.T().TempDir()
+	script := filepath.Join(dir, "bootstrap.sh")
+	require.NoError(s.T(), os.WriteFile(script, []byte("#!/bin/bash\napt-get install -y jq\n"), 0o600))
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+engine:
+  gcp:
+    enable: true
+    startup_script_path: `+script+`
+`), 0o600))
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "#!/bin/bash\napt-get install -y jq\n", cfg.Engine.GCP.StartupScript)
+
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+engine:
+  gcp:
+    startup_script: "echo hi"
+    startup_script_path: `+script+`
+`), 0o600))
+	_, err = Load(path)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.gcp: set startup_script or startup_script_path, not both")
+
+	cfg = validGCPConfig()
+	cfg.Engine.GCP.Metadata = map[string]string{"scaleset-startup-script": "echo hi"}
+	err = cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.gcp.metadata: scaleset-startup-script is set by the engine")
+}
+
+func (s *ConfigValidationSuite) TestLoad_GCPMetadataFromFileErrors() {
+	dir := s.T().TempDir()
+	script := filepath.Join(dir, "startup.sh")
+	require.NoError(s.T(), os.WriteFile(script, []byte("#!/bin/bash\n"), 0o600))
+
+	tests := []struct {
+		name   string
+		yaml   string
+		errMsg string
+	}{
+		{
+			name: "missing file",
+			yaml: `
+engine:
+  gcp:
+    metadata_from_file:
+      user-data: ` + filepath.Join(dir, "missing.yaml"),
+			errMsg: `engine.gcp.metadata_from_file: reading "user-data"`,
+		},
+		{
+			name: "key also inline",
+			yaml: `
+engine:
+  gcp:
+    metadata:
+      startup-script: echo hi
+    metadata_from_file:
+      startup-script: ` + script,
+			errMsg: `key "startup-script" is also set in engine.gcp.metadata`,
+		},
+		{
+			name: "fallback engine",
+			yaml: `
+engine:
+  docker:
+    enable: true
+  fallback:
+    engines:
+      - gcp:
+          metadata_from_file:
+            user-data: ` + filepath.Join(dir, "missing.yaml"),
+			errMsg: "engine.fallback.engines[0].gcp.metadata_from_file",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			path := filepath.Join(dir, "config.yaml")
+			require.NoError(s.T(), os.WriteFile(path, []byte(tt.yaml), 0o600))
+			_, err := Load(path)
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestSelectProfile() {
+	profiles := []EngineProfile{
+		{Name: "gpu", Labels: []string{"gpu"}},
+		{Name: "large", Labels: []string{"large", "linux"}},
+	}
+	tests := []struct {
+		name   string
+		labels []string
+		want   string
+		errMsg string
+	}{
+		{name: "no labels matches nothing", want: ""},
+		{name: "single label", labels: []string{"ci", "gpu"}, want: "gpu"},
+		{name: "case-insensitive", labels: []string{"GPU"}, want: "gpu"},
+		{name: "all labels required", labels: []string{"large"}, want: ""},
+		{name: "all labels present", labels: []string{"linux", "large"}, want: "large"},
+		{name: "ambiguous", labels: []string{"gpu", "large", "linux"}, errMsg: `match both "gpu" and "large"`},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validGCPConfig()
+			cfg.ScaleSet.Labels = tt.labels
+			cfg.Engine.Profiles = profiles
+			p, err := cfg.selectProfile()
+			if tt.errMsg != "" {
+				require.Error(s.T(), err)
+				assert.Contains(s.T(), err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(s.T(), err)
+			if tt.want == "" {
+				assert.Nil(s.T(), p)
+				return
+			}
+			require.NotNil(s.T(), p)
+			assert.Equal(s.T(), tt.want, p.Name)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestProfileApply() {
+	cfg := validGCPConfig()
+	cfg.ApplyDefaults()
+	p := EngineProfile{
+		Name:   "gpu",
+		Labels: []string{"gpu"},
+		GCP: GCPProfile{
+			MachineType:  "n1-standard-8",
+			DiskSizeGB:   200,
+			Accelerators: []GCPAccelerator{{Type: "nvidia-tesla-t4", Count: 1}},
+		},
+	}
+
+	ec := p.apply(cfg.Engine)
+	assert.Equal(s.T(), "n1-standard-8", ec.GCP.MachineType)
+	assert.Equal(s.T(), int64(200), ec.GCP.DiskSizeGB)
+	assert.Equal(s.T(), []GCPAccelerator{{Type: "nvidia-tesla-t4", Count: 1}}, ec.GCP.Accelerators)
+	assert.Equal(s.T(), "pd-ssd", ec.GCP.DiskType, "unset fields keep the engine's value")
+	assert.Equal(s.T(), cfg.Engine.GCP.Image, ec.GCP.Image)
+	assert.Equal(s.T(), "e2-medium", cfg.Engine.GCP.MachineType, "the engine config is not modified")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Profiles() {
+	gpu := EngineProfile{
+		Name:   "gpu",
+		Labels: []string{"gpu"},
+		GCP:    GCPProfile{Accelerators: []GCPAccelerator{{Type: "nvidia-tesla-t4", Count: 1}}},
+	}
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		errMsg string
+	}{
+		{name: "valid", modify: func(c *Config) {
+			c.ScaleSet.Labels = []string{"gpu"}
+			c.Engine.Profiles = []EngineProfile{gpu}
+		}},
+		{name: "missing name", modify: func(c *Config) {
+			c.Engine.Profiles = []EngineProfile{{Labels: []string{"gpu"}}}
+		}, errMsg: "engine.profiles[0].name is required"},
+		{name: "duplicate name", modify: func(c *Config) {
+			c.Engine.Profiles = []EngineProfile{gpu, gpu}
+		}, errMsg: `duplicate profile "gpu"`},
+		{name: "no labels", modify: func(c *Config) {
+			c.Engine.Profiles = []EngineProfile{{Name: "gpu"}}
+		}, errMsg: "must list at least one label"},
+		{name: "zero accelerators", modify: func(c *Config) {
+			c.Engine.Profiles = []EngineProfile{{Name: "gpu", Labels: []string{"gpu"},
+				GCP: GCPProfile{Accelerators: []GCPAccelerator{{Type: "nvidia-tesla-t4"}}}}}
+		}, errMsg: "engine.profiles[0].gcp.accelerators[0].count must be >= 1"},
+		{name: "invalid once applied", modify: func(c *Config) {
+			c.ScaleSet.Labels = []string{"gpu"}
+			c.Engine.Profiles = []EngineProfile{{Name: "gpu", Labels: []string{"gpu"},
+				GCP: GCPProfile{DiskIOPS: 5000}}}
+		}, errMsg: `engine profile "gpu": engine.gcp.disk_iops is not supported by disk type "pd-ssd"`},
+		{name: "ambiguous", modify: func(c *Config) {
+			c.ScaleSet.Labels = []string{"gpu", "large"}
+			c.Engine.Profiles = []EngineProfile{gpu, {Name: "large", Labels: []string{"large"}}}
+		}, errMsg: "match both"},
+		{name: "profiles on fallback", modify: func(c *Config) {
+			c.Engine.Fallback = &EngineFallbackConfig{Engines: []EngineConfig{{
+				Docker:   DockerEngineConfig{Enable: true},
+				Profiles: []EngineProfile{gpu},
+			}}}
+		}, errMsg: "engine.fallback.engines[0].profiles: only the primary engine can have profiles"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validGCPConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestLoad_EngineProfiles() {
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+scaleset:
+  labels: [gpu]
+engine:
+  gcp:
+    enable: true
+  profiles:
+    - name: gpu
+      labels: [gpu]
+      gcp:
+        machine_type: n1-standard-8
+        accelerators:
+          - type: nvidia-tesla-t4
+            count: 2
+`), 0o600))
+
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	p, err := cfg.selectProfile()
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), p)
+	assert.Equal(s.T(), "n1-standard-8", p.GCP.MachineType)
+	assert.Equal(s.T(), []GCPAccelerator{{Type: "nvidia-tesla-t4", Count: 2}}, p.GCP.Accelerators)
+}
+
+func (s *ConfigValidationSuite) TestValidate_Kubernetes() {
+	tests := []struct {
+		name   string
+		modify func(*KubernetesEngineConfig)
+		errMsg string
+	}{
+		{name: "minimal", modify: func(*KubernetesEngineConfig) {}},
+		{name: "full template", modify: func(k *KubernetesEngineConfig) {
+			k.Namespace = "ci"
+			k.Resources = KubernetesResources{Requests: map[string]string{"cpu": "2"}, Limits: map[string]string{"memory": "4Gi"}}
+			k.NodeSelector = map[string]string{"pool": "ci"}
+			k.Tolerations = []KubernetesToleration{
+				{Key: "ci", Value: "true", Effect: "NoSchedule"},
+				{Operator: "Exists"},
+			}
+			k.Env = map[string]string{"FOO": "bar"}
+		}},
+		{name: "equal without key", modify: func(k *KubernetesEngineConfig) {
+			k.Tolerations = []KubernetesToleration{{Operator: "Equal", Value: "true"}}
+		}, errMsg: "tolerations[0]: key is required"},
+		{name: "exists with value", modify: func(k *KubernetesEngineConfig) {
+			k.Tolerations = []KubernetesToleration{{Key: "ci", Operator: "Exists", Value: "true"}}
+		}, errMsg: "value must be empty"},
+		{name: "bad operator", modify: func(k *KubernetesEngineConfig) {
+			k.Tolerations = []KubernetesToleration{{Key: "ci", Operator: "In"}}
+		}, errMsg: "tolerations[0].operator"},
+		{name: "bad effect", modify: func(k *KubernetesEngineConfig) {
+			k.Tolerations = []KubernetesToleration{{Key: "ci", Effect: "NoRun"}}
+		}, errMsg: "tolerations[0].effect"},
+		{name: "reserved env", modify: func(k *KubernetesEngineConfig) {
+			k.Env = map[string]string{"ACTIONS_RUNNER_INPUT_JITCONFIG": "x"}
+		}, errMsg: "is set by scaleset"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Engine.Docker.Enable = false
+			cfg.Engine.Kubernetes.Enable = true
+			tt.modify(&cfg.Engine.Kubernetes)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_HCloud() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.Enable = false
+	cfg.Engine.HCloud.Enable = true
+
+	s.T().Setenv("HCLOUD_TOKEN", "")
+	err := cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.hcloud.token is required")
+
+	s.T().Setenv("HCLOUD_TOKEN", "from-env")
+	require.NoError(s.T(), cfg.Validate())
+
+	cfg.Engine.HCloud.Location = "hel1"
+	region, zone := cfg.Engine.Location()
+	assert.Equal(s.T(), "hel1", region)
+	assert.Empty(s.T(), zone)
+	require.NotNil(s.T(), cfg.Engine.HCloud.PublicIPv4)
+	assert.True(s.T(), *cfg.Engine.HCloud.PublicIPv4)
+}
+
+func (s *ConfigValidationSuite) TestValidate_Podman() {
+	tests := []struct {
+		name   string
+		modify func(*PodmanEngineConfig)
+		errMsg string
+	}{
+		{name: "minimal", modify: func(*PodmanEngineConfig) {}},
+		{name: "rootless with limits", modify: func(p *PodmanEngineConfig) {
+			p.Socket = "unix:///run/user/1000/podman/podman.sock"
+			p.UserNS = "keep-id:uid=1001"
+			p.CPUs = 2
+			p.Memory = "4g"
+			p.PidsLimit = 2048
+		}},
+		{name: "unknown userns", modify: func(p *PodmanEngineConfig) {
+			p.UserNS = "keepid"
+		}, errMsg: "podman.userns: unknown mode"},
+		{name: "bad memory", modify: func(p *PodmanEngineConfig) {
+			p.Memory = "lots"
+		}, errMsg: "podman.memory"},
+		{name: "reserved env", modify: func(p *PodmanEngineConfig) {
+			p.Env = map[string]string{"ACTIONS_RUNNER_INPUT_JITCONFIG": "x"}
+		}, errMsg: "is set by scaleset"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Engine.Docker.Enable = false
+			cfg.Engine.Podman.Enable = true
+			tt.modify(&cfg.Engine.Podman)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_Plugin() {
+	tests := []struct {
+		name   string
+		modify func(*PluginEngineConfig)
+		errMsg string
+	}{
+		{name: "full", modify: func(p *PluginEngineConfig) {
+			p.Args = []string{"--verbose"}
+			p.Env = []string{"AWS_PROFILE"}
+			p.Config = map[string]any{"region": "eu", "pool": map[string]any{"size": 4}}
+		}},
+		{name: "no path", modify: func(p *PluginEngineConfig) {
+			p.Path = ""
+		}, errMsg: "engine.plugin.path is required"},
+		{name: "negative start timeout", modify: func(p *PluginEngineConfig) {
+			p.StartTimeout = -time.Second
+		}, errMsg: "plugin.start_timeout"},
+		{name: "env with value", modify: func(p *PluginEngineConfig) {
+			p.Env = []string{"AWS_PROFILE=prod"}
+		}, errMsg: "engine.plugin.env"},
+		{name: "config not JSON", modify: func(p *PluginEngineConfig) {
+			p.Config = map[string]any{"when": time.Now()}
+		}, errMsg: "engine.plugin.config"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Engine.Docker.Enable = false
+			cfg.Engine.Plugin = PluginEngineConfig{Enable: true, Path: "/usr/local/bin/scaleset-engine-example"}
+			tt.modify(&cfg.Engine.Plugin)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_Audit() {
+	cfg := validDockerConfig()
+	cfg.Audit.Path = "/var/log/scaleset/audit.jsonl"
+	assert.NoError(s.T(), cfg.Validate())
+
+	cfg.State.Path = "/var/log/scaleset/./audit.jsonl"
+	assert.EqualError(s.T(), cfg.Validate(), "audit.path: /var/log/scaleset/audit.jsonl is already the state file (state.path)")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Fake() {
+	tests := []struct {
+		name   string
+		modify func(*FakeEngineConfig)
+		errMsg string
+	}{
+		{name: "defaults", modify: func(*FakeEngineConfig) {}},
+		{name: "full", modify: func(f *FakeEngineConfig) {
+			f.StartLatency = 2 * time.Second
+			f.DestroyLatency = time.Second
+			f.Jitter = 500 * time.Millisecond
+			f.StartFailureRate = 0.2
+			f.DestroyFailureRate = 1
+			f.Seed = 42
+		}},
+		{name: "negative latency", modify: func(f *FakeEngineConfig) {
+			f.StartLatency = -time.Second
+		}, errMsg: "engine.fake.start_latency must be >= 0"},
+		{name: "negative jitter", modify: func(f *FakeEngineConfig) {
+			f.Jitter = -time.Second
+		}, errMsg: "engine.fake.jitter must be >= 0"},
+		{name: "failure rate above 1", modify: func(f *FakeEngineConfig) {
+			f.StartFailureRate = 1.5
+		}, errMsg: "engine.fake.start_failure_rate must be between 0 and 1"},
+		{name: "negative failure rate", modify: func(f *FakeEngineConfig) {
+			f.DestroyFailureRate = -0.1
+		}, errMsg: "engine.fake.destroy_failure_rate must be between 0 and 1"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Engine.Docker.Enable = false
+			cfg.Engine.Fake = FakeEngineConfig{Enable: true}
+			tt.modify(&cfg.Engine.Fake)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_Docker_DindMode() {
+	tests := []struct {
+		name    string
+		dind    bool
+		mode    string
+		cleanup bool
+		errMsg  string
+	}{
+		{name: "socket", dind: true, mode: "socket"},
+		{name: "sidecar", dind: true, mode: "sidecar"},
+		{name: "unknown mode", dind: true, mode: "rootless", errMsg: `dind_mode must be "socket" or "sidecar", got "rootless"`},
+		{name: "sidecar without dind", mode: "sidecar", errMsg: "requires engine.docker.dind"},
+		{name: "sidecar with cleanup", dind: true, mode: "sidecar", cleanup: true, errMsg: "dind_cleanup applies to dind_mode"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Engine.Docker.Dind = tt.dind
+			cfg.Engine.Docker.DindMode = tt.mode
+			cfg.Engine.Docker.DindCleanup = tt.cleanup
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_Docker_DindSidecarImageNotPreloadedTwice() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.Dind = true
+	cfg.Engine.Docker.DindMode = "sidecar"
+	cfg.Engine.Docker.PreloadImages = []DockerPreloadImage{{Image: "docker:dind"}}
+	err := cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), `"docker:dind" is already pulled`)
+}
+
+func (s *ConfigValidationSuite) TestValidate_Docker_DindHost() {
+	tests := []struct {
+		name   string
+		host   string
+		dind   bool
+		mode   string
+		errMsg string
+	}{
+		{name: "dind with default host", dind: true},
+		{name: "dind with local socket", host: "unix:///var/run/docker.sock", dind: true},
+		{name: "dind with loopback tcp", host: "tcp://127.0.0.1:2375", dind: true},
+		{name: "remote host without dind", host: "tcp://10.0.0.5:2376"},
+		{name: "dind with remote tcp", host: "tcp://10.0.0.5:2376", dind: true, errMsg: `engine.docker.host is "tcp://10.0.0.5:2376"`},
+		{name: "dind with ssh", host: "ssh://user@build-host", dind: true, errMsg: "requires a local daemon"},
+		{name: "sidecar with remote tcp", host: "tcp://10.0.0.5:2376", dind: true, mode: "sidecar"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Engine.Docker.Host = tt.host
+			cfg.Engine.Docker.Dind = tt.dind
+			cfg.Engine.Docker.DindMode = tt.mode
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(s.T(), err)
+				return
+			}
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_MissingProject() {
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.Project = ""
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "project")
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_MissingZone() {
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.Zone = ""
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "zone")
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_Zones() {
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.Zone = ""
+	cfg.Engine.GCP.Zones = []string{"us-central1-b", "us-central1-c"}
+	require.NoError(s.T(), cfg.Validate())
+	assert.Equal(s.T(), "us-central1-b", cfg.Engine.GCP.Zone, "zone defaults to the first of zones")
+	gc := cfg.gcpConfig(&cfg.Engine)
+	assert.Equal(s.T(), []string{"us-central1-b", "us-central1-c"}, gc.Zones)
+
+	tests := []struct {
+		name    string
+		mutate  func(*GCPEngineConfig)
+		wantErr string
+	}{
+		{"zone not listed", func(g *GCPEngineConfig) { g.Zone = "us-central1-a" }, `engine.gcp.zone "us-central1-a" must be one of zones`},
+		{"other region", func(g *GCPEngineConfig) { g.Zones = append(g.Zones, "europe-west1-b") }, `engine.gcp.zones[2]: "europe-west1-b" is not in region us-central1`},
+		{"duplicate", func(g *GCPEngineConfig) { g.Zones = append(g.Zones, "us-central1-b") }, `engine.gcp.zones[2]: "us-central1-b" is listed twice`},
+		{"zone in runner name", func(g *GCPEngineConfig) { g.ZoneInRunnerName = true }, "engine.gcp.zone_in_runner_name cannot be used with several zones"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validGCPConfig()
+			cfg.Engine.GCP.Zone = ""
+			cfg.Engine.GCP.Zones = []string{"us-central1-b", "us-central1-c"}
+			tt.mutate(&cfg.Engine.GCP)
+			err := cfg.Validate()
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_MissingImage() {
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.Image = ""
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "image")
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_InvalidBulkInsertThreshold() {
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.BulkInsertThreshold = -1
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "bulk_insert_threshold")
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_SpotRequiresReconcile() {
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.Spot = true
+	err := cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.gcp.spot requires scaleset.reconcile_interval")
+
+	cfg.ScaleSet.ReconcileInterval = time.Minute
+	require.NoError(s.T(), cfg.Validate())
+	assert.True(s.T(), cfg.gcpConfig(&cfg.Engine).Spot)
+
+	fallback := validGCPConfig().Engine
+	fallback.GCP.Spot = true
+	cfg = validDockerConfig()
+	cfg.Engine.Fallback = &EngineFallbackConfig{Engines: []EngineConfig{fallback}}
+	err = cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.fallback.engines[0].gcp.spot")
+}
+
+func (s *ConfigValidationSuite) TestValidate_AWSNotImplemented() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.Enable = false
+	cfg.Engine.AWS = AWSEngineConfig{Enable: true}
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "not yet implemented")
+}
+
+func (s *ConfigValidationSuite) TestValidate_AzureNotImplemented() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.Enable = false
+	cfg.Engine.Azure = AzureEngineConfig{Enable: true}
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "not yet implemented")
+}
+
+// ---------------------------------------------------------------------------
+// Engine fallback
+// ---------------------------------------------------------------------------
+
+// withDockerFallback adds a Docker fallback to the GCP config.
+func withDockerFallback(cfg *Config) *Config {
+	cfg.Engine.Fallback = &EngineFallbackConfig{
+		Engines: []EngineConfig{{Docker: DockerEngineConfig{Enable: true}}},
+	}
+	return cfg
+}
+
+func (s *ConfigValidationSuite) TestValidate_Fallback_Valid() {
+	cfg := withDockerFallback(validGCPConfig())
+	require.NoError(s.T(), cfg.Validate())
+	assert.Equal(s.T(), 3, cfg.Engine.Fallback.AfterFailures, "default")
+	assert.Equal(s.T(), "ghcr.io/actions/actions-runner:latest", cfg.Engine.Fallback.Engines[0].Docker.Image,
+		"fallback engines get engine defaults")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Fallback_RequiresEngines() {
+	cfg := validGCPConfig()
+	cfg.Engine.Fallback = &EngineFallbackConfig{}
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.fallback.engines")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Fallback_ValidatesEachEngine() {
+	cfg := validDockerConfig()
+	cfg.Engine.Fallback = &EngineFallbackConfig{
+		Engines: []EngineConfig{{GCP: GCPEngineConfig{Enable: true, Zone: "us-central1-a"}}},
+	}
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.fallback.engines[0].gcp.project")
+
+	cfg.Engine.Fallback.Engines = []EngineConfig{{}}
+	err = cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "engine.fallback.engines[0]: at least one engine")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Fallback_NoNestedFallback() {
+	cfg := withDockerFallback(validGCPConfig())
+	cfg.Engine.Fallback.Engines[0].Fallback = &EngineFallbackConfig{}
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "only the primary engine")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Fallback_NegativeRetryPrimaryAfter() {
+	cfg := withDockerFallback(validGCPConfig())
+	cfg.Engine.Fallback.RetryPrimaryAfter = -time.Minute
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "retry_primary_after")
+}
+
+func (s *ConfigValidationSuite) TestLoad_FallbackChain() {
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+engine:
+  gcp:
+    enable: true
+    machine_type: e2-standard-4
+  fallback:
+    after_failures: 5
+    retry_primary_after: 15m
+    engines:
+      - gcp:
+          enable: true
+          machine_type: e2-standard-8
+      - docker:
+          enable: true
+`), 0o600))
+
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	fb := cfg.Engine.Fallback
+	require.NotNil(s.T(), fb)
+	assert.Equal(s.T(), 5, fb.AfterFailures)
+	assert.Equal(s.T(), 15*time.Minute, fb.RetryPrimaryAfter)
+	require.Len(s.T(), fb.Engines, 2)
+	assert.Equal(s.T(), "e2-standard-8", fb.Engines[0].GCP.MachineType)
+	assert.Equal(s.T(), "docker", fb.Engines[1].EnabledEngine())
+}
+
+// ---------------------------------------------------------------------------
+// Defaults
+// ---------------------------------------------------------------------------
+
+func (s *ConfigValidationSuite) TestApplyDefaults_SetsExpectedValues() {
+	cfg := &Config{}
+	cfg.ApplyDefaults()
+
+	assert.Equal(s.T(), 10, cfg.ScaleSet.MaxRunners)
+	assert.Equal(s.T(), 10, cfg.ScaleSet.MaxConcurrentDestroys)
+	assert.Equal(s.T(), 3, cfg.ScaleSet.CreateRetries)
+	assert.Equal(s.T(), 2*time.Second, cfg.ScaleSet.CreateRetryDelay)
+	assert.Equal(s.T(), 3, cfg.ScaleSet.ShutdownRetries)
+	assert.Equal(s.T(), time.Second, cfg.ScaleSet.ShutdownRetryDelay)
+	assert.Equal(s.T(), 5, cfg.ScaleSet.StartParallelism)
+	assert.Equal(s.T(), time.Second, cfg.ScaleSet.StartRetryDelay)
+	assert.Equal(s.T(), 30*time.Second, cfg.ScaleSet.StartRetryMaxDelay)
+	assert.Equal(s.T(), 5*time.Second, cfg.ScaleSet.AdmissionCheckTimeout)
+	assert.Equal(s.T(), "ghcr.io/actions/actions-runner:latest", cfg.Engine.Docker.Image)
+	assert.Equal(s.T(), "e2-medium", cfg.Engine.GCP.MachineType)
+	assert.Equal(s.T(), int64(50), cfg.Engine.GCP.DiskSizeGB)
+	assert.Equal(s.T(), 5, cfg.Engine.GCP.BulkInsertThreshold)
+	assert.NotNil(s.T(), cfg.Engine.GCP.PublicIP)
+	assert.True(s.T(), *cfg.Engine.GCP.PublicIP)
+	assert.Equal(s.T(), "info", cfg.Logging.Level)
+	assert.Equal(s.T(), "text", cfg.Logging.Format)
+	assert.Equal(s.T(), "stdout", cfg.Logging.Output)
+	assert.Equal(s.T(), 9090, cfg.Prometheus.Port)
+	assert.True(s.T(), cfg.Health.IsEnabled())
+	assert.Equal(s.T(), 9090, cfg.Health.Port, "health shares the metrics port")
+	assert.Equal(s.T(), "127.0.0.1", cfg.Admin.Address)
+	assert.Equal(s.T(), 9092, cfg.Admin.Port)
+}
+
+// ---------------------------------------------------------------------------
+// Logger output
+// ---------------------------------------------------------------------------
+
+func (s *ConfigValidationSuite) TestLogOutput_StdStreams() {
+	cfg := &Config{}
+
+	cfg.Logging.Output = "stdout"
+	w, err := cfg.logOutput()
+	require.NoError(s.T(), err)
+	assert.Same(s.T(), os.Stdout, w)
+
+	cfg.Logging.Output = "stderr"
+	w, err = cfg.logOutput()
+	require.NoError(s.T(), err)
+	assert.Same(s.T(), os.Stderr, w)
+}
+
+func (s *ConfigValidationSuite) TestNewLogger_WritesToFile() {
+	path := filepath.Join(s.T().TempDir(), "scaleset.log")
+	require.NoError(s.T(), os.WriteFile(path, []byte("existing line\n"), 0o644))
+
+	cfg := &Config{Logging: LoggingConfig{Output: path, Format: "json"}}
+	logger, err := cfg.NewLogger()
+	require.NoError(s.T(), err)
+
+	logger.Info("hello from test")
+
+	data, err := os.ReadFile(path)
+	require.NoError(s.T(), err)
+	assert.Contains(s.T(), string(data), "existing line", "file must be opened in append mode")
+	assert.Contains(s.T(), string(data), "hello from test")
+}
+
+func (s *ConfigValidationSuite) TestNewLogger_UnwritableFile() {
+	cfg := &Config{Logging: LoggingConfig{Output: filepath.Join(s.T().TempDir(), "missing", "scaleset.log")}}
+	_, err := cfg.NewLogger()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "opening log file")
+}
+
+func (s *ConfigValidationSuite) TestScaleSetLogger_TagsEntries() {
+	cfg := validDockerConfig()
+	cfg.Logging.IncludeScaleSet = true
+
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, nil))
+	cfg.ScaleSetLogger(base, 42).WithGroup("scaler").Info("scaling up")
+
+	var entry map[string]any
+	require.NoError(s.T(), json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(s.T(), "test-scaleset", entry["scale_set"])
+	assert.Equal(s.T(), float64(42), entry["scale_set_id"])
+}
+
+func (s *ConfigValidationSuite) TestScaleSetLogger_DisabledByDefault() {
+	cfg := validDockerConfig()
+
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, nil))
+	cfg.ScaleSetLogger(base, 42).Info("scaling up")
+
+	assert.NotContains(s.T(), buf.String(), "scale_set")
+}
+
+// ---------------------------------------------------------------------------
+// EnabledEngine helper
+// ---------------------------------------------------------------------------
+
+func (s *ConfigValidationSuite) TestEnabledEngine() {
+	tests := []struct {
+		name   string
+		cfg    EngineConfig
+		expect string
+	}{
+		{"docker", EngineConfig{Docker: DockerEngineConfig{Enable: true}}, "docker"},
+		{"gcp", EngineConfig{GCP: GCPEngineConfig{Enable: true}}, "gcp"},
+		{"kubernetes", EngineConfig{Kubernetes: KubernetesEngineConfig{Enable: true}}, "kubernetes"},
+		{"hcloud", EngineConfig{HCloud: HCloudEngineConfig{Enable: true}}, "hcloud"},
+		{"podman", EngineConfig{Podman: PodmanEngineConfig{Enable: true}}, "podman"},
+		{"plugin", EngineConfig{Plugin: PluginEngineConfig{Enable: true}}, "plugin"},
+		{"fake", EngineConfig{Fake: FakeEngineConfig{Enable: true}}, "fake"},
+		{"aws", EngineConfig{AWS: AWSEngineConfig{Enable: true}}, "aws"},
+		{"azure", EngineConfig{Azure: AzureEngineConfig{Enable: true}}, "azure"},
+		{"none", EngineConfig{}, ""},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			assert.Equal(s.T(), tc.expect, tc.cfg.EnabledEngine())
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestLocation() {
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.Zone = "europe-west4-b"
+	region, zone := cfg.Engine.Location()
+	assert.Equal(s.T(), "europe-west4", region)
+	assert.Equal(s.T(), "europe-west4-b", zone)
+
+	region, zone = validDockerConfig().Engine.Location()
+	assert.Empty(s.T(), region)
+	assert.Empty(s.T(), zone)
+}
+
+// ---------------------------------------------------------------------------
+// BuildLabels
+// ---------------------------------------------------------------------------
+
+func (s *ConfigValidationSuite) TestBuildLabels_WithLabels() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.Labels = []string{"linux", "x64", "gpu"}
+	labels := cfg.BuildLabels()
+	assert.Len(s.T(), labels, 3)
+	assert.Equal(s.T(), "linux", labels[0].Name)
+	assert.Equal(s.T(), "x64", labels[1].Name)
+	assert.Equal(s.T(), "gpu", labels[2].Name)
+}
+
+func (s *ConfigValidationSuite) TestBuildLabels_FallsBackToName() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.Labels = nil
+	labels := cfg.BuildLabels()
+	assert.Len(s.T(), labels, 1)
+	assert.Equal(s.T(), "test-scaleset", labels[0].Name)
+}
+
+func (s *ConfigValidationSuite) TestBuildLabels_TrimsWhitespace() {
 	cfg := validDockerConfig()
 	cfg.ScaleSet.Labels = []string{"  linux  ", "x64"}
 	labels := cfg.BuildLabels()
 	assert.Equal(s.T(), "linux", labels[0].Name)
 }
+
+// ---------------------------------------------------------------------------
+// RunnerEnv
+// ---------------------------------------------------------------------------
+
+func (s *ConfigValidationSuite) TestRunnerEnv_DisabledByDefault() {
+	cfg := validDockerConfig()
+	assert.Empty(s.T(), cfg.RunnerEnv())
+}
+
+func (s *ConfigValidationSuite) TestRunnerEnv_ContextFromRepoURL() {
+	cfg := validDockerConfig()
+	cfg.ApplyDefaults()
+	cfg.ScaleSet.Labels = []string{"linux", "x64"}
+	cfg.Engine.Docker.ContextEnv = true
+
+	env := cfg.RunnerEnv()
+	assert.Equal(s.T(), "test-scaleset", env["SCALESET_NAME"])
+	assert.Equal(s.T(), "https://github.com/my-org/my-repo", env["SCALESET_GITHUB_URL"])
+	assert.Equal(s.T(), "my-org", env["SCALESET_ORG"])
+	assert.Equal(s.T(), "my-org/my-repo", env["SCALESET_REPO"])
+	assert.Equal(s.T(), "default", env["SCALESET_RUNNER_GROUP"])
+	assert.Equal(s.T(), "linux,x64", env["SCALESET_LABELS"])
+}
+
+func (s *ConfigValidationSuite) TestRunnerEnv_ContextFromOrgAndEnterpriseURL() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.ContextEnv = true
+
+	cfg.GitHub.URL = "https://github.com/my-org"
+	env := cfg.RunnerEnv()
+	assert.Equal(s.T(), "my-org", env["SCALESET_ORG"])
+	assert.NotContains(s.T(), env, "SCALESET_REPO")
+
+	cfg.GitHub.URL = "https://ghes.example.com/enterprises/acme"
+	env = cfg.RunnerEnv()
+	assert.Equal(s.T(), "acme", env["SCALESET_ENTERPRISE"])
+	assert.NotContains(s.T(), env, "SCALESET_ORG")
+}
+
+func (s *ConfigValidationSuite) TestRunnerEnv_StaticEnvOverridesContext() {
+	cfg := validDockerConfig()
+	cfg.Engine.Docker.ContextEnv = true
+	cfg.Engine.Docker.Env = map[string]string{
+		"SCALESET_ALLOWED_REPOS": "my-org/a,my-org/b",
+		"SCALESET_ORG":           "override",
+	}
+
+	env := cfg.RunnerEnv()
+	assert.Equal(s.T(), "my-org/a,my-org/b", env["SCALESET_ALLOWED_REPOS"])
+	assert.Equal(s.T(), "override", env["SCALESET_ORG"])
+}
+
+func (s *ConfigValidationSuite) TestValidate_Docker_InvalidEnv() {
+	for _, key := range []string{"", "A=B", "ACTIONS_RUNNER_INPUT_JITCONFIG"} {
+		cfg := validDockerConfig()
+		cfg.Engine.Docker.Env = map[string]string{key: "x"}
+		err := cfg.Validate()
+		assert.Error(s.T(), err, key)
+		assert.Contains(s.T(), err.Error(), "engine.docker.env")
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_HealthLabels() {
+	cfg := validDockerConfig()
+	cfg.Health.Labels = map[string]string{"environment": "production"}
+	assert.NoError(s.T(), cfg.Validate())
+
+	cfg.Health.Labels = map[string]string{" ": "x"}
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "health.labels")
+}
+
+func (s *ConfigValidationSuite) TestValidate_AdminPort() {
+	cfg := validDockerConfig()
+	cfg.Admin.Enable = true
+	assert.NoError(s.T(), cfg.Validate())
+
+	cfg.Admin.Port = 9090
+	err := cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "admin.port: 9090 is already used")
+
+	cfg.Admin.Port = 70000
+	err = cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "admin.port must be between 1 and 65535")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Webhook() {
+	cfg := validDockerConfig()
+	cfg.Webhook = WebhookConfig{Enable: true, Secret: "s3cret"}
+	require.NoError(s.T(), cfg.Validate())
+	assert.Equal(s.T(), 9093, cfg.Webhook.Port)
+	assert.Equal(s.T(), "/webhook", cfg.Webhook.Path)
+	assert.Equal(s.T(), WebhookModeAssist, cfg.Webhook.Mode)
+
+	tests := []struct {
+		name string
+		mod  func(c *Config)
+		want string
+	}{
+		{"no secret", func(c *Config) { c.Webhook.Secret = "" }, "webhook.secret is required"},
+		{"health port", func(c *Config) { c.Webhook.Port = 9090 }, "webhook.port: 9090 is already used by the health server"},
+		{"admin port", func(c *Config) {
+			c.Admin.Enable = true
+			c.Webhook.Port = 9092
+		}, "webhook.port: 9092 is already used by the admin API"},
+		{"port range", func(c *Config) { c.Webhook.Port = 70000 }, "webhook.port must be between 1 and 65535"},
+		{"path", func(c *Config) { c.Webhook.Path = "webhook" }, "webhook.path must start with '/'"},
+		{"mode", func(c *Config) { c.Webhook.Mode = "exclusive" }, `webhook.mode must be "assist" or "only"`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.Webhook = WebhookConfig{Enable: true, Secret: "s3cret"}
+			tt.mod(cfg)
+			err := cfg.Validate()
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.want)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestResolveSecrets() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/secret/data/gh" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"token":"ghp_vault","key":"PEM","hook":"whsec"},"metadata":{}}}`))
+	}))
+	defer srv.Close()
+	s.T().Setenv("VAULT_ADDR", srv.URL)
+	s.T().Setenv("VAULT_TOKEN", "s.token")
+
+	cfg := &Config{
+		GitHub: GitHubConfig{Token: "vault:secret/data/gh#token"},
+		ScaleSets: []ScaleSetEntry{
+			{GitHub: GitHubConfig{App: GitHubAppConfig{PrivateKey: "vault:secret/data/gh#key"}}},
+			{GitHub: GitHubConfig{Token: "ghp_plain"}},
+		},
+		Webhook: WebhookConfig{Secret: "vault:secret/data/gh#hook"},
+	}
+	require.NoError(s.T(), cfg.ResolveSecrets(context.Background()))
+	assert.Equal(s.T(), "whsec", cfg.Webhook.Secret)
+	assert.Equal(s.T(), "ghp_vault", cfg.GitHub.Token)
+	assert.Equal(s.T(), "PEM", cfg.ScaleSets[0].GitHub.App.PrivateKey)
+	assert.Equal(s.T(), "ghp_plain", cfg.ScaleSets[1].GitHub.Token)
+
+	cfg = &Config{GitHub: GitHubConfig{Token: "vault:secret/data/missing#token"}}
+	err := cfg.ResolveSecrets(context.Background())
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "github.token: resolving vault:secret/data/missing#token")
+}
+
+// validLeaderConfig returns a valid configuration with leader election
+// on the redis backend.
+func validLeaderConfig() *Config {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.KeepOnShutdown = true
+	cfg.ScaleSet.AdoptExisting = true
+	cfg.Leader = LeaderConfig{Enable: true, Backend: "redis", Redis: LeaderRedisConfig{Address: "redis:6379"}}
+	return cfg
+}
+
+func (s *ConfigValidationSuite) TestValidate_LeaderElection() {
+	cfg := validLeaderConfig()
+	require.NoError(s.T(), cfg.Validate())
+	assert.Equal(s.T(), 15*time.Second, cfg.Leader.LeaseDuration)
+	assert.Equal(s.T(), 10*time.Second, cfg.Leader.RenewDeadline)
+	assert.Equal(s.T(), 2*time.Second, cfg.Leader.RetryPeriod)
+	assert.Equal(s.T(), "scaleset-leader", cfg.Leader.Redis.Key)
+
+	tests := []struct {
+		name string
+		mod  func(l *LeaderConfig)
+		want string
+	}{
+		{"no backend", func(l *LeaderConfig) { l.Backend = "" }, "leader_election.backend is required"},
+		{"unknown backend", func(l *LeaderConfig) { l.Backend = "etcd" }, `unknown backend "etcd"`},
+		{"redis address", func(l *LeaderConfig) { l.Redis.Address = "redis" }, "leader_election.redis.address: must be host:port"},
+		{"file path", func(l *LeaderConfig) { l.Backend = "file" }, "leader_election.file.path is required"},
+		{"renew deadline", func(l *LeaderConfig) { l.RenewDeadline = time.Second }, "renew_deadline (1s) must be greater than retry_period (2s)"},
+		{"lease duration", func(l *LeaderConfig) { l.LeaseDuration = 10 * time.Second }, "lease_duration (10s) must be greater than renew_deadline (10s)"},
+		{"whole seconds", func(l *LeaderConfig) {
+			l.Backend = "kubernetes"
+			l.LeaseDuration = 15500 * time.Millisecond
+		}, "must be whole seconds"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validLeaderConfig()
+			cfg.ApplyDefaults()
+			tt.mod(&cfg.Leader)
+			err := cfg.Validate()
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.want)
+		})
+	}
+
+	// The file lock has no lease, so lease_duration is not checked.
+	cfg = validLeaderConfig()
+	cfg.Leader = LeaderConfig{Enable: true, Backend: "file", LeaseDuration: time.Second, File: LeaderFileConfig{Path: "/run/scaleset.lock"}}
+	assert.NoError(s.T(), cfg.Validate())
+
+	// Replicas must share the scale set, so a leader that loses its lease
+	// neither deletes it nor registers a new one.
+	for _, mod := range []func(c *Config){
+		func(c *Config) { c.ScaleSet.KeepOnShutdown = false },
+		func(c *Config) { c.ScaleSet.AdoptExisting = false },
+	} {
+		cfg = validLeaderConfig()
+		mod(cfg)
+		assert.ErrorContains(s.T(), cfg.Validate(), "leader_election.enable requires scaleset.keep_on_shutdown and scaleset.adopt_existing")
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_HealthServer() {
+	cfg := validDockerConfig()
+	cfg.Prometheus.Port = 9100
+	require.NoError(s.T(), cfg.Validate())
+	assert.Equal(s.T(), 9100, cfg.Health.Port, "defaults to prometheus.port")
+
+	cfg.Health.Port = 70000
+	err := cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "health.port must be between 1 and 65535")
+
+	disabled := false
+	cfg = validDockerConfig()
+	cfg.Health.Enable = &disabled
+	cfg.Health.Pprof = true
+	err = cfg.Validate()
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "health.pprof requires health.enable")
+
+	// With the health server off, the admin API may take its port.
+	cfg.Health.Pprof = false
+	cfg.Admin.Enable = true
+	cfg.Admin.Port = 9090
+	assert.NoError(s.T(), cfg.Validate())
+}
+
+func (s *ConfigValidationSuite) TestValidate_RunID() {
+	cfg := validDockerConfig()
+	cfg.ScaleSet.RunID = "9f1c2d3e-ci_runners"
+	assert.NoError(s.T(), cfg.Validate())
+
+	cfg.ScaleSet.RunID = "Not.Valid"
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "run_id")
+}
+
+// ---------------------------------------------------------------------------
+// GCP quota check
+// ---------------------------------------------------------------------------
+
+type fakeQuotaChecker struct {
+	err        error
+	maxRunners int
+}
+
+func (f *fakeQuotaChecker) CheckQuotaHeadroom(_ context.Context, maxRunners int) error {
+	f.maxRunners = maxRunners
+	return f.err
+}
+
+func (s *ConfigValidationSuite) TestValidate_GCP_QuotaCheckMode() {
+	for _, mode := range []string{"", "warn", "error"} {
+		cfg := validGCPConfig()
+		cfg.Engine.GCP.QuotaCheck = mode
+		assert.NoError(s.T(), cfg.Validate(), mode)
+	}
+
+	cfg := validGCPConfig()
+	cfg.Engine.GCP.QuotaCheck = "strict"
+	err := cfg.Validate()
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "quota_check")
+}
+
+func (s *ConfigValidationSuite) TestCheckGCPQuota() {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	shortfall := errors.New("insufficient gcp quota: CPUS")
+
+	cfg := validGCPConfig()
+	cfg.ScaleSet.MaxRunners = 7
+
+	// Off: the checker is not consulted.
+	qc := &fakeQuotaChecker{err: shortfall}
+	require.NoError(s.T(), cfg.checkQuota(context.Background(), cfg.Engine.GCP.QuotaCheck, qc, logger))
+	assert.Zero(s.T(), qc.maxRunners)
+
+	// Warn: logged, startup continues.
+	cfg.Engine.GCP.QuotaCheck = "warn"
+	require.NoError(s.T(), cfg.checkQuota(context.Background(), cfg.Engine.GCP.QuotaCheck, qc, logger))
+	assert.Equal(s.T(), 7, qc.maxRunners)
+	assert.Contains(s.T(), buf.String(), "insufficient gcp quota")
+
+	// Error: returned.
+	cfg.Engine.GCP.QuotaCheck = "error"
+	err := cfg.checkQuota(context.Background(), cfg.Engine.GCP.QuotaCheck, qc, logger)
+	require.ErrorIs(s.T(), err, shortfall)
+
+	// Sufficient headroom passes in either mode.
+	require.NoError(s.T(), cfg.checkQuota(context.Background(), cfg.Engine.GCP.QuotaCheck, &fakeQuotaChecker{}, logger))
+}
+
+// ---------------------------------------------------------------------------
+// Environment
+// ---------------------------------------------------------------------------
+
+// envLookup returns a lookup func over env.
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func (s *ConfigValidationSuite) TestApplyEnv_Overrides() {
+	cfg := validDockerConfig()
+	cfg.Health.Labels = map[string]string{"team": "platform"}
+
+	err := cfg.ApplyEnv(envLookup(map[string]string{
+		"SCALESET_GITHUB_TOKEN":               "ghp_from_env",
+		"SCALESET_ENGINE_DOCKER_IMAGE":        "runner:env",
+		"SCALESET_SCALESET_MAX_RUNNERS":       "20",
+		"SCALESET_SCALESET_START_DEADLINE":    "90s",
+		"SCALESET_SCALESET_IDEMPOTENT_STARTS": "true",
+		"SCALESET_SCALESET_LABELS":            "[gpu, linux]",
+		"SCALESET_HEALTH_LABELS":              "{region: eu}",
+		"SCALESET_LOGGING_LEVEL":              "",
+	}))
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), "ghp_from_env", cfg.GitHub.Token)
+	assert.Equal(s.T(), "runner:env", cfg.Engine.Docker.Image)
+	assert.Equal(s.T(), 20, cfg.ScaleSet.MaxRunners)
+	assert.Equal(s.T(), 90*time.Second, cfg.ScaleSet.StartDeadline)
+	assert.True(s.T(), cfg.ScaleSet.IdempotentStarts)
+	assert.Equal(s.T(), []string{"gpu", "linux"}, cfg.ScaleSet.Labels)
+	assert.Equal(s.T(), map[string]string{"region": "eu"}, cfg.Health.Labels, "maps are replaced, not merged")
+	assert.Empty(s.T(), cfg.Logging.Level, "empty variables are ignored")
+	assert.Nil(s.T(), cfg.Engine.Fallback, "unset sections stay unset")
+}
+
+func (s *ConfigValidationSuite) TestApplyEnv_AllocatesSection() {
+	cfg := validDockerConfig()
+	err := cfg.ApplyEnv(envLookup(map[string]string{
+		"SCALESET_ENGINE_FALLBACK_AFTER_FAILURES": "5",
+	}))
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), cfg.Engine.Fallback)
+	assert.Equal(s.T(), 5, cfg.Engine.Fallback.AfterFailures)
+}
+
+func (s *ConfigValidationSuite) TestApplyEnv_InvalidValue() {
+	cfg := validDockerConfig()
+	err := cfg.ApplyEnv(envLookup(map[string]string{
+		"SCALESET_SCALESET_MAX_RUNNERS": "lots",
+	}))
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "SCALESET_SCALESET_MAX_RUNNERS (scaleset.max_runners): invalid value")
+}
+
+func (s *ConfigValidationSuite) TestApplyEnv_NamesAreUnique() {
+	seen := make(map[string]int)
+	err := (&Config{}).ApplyEnv(func(key string) (string, bool) {
+		seen[key]++
+		return "", false
+	})
+	require.NoError(s.T(), err)
+
+	assert.Contains(s.T(), seen, "SCALESET_GITHUB_APP_PRIVATE_KEY")
+	assert.NotContains(s.T(), seen, "SCALESET_ENGINE_GCP_METADATA_FROM_FILE")
+	for key, n := range seen {
+		assert.Equal(s.T(), 1, n, "%s names more than one field", key)
+	}
+}
+
+func (s *ConfigValidationSuite) TestLoad_ScaleSets() {
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+github:
+  url: https://github.com/my-org
+  token: ghp_shared
+scale_sets:
+  - scaleset:
+      name: linux
+    engine:
+      docker:
+        enable: true
+  - github:
+      url: https://github.com/other-org/repo
+      app:
+        client_id: Iv1.abc
+        installation_id: 42
+        private_key: key
+    scaleset:
+      name: gpu
+      max_runners: 4
+    engine:
+      docker:
+        enable: true
+        image: runner:gpu
+    state:
+      path: /var/lib/scaleset/gpu.json
+`), 0o600))
+
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), cfg.Validate())
+
+	cfgs := cfg.ScaleSetConfigs()
+	require.Len(s.T(), cfgs, 2)
+	linux, gpu := cfgs[0], cfgs[1]
+
+	assert.Equal(s.T(), "https://github.com/my-org", linux.GitHub.URL)
+	assert.Equal(s.T(), "ghp_shared", linux.GitHub.Token)
+	assert.Equal(s.T(), 10, linux.ScaleSet.MaxRunners, "entries get the scaleset defaults")
+	assert.Equal(s.T(), "ghcr.io/actions/actions-runner:latest", linux.Engine.Docker.Image)
+
+	assert.Equal(s.T(), "https://github.com/other-org/repo", gpu.GitHub.URL)
+	assert.Empty(s.T(), gpu.GitHub.Token, "an entry's credentials replace the shared ones")
+	assert.Equal(s.T(), int64(42), gpu.GitHub.App.InstallationID)
+	assert.Equal(s.T(), 4, gpu.ScaleSet.MaxRunners)
+	assert.Equal(s.T(), "/var/lib/scaleset/gpu.json", gpu.State.Path)
+
+	for _, sc := range cfgs {
+		assert.Equal(s.T(), "info", sc.Logging.Level, "shared sections are copied")
+		assert.Nil(s.T(), sc.ScaleSets)
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_ScaleSets() {
+	entry := func(name string) ScaleSetEntry {
+		return ScaleSetEntry{
+			ScaleSet: ScaleSetConfig{Name: name},
+			Engine:   EngineConfig{Docker: DockerEngineConfig{Enable: true}},
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		errMsg string
+	}{
+		{
+			name:   "top-level scaleset",
+			modify: func(c *Config) { c.ScaleSet.MaxRunners = 5 },
+			errMsg: "scaleset: not allowed with scale_sets",
+		},
+		{
+			name:   "top-level engine",
+			modify: func(c *Config) { c.Engine.Docker.Enable = true },
+			errMsg: "engine: not allowed with scale_sets",
+		},
+		{
+			name:   "top-level state",
+			modify: func(c *Config) { c.State.Path = "/tmp/state.json" },
+			errMsg: "state.path: not allowed with scale_sets",
+		},
+		{
+			name:   "invalid entry",
+			modify: func(c *Config) { c.ScaleSets[1].ScaleSet.Name = "" },
+			errMsg: "scale_sets[1]: scaleset.name is required",
+		},
+		{
+			name:   "duplicate name",
+			modify: func(c *Config) { c.ScaleSets[1].ScaleSet.Name = "linux" },
+			errMsg: `scale_sets[1].scaleset.name: "linux" is also registered at https://github.com/my-org/my-repo by scale_sets[0]`,
+		},
+		{
+			name: "shared state file",
+			modify: func(c *Config) {
+				c.ScaleSets[0].State.Path = "/tmp/state.json"
+				c.ScaleSets[1].State.Path = "/tmp/state.json"
+			},
+			errMsg: `scale_sets[1].state.path: "/tmp/state.json" is also used by scale_sets[0]`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := &Config{
+				GitHub:    validDockerConfig().GitHub,
+				ScaleSets: []ScaleSetEntry{entry("linux"), entry("gpu")},
+			}
+			require.NoError(s.T(), cfg.Validate())
+
+			cfg = &Config{
+				GitHub:    validDockerConfig().GitHub,
+				ScaleSets: []ScaleSetEntry{entry("linux"), entry("gpu")},
+			}
+			tt.modify(cfg)
+			err := cfg.Validate()
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestValidate_ScaleSetsSameNameInOtherRepo() {
+	cfg := &Config{
+		GitHub: validDockerConfig().GitHub,
+		ScaleSets: []ScaleSetEntry{
+			{ScaleSet: ScaleSetConfig{Name: "ci"}, Engine: EngineConfig{Docker: DockerEngineConfig{Enable: true}}},
+			{
+				GitHub:   GitHubConfig{URL: "https://github.com/my-org/other-repo"},
+				ScaleSet: ScaleSetConfig{Name: "ci"},
+				Engine:   EngineConfig{Docker: DockerEngineConfig{Enable: true}},
+			},
+		},
+	}
+	require.NoError(s.T(), cfg.Validate())
+	assert.Equal(s.T(), "ghp_test_token", cfg.ScaleSetConfigs()[1].GitHub.Token, "credentials are shared unless overridden")
+}
+
+func (s *ConfigValidationSuite) TestRunnerLimitsAt() {
+	five, zero, twenty := 5, 0, 20
+	sc := ScaleSetConfig{
+		MinRunners: 1,
+		MaxRunners: 10,
+		Schedules: []RunnerSchedule{
+			{Name: "release", Cron: "0 0 1 * *", Duration: 24 * time.Hour, Timezone: "UTC", MaxRunners: &twenty},
+			{Name: "business-hours", Cron: "0 8 * * mon-fri", Duration: 10 * time.Hour, Timezone: "UTC", MinRunners: &five},
+			{Name: "weekend", Cron: "0 0 * * sat", Duration: 48 * time.Hour, Timezone: "UTC", MinRunners: &zero},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		min, max int
+		schedule string
+	}{
+		{"weekday morning", time.Date(2025, time.June, 3, 9, 30, 0, 0, time.UTC), 5, 10, "business-hours"},
+		{"weekday night", time.Date(2025, time.June, 3, 20, 0, 0, 0, time.UTC), 1, 10, ""},
+		{"weekend", time.Date(2025, time.June, 8, 12, 0, 0, 0, time.UTC), 0, 10, "weekend"},
+		{"first schedule wins", time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC), 1, 20, "release"},
+		{"in another zone", time.Date(2025, time.June, 3, 10, 0, 0, 0, time.FixedZone("CEST", 2*3600)), 5, 10, "business-hours"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			minRunners, maxRunners, schedule := sc.RunnerLimitsAt(tt.at)
+			assert.Equal(s.T(), tt.min, minRunners)
+			assert.Equal(s.T(), tt.max, maxRunners)
+			assert.Equal(s.T(), tt.schedule, schedule)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestLoad_Schedules() {
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+github:
+  url: https://github.com/my-org
+  token: ghp_test_token
+scaleset:
+  name: ci
+  schedules:
+    - name: business-hours
+      cron: "0 8 * * mon-fri"
+      duration: 10h
+      timezone: Europe/Stockholm
+      min_runners: 5
+engine:
+  docker:
+    enable: true
+`), 0o600))
+
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), cfg.Validate())
+	require.Len(s.T(), cfg.ScaleSet.Schedules, 1)
+	r := cfg.ScaleSet.Schedules[0]
+	assert.Equal(s.T(), 10*time.Hour, r.Duration)
+	assert.Equal(s.T(), 5, *r.MinRunners)
+	assert.Nil(s.T(), r.MaxRunners)
+}
+
+func (s *ConfigValidationSuite) TestValidate_Schedules() {
+	intp := func(v int) *int { return &v }
+	tests := []struct {
+		name   string
+		modify func(r *RunnerSchedule)
+		errMsg string
+	}{
+		{"no name", func(r *RunnerSchedule) { r.Name = "" }, "scaleset.schedules[1].name is required"},
+		{"duplicate name", func(r *RunnerSchedule) { r.Name = "nights" }, `duplicate schedule "nights"`},
+		{"invalid cron", func(r *RunnerSchedule) { r.Cron = "0 8 * *" }, "scaleset.schedules[1].cron: cron expression"},
+		{"no duration", func(r *RunnerSchedule) { r.Duration = 0 }, "scaleset.schedules[1].duration must be > 0"},
+		{"long duration", func(r *RunnerSchedule) { r.Duration = 32 * 24 * time.Hour }, "at most 744h0m0s"},
+		{"unknown timezone", func(r *RunnerSchedule) { r.Timezone = "Mars/Olympus" }, "scaleset.schedules[1].timezone"},
+		{"no limits", func(r *RunnerSchedule) { r.MinRunners = nil }, "scaleset.schedules[1]: set min_runners or max_runners"},
+		{"negative min", func(r *RunnerSchedule) { r.MinRunners = intp(-1) }, "scaleset.schedules[1].min_runners must be >= 0"},
+		{"min above max", func(r *RunnerSchedule) { r.MinRunners = intp(11) }, "scaleset.schedules[1]: max_runners (10) < min_runners (11)"},
+		{"max below min", func(r *RunnerSchedule) { r.MinRunners, r.MaxRunners = intp(3), intp(2) }, "max_runners (2) < min_runners (3)"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.ScaleSet.Schedules = []RunnerSchedule{
+				{Name: "nights", Cron: "0 20 * * *", Duration: 12 * time.Hour, MinRunners: intp(0)},
+				{Name: "business-hours", Cron: "0 8 * * mon-fri", Duration: 10 * time.Hour, Timezone: "UTC", MinRunners: intp(5)},
+			}
+			require.NoError(s.T(), cfg.Validate())
+
+			tt.modify(&cfg.ScaleSet.Schedules[1])
+			err := cfg.Validate()
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestLoad_Flavors() {
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	require.NoError(s.T(), os.WriteFile(path, []byte(`
+github:
+  url: https://github.com/my-org
+  token: ghp_test_token
+scaleset:
+  name: ci
+  labels: [linux]
+  run_id: prod
+  flavors:
+    - name: small
+      gcp: { machine_type: e2-small }
+    - name: gpu
+      labels: [gpu, cuda]
+      max_runners: 2
+      gcp:
+        machine_type: n1-standard-8
+        accelerators: [{ type: nvidia-tesla-t4, count: 1 }]
+engine:
+  gcp:
+    enable: true
+    project: my-project
+    zone: europe-west4-b
+    image: projects/my-project/global/images/runner
+state:
+  path: /var/lib/scaleset/state.json
+`), 0o600))
+
+	cfg, err := Load(path)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), cfg.Validate())
+
+	cfgs := cfg.ScaleSetConfigs()
+	require.Len(s.T(), cfgs, 2)
+	small, gpu := cfgs[0], cfgs[1]
+
+	assert.Equal(s.T(), "ci-small", small.ScaleSet.Name)
+	assert.Equal(s.T(), []string{"linux", "small"}, small.ScaleSet.Labels, "a flavor's name is its default label")
+	assert.Equal(s.T(), 10, small.ScaleSet.MaxRunners, "flavors inherit the scale set's limits")
+	assert.Equal(s.T(), "prod-small", small.ScaleSet.RunID)
+	assert.Equal(s.T(), "/var/lib/scaleset/state-small.json", small.State.Path)
+
+	assert.Equal(s.T(), "ci-gpu", gpu.ScaleSet.Name)
+	assert.Equal(s.T(), []string{"linux", "gpu", "cuda"}, gpu.ScaleSet.Labels)
+	assert.Equal(s.T(), 2, gpu.ScaleSet.MaxRunners)
+	assert.Equal(s.T(), "/var/lib/scaleset/state-gpu.json", gpu.State.Path)
+
+	for _, sc := range cfgs {
+		assert.Nil(s.T(), sc.ScaleSet.Flavors)
+		profile, err := sc.selectProfile()
+		require.NoError(s.T(), err)
+		require.NotNil(s.T(), profile)
+	}
+	smallProfile, _ := small.selectProfile()
+	assert.Equal(s.T(), "e2-small", smallProfile.apply(small.Engine).GCP.MachineType)
+	gpuProfile, _ := gpu.selectProfile()
+	assert.Equal(s.T(), []GCPAccelerator{{Type: "nvidia-tesla-t4", Count: 1}}, gpuProfile.apply(gpu.Engine).GCP.Accelerators)
+	assert.Equal(s.T(), "e2-medium", cfg.Engine.GCP.MachineType, "the base engine is unchanged")
+}
+
+func (s *ConfigValidationSuite) TestValidate_Flavors() {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		errMsg string
+	}{
+		{
+			name:   "no name",
+			modify: func(c *Config) { c.ScaleSet.Flavors[1].Name = "" },
+			errMsg: "scaleset.flavors[1].name is required",
+		},
+		{
+			name:   "invalid name",
+			modify: func(c *Config) { c.ScaleSet.Flavors[1].Name = "Large" },
+			errMsg: `scaleset.flavors[1].name "Large" must be`,
+		},
+		{
+			name:   "duplicate name",
+			modify: func(c *Config) { c.ScaleSet.Flavors[1].Name = "small" },
+			errMsg: `duplicate flavor "small"`,
+		},
+		{
+			name: "limits",
+			modify: func(c *Config) {
+				one := 1
+				c.ScaleSet.Flavors[1].MaxRunners = &one
+				c.ScaleSet.Flavors[1].MinRunners = new(int)
+				*c.ScaleSet.Flavors[1].MinRunners = 2
+			},
+			errMsg: "scaleset.flavors[1] (large): scaleset.max_runners (1) < scaleset.min_runners (2)",
+		},
+		{
+			name: "engine profiles",
+			modify: func(c *Config) {
+				c.Engine.Profiles = []EngineProfile{{Name: "gpu", Labels: []string{"gpu"}}}
+			},
+			errMsg: "engine.profiles: not allowed with scaleset.flavors",
+		},
+		{
+			name: "scale_sets entry",
+			modify: func(c *Config) {
+				c.ScaleSets = []ScaleSetEntry{{ScaleSet: c.ScaleSet, Engine: c.Engine}}
+				c.ScaleSet, c.Engine = ScaleSetConfig{}, EngineConfig{}
+			},
+			errMsg: "scale_sets[0].scaleset.flavors: not allowed with scale_sets",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			cfg := validDockerConfig()
+			cfg.ScaleSet.Flavors = []Flavor{{Name: "small"}, {Name: "large", Docker: DockerProfile{Image: "runner:large"}}}
+			tt.modify(cfg)
+			err := cfg.Validate()
+			require.Error(s.T(), err)
+			assert.Contains(s.T(), err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func (s *ConfigValidationSuite) TestScaleSetConfigs_SingleScaleSet() {
+	cfg := validDockerConfig()
+	assert.Equal(s.T(), []*Config{cfg}, cfg.ScaleSetConfigs())
+}
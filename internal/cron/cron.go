@@ -0,0 +1,165 @@
+// Package cron parses standard five-field cron expressions ("minute hour
+// day-of-month month day-of-week") for the scheduled runner limits.  It
+// only matches times against an expression; running anything on a
+// schedule is left to the caller.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record an unrestricted ("*") day-of-month or
+	// day-of-week: when both are restricted, a day matching either one
+	// matches, as in cron(8).
+	domStar, dowStar bool
+}
+
+// field is the range and names of one cron field.
+type field struct {
+	name     string
+	min, max int
+	names    []string // names[i] is value min+i
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	dowField = field{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// Parse parses a five-field cron expression.  Each field is "*", a value,
+// a range "a-b" or a comma-separated list of those, each optionally
+// stepped with "/n".  Months and days of the week may be given by their
+// three-letter English names; Sunday is 0 or 7.
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+	var (
+		s   Schedule
+		err error
+	)
+	for i, f := range []struct {
+		spec string
+		def  field
+		bits *uint64
+	}{
+		{fields[0], minuteField, &s.minute},
+		{fields[1], hourField, &s.hour},
+		{fields[2], domField, &s.dom},
+		{fields[3], monthField, &s.month},
+		{fields[4], dowField, &s.dow},
+	} {
+		if *f.bits, err = f.def.parse(f.spec); err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		if i == 4 && *f.bits&(1<<7) != 0 {
+			*f.bits |= 1 // Sunday is 0 or 7
+		}
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+// parse returns the values the spec of field f selects as a bit set.
+func (f field) parse(spec string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(spec, ",") {
+		rng, stepSpec, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			n, err := strconv.Atoi(stepSpec)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepSpec)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			loSpec, hiSpec, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(loSpec); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(hiSpec); err != nil {
+				return 0, err
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("%s: range %q is reversed", f.name, rng)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !stepped {
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single number or name of field f.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: %d is out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Matches reports whether the minute of t matches the schedule, in t's
+// location.
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minute&(1<<t.Minute()) == 0 || s.hour&(1<<t.Hour()) == 0 || s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Active reports whether t falls within d of the schedule's most recent
+// match, i.e. whether a window of length d opened by the schedule is
+// open at t.  Windows are looked up minute by minute, so d should be
+// bounded (days, not years).
+func (s *Schedule) Active(t time.Time, d time.Duration) bool {
+	start := t.Truncate(time.Minute)
+	for m := start; t.Sub(m) < d; m = m.Add(-time.Minute) {
+		if s.Matches(m) {
+			return true
+		}
+	}
+	return false
+}
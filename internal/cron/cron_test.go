@@ -0,0 +1,102 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// at returns the given UTC time on 2025-06-<day> (a Sunday is the 1st).
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, time.June, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * * fri-mon",
+		"*/0 * * * *",
+		"x * * * *",
+		"* * * foo *",
+	} {
+		_, err := Parse(expr)
+		assert.Error(t, err, "%q", expr)
+	}
+}
+
+func TestMatches_BusinessHours(t *testing.T) {
+	s, err := Parse("0 8 * * mon-fri")
+	require.NoError(t, err)
+
+	assert.True(t, s.Matches(at(2, 8, 0)), "Monday 08:00")
+	assert.True(t, s.Matches(at(6, 8, 0)), "Friday 08:00")
+	assert.False(t, s.Matches(at(2, 8, 1)), "Monday 08:01")
+	assert.False(t, s.Matches(at(1, 8, 0)), "Sunday 08:00")
+	assert.False(t, s.Matches(at(7, 8, 0)), "Saturday 08:00")
+}
+
+func TestMatches_ListsStepsAndNames(t *testing.T) {
+	s, err := Parse("*/15 9,17 * JAN-Jun 0")
+	require.NoError(t, err)
+
+	assert.True(t, s.Matches(at(1, 9, 45)))
+	assert.True(t, s.Matches(at(1, 17, 0)))
+	assert.False(t, s.Matches(at(1, 9, 10)))
+	assert.False(t, s.Matches(at(1, 12, 0)))
+	assert.False(t, s.Matches(time.Date(2025, time.July, 6, 9, 0, 0, 0, time.UTC)), "July")
+}
+
+func TestMatches_SundayIsSeven(t *testing.T) {
+	s, err := Parse("0 0 * * 7")
+	require.NoError(t, err)
+	assert.True(t, s.Matches(at(1, 0, 0)))
+}
+
+func TestMatches_DayOfMonthOrDayOfWeek(t *testing.T) {
+	// Both restricted: either matches, as in cron(8).
+	s, err := Parse("0 0 15 * mon")
+	require.NoError(t, err)
+	assert.True(t, s.Matches(at(15, 0, 0)), "the 15th, a Sunday")
+	assert.True(t, s.Matches(at(2, 0, 0)), "a Monday")
+	assert.False(t, s.Matches(at(3, 0, 0)))
+
+	// One unrestricted: both must match.
+	s, err = Parse("0 0 15 * *")
+	require.NoError(t, err)
+	assert.False(t, s.Matches(at(2, 0, 0)))
+}
+
+func TestActive(t *testing.T) {
+	s, err := Parse("0 8 * * mon-fri")
+	require.NoError(t, err)
+	d := 10 * time.Hour
+
+	assert.True(t, s.Active(at(2, 8, 0), d), "at the start")
+	assert.True(t, s.Active(at(2, 17, 59), d))
+	assert.True(t, s.Active(at(2, 17, 59).Add(30*time.Second), d))
+	assert.False(t, s.Active(at(2, 18, 0), d), "at the end")
+	assert.False(t, s.Active(at(2, 7, 59), d))
+	assert.False(t, s.Active(at(7, 9, 0), d), "Saturday")
+}
+
+func TestActive_SpansMidnightInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	s, err := Parse("0 22 * * fri")
+	require.NoError(t, err)
+
+	// Friday 22:00 to Saturday 06:00, Stockholm time (UTC+2 in June).
+	assert.False(t, s.Active(time.Date(2025, time.June, 7, 4, 0, 0, 0, time.UTC).In(loc), 8*time.Hour))
+	assert.True(t, s.Active(time.Date(2025, time.June, 7, 3, 59, 0, 0, time.UTC).In(loc), 8*time.Hour))
+}
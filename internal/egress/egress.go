@@ -0,0 +1,90 @@
+// Package egress configures how scaleset's own HTTP clients (the GitHub
+// scaleset client, the Docker client and the cloud SDKs) leave the
+// host: through which proxy, and which certificate authorities they
+// trust.  Enterprise networks often require both an egress proxy and a
+// private CA that re-signs TLS traffic.
+package egress
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Config describes the proxy and the trusted CAs.  The zero value uses
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables and
+// the system roots, like Go's default transport.
+type Config struct {
+	// ProxyURL is the proxy for HTTP and HTTPS requests, e.g.
+	// "http://proxy.corp.example:3128".  Empty uses the environment.
+	ProxyURL string
+
+	// NoProxy lists hosts that bypass ProxyURL, in NO_PROXY syntax
+	// ("localhost,.internal.example,10.0.0.0/8").  Only used with
+	// ProxyURL.
+	NoProxy string
+
+	// CABundlePath is a PEM file of CA certificates trusted in addition
+	// to the system roots.
+	CABundlePath string
+}
+
+// IsZero reports whether c leaves the default proxy and roots in place.
+func (c Config) IsZero() bool {
+	return c == Config{}
+}
+
+// Proxy returns the proxy selection function for c.
+func (c Config) Proxy() func(*http.Request) (*url.URL, error) {
+	if c.ProxyURL == "" {
+		return http.ProxyFromEnvironment
+	}
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  c.ProxyURL,
+		HTTPSProxy: c.ProxyURL,
+		NoProxy:    c.NoProxy,
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// RootCAs returns the system roots extended with the certificates of
+// CABundlePath, or nil (the system roots) without a bundle.
+func (c Config) RootCAs() (*x509.CertPool, error) {
+	if c.CABundlePath == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(c.CABundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", c.CABundlePath)
+	}
+	return pool, nil
+}
+
+// Transport returns a copy of http.DefaultTransport using c's proxy and
+// roots.
+func (c Config) Transport() (*http.Transport, error) {
+	roots, err := c.RootCAs()
+	if err != nil {
+		return nil, err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = c.Proxy()
+	if roots != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: roots}
+	}
+	return t, nil
+}
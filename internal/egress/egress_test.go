@@ -0,0 +1,108 @@
+package egress
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCA writes a self-signed CA certificate to a PEM file and returns
+// its path and certificate.
+func writeCA(t *testing.T) (string, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Corp Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return path, cert
+}
+
+func TestProxy(t *testing.T) {
+	proxy := Config{ProxyURL: "http://proxy.corp:3128", NoProxy: ".internal,10.0.0.0/8"}.Proxy()
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.github.com/meta", want: "http://proxy.corp:3128"},
+		{url: "http://ghes.example.com/api/v3", want: "http://proxy.corp:3128"},
+		{url: "https://ghes.internal/api/v3", want: ""},
+		{url: "https://10.1.2.3:2376/v1.47/info", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+			got, err := proxy(req)
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestRootCAs(t *testing.T) {
+	assert.True(t, Config{}.IsZero())
+	roots, err := Config{}.RootCAs()
+	require.NoError(t, err)
+	assert.Nil(t, roots, "no bundle keeps the system roots")
+
+	path, cert := writeCA(t)
+	roots, err = Config{CABundlePath: path}.RootCAs()
+	require.NoError(t, err)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots})
+	assert.NoError(t, err, "bundle CA should be trusted")
+
+	_, err = Config{CABundlePath: filepath.Join(t.TempDir(), "missing.pem")}.RootCAs()
+	assert.ErrorContains(t, err, "reading CA bundle")
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate\n"), 0o600))
+	_, err = Config{CABundlePath: empty}.RootCAs()
+	assert.ErrorContains(t, err, "contains no PEM certificates")
+}
+
+func TestTransport(t *testing.T) {
+	path, _ := writeCA(t)
+	tr, err := Config{ProxyURL: "http://proxy.corp:3128", CABundlePath: path}.Transport()
+	require.NoError(t, err)
+
+	require.NotNil(t, tr.TLSClientConfig)
+	assert.NotNil(t, tr.TLSClientConfig.RootCAs)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com", nil)
+	u, err := tr.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "proxy.corp:3128", u.Host)
+
+	assert.NotSame(t, http.DefaultTransport, tr, "the default transport must not be modified")
+}
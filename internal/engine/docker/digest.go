@@ -0,0 +1,37 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+)
+
+// inspectFunc inspects a local image.
+type inspectFunc func(ctx context.Context, ref string) (image.InspectResponse, error)
+
+// resolveDigest returns a reference to exactly the local image ref points
+// to, so containers created from it keep using that image even if the tag
+// moves.  This is the repo digest of ref's repository
+// ("ghcr.io/actions/actions-runner@sha256:..."), or the image ID for an
+// image that was never pushed and so has no repo digest.
+func resolveDigest(ctx context.Context, inspect inspectFunc, ref string) (string, error) {
+	img, err := inspect(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("inspecting image %s: %w", ref, err)
+	}
+
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		for _, rd := range img.RepoDigests {
+			digested, err := reference.ParseNormalizedNamed(rd)
+			if err == nil && digested.Name() == named.Name() {
+				return rd, nil
+			}
+		}
+	}
+	if img.ID == "" {
+		return "", fmt.Errorf("image %s has no digest", ref)
+	}
+	return img.ID, nil
+}
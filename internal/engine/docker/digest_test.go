@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDigest(t *testing.T) {
+	var (
+		sum          = func(c string) string { return "sha256:" + strings.Repeat(c, 64) }
+		runnerDigest = "ghcr.io/actions/actions-runner@" + sum("a")
+		mirrorDigest = "registry.example.com/actions-runner@" + sum("b")
+		alpineDigest = "alpine@" + sum("d")
+		imageID      = sum("c")
+	)
+
+	tests := []struct {
+		name    string
+		ref     string
+		img     image.InspectResponse
+		err     error
+		want    string
+		wantErr string
+	}{
+		{
+			name: "repo digest of the pulled repository",
+			ref:  "ghcr.io/actions/actions-runner:latest",
+			img:  image.InspectResponse{ID: imageID, RepoDigests: []string{mirrorDigest, runnerDigest}},
+			want: runnerDigest,
+		},
+		{
+			name: "docker hub short name",
+			ref:  "alpine:latest",
+			img:  image.InspectResponse{ID: imageID, RepoDigests: []string{alpineDigest}},
+			want: alpineDigest,
+		},
+		{
+			name: "local image falls back to image id",
+			ref:  "my-runner:dev",
+			img:  image.InspectResponse{ID: imageID},
+			want: imageID,
+		},
+		{
+			name:    "no digest at all",
+			ref:     "my-runner:dev",
+			wantErr: "has no digest",
+		},
+		{
+			name:    "inspect failure",
+			ref:     "ghcr.io/actions/actions-runner:latest",
+			err:     errors.New("no such image"),
+			wantErr: "inspecting image ghcr.io/actions/actions-runner:latest: no such image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inspected string
+			inspect := func(_ context.Context, ref string) (image.InspectResponse, error) {
+				inspected = ref
+				return tt.img, tt.err
+			}
+
+			got, err := resolveDigest(context.Background(), inspect, tt.ref)
+			assert.Equal(t, tt.ref, inspected)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package docker
+
+// diskFree is not supported on this platform.
+func diskFree(string) (uint64, bool) {
+	return 0, false
+}
@@ -4,19 +4,32 @@ package docker
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"maps"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/system"
 	dockerclient "github.com/docker/docker/client"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/terrpan/scaleset/internal/egress"
 	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/retry"
 )
 
 // Config holds Docker-specific settings.
@@ -28,23 +41,218 @@ type Config struct {
 	// Default: "ghcr.io/actions/actions-runner:latest"
 	Image string
 
-	// Dind enables Docker-in-Docker by bind-mounting the host's Docker
-	// socket (/var/run/docker.sock) into each runner container.  This
-	// allows workflows to run Docker commands (docker build, docker
-	// compose, container actions, etc.).
-	//
-	// Security note: the socket gives the runner full access to the
-	// host Docker daemon.  Only enable this if you trust the workflows
-	// that will run on these runners.
+	// Host is the Docker daemon address, e.g. "tcp://10.0.0.5:2376".
+	// Empty uses DOCKER_HOST, else the platform default socket.
+	Host string
+
+	// Dind enables Docker-in-Docker, allowing workflows to run Docker
+	// commands (docker build, docker compose, container actions, etc.).
+	// DindMode selects how.
 	Dind bool
+
+	// DindMode is DindModeSocket (the default) or DindModeSidecar.
+	//
+	// Socket mode bind-mounts the host's Docker socket
+	// (/var/run/docker.sock) into each runner container.  Security note:
+	// the socket gives the runner full access to the host Docker daemon.
+	// Only use it if you trust the workflows that will run on these
+	// runners.
+	//
+	// Sidecar mode starts a privileged DindImage daemon per runner on a
+	// private bridge network and points the runner's DOCKER_HOST at it,
+	// so workflows never reach the host daemon.  The runner's work
+	// directory is a volume shared with the daemon, so workspace bind
+	// mounts work.  The daemon, network and volume are removed with the
+	// runner.  Sidecar mode works with remote daemons.
+	DindMode string
+
+	// DindImage is the daemon image of sidecar mode.  It is pulled at
+	// startup like the runner image.  Default: DefaultDindImage.
+	DindImage string
+
+	// DindCleanup, in socket mode, removes containers the runner started
+	// on the host daemon before the runner itself is removed.
+	// Children are matched by the label "scaleset.parent=<runner name>";
+	// the runner receives it as SCALESET_CHILD_LABEL, and workflows must
+	// pass it through (docker run --label "$SCALESET_CHILD_LABEL" ...):
+	// the host daemon cannot tell which runner started an unlabelled
+	// container, so those are left behind.
+	DindCleanup bool
+
+	// StopTimeout is the grace period given to a runner container to
+	// exit after SIGTERM before it is force-removed.  Zero (the default)
+	// force-removes immediately.
+	StopTimeout time.Duration
+
+	// StartRetries is the number of times ContainerStart is retried when
+	// it fails after a successful ContainerCreate (e.g. transient resource
+	// contention).  The container is not re-created between attempts.
+	// Zero (the default) gives up on the first failure.
+	StartRetries int
+
+	// Init runs Docker's init process (tini) as PID 1 in each runner
+	// container, so signals are forwarded and orphaned child processes
+	// (common with DinD and job scripts that background work) are
+	// reaped instead of accumulating as zombies.
+	Init bool
+
+	// RunID is recorded on every runner container with the
+	// engine.RunIDLabel label, next to engine.ManagedLabel, so the
+	// containers of a crashed process can be found by the cleanup
+	// command.
+	RunID string
+
+	// ScaleSetName is recorded on every container, network and volume
+	// the engine creates with the engine.ScaleSetNameLabel label, next
+	// to engine.ManagedByLabel and, once SetScaleSetID was called,
+	// engine.ScaleSetIDLabel.
+	ScaleSetName string
+
+	// Env holds extra environment variables set in every runner
+	// container, e.g. scale set context (SCALESET_ORG, ...) or an
+	// operator-provided repository allowlist.  Variables the engine sets
+	// itself are never overridden.
+	Env map[string]string
+
+	// PinDigest resolves Image to its digest once it is pulled and creates
+	// every runner container from the digest, so all runners of this
+	// process use the same image even if a tag such as ":latest" moves.
+	PinDigest bool
+
+	// PreloadImages are pulled at startup together with Image, e.g. the
+	// job container or service images workflows use through DinD.
+	PreloadImages []PreloadImage
+
+	// PreloadConcurrency bounds how many images are pulled at once at
+	// startup.  Default: 4.
+	PreloadConcurrency int
+
+	// PreloadTimeout bounds each startup pull, including the runner
+	// image.  Zero (the default) means no timeout.
+	PreloadTimeout time.Duration
+
+	// Limits caps the CPU, memory, process count and /dev/shm size of
+	// each runner container.
+	Limits Limits
+
+	// UsernsMode is the user namespace mode of runner containers, e.g.
+	// "keep-id" on rootless Podman.  Empty uses the daemon's default.
+	UsernsMode string
+
+	// Network is an existing network runner containers join instead of
+	// the default bridge, e.g. a dedicated bridge shared with a cache.
+	// Not used in sidecar mode, where runners join their daemon's.
+	Network string
+
+	// ExtraHosts are hosts file entries ("name:ip", see
+	// ValidateExtraHost) added to every runner container.
+	ExtraHosts []string
+
+	// Mounts are volumes ("source:target[:options]", see ValidateMount)
+	// mounted into every runner container, e.g. a shared Go module
+	// cache.
+	Mounts []string
+
+	// GPUs requests host GPUs for every runner container, as docker run
+	// --gpus (see ParseGPUs).  Nil requests none.
+	GPUs *container.DeviceRequest
+
+	// Egress sets the proxy and extra CAs used to reach a TCP daemon.
+	// Socket connections ignore it.  Image pulls are made by the daemon
+	// and use its own proxy settings.
+	Egress egress.Config
+}
+
+// startRetryDelay is the pause between ContainerStart attempts.
+const startRetryDelay = time.Second
+
+// childLabel is the label key used to associate containers started via
+// the DinD socket with the runner that started them.
+const childLabel = "scaleset.parent"
+
+// newClient connects to the daemon cfg.Host (or DOCKER_HOST) points at.
+func newClient(cfg Config) (*dockerclient.Client, error) {
+	opts := []dockerclient.Opt{
+		dockerclient.FromEnv,
+		dockerclient.WithAPIVersionNegotiation(),
+	}
+	if cfg.Host != "" {
+		opts = append(opts, dockerclient.WithHost(cfg.Host))
+	}
+	if !cfg.Egress.IsZero() {
+		opts = append(opts, withEgress(cfg.Egress))
+	}
+	client, err := dockerclient.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("docker client: %w", err)
+	}
+	// The host is also checked in config validation, but DOCKER_HOST
+	// is only known here.
+	if cfg.Dind && cfg.DindMode != DindModeSidecar && !IsLocalHost(client.DaemonHost()) {
+		_ = client.Close()
+		return nil, fmt.Errorf("dind mounts the local /var/run/docker.sock and needs a local docker daemon, but the daemon is %s", client.DaemonHost())
+	}
+	return client, nil
+}
+
+// withEgress applies eg to the client's transport once the host is
+// known.  The proxy is only set for TCP daemons.  The roots are only
+// used when DOCKER_CERT_PATH did not already pin the daemon's CA.
+func withEgress(eg egress.Config) dockerclient.Opt {
+	return func(c *dockerclient.Client) error {
+		t, ok := c.HTTPClient().Transport.(*http.Transport)
+		if !ok {
+			return fmt.Errorf("docker client: cannot apply network settings to transport %T", c.HTTPClient().Transport)
+		}
+		roots, err := eg.RootCAs()
+		if err != nil {
+			return fmt.Errorf("docker client: %w", err)
+		}
+		if strings.HasPrefix(c.DaemonHost(), "tcp://") {
+			t.Proxy = eg.Proxy()
+		}
+		if roots != nil {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			if t.TLSClientConfig.RootCAs == nil {
+				t.TLSClientConfig.RootCAs = roots
+			}
+		}
+		return nil
+	}
 }
 
 // Engine manages GitHub Actions runners as Docker containers.
 type Engine struct {
-	client *dockerclient.Client
-	image  string
-	dind   bool
-	logger *slog.Logger
+	client      *dockerclient.Client
+	image       string
+	dind        bool
+	dindSidecar bool
+	dindImage   string
+	dindCleanup bool
+	init        bool
+	limits      Limits
+	usernsMode  string
+	network     string
+	extraHosts  []string
+	mounts      []string
+	gpus        *container.DeviceRequest
+	labels      map[string]string
+	stopTimeout time.Duration
+	extraEnv    []string // sorted KEY=value pairs from Config.Env
+	logger      *slog.Logger
+
+	// scaleSetName and scaleSetID identify the scale set on every
+	// resource; see Config.ScaleSetName and SetScaleSetID.
+	scaleSetName string
+	scaleSetID   atomic.Int64
+
+	startRetries    int
+	startRetryDelay time.Duration
+	// containerStart starts a created container.  It is a field so tests
+	// can inject transient failures; New sets it to client.ContainerStart.
+	containerStart func(ctx context.Context, id string, opts container.StartOptions) error
 
 	mu         sync.Mutex
 	containers map[string]string // name -> containerID
@@ -53,47 +261,82 @@ type Engine struct {
 	tracer trace.Tracer
 }
 
-// Compile-time check that Engine satisfies the engine.Engine interface.
-var _ engine.Engine = (*Engine)(nil)
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine             = (*Engine)(nil)
+	_ engine.Checker            = (*Engine)(nil)
+	_ engine.RunnerFinder       = (*Engine)(nil)
+	_ engine.RunnerLister       = (*Engine)(nil)
+	_ engine.ScaleSetIdentifier = (*Engine)(nil)
+)
 
 // New creates a Docker engine, connects to the daemon, and pulls the
-// runner image so it is available for container creation.
+// runner image and any preload images so they are available for
+// container creation.  A failed pull of the runner image or of a
+// required preload image fails New.
 func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Engine, error) {
 	if cfg.Image == "" {
 		cfg.Image = "ghcr.io/actions/actions-runner:latest"
 	}
-
-	client, err := dockerclient.NewClientWithOpts(
-		dockerclient.FromEnv,
-		dockerclient.WithAPIVersionNegotiation(),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("docker client: %w", err)
+	if cfg.DindImage == "" {
+		cfg.DindImage = DefaultDindImage
 	}
+	sidecar := cfg.Dind && cfg.DindMode == DindModeSidecar
 
-	logger.Info("pulling runner image", slog.String("image", cfg.Image))
-
-	pull, err := client.ImagePull(ctx, cfg.Image, image.PullOptions{})
+	client, err := newClient(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("image pull %s: %w", cfg.Image, err)
+		return nil, err
 	}
-	// Drain and close the pull stream so the image is fully downloaded.
-	if _, err := io.ReadAll(pull); err != nil {
-		return nil, fmt.Errorf("reading image pull response: %w", err)
+
+	// The runner image is always required.
+	images := append([]PreloadImage{{Image: cfg.Image, Required: true}}, cfg.PreloadImages...)
+	if sidecar {
+		images = append(images, PreloadImage{Image: cfg.DindImage, Required: true})
 	}
-	if err := pull.Close(); err != nil {
-		return nil, fmt.Errorf("closing image pull stream: %w", err)
+	if err := preloadImages(ctx, clientPull(client), images, cfg.PreloadConcurrency, cfg.PreloadTimeout, logger); err != nil {
+		return nil, err
 	}
 
-	logger.Info("runner image ready", slog.String("image", cfg.Image))
+	if cfg.PinDigest {
+		pinned, err := resolveDigest(ctx, func(ctx context.Context, ref string) (image.InspectResponse, error) {
+			return client.ImageInspect(ctx, ref)
+		}, cfg.Image)
+		if err != nil {
+			return nil, fmt.Errorf("pinning runner image: %w", err)
+		}
+		logger.Info("runner image pinned to digest",
+			slog.String("image", cfg.Image),
+			slog.String("digest", pinned),
+		)
+		cfg.Image = pinned
+	}
 
 	return &Engine{
-		client:     client,
-		image:      cfg.Image,
-		dind:       cfg.Dind,
-		logger:     logger,
-		containers: make(map[string]string),
-		tracer:     otel.Tracer("scaleset/engine/docker"),
+		client:      client,
+		image:       cfg.Image,
+		dind:        cfg.Dind,
+		dindSidecar: sidecar,
+		dindImage:   cfg.DindImage,
+		dindCleanup: cfg.DindCleanup,
+		init:        cfg.Init,
+		limits:      cfg.Limits,
+		usernsMode:  cfg.UsernsMode,
+		network:     cfg.Network,
+		extraHosts:  cfg.ExtraHosts,
+		mounts:      cfg.Mounts,
+		gpus:        cfg.GPUs,
+		labels:      engine.RunnerLabels(cfg.RunID),
+		stopTimeout: cfg.StopTimeout,
+		extraEnv:    envList(cfg.Env),
+		logger:      logger,
+		containers:  make(map[string]string),
+		tracer:      otel.Tracer("scaleset/engine/docker"),
+
+		startRetries:    cfg.StartRetries,
+		startRetryDelay: startRetryDelay,
+		containerStart:  client.ContainerStart,
+
+		scaleSetName: cfg.ScaleSetName,
 	}, nil
 }
 
@@ -107,6 +350,8 @@ func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string)
 		attribute.String("runner.name", name),
 		attribute.String("docker.image", e.image),
 		attribute.Bool("docker.dind", e.dind),
+		attribute.Bool("docker.dind_sidecar", e.dindSidecar),
+		attribute.Bool("docker.gpus", e.gpus != nil),
 	)
 
 	env := []string{
@@ -115,17 +360,29 @@ func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string)
 
 	// When DinD is enabled, run as root for cross-platform socket access.
 	// On Linux, the docker group has write permission; on macOS Docker
-	// Desktop, only the owner does.  Running as root works on both.
+	// Desktop, only the owner does.  Running as root works on both.  In
+	// sidecar mode the shared work volume is root-owned; root in the
+	// runner container grants nothing on the host there.
 	user := "runner"
 	if e.dind {
 		user = "root"
 	}
 
 	var hostCfg *container.HostConfig
-	if e.dind {
+	switch {
+	case e.dindSidecar:
+		if err := e.startSidecar(ctx, name); err != nil {
+			return "", err
+		}
+		env = append(env, sidecarRunnerEnv()...)
+		env = append(env, "RUNNER_ALLOW_RUNASROOT=1")
+		hostCfg = &container.HostConfig{}
+		sidecarRunnerHost(name, hostCfg)
+	case e.dind:
 		env = append(env,
 			"DOCKER_HOST=unix:///var/run/docker.sock",
 			"RUNNER_ALLOW_RUNASROOT=1",
+			fmt.Sprintf("SCALESET_CHILD_LABEL=%s=%s", childLabel, name),
 		)
 		hostCfg = &container.HostConfig{
 			Binds: []string{"/var/run/docker.sock:/var/run/docker.sock"},
@@ -135,13 +392,56 @@ func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string)
 		)
 	}
 
+	if e.init {
+		if hostCfg == nil {
+			hostCfg = &container.HostConfig{}
+		}
+		init := true
+		hostCfg.Init = &init
+	}
+
+	if !e.limits.isZero() {
+		if hostCfg == nil {
+			hostCfg = &container.HostConfig{}
+		}
+		e.limits.apply(hostCfg)
+	}
+
+	if e.usernsMode != "" {
+		if hostCfg == nil {
+			hostCfg = &container.HostConfig{}
+		}
+		hostCfg.UsernsMode = container.UsernsMode(e.usernsMode)
+	}
+
+	if e.network != "" || len(e.extraHosts) > 0 || len(e.mounts) > 0 {
+		if hostCfg == nil {
+			hostCfg = &container.HostConfig{}
+		}
+		if e.network != "" && !e.dindSidecar {
+			hostCfg.NetworkMode = container.NetworkMode(e.network)
+		}
+		hostCfg.ExtraHosts = append(hostCfg.ExtraHosts, e.extraHosts...)
+		hostCfg.Binds = append(hostCfg.Binds, e.mounts...)
+	}
+
+	if e.gpus != nil {
+		if hostCfg == nil {
+			hostCfg = &container.HostConfig{}
+		}
+		hostCfg.DeviceRequests = append(hostCfg.DeviceRequests, *e.gpus)
+	}
+
+	env = mergeEnv(env, e.extraEnv)
+
 	resp, err := e.client.ContainerCreate(
 		ctx,
 		&container.Config{
-			Image: e.image,
-			User:  user,
-			Cmd:   []string{"/home/runner/run.sh"},
-			Env:   env,
+			Image:  e.image,
+			User:   user,
+			Cmd:    []string{"/home/runner/run.sh"},
+			Env:    env,
+			Labels: e.runnerLabels(),
 		},
 		hostCfg,
 		nil, // networking config
@@ -149,12 +449,18 @@ func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string)
 		name,
 	)
 	if err != nil {
+		if e.dindSidecar {
+			_ = e.removeSidecar(ctx, name)
+		}
 		return "", fmt.Errorf("container create %s: %w", name, err)
 	}
 
-	if err := e.client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+	if err := e.startContainer(ctx, name, resp.ID); err != nil {
 		// Best-effort cleanup of the created-but-not-started container.
 		_ = e.client.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+		if e.dindSidecar {
+			_ = e.removeSidecar(ctx, name)
+		}
 		return "", fmt.Errorf("container start %s: %w", name, err)
 	}
 
@@ -182,24 +488,32 @@ func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
 
 	e.logger.Info("destroying runner", slog.String("containerID", id))
 
-	if err := e.client.ContainerRemove(ctx, id, container.RemoveOptions{Force: true}); err != nil {
-		return fmt.Errorf("container remove %s: %w", id, err)
-	}
-
-	// Remove from tracking map.
 	e.mu.Lock()
-	for name, cid := range e.containers {
+	var name string
+	for n, cid := range e.containers {
 		if cid == id {
-			delete(e.containers, name)
+			name = n
 			break
 		}
 	}
 	e.mu.Unlock()
 
+	if err := e.removeContainer(ctx, name, id); err != nil {
+		return err
+	}
+
+	// Remove from tracking map.
+	if name != "" {
+		e.mu.Lock()
+		delete(e.containers, name)
+		e.mu.Unlock()
+	}
+
 	return nil
 }
 
-// Shutdown force-removes every container this engine is tracking.
+// Shutdown force-removes every container this engine is tracking and
+// returns every failure joined.
 func (e *Engine) Shutdown(ctx context.Context) error {
 	ctx, span := e.tracer.Start(ctx, "engine.docker.Shutdown")
 	defer span.End()
@@ -213,21 +527,19 @@ func (e *Engine) Shutdown(ctx context.Context) error {
 
 	span.SetAttributes(attribute.Int("docker.containers_count", len(snapshot)))
 
-	var firstErr error
+	var errs []error
 	for name, id := range snapshot {
 		e.logger.Info("shutdown: removing runner",
 			slog.String("name", name),
 			slog.String("containerID", id),
 		)
-		if err := e.client.ContainerRemove(ctx, id, container.RemoveOptions{Force: true}); err != nil {
+		if err := e.removeContainer(ctx, name, id); err != nil {
 			e.logger.Error("shutdown: failed to remove runner",
 				slog.String("name", name),
 				slog.String("containerID", id),
 				slog.String("error", err.Error()),
 			)
-			if firstErr == nil {
-				firstErr = err
-			}
+			errs = append(errs, fmt.Errorf("removing %s: %w", name, err))
 		}
 	}
 
@@ -235,5 +547,261 @@ func (e *Engine) Shutdown(ctx context.Context) error {
 	clear(e.containers)
 	e.mu.Unlock()
 
-	return firstErr
+	return errors.Join(errs...)
+}
+
+// mergeEnv appends extra to env, skipping variables env already sets so
+// the engine's own values (JIT config, DOCKER_HOST, ...) cannot be
+// overridden.
+func mergeEnv(env, extra []string) []string {
+	set := make(map[string]bool, len(env))
+	for _, kv := range env {
+		k, _, _ := strings.Cut(kv, "=")
+		set[k] = true
+	}
+	for _, kv := range extra {
+		k, _, _ := strings.Cut(kv, "=")
+		if !set[k] {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// envList converts env into sorted KEY=value pairs.
+func envList(env map[string]string) []string {
+	list := make([]string, 0, len(env))
+	for k, v := range env {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// startContainer starts a created container, retrying up to startRetries
+// times at a constant startRetryDelay before returning the last error.
+func (e *Engine) startContainer(ctx context.Context, name, id string) error {
+	return retry.Do(ctx, func() error {
+		return e.containerStart(ctx, id, container.StartOptions{})
+	}, retry.Options{
+		Retries:    e.startRetries,
+		Base:       e.startRetryDelay,
+		Multiplier: 1,
+		OnRetry: func(attempt int, err error, _ time.Duration) {
+			e.logger.Warn("container start failed, retrying",
+				slog.String("name", name),
+				slog.Int("attempt", attempt),
+				slog.String("error", err.Error()),
+			)
+		},
+	})
+}
+
+// Check implements engine.Checker.  It pings the daemon and reports its
+// version, the number of tracked runners and, when the daemon is local,
+// the free space on its data root.
+func (e *Engine) Check(ctx context.Context) (map[string]string, error) {
+	e.mu.Lock()
+	tracked := len(e.containers)
+	e.mu.Unlock()
+
+	diags := map[string]string{
+		"docker.runners": strconv.Itoa(tracked),
+	}
+
+	version, err := e.client.ServerVersion(ctx)
+	if err != nil {
+		return diags, fmt.Errorf("docker daemon unreachable: %w", err)
+	}
+	diags["docker.version"] = version.Version
+	diags["docker.api_version"] = version.APIVersion
+
+	// The data root is a path on the daemon host, so free space is only
+	// meaningful when the daemon runs on this machine.
+	if strings.HasPrefix(e.client.DaemonHost(), "unix://") {
+		info, err := e.client.Info(ctx)
+		if err == nil {
+			if free, ok := diskFree(info.DockerRootDir); ok {
+				diags["docker.disk_free_bytes"] = strconv.FormatUint(free, 10)
+			}
+		}
+	}
+
+	return diags, nil
+}
+
+// Info returns the daemon's system information.
+func (e *Engine) Info(ctx context.Context) (system.Info, error) {
+	return e.client.Info(ctx)
+}
+
+// FindRunner implements engine.RunnerFinder.  Container names are unique
+// per daemon, so the runner name is looked up directly.  A running
+// container is adopted; one that exists but is not running (created but
+// never started, or already exited) cannot serve a job and is removed so
+// the name can be reused.
+func (e *Engine) FindRunner(ctx context.Context, key string) (string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.docker.FindRunner")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("runner.name", key))
+
+	info, err := e.client.ContainerInspect(ctx, key)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			// A sidecar left behind would block the name's reuse.
+			if e.dindSidecar {
+				if err := e.removeSidecar(ctx, key); err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		}
+		return "", fmt.Errorf("container inspect %s: %w", key, err)
+	}
+
+	if info.State == nil || !info.State.Running {
+		e.logger.Info("removing leftover runner container that is not running",
+			slog.String("name", key),
+			slog.String("containerID", info.ID),
+		)
+		if err := e.removeContainer(ctx, key, info.ID); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+
+	e.mu.Lock()
+	e.containers[key] = info.ID
+	e.mu.Unlock()
+
+	span.SetAttributes(attribute.String("docker.container_id", info.ID))
+	e.logger.Info("adopted existing runner container",
+		slog.String("name", key),
+		slog.String("containerID", info.ID),
+	)
+	return info.ID, nil
+}
+
+// SetScaleSetID implements engine.ScaleSetIdentifier.
+func (e *Engine) SetScaleSetID(id int) {
+	e.scaleSetID.Store(int64(id))
+}
+
+// scaleSetLabels returns the engine.ScaleSetLabels of the scale set the
+// engine serves.
+func (e *Engine) scaleSetLabels() map[string]string {
+	return engine.ScaleSetLabels(e.scaleSetName, int(e.scaleSetID.Load()))
+}
+
+// runnerLabels returns the labels of a runner container: the run labels
+// and the scale set's identity.
+func (e *Engine) runnerLabels() map[string]string {
+	labels := e.scaleSetLabels()
+	maps.Copy(labels, e.labels)
+	return labels
+}
+
+// ListRunners implements engine.RunnerLister using a container label
+// filter.  Stopped containers are included so they are cleaned up too.
+func (e *Engine) ListRunners(ctx context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	args := filters.NewArgs()
+	for k, v := range labels {
+		args.Add("label", fmt.Sprintf("%s=%s", k, v))
+	}
+	containers, err := e.client.ContainerList(ctx, container.ListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("container list: %w", err)
+	}
+
+	runners := make([]engine.ListedRunner, 0, len(containers))
+	for _, c := range containers {
+		var name string
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		runners = append(runners, engine.ListedRunner{ID: c.ID, Name: name, Labels: c.Labels})
+	}
+	return runners, nil
+}
+
+// removeContainer stops the runner container (honouring StopTimeout),
+// cleans up its DinD children when enabled, force-removes it and then
+// removes its DinD sidecar.
+func (e *Engine) removeContainer(ctx context.Context, name, id string) error {
+	// A runner found by the cleanup command, the reconciler or a
+	// restarted process is not tracked; its name is needed to find the
+	// sidecar or the children.
+	if (e.dindCleanup || e.dindSidecar) && name == "" {
+		info, err := e.client.ContainerInspect(ctx, id)
+		if err != nil && !cerrdefs.IsNotFound(err) {
+			return fmt.Errorf("container inspect %s: %w", id, err)
+		}
+		if err == nil {
+			name = strings.TrimPrefix(info.Name, "/")
+		}
+	}
+
+	if e.stopTimeout > 0 {
+		timeout := int(e.stopTimeout.Seconds())
+		if err := e.client.ContainerStop(ctx, id, container.StopOptions{Timeout: &timeout}); err != nil && !cerrdefs.IsNotFound(err) {
+			// Not fatal -- the force-remove below kills it anyway.
+			e.logger.Warn("graceful stop failed, force-removing",
+				slog.String("containerID", id),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if e.dind && !e.dindSidecar && e.dindCleanup && name != "" {
+		e.removeChildren(ctx, name)
+	}
+
+	if err := e.client.ContainerRemove(ctx, id, container.RemoveOptions{Force: true}); err != nil {
+		// Already gone (double destroy, removed by hand): the desired
+		// end state, so destroy stays idempotent like the GCP engine.
+		if cerrdefs.IsNotFound(err) {
+			e.logger.Info("runner container already removed",
+				slog.String("containerID", id),
+			)
+		} else {
+			return fmt.Errorf("container remove %s: %w", id, err)
+		}
+	}
+
+	if e.dindSidecar && name != "" {
+		return e.removeSidecar(ctx, name)
+	}
+	return nil
+}
+
+// removeChildren force-removes every container labelled as a child of
+// the named runner.  Failures are logged but never block removal of the
+// runner itself.
+func (e *Engine) removeChildren(ctx context.Context, name string) {
+	children, err := e.client.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", childLabel, name))),
+	})
+	if err != nil {
+		e.logger.Warn("dind cleanup: listing child containers failed",
+			slog.String("name", name),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, c := range children {
+		e.logger.Info("dind cleanup: removing child container",
+			slog.String("name", name),
+			slog.String("containerID", c.ID),
+		)
+		if err := e.client.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true}); err != nil {
+			e.logger.Warn("dind cleanup: failed to remove child container",
+				slog.String("name", name),
+				slog.String("containerID", c.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
 }
@@ -4,19 +4,24 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"testing"
 	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
 	dockerclient "github.com/docker/docker/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.opentelemetry.io/otel"
+
+	"github.com/terrpan/scaleset/internal/engine"
 )
 
 // DockerEngineSuite tests the Docker engine against a real Docker daemon.
@@ -87,8 +92,12 @@ func (s *DockerEngineSuite) newTestEngine() *Engine {
 		image:      s.testImage,
 		dind:       false,
 		logger:     s.logger,
+		labels:     engine.RunnerLabels("test-run"),
 		containers: make(map[string]string),
 		tracer:     otel.Tracer("test"),
+
+		startRetryDelay: 10 * time.Millisecond,
+		containerStart:  s.docker.ContainerStart,
 	}
 }
 
@@ -154,6 +163,23 @@ func (s *DockerEngineSuite) TestNew_PullsImage() {
 	assert.Equal(s.T(), s.testImage, e.image)
 }
 
+func (s *DockerEngineSuite) TestNew_PinsImageDigest() {
+	e, err := New(s.ctx, Config{
+		Image:     s.testImage,
+		PinDigest: true,
+	}, s.logger)
+	require.NoError(s.T(), err)
+
+	img, err := s.docker.ImageInspect(s.ctx, s.testImage)
+	require.NoError(s.T(), err)
+	assert.Contains(s.T(), img.RepoDigests, e.image, "runners are created from the resolved digest")
+
+	// The digest reference resolves to the same local image.
+	pinned, err := s.docker.ImageInspect(s.ctx, e.image)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), img.ID, pinned.ID)
+}
+
 // ---------------------------------------------------------------------------
 // DestroyRunner: container lifecycle
 // ---------------------------------------------------------------------------
@@ -252,11 +278,10 @@ func (s *DockerEngineSuite) TestDestroyRunner_DoubleDestroy() {
 	err := e.DestroyRunner(s.ctx, id)
 	require.NoError(s.T(), err)
 
-	// Second destroy: Docker returns an error for non-existent container.
-	// This documents the current behavior -- Docker's DestroyRunner
-	// is not idempotent (unlike GCP which handles 404 gracefully).
+	// Second destroy: the container is already gone, which is treated as
+	// success so DestroyRunner is idempotent (like GCP's 404 handling).
 	err = e.DestroyRunner(s.ctx, id)
-	assert.Error(s.T(), err, "Docker force-remove of non-existent container returns error")
+	assert.NoError(s.T(), err, "destroying an already-removed container should succeed")
 }
 
 // ---------------------------------------------------------------------------
@@ -298,6 +323,134 @@ func (s *DockerEngineSuite) TestDindMode_SocketMount() {
 	assert.True(s.T(), hasRunAsRoot, "DinD should set RUNNER_ALLOW_RUNASROOT")
 }
 
+func (s *DockerEngineSuite) TestDindCleanup_RemovesChildContainers() {
+	e := s.newTestEngine()
+	e.dind = true
+	e.dindCleanup = true
+	defer e.Shutdown(s.ctx)
+
+	id := s.startTestContainer(e, "test-dind-parent", true)
+
+	// Simulate a workflow starting a sibling container through the
+	// mounted socket, labelled the way SCALESET_CHILD_LABEL suggests.
+	child, err := s.docker.ContainerCreate(
+		s.ctx,
+		&container.Config{
+			Image:  s.testImage,
+			Cmd:    []string{"sleep", "300"},
+			Labels: map[string]string{childLabel: "test-dind-parent"},
+		},
+		nil, nil, nil,
+		"test-dind-child",
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.docker.ContainerStart(s.ctx, child.ID, container.StartOptions{}))
+
+	// An unrelated container must survive the cleanup.
+	other, err := s.docker.ContainerCreate(
+		s.ctx,
+		&container.Config{Image: s.testImage, Cmd: []string{"sleep", "300"}},
+		nil, nil, nil,
+		"test-dind-unrelated",
+	)
+	require.NoError(s.T(), err)
+	defer s.docker.ContainerRemove(s.ctx, other.ID, container.RemoveOptions{Force: true})
+
+	err = e.DestroyRunner(s.ctx, id)
+	require.NoError(s.T(), err)
+
+	assert.False(s.T(), s.containerExists(id))
+	assert.False(s.T(), s.containerExists(child.ID), "child container should be removed with its runner")
+	assert.True(s.T(), s.containerExists(other.ID), "unrelated container must not be touched")
+}
+
+func (s *DockerEngineSuite) TestDindCleanup_UntrackedRunnerRemovesChildren() {
+	e := s.newTestEngine()
+	e.dind = true
+	e.dindCleanup = true
+	defer e.Shutdown(s.ctx)
+
+	// A runner left by a previous process: the engine does not track it.
+	id := s.startTestContainer(e, "test-dind-untracked", true)
+	e.mu.Lock()
+	delete(e.containers, "test-dind-untracked")
+	e.mu.Unlock()
+
+	child, err := s.docker.ContainerCreate(
+		s.ctx,
+		&container.Config{
+			Image:  s.testImage,
+			Cmd:    []string{"sleep", "300"},
+			Labels: map[string]string{childLabel: "test-dind-untracked"},
+		},
+		nil, nil, nil,
+		"test-dind-untracked-child",
+	)
+	require.NoError(s.T(), err)
+	defer s.docker.ContainerRemove(s.ctx, child.ID, container.RemoveOptions{Force: true})
+
+	err = e.DestroyRunner(s.ctx, id)
+	require.NoError(s.T(), err)
+
+	assert.False(s.T(), s.containerExists(id))
+	assert.False(s.T(), s.containerExists(child.ID), "children of an untracked runner are found by its name")
+}
+
+func (s *DockerEngineSuite) TestDindSidecar_RemovedWithRunner() {
+	e := s.newTestEngine()
+	e.dind = true
+	e.dindSidecar = true
+	e.dindImage = s.testImage // stands in for docker:dind
+	defer e.Shutdown(s.ctx)
+
+	require.NoError(s.T(), e.startSidecar(s.ctx, "test-sidecar"))
+
+	// The runner joins the sidecar's network, where the daemon is
+	// reachable under its alias.
+	hostCfg := &container.HostConfig{}
+	sidecarRunnerHost("test-sidecar", hostCfg)
+	resp, err := s.docker.ContainerCreate(
+		s.ctx,
+		&container.Config{Image: s.testImage, Cmd: []string{"sleep", "300"}},
+		hostCfg,
+		nil, nil,
+		"test-sidecar",
+	)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.docker.ContainerStart(s.ctx, resp.ID, container.StartOptions{}))
+
+	sidecar, err := s.docker.ContainerInspect(s.ctx, sidecarName("test-sidecar"))
+	require.NoError(s.T(), err)
+	assert.True(s.T(), sidecar.HostConfig.Privileged)
+	assert.Contains(s.T(), sidecar.NetworkSettings.Networks[sidecarName("test-sidecar")].Aliases, sidecarHost)
+
+	// Untracked, as for the cleanup command: the sidecar is found from
+	// the runner container's name.
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, resp.ID))
+
+	assert.False(s.T(), s.containerExists(resp.ID))
+	assert.False(s.T(), s.containerExists(sidecar.ID), "sidecar should be removed with its runner")
+	_, err = s.docker.NetworkInspect(s.ctx, sidecarName("test-sidecar"), network.InspectOptions{})
+	assert.True(s.T(), cerrdefs.IsNotFound(err), "sidecar network should be removed")
+	_, err = s.docker.VolumeInspect(s.ctx, sidecarVolume("test-sidecar"))
+	assert.True(s.T(), cerrdefs.IsNotFound(err), "work volume should be removed")
+
+	// Removing again is a no-op.
+	assert.NoError(s.T(), e.removeSidecar(s.ctx, "test-sidecar"))
+}
+
+func (s *DockerEngineSuite) TestStopTimeout_GracefulStop() {
+	e := s.newTestEngine()
+	e.stopTimeout = 2 * time.Second
+	defer e.Shutdown(s.ctx)
+
+	id := s.startTestContainer(e, "test-stop-grace", false)
+
+	err := e.DestroyRunner(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), s.containerExists(id))
+}
+
 func (s *DockerEngineSuite) TestNonDindMode_NoSocketMount() {
 	e := s.newTestEngine()
 	defer e.Shutdown(s.ctx)
@@ -363,3 +516,258 @@ func (s *DockerEngineSuite) TestShutdown_MixedState() {
 	assert.Empty(s.T(), e.containers)
 	e.mu.Unlock()
 }
+
+// ---------------------------------------------------------------------------
+// Readiness check
+// ---------------------------------------------------------------------------
+
+func (s *DockerEngineSuite) TestCheck_ReportsDaemonDiagnostics() {
+	e := s.newTestEngine()
+	_ = s.startTestContainer(e, "test-check-0", false)
+
+	diags, err := e.Check(s.ctx)
+	require.NoError(s.T(), err)
+
+	assert.NotEmpty(s.T(), diags["docker.version"])
+	assert.NotEmpty(s.T(), diags["docker.api_version"])
+	assert.Equal(s.T(), "1", diags["docker.runners"])
+}
+
+// ---------------------------------------------------------------------------
+// ContainerStart retry
+// ---------------------------------------------------------------------------
+
+func (s *DockerEngineSuite) TestStartRunner_RetriesTransientStartFailure() {
+	e := s.newTestEngine()
+	e.startRetries = 2
+	defer e.Shutdown(s.ctx)
+
+	// Fail the first start, then report success without starting: the test
+	// image has no /home/runner/run.sh, so a real start would fail anyway.
+	calls := 0
+	e.containerStart = func(context.Context, string, container.StartOptions) error {
+		calls++
+		if calls == 1 {
+			return errors.New("transient: resource temporarily unavailable")
+		}
+		return nil
+	}
+
+	id, err := e.StartRunner(s.ctx, "test-start-retry", "jit")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, calls)
+	assert.True(s.T(), s.containerExists(id), "created container should be reused, not re-created")
+}
+
+func (s *DockerEngineSuite) TestStartRunner_RemovesContainerAfterRetriesExhausted() {
+	e := s.newTestEngine()
+	e.startRetries = 2
+
+	calls := 0
+	e.containerStart = func(context.Context, string, container.StartOptions) error {
+		calls++
+		return errors.New("transient: resource temporarily unavailable")
+	}
+
+	_, err := e.StartRunner(s.ctx, "test-start-exhausted", "jit")
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), 3, calls)
+	assert.False(s.T(), s.containerExists("test-start-exhausted"))
+	assert.Empty(s.T(), e.containers)
+}
+
+// ---------------------------------------------------------------------------
+// Extra runner environment
+// ---------------------------------------------------------------------------
+
+func (s *DockerEngineSuite) TestStartRunner_InjectsExtraEnv() {
+	e := s.newTestEngine()
+	e.extraEnv = envList(map[string]string{
+		"SCALESET_ORG":                   "my-org",
+		"SCALESET_ALLOWED_REPOS":         "my-org/a,my-org/b",
+		"ACTIONS_RUNNER_INPUT_JITCONFIG": "must-not-override",
+	})
+	// Only the created container's config matters here.
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+
+	id, err := e.StartRunner(s.ctx, "test-extra-env", "real-jit")
+	require.NoError(s.T(), err)
+
+	info, err := s.docker.ContainerInspect(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.Contains(s.T(), info.Config.Env, "SCALESET_ORG=my-org")
+	assert.Contains(s.T(), info.Config.Env, "SCALESET_ALLOWED_REPOS=my-org/a,my-org/b")
+	assert.Contains(s.T(), info.Config.Env, "ACTIONS_RUNNER_INPUT_JITCONFIG=real-jit")
+	assert.NotContains(s.T(), info.Config.Env, "ACTIONS_RUNNER_INPUT_JITCONFIG=must-not-override")
+}
+
+// ---------------------------------------------------------------------------
+// FindRunner
+// ---------------------------------------------------------------------------
+
+func (s *DockerEngineSuite) TestFindRunner_AdoptsRunningContainer() {
+	id := s.startTestContainer(s.newTestEngine(), "test-find-running", false)
+
+	// A second engine has no record of the container, as after a start
+	// whose response was lost.
+	e := s.newTestEngine()
+	defer e.Shutdown(s.ctx)
+
+	found, err := e.FindRunner(s.ctx, "test-find-running")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), id, found)
+	assert.Equal(s.T(), id, e.containers["test-find-running"])
+}
+
+func (s *DockerEngineSuite) TestFindRunner_NotFound() {
+	e := s.newTestEngine()
+
+	found, err := e.FindRunner(s.ctx, "test-find-missing")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), found)
+}
+
+func (s *DockerEngineSuite) TestFindRunner_RemovesCreatedButNotStarted() {
+	resp, err := s.docker.ContainerCreate(s.ctx,
+		&container.Config{Image: s.testImage, Cmd: []string{"sleep", "300"}},
+		nil, nil, nil, "test-find-created")
+	require.NoError(s.T(), err)
+
+	e := s.newTestEngine()
+	found, err := e.FindRunner(s.ctx, "test-find-created")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), found)
+	assert.False(s.T(), s.containerExists(resp.ID))
+	assert.Empty(s.T(), e.containers)
+}
+
+// ---------------------------------------------------------------------------
+// Init process
+// ---------------------------------------------------------------------------
+
+func (s *DockerEngineSuite) TestStartRunner_SetsInit() {
+	e := s.newTestEngine()
+	e.init = true
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+
+	id, err := e.StartRunner(s.ctx, "test-init", "jit")
+	require.NoError(s.T(), err)
+
+	info, err := s.docker.ContainerInspect(s.ctx, id)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), info.HostConfig.Init)
+	assert.True(s.T(), *info.HostConfig.Init)
+}
+
+func (s *DockerEngineSuite) TestStartRunner_InitDisabledByDefault() {
+	e := s.newTestEngine()
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+
+	id, err := e.StartRunner(s.ctx, "test-no-init", "jit")
+	require.NoError(s.T(), err)
+
+	info, err := s.docker.ContainerInspect(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), info.HostConfig.Init != nil && *info.HostConfig.Init)
+}
+
+// ---------------------------------------------------------------------------
+// Resource limits
+// ---------------------------------------------------------------------------
+
+func (s *DockerEngineSuite) TestStartRunner_SetsLimits() {
+	e := s.newTestEngine()
+	e.limits = Limits{CPUs: 0.5, Memory: 256 << 20, PidsLimit: 512, ShmSize: 128 << 20}
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+
+	id, err := e.StartRunner(s.ctx, "test-limits", "jit")
+	require.NoError(s.T(), err)
+
+	info, err := s.docker.ContainerInspect(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(500_000_000), info.HostConfig.NanoCPUs)
+	assert.Equal(s.T(), int64(256<<20), info.HostConfig.Memory)
+	require.NotNil(s.T(), info.HostConfig.PidsLimit)
+	assert.Equal(s.T(), int64(512), *info.HostConfig.PidsLimit)
+	assert.Equal(s.T(), int64(128<<20), info.HostConfig.ShmSize)
+}
+
+func (s *DockerEngineSuite) TestStartRunner_NetworkHostsAndMounts() {
+	_, err := s.docker.NetworkCreate(s.ctx, "scaleset-test-net", network.CreateOptions{Driver: "bridge"})
+	require.NoError(s.T(), err)
+	defer func() { _ = s.docker.NetworkRemove(context.Background(), "scaleset-test-net") }()
+
+	e := s.newTestEngine()
+	e.network = "scaleset-test-net"
+	e.extraHosts = []string{"cache.internal:10.0.0.5"}
+	e.mounts = []string{"scaleset-test-cache:/cache:ro"}
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+	defer func() { _ = s.docker.VolumeRemove(context.Background(), "scaleset-test-cache", true) }()
+
+	id, err := e.StartRunner(s.ctx, "test-network", "jit")
+	require.NoError(s.T(), err)
+
+	info, err := s.docker.ContainerInspect(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), container.NetworkMode("scaleset-test-net"), info.HostConfig.NetworkMode)
+	assert.Equal(s.T(), []string{"cache.internal:10.0.0.5"}, info.HostConfig.ExtraHosts)
+	assert.Equal(s.T(), []string{"scaleset-test-cache:/cache:ro"}, info.HostConfig.Binds)
+}
+
+func (s *DockerEngineSuite) TestStartRunner_SetsManagedLabels() {
+	e := s.newTestEngine()
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+
+	id, err := e.StartRunner(s.ctx, "test-labels", "jit")
+	require.NoError(s.T(), err)
+
+	info, err := s.docker.ContainerInspect(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "true", info.Config.Labels[engine.ManagedLabel])
+	assert.Equal(s.T(), "test-run", info.Config.Labels[engine.RunIDLabel])
+	assert.Equal(s.T(), engine.ManagedBy, info.Config.Labels[engine.ManagedByLabel])
+}
+
+func (s *DockerEngineSuite) TestStartRunner_SetsScaleSetLabels() {
+	e := s.newTestEngine()
+	e.scaleSetName = "test-runners"
+	e.SetScaleSetID(42)
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+
+	id, err := e.StartRunner(s.ctx, "test-scale-set-labels", "jit")
+	require.NoError(s.T(), err)
+
+	info, err := s.docker.ContainerInspect(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "test-runners", info.Config.Labels[engine.ScaleSetNameLabel])
+	assert.Equal(s.T(), "42", info.Config.Labels[engine.ScaleSetIDLabel])
+}
+
+func (s *DockerEngineSuite) TestListRunners_FiltersByRunID() {
+	e := s.newTestEngine()
+	e.containerStart = func(context.Context, string, container.StartOptions) error { return nil }
+	defer e.Shutdown(s.ctx)
+
+	other := s.newTestEngine()
+	other.labels = engine.RunnerLabels("other-run")
+	other.containerStart = e.containerStart
+	defer other.Shutdown(s.ctx)
+
+	id, err := e.StartRunner(s.ctx, "test-list-mine", "jit")
+	require.NoError(s.T(), err)
+	_, err = other.StartRunner(s.ctx, "test-list-other", "jit")
+	require.NoError(s.T(), err)
+
+	runners, err := e.ListRunners(s.ctx, engine.RunnerLabels("test-run"))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), runners, 1)
+	assert.Equal(s.T(), id, runners[0].ID)
+	assert.Equal(s.T(), "test-list-mine", runners[0].Name)
+}
@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// ParseGPUs parses the GPUs to pass through to runner containers: "all",
+// or a comma-separated list of device indexes or UUIDs ("0,1",
+// "GPU-3a23c669-...").  The request is the one docker run --gpus makes,
+// served by the NVIDIA Container Toolkit on the daemon's host.  An empty
+// spec requests none.
+func ParseGPUs(spec string) (*container.DeviceRequest, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	req := &container.DeviceRequest{Capabilities: [][]string{{"gpu"}}}
+	if spec == "all" {
+		req.Count = -1
+		return req, nil
+	}
+	for _, id := range strings.Split(spec, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || id == "all" {
+			return nil, fmt.Errorf("invalid gpus %q: want \"all\" or comma-separated device IDs", spec)
+		}
+		req.DeviceIDs = append(req.DeviceIDs, id)
+	}
+	return req, nil
+}
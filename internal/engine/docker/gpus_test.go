@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGPUs(t *testing.T) {
+	req, err := ParseGPUs("")
+	require.NoError(t, err)
+	assert.Nil(t, req)
+
+	req, err = ParseGPUs("all")
+	require.NoError(t, err)
+	assert.Equal(t, &container.DeviceRequest{Count: -1, Capabilities: [][]string{{"gpu"}}}, req)
+
+	req, err = ParseGPUs("0, GPU-3a23c669")
+	require.NoError(t, err)
+	assert.Equal(t, &container.DeviceRequest{DeviceIDs: []string{"0", "GPU-3a23c669"}, Capabilities: [][]string{{"gpu"}}}, req)
+
+	_, err = ParseGPUs("0,,1")
+	assert.ErrorContains(t, err, `invalid gpus "0,,1"`)
+	_, err = ParseGPUs("0,all")
+	assert.ErrorContains(t, err, "want \"all\" or comma-separated device IDs")
+}
@@ -0,0 +1,31 @@
+package docker
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// IsLocalHost reports whether the Docker daemon address host (as in
+// DOCKER_HOST) refers to a daemon on this machine: a unix socket, a
+// Windows named pipe, or TCP to a loopback address.  DinD bind-mounts
+// this machine's /var/run/docker.sock, which only reaches the daemon
+// running the runners when the daemon is local.
+func IsLocalHost(host string) bool {
+	u, err := url.Parse(host)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "unix", "npipe":
+		return true
+	case "tcp", "http", "https":
+		h := u.Hostname()
+		if strings.EqualFold(h, "localhost") {
+			return true
+		}
+		ip := net.ParseIP(h)
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dockerclient "github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/egress"
+)
+
+func TestIsLocalHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"unix:///var/run/docker.sock", true},
+		{"unix:///run/user/1000/docker.sock", true},
+		{"npipe:////./pipe/docker_engine", true},
+		{"tcp://localhost:2375", true},
+		{"tcp://127.0.0.1:2376", true},
+		{"tcp://[::1]:2375", true},
+		{"tcp://10.0.0.5:2376", false},
+		{"tcp://docker.example.com:2376", false},
+		{"ssh://user@build-host", false},
+		{"not a url\x7f", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsLocalHost(tt.host))
+		})
+	}
+}
+
+func TestWithEgress(t *testing.T) {
+	// The daemon address is unroutable: the request only succeeds if it
+	// goes through the proxy.
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.Host
+		w.Header().Set("API-Version", "1.47")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	client, err := dockerclient.NewClientWithOpts(
+		dockerclient.WithHost("tcp://192.0.2.1:2376"),
+		withEgress(egress.Config{ProxyURL: proxy.URL}),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Ping(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1:2376", proxied)
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	units "github.com/docker/go-units"
+)
+
+// Limits cap the resources of each runner container, so that a runaway
+// build cannot starve the host.  Zero values leave a resource unlimited;
+// a zero ShmSize keeps Docker's default /dev/shm size (64 MB).
+type Limits struct {
+	// CPUs is the number of CPUs a container may use, e.g. 1.5 (docker
+	// run --cpus).
+	CPUs float64
+	// Memory is the memory limit in bytes (--memory).
+	Memory int64
+	// PidsLimit is the maximum number of processes (--pids-limit).
+	PidsLimit int64
+	// ShmSize is the size of /dev/shm in bytes (--shm-size).
+	ShmSize int64
+}
+
+// ParseSize parses a size in the notation of docker run --memory and
+// --shm-size ("512m", "4g", or bytes) into bytes.  An empty size is 0.
+func ParseSize(size string) (int64, error) {
+	if size == "" {
+		return 0, nil
+	}
+	n, err := units.RAMInBytes(size)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", size, err)
+	}
+	return n, nil
+}
+
+// isZero reports whether l limits nothing.
+func (l Limits) isZero() bool {
+	return l == Limits{}
+}
+
+// apply sets the limits on a container's host config.
+func (l Limits) apply(hc *container.HostConfig) {
+	if l.CPUs > 0 {
+		hc.NanoCPUs = int64(l.CPUs * 1e9)
+	}
+	if l.Memory > 0 {
+		hc.Memory = l.Memory
+	}
+	if l.PidsLimit > 0 {
+		pids := l.PidsLimit
+		hc.PidsLimit = &pids
+	}
+	if l.ShmSize > 0 {
+		hc.ShmSize = l.ShmSize
+	}
+}
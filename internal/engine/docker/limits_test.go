@@ -0,0 +1,53 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{size: "", want: 0},
+		{size: "1048576", want: 1 << 20},
+		{size: "512m", want: 512 << 20},
+		{size: "4g", want: 4 << 30},
+		{size: "4GB", want: 4 << 30},
+		{size: "lots", wantErr: true},
+		{size: "-1g", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := ParseSize(tt.size)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLimitsApply(t *testing.T) {
+	assert.True(t, Limits{}.isZero())
+
+	hc := &container.HostConfig{}
+	Limits{CPUs: 1.5, Memory: 4 << 30, PidsLimit: 1024, ShmSize: 1 << 30}.apply(hc)
+	assert.Equal(t, int64(1_500_000_000), hc.NanoCPUs)
+	assert.Equal(t, int64(4<<30), hc.Memory)
+	require.NotNil(t, hc.PidsLimit)
+	assert.Equal(t, int64(1024), *hc.PidsLimit)
+	assert.Equal(t, int64(1<<30), hc.ShmSize)
+
+	// Unset limits leave the host config alone.
+	hc = &container.HostConfig{}
+	Limits{Memory: 512 << 20}.apply(hc)
+	assert.Equal(t, container.HostConfig{Resources: container.Resources{Memory: 512 << 20}}, *hc)
+}
@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"strings"
+)
+
+// ValidateMount checks a mount in the notation of docker run --volume:
+// "source:target[:options]".  source is an absolute path on the daemon's
+// host or a named volume (e.g. "gomodcache"), target an absolute path
+// in the container, and options a comma-separated list of "ro", "rw",
+// "z", "Z" and propagation modes.
+func ValidateMount(spec string) error {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("mount %q: want source:target[:options]", spec)
+	}
+	source, target := parts[0], parts[1]
+	if source == "" {
+		return fmt.Errorf("mount %q: empty source", spec)
+	}
+	if !path.IsAbs(source) && strings.ContainsAny(source, `/\`) {
+		return fmt.Errorf("mount %q: source must be an absolute path or a volume name", spec)
+	}
+	if !path.IsAbs(target) {
+		return fmt.Errorf("mount %q: target must be an absolute path", spec)
+	}
+	if len(parts) == 3 {
+		for _, opt := range strings.Split(parts[2], ",") {
+			switch opt {
+			case "ro", "rw", "z", "Z", "private", "rprivate", "shared", "rshared", "slave", "rslave", "nocopy":
+			default:
+				return fmt.Errorf("mount %q: unknown option %q", spec, opt)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateExtraHost checks a hosts file entry in the notation of docker
+// run --add-host: "name:ip", where ip may be "host-gateway" for the
+// daemon host's address.
+func ValidateExtraHost(spec string) error {
+	name, ip, ok := strings.Cut(spec, ":")
+	if !ok || name == "" {
+		return fmt.Errorf("extra host %q: want name:ip", spec)
+	}
+	if ip != "host-gateway" && net.ParseIP(ip) == nil {
+		return fmt.Errorf("extra host %q: %q is not an IP address or host-gateway", spec, ip)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMount(t *testing.T) {
+	for _, spec := range []string{
+		"/var/cache/go:/home/runner/go/pkg/mod",
+		"/var/cache/go:/home/runner/go/pkg/mod:ro",
+		"gomodcache:/home/runner/go/pkg/mod:rw,z",
+	} {
+		assert.NoError(t, ValidateMount(spec), spec)
+	}
+
+	tests := map[string]string{
+		"/cache":                 "want source:target[:options]",
+		"/a:/b:ro:extra":         "want source:target[:options]",
+		":/cache":                "empty source",
+		"cache/go:/cache":        "source must be an absolute path or a volume name",
+		"/var/cache/go:cache":    "target must be an absolute path",
+		"/var/cache/go:/c:fast":  `unknown option "fast"`,
+		"/var/cache/go:/c:ro,xx": `unknown option "xx"`,
+	}
+	for spec, want := range tests {
+		assert.ErrorContains(t, ValidateMount(spec), want, spec)
+	}
+}
+
+func TestValidateExtraHost(t *testing.T) {
+	assert.NoError(t, ValidateExtraHost("cache.internal:10.0.0.5"))
+	assert.NoError(t, ValidateExtraHost("registry:host-gateway"))
+	assert.NoError(t, ValidateExtraHost("v6:2001:db8::1"))
+
+	assert.ErrorContains(t, ValidateExtraHost("cache.internal"), "want name:ip")
+	assert.ErrorContains(t, ValidateExtraHost(":10.0.0.5"), "want name:ip")
+	assert.ErrorContains(t, ValidateExtraHost("cache:somewhere"), "is not an IP address")
+}
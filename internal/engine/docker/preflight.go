@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+)
+
+// Preflight checks that the engine New would create from cfg can work,
+// without pulling images or creating containers: the daemon answers
+// (and is local for dind), the runner image is looked up locally and
+// the configured network exists.  A missing image is not an error,
+// since New pulls it.  The returned diagnostics describe the daemon,
+// the image and the network.
+func Preflight(ctx context.Context, cfg Config) (map[string]string, error) {
+	if cfg.Image == "" {
+		cfg.Image = "ghcr.io/actions/actions-runner:latest"
+	}
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	diags, err := preflight(ctx, client.ServerVersion, func(ctx context.Context, ref string) error {
+		_, err := client.ImageInspect(ctx, ref)
+		return err
+	}, cfg.Image)
+	if err != nil || cfg.Network == "" {
+		return diags, err
+	}
+	err = preflightNetwork(ctx, func(ctx context.Context, name string) (network.Inspect, error) {
+		return client.NetworkInspect(ctx, name, network.InspectOptions{})
+	}, cfg.Network, diags)
+	return diags, err
+}
+
+// preflightNetwork checks that the named network exists and records its
+// driver in diags.
+func preflightNetwork(ctx context.Context, inspect func(ctx context.Context, name string) (network.Inspect, error), name string, diags map[string]string) error {
+	n, err := inspect(ctx, name)
+	switch {
+	case cerrdefs.IsNotFound(err):
+		return fmt.Errorf("network %s does not exist; create it with docker network create", name)
+	case err != nil:
+		return fmt.Errorf("inspecting network %s: %w", name, err)
+	}
+	diags["docker.network"] = name + " (" + n.Driver + ")"
+	return nil
+}
+
+// preflight runs the checks of Preflight against a daemon.
+func preflight(ctx context.Context, version func(context.Context) (types.Version, error), inspect func(ctx context.Context, ref string) error, image string) (map[string]string, error) {
+	v, err := version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("docker daemon unreachable: %w", err)
+	}
+	diags := map[string]string{
+		"docker.version":     v.Version,
+		"docker.api_version": v.APIVersion,
+	}
+
+	switch err := inspect(ctx, image); {
+	case err == nil:
+		diags["docker.image"] = image + " (present)"
+	case cerrdefs.IsNotFound(err):
+		diags["docker.image"] = image + " (not present, pulled on startup)"
+	default:
+		return diags, fmt.Errorf("inspecting image %s: %w", image, err)
+	}
+	return diags, nil
+}
@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight(t *testing.T) {
+	daemon := func(context.Context) (types.Version, error) {
+		return types.Version{Version: "28.5.2", APIVersion: "1.51"}, nil
+	}
+	const image = "ghcr.io/actions/actions-runner:latest"
+
+	tests := []struct {
+		name       string
+		version    func(context.Context) (types.Version, error)
+		inspectErr error
+		wantImage  string
+		wantErr    string
+	}{
+		{
+			name:      "image present",
+			version:   daemon,
+			wantImage: image + " (present)",
+		},
+		{
+			name:       "image pulled on startup",
+			version:    daemon,
+			inspectErr: fmt.Errorf("no such image: %w", cerrdefs.ErrNotFound),
+			wantImage:  image + " (not present, pulled on startup)",
+		},
+		{
+			name:       "inspect fails",
+			version:    daemon,
+			inspectErr: errors.New("permission denied"),
+			wantErr:    "inspecting image " + image + ": permission denied",
+		},
+		{
+			name: "daemon unreachable",
+			version: func(context.Context) (types.Version, error) {
+				return types.Version{}, errors.New("connection refused")
+			},
+			wantErr: "docker daemon unreachable: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags, err := preflight(context.Background(), tt.version, func(context.Context, string) error {
+				return tt.inspectErr
+			}, image)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{
+				"docker.version":     "28.5.2",
+				"docker.api_version": "1.51",
+				"docker.image":       tt.wantImage,
+			}, diags)
+		})
+	}
+}
+
+func TestPreflightNetwork(t *testing.T) {
+	diags := map[string]string{}
+	err := preflightNetwork(context.Background(), func(_ context.Context, name string) (network.Inspect, error) {
+		return network.Inspect{Name: name, Driver: "bridge"}, nil
+	}, "ci", diags)
+	require.NoError(t, err)
+	assert.Equal(t, "ci (bridge)", diags["docker.network"])
+
+	err = preflightNetwork(context.Background(), func(context.Context, string) (network.Inspect, error) {
+		return network.Inspect{}, fmt.Errorf("network ci not found: %w", cerrdefs.ErrNotFound)
+	}, "ci", diags)
+	assert.EqualError(t, err, "network ci does not exist; create it with docker network create")
+}
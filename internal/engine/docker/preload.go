@@ -0,0 +1,126 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+	dockerclient "github.com/docker/docker/client"
+)
+
+// PreloadImage is an image pulled at startup so runners and their job
+// containers start without waiting for a pull.
+type PreloadImage struct {
+	// Image is the image reference, e.g. "node:20".
+	Image string
+
+	// Required makes a failed pull abort engine startup.  Optional
+	// images that fail to pull are logged and skipped; they are pulled
+	// on first use instead.
+	Required bool
+}
+
+// defaultPreloadConcurrency is used when Config.PreloadConcurrency is zero.
+const defaultPreloadConcurrency = 4
+
+// pullFunc pulls one image to completion.
+type pullFunc func(ctx context.Context, ref string) error
+
+// clientPull returns a pullFunc that pulls through client.
+func clientPull(client *dockerclient.Client) pullFunc {
+	return func(ctx context.Context, ref string) error {
+		pull, err := client.ImagePull(ctx, ref, image.PullOptions{})
+		if err != nil {
+			return err
+		}
+		// Drain and close the pull stream so the image is fully downloaded.
+		if _, err := io.ReadAll(pull); err != nil {
+			_ = pull.Close()
+			return fmt.Errorf("reading image pull response: %w", err)
+		}
+		if err := pull.Close(); err != nil {
+			return fmt.Errorf("closing image pull stream: %w", err)
+		}
+		return nil
+	}
+}
+
+// preloadImages pulls images with at most concurrency pulls in flight,
+// each bounded by timeout (zero: no timeout).  Every image is attempted
+// even if others fail.  Failures of optional images are logged; the
+// failures of required images are joined and returned.
+func preloadImages(
+	ctx context.Context,
+	pull pullFunc,
+	images []PreloadImage,
+	concurrency int,
+	timeout time.Duration,
+	logger *slog.Logger,
+) error {
+	if concurrency <= 0 {
+		concurrency = defaultPreloadConcurrency
+	}
+
+	var (
+		sem  = make(chan struct{}, concurrency)
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, img := range images {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				if img.Required {
+					errs = append(errs, fmt.Errorf("image pull %s: %w", img.Image, ctx.Err()))
+				}
+				mu.Unlock()
+				return
+			}
+
+			pullCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				pullCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			logger.Info("pulling image",
+				slog.String("image", img.Image),
+				slog.Bool("required", img.Required),
+			)
+			start := time.Now()
+			if err := pull(pullCtx, img.Image); err != nil {
+				if img.Required {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("image pull %s: %w", img.Image, err))
+					mu.Unlock()
+					return
+				}
+				logger.Warn("failed to preload optional image, continuing",
+					slog.String("image", img.Image),
+					slog.String("error", err.Error()),
+				)
+				return
+			}
+			logger.Info("image ready",
+				slog.String("image", img.Image),
+				slog.Duration("took", time.Since(start)),
+			)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
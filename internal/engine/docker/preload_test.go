@@ -0,0 +1,198 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// ---------------------------------------------------------------------------
+// Fake puller
+// ---------------------------------------------------------------------------
+
+// fakePuller records pulls and tracks how many are in flight.  Pulls of
+// images in block wait for release (or their context); pulls of images
+// in fail return an error.
+type fakePuller struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	pulled      []string
+	block       map[string]bool
+	fail        map[string]error
+	release     chan struct{}
+}
+
+func newFakePuller() *fakePuller {
+	return &fakePuller{
+		block:   map[string]bool{},
+		fail:    map[string]error{},
+		release: make(chan struct{}),
+	}
+}
+
+func (f *fakePuller) pull(ctx context.Context, ref string) error {
+	f.mu.Lock()
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	block, err := f.block[ref], f.fail[ref]
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	if block {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.pulled = append(f.pulled, ref)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakePuller) getInFlight() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.inFlight
+}
+
+func (f *fakePuller) getPulled() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := append([]string(nil), f.pulled...)
+	sort.Strings(out)
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Test suite
+// ---------------------------------------------------------------------------
+
+type PreloadSuite struct {
+	suite.Suite
+	ctx    context.Context
+	logger *slog.Logger
+	puller *fakePuller
+}
+
+func (s *PreloadSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	s.puller = newFakePuller()
+}
+
+func TestPreloadSuite(t *testing.T) {
+	suite.Run(t, new(PreloadSuite))
+}
+
+func images(required bool, refs ...string) []PreloadImage {
+	out := make([]PreloadImage, len(refs))
+	for i, ref := range refs {
+		out[i] = PreloadImage{Image: ref, Required: required}
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func (s *PreloadSuite) TestPullsConcurrentlyUpToLimit() {
+	refs := []string{"a", "b", "c", "d", "e", "f"}
+	for _, ref := range refs {
+		s.puller.block[ref] = true
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- preloadImages(s.ctx, s.puller.pull, images(true, refs...), 3, 0, s.logger)
+	}()
+
+	// Three pulls run at once and no fourth starts while they block.
+	require.Eventually(s.T(), func() bool { return s.puller.getInFlight() == 3 },
+		time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(s.T(), 3, s.puller.getInFlight())
+
+	close(s.puller.release)
+	require.NoError(s.T(), <-done)
+	assert.Equal(s.T(), 3, s.puller.maxInFlight)
+	assert.Equal(s.T(), refs, s.puller.getPulled())
+}
+
+func (s *PreloadSuite) TestDefaultConcurrency() {
+	refs := []string{"a", "b", "c", "d", "e", "f"}
+	for _, ref := range refs {
+		s.puller.block[ref] = true
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- preloadImages(s.ctx, s.puller.pull, images(false, refs...), 0, 0, s.logger)
+	}()
+
+	require.Eventually(s.T(), func() bool { return s.puller.getInFlight() == defaultPreloadConcurrency },
+		time.Second, time.Millisecond)
+	close(s.puller.release)
+	require.NoError(s.T(), <-done)
+	assert.Equal(s.T(), defaultPreloadConcurrency, s.puller.maxInFlight)
+}
+
+func (s *PreloadSuite) TestOptionalFailureIsSkipped() {
+	s.puller.fail["broken"] = errors.New("manifest unknown")
+
+	imgs := append(images(true, "runner"), images(false, "broken", "node")...)
+	err := preloadImages(s.ctx, s.puller.pull, imgs, 2, 0, s.logger)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"node", "runner"}, s.puller.getPulled())
+}
+
+func (s *PreloadSuite) TestRequiredFailuresAreAggregated() {
+	s.puller.fail["runner"] = errors.New("unauthorized")
+	s.puller.fail["db"] = errors.New("manifest unknown")
+	s.puller.fail["cache"] = errors.New("optional, ignored")
+
+	imgs := append(images(true, "runner", "db"), images(false, "cache", "node")...)
+	err := preloadImages(s.ctx, s.puller.pull, imgs, 2, 0, s.logger)
+
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "image pull runner: unauthorized")
+	assert.Contains(s.T(), err.Error(), "image pull db: manifest unknown")
+	assert.NotContains(s.T(), err.Error(), "cache")
+	assert.Equal(s.T(), []string{"node"}, s.puller.getPulled(), "other images are still pulled")
+}
+
+func (s *PreloadSuite) TestTimeoutAppliesPerImage() {
+	s.puller.block["slow-optional"] = true
+	s.puller.block["slow-required"] = true
+
+	imgs := append(images(false, "slow-optional", "fast"), images(true, "slow-required")...)
+	err := preloadImages(s.ctx, s.puller.pull, imgs, 3, 20*time.Millisecond, s.logger)
+
+	require.Error(s.T(), err)
+	assert.ErrorIs(s.T(), err, context.DeadlineExceeded)
+	assert.Contains(s.T(), err.Error(), "slow-required")
+	assert.NotContains(s.T(), err.Error(), "slow-optional")
+	assert.Equal(s.T(), []string{"fast"}, s.puller.getPulled())
+}
@@ -0,0 +1,175 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// DinD modes.  In socket mode the runner uses the host daemon through
+// its bind-mounted socket; in sidecar mode every runner gets a private
+// docker:dind daemon, so workflows never reach the host daemon.
+const (
+	DindModeSocket  = "socket"
+	DindModeSidecar = "sidecar"
+)
+
+// DefaultDindImage is the daemon image of sidecar mode.
+const DefaultDindImage = "docker:dind"
+
+// sidecarLabel is the label key that associates a sidecar daemon, its
+// network and its work volume with their runner.  The sidecar does not
+// carry the engine.ManagedLabel runner labels, so it is never listed
+// (or reaped) as a runner itself; it does carry the scale set's
+// engine.ScaleSetLabels.
+const sidecarLabel = "scaleset.sidecar-of"
+
+// sidecarHost is the network alias of the sidecar daemon, and
+// sidecarPort the plain-TCP port it listens on.  TLS is disabled: the
+// daemon is only reachable from the runner's private network.
+const (
+	sidecarHost = "docker"
+	sidecarPort = 2375
+)
+
+// sidecarWorkDir is the runner's work directory.  It is a volume shared
+// with the sidecar at the same path, so bind mounts of the workspace
+// (docker run -v "$PWD:/src", job containers) resolve on the daemon.
+const sidecarWorkDir = "/home/runner/_work"
+
+// sidecarName returns the name of the sidecar container and network of
+// the named runner.
+func sidecarName(runner string) string { return runner + "-dind" }
+
+// sidecarVolume returns the name of the work volume of the named runner.
+func sidecarVolume(runner string) string { return runner + "-work" }
+
+// sidecarLabels returns the labels of the sidecar resources of the named
+// runner.
+func sidecarLabels(runner string) map[string]string {
+	return map[string]string{sidecarLabel: runner}
+}
+
+// sidecarContainer returns the create settings of the sidecar daemon of
+// the named runner.  docker:dind needs a privileged container.
+func sidecarContainer(runner, image string) (*container.Config, *container.HostConfig, *network.NetworkingConfig) {
+	cfg := &container.Config{
+		Image:  image,
+		Env:    []string{"DOCKER_TLS_CERTDIR="},
+		Labels: sidecarLabels(runner),
+	}
+	hostCfg := &container.HostConfig{
+		Privileged: true,
+		Binds:      []string{sidecarVolume(runner) + ":" + sidecarWorkDir},
+	}
+	netCfg := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			sidecarName(runner): {Aliases: []string{sidecarHost}},
+		},
+	}
+	return cfg, hostCfg, netCfg
+}
+
+// sidecarRunnerEnv returns the environment that points the runner at its
+// sidecar daemon.
+func sidecarRunnerEnv() []string {
+	return []string{fmt.Sprintf("DOCKER_HOST=tcp://%s:%d", sidecarHost, sidecarPort)}
+}
+
+// sidecarRunnerHost attaches the runner to its sidecar's network and
+// mounts the shared work volume.
+func sidecarRunnerHost(runner string, hostCfg *container.HostConfig) {
+	hostCfg.NetworkMode = container.NetworkMode(sidecarName(runner))
+	hostCfg.Binds = append(hostCfg.Binds, sidecarVolume(runner)+":"+sidecarWorkDir)
+}
+
+// startSidecar creates the private network, the work volume and the
+// dind daemon of the named runner, and starts the daemon.  On failure
+// whatever was created is removed again.
+func (e *Engine) startSidecar(ctx context.Context, runner string) error {
+	name := sidecarName(runner)
+	labels := sidecarLabels(runner)
+	maps.Copy(labels, e.scaleSetLabels())
+
+	if _, err := e.client.NetworkCreate(ctx, name, network.CreateOptions{
+		Driver: "bridge",
+		Labels: labels,
+	}); err != nil {
+		return fmt.Errorf("network create %s: %w", name, err)
+	}
+
+	start := func() error {
+		if _, err := e.client.VolumeCreate(ctx, volume.CreateOptions{
+			Name:   sidecarVolume(runner),
+			Labels: labels,
+		}); err != nil {
+			return fmt.Errorf("volume create %s: %w", sidecarVolume(runner), err)
+		}
+
+		cfg, hostCfg, netCfg := sidecarContainer(runner, e.dindImage)
+		maps.Copy(cfg.Labels, labels)
+		resp, err := e.client.ContainerCreate(ctx, cfg, hostCfg, netCfg, nil, name)
+		if err != nil {
+			return fmt.Errorf("container create %s: %w", name, err)
+		}
+		if err := e.startContainer(ctx, name, resp.ID); err != nil {
+			return fmt.Errorf("container start %s: %w", name, err)
+		}
+		return nil
+	}
+	if err := start(); err != nil {
+		_ = e.removeSidecar(ctx, runner)
+		return err
+	}
+
+	e.logger.Info("dind sidecar started",
+		slog.String("name", runner),
+		slog.String("sidecar", name),
+	)
+	return nil
+}
+
+// removeSidecar force-removes the dind daemon, the work volume and the
+// network of the named runner.  Resources that are already gone are
+// skipped, so removal can be retried; other failures are returned
+// joined.
+func (e *Engine) removeSidecar(ctx context.Context, runner string) error {
+	var errs []error
+
+	containers, err := e.client.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", sidecarLabel, runner))),
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("listing sidecar of %s: %w", runner, err))
+	}
+	for _, c := range containers {
+		if err := e.client.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true}); err != nil && !cerrdefs.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("container remove %s: %w", c.ID, err))
+		}
+	}
+
+	if err := e.client.VolumeRemove(ctx, sidecarVolume(runner), true); err != nil && !cerrdefs.IsNotFound(err) {
+		errs = append(errs, fmt.Errorf("volume remove %s: %w", sidecarVolume(runner), err))
+	}
+	if err := e.client.NetworkRemove(ctx, sidecarName(runner)); err != nil && !cerrdefs.IsNotFound(err) {
+		errs = append(errs, fmt.Errorf("network remove %s: %w", sidecarName(runner), err))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		e.logger.Warn("dind sidecar: removal failed",
+			slog.String("name", runner),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
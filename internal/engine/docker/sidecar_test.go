@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSidecarContainer(t *testing.T) {
+	cfg, hostCfg, netCfg := sidecarContainer("runner-1", "docker:27-dind")
+
+	assert.Equal(t, "docker:27-dind", cfg.Image)
+	assert.Contains(t, cfg.Env, "DOCKER_TLS_CERTDIR=")
+	assert.Equal(t, map[string]string{sidecarLabel: "runner-1"}, cfg.Labels)
+
+	assert.True(t, hostCfg.Privileged)
+	assert.Equal(t, []string{"runner-1-work:/home/runner/_work"}, hostCfg.Binds)
+
+	require.Contains(t, netCfg.EndpointsConfig, "runner-1-dind")
+	assert.Equal(t, []string{"docker"}, netCfg.EndpointsConfig["runner-1-dind"].Aliases)
+}
+
+func TestSidecarRunner(t *testing.T) {
+	hostCfg := &container.HostConfig{}
+	sidecarRunnerHost("runner-1", hostCfg)
+
+	assert.Equal(t, container.NetworkMode("runner-1-dind"), hostCfg.NetworkMode)
+	assert.Equal(t, []string{"runner-1-work:/home/runner/_work"}, hostCfg.Binds)
+	assert.Equal(t, []string{"DOCKER_HOST=tcp://docker:2375"}, sidecarRunnerEnv())
+	assert.NotContains(t, hostCfg.Binds, "/var/run/docker.sock:/var/run/docker.sock")
+}
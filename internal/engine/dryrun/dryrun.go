@@ -0,0 +1,139 @@
+// Package dryrun implements an engine.Engine that creates nothing: it
+// logs the runner each StartRunner and DestroyRunner call would create
+// or destroy in the configured engine, with the labels it would carry,
+// and tracks the would-be runners so that the scaler, the reconciler
+// and the admin API behave as in a real run.  It backs the --dry-run
+// flag, for validating a configuration and its scaling behaviour
+// against a real repository.
+package dryrun
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// Config configures a dry-run Engine.
+type Config struct {
+	// Engine is the name of the configured engine, for the log entries.
+	Engine string
+	// RunID and ScaleSetName label the would-be runners as the
+	// configured engine would.
+	RunID        string
+	ScaleSetName string
+	Logger       *slog.Logger
+}
+
+// Engine logs the runners it is asked to start and destroy.
+type Engine struct {
+	cfg        Config
+	scaleSetID atomic.Int64
+
+	mu      sync.Mutex
+	seq     int
+	runners map[string]engine.ListedRunner // by id
+}
+
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine             = (*Engine)(nil)
+	_ engine.Checker            = (*Engine)(nil)
+	_ engine.RunnerLister       = (*Engine)(nil)
+	_ engine.ScaleSetIdentifier = (*Engine)(nil)
+)
+
+// New creates a dry-run Engine.
+func New(cfg Config) *Engine {
+	return &Engine{cfg: cfg, runners: make(map[string]engine.ListedRunner)}
+}
+
+// SetScaleSetID sets the scale set ID the would-be runners are labelled
+// with.
+func (e *Engine) SetScaleSetID(id int) {
+	e.scaleSetID.Store(int64(id))
+}
+
+// StartRunner logs the runner it would start and returns an id of the
+// form "dryrun-<n>".
+func (e *Engine) StartRunner(_ context.Context, name string, jitConfig string) (string, error) {
+	labels := engine.RunnerLabels(e.cfg.RunID)
+	maps.Copy(labels, engine.ScaleSetLabels(e.cfg.ScaleSetName, int(e.scaleSetID.Load())))
+
+	e.mu.Lock()
+	e.seq++
+	id := "dryrun-" + strconv.Itoa(e.seq)
+	e.runners[id] = engine.ListedRunner{ID: id, Name: name, Labels: labels}
+	e.mu.Unlock()
+
+	e.cfg.Logger.Info("dry run: would start runner",
+		slog.String("engine", e.cfg.Engine),
+		slog.String("name", name),
+		slog.String("id", id),
+		slog.Any("labels", labels),
+		slog.Bool("jitConfig", jitConfig != ""),
+	)
+	return id, nil
+}
+
+// DestroyRunner logs the runner it would destroy and forgets it.
+func (e *Engine) DestroyRunner(_ context.Context, id string) error {
+	e.mu.Lock()
+	r, ok := e.runners[id]
+	delete(e.runners, id)
+	e.mu.Unlock()
+
+	e.cfg.Logger.Info("dry run: would destroy runner",
+		slog.String("engine", e.cfg.Engine),
+		slog.String("name", r.Name),
+		slog.String("id", id),
+		slog.Bool("known", ok),
+	)
+	return nil
+}
+
+// ListRunners returns the would-be runners carrying all of labels.
+func (e *Engine) ListRunners(_ context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []engine.ListedRunner
+	for _, id := range slices.Sorted(maps.Keys(e.runners)) {
+		r := e.runners[id]
+		if matches(r.Labels, labels) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func matches(have, want map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Check always succeeds: there is no backend to reach.
+func (e *Engine) Check(context.Context) (map[string]string, error) {
+	return map[string]string{"mode": "dry-run", "engine": e.cfg.Engine}, nil
+}
+
+// Shutdown logs the would-be runners left, which a real run would
+// destroy.
+func (e *Engine) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cfg.Logger.Info("dry run: shutting down",
+		slog.String("engine", e.cfg.Engine),
+		slog.Int("runners", len(e.runners)),
+	)
+	clear(e.runners)
+	return nil
+}
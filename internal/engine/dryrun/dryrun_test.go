@@ -0,0 +1,56 @@
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+func TestEngine_TracksWouldBeRunners(t *testing.T) {
+	var logs bytes.Buffer
+	e := New(Config{
+		Engine:       "gcp",
+		RunID:        "run-1",
+		ScaleSetName: "Linux Runners",
+		Logger:       slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	e.SetScaleSetID(42)
+	ctx := context.Background()
+
+	a, err := e.StartRunner(ctx, "runner-a", "jit")
+	require.NoError(t, err)
+	b, err := e.StartRunner(ctx, "runner-b", "jit")
+	require.NoError(t, err)
+	assert.Equal(t, "dryrun-1", a)
+	assert.Equal(t, "dryrun-2", b)
+	assert.Contains(t, logs.String(), `msg="dry run: would start runner" engine=gcp name=runner-a id=dryrun-1`)
+	assert.Contains(t, logs.String(), "scaleset-name:linux-runners")
+
+	listed, err := e.ListRunners(ctx, engine.RunnerLabels("run-1"))
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, "runner-a", listed[0].Name)
+	assert.Equal(t, "42", listed[0].Labels[engine.ScaleSetIDLabel])
+	listed, err = e.ListRunners(ctx, engine.RunnerLabels("other-run"))
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	require.NoError(t, e.DestroyRunner(ctx, a))
+	assert.Contains(t, logs.String(), `msg="dry run: would destroy runner" engine=gcp name=runner-a id=dryrun-1 known=true`)
+	listed, err = e.ListRunners(ctx, engine.RunnerLabels(""))
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	details, err := e.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "dry-run", details["mode"])
+
+	require.NoError(t, e.Shutdown(ctx))
+	assert.Contains(t, logs.String(), `msg="dry run: shutting down" engine=gcp runners=1`)
+}
@@ -4,7 +4,11 @@
 // compute-agnostic.
 package engine
 
-import "context"
+import (
+	"context"
+	"strconv"
+	"strings"
+)
 
 // Engine is the contract every compute backend must satisfy.
 //
@@ -45,3 +49,176 @@ type Engine interface {
 	// termination.
 	Shutdown(ctx context.Context) error
 }
+
+// RunnerSpec describes a single runner to be started through
+// BatchStarter.
+type RunnerSpec struct {
+	// Name is the runner registration name (see StartRunner).
+	Name string
+	// JITConfig is the base64-encoded JIT configuration for this runner.
+	JITConfig string
+}
+
+// BatchStarter is an optional interface an Engine may implement when its
+// backend can provision several runners in one call (e.g. GCP
+// bulkInsert).  When the configured engine implements it, the scaler
+// starts a scale-up's runners with a single StartRunners call instead of
+// calling StartRunner in a loop.
+type BatchStarter interface {
+	// StartRunners starts every runner in specs and returns the engine
+	// id of each runner that started, keyed by runner name.  On partial
+	// failure it returns the runners that did start together with a
+	// non-nil error; the caller must track those runners normally.
+	StartRunners(ctx context.Context, specs []RunnerSpec) (map[string]string, error)
+}
+
+// BatchDecider is an optional interface a BatchStarter may implement when
+// only some scale-ups benefit from a batch call (e.g. GCP bulkInsert,
+// which is opt-in and only pays off above a threshold).  When UseBatch
+// reports false for a scale-up of n runners, the scaler starts them one
+// by one through StartRunner, honouring its start parallelism and
+// retries, as it does for engines without StartRunners.
+type BatchDecider interface {
+	UseBatch(n int) bool
+}
+
+// Checker is an optional interface an Engine may implement to report
+// backend health.  Check returns bounded, non-secret diagnostics (daemon
+// version, free disk, quota headroom, ...) and a non-nil error when the
+// backend is unreachable or unhealthy.  It backs the /readyz endpoint.
+type Checker interface {
+	Check(ctx context.Context) (map[string]string, error)
+}
+
+// NameSuffixer is an optional interface an Engine may implement to add a
+// suffix (e.g. the zone or availability zone) to the runner names the
+// scaler generates.  The scaler owns naming because the name must be known
+// before the JIT config is requested, so the engine only contributes a
+// fixed suffix; the scaler sanitizes it and keeps the full name within
+// the limits of GitHub and the cloud providers.  An empty suffix leaves
+// names unchanged.
+type NameSuffixer interface {
+	RunnerNameSuffix() string
+}
+
+// RunnerFinder is an optional interface an Engine may implement to look
+// up a runner by its idempotency key, which is the runner name passed to
+// StartRunner.  A start can fail on the caller's side (timeout, lost
+// operation) after the backend already created the resource; when start
+// idempotency is enabled the scaler retries such a start under the same
+// name and first asks FindRunner whether it exists, adopting it rather
+// than creating a duplicate.
+type RunnerFinder interface {
+	// FindRunner returns the id of the usable runner started under key,
+	// or "" if there is none.  A leftover resource that can no longer
+	// run (e.g. a container that never started) should be removed and
+	// reported as "" so the name can be reused.  A found runner must be
+	// tracked as if StartRunner had returned it.
+	FindRunner(ctx context.Context, key string) (id string, err error)
+}
+
+// Labels applied to every runner resource so a later process can find
+// it without this one's in-memory state (see RunnerLister).  Keys and
+// values are limited to lowercase letters, digits, '-' and '_' so they
+// are valid Docker and GCP labels alike.
+const (
+	// ManagedLabel marks a resource as created by scaleset.  Its value
+	// is always "true".
+	ManagedLabel = "scaleset-managed"
+
+	// RunIDLabel holds the run ID of the process that created the
+	// resource.
+	RunIDLabel = "scaleset-run-id"
+
+	// ScaleSetNameLabel holds the name of the scale set the runner
+	// belongs to, reduced to a valid label value (see ScaleSetLabels).
+	ScaleSetNameLabel = "scaleset-name"
+
+	// ScaleSetIDLabel holds the ID GitHub assigned to the scale set.
+	ScaleSetIDLabel = "scale-set-id"
+
+	// ManagedByLabel names the tool that created the resource, for cost
+	// attribution alongside other tools' resources.  Its value is
+	// always ManagedBy.
+	ManagedByLabel = "managed-by"
+)
+
+// ManagedBy is the value of ManagedByLabel.
+const ManagedBy = "terrpan-scaleset"
+
+// RunnerLabels returns the labels an engine attaches to a runner created
+// by the process with the given run ID.  An empty runID yields only the
+// managed marker.
+func RunnerLabels(runID string) map[string]string {
+	labels := map[string]string{ManagedLabel: "true"}
+	if runID != "" {
+		labels[RunIDLabel] = runID
+	}
+	return labels
+}
+
+// ScaleSetLabels returns the labels identifying the scale set a runner
+// belongs to, which engines attach next to RunnerLabels.  The name is
+// lowercased, other characters than letters, digits, '-' and '_' become
+// '-', and it is cut to 63 characters.  An empty name or a zero id is
+// left out.
+func ScaleSetLabels(name string, id int) map[string]string {
+	labels := map[string]string{ManagedByLabel: ManagedBy}
+	if v := labelValue(name); v != "" {
+		labels[ScaleSetNameLabel] = v
+	}
+	if id != 0 {
+		labels[ScaleSetIDLabel] = strconv.Itoa(id)
+	}
+	return labels
+}
+
+// labelValue reduces s to a valid label value: lowercase letters,
+// digits, '-' and '_', starting and ending with a letter or digit, at
+// most 63 characters.
+func labelValue(s string) string {
+	v := []byte(strings.ToLower(s))
+	for i, c := range v {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			v[i] = '-'
+		}
+	}
+	if len(v) > 63 {
+		v = v[:63]
+	}
+	return strings.Trim(string(v), "-_")
+}
+
+// ScaleSetIdentifier is an optional interface an Engine may implement to
+// learn the ID of the scale set it serves for ScaleSetIDLabel.  The ID is
+// only known once the scale set is registered, which with concurrent
+// initialization happens after the engine was created, so it is set
+// before the first runner is started rather than passed to the engine's
+// constructor.
+type ScaleSetIdentifier interface {
+	SetScaleSetID(id int)
+}
+
+// ListedRunner is a runner resource reported by RunnerLister.
+type ListedRunner struct {
+	// ID is the engine id, as accepted by DestroyRunner.
+	ID string
+	// Name is the runner (resource) name.
+	Name string
+	// Labels are the resource's labels.
+	Labels map[string]string
+	// Preempted is set when the backend reclaimed the resource (e.g. a
+	// GCP spot VM was preempted).  It can no longer run a job and only
+	// needs to be destroyed.
+	Preempted bool
+}
+
+// RunnerLister is an optional interface an Engine may implement to
+// enumerate runner resources by label, including ones it is not
+// tracking.  It backs the cleanup command, which destroys resources
+// left behind by a process that crashed before its Shutdown ran.
+type RunnerLister interface {
+	// ListRunners returns every resource in the backend that carries
+	// all of the given labels.
+	ListRunners(ctx context.Context, labels map[string]string) ([]ListedRunner, error)
+}
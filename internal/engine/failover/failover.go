@@ -0,0 +1,318 @@
+// Package failover implements an engine.Engine that starts runners on a
+// primary engine and fails over to fallback engines (e.g. GCP Spot ->
+// GCP standard -> Docker) when the current one keeps failing to start
+// runners.  Runners are always destroyed on the engine that started
+// them, so a failover never strands a runner.
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// Config holds the failover policy.
+type Config struct {
+	// AfterFailures is the number of consecutive start failures on the
+	// current engine after which new runners are started on the next
+	// engine in the chain.  Default: 3.
+	AfterFailures int
+
+	// RetryPrimaryAfter is how long to stay on a fallback before trying
+	// the primary again.  Zero stays on the fallback until restart.
+	RetryPrimaryAfter time.Duration
+}
+
+// Member is one engine in the chain.
+type Member struct {
+	// Name identifies the engine in logs and diagnostics, e.g. "gcp".
+	Name   string
+	Engine engine.Engine
+}
+
+// Engine starts runners on the first healthy engine of a chain.
+type Engine struct {
+	members []Member
+	cfg     Config
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu           sync.Mutex
+	active       int            // index of the engine new runners start on
+	failures     int            // consecutive start failures on active
+	failedOverAt time.Time      // when active last moved off the primary
+	owners       map[string]int // runner id -> index of the engine that started it
+}
+
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine             = (*Engine)(nil)
+	_ engine.BatchStarter       = (*Engine)(nil)
+	_ engine.Checker            = (*Engine)(nil)
+	_ engine.NameSuffixer       = (*Engine)(nil)
+	_ engine.RunnerFinder       = (*Engine)(nil)
+	_ engine.RunnerLister       = (*Engine)(nil)
+	_ engine.ScaleSetIdentifier = (*Engine)(nil)
+)
+
+// New creates a failover engine over members; members[0] is the primary.
+func New(members []Member, cfg Config, logger *slog.Logger) (*Engine, error) {
+	if len(members) == 0 {
+		return nil, errors.New("failover: at least one engine is required")
+	}
+	if cfg.AfterFailures <= 0 {
+		cfg.AfterFailures = 3
+	}
+	return &Engine{
+		members: members,
+		cfg:     cfg,
+		logger:  logger,
+		now:     time.Now,
+		owners:  make(map[string]int),
+	}, nil
+}
+
+// Active returns the name of the engine new runners are started on.
+func (e *Engine) Active() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.members[e.active].Name
+}
+
+// pick returns the index of the engine to start the next runner on,
+// moving back to the primary once RetryPrimaryAfter has passed.
+func (e *Engine) pick() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.active > 0 && e.cfg.RetryPrimaryAfter > 0 && e.now().Sub(e.failedOverAt) >= e.cfg.RetryPrimaryAfter {
+		e.logger.Info("retrying primary engine",
+			slog.String("engine", e.members[0].Name),
+			slog.String("fallback", e.members[e.active].Name),
+		)
+		e.active = 0
+		e.failures = 0
+	}
+	return e.active
+}
+
+// recordResult updates the failure count of engine idx after a start
+// and fails over once it reaches AfterFailures.  Results of an engine
+// that is no longer active are ignored.
+func (e *Engine) recordResult(idx int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if idx != e.active {
+		return
+	}
+	if err == nil {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures < e.cfg.AfterFailures || e.active == len(e.members)-1 {
+		return
+	}
+
+	from := e.members[e.active].Name
+	e.active++
+	e.failures = 0
+	e.failedOverAt = e.now()
+	e.logger.Warn("engine keeps failing to start runners, failing over",
+		slog.String("from", from),
+		slog.String("to", e.members[e.active].Name),
+		slog.Int("failures", e.cfg.AfterFailures),
+		slog.String("error", err.Error()),
+	)
+}
+
+// track records that engine idx owns runner id.
+func (e *Engine) track(idx int, id string) {
+	e.mu.Lock()
+	e.owners[id] = idx
+	e.mu.Unlock()
+}
+
+// StartRunner starts the runner on the active engine.
+func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string) (string, error) {
+	idx := e.pick()
+	id, err := e.members[idx].Engine.StartRunner(ctx, name, jitConfig)
+	e.recordResult(idx, err)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", e.members[idx].Name, err)
+	}
+	e.track(idx, id)
+	return id, nil
+}
+
+// StartRunners implements engine.BatchStarter.  The batch goes to the
+// active engine, in one call when it is a BatchStarter and one runner at
+// a time (stopping at the first failure) otherwise.  A failed batch
+// counts as a single failure.
+func (e *Engine) StartRunners(ctx context.Context, specs []engine.RunnerSpec) (map[string]string, error) {
+	idx := e.pick()
+	m := e.members[idx]
+
+	var (
+		started map[string]string
+		err     error
+	)
+	if bs, ok := m.Engine.(engine.BatchStarter); ok {
+		started, err = bs.StartRunners(ctx, specs)
+	} else {
+		started = make(map[string]string, len(specs))
+		for _, spec := range specs {
+			var id string
+			id, err = m.Engine.StartRunner(ctx, spec.Name, spec.JITConfig)
+			if err != nil {
+				break
+			}
+			started[spec.Name] = id
+		}
+	}
+
+	e.recordResult(idx, err)
+	for _, id := range started {
+		e.track(idx, id)
+	}
+	if err != nil {
+		return started, fmt.Errorf("%s: %w", m.Name, err)
+	}
+	return started, nil
+}
+
+// DestroyRunner destroys the runner on the engine that started it.  An
+// id this engine did not start is destroyed on every engine, which is
+// safe because DestroyRunner is idempotent.
+func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
+	e.mu.Lock()
+	idx, ok := e.owners[id]
+	e.mu.Unlock()
+
+	if ok {
+		if err := e.members[idx].Engine.DestroyRunner(ctx, id); err != nil {
+			return err
+		}
+	} else {
+		var errs []error
+		for _, m := range e.members {
+			if err := m.Engine.DestroyRunner(ctx, id); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", m.Name, err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	delete(e.owners, id)
+	e.mu.Unlock()
+	return nil
+}
+
+// Shutdown shuts down every engine in the chain.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, m := range e.members {
+		if err := m.Engine.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Check implements engine.Checker by checking the active engine, which
+// is the one new runners depend on.
+func (e *Engine) Check(ctx context.Context) (map[string]string, error) {
+	e.mu.Lock()
+	m := e.members[e.active]
+	e.mu.Unlock()
+
+	diags := map[string]string{"failover.active": m.Name}
+	checker, ok := m.Engine.(engine.Checker)
+	if !ok {
+		return diags, nil
+	}
+	d, err := checker.Check(ctx)
+	for k, v := range d {
+		diags[k] = v
+	}
+	return diags, err
+}
+
+// RunnerNameSuffix implements engine.NameSuffixer with the primary's
+// suffix, since the scaler asks for it once at startup.
+func (e *Engine) RunnerNameSuffix() string {
+	if ns, ok := e.members[0].Engine.(engine.NameSuffixer); ok {
+		return ns.RunnerNameSuffix()
+	}
+	return ""
+}
+
+// SetScaleSetID implements engine.ScaleSetIdentifier by passing id to
+// every engine of the chain that records it.
+func (e *Engine) SetScaleSetID(id int) {
+	for _, m := range e.members {
+		if ider, ok := m.Engine.(engine.ScaleSetIdentifier); ok {
+			ider.SetScaleSetID(id)
+		}
+	}
+}
+
+// FindRunner implements engine.RunnerFinder.  The earlier attempt may
+// have gone to any engine, so each one that can look runners up is
+// asked in chain order.
+func (e *Engine) FindRunner(ctx context.Context, key string) (string, error) {
+	for idx, m := range e.members {
+		finder, ok := m.Engine.(engine.RunnerFinder)
+		if !ok {
+			continue
+		}
+		id, err := finder.FindRunner(ctx, key)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", m.Name, err)
+		}
+		if id != "" {
+			e.track(idx, id)
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+// ListRunners implements engine.RunnerLister across every engine that
+// supports it.  Engines sharing a backend (two GCP engines in one zone)
+// report the same resources, so results are de-duplicated by id.  Each
+// runner is attributed to the first engine that lists it, so a later
+// DestroyRunner goes to that engine only.
+func (e *Engine) ListRunners(ctx context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	var (
+		runners []engine.ListedRunner
+		seen    = make(map[string]bool)
+	)
+	for idx, m := range e.members {
+		lister, ok := m.Engine.(engine.RunnerLister)
+		if !ok {
+			continue
+		}
+		listed, err := lister.ListRunners(ctx, labels)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Name, err)
+		}
+		for _, r := range listed {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				e.track(idx, r.ID)
+				runners = append(runners, r)
+			}
+		}
+	}
+	return runners, nil
+}
@@ -0,0 +1,253 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// ---------------------------------------------------------------------------
+// Mock engine
+// ---------------------------------------------------------------------------
+
+type mockEngine struct {
+	name string
+
+	mu         sync.Mutex
+	startErr   error
+	started    []string
+	destroyed  []string
+	shutdown   bool
+	scaleSetID int
+}
+
+func (m *mockEngine) StartRunner(_ context.Context, name, _ string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.startErr != nil {
+		return "", m.startErr
+	}
+	m.started = append(m.started, name)
+	return m.name + "-" + name, nil
+}
+
+func (m *mockEngine) DestroyRunner(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.destroyed = append(m.destroyed, id)
+	return nil
+}
+
+func (m *mockEngine) Shutdown(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shutdown = true
+	return nil
+}
+
+func (m *mockEngine) SetScaleSetID(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scaleSetID = id
+}
+
+func (m *mockEngine) setStartErr(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.startErr = err
+}
+
+// ---------------------------------------------------------------------------
+// Test suite
+// ---------------------------------------------------------------------------
+
+type FailoverSuite struct {
+	suite.Suite
+	ctx      context.Context
+	primary  *mockEngine
+	fallback *mockEngine
+	now      time.Time
+}
+
+func (s *FailoverSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.primary = &mockEngine{name: "spot"}
+	s.fallback = &mockEngine{name: "standard"}
+	s.now = time.Now()
+}
+
+func (s *FailoverSuite) newEngine(cfg Config) *Engine {
+	e, err := New([]Member{
+		{Name: "spot", Engine: s.primary},
+		{Name: "standard", Engine: s.fallback},
+	}, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(s.T(), err)
+	e.now = func() time.Time { return s.now }
+	return e
+}
+
+func TestFailoverSuite(t *testing.T) {
+	suite.Run(t, new(FailoverSuite))
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func (s *FailoverSuite) TestNew_RequiresEngine() {
+	_, err := New(nil, Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.Error(s.T(), err)
+}
+
+func (s *FailoverSuite) TestStartRunner_UsesPrimaryWhileHealthy() {
+	e := s.newEngine(Config{AfterFailures: 2})
+
+	id, err := e.StartRunner(s.ctx, "r1", "jit")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "spot-r1", id)
+	assert.Empty(s.T(), s.fallback.started)
+	assert.Equal(s.T(), "spot", e.Active())
+}
+
+func (s *FailoverSuite) TestStartRunner_FailsOverAfterConsecutiveFailures() {
+	s.primary.setStartErr(errors.New("ZONE_RESOURCE_POOL_EXHAUSTED"))
+	e := s.newEngine(Config{AfterFailures: 2})
+
+	for i := range 2 {
+		_, err := e.StartRunner(s.ctx, fmt.Sprintf("r%d", i), "jit")
+		require.Error(s.T(), err)
+		assert.Contains(s.T(), err.Error(), "spot")
+	}
+	assert.Equal(s.T(), "standard", e.Active())
+
+	id, err := e.StartRunner(s.ctx, "r2", "jit")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "standard-r2", id)
+	assert.Equal(s.T(), []string{"r2"}, s.fallback.started)
+}
+
+func (s *FailoverSuite) TestStartRunner_SuccessResetsFailureCount() {
+	e := s.newEngine(Config{AfterFailures: 2})
+
+	s.primary.setStartErr(errors.New("transient"))
+	_, _ = e.StartRunner(s.ctx, "r1", "jit")
+	s.primary.setStartErr(nil)
+	_, err := e.StartRunner(s.ctx, "r2", "jit")
+	require.NoError(s.T(), err)
+	s.primary.setStartErr(errors.New("transient"))
+	_, _ = e.StartRunner(s.ctx, "r3", "jit")
+
+	assert.Equal(s.T(), "spot", e.Active(), "failures were not consecutive")
+}
+
+func (s *FailoverSuite) TestStartRunner_StaysOnLastEngine() {
+	s.primary.setStartErr(errors.New("down"))
+	s.fallback.setStartErr(errors.New("down too"))
+	e := s.newEngine(Config{AfterFailures: 1})
+
+	for i := range 5 {
+		_, err := e.StartRunner(s.ctx, fmt.Sprintf("r%d", i), "jit")
+		require.Error(s.T(), err)
+	}
+	assert.Equal(s.T(), "standard", e.Active())
+}
+
+func (s *FailoverSuite) TestStartRunner_RetriesPrimaryAfterInterval() {
+	s.primary.setStartErr(errors.New("down"))
+	e := s.newEngine(Config{AfterFailures: 1, RetryPrimaryAfter: 10 * time.Minute})
+
+	_, _ = e.StartRunner(s.ctx, "r1", "jit")
+	require.Equal(s.T(), "standard", e.Active())
+
+	s.now = s.now.Add(5 * time.Minute)
+	_, err := e.StartRunner(s.ctx, "r2", "jit")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"r2"}, s.fallback.started)
+
+	s.primary.setStartErr(nil)
+	s.now = s.now.Add(5 * time.Minute)
+	id, err := e.StartRunner(s.ctx, "r3", "jit")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "spot-r3", id)
+	assert.Equal(s.T(), "spot", e.Active())
+}
+
+func (s *FailoverSuite) TestDestroyRunner_RoutesToOwningEngine() {
+	e := s.newEngine(Config{AfterFailures: 1})
+
+	primaryID, err := e.StartRunner(s.ctx, "r1", "jit")
+	require.NoError(s.T(), err)
+
+	s.primary.setStartErr(errors.New("down"))
+	_, _ = e.StartRunner(s.ctx, "r2", "jit")
+	fallbackID, err := e.StartRunner(s.ctx, "r3", "jit")
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, primaryID))
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, fallbackID))
+	assert.Equal(s.T(), []string{primaryID}, s.primary.destroyed)
+	assert.Equal(s.T(), []string{fallbackID}, s.fallback.destroyed)
+}
+
+func (s *FailoverSuite) TestDestroyRunner_UnknownIDTriesEveryEngine() {
+	e := s.newEngine(Config{})
+
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, "orphan"))
+	assert.Equal(s.T(), []string{"orphan"}, s.primary.destroyed)
+	assert.Equal(s.T(), []string{"orphan"}, s.fallback.destroyed)
+}
+
+func (s *FailoverSuite) TestStartRunners_CountsFailedBatchOnce() {
+	s.primary.setStartErr(errors.New("down"))
+	e := s.newEngine(Config{AfterFailures: 2})
+
+	specs := []engine.RunnerSpec{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	started, err := e.StartRunners(s.ctx, specs)
+	require.Error(s.T(), err)
+	assert.Empty(s.T(), started)
+	assert.Equal(s.T(), "spot", e.Active())
+
+	_, _ = e.StartRunners(s.ctx, specs)
+	assert.Equal(s.T(), "standard", e.Active())
+
+	started, err = e.StartRunners(s.ctx, specs)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), map[string]string{"a": "standard-a", "b": "standard-b", "c": "standard-c"}, started)
+}
+
+func (s *FailoverSuite) TestShutdown_ShutsDownEveryEngine() {
+	e := s.newEngine(Config{})
+
+	require.NoError(s.T(), e.Shutdown(s.ctx))
+	assert.True(s.T(), s.primary.shutdown)
+	assert.True(s.T(), s.fallback.shutdown)
+}
+
+func (s *FailoverSuite) TestSetScaleSetID_SetsEveryEngine() {
+	e := s.newEngine(Config{})
+
+	e.SetScaleSetID(42)
+	assert.Equal(s.T(), 42, s.primary.scaleSetID)
+	assert.Equal(s.T(), 42, s.fallback.scaleSetID)
+}
+
+func (s *FailoverSuite) TestCheck_ReportsActiveEngine() {
+	s.primary.setStartErr(errors.New("down"))
+	e := s.newEngine(Config{AfterFailures: 1})
+	_, _ = e.StartRunner(s.ctx, "r1", "jit")
+
+	diags, err := e.Check(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "standard", diags["failover.active"])
+}
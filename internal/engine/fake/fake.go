@@ -0,0 +1,233 @@
+// Package fake implements an in-memory engine.Engine for tests: runners
+// are records, not containers or VMs, and never connect to GitHub.  It
+// can delay StartRunner and DestroyRunner and make a share of the calls
+// fail, so that end-to-end tests and downstream consumers can exercise
+// the scaler pipeline (retries, backoff, failover, reconciling) without
+// a backend.  It is selected with engine.fake.enable.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// ErrInjected is returned (wrapped) by the calls failure injection makes
+// fail.
+var ErrInjected = errors.New("injected failure")
+
+// ErrUnknownRunner is returned by DestroyRunner for an id the engine does
+// not track.
+var ErrUnknownRunner = errors.New("unknown runner")
+
+// Config configures a fake Engine.
+type Config struct {
+	// StartLatency and DestroyLatency delay every StartRunner and
+	// DestroyRunner call; Jitter adds up to that much more at random.
+	StartLatency   time.Duration
+	DestroyLatency time.Duration
+	Jitter         time.Duration
+
+	// StartFailureRate and DestroyFailureRate are the share of
+	// StartRunner and DestroyRunner calls, from 0 to 1, that fail with
+	// ErrInjected after their latency.
+	StartFailureRate   float64
+	DestroyFailureRate float64
+
+	// Seed seeds the random source of the jitter and the failures, for
+	// reproducible runs.  Zero picks a random seed.
+	Seed uint64
+
+	// RunID and ScaleSetName label the runners as a real engine would.
+	RunID        string
+	ScaleSetName string
+}
+
+// Stats counts the calls an Engine has handled.
+type Stats struct {
+	Started, Destroyed             int
+	StartFailures, DestroyFailures int
+}
+
+// Engine tracks runners in memory.
+type Engine struct {
+	cfg        Config
+	logger     *slog.Logger
+	scaleSetID atomic.Int64
+
+	mu      sync.Mutex
+	rand    *rand.Rand
+	seq     int
+	runners map[string]engine.ListedRunner // by id
+	stats   Stats
+}
+
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine             = (*Engine)(nil)
+	_ engine.Checker            = (*Engine)(nil)
+	_ engine.RunnerLister       = (*Engine)(nil)
+	_ engine.ScaleSetIdentifier = (*Engine)(nil)
+)
+
+// New creates a fake Engine.
+func New(cfg Config, logger *slog.Logger) (*Engine, error) {
+	if cfg.StartLatency < 0 || cfg.DestroyLatency < 0 || cfg.Jitter < 0 {
+		return nil, fmt.Errorf("fake: latencies must be >= 0")
+	}
+	if cfg.StartFailureRate < 0 || cfg.StartFailureRate > 1 || cfg.DestroyFailureRate < 0 || cfg.DestroyFailureRate > 1 {
+		return nil, fmt.Errorf("fake: failure rates must be between 0 and 1")
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	logger.Info("fake engine initialized",
+		slog.Duration("startLatency", cfg.StartLatency),
+		slog.Duration("destroyLatency", cfg.DestroyLatency),
+		slog.Float64("startFailureRate", cfg.StartFailureRate),
+		slog.Float64("destroyFailureRate", cfg.DestroyFailureRate),
+	)
+	return &Engine{
+		cfg:     cfg,
+		logger:  logger,
+		rand:    rand.New(rand.NewPCG(seed, seed)),
+		runners: make(map[string]engine.ListedRunner),
+	}, nil
+}
+
+// SetScaleSetID sets the scale set ID the runners are labelled with.
+func (e *Engine) SetScaleSetID(id int) {
+	e.scaleSetID.Store(int64(id))
+}
+
+// StartRunner records a runner after StartLatency and returns an id of
+// the form "fake-<n>", unless failure injection makes it fail.
+func (e *Engine) StartRunner(ctx context.Context, name string, _ string) (string, error) {
+	delay, fail := e.roll(e.cfg.StartLatency, e.cfg.StartFailureRate)
+	if err := sleep(ctx, delay); err != nil {
+		return "", err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if fail {
+		e.stats.StartFailures++
+		return "", fmt.Errorf("starting runner %s: %w", name, ErrInjected)
+	}
+	labels := engine.RunnerLabels(e.cfg.RunID)
+	maps.Copy(labels, engine.ScaleSetLabels(e.cfg.ScaleSetName, int(e.scaleSetID.Load())))
+	e.seq++
+	id := "fake-" + strconv.Itoa(e.seq)
+	e.runners[id] = engine.ListedRunner{ID: id, Name: name, Labels: labels}
+	e.stats.Started++
+	e.logger.Debug("runner started", slog.String("name", name), slog.String("id", id))
+	return id, nil
+}
+
+// DestroyRunner forgets a runner after DestroyLatency, unless failure
+// injection makes it fail.
+func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
+	delay, fail := e.roll(e.cfg.DestroyLatency, e.cfg.DestroyFailureRate)
+	if err := sleep(ctx, delay); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if fail {
+		e.stats.DestroyFailures++
+		return fmt.Errorf("destroying runner %s: %w", id, ErrInjected)
+	}
+	r, ok := e.runners[id]
+	if !ok {
+		return fmt.Errorf("destroying runner %s: %w", id, ErrUnknownRunner)
+	}
+	delete(e.runners, id)
+	e.stats.Destroyed++
+	e.logger.Debug("runner destroyed", slog.String("name", r.Name), slog.String("id", id))
+	return nil
+}
+
+// roll returns the delay of a call with the given latency and whether
+// the call fails, given its failure rate.
+func (e *Engine) roll(latency time.Duration, failureRate float64) (time.Duration, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cfg.Jitter > 0 {
+		latency += time.Duration(e.rand.Int64N(int64(e.cfg.Jitter) + 1))
+	}
+	return latency, failureRate > 0 && e.rand.Float64() < failureRate
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// ListRunners returns the runners carrying all of labels, sorted by id.
+func (e *Engine) ListRunners(_ context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []engine.ListedRunner
+	for _, id := range slices.Sorted(maps.Keys(e.runners)) {
+		r := e.runners[id]
+		if matches(r.Labels, labels) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func matches(have, want map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Stats returns the calls the engine has handled so far.
+func (e *Engine) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+// Check reports the runners tracked; it always succeeds.
+func (e *Engine) Check(context.Context) (map[string]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return map[string]string{"mode": "fake", "runners": strconv.Itoa(len(e.runners))}, nil
+}
+
+// Shutdown forgets every runner.
+func (e *Engine) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.logger.Info("fake engine shutting down", slog.Int("runners", len(e.runners)))
+	e.stats.Destroyed += len(e.runners)
+	clear(e.runners)
+	return nil
+}
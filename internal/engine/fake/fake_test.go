@@ -0,0 +1,99 @@
+package fake
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestEngine_TracksRunners(t *testing.T) {
+	e, err := New(Config{RunID: "run-1", ScaleSetName: "Linux Runners"}, discard)
+	require.NoError(t, err)
+	e.SetScaleSetID(42)
+	ctx := context.Background()
+
+	a, err := e.StartRunner(ctx, "runner-a", "jit")
+	require.NoError(t, err)
+	_, err = e.StartRunner(ctx, "runner-b", "jit")
+	require.NoError(t, err)
+	assert.Equal(t, "fake-1", a)
+
+	listed, err := e.ListRunners(ctx, engine.RunnerLabels("run-1"))
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, "runner-a", listed[0].Name)
+	assert.Equal(t, "linux-runners", listed[0].Labels[engine.ScaleSetNameLabel])
+	assert.Equal(t, "42", listed[0].Labels[engine.ScaleSetIDLabel])
+
+	require.NoError(t, e.DestroyRunner(ctx, a))
+	assert.ErrorIs(t, e.DestroyRunner(ctx, a), ErrUnknownRunner)
+	details, err := e.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", details["runners"])
+
+	require.NoError(t, e.Shutdown(ctx))
+	assert.Equal(t, Stats{Started: 2, Destroyed: 2}, e.Stats())
+}
+
+func TestEngine_InjectsFailures(t *testing.T) {
+	ctx := context.Background()
+	e, err := New(Config{StartFailureRate: 1}, discard)
+	require.NoError(t, err)
+	_, err = e.StartRunner(ctx, "runner-a", "jit")
+	assert.ErrorIs(t, err, ErrInjected)
+
+	e, err = New(Config{DestroyFailureRate: 1}, discard)
+	require.NoError(t, err)
+	id, err := e.StartRunner(ctx, "runner-a", "jit")
+	require.NoError(t, err)
+	assert.ErrorIs(t, e.DestroyRunner(ctx, id), ErrInjected)
+	assert.Equal(t, Stats{Started: 1, DestroyFailures: 1}, e.Stats())
+
+	// With a seed, the same calls fail on every run.
+	outcomes := func() []bool {
+		e, err := New(Config{StartFailureRate: 0.5, Seed: 7}, discard)
+		require.NoError(t, err)
+		var out []bool
+		for range 20 {
+			_, err := e.StartRunner(ctx, "r", "jit")
+			out = append(out, err == nil)
+		}
+		return out
+	}
+	first := outcomes()
+	assert.Equal(t, first, outcomes())
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
+
+func TestEngine_Latency(t *testing.T) {
+	e, err := New(Config{StartLatency: 50 * time.Millisecond}, discard)
+	require.NoError(t, err)
+
+	begin := time.Now()
+	_, err = e.StartRunner(context.Background(), "runner-a", "jit")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(begin), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = e.StartRunner(ctx, "runner-b", "jit")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, e.Stats().Started)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New(Config{StartFailureRate: 1.5}, discard)
+	assert.Error(t, err)
+	_, err = New(Config{DestroyLatency: -time.Second}, discard)
+	assert.Error(t, err)
+}
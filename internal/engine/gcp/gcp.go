@@ -9,9 +9,16 @@ package gcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	compute "cloud.google.com/go/compute/apiv1"
 	computepb "cloud.google.com/go/compute/apiv1/computepb"
@@ -19,8 +26,12 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/terrpan/scaleset/internal/egress"
 	"github.com/terrpan/scaleset/internal/engine"
 )
 
@@ -28,6 +39,10 @@ import (
 // tests can provide a mock implementation.
 type instancesAPI interface {
 	Insert(ctx context.Context, req *computepb.InsertInstanceRequest) (operationWaiter, error)
+	BulkInsert(ctx context.Context, req *computepb.BulkInsertInstanceRequest) (operationWaiter, error)
+	Get(ctx context.Context, req *computepb.GetInstanceRequest) (*computepb.Instance, error)
+	List(ctx context.Context, req *computepb.ListInstancesRequest) ([]*computepb.Instance, error)
+	SetMetadata(ctx context.Context, req *computepb.SetMetadataInstanceRequest) (operationWaiter, error)
 	Delete(ctx context.Context, req *computepb.DeleteInstanceRequest) (operationWaiter, error)
 	Close() error
 }
@@ -40,7 +55,7 @@ type operationWaiter interface {
 
 // realInstancesClient wraps *compute.InstancesClient to satisfy
 // instancesAPI, adapting the return type from *compute.Operation to
-// operationWaiter.
+// operationWaiter and draining list iterators.
 type realInstancesClient struct {
 	c *compute.InstancesClient
 }
@@ -49,6 +64,33 @@ func (r *realInstancesClient) Insert(ctx context.Context, req *computepb.InsertI
 	return r.c.Insert(ctx, req)
 }
 
+func (r *realInstancesClient) BulkInsert(ctx context.Context, req *computepb.BulkInsertInstanceRequest) (operationWaiter, error) {
+	return r.c.BulkInsert(ctx, req)
+}
+
+func (r *realInstancesClient) Get(ctx context.Context, req *computepb.GetInstanceRequest) (*computepb.Instance, error) {
+	return r.c.Get(ctx, req)
+}
+
+func (r *realInstancesClient) List(ctx context.Context, req *computepb.ListInstancesRequest) ([]*computepb.Instance, error) {
+	var out []*computepb.Instance
+	it := r.c.List(ctx, req)
+	for {
+		inst, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inst)
+	}
+}
+
+func (r *realInstancesClient) SetMetadata(ctx context.Context, req *computepb.SetMetadataInstanceRequest) (operationWaiter, error) {
+	return r.c.SetMetadata(ctx, req)
+}
+
 func (r *realInstancesClient) Delete(ctx context.Context, req *computepb.DeleteInstanceRequest) (operationWaiter, error) {
 	return r.c.Delete(ctx, req)
 }
@@ -57,14 +99,75 @@ func (r *realInstancesClient) Close() error {
 	return r.c.Close()
 }
 
+// regionsAPI abstracts the Regions client used for readiness checks.
+// *compute.RegionsClient satisfies it directly.
+type regionsAPI interface {
+	Get(ctx context.Context, req *computepb.GetRegionRequest, opts ...gax.CallOption) (*computepb.Region, error)
+	Close() error
+}
+
+// machineTypesAPI abstracts the MachineTypes client used to look up the
+// vCPU count of Config.MachineType.  *compute.MachineTypesClient
+// satisfies it directly.
+type machineTypesAPI interface {
+	Get(ctx context.Context, req *computepb.GetMachineTypeRequest, opts ...gax.CallOption) (*computepb.MachineType, error)
+	Close() error
+}
+
+// networksAPI reads a VPC network to tell auto-mode from custom-mode.
+// *compute.NetworksClient satisfies it directly.
+type networksAPI interface {
+	Get(ctx context.Context, req *computepb.GetNetworkRequest, opts ...gax.CallOption) (*computepb.Network, error)
+	Close() error
+}
+
+// subnetworksAPI lists a region's subnetworks for subnet auto-selection.
+type subnetworksAPI interface {
+	List(ctx context.Context, req *computepb.ListSubnetworksRequest) ([]*computepb.Subnetwork, error)
+	Close() error
+}
+
+// realSubnetworksClient wraps *compute.SubnetworksClient to satisfy
+// subnetworksAPI, draining the list iterator.
+type realSubnetworksClient struct {
+	c *compute.SubnetworksClient
+}
+
+func (r *realSubnetworksClient) List(ctx context.Context, req *computepb.ListSubnetworksRequest) ([]*computepb.Subnetwork, error) {
+	var out []*computepb.Subnetwork
+	it := r.c.List(ctx, req)
+	for {
+		sn, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sn)
+	}
+}
+
+func (r *realSubnetworksClient) Close() error {
+	return r.c.Close()
+}
+
 // Config holds GCP-specific engine settings.
 type Config struct {
 	// Project is the GCP project ID (required).
 	Project string
 
-	// Zone is the GCP zone where runner VMs are created (required).
+	// Zone is the GCP zone where runner VMs are created (required).  Its
+	// region is the one whose quotas and subnets are used.
 	Zone string
 
+	// Zones spreads runner VMs round-robin across several zones of
+	// Zone's region, Zone among them.  A start that fails because a
+	// zone is out of capacity (ZONE_RESOURCE_POOL_EXHAUSTED) is retried
+	// in the next zone, and the exhausted zone is tried last for
+	// zoneCooldown.  Empty means Zone alone.
+	Zones []string
+
 	// MachineType is the Compute Engine machine type.
 	// Default: "e2-medium".
 	MachineType string
@@ -78,13 +181,34 @@ type Config struct {
 	// DiskSizeGB is the boot disk size in GB.  Default: 50.
 	DiskSizeGB int64
 
+	// DiskType is the boot disk type, e.g. "pd-balanced", "pd-extreme" or
+	// "hyperdisk-balanced".  Default: "pd-ssd".
+	DiskType string
+
+	// DiskIOPS is the boot disk's provisioned IOPS.  Only disk types for
+	// which SupportsProvisionedIOPS is true accept it.  Zero uses the
+	// disk type's default.
+	DiskIOPS int64
+
+	// DiskThroughput is the boot disk's provisioned throughput in MiB/s.
+	// Only disk types for which SupportsProvisionedThroughput is true
+	// accept it.  Zero uses the disk type's default.
+	DiskThroughput int64
+
 	// Network is the VPC network (optional).  Defaults to "default".
+	// Either a bare name in Project or a self-link / partial URL such as
+	// "projects/<host-project>/global/networks/<name>".
 	Network string
 
 	// Subnet is the subnetwork (optional).  If empty, the default subnet
 	// for the zone is used.
 	Subnet string
 
+	// AutoSubnet selects the subnet of Network in the zone's region when
+	// Subnet is empty and Network is a custom-mode VPC, which has no
+	// default subnet for GCP to pick.  It is resolved once in New.
+	AutoSubnet bool
+
 	// PublicIP controls whether runner VMs get an external IP.
 	// Default: true.
 	PublicIP bool
@@ -93,6 +217,67 @@ type Config struct {
 	// runner VMs (optional).  If empty, the project's default compute
 	// service account is used.
 	ServiceAccount string
+
+	// UseBulkInsert enables the instances.bulkInsert API for scale-ups of
+	// at least BulkInsertThreshold runners.  bulkInsert cannot set
+	// per-instance metadata, so VMs are created without a JIT config and
+	// each runner's config is pushed with SetMetadata afterwards; the
+	// startup script polls the metadata server until it appears.
+	UseBulkInsert bool
+
+	// BulkInsertThreshold is the minimum scale-up size that uses
+	// bulkInsert when UseBulkInsert is set.  Default: 5.
+	BulkInsertThreshold int
+
+	// ZoneInRunnerName appends the zone to runner (and therefore VM)
+	// names, e.g. "runner-1a2b3c4d-us-central1-a".
+	ZoneInRunnerName bool
+
+	// RunID is recorded on every runner VM with the engine.RunIDLabel
+	// label, next to engine.ManagedLabel, so the VMs of a crashed
+	// process can be found by the cleanup command.
+	RunID string
+
+	// ScaleSetName is recorded on every runner VM and its boot disk with
+	// the engine.ScaleSetNameLabel label, next to engine.ManagedByLabel
+	// and, once SetScaleSetID was called, engine.ScaleSetIDLabel, so
+	// that billing can be broken down by scale set.
+	ScaleSetName string
+
+	// Metadata is extra instance metadata set on every runner VM, e.g.
+	// cloud-init "user-data".  It must not set
+	// ACTIONS_RUNNER_INPUT_JITCONFIG or StartupScriptKey.
+	Metadata map[string]string
+
+	// StartupScript is set as the StartupScriptKey metadata of every
+	// runner VM.  The startup scripts of the images in docs/gcp run it
+	// before the runner starts, e.g. to install tools or mount disks,
+	// and do not start the runner if it fails.  Unlike GCE's own
+	// "startup-script", it is sure to finish before the job begins.
+	StartupScript string
+
+	// Accelerators are attached to every runner VM.  VMs with
+	// accelerators cannot live-migrate, so they are set to terminate
+	// on host maintenance.
+	Accelerators []Accelerator
+
+	// Spot creates runner VMs with the SPOT provisioning model.  GCP may
+	// preempt a spot VM at any time; it is then stopped rather than
+	// deleted, so ListRunners can report it as Preempted and the
+	// scaler's reconciler can delete and replace it.
+	Spot bool
+
+	// Egress sets the proxy and extra CAs of the Compute Engine API
+	// clients.  The zero value keeps the SDK's transport, which honours
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	Egress egress.Config
+}
+
+// Accelerator attaches Count accelerators of Type (e.g.
+// "nvidia-tesla-t4") to a runner VM.
+type Accelerator struct {
+	Type  string
+	Count int32
 }
 
 // Engine manages GitHub Actions runners as GCP Compute Engine VMs.
@@ -102,8 +287,22 @@ type Engine struct {
 	cfg      Config
 	logger   *slog.Logger
 
+	// Optional read-only clients; nil disables quota diagnostics and
+	// CheckQuotaHeadroom.
+	regions      regionsAPI
+	machineTypes machineTypesAPI
+
+	zones []string // Zones, or Zone alone
+	now   func() time.Time
+
 	mu        sync.Mutex
-	instances map[string]string // runner name -> instance name
+	instances map[string]string    // runner name -> instance name
+	zoneOf    map[string]string    // instance name -> zone
+	nextZone  int                  // index into zones of the next start
+	exhausted map[string]time.Time // zone -> when it last ran out of capacity
+
+	// scaleSetID is recorded on every VM; see SetScaleSetID.
+	scaleSetID atomic.Int64
 
 	// OpenTelemetry instrumentation
 	tracer trace.Tracer
@@ -116,8 +315,45 @@ type closerOnly interface {
 	Close() error
 }
 
-// Compile-time check that Engine satisfies the engine.Engine interface.
-var _ engine.Engine = (*Engine)(nil)
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine             = (*Engine)(nil)
+	_ engine.BatchStarter       = (*Engine)(nil)
+	_ engine.Checker            = (*Engine)(nil)
+	_ engine.NameSuffixer       = (*Engine)(nil)
+	_ engine.RunnerFinder       = (*Engine)(nil)
+	_ engine.RunnerLister       = (*Engine)(nil)
+	_ engine.ScaleSetIdentifier = (*Engine)(nil)
+)
+
+// StartupScriptKey is the instance metadata key of Config.StartupScript.
+const StartupScriptKey = "scaleset-startup-script"
+
+// zoneCooldown is how long a zone that ran out of capacity is tried
+// after the other zones.
+const zoneCooldown = 5 * time.Minute
+
+// checkedQuotas are the regional quotas reported by Check.
+var checkedQuotas = []string{"CPUS", "INSTANCES", "SSD_TOTAL_GB", "IN_USE_ADDRESSES"}
+
+// clientOptions returns the options that route the Compute Engine API
+// clients through eg: an HTTP client whose transport authenticates with
+// Application Default Credentials on top of eg's proxy and roots.  A
+// zero eg needs no options.
+func clientOptions(ctx context.Context, eg egress.Config) ([]option.ClientOption, error) {
+	if eg.IsZero() {
+		return nil, nil
+	}
+	base, err := eg.Transport()
+	if err != nil {
+		return nil, fmt.Errorf("gcp transport: %w", err)
+	}
+	rt, err := htransport.NewTransport(ctx, base, option.WithScopes(compute.DefaultAuthScopes()...))
+	if err != nil {
+		return nil, fmt.Errorf("gcp transport: %w", err)
+	}
+	return []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: rt})}, nil
+}
 
 // New creates a GCP engine using Application Default Credentials.
 func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Engine, error) {
@@ -127,31 +363,73 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Engine, error)
 	if cfg.DiskSizeGB == 0 {
 		cfg.DiskSizeGB = 50
 	}
+	if cfg.DiskType == "" {
+		cfg.DiskType = "pd-ssd"
+	}
 	if cfg.Network == "" {
 		cfg.Network = "default"
 	}
+	if cfg.BulkInsertThreshold == 0 {
+		cfg.BulkInsertThreshold = 5
+	}
 
-	client, err := compute.NewInstancesRESTClient(ctx)
+	opts, err := clientOptions(ctx, cfg.Egress)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := compute.NewInstancesRESTClient(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("gcp instances client: %w", err)
 	}
 
-	opClient, err := compute.NewZoneOperationsRESTClient(ctx)
+	opClient, err := compute.NewZoneOperationsRESTClient(ctx, opts...)
 	if err != nil {
 		_ = client.Close()
 		return nil, fmt.Errorf("gcp zone operations client: %w", err)
 	}
 
-	return newEngine(&realInstancesClient{c: client}, opClient, cfg, logger), nil
+	regions, err := compute.NewRegionsRESTClient(ctx, opts...)
+	if err != nil {
+		_ = client.Close()
+		_ = opClient.Close()
+		return nil, fmt.Errorf("gcp regions client: %w", err)
+	}
+
+	machineTypes, err := compute.NewMachineTypesRESTClient(ctx, opts...)
+	if err != nil {
+		_ = client.Close()
+		_ = opClient.Close()
+		_ = regions.Close()
+		return nil, fmt.Errorf("gcp machine types client: %w", err)
+	}
+
+	e := newEngine(&realInstancesClient{c: client}, opClient, cfg, logger)
+	e.regions = regions
+	e.machineTypes = machineTypes
+
+	if cfg.AutoSubnet && cfg.Subnet == "" {
+		if err := e.autoSelectSubnet(ctx); err != nil {
+			_ = e.Shutdown(ctx)
+			return nil, err
+		}
+	}
+	return e, nil
 }
 
 // newEngine is the internal constructor used by New and by tests.
 func newEngine(client instancesAPI, opClient closerOnly, cfg Config, logger *slog.Logger) *Engine {
+	zones := cfg.Zones
+	if len(zones) == 0 {
+		zones = []string{cfg.Zone}
+	}
 	logger.Info("gcp engine initialized",
 		slog.String("project", cfg.Project),
 		slog.String("zone", cfg.Zone),
+		slog.Any("zones", zones),
 		slog.String("machine_type", cfg.MachineType),
 		slog.String("image", cfg.Image),
+		slog.Bool("spot", cfg.Spot),
 	)
 
 	return &Engine{
@@ -159,14 +437,19 @@ func newEngine(client instancesAPI, opClient closerOnly, cfg Config, logger *slo
 		opClient:  opClient,
 		cfg:       cfg,
 		logger:    logger,
+		zones:     zones,
+		now:       time.Now,
 		instances: make(map[string]string),
+		zoneOf:    make(map[string]string),
+		exhausted: make(map[string]time.Time),
 		tracer:    otel.Tracer("scaleset/engine/gcp"),
 	}
 }
 
 // StartRunner creates and starts a GCP VM that runs a GitHub Actions
 // runner with the provided JIT configuration.  The JIT config is passed
-// via instance metadata so the startup script can read it.
+// via instance metadata so the startup script can read it.  With
+// several zones, a zone out of capacity is skipped for the next one.
 func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string) (string, error) {
 	ctx, span := e.tracer.Start(ctx, "engine.gcp.StartRunner")
 	defer span.End()
@@ -174,27 +457,376 @@ func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string)
 	span.SetAttributes(
 		attribute.String("runner.name", name),
 		attribute.String("gcp.project", e.cfg.Project),
-		attribute.String("gcp.zone", e.cfg.Zone),
 		attribute.String("gcp.machine_type", e.cfg.MachineType),
 	)
 
-	machineType := fmt.Sprintf("zones/%s/machineTypes/%s", e.cfg.Zone, e.cfg.MachineType)
+	var err error
+	for _, zone := range e.zoneOrder() {
+		err = e.insertInstance(ctx, name, jitConfig, zone)
+		if err == nil {
+			e.mu.Lock()
+			e.instances[name] = name
+			e.zoneOf[name] = zone
+			e.mu.Unlock()
+
+			span.SetAttributes(
+				attribute.String("gcp.zone", zone),
+				attribute.String("gcp.instance_name", name),
+			)
+			e.logger.Info("runner VM started",
+				slog.String("name", name),
+				slog.String("zone", zone),
+			)
+
+			// For GCP, the instance name is the opaque ID.
+			return name, nil
+		}
+		if !isZoneExhausted(err) {
+			return "", err
+		}
+		e.markExhausted(zone)
+		span.AddEvent("zone out of capacity", trace.WithAttributes(attribute.String("gcp.zone", zone)))
+		e.logger.Warn("zone out of capacity for runner VM",
+			slog.String("name", name),
+			slog.String("zone", zone),
+			slog.String("error", err.Error()),
+		)
+	}
+	return "", err
+}
+
+// insertInstance creates the runner VM name in zone and waits for it.
+func (e *Engine) insertInstance(ctx context.Context, name, jitConfig, zone string) error {
+	machineType := fmt.Sprintf("zones/%s/machineTypes/%s", zone, e.cfg.MachineType)
+
+	instance := &computepb.Instance{
+		Name:              proto.String(name),
+		MachineType:       proto.String(machineType),
+		Disks:             []*computepb.AttachedDisk{e.bootDisk(fmt.Sprintf("zones/%s/diskTypes/%s", zone, e.cfg.DiskType))},
+		NetworkInterfaces: []*computepb.NetworkInterface{e.networkInterface()},
+		Metadata:          e.instanceMetadata(jitConfig, ""),
+		ServiceAccounts:   e.serviceAccounts(),
+		Labels:            e.labels(),
+		GuestAccelerators: e.guestAccelerators(fmt.Sprintf("zones/%s/acceleratorTypes/", zone)),
+		Scheduling:        e.scheduling(),
+	}
+
+	e.logger.Info("creating runner VM",
+		slog.String("name", name),
+		slog.String("machine_type", e.cfg.MachineType),
+		slog.String("zone", zone),
+	)
+
+	op, err := e.client.Insert(ctx, &computepb.InsertInstanceRequest{
+		Project:          e.cfg.Project,
+		Zone:             zone,
+		InstanceResource: instance,
+	})
+	if err != nil {
+		return fmt.Errorf("insert instance %s in %s: %w", name, zone, err)
+	}
+
+	// Wait for the insert operation to complete.
+	trace.SpanFromContext(ctx).AddEvent("waiting for GCP operation")
+	if err := op.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for instance %s in %s: %w", name, zone, err)
+	}
+	return nil
+}
+
+// zoneOrder returns the zones to try for the next start: round-robin
+// from the zone after the previous start's, with zones that ran out of
+// capacity within zoneCooldown moved to the end.
+func (e *Engine) zoneOrder() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := len(e.zones)
+	start := e.nextZone % n
+	e.nextZone = start + 1
+
+	order := make([]string, 0, n)
+	var cooling []string
+	for i := range n {
+		zone := e.zones[(start+i)%n]
+		if t, ok := e.exhausted[zone]; ok && e.now().Sub(t) < zoneCooldown {
+			cooling = append(cooling, zone)
+			continue
+		}
+		order = append(order, zone)
+	}
+	return append(order, cooling...)
+}
+
+// markExhausted records that zone ran out of capacity.
+func (e *Engine) markExhausted(zone string) {
+	e.mu.Lock()
+	e.exhausted[zone] = e.now()
+	e.mu.Unlock()
+}
+
+// isZoneExhausted reports whether err says the zone has no capacity
+// left for the VM, a stockout that another zone may not share.
+func isZoneExhausted(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "ZONE_RESOURCE_POOL_EXHAUSTED") ||
+		strings.Contains(msg, "does not have enough resources available")
+}
+
+// StartRunners implements engine.BatchStarter.  When UseBulkInsert is
+// set and the batch has at least BulkInsertThreshold runners, all VMs
+// are created with a single bulkInsert call; otherwise each runner is
+// started individually via StartRunner.
+func (e *Engine) StartRunners(ctx context.Context, specs []engine.RunnerSpec) (map[string]string, error) {
+	if len(specs) == 0 {
+		return map[string]string{}, nil
+	}
+	if !e.useBulkInsert(len(specs)) {
+		return e.startIndividually(ctx, specs)
+	}
+	return e.bulkStart(ctx, specs)
+}
+
+// UseBatch implements engine.BatchDecider: without bulkInsert the scaler
+// starts the runners itself, in parallel and with retries, rather than
+// through StartRunners.
+func (e *Engine) UseBatch(n int) bool {
+	return e.useBulkInsert(n)
+}
+
+// useBulkInsert reports whether a batch of n runners should be created
+// with bulkInsert.
+func (e *Engine) useBulkInsert(n int) bool {
+	return e.cfg.UseBulkInsert && n >= e.cfg.BulkInsertThreshold
+}
+
+// startIndividually starts runners one at a time, stopping at the first
+// failure.
+func (e *Engine) startIndividually(ctx context.Context, specs []engine.RunnerSpec) (map[string]string, error) {
+	started := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		id, err := e.StartRunner(ctx, spec.Name, spec.JITConfig)
+		if err != nil {
+			return started, err
+		}
+		started[spec.Name] = id
+	}
+	return started, nil
+}
+
+// bulkStart creates all runner VMs with one bulkInsert call and then
+// delivers each runner's JIT config through SetMetadata.  bulkInsert is
+// all-or-nothing (min_count defaults to count), so a failed insert
+// leaves no VMs behind; with several zones, a zone out of capacity is
+// skipped for the next one.  A runner whose metadata cannot be set is
+// deleted and reported in the returned error.
+func (e *Engine) bulkStart(ctx context.Context, specs []engine.RunnerSpec) (map[string]string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.gcp.StartRunners")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("gcp.bulk_count", len(specs)),
+		attribute.String("gcp.project", e.cfg.Project),
+		attribute.String("gcp.machine_type", e.cfg.MachineType),
+	)
+
+	var (
+		zone string
+		err  error
+	)
+	for _, zone = range e.zoneOrder() {
+		err = e.bulkInsert(ctx, specs, zone)
+		if err == nil || !isZoneExhausted(err) {
+			break
+		}
+		e.markExhausted(zone)
+		span.AddEvent("zone out of capacity", trace.WithAttributes(attribute.String("gcp.zone", zone)))
+		e.logger.Warn("zone out of capacity for bulk insert",
+			slog.Int("count", len(specs)),
+			slog.String("zone", zone),
+			slog.String("error", err.Error()),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("gcp.zone", zone))
+
+	e.mu.Lock()
+	for _, spec := range specs {
+		e.zoneOf[spec.Name] = zone
+	}
+	e.mu.Unlock()
+
+	started := make(map[string]string, len(specs))
+	var errs []error
+	for _, spec := range specs {
+		if err := e.setJITMetadata(ctx, zone, spec.Name, spec.JITConfig); err != nil {
+			e.logger.Error("failed to deliver JIT config, deleting runner VM",
+				slog.String("name", spec.Name),
+				slog.String("error", err.Error()),
+			)
+			if derr := e.DestroyRunner(ctx, spec.Name); derr != nil {
+				err = errors.Join(err, derr)
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		e.mu.Lock()
+		e.instances[spec.Name] = spec.Name
+		e.mu.Unlock()
+		started[spec.Name] = spec.Name
+	}
+
+	e.logger.Info("bulk runner VMs started",
+		slog.Int("started", len(started)),
+		slog.Int("failed", len(errs)),
+		slog.String("zone", zone),
+	)
+
+	return started, errors.Join(errs...)
+}
+
+// bulkInsert creates the VMs of specs in zone with one bulkInsert call,
+// without their JIT configs, and waits for them.
+func (e *Engine) bulkInsert(ctx context.Context, specs []engine.RunnerSpec, zone string) error {
+	perInstance := make(map[string]*computepb.BulkInsertInstanceResourcePerInstanceProperties, len(specs))
+	for _, spec := range specs {
+		perInstance[spec.Name] = &computepb.BulkInsertInstanceResourcePerInstanceProperties{
+			Name: proto.String(spec.Name),
+		}
+	}
+
+	// Instance properties take bare resource names, not zonal URLs.
+	props := &computepb.InstanceProperties{
+		MachineType:       proto.String(e.cfg.MachineType),
+		Disks:             []*computepb.AttachedDisk{e.bootDisk(e.cfg.DiskType)},
+		NetworkInterfaces: []*computepb.NetworkInterface{e.networkInterface()},
+		ServiceAccounts:   e.serviceAccounts(),
+		Labels:            e.labels(),
+		GuestAccelerators: e.guestAccelerators(""),
+		Scheduling:        e.scheduling(),
+	}
+	if len(e.cfg.Metadata) > 0 || e.cfg.StartupScript != "" {
+		// The JIT configs follow through SetMetadata, but the extra
+		// metadata (e.g. the startup script) must be there at boot.
+		props.Metadata = e.instanceMetadata("", "")
+	}
+
+	e.logger.Info("bulk creating runner VMs",
+		slog.Int("count", len(specs)),
+		slog.String("machine_type", e.cfg.MachineType),
+		slog.String("zone", zone),
+	)
 
-	// Boot disk from the pre-built runner image.
-	disk := &computepb.AttachedDisk{
-		AutoDelete: proto.Bool(true),
-		Boot:       proto.Bool(true),
-		InitializeParams: &computepb.AttachedDiskInitializeParams{
-			SourceImage: proto.String(e.cfg.Image),
-			DiskSizeGb:  proto.Int64(e.cfg.DiskSizeGB),
-			DiskType:    proto.String(fmt.Sprintf("zones/%s/diskTypes/pd-ssd", e.cfg.Zone)),
+	op, err := e.client.BulkInsert(ctx, &computepb.BulkInsertInstanceRequest{
+		Project: e.cfg.Project,
+		Zone:    zone,
+		BulkInsertInstanceResourceResource: &computepb.BulkInsertInstanceResource{
+			Count:                 proto.Int64(int64(len(specs))),
+			InstanceProperties:    props,
+			PerInstanceProperties: perInstance,
 		},
+	})
+	if err != nil {
+		return fmt.Errorf("bulk insert %d instances in %s: %w", len(specs), zone, err)
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("waiting for GCP bulk operation")
+	if err := op.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for bulk insert of %d instances in %s: %w", len(specs), zone, err)
 	}
+	return nil
+}
 
-	// Network interface.
-	networkURL := fmt.Sprintf("global/networks/%s", e.cfg.Network)
+// setJITMetadata writes the JIT config into an existing instance's
+// metadata.  SetMetadata requires the current fingerprint, so the
+// instance is read first.
+func (e *Engine) setJITMetadata(ctx context.Context, zone, name, jitConfig string) error {
+	inst, err := e.client.Get(ctx, &computepb.GetInstanceRequest{
+		Project:  e.cfg.Project,
+		Zone:     zone,
+		Instance: name,
+	})
+	if err != nil {
+		return fmt.Errorf("get instance %s: %w", name, err)
+	}
+
+	op, err := e.client.SetMetadata(ctx, &computepb.SetMetadataInstanceRequest{
+		Project:          e.cfg.Project,
+		Zone:             zone,
+		Instance:         name,
+		MetadataResource: e.instanceMetadata(jitConfig, inst.GetMetadata().GetFingerprint()),
+	})
+	if err != nil {
+		return fmt.Errorf("set metadata on %s: %w", name, err)
+	}
+	if err := op.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for metadata on %s: %w", name, err)
+	}
+	return nil
+}
+
+// bootDisk returns the boot disk built from the runner image.  diskType
+// is a zonal URL for Insert and a bare type name for bulkInsert.
+func (e *Engine) bootDisk(diskType string) *computepb.AttachedDisk {
+	params := &computepb.AttachedDiskInitializeParams{
+		SourceImage: proto.String(e.cfg.Image),
+		DiskSizeGb:  proto.Int64(e.cfg.DiskSizeGB),
+		DiskType:    proto.String(diskType),
+		Labels:      e.labels(),
+	}
+	if e.cfg.DiskIOPS > 0 {
+		params.ProvisionedIops = proto.Int64(e.cfg.DiskIOPS)
+	}
+	if e.cfg.DiskThroughput > 0 {
+		params.ProvisionedThroughput = proto.Int64(e.cfg.DiskThroughput)
+	}
+	return &computepb.AttachedDisk{
+		AutoDelete:       proto.Bool(true),
+		Boot:             proto.Bool(true),
+		InitializeParams: params,
+	}
+}
+
+// labels returns the labels of a runner VM and its boot disk: the run
+// labels and the scale set's identity.
+func (e *Engine) labels() map[string]string {
+	labels := engine.ScaleSetLabels(e.cfg.ScaleSetName, int(e.scaleSetID.Load()))
+	maps.Copy(labels, engine.RunnerLabels(e.cfg.RunID))
+	return labels
+}
+
+// SetScaleSetID implements engine.ScaleSetIdentifier.
+func (e *Engine) SetScaleSetID(id int) {
+	e.scaleSetID.Store(int64(id))
+}
+
+// SupportsProvisionedIOPS reports whether disks of diskType accept a
+// provisioned IOPS value.
+func SupportsProvisionedIOPS(diskType string) bool {
+	switch diskType {
+	case "pd-extreme", "hyperdisk-balanced", "hyperdisk-balanced-high-availability", "hyperdisk-extreme":
+		return true
+	}
+	return false
+}
+
+// SupportsProvisionedThroughput reports whether disks of diskType accept
+// a provisioned throughput value.
+func SupportsProvisionedThroughput(diskType string) bool {
+	switch diskType {
+	case "hyperdisk-balanced", "hyperdisk-balanced-high-availability", "hyperdisk-throughput", "hyperdisk-ml":
+		return true
+	}
+	return false
+}
+
+// networkInterface returns the runner VM's network interface.
+func (e *Engine) networkInterface() *computepb.NetworkInterface {
 	nic := &computepb.NetworkInterface{
-		Network: proto.String(networkURL),
+		Network: proto.String(networkURL(e.cfg.Network)),
 	}
 	if e.cfg.Subnet != "" {
 		nic.Subnetwork = proto.String(e.cfg.Subnet)
@@ -207,72 +839,222 @@ func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string)
 			},
 		}
 	}
+	return nic
+}
 
-	// Instance metadata: pass JIT config to the startup script.
-	metadata := &computepb.Metadata{
-		Items: []*computepb.Items{
-			{
-				Key:   proto.String("ACTIONS_RUNNER_INPUT_JITCONFIG"),
-				Value: proto.String(jitConfig),
-			},
+// serviceAccounts returns the service account attachment, or nil if no
+// service account is configured.
+func (e *Engine) serviceAccounts() []*computepb.ServiceAccount {
+	if e.cfg.ServiceAccount == "" {
+		return nil
+	}
+	return []*computepb.ServiceAccount{
+		{
+			Email:  proto.String(e.cfg.ServiceAccount),
+			Scopes: []string{"https://www.googleapis.com/auth/cloud-platform"},
 		},
 	}
+}
 
-	instance := &computepb.Instance{
-		Name:              proto.String(name),
-		MachineType:       proto.String(machineType),
-		Disks:             []*computepb.AttachedDisk{disk},
-		NetworkInterfaces: []*computepb.NetworkInterface{nic},
-		Metadata:          metadata,
+// guestAccelerators returns the configured accelerators, or nil if none.
+// typePrefix is prepended to each type: a zonal URL prefix for Insert,
+// empty for bulkInsert, which takes bare type names.
+func (e *Engine) guestAccelerators(typePrefix string) []*computepb.AcceleratorConfig {
+	var out []*computepb.AcceleratorConfig
+	for _, a := range e.cfg.Accelerators {
+		out = append(out, &computepb.AcceleratorConfig{
+			AcceleratorType:  proto.String(typePrefix + a.Type),
+			AcceleratorCount: proto.Int32(a.Count),
+		})
 	}
+	return out
+}
 
-	// Attach a service account if configured.
-	if e.cfg.ServiceAccount != "" {
-		instance.ServiceAccounts = []*computepb.ServiceAccount{
-			{
-				Email:  proto.String(e.cfg.ServiceAccount),
-				Scopes: []string{"https://www.googleapis.com/auth/cloud-platform"},
-			},
+// scheduling returns the VM scheduling policy: GPU VMs cannot
+// live-migrate and must terminate on host maintenance, and neither they
+// nor spot VMs may restart automatically, since a runner's JIT config
+// is single-use.  A preempted spot VM is stopped so it stays visible to
+// ListRunners.  Nil keeps the defaults.
+func (e *Engine) scheduling() *computepb.Scheduling {
+	if e.cfg.Spot {
+		return &computepb.Scheduling{
+			ProvisioningModel:         proto.String("SPOT"),
+			InstanceTerminationAction: proto.String("STOP"),
+			AutomaticRestart:          proto.Bool(false),
+			OnHostMaintenance:         proto.String("TERMINATE"),
 		}
 	}
+	if len(e.cfg.Accelerators) == 0 {
+		return nil
+	}
+	return &computepb.Scheduling{
+		AutomaticRestart:  proto.Bool(false),
+		OnHostMaintenance: proto.String("TERMINATE"),
+	}
+}
 
-	e.logger.Info("creating runner VM",
-		slog.String("name", name),
-		slog.String("machine_type", e.cfg.MachineType),
-		slog.String("zone", e.cfg.Zone),
-	)
+// preempted reports whether inst is a spot VM that GCP has reclaimed, or
+// a GPU VM that host maintenance terminated.  scaleset never stops VMs,
+// so a stopping or stopped one was ended by GCP.
+func (e *Engine) preempted(inst *computepb.Instance) bool {
+	if !e.cfg.Spot && len(e.cfg.Accelerators) == 0 {
+		return false
+	}
+	switch inst.GetStatus() {
+	case "STOPPING", "TERMINATED":
+		return true
+	}
+	return false
+}
 
-	op, err := e.client.Insert(ctx, &computepb.InsertInstanceRequest{
-		Project:          e.cfg.Project,
-		Zone:             e.cfg.Zone,
-		InstanceResource: instance,
-	})
-	if err != nil {
-		return "", fmt.Errorf("insert instance %s: %w", name, err)
+// instanceMetadata returns instance metadata carrying the JIT config for
+// the startup script, if jitConfig is set, the configured startup
+// script and the extra metadata.  fingerprint is required when updating metadata on an
+// existing instance and empty on create.
+func (e *Engine) instanceMetadata(jitConfig, fingerprint string) *computepb.Metadata {
+	md := &computepb.Metadata{}
+	if jitConfig != "" {
+		md.Items = append(md.Items, &computepb.Items{
+			Key:   proto.String("ACTIONS_RUNNER_INPUT_JITCONFIG"),
+			Value: proto.String(jitConfig),
+		})
+	}
+	if e.cfg.StartupScript != "" {
+		md.Items = append(md.Items, &computepb.Items{
+			Key:   proto.String(StartupScriptKey),
+			Value: proto.String(e.cfg.StartupScript),
+		})
+	}
+	keys := make([]string, 0, len(e.cfg.Metadata))
+	for k := range e.cfg.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		md.Items = append(md.Items, &computepb.Items{
+			Key:   proto.String(k),
+			Value: proto.String(e.cfg.Metadata[k]),
+		})
+	}
+	if fingerprint != "" {
+		md.Fingerprint = proto.String(fingerprint)
 	}
+	return md
+}
 
-	// Wait for the insert operation to complete.
-	span.AddEvent("waiting for GCP operation")
-	if err := op.Wait(ctx); err != nil {
-		return "", fmt.Errorf("waiting for instance %s: %w", name, err)
+// FindRunner implements engine.RunnerFinder.  Instances are named after
+// the runner, so the key is looked up with a direct Get in each zone
+// rather than a label filter.  An instance that is provisioning or
+// running is adopted; one that is stopping or stopped cannot serve a job
+// and is deleted so the name can be reused.
+func (e *Engine) FindRunner(ctx context.Context, key string) (string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.gcp.FindRunner")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("runner.name", key))
+
+	var inst *computepb.Instance
+	for _, zone := range e.zones {
+		found, err := e.client.Get(ctx, &computepb.GetInstanceRequest{
+			Project:  e.cfg.Project,
+			Zone:     zone,
+			Instance: key,
+		})
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("get instance %s: %w", key, err)
+		}
+		inst = found
+		span.SetAttributes(attribute.String("gcp.zone", zone))
+		e.mu.Lock()
+		e.zoneOf[key] = zone
+		e.mu.Unlock()
+		break
+	}
+	if inst == nil {
+		return "", nil
+	}
+
+	switch inst.GetStatus() {
+	case "PROVISIONING", "STAGING", "RUNNING":
+	default:
+		e.logger.Info("deleting leftover runner VM that is not running",
+			slog.String("name", key),
+			slog.String("status", inst.GetStatus()),
+		)
+		if err := e.DestroyRunner(ctx, key); err != nil {
+			return "", err
+		}
+		return "", nil
 	}
 
 	e.mu.Lock()
-	e.instances[name] = name
+	e.instances[key] = key
 	e.mu.Unlock()
 
-	span.SetAttributes(attribute.String("gcp.instance_name", name))
-
-	e.logger.Info("runner VM started",
-		slog.String("name", name),
-		slog.String("zone", e.cfg.Zone),
+	e.logger.Info("adopted existing runner VM",
+		slog.String("name", key),
+		slog.String("status", inst.GetStatus()),
 	)
+	return key, nil
+}
 
-	// For GCP, the instance name is the opaque ID.
-	return name, nil
+// ListRunners implements engine.RunnerLister.  It lists the instances in
+// the engine's zones that carry every given label, whatever their
+// status; preempted spot VMs are marked Preempted.
+func (e *Engine) ListRunners(ctx context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.gcp.ListRunners")
+	defer span.End()
+
+	span.SetAttributes(attribute.StringSlice("gcp.zones", e.zones))
+
+	var runners []engine.ListedRunner
+	for _, zone := range e.zones {
+		instances, err := e.client.List(ctx, &computepb.ListInstancesRequest{
+			Project: e.cfg.Project,
+			Zone:    zone,
+			Filter:  proto.String(labelFilter(labels)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list instances in %s: %w", zone, err)
+		}
+
+		e.mu.Lock()
+		for _, inst := range instances {
+			e.zoneOf[inst.GetName()] = zone
+			runners = append(runners, engine.ListedRunner{
+				ID:        inst.GetName(),
+				Name:      inst.GetName(),
+				Labels:    inst.GetLabels(),
+				Preempted: e.preempted(inst),
+			})
+		}
+		e.mu.Unlock()
+	}
+	return runners, nil
 }
 
-// DestroyRunner permanently deletes the VM identified by id.
+// labelFilter builds a Compute Engine list filter matching every label,
+// e.g. `(labels.a = "x") AND (labels.b = "y")`.  Keys are sorted so the
+// filter is deterministic.
+func labelFilter(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	terms := make([]string, 0, len(keys))
+	for _, k := range keys {
+		terms = append(terms, fmt.Sprintf("(labels.%s = %q)", k, labels[k]))
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// DestroyRunner permanently deletes the VM identified by id, in the zone
+// it was created or found in, else in whichever zone has it.
 // It is idempotent -- deleting an already-deleted VM is not an error.
 func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
 	ctx, span := e.tracer.Start(ctx, "engine.gcp.DestroyRunner")
@@ -281,46 +1063,285 @@ func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
 	span.SetAttributes(
 		attribute.String("gcp.instance_name", id),
 		attribute.String("gcp.project", e.cfg.Project),
-		attribute.String("gcp.zone", e.cfg.Zone),
 	)
 
 	e.logger.Info("destroying runner VM", slog.String("name", id))
 
+	e.mu.Lock()
+	zone, known := e.zoneOf[id]
+	e.mu.Unlock()
+	zones := e.zones
+	if known {
+		zones = []string{zone}
+	}
+
+	for _, zone := range zones {
+		err := e.deleteInstance(ctx, id, zone)
+		// Treat "not found" as success -- the instance is already gone,
+		// or lives in another zone.
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		span.SetAttributes(attribute.String("gcp.zone", zone))
+		e.removeFromTracking(id)
+		e.logger.Info("runner VM destroyed", slog.String("name", id))
+		return nil
+	}
+
+	span.AddEvent("instance already deleted (idempotent)")
+	e.logger.Info("runner VM already deleted", slog.String("name", id))
+	e.removeFromTracking(id)
+	return nil
+}
+
+// deleteInstance deletes the VM id in zone and waits for it.
+func (e *Engine) deleteInstance(ctx context.Context, id, zone string) error {
 	op, err := e.client.Delete(ctx, &computepb.DeleteInstanceRequest{
 		Project:  e.cfg.Project,
-		Zone:     e.cfg.Zone,
+		Zone:     zone,
 		Instance: id,
 	})
 	if err != nil {
-		// Treat "not found" as success -- the instance is already gone.
 		// The GCP client returns a googleapi.Error with Code 404.
-		if isNotFound(err) {
-			span.AddEvent("instance already deleted (idempotent)")
-			e.logger.Info("runner VM already deleted", slog.String("name", id))
-			e.removeFromTracking(id)
-			return nil
-		}
 		return fmt.Errorf("delete instance %s: %w", id, err)
 	}
 
 	if err := op.Wait(ctx); err != nil {
-		// Also handle 404 during wait -- race between delete and check.
-		if isNotFound(err) {
-			span.AddEvent("instance already deleted during wait (idempotent)")
-			e.logger.Info("runner VM already deleted", slog.String("name", id))
-			e.removeFromTracking(id)
-			return nil
-		}
+		// A 404 during wait is a race between delete and check.
 		return fmt.Errorf("waiting for delete of %s: %w", id, err)
 	}
+	return nil
+}
+
+// Check implements engine.Checker.  It reads the zone's region to confirm
+// the project is reachable with the engine's credentials and reports
+// usage/limit for the quotas runner VMs consume.
+func (e *Engine) Check(ctx context.Context) (map[string]string, error) {
+	region := RegionFromZone(e.cfg.Zone)
+	diags := map[string]string{
+		"gcp.project": e.cfg.Project,
+		"gcp.zone":    e.cfg.Zone,
+		"gcp.region":  region,
+	}
+	if len(e.zones) > 1 {
+		diags["gcp.zones"] = strings.Join(e.zones, ", ")
+	}
+	if e.regions == nil {
+		return diags, nil
+	}
 
-	e.removeFromTracking(id)
-	e.logger.Info("runner VM destroyed", slog.String("name", id))
+	quotas, err := e.regionQuotas(ctx, region)
+	if err != nil {
+		return diags, err
+	}
+	for _, metric := range checkedQuotas {
+		q, ok := quotas[metric]
+		if !ok {
+			continue
+		}
+		diags["gcp.quota."+strings.ToLower(metric)] = fmt.Sprintf("%g/%g", q.GetUsage(), q.GetLimit())
+	}
+
+	return diags, nil
+}
+
+// regionQuotas returns the region's quotas keyed by metric name.
+func (e *Engine) regionQuotas(ctx context.Context, region string) (map[string]*computepb.Quota, error) {
+	r, err := e.regions.Get(ctx, &computepb.GetRegionRequest{
+		Project: e.cfg.Project,
+		Region:  region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gcp region %s unreachable: %w", region, err)
+	}
+
+	quotas := make(map[string]*computepb.Quota, len(r.GetQuotas()))
+	for _, q := range r.GetQuotas() {
+		quotas[q.GetMetric()] = q
+	}
+	return quotas, nil
+}
+
+// CheckQuotaHeadroom verifies that the region has enough free CPUS and
+// INSTANCES quota to run maxRunners VMs of the configured machine type
+// on top of current usage.  It returns a descriptive error listing every
+// shortfall.  Quotas the region does not report are skipped.
+func (e *Engine) CheckQuotaHeadroom(ctx context.Context, maxRunners int) error {
+	if e.regions == nil || e.machineTypes == nil {
+		return fmt.Errorf("gcp quota check: clients not initialized")
+	}
+
+	mt, err := e.machineTypes.Get(ctx, &computepb.GetMachineTypeRequest{
+		Project:     e.cfg.Project,
+		Zone:        e.cfg.Zone,
+		MachineType: e.cfg.MachineType,
+	})
+	if err != nil {
+		return fmt.Errorf("gcp machine type %s: %w", e.cfg.MachineType, err)
+	}
+
+	region := RegionFromZone(e.cfg.Zone)
+	quotas, err := e.regionQuotas(ctx, region)
+	if err != nil {
+		return err
+	}
+
+	needed := map[string]float64{
+		"CPUS":      float64(maxRunners) * float64(mt.GetGuestCpus()),
+		"INSTANCES": float64(maxRunners),
+	}
+
+	var errs []error
+	for _, metric := range []string{"CPUS", "INSTANCES"} {
+		q, ok := quotas[metric]
+		if !ok {
+			continue
+		}
+		headroom := q.GetLimit() - q.GetUsage()
+		if needed[metric] > headroom {
+			errs = append(errs, fmt.Errorf("%s: max_runners %d x %s needs %g, only %g of %g free in %s",
+				metric, maxRunners, e.cfg.MachineType, needed[metric], headroom, q.GetLimit(), region))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("insufficient gcp quota: %w", errors.Join(errs...))
+	}
+
+	e.logger.Info("gcp quota headroom ok",
+		slog.String("region", region),
+		slog.Int("max_runners", maxRunners),
+		slog.Float64("vcpus_needed", needed["CPUS"]),
+	)
+	return nil
+}
+
+// RunnerNameSuffix implements engine.NameSuffixer.  It returns the zone
+// when ZoneInRunnerName is set.
+func (e *Engine) RunnerNameSuffix() string {
+	if !e.cfg.ZoneInRunnerName {
+		return ""
+	}
+	return e.cfg.Zone
+}
+
+// networkURL returns the network reference for an instance.  A bare
+// network name is resolved in the engine's project
+// ("global/networks/<name>"); anything containing a "/" -- a full
+// self-link, "projects/<p>/global/networks/<name>" for a network in
+// another project (Shared VPC), or "global/networks/<name>" -- is passed
+// through unchanged.
+func networkURL(network string) string {
+	if strings.Contains(network, "/") {
+		return network
+	}
+	return "global/networks/" + network
+}
+
+// autoSelectSubnet creates the network clients, resolves the subnet with
+// selectSubnet and closes them again; they are only needed at startup.
+func (e *Engine) autoSelectSubnet(ctx context.Context) error {
+	opts, err := clientOptions(ctx, e.cfg.Egress)
+	if err != nil {
+		return err
+	}
+	networks, err := compute.NewNetworksRESTClient(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("gcp networks client: %w", err)
+	}
+	defer networks.Close()
+
+	subnets, err := compute.NewSubnetworksRESTClient(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("gcp subnetworks client: %w", err)
+	}
+	sc := &realSubnetworksClient{c: subnets}
+	defer sc.Close()
+
+	return e.selectSubnet(ctx, networks, sc)
+}
+
+// selectSubnet sets cfg.Subnet to the subnet of cfg.Network in the
+// zone's region when the network is custom-mode.  Auto-mode networks are
+// left alone: GCP picks their regional subnet itself.  For a network in
+// another project (Shared VPC) the subnets are listed in that project.
+func (e *Engine) selectSubnet(ctx context.Context, networks networksAPI, subnets subnetworksAPI) error {
+	project, name := networkProjectAndName(e.cfg.Project, e.cfg.Network)
+
+	network, err := networks.Get(ctx, &computepb.GetNetworkRequest{
+		Project: project,
+		Network: name,
+	})
+	if err != nil {
+		return fmt.Errorf("get network %s: %w", e.cfg.Network, err)
+	}
+	if network.GetAutoCreateSubnetworks() {
+		return nil
+	}
+
+	region := RegionFromZone(e.cfg.Zone)
+	list, err := subnets.List(ctx, &computepb.ListSubnetworksRequest{
+		Project: project,
+		Region:  region,
+	})
+	if err != nil {
+		return fmt.Errorf("list subnetworks in %s: %w", region, err)
+	}
+
+	var candidates []*computepb.Subnetwork
+	for _, sn := range list {
+		if sn.GetNetwork() == network.GetSelfLink() {
+			candidates = append(candidates, sn)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("custom-mode network %s has no subnet in region %s; set engine.gcp.subnet", e.cfg.Network, region)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].GetName() < candidates[j].GetName()
+	})
+	if len(candidates) > 1 {
+		e.logger.Warn("custom-mode network has several subnets in region, using the first by name",
+			slog.String("network", e.cfg.Network),
+			slog.String("region", region),
+			slog.String("subnet", candidates[0].GetName()),
+		)
+	}
 
+	e.cfg.Subnet = candidates[0].GetSelfLink()
+	e.logger.Info("auto-selected subnet",
+		slog.String("network", e.cfg.Network),
+		slog.String("subnet", e.cfg.Subnet),
+	)
 	return nil
 }
 
-// Shutdown deletes all VMs currently tracked by this engine instance.
+// networkProjectAndName extracts the project and network name from a
+// bare name or a (partial) self-link.  Bare names belong to project.
+func networkProjectAndName(project, network string) (string, string) {
+	parts := strings.Split(network, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "projects" {
+			project = parts[i+1]
+			break
+		}
+	}
+	return project, parts[len(parts)-1]
+}
+
+// RegionFromZone returns the region of a zone ("us-central1-a" ->
+// "us-central1").
+func RegionFromZone(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+// Shutdown deletes all VMs currently tracked by this engine instance and
+// returns every failure joined.
 func (e *Engine) Shutdown(ctx context.Context) error {
 	ctx, span := e.tracer.Start(ctx, "engine.gcp.Shutdown")
 	defer span.End()
@@ -334,7 +1355,7 @@ func (e *Engine) Shutdown(ctx context.Context) error {
 
 	span.SetAttributes(attribute.Int("gcp.instances_count", len(snapshot)))
 
-	var firstErr error
+	var errs []error
 	for name, id := range snapshot {
 		e.logger.Info("shutdown: deleting runner VM",
 			slog.String("name", name),
@@ -344,9 +1365,7 @@ func (e *Engine) Shutdown(ctx context.Context) error {
 				slog.String("name", name),
 				slog.String("error", err.Error()),
 			)
-			if firstErr == nil {
-				firstErr = err
-			}
+			errs = append(errs, fmt.Errorf("deleting %s: %w", name, err))
 		}
 	}
 
@@ -355,14 +1374,24 @@ func (e *Engine) Shutdown(ctx context.Context) error {
 	e.mu.Unlock()
 
 	// Close the API clients.
-	if err := e.client.Close(); err != nil && firstErr == nil {
-		firstErr = err
+	if err := e.client.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := e.opClient.Close(); err != nil {
+		errs = append(errs, err)
 	}
-	if err := e.opClient.Close(); err != nil && firstErr == nil {
-		firstErr = err
+	if e.regions != nil {
+		if err := e.regions.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if e.machineTypes != nil {
+		if err := e.machineTypes.Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return firstErr
+	return errors.Join(errs...)
 }
 
 // removeFromTracking removes an instance from the tracking map.
@@ -374,6 +1403,7 @@ func (e *Engine) removeFromTracking(id string) {
 			break
 		}
 	}
+	delete(e.zoneOf, id)
 	e.mu.Unlock()
 }
 
@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	computepb "cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/actions/scaleset"
 	gax "github.com/googleapis/gax-go/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/scaler"
 )
 
 // ---------------------------------------------------------------------------
@@ -27,6 +34,37 @@ func (m *mockOperation) Wait(_ context.Context, _ ...gax.CallOption) error {
 	return m.err
 }
 
+// overlapOperation is an operation whose Wait takes delay and records the
+// most Waits in flight at once.
+type overlapOperation struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (o *overlapOperation) Wait(_ context.Context, _ ...gax.CallOption) error {
+	o.mu.Lock()
+	o.inFlight++
+	o.maxInFlight = max(o.maxInFlight, o.inFlight)
+	o.mu.Unlock()
+
+	time.Sleep(o.delay)
+
+	o.mu.Lock()
+	o.inFlight--
+	o.mu.Unlock()
+	return nil
+}
+
+// jitGenerator is a scaler.JitConfigGenerator returning a fixed config.
+type jitGenerator struct{}
+
+func (jitGenerator) GenerateJitRunnerConfig(_ context.Context, setting *scaleset.RunnerScaleSetJitRunnerSetting, _ int) (*scaleset.RunnerScaleSetJitRunnerConfig, error) {
+	return &scaleset.RunnerScaleSetJitRunnerConfig{EncodedJITConfig: "jit-" + setting.Name}, nil
+}
+
 // ---------------------------------------------------------------------------
 // Mock instances client (satisfies instancesAPI)
 // ---------------------------------------------------------------------------
@@ -34,21 +72,85 @@ func (m *mockOperation) Wait(_ context.Context, _ ...gax.CallOption) error {
 type mockInstancesClient struct {
 	mu sync.Mutex
 
-	insertCalls []*computepb.InsertInstanceRequest
-	deleteCalls []*computepb.DeleteInstanceRequest
-	closed      bool
-
-	insertErr error // returned by Insert
-	insertOp  operationWaiter
-	deleteErr error // returned by Delete
-	deleteOp  operationWaiter
+	insertCalls      []*computepb.InsertInstanceRequest
+	bulkInsertCalls  []*computepb.BulkInsertInstanceRequest
+	setMetadataCalls []*computepb.SetMetadataInstanceRequest
+	deleteCalls      []*computepb.DeleteInstanceRequest
+	listCalls        []*computepb.ListInstancesRequest
+	closed           bool
+
+	insertErr         error            // returned by Insert
+	insertZoneErr     map[string]error // per-zone Insert errors
+	insertOp          operationWaiter
+	bulkInsertErr     error            // returned by BulkInsert
+	bulkInsertZoneErr map[string]error // per-zone BulkInsert errors
+	bulkInsertOp      operationWaiter
+	setMetadataErr    map[string]error // per-instance SetMetadata errors
+	deleteErr         error            // returned by Delete
+	deleteZoneErr     map[string]error // per-zone Delete errors
+	deleteOp          operationWaiter
+
+	// existing, when non-nil, makes Get return only these instances and
+	// a 404 for any other name.
+	existing map[string]*computepb.Instance
+
+	// listed is returned by List regardless of the filter.
+	listed []*computepb.Instance
 }
 
 func newMockInstancesClient() *mockInstancesClient {
 	return &mockInstancesClient{
-		insertOp: &mockOperation{},
-		deleteOp: &mockOperation{},
+		insertOp:     &mockOperation{},
+		bulkInsertOp: &mockOperation{},
+		deleteOp:     &mockOperation{},
+	}
+}
+
+func (m *mockInstancesClient) BulkInsert(_ context.Context, req *computepb.BulkInsertInstanceRequest) (operationWaiter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bulkInsertCalls = append(m.bulkInsertCalls, req)
+	if m.bulkInsertErr != nil {
+		return nil, m.bulkInsertErr
+	}
+	if err := m.bulkInsertZoneErr[req.GetZone()]; err != nil {
+		return nil, err
+	}
+	return m.bulkInsertOp, nil
+}
+
+func (m *mockInstancesClient) Get(_ context.Context, req *computepb.GetInstanceRequest) (*computepb.Instance, error) {
+	if m.existing != nil {
+		inst, ok := m.existing[req.GetInstance()]
+		if !ok {
+			return nil, fmt.Errorf("googleapi: Error 404: The resource was not found")
+		}
+		return inst, nil
+	}
+	return &computepb.Instance{
+		Name:     proto.String(req.GetInstance()),
+		Metadata: &computepb.Metadata{Fingerprint: proto.String("fp-" + req.GetInstance())},
+	}, nil
+}
+
+func (m *mockInstancesClient) List(_ context.Context, req *computepb.ListInstancesRequest) ([]*computepb.Instance, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.listCalls = append(m.listCalls, req)
+	return m.listed, nil
+}
+
+func (m *mockInstancesClient) SetMetadata(_ context.Context, req *computepb.SetMetadataInstanceRequest) (operationWaiter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setMetadataCalls = append(m.setMetadataCalls, req)
+	if err := m.setMetadataErr[req.GetInstance()]; err != nil {
+		return nil, err
 	}
+	return &mockOperation{}, nil
 }
 
 func (m *mockInstancesClient) Insert(_ context.Context, req *computepb.InsertInstanceRequest) (operationWaiter, error) {
@@ -59,6 +161,9 @@ func (m *mockInstancesClient) Insert(_ context.Context, req *computepb.InsertIns
 	if m.insertErr != nil {
 		return nil, m.insertErr
 	}
+	if err := m.insertZoneErr[req.GetZone()]; err != nil {
+		return nil, err
+	}
 	return m.insertOp, nil
 }
 
@@ -70,6 +175,9 @@ func (m *mockInstancesClient) Delete(_ context.Context, req *computepb.DeleteIns
 	if m.deleteErr != nil {
 		return nil, m.deleteErr
 	}
+	if err := m.deleteZoneErr[req.GetZone()]; err != nil {
+		return nil, err
+	}
 	return m.deleteOp, nil
 }
 
@@ -93,6 +201,92 @@ func (m *mockCloser) Close() error {
 	return nil
 }
 
+// ---------------------------------------------------------------------------
+// Mock regions client (satisfies regionsAPI)
+// ---------------------------------------------------------------------------
+
+type mockRegionsClient struct {
+	region *computepb.Region
+	err    error
+	req    *computepb.GetRegionRequest
+	closed bool
+}
+
+func (m *mockRegionsClient) Get(_ context.Context, req *computepb.GetRegionRequest, _ ...gax.CallOption) (*computepb.Region, error) {
+	m.req = req
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.region, nil
+}
+
+func (m *mockRegionsClient) Close() error {
+	m.closed = true
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Mock machine types client (satisfies machineTypesAPI)
+// ---------------------------------------------------------------------------
+
+type mockMachineTypesClient struct {
+	guestCpus int32
+	req       *computepb.GetMachineTypeRequest
+}
+
+func (m *mockMachineTypesClient) Get(_ context.Context, req *computepb.GetMachineTypeRequest, _ ...gax.CallOption) (*computepb.MachineType, error) {
+	m.req = req
+	return &computepb.MachineType{GuestCpus: proto.Int32(m.guestCpus)}, nil
+}
+
+func (m *mockMachineTypesClient) Close() error { return nil }
+
+// ---------------------------------------------------------------------------
+// Mock network clients (satisfy networksAPI / subnetworksAPI)
+// ---------------------------------------------------------------------------
+
+type mockNetworksClient struct {
+	network *computepb.Network
+	req     *computepb.GetNetworkRequest
+}
+
+func (m *mockNetworksClient) Get(_ context.Context, req *computepb.GetNetworkRequest, _ ...gax.CallOption) (*computepb.Network, error) {
+	m.req = req
+	return m.network, nil
+}
+
+func (m *mockNetworksClient) Close() error { return nil }
+
+type mockSubnetworksClient struct {
+	subnets []*computepb.Subnetwork
+	req     *computepb.ListSubnetworksRequest
+}
+
+func (m *mockSubnetworksClient) List(_ context.Context, req *computepb.ListSubnetworksRequest) ([]*computepb.Subnetwork, error) {
+	m.req = req
+	return m.subnets, nil
+}
+
+func (m *mockSubnetworksClient) Close() error { return nil }
+
+const testNetworkSelfLink = "https://www.googleapis.com/compute/v1/projects/test-project/global/networks/ci-vpc"
+
+func customNetwork() *computepb.Network {
+	return &computepb.Network{
+		Name:                  proto.String("ci-vpc"),
+		SelfLink:              proto.String(testNetworkSelfLink),
+		AutoCreateSubnetworks: proto.Bool(false),
+	}
+}
+
+func subnet(name, network string) *computepb.Subnetwork {
+	return &computepb.Subnetwork{
+		Name:     proto.String(name),
+		Network:  proto.String(network),
+		SelfLink: proto.String("https://www.googleapis.com/compute/v1/projects/test-project/regions/us-central1/subnetworks/" + name),
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Test suite
 // ---------------------------------------------------------------------------
@@ -117,6 +311,7 @@ func (s *GCPEngineSuite) SetupTest() {
 		MachineType: "e2-medium",
 		Image:       "projects/test-project/global/images/runner-image",
 		DiskSizeGB:  50,
+		DiskType:    "pd-ssd",
 		Network:     "default",
 		PublicIP:    true,
 	}
@@ -184,6 +379,59 @@ func (s *GCPEngineSuite) TestStartRunner_DiskConfig() {
 	assert.Contains(s.T(), disk.GetInitializeParams().GetDiskType(), "pd-ssd")
 }
 
+func (s *GCPEngineSuite) TestStartRunner_ProvisionedDiskPerformance() {
+	s.cfg.DiskType = "hyperdisk-balanced"
+	s.cfg.DiskIOPS = 10000
+	s.cfg.DiskThroughput = 400
+	e := s.newEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-hd", "jit")
+	require.NoError(s.T(), err)
+
+	params := s.client.insertCalls[0].GetInstanceResource().GetDisks()[0].GetInitializeParams()
+	assert.Equal(s.T(), "zones/us-central1-a/diskTypes/hyperdisk-balanced", params.GetDiskType())
+	assert.Equal(s.T(), int64(10000), params.GetProvisionedIops())
+	assert.Equal(s.T(), int64(400), params.GetProvisionedThroughput())
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertProvisionedDiskPerformance() {
+	s.cfg.DiskType = "pd-extreme"
+	s.cfg.DiskIOPS = 20000
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	_, err := s.newEngine().StartRunners(s.ctx, runnerSpecs(2))
+	require.NoError(s.T(), err)
+
+	props := s.client.bulkInsertCalls[0].GetBulkInsertInstanceResourceResource().GetInstanceProperties()
+	params := props.GetDisks()[0].GetInitializeParams()
+	assert.Equal(s.T(), "pd-extreme", params.GetDiskType())
+	assert.Equal(s.T(), int64(20000), params.GetProvisionedIops())
+	assert.Nil(s.T(), params.ProvisionedThroughput)
+}
+
+func (s *GCPEngineSuite) TestSupportsProvisionedDiskPerformance() {
+	assert.True(s.T(), SupportsProvisionedIOPS("pd-extreme"))
+	assert.True(s.T(), SupportsProvisionedIOPS("hyperdisk-balanced"))
+	assert.False(s.T(), SupportsProvisionedIOPS("pd-ssd"))
+	assert.False(s.T(), SupportsProvisionedIOPS("hyperdisk-throughput"))
+
+	assert.True(s.T(), SupportsProvisionedThroughput("hyperdisk-throughput"))
+	assert.True(s.T(), SupportsProvisionedThroughput("hyperdisk-balanced"))
+	assert.False(s.T(), SupportsProvisionedThroughput("pd-extreme"))
+	assert.False(s.T(), SupportsProvisionedThroughput("pd-balanced"))
+}
+
+func (s *GCPEngineSuite) TestStartRunner_NoProvisionedDiskPerformanceByDefault() {
+	e := s.newEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-ssd", "jit")
+	require.NoError(s.T(), err)
+
+	params := s.client.insertCalls[0].GetInstanceResource().GetDisks()[0].GetInitializeParams()
+	assert.Nil(s.T(), params.ProvisionedIops)
+	assert.Nil(s.T(), params.ProvisionedThroughput)
+}
+
 func (s *GCPEngineSuite) TestStartRunner_PublicIP() {
 	s.cfg.PublicIP = true
 	e := s.newEngine()
@@ -269,6 +517,451 @@ func (s *GCPEngineSuite) TestStartRunner_OperationWaitError() {
 	assert.Contains(s.T(), err.Error(), "operation timed out")
 }
 
+// ---------------------------------------------------------------------------
+// Multi-zone tests
+// ---------------------------------------------------------------------------
+
+// errZoneExhausted is the error GCP returns for a zonal stockout.
+var errZoneExhausted = fmt.Errorf("googleapi: Error 503: ZONE_RESOURCE_POOL_EXHAUSTED: The zone does not have enough resources available to fulfill the request")
+
+func (s *GCPEngineSuite) multiZoneEngine() *Engine {
+	s.cfg.Zones = []string{"us-central1-a", "us-central1-b", "us-central1-c"}
+	return s.newEngine()
+}
+
+func (s *GCPEngineSuite) insertZones() []string {
+	var zones []string
+	for _, req := range s.client.insertCalls {
+		zones = append(zones, req.GetZone())
+	}
+	return zones
+}
+
+func (s *GCPEngineSuite) TestStartRunner_SpreadsAcrossZones() {
+	e := s.multiZoneEngine()
+
+	for i := range 4 {
+		_, err := e.StartRunner(s.ctx, fmt.Sprintf("runner-%d", i), "jit")
+		require.NoError(s.T(), err)
+	}
+	assert.Equal(s.T(), []string{"us-central1-a", "us-central1-b", "us-central1-c", "us-central1-a"}, s.insertZones())
+
+	req := s.client.insertCalls[1].GetInstanceResource()
+	assert.Equal(s.T(), "zones/us-central1-b/machineTypes/e2-medium", req.GetMachineType())
+	assert.Equal(s.T(), "zones/us-central1-b/diskTypes/pd-ssd", req.GetDisks()[0].GetInitializeParams().GetDiskType())
+}
+
+func (s *GCPEngineSuite) TestStartRunner_FallsBackOnZoneExhausted() {
+	s.client.insertZoneErr = map[string]error{"us-central1-a": errZoneExhausted}
+	e := s.multiZoneEngine()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+
+	_, err := e.StartRunner(s.ctx, "runner-0", "jit")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"us-central1-a", "us-central1-b"}, s.insertZones())
+
+	// The exhausted zone is tried last while it cools down.
+	delete(s.client.insertZoneErr, "us-central1-a")
+	s.client.insertCalls = nil
+	for i := 1; i <= 3; i++ {
+		_, err := e.StartRunner(s.ctx, fmt.Sprintf("runner-%d", i), "jit")
+		require.NoError(s.T(), err)
+	}
+	assert.Equal(s.T(), []string{"us-central1-b", "us-central1-c", "us-central1-b"}, s.insertZones())
+
+	now = now.Add(zoneCooldown)
+	s.client.insertCalls = nil
+	for i := 4; i <= 6; i++ {
+		_, err := e.StartRunner(s.ctx, fmt.Sprintf("runner-%d", i), "jit")
+		require.NoError(s.T(), err)
+	}
+	assert.Equal(s.T(), []string{"us-central1-b", "us-central1-c", "us-central1-a"}, s.insertZones())
+}
+
+func (s *GCPEngineSuite) TestStartRunner_AllZonesExhausted() {
+	s.client.insertErr = errZoneExhausted
+	e := s.multiZoneEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-0", "jit")
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "ZONE_RESOURCE_POOL_EXHAUSTED")
+	assert.Len(s.T(), s.client.insertCalls, 3)
+}
+
+func (s *GCPEngineSuite) TestStartRunner_OtherErrorsDoNotFallBack() {
+	s.client.insertErr = fmt.Errorf("quota exceeded")
+	e := s.multiZoneEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-0", "jit")
+	require.Error(s.T(), err)
+	assert.Len(s.T(), s.client.insertCalls, 1)
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertFallsBackOnZoneExhausted() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	s.client.bulkInsertZoneErr = map[string]error{"us-central1-a": errZoneExhausted}
+	e := s.multiZoneEngine()
+
+	started, err := e.StartRunners(s.ctx, runnerSpecs(2))
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), started, 2)
+	require.Len(s.T(), s.client.bulkInsertCalls, 2)
+	assert.Equal(s.T(), "us-central1-b", s.client.bulkInsertCalls[1].GetZone())
+	for _, req := range s.client.setMetadataCalls {
+		assert.Equal(s.T(), "us-central1-b", req.GetZone())
+	}
+}
+
+func (s *GCPEngineSuite) TestDestroyRunner_DeletesInRunnerZone() {
+	e := s.multiZoneEngine()
+	_, err := e.StartRunner(s.ctx, "runner-0", "jit")
+	require.NoError(s.T(), err)
+	_, err = e.StartRunner(s.ctx, "runner-1", "jit")
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, "runner-1"))
+	require.Len(s.T(), s.client.deleteCalls, 1)
+	assert.Equal(s.T(), "us-central1-b", s.client.deleteCalls[0].GetZone())
+}
+
+func (s *GCPEngineSuite) TestDestroyRunner_UnknownZoneTriesEachZone() {
+	s.client.deleteZoneErr = map[string]error{
+		"us-central1-a": fmt.Errorf("googleapi: Error 404: The resource was not found"),
+	}
+	e := s.multiZoneEngine()
+
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, "runner-adopted"))
+	require.Len(s.T(), s.client.deleteCalls, 2)
+	assert.Equal(s.T(), "us-central1-b", s.client.deleteCalls[1].GetZone())
+}
+
+func (s *GCPEngineSuite) TestFindRunner_SearchesZones() {
+	s.client.existing = map[string]*computepb.Instance{
+		"runner-1": {Name: proto.String("runner-1"), Status: proto.String("RUNNING")},
+	}
+	e := s.multiZoneEngine()
+
+	id, err := e.FindRunner(s.ctx, "runner-1")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "runner-1", id)
+	assert.Equal(s.T(), "us-central1-a", e.zoneOf["runner-1"])
+}
+
+func (s *GCPEngineSuite) TestListRunners_ListsEachZone() {
+	s.client.listed = []*computepb.Instance{{Name: proto.String("runner-1")}}
+	e := s.multiZoneEngine()
+
+	runners, err := e.ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), runners, 3)
+	require.Len(s.T(), s.client.listCalls, 3)
+	assert.Equal(s.T(), "us-central1-c", s.client.listCalls[2].GetZone())
+}
+
+// ---------------------------------------------------------------------------
+// StartRunners (bulk insert) tests
+// ---------------------------------------------------------------------------
+
+func runnerSpecs(n int) []engine.RunnerSpec {
+	specs := make([]engine.RunnerSpec, n)
+	for i := range n {
+		specs[i] = engine.RunnerSpec{
+			Name:      fmt.Sprintf("runner-bulk-%d", i),
+			JITConfig: fmt.Sprintf("jit-%d", i),
+		}
+	}
+	return specs
+}
+
+func (s *GCPEngineSuite) TestUseBulkInsert_Decision() {
+	tests := []struct {
+		name      string
+		enabled   bool
+		threshold int
+		n         int
+		expect    bool
+	}{
+		{"disabled", false, 5, 10, false},
+		{"below threshold", true, 5, 4, false},
+		{"at threshold", true, 5, 5, true},
+		{"above threshold", true, 5, 20, true},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.cfg.UseBulkInsert = tc.enabled
+			s.cfg.BulkInsertThreshold = tc.threshold
+			assert.Equal(s.T(), tc.expect, s.newEngine().useBulkInsert(tc.n))
+		})
+	}
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BelowThresholdUsesInsert() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 5
+	e := s.newEngine()
+
+	started, err := e.StartRunners(s.ctx, runnerSpecs(3))
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), started, 3)
+	assert.Len(s.T(), s.client.insertCalls, 3)
+	assert.Empty(s.T(), s.client.bulkInsertCalls)
+}
+
+func (s *GCPEngineSuite) TestUseBatch_OnlyWithBulkInsert() {
+	e := s.newEngine()
+	assert.False(s.T(), e.UseBatch(10))
+
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 5
+	e = s.newEngine()
+	assert.False(s.T(), e.UseBatch(4))
+	assert.True(s.T(), e.UseBatch(5))
+}
+
+func (s *GCPEngineSuite) TestScaleUp_WithoutBulkInsertStartsInParallel() {
+	op := &overlapOperation{delay: 20 * time.Millisecond}
+	s.client.insertOp = op
+	sc := scaler.New(scaler.Config{
+		ScaleSetID:       1,
+		MaxRunners:       10,
+		ScalesetClient:   jitGenerator{},
+		Engine:           s.newEngine(),
+		Logger:           s.logger,
+		StartParallelism: 3,
+	})
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 6)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 6, count)
+	assert.Len(s.T(), s.client.insertCalls, 6)
+	assert.Empty(s.T(), s.client.bulkInsertCalls)
+	assert.Greater(s.T(), op.maxInFlight, 1, "starts overlap")
+	assert.LessOrEqual(s.T(), op.maxInFlight, 3)
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsert() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 5
+	e := s.newEngine()
+
+	specs := runnerSpecs(6)
+	started, err := e.StartRunners(s.ctx, specs)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), started, 6)
+	assert.Empty(s.T(), s.client.insertCalls)
+
+	// One bulkInsert naming every runner explicitly.
+	require.Len(s.T(), s.client.bulkInsertCalls, 1)
+	res := s.client.bulkInsertCalls[0].GetBulkInsertInstanceResourceResource()
+	assert.Equal(s.T(), int64(6), res.GetCount())
+	assert.Equal(s.T(), "e2-medium", res.GetInstanceProperties().GetMachineType())
+	assert.Empty(s.T(), res.GetInstanceProperties().GetMetadata().GetItems(),
+		"shared properties must not carry a JIT config")
+	for _, spec := range specs {
+		assert.Contains(s.T(), res.GetPerInstanceProperties(), spec.Name)
+	}
+
+	// Each runner receives its own JIT config via SetMetadata.
+	require.Len(s.T(), s.client.setMetadataCalls, 6)
+	jitByInstance := make(map[string]string)
+	for _, req := range s.client.setMetadataCalls {
+		assert.Equal(s.T(), "fp-"+req.GetInstance(), req.GetMetadataResource().GetFingerprint())
+		for _, item := range req.GetMetadataResource().GetItems() {
+			if item.GetKey() == "ACTIONS_RUNNER_INPUT_JITCONFIG" {
+				jitByInstance[req.GetInstance()] = item.GetValue()
+			}
+		}
+	}
+	for _, spec := range specs {
+		assert.Equal(s.T(), spec.JITConfig, jitByInstance[spec.Name])
+	}
+
+	e.mu.Lock()
+	assert.Len(s.T(), e.instances, 6)
+	e.mu.Unlock()
+}
+
+// metadataItems flattens instance metadata into a key -> value map.
+func metadataItems(md *computepb.Metadata) map[string]string {
+	items := make(map[string]string)
+	for _, item := range md.GetItems() {
+		items[item.GetKey()] = item.GetValue()
+	}
+	return items
+}
+
+func (s *GCPEngineSuite) TestStartRunner_ExtraMetadata() {
+	s.cfg.Metadata = map[string]string{
+		"user-data":      "#cloud-config\npackages: [jq]\n",
+		"startup-script": "#!/bin/bash\necho hi\n",
+	}
+	e := s.newEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-abc123", "base64-jit-config")
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), s.client.insertCalls, 1)
+	assert.Equal(s.T(), map[string]string{
+		"ACTIONS_RUNNER_INPUT_JITCONFIG": "base64-jit-config",
+		"startup-script":                 "#!/bin/bash\necho hi\n",
+		"user-data":                      "#cloud-config\npackages: [jq]\n",
+	}, metadataItems(s.client.insertCalls[0].GetInstanceResource().GetMetadata()))
+}
+
+func (s *GCPEngineSuite) TestStartRunner_StartupScript() {
+	s.cfg.StartupScript = "#!/bin/bash\napt-get install -y jq\n"
+	e := s.newEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-abc123", "base64-jit-config")
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), map[string]string{
+		"ACTIONS_RUNNER_INPUT_JITCONFIG": "base64-jit-config",
+		StartupScriptKey:                 "#!/bin/bash\napt-get install -y jq\n",
+	}, metadataItems(s.client.insertCalls[0].GetInstanceResource().GetMetadata()))
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertStartupScript() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	s.cfg.StartupScript = "#!/bin/bash\n"
+	e := s.newEngine()
+
+	_, err := e.StartRunners(s.ctx, runnerSpecs(2))
+	require.NoError(s.T(), err)
+
+	props := s.client.bulkInsertCalls[0].GetBulkInsertInstanceResourceResource().GetInstanceProperties()
+	assert.Equal(s.T(), map[string]string{StartupScriptKey: "#!/bin/bash\n"}, metadataItems(props.GetMetadata()))
+	for _, req := range s.client.setMetadataCalls {
+		assert.Equal(s.T(), "#!/bin/bash\n", metadataItems(req.GetMetadataResource())[StartupScriptKey])
+	}
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertExtraMetadata() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	s.cfg.Metadata = map[string]string{"user-data": "#cloud-config\n"}
+	e := s.newEngine()
+
+	specs := runnerSpecs(3)
+	_, err := e.StartRunners(s.ctx, specs)
+	require.NoError(s.T(), err)
+
+	// The shared properties carry the extra metadata for boot...
+	require.Len(s.T(), s.client.bulkInsertCalls, 1)
+	props := s.client.bulkInsertCalls[0].GetBulkInsertInstanceResourceResource().GetInstanceProperties()
+	assert.Equal(s.T(), map[string]string{"user-data": "#cloud-config\n"}, metadataItems(props.GetMetadata()))
+
+	// ...and SetMetadata, which replaces every item, keeps it next to
+	// each runner's JIT config.
+	require.Len(s.T(), s.client.setMetadataCalls, 3)
+	for _, req := range s.client.setMetadataCalls {
+		items := metadataItems(req.GetMetadataResource())
+		assert.Equal(s.T(), "#cloud-config\n", items["user-data"])
+		assert.NotEmpty(s.T(), items["ACTIONS_RUNNER_INPUT_JITCONFIG"])
+	}
+}
+
+func (s *GCPEngineSuite) TestStartRunner_Accelerators() {
+	s.cfg.Accelerators = []Accelerator{{Type: "nvidia-tesla-t4", Count: 2}}
+	e := s.newEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-abc123", "base64-jit-config")
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), s.client.insertCalls, 1)
+	inst := s.client.insertCalls[0].GetInstanceResource()
+	require.Len(s.T(), inst.GetGuestAccelerators(), 1)
+	assert.Equal(s.T(), "zones/us-central1-a/acceleratorTypes/nvidia-tesla-t4", inst.GetGuestAccelerators()[0].GetAcceleratorType())
+	assert.Equal(s.T(), int32(2), inst.GetGuestAccelerators()[0].GetAcceleratorCount())
+	assert.Equal(s.T(), "TERMINATE", inst.GetScheduling().GetOnHostMaintenance())
+	require.NotNil(s.T(), inst.GetScheduling().AutomaticRestart)
+	assert.False(s.T(), inst.GetScheduling().GetAutomaticRestart())
+}
+
+func (s *GCPEngineSuite) TestStartRunner_NoAcceleratorsByDefault() {
+	e := s.newEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-abc123", "base64-jit-config")
+	require.NoError(s.T(), err)
+
+	inst := s.client.insertCalls[0].GetInstanceResource()
+	assert.Empty(s.T(), inst.GetGuestAccelerators())
+	assert.Nil(s.T(), inst.GetScheduling(), "default scheduling keeps live migration")
+}
+
+func (s *GCPEngineSuite) TestStartRunner_Spot() {
+	s.cfg.Spot = true
+	_, err := s.newEngine().StartRunner(s.ctx, "runner-abc123", "base64-jit-config")
+	require.NoError(s.T(), err)
+
+	sched := s.client.insertCalls[0].GetInstanceResource().GetScheduling()
+	assert.Equal(s.T(), "SPOT", sched.GetProvisioningModel())
+	assert.Equal(s.T(), "STOP", sched.GetInstanceTerminationAction())
+	assert.False(s.T(), sched.GetAutomaticRestart())
+	assert.Equal(s.T(), "TERMINATE", sched.GetOnHostMaintenance())
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertSpot() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	s.cfg.Spot = true
+	_, err := s.newEngine().StartRunners(s.ctx, runnerSpecs(2))
+	require.NoError(s.T(), err)
+
+	props := s.client.bulkInsertCalls[0].GetBulkInsertInstanceResourceResource().GetInstanceProperties()
+	assert.Equal(s.T(), "SPOT", props.GetScheduling().GetProvisioningModel())
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertAccelerators() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	s.cfg.Accelerators = []Accelerator{{Type: "nvidia-l4", Count: 1}}
+	e := s.newEngine()
+
+	_, err := e.StartRunners(s.ctx, runnerSpecs(2))
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), s.client.bulkInsertCalls, 1)
+	props := s.client.bulkInsertCalls[0].GetBulkInsertInstanceResourceResource().GetInstanceProperties()
+	require.Len(s.T(), props.GetGuestAccelerators(), 1)
+	assert.Equal(s.T(), "nvidia-l4", props.GetGuestAccelerators()[0].GetAcceleratorType())
+	assert.Equal(s.T(), "TERMINATE", props.GetScheduling().GetOnHostMaintenance())
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertError() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	s.client.bulkInsertErr = fmt.Errorf("quota exceeded")
+	e := s.newEngine()
+
+	started, err := e.StartRunners(s.ctx, runnerSpecs(3))
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "quota exceeded")
+	assert.Empty(s.T(), started)
+	assert.Empty(s.T(), s.client.setMetadataCalls)
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkMetadataFailureDeletesRunner() {
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	s.client.setMetadataErr = map[string]error{"runner-bulk-1": fmt.Errorf("fingerprint mismatch")}
+	e := s.newEngine()
+
+	started, err := e.StartRunners(s.ctx, runnerSpecs(3))
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "fingerprint mismatch")
+	assert.Len(s.T(), started, 2)
+	assert.NotContains(s.T(), started, "runner-bulk-1")
+
+	// The VM without a JIT config must not be left running.
+	require.Len(s.T(), s.client.deleteCalls, 1)
+	assert.Equal(s.T(), "runner-bulk-1", s.client.deleteCalls[0].GetInstance())
+}
+
 // ---------------------------------------------------------------------------
 // DestroyRunner tests
 // ---------------------------------------------------------------------------
@@ -479,3 +1172,347 @@ func (s *GCPEngineSuite) TestNewEngine_DefaultConfig() {
 	assert.NotNil(s.T(), e)
 	assert.Equal(s.T(), "p", e.cfg.Project)
 }
+
+// ---------------------------------------------------------------------------
+// Check tests
+// ---------------------------------------------------------------------------
+
+func (s *GCPEngineSuite) TestCheck_ReportsQuotas() {
+	regions := &mockRegionsClient{region: &computepb.Region{
+		Quotas: []*computepb.Quota{
+			{Metric: proto.String("CPUS"), Usage: proto.Float64(12), Limit: proto.Float64(24)},
+			{Metric: proto.String("INSTANCES"), Usage: proto.Float64(6), Limit: proto.Float64(100)},
+			{Metric: proto.String("NVIDIA_T4_GPUS"), Usage: proto.Float64(0), Limit: proto.Float64(1)},
+		},
+	}}
+	e := s.newEngine()
+	e.regions = regions
+
+	diags, err := e.Check(s.ctx)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), "test-project", regions.req.GetProject())
+	assert.Equal(s.T(), "us-central1", regions.req.GetRegion())
+	assert.Equal(s.T(), map[string]string{
+		"gcp.project":         "test-project",
+		"gcp.zone":            "us-central1-a",
+		"gcp.region":          "us-central1",
+		"gcp.quota.cpus":      "12/24",
+		"gcp.quota.instances": "6/100",
+	}, diags)
+}
+
+func (s *GCPEngineSuite) TestCheck_RegionError() {
+	e := s.newEngine()
+	e.regions = &mockRegionsClient{err: fmt.Errorf("permission denied")}
+
+	diags, err := e.Check(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "us-central1")
+	assert.Equal(s.T(), "test-project", diags["gcp.project"])
+}
+
+func (s *GCPEngineSuite) TestCheck_WithoutRegionsClient() {
+	diags, err := s.newEngine().Check(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "us-central1", diags["gcp.region"])
+}
+
+func (s *GCPEngineSuite) TestShutdown_ClosesRegionsClient() {
+	regions := &mockRegionsClient{}
+	e := s.newEngine()
+	e.regions = regions
+
+	require.NoError(s.T(), e.Shutdown(s.ctx))
+	assert.True(s.T(), regions.closed)
+}
+
+func (s *GCPEngineSuite) TestRunnerNameSuffix() {
+	assert.Empty(s.T(), s.newEngine().RunnerNameSuffix())
+
+	s.cfg.ZoneInRunnerName = true
+	assert.Equal(s.T(), "us-central1-a", s.newEngine().RunnerNameSuffix())
+}
+
+func (s *GCPEngineSuite) TestFindRunner_AdoptsRunningInstance() {
+	s.client.existing = map[string]*computepb.Instance{
+		"runner-1": {Name: proto.String("runner-1"), Status: proto.String("RUNNING")},
+	}
+	e := s.newEngine()
+
+	id, err := e.FindRunner(s.ctx, "runner-1")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "runner-1", id)
+
+	e.mu.Lock()
+	assert.Equal(s.T(), "runner-1", e.instances["runner-1"])
+	e.mu.Unlock()
+	assert.Empty(s.T(), s.client.deleteCalls)
+}
+
+func (s *GCPEngineSuite) TestFindRunner_NotFound() {
+	s.client.existing = map[string]*computepb.Instance{}
+
+	id, err := s.newEngine().FindRunner(s.ctx, "runner-missing")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), id)
+}
+
+func (s *GCPEngineSuite) TestFindRunner_DeletesStoppedInstance() {
+	s.client.existing = map[string]*computepb.Instance{
+		"runner-1": {Name: proto.String("runner-1"), Status: proto.String("TERMINATED")},
+	}
+
+	id, err := s.newEngine().FindRunner(s.ctx, "runner-1")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), id)
+	require.Len(s.T(), s.client.deleteCalls, 1)
+	assert.Equal(s.T(), "runner-1", s.client.deleteCalls[0].GetInstance())
+}
+
+func (s *GCPEngineSuite) TestStartRunner_SetsManagedLabels() {
+	s.cfg.RunID = "run-1"
+	_, err := s.newEngine().StartRunner(s.ctx, "runner-1", "jit")
+	require.NoError(s.T(), err)
+
+	labels := s.client.insertCalls[0].GetInstanceResource().GetLabels()
+	assert.Equal(s.T(), "true", labels[engine.ManagedLabel])
+	assert.Equal(s.T(), "run-1", labels[engine.RunIDLabel])
+}
+
+func (s *GCPEngineSuite) TestStartRunner_SetsScaleSetLabels() {
+	s.cfg.RunID = "run-1"
+	s.cfg.ScaleSetName = "Linux GPU.Runners"
+	e := s.newEngine()
+	e.SetScaleSetID(42)
+	_, err := e.StartRunner(s.ctx, "runner-1", "jit")
+	require.NoError(s.T(), err)
+
+	want := map[string]string{
+		engine.ManagedLabel:      "true",
+		engine.RunIDLabel:        "run-1",
+		engine.ManagedByLabel:    "terrpan-scaleset",
+		engine.ScaleSetNameLabel: "linux-gpu-runners",
+		engine.ScaleSetIDLabel:   "42",
+	}
+	instance := s.client.insertCalls[0].GetInstanceResource()
+	assert.Equal(s.T(), want, instance.GetLabels())
+	assert.Equal(s.T(), want, instance.GetDisks()[0].GetInitializeParams().GetLabels(), "the boot disk is labelled too")
+}
+
+func (s *GCPEngineSuite) TestStartRunners_BulkInsertSetsManagedLabels() {
+	s.cfg.RunID = "run-1"
+	s.cfg.UseBulkInsert = true
+	s.cfg.BulkInsertThreshold = 2
+	_, err := s.newEngine().StartRunners(s.ctx, runnerSpecs(2))
+	require.NoError(s.T(), err)
+
+	props := s.client.bulkInsertCalls[0].GetBulkInsertInstanceResourceResource().GetInstanceProperties()
+	assert.Equal(s.T(), "run-1", props.GetLabels()[engine.RunIDLabel])
+	assert.Equal(s.T(), "terrpan-scaleset", props.GetLabels()[engine.ManagedByLabel])
+}
+
+func (s *GCPEngineSuite) TestListRunners_FiltersByLabels() {
+	s.client.listed = []*computepb.Instance{
+		{Name: proto.String("runner-1"), Labels: engine.RunnerLabels("run-1")},
+	}
+
+	runners, err := s.newEngine().ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), runners, 1)
+	assert.Equal(s.T(), "runner-1", runners[0].ID)
+	assert.Equal(s.T(), "run-1", runners[0].Labels[engine.RunIDLabel])
+
+	require.Len(s.T(), s.client.listCalls, 1)
+	assert.Equal(s.T(), "us-central1-a", s.client.listCalls[0].GetZone())
+	assert.Equal(s.T(), `(labels.scaleset-managed = "true") AND (labels.scaleset-run-id = "run-1")`,
+		s.client.listCalls[0].GetFilter())
+}
+
+func (s *GCPEngineSuite) TestListRunners_MarksPreemptedSpotVMs() {
+	s.client.listed = []*computepb.Instance{
+		{Name: proto.String("runner-1"), Status: proto.String("RUNNING")},
+		{Name: proto.String("runner-2"), Status: proto.String("STOPPING")},
+		{Name: proto.String("runner-3"), Status: proto.String("TERMINATED")},
+	}
+
+	runners, err := s.newEngine().ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	for _, r := range runners {
+		assert.False(s.T(), r.Preempted, "%s: only spot and GPU VMs are preempted", r.Name)
+	}
+
+	s.cfg.Spot = true
+	runners, err = s.newEngine().ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), runners, 3)
+	assert.False(s.T(), runners[0].Preempted)
+	assert.True(s.T(), runners[1].Preempted)
+	assert.True(s.T(), runners[2].Preempted)
+}
+
+func (s *GCPEngineSuite) TestListRunners_MarksMaintenanceTerminatedGPUVMs() {
+	s.cfg.Accelerators = []Accelerator{{Type: "nvidia-tesla-t4", Count: 1}}
+	s.client.listed = []*computepb.Instance{
+		{Name: proto.String("runner-1"), Status: proto.String("RUNNING")},
+		{Name: proto.String("runner-2"), Status: proto.String("TERMINATED")},
+	}
+
+	runners, err := s.newEngine().ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), runners, 2)
+	assert.False(s.T(), runners[0].Preempted)
+	assert.True(s.T(), runners[1].Preempted)
+}
+
+func (s *GCPEngineSuite) TestSelectSubnet_CustomModePicksRegionalSubnet() {
+	s.cfg.Network = "ci-vpc"
+	e := s.newEngine()
+	networks := &mockNetworksClient{network: customNetwork()}
+	subnets := &mockSubnetworksClient{subnets: []*computepb.Subnetwork{
+		subnet("other-vpc-subnet", "https://www.googleapis.com/compute/v1/projects/test-project/global/networks/other"),
+		subnet("ci-us-central1", testNetworkSelfLink),
+	}}
+
+	require.NoError(s.T(), e.selectSubnet(s.ctx, networks, subnets))
+	assert.Equal(s.T(), "ci-vpc", networks.req.GetNetwork())
+	assert.Equal(s.T(), "us-central1", subnets.req.GetRegion())
+	assert.True(s.T(), strings.HasSuffix(e.cfg.Subnet, "/subnetworks/ci-us-central1"))
+
+	_, err := e.StartRunner(s.ctx, "runner-subnet", "jit")
+	require.NoError(s.T(), err)
+	nic := s.client.insertCalls[0].GetInstanceResource().GetNetworkInterfaces()[0]
+	assert.Equal(s.T(), e.cfg.Subnet, nic.GetSubnetwork())
+}
+
+func (s *GCPEngineSuite) TestSelectSubnet_AutoModeLeavesSubnetEmpty() {
+	e := s.newEngine()
+	network := customNetwork()
+	network.AutoCreateSubnetworks = proto.Bool(true)
+	subnets := &mockSubnetworksClient{}
+
+	require.NoError(s.T(), e.selectSubnet(s.ctx, &mockNetworksClient{network: network}, subnets))
+	assert.Empty(s.T(), e.cfg.Subnet)
+	assert.Nil(s.T(), subnets.req, "auto-mode networks need no subnet lookup")
+}
+
+func (s *GCPEngineSuite) TestSelectSubnet_SharedVPCListsHostProject() {
+	s.cfg.Network = "projects/host-project/global/networks/ci-vpc"
+	e := s.newEngine()
+	networks := &mockNetworksClient{network: customNetwork()}
+	subnets := &mockSubnetworksClient{subnets: []*computepb.Subnetwork{subnet("ci-us-central1", testNetworkSelfLink)}}
+
+	require.NoError(s.T(), e.selectSubnet(s.ctx, networks, subnets))
+	assert.Equal(s.T(), "host-project", networks.req.GetProject())
+	assert.Equal(s.T(), "ci-vpc", networks.req.GetNetwork())
+	assert.Equal(s.T(), "host-project", subnets.req.GetProject())
+}
+
+func (s *GCPEngineSuite) TestSelectSubnet_NoSubnetInRegion() {
+	e := s.newEngine()
+	subnets := &mockSubnetworksClient{subnets: []*computepb.Subnetwork{
+		subnet("other", "https://www.googleapis.com/compute/v1/projects/test-project/global/networks/other"),
+	}}
+
+	err := e.selectSubnet(s.ctx, &mockNetworksClient{network: customNetwork()}, subnets)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "no subnet in region us-central1")
+}
+
+func (s *GCPEngineSuite) TestSelectSubnet_SeveralSubnetsPicksFirstByName() {
+	e := s.newEngine()
+	subnets := &mockSubnetworksClient{subnets: []*computepb.Subnetwork{
+		subnet("ci-b", testNetworkSelfLink),
+		subnet("ci-a", testNetworkSelfLink),
+	}}
+
+	require.NoError(s.T(), e.selectSubnet(s.ctx, &mockNetworksClient{network: customNetwork()}, subnets))
+	assert.True(s.T(), strings.HasSuffix(e.cfg.Subnet, "/subnetworks/ci-a"))
+}
+
+func (s *GCPEngineSuite) TestNetworkURL() {
+	cases := map[string]string{
+		"default":                "global/networks/default",
+		"my-vpc":                 "global/networks/my-vpc",
+		"global/networks/my-vpc": "global/networks/my-vpc",
+		"projects/host-project/global/networks/vpc":                                       "projects/host-project/global/networks/vpc",
+		"https://www.googleapis.com/compute/v1/projects/host-project/global/networks/vpc": "https://www.googleapis.com/compute/v1/projects/host-project/global/networks/vpc",
+	}
+	for in, want := range cases {
+		assert.Equal(s.T(), want, networkURL(in), in)
+	}
+}
+
+func (s *GCPEngineSuite) TestStartRunner_NetworkSelfLink() {
+	s.cfg.Network = "https://www.googleapis.com/compute/v1/projects/host-project/global/networks/shared-vpc"
+	e := s.newEngine()
+
+	_, err := e.StartRunner(s.ctx, "runner-selflink", "jit")
+	require.NoError(s.T(), err)
+
+	nic := s.client.insertCalls[0].GetInstanceResource().GetNetworkInterfaces()[0]
+	assert.Equal(s.T(), s.cfg.Network, nic.GetNetwork())
+}
+
+func (s *GCPEngineSuite) TestRegionFromZone() {
+	assert.Equal(s.T(), "us-central1", RegionFromZone("us-central1-a"))
+	assert.Equal(s.T(), "europe-west4", RegionFromZone("europe-west4-b"))
+	assert.Equal(s.T(), "local", RegionFromZone("local"))
+}
+
+// ---------------------------------------------------------------------------
+// Quota headroom tests
+// ---------------------------------------------------------------------------
+
+func quotaRegion(cpuUsage, cpuLimit, instUsage, instLimit float64) *computepb.Region {
+	return &computepb.Region{Quotas: []*computepb.Quota{
+		{Metric: proto.String("CPUS"), Usage: proto.Float64(cpuUsage), Limit: proto.Float64(cpuLimit)},
+		{Metric: proto.String("INSTANCES"), Usage: proto.Float64(instUsage), Limit: proto.Float64(instLimit)},
+	}}
+}
+
+func (s *GCPEngineSuite) TestCheckQuotaHeadroom_Sufficient() {
+	mt := &mockMachineTypesClient{guestCpus: 2}
+	e := s.newEngine()
+	e.machineTypes = mt
+	// 10 runners x 2 vCPUs = 20, with 24 - 4 = 20 free.
+	e.regions = &mockRegionsClient{region: quotaRegion(4, 24, 2, 100)}
+
+	require.NoError(s.T(), e.CheckQuotaHeadroom(s.ctx, 10))
+	assert.Equal(s.T(), "e2-medium", mt.req.GetMachineType())
+	assert.Equal(s.T(), "us-central1-a", mt.req.GetZone())
+}
+
+func (s *GCPEngineSuite) TestCheckQuotaHeadroom_InsufficientCPUs() {
+	e := s.newEngine()
+	e.machineTypes = &mockMachineTypesClient{guestCpus: 4}
+	e.regions = &mockRegionsClient{region: quotaRegion(8, 24, 2, 100)}
+
+	err := e.CheckQuotaHeadroom(s.ctx, 10)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "CPUS")
+	assert.Contains(s.T(), err.Error(), "needs 40, only 16 of 24 free")
+	assert.NotContains(s.T(), err.Error(), "INSTANCES")
+}
+
+func (s *GCPEngineSuite) TestCheckQuotaHeadroom_InsufficientInstances() {
+	e := s.newEngine()
+	e.machineTypes = &mockMachineTypesClient{guestCpus: 1}
+	e.regions = &mockRegionsClient{region: quotaRegion(0, 100, 8, 10)}
+
+	err := e.CheckQuotaHeadroom(s.ctx, 5)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "INSTANCES")
+}
+
+func (s *GCPEngineSuite) TestCheckQuotaHeadroom_RegionError() {
+	e := s.newEngine()
+	e.machineTypes = &mockMachineTypesClient{guestCpus: 2}
+	e.regions = &mockRegionsClient{err: fmt.Errorf("permission denied")}
+
+	require.Error(s.T(), e.CheckQuotaHeadroom(s.ctx, 1))
+}
+
+func (s *GCPEngineSuite) TestCheckQuotaHeadroom_NoClients() {
+	require.Error(s.T(), s.newEngine().CheckQuotaHeadroom(s.ctx, 1))
+}
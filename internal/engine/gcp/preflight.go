@@ -0,0 +1,171 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	computepb "cloud.google.com/go/compute/apiv1/computepb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// zonesAPI looks up a zone.  *compute.ZonesClient satisfies it directly.
+type zonesAPI interface {
+	Get(ctx context.Context, req *computepb.GetZoneRequest, opts ...gax.CallOption) (*computepb.Zone, error)
+	Close() error
+}
+
+// imagesAPI looks up an image by name or family.  *compute.ImagesClient
+// satisfies it directly.
+type imagesAPI interface {
+	Get(ctx context.Context, req *computepb.GetImageRequest, opts ...gax.CallOption) (*computepb.Image, error)
+	GetFromFamily(ctx context.Context, req *computepb.GetFromFamilyImageRequest, opts ...gax.CallOption) (*computepb.Image, error)
+	Close() error
+}
+
+// acceleratorTypesAPI looks up an accelerator type in a zone.
+// *compute.AcceleratorTypesClient satisfies it directly.
+type acceleratorTypesAPI interface {
+	Get(ctx context.Context, req *computepb.GetAcceleratorTypeRequest, opts ...gax.CallOption) (*computepb.AcceleratorType, error)
+	Close() error
+}
+
+// Preflight checks, without creating anything, that the project, zones,
+// machine type, accelerator types and image of cfg exist and can be read with the
+// Application Default Credentials.  A missing or inaccessible project
+// fails the zone lookup.  The returned diagnostics describe what was
+// found.
+func Preflight(ctx context.Context, cfg Config) (map[string]string, error) {
+	if cfg.MachineType == "" {
+		cfg.MachineType = "e2-medium"
+	}
+
+	opts, err := clientOptions(ctx, cfg.Egress)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := compute.NewZonesRESTClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("gcp zones client: %w", err)
+	}
+	defer zones.Close()
+	machineTypes, err := compute.NewMachineTypesRESTClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("gcp machine types client: %w", err)
+	}
+	defer machineTypes.Close()
+	images, err := compute.NewImagesRESTClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("gcp images client: %w", err)
+	}
+	defer images.Close()
+	acceleratorTypes, err := compute.NewAcceleratorTypesRESTClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("gcp accelerator types client: %w", err)
+	}
+	defer acceleratorTypes.Close()
+
+	return preflight(ctx, zones, machineTypes, acceleratorTypes, images, cfg)
+}
+
+// preflight runs the checks of Preflight against the given clients.
+func preflight(ctx context.Context, zones zonesAPI, machineTypes machineTypesAPI, acceleratorTypes acceleratorTypesAPI, images imagesAPI, cfg Config) (map[string]string, error) {
+	names := cfg.Zones
+	if len(names) == 0 {
+		names = []string{cfg.Zone}
+	}
+	diags := map[string]string{
+		"gcp.project": cfg.Project,
+		"gcp.zone":    strings.Join(names, ", "),
+	}
+
+	for _, name := range names {
+		if err := preflightZone(ctx, zones, machineTypes, acceleratorTypes, cfg, name, diags); err != nil {
+			return diags, err
+		}
+	}
+
+	img, err := lookupImage(ctx, images, cfg.Project, cfg.Image)
+	if err != nil {
+		return diags, err
+	}
+	diags["gcp.image"] = img.GetSelfLink()
+	if status := img.GetStatus(); status != "" && status != "READY" {
+		return diags, fmt.Errorf("gcp image %s is %s", cfg.Image, status)
+	}
+	return diags, nil
+}
+
+// preflightZone checks that zone is up and offers the machine type and
+// accelerator types of cfg, recording what it found in diags.
+func preflightZone(ctx context.Context, zones zonesAPI, machineTypes machineTypesAPI, acceleratorTypes acceleratorTypesAPI, cfg Config, zone string, diags map[string]string) error {
+	z, err := zones.Get(ctx, &computepb.GetZoneRequest{Project: cfg.Project, Zone: zone})
+	if err != nil {
+		return fmt.Errorf("gcp zone %s in project %s: %w", zone, cfg.Project, err)
+	}
+	if status := z.GetStatus(); status != "" && status != "UP" {
+		return fmt.Errorf("gcp zone %s is %s", zone, status)
+	}
+
+	mt, err := machineTypes.Get(ctx, &computepb.GetMachineTypeRequest{
+		Project:     cfg.Project,
+		Zone:        zone,
+		MachineType: cfg.MachineType,
+	})
+	if err != nil {
+		return fmt.Errorf("gcp machine type %s: %w", cfg.MachineType, err)
+	}
+	diags["gcp.machine_type"] = fmt.Sprintf("%s (%d vCPUs)", cfg.MachineType, mt.GetGuestCpus())
+
+	// Not every zone offers every GPU, and each type caps the cards one
+	// VM can attach; both only show up as an insert error otherwise.
+	var accelerators []string
+	for _, a := range cfg.Accelerators {
+		at, err := acceleratorTypes.Get(ctx, &computepb.GetAcceleratorTypeRequest{
+			Project:         cfg.Project,
+			Zone:            zone,
+			AcceleratorType: a.Type,
+		})
+		if err != nil {
+			return fmt.Errorf("gcp accelerator type %s in zone %s: %w", a.Type, zone, err)
+		}
+		if max := at.GetMaximumCardsPerInstance(); max > 0 && a.Count > max {
+			return fmt.Errorf("gcp accelerator type %s allows at most %d per VM, got %d", a.Type, max, a.Count)
+		}
+		accelerators = append(accelerators, fmt.Sprintf("%d x %s", a.Count, a.Type))
+	}
+	if len(accelerators) > 0 {
+		diags["gcp.accelerators"] = strings.Join(accelerators, ", ")
+	}
+	return nil
+}
+
+// lookupImage resolves image, a self-link, a
+// "projects/P/global/images/NAME" or "projects/P/global/images/family/F"
+// path, or a bare image name in project.
+func lookupImage(ctx context.Context, images imagesAPI, project, image string) (*computepb.Image, error) {
+	path := image
+	if i := strings.Index(path, "projects/"); i >= 0 {
+		path = path[i:]
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) >= 2 && parts[0] == "projects" {
+		project = parts[1]
+	}
+
+	var (
+		img *computepb.Image
+		err error
+	)
+	switch n := len(parts); {
+	case n >= 2 && parts[n-2] == "family":
+		img, err = images.GetFromFamily(ctx, &computepb.GetFromFamilyImageRequest{Project: project, Family: parts[n-1]})
+	default:
+		img, err = images.Get(ctx, &computepb.GetImageRequest{Project: project, Image: parts[n-1]})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gcp image %s: %w", image, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,206 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	computepb "cloud.google.com/go/compute/apiv1/computepb"
+	gax "github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+type mockZonesClient struct {
+	zone *computepb.Zone
+	err  error
+	req  *computepb.GetZoneRequest
+}
+
+func (m *mockZonesClient) Get(_ context.Context, req *computepb.GetZoneRequest, _ ...gax.CallOption) (*computepb.Zone, error) {
+	m.req = req
+	return m.zone, m.err
+}
+
+func (m *mockZonesClient) Close() error { return nil }
+
+type mockImagesClient struct {
+	image     *computepb.Image
+	err       error
+	req       *computepb.GetImageRequest
+	familyReq *computepb.GetFromFamilyImageRequest
+}
+
+func (m *mockImagesClient) Get(_ context.Context, req *computepb.GetImageRequest, _ ...gax.CallOption) (*computepb.Image, error) {
+	m.req = req
+	return m.image, m.err
+}
+
+func (m *mockImagesClient) GetFromFamily(_ context.Context, req *computepb.GetFromFamilyImageRequest, _ ...gax.CallOption) (*computepb.Image, error) {
+	m.familyReq = req
+	return m.image, m.err
+}
+
+func (m *mockImagesClient) Close() error { return nil }
+
+type mockAcceleratorTypesClient struct {
+	maxCards int32
+	err      error
+	reqs     []*computepb.GetAcceleratorTypeRequest
+}
+
+func (m *mockAcceleratorTypesClient) Get(_ context.Context, req *computepb.GetAcceleratorTypeRequest, _ ...gax.CallOption) (*computepb.AcceleratorType, error) {
+	m.reqs = append(m.reqs, req)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &computepb.AcceleratorType{MaximumCardsPerInstance: proto.Int32(m.maxCards)}, nil
+}
+
+func (m *mockAcceleratorTypesClient) Close() error { return nil }
+
+func preflightConfig() Config {
+	return Config{
+		Project:     "my-project",
+		Zone:        "us-central1-a",
+		MachineType: "e2-standard-4",
+		Image:       "projects/my-project/global/images/family/scaleset-runner",
+	}
+}
+
+func readyImage() *computepb.Image {
+	return &computepb.Image{
+		SelfLink: proto.String("https://www.googleapis.com/compute/v1/projects/my-project/global/images/scaleset-runner-2"),
+		Status:   proto.String("READY"),
+	}
+}
+
+func TestPreflight_Passes(t *testing.T) {
+	zones := &mockZonesClient{zone: &computepb.Zone{Status: proto.String("UP")}}
+	images := &mockImagesClient{image: readyImage()}
+
+	diags, err := preflight(context.Background(), zones, &mockMachineTypesClient{guestCpus: 4}, &mockAcceleratorTypesClient{}, images, preflightConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "my-project", zones.req.GetProject())
+	assert.Equal(t, "us-central1-a", zones.req.GetZone())
+	assert.Equal(t, "scaleset-runner", images.familyReq.GetFamily())
+	assert.Equal(t, map[string]string{
+		"gcp.project":      "my-project",
+		"gcp.zone":         "us-central1-a",
+		"gcp.machine_type": "e2-standard-4 (4 vCPUs)",
+		"gcp.image":        "https://www.googleapis.com/compute/v1/projects/my-project/global/images/scaleset-runner-2",
+	}, diags)
+}
+
+func TestPreflight_Fails(t *testing.T) {
+	tests := []struct {
+		name    string
+		zones   *mockZonesClient
+		images  *mockImagesClient
+		wantErr string
+	}{
+		{
+			name:    "unknown project or zone",
+			zones:   &mockZonesClient{err: errors.New("404 not found")},
+			images:  &mockImagesClient{image: readyImage()},
+			wantErr: "gcp zone us-central1-a in project my-project: 404 not found",
+		},
+		{
+			name:    "zone down",
+			zones:   &mockZonesClient{zone: &computepb.Zone{Status: proto.String("DOWN")}},
+			images:  &mockImagesClient{image: readyImage()},
+			wantErr: "gcp zone us-central1-a is DOWN",
+		},
+		{
+			name:    "missing image",
+			zones:   &mockZonesClient{zone: &computepb.Zone{Status: proto.String("UP")}},
+			images:  &mockImagesClient{err: errors.New("404 not found")},
+			wantErr: "gcp image projects/my-project/global/images/family/scaleset-runner: 404 not found",
+		},
+		{
+			name:    "image not ready",
+			zones:   &mockZonesClient{zone: &computepb.Zone{Status: proto.String("UP")}},
+			images:  &mockImagesClient{image: &computepb.Image{Status: proto.String("PENDING")}},
+			wantErr: "gcp image projects/my-project/global/images/family/scaleset-runner is PENDING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := preflight(context.Background(), tt.zones, &mockMachineTypesClient{guestCpus: 2}, &mockAcceleratorTypesClient{}, tt.images, preflightConfig())
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPreflight_ChecksEachZone(t *testing.T) {
+	zones := &mockZonesClient{zone: &computepb.Zone{Status: proto.String("UP")}}
+	images := &mockImagesClient{image: readyImage()}
+	cfg := preflightConfig()
+	cfg.Zones = []string{"us-central1-a", "us-central1-b"}
+
+	diags, err := preflight(context.Background(), zones, &mockMachineTypesClient{guestCpus: 4}, &mockAcceleratorTypesClient{}, images, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "us-central1-b", zones.req.GetZone())
+	assert.Equal(t, "us-central1-a, us-central1-b", diags["gcp.zone"])
+}
+
+func TestPreflight_Accelerators(t *testing.T) {
+	zones := &mockZonesClient{zone: &computepb.Zone{Status: proto.String("UP")}}
+	images := &mockImagesClient{image: readyImage()}
+	cfg := preflightConfig()
+	cfg.MachineType = "n1-standard-8"
+	cfg.Accelerators = []Accelerator{{Type: "nvidia-tesla-t4", Count: 2}}
+
+	accelerators := &mockAcceleratorTypesClient{maxCards: 4}
+	diags, err := preflight(context.Background(), zones, &mockMachineTypesClient{guestCpus: 8}, accelerators, images, cfg)
+	require.NoError(t, err)
+	require.Len(t, accelerators.reqs, 1)
+	assert.Equal(t, "us-central1-a", accelerators.reqs[0].GetZone())
+	assert.Equal(t, "nvidia-tesla-t4", accelerators.reqs[0].GetAcceleratorType())
+	assert.Equal(t, "2 x nvidia-tesla-t4", diags["gcp.accelerators"])
+
+	_, err = preflight(context.Background(), zones, &mockMachineTypesClient{guestCpus: 8},
+		&mockAcceleratorTypesClient{maxCards: 1}, images, cfg)
+	require.EqualError(t, err, "gcp accelerator type nvidia-tesla-t4 allows at most 1 per VM, got 2")
+
+	_, err = preflight(context.Background(), zones, &mockMachineTypesClient{guestCpus: 8},
+		&mockAcceleratorTypesClient{err: errors.New("404 not found")}, images, cfg)
+	require.EqualError(t, err, "gcp accelerator type nvidia-tesla-t4 in zone us-central1-a: 404 not found")
+}
+
+func TestLookupImage(t *testing.T) {
+	tests := []struct {
+		image      string
+		wantImage  *computepb.GetImageRequest
+		wantFamily *computepb.GetFromFamilyImageRequest
+	}{
+		{
+			image:     "projects/ubuntu-os-cloud/global/images/ubuntu-2404-noble-amd64-v20250101",
+			wantImage: &computepb.GetImageRequest{Project: "ubuntu-os-cloud", Image: "ubuntu-2404-noble-amd64-v20250101"},
+		},
+		{
+			image:      "https://www.googleapis.com/compute/v1/projects/other/global/images/family/runner",
+			wantFamily: &computepb.GetFromFamilyImageRequest{Project: "other", Family: "runner"},
+		},
+		{
+			image:     "scaleset-runner-1",
+			wantImage: &computepb.GetImageRequest{Project: "my-project", Image: "scaleset-runner-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			images := &mockImagesClient{image: readyImage()}
+			_, err := lookupImage(context.Background(), images, "my-project", tt.image)
+			require.NoError(t, err)
+			if tt.wantImage != nil {
+				assert.True(t, proto.Equal(tt.wantImage, images.req), "got %v", images.req)
+			}
+			if tt.wantFamily != nil {
+				assert.True(t, proto.Equal(tt.wantFamily, images.familyReq), "got %v", images.familyReq)
+			}
+		})
+	}
+}
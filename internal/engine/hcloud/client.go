@@ -0,0 +1,208 @@
+package hcloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultEndpoint is the Hetzner Cloud API.
+const DefaultEndpoint = "https://api.hetzner.cloud/v1"
+
+// serversAPI abstracts the Hetzner Cloud API calls the engine makes so
+// that tests can provide a mock implementation.  restClient satisfies
+// it.
+type serversAPI interface {
+	CreateServer(ctx context.Context, req *createServerRequest) (*server, error)
+	GetServer(ctx context.Context, id int64) (*server, error)
+	ListServers(ctx context.Context, name, labelSelector string) ([]server, error)
+	DeleteServer(ctx context.Context, id int64) error
+}
+
+// createServerRequest is the body of POST /servers.  Only the fields
+// scaleset sets are declared.
+type createServerRequest struct {
+	Name             string            `json:"name"`
+	ServerType       string            `json:"server_type"`
+	Image            string            `json:"image"`
+	Location         string            `json:"location,omitempty"`
+	SSHKeys          []string          `json:"ssh_keys,omitempty"`
+	UserData         string            `json:"user_data"`
+	Labels           map[string]string `json:"labels"`
+	StartAfterCreate bool              `json:"start_after_create"`
+	PublicNet        *publicNet        `json:"public_net,omitempty"`
+}
+
+type publicNet struct {
+	EnableIPv4 bool `json:"enable_ipv4"`
+	EnableIPv6 bool `json:"enable_ipv6"`
+}
+
+// server is the subset of the Server object the engine reads.
+type server struct {
+	ID     int64             `json:"id"`
+	Name   string            `json:"name"`
+	Status string            `json:"status"`
+	Labels map[string]string `json:"labels"`
+}
+
+// Server statuses in which the server cannot run a job (any more).
+const (
+	statusStopping = "stopping"
+	statusOff      = "off"
+	statusDeleting = "deleting"
+)
+
+// apiError is a non-2xx response from the Hetzner Cloud API, which
+// carries an error code such as "not_found", "uniqueness_error" or
+// "resource_limit_exceeded".
+type apiError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	msg := fmt.Sprintf("hcloud API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	return msg
+}
+
+// isNotFound reports whether err is a 404 from the API.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// restClient talks to the Hetzner Cloud REST API with an API token.
+type restClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// Compile-time check.
+var _ serversAPI = (*restClient)(nil)
+
+// listPageSize is the largest page the API returns.
+const listPageSize = 50
+
+func (c *restClient) CreateServer(ctx context.Context, req *createServerRequest) (*server, error) {
+	var resp struct {
+		Server server `json:"server"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/servers", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+func (c *restClient) GetServer(ctx context.Context, id int64) (*server, error) {
+	var resp struct {
+		Server server `json:"server"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/servers/"+strconv.FormatInt(id, 10), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+// ListServers returns the servers named name (if set) that match
+// labelSelector (if set), following pagination.
+func (c *restClient) ListServers(ctx context.Context, name, labelSelector string) ([]server, error) {
+	var out []server
+	for page := 1; page != 0; {
+		query := url.Values{
+			"page":     {strconv.Itoa(page)},
+			"per_page": {strconv.Itoa(listPageSize)},
+		}
+		if name != "" {
+			query.Set("name", name)
+		}
+		if labelSelector != "" {
+			query.Set("label_selector", labelSelector)
+		}
+		var resp struct {
+			Servers []server `json:"servers"`
+			Meta    struct {
+				Pagination struct {
+					NextPage int `json:"next_page"`
+				} `json:"pagination"`
+			} `json:"meta"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/servers", query, nil, &resp); err != nil {
+			return nil, err
+		}
+		out = append(out, resp.Servers...)
+		page = resp.Meta.Pagination.NextPage
+	}
+	return out, nil
+}
+
+func (c *restClient) DeleteServer(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/servers/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
+
+// do sends a request with a JSON body (if in is non-nil) and decodes a
+// JSON response into out (if non-nil).
+func (c *restClient) do(ctx context.Context, method, p string, query url.Values, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	u := c.baseURL + p
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Errors come back as {"error": {"code", "message"}}; fall back
+		// to the raw body.
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var e struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		apiErr := &apiError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(data))}
+		if json.Unmarshal(data, &e) == nil && e.Error.Message != "" {
+			apiErr.Code, apiErr.Message = e.Error.Code, e.Error.Message
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, p, err)
+	}
+	return nil
+}
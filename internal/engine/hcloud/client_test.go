@@ -0,0 +1,84 @@
+package hcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(srv *httptest.Server, token string) *restClient {
+	return &restClient{baseURL: srv.URL, token: token, http: srv.Client()}
+}
+
+func TestRestClient_CreateServer(t *testing.T) {
+	var got createServerRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/servers", r.URL.Path)
+		assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"server":{"id":42,"name":"runner-a","status":"initializing"},"action":{"id":1}}`))
+	}))
+	defer srv.Close()
+
+	created, err := newTestClient(srv, "s3cret").CreateServer(context.Background(), &createServerRequest{
+		Name: "runner-a", ServerType: "cx22", Image: "ubuntu-24.04", StartAfterCreate: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), created.ID)
+	assert.Equal(t, "runner-a", got.Name)
+	assert.Equal(t, "cx22", got.ServerType)
+}
+
+func TestRestClient_ListServersFollowsPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/servers", r.URL.Path)
+		assert.Equal(t, "scaleset-managed=true", r.URL.Query().Get("label_selector"))
+		assert.Equal(t, "50", r.URL.Query().Get("per_page"))
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`{"servers":[{"id":1,"name":"runner-a","status":"running"}],"meta":{"pagination":{"next_page":2}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"servers":[{"id":2,"name":"runner-b","status":"off"}],"meta":{"pagination":{"next_page":null}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	servers, err := newTestClient(srv, "t").ListServers(context.Background(), "", "scaleset-managed=true")
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	assert.Equal(t, "runner-a", servers[0].Name)
+	assert.Equal(t, "off", servers[1].Status)
+}
+
+func TestRestClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/servers/404":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"server with ID '404' not found"}}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`bad gateway`))
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "t")
+
+	err := c.DeleteServer(context.Background(), 404)
+	require.Error(t, err)
+	assert.True(t, isNotFound(err))
+	assert.Contains(t, err.Error(), "server with ID '404' not found (not_found)")
+
+	_, err = c.GetServer(context.Background(), 1)
+	require.Error(t, err)
+	assert.False(t, isNotFound(err))
+	assert.Contains(t, err.Error(), "502 Bad Gateway: bad gateway")
+}
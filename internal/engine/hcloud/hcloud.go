@@ -0,0 +1,428 @@
+// Package hcloud implements the engine.Engine interface by running each
+// ephemeral GitHub Actions runner on its own Hetzner Cloud server.
+//
+// The JIT config reaches the server as cloud-init user data, so the
+// image only needs cloud-init and an installed runner (see
+// Config.RunnerDir); a snapshot of a server prepared once is the usual
+// choice.  Servers are deleted, never powered off, when their job is
+// done.
+package hcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/terrpan/scaleset/internal/egress"
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// Config holds Hetzner Cloud engine settings.
+type Config struct {
+	// Token is the Hetzner Cloud API token of the project servers are
+	// created in.  Default: the HCLOUD_TOKEN environment variable.
+	Token string
+
+	// ServerType is the server type, e.g. "cx22" or "cax21".
+	// Default: "cx22".
+	ServerType string
+
+	// Image is the name or ID of the image or snapshot servers boot
+	// from.  It must provide cloud-init and the runner in RunnerDir.
+	// Default: "ubuntu-24.04", which has no runner and only suits a
+	// RunnerDir prepared by other means.
+	Image string
+
+	// Location is the location servers are created in, e.g. "fsn1".
+	// Empty lets Hetzner pick one that offers ServerType.
+	Location string
+
+	// SSHKeys are the names or IDs of project SSH keys installed for
+	// root, for debugging.  Without any, Hetzner mails a root password
+	// for every server, so set at least one in practice.
+	SSHKeys []string
+
+	// PublicIPv4 gives servers a public IPv4 address.  Without one they
+	// need IPv6 access to GitHub.  Default: true.
+	PublicIPv4 bool
+
+	// RunnerDir is the directory of the installed runner (run.sh).
+	// Default: "/home/runner".
+	RunnerDir string
+
+	// RunnerUser is the user the runner runs as.  Default: "runner".
+	RunnerUser string
+
+	// RunID is recorded on every runner server with the
+	// engine.RunIDLabel label, next to engine.ManagedLabel, so the
+	// servers of a crashed process can be found by the cleanup command.
+	RunID string
+
+	// Endpoint is the API base URL.  Default: DefaultEndpoint.
+	Endpoint string
+
+	// Egress sets the proxy and extra CAs of the API client.
+	Egress egress.Config
+}
+
+// Engine manages GitHub Actions runners as Hetzner Cloud servers.
+type Engine struct {
+	client serversAPI
+	cfg    Config
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	servers map[string]string // runner name -> server id
+
+	// OpenTelemetry instrumentation
+	tracer trace.Tracer
+}
+
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine       = (*Engine)(nil)
+	_ engine.Checker      = (*Engine)(nil)
+	_ engine.RunnerFinder = (*Engine)(nil)
+	_ engine.RunnerLister = (*Engine)(nil)
+)
+
+// New creates a Hetzner Cloud engine.
+func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Engine, error) {
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("HCLOUD_TOKEN")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("hcloud: no API token (set engine.hcloud.token or HCLOUD_TOKEN)")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	transport, err := cfg.Egress.Transport()
+	if err != nil {
+		return nil, fmt.Errorf("hcloud: %w", err)
+	}
+	client := &restClient{
+		baseURL: strings.TrimRight(cfg.Endpoint, "/"),
+		token:   cfg.Token,
+		http:    &http.Client{Transport: transport},
+	}
+	return newEngine(client, cfg, logger), nil
+}
+
+// newEngine is the internal constructor used by New and by tests.
+func newEngine(client serversAPI, cfg Config, logger *slog.Logger) *Engine {
+	if cfg.ServerType == "" {
+		cfg.ServerType = "cx22"
+	}
+	if cfg.Image == "" {
+		cfg.Image = "ubuntu-24.04"
+	}
+	if cfg.RunnerDir == "" {
+		cfg.RunnerDir = "/home/runner"
+	}
+	if cfg.RunnerUser == "" {
+		cfg.RunnerUser = "runner"
+	}
+
+	logger.Info("hcloud engine initialized",
+		slog.String("serverType", cfg.ServerType),
+		slog.String("image", cfg.Image),
+		slog.String("location", cfg.Location),
+	)
+
+	return &Engine{
+		client:  client,
+		cfg:     cfg,
+		logger:  logger,
+		servers: make(map[string]string),
+		tracer:  otel.Tracer("scaleset/engine/hcloud"),
+	}
+}
+
+// StartRunner creates a server that runs a GitHub Actions runner with
+// the provided JIT configuration.  It returns once the API has accepted
+// the server; the boot happens afterwards.
+func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string) (string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.hcloud.StartRunner")
+	defer span.End()
+
+	serverName := ServerName(name)
+	span.SetAttributes(
+		attribute.String("runner.name", name),
+		attribute.String("hcloud.server_name", serverName),
+		attribute.String("hcloud.server_type", e.cfg.ServerType),
+		attribute.String("hcloud.location", e.cfg.Location),
+	)
+
+	e.logger.Info("creating runner server",
+		slog.String("name", name),
+		slog.String("server", serverName),
+		slog.String("serverType", e.cfg.ServerType),
+	)
+
+	srv, err := e.client.CreateServer(ctx, &createServerRequest{
+		Name:             serverName,
+		ServerType:       e.cfg.ServerType,
+		Image:            e.cfg.Image,
+		Location:         e.cfg.Location,
+		SSHKeys:          e.cfg.SSHKeys,
+		UserData:         e.userData(jitConfig),
+		Labels:           engine.RunnerLabels(e.cfg.RunID),
+		StartAfterCreate: true,
+		PublicNet:        &publicNet{EnableIPv4: e.cfg.PublicIPv4, EnableIPv6: true},
+	})
+	if err != nil {
+		return "", fmt.Errorf("create server %s: %w", serverName, err)
+	}
+
+	id := strconv.FormatInt(srv.ID, 10)
+	span.SetAttributes(attribute.String("hcloud.server_id", id))
+
+	e.mu.Lock()
+	e.servers[name] = id
+	e.mu.Unlock()
+
+	e.logger.Info("runner server created",
+		slog.String("name", name),
+		slog.String("server", serverName),
+		slog.String("id", id),
+	)
+	return id, nil
+}
+
+// jitConfigPath is where the user data puts the JIT config on the
+// server.  /run is a tmpfs, so the config never reaches the disk.
+const jitConfigPath = "/run/scaleset/jitconfig"
+
+// userData returns the cloud-init user data that starts the runner.
+// The JIT config is written to a root-only file and handed to the
+// runner in its environment, never on a command line where any user
+// could read it.  A JIT config is base64, so it needs no escaping
+// inside the double-quoted YAML string.
+func (e *Engine) userData(jitConfig string) string {
+	run := fmt.Sprintf(`ACTIONS_RUNNER_INPUT_JITCONFIG="$(cat %s)" exec runuser -u %s -- %s/run.sh`,
+		jitConfigPath, e.cfg.RunnerUser, strings.TrimRight(e.cfg.RunnerDir, "/"))
+	return fmt.Sprintf(`#cloud-config
+write_files:
+  - path: %s
+    permissions: "0600"
+    content: "%s"
+runcmd:
+  - [sh, -c, '%s']
+`, jitConfigPath, jitConfig, run)
+}
+
+// maxServerNameLength keeps server names valid hostnames, which is what
+// Hetzner requires of them.
+const maxServerNameLength = 63
+
+// ServerName returns the server name for a runner: the runner name
+// lowercased, with characters other than letters, digits and '-'
+// replaced by '-', trimmed to a valid hostname label.
+func ServerName(runnerName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, runnerName)
+	if len(name) > maxServerNameLength {
+		name = name[:maxServerNameLength]
+	}
+	return strings.Trim(name, "-")
+}
+
+// DestroyRunner deletes the runner's server.  Deleting a server that no
+// longer exists is not an error.
+func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
+	ctx, span := e.tracer.Start(ctx, "engine.hcloud.DestroyRunner")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("hcloud.server_id", id))
+
+	serverID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("delete server %q: invalid server id", id)
+	}
+
+	e.logger.Info("deleting runner server", slog.String("id", id))
+
+	if err := e.client.DeleteServer(ctx, serverID); err != nil {
+		if !isNotFound(err) {
+			return fmt.Errorf("delete server %s: %w", id, err)
+		}
+		span.AddEvent("server already deleted (idempotent)")
+		e.logger.Info("runner server already deleted", slog.String("id", id))
+	} else {
+		e.logger.Info("runner server deleted", slog.String("id", id))
+	}
+
+	e.removeFromTracking(id)
+	return nil
+}
+
+// Check implements engine.Checker.  It lists the engine's runner
+// servers to confirm the API is reachable and the token is valid.
+func (e *Engine) Check(ctx context.Context) (map[string]string, error) {
+	e.mu.Lock()
+	tracked := len(e.servers)
+	e.mu.Unlock()
+
+	diags := map[string]string{
+		"hcloud.server_type": e.cfg.ServerType,
+		"hcloud.runners":     strconv.Itoa(tracked),
+	}
+	if e.cfg.Location != "" {
+		diags["hcloud.location"] = e.cfg.Location
+	}
+
+	servers, err := e.client.ListServers(ctx, "", labelSelector(engine.RunnerLabels(e.cfg.RunID)))
+	if err != nil {
+		return diags, fmt.Errorf("hcloud API unreachable: %w", err)
+	}
+	diags["hcloud.servers"] = strconv.Itoa(len(servers))
+	return diags, nil
+}
+
+// FindRunner implements engine.RunnerFinder.  Server names are unique
+// per project, so the server for key is looked up by name.  A server
+// that is powering off or off cannot serve a job and is deleted so the
+// name can be reused.
+func (e *Engine) FindRunner(ctx context.Context, key string) (string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.hcloud.FindRunner")
+	defer span.End()
+
+	serverName := ServerName(key)
+	span.SetAttributes(
+		attribute.String("runner.name", key),
+		attribute.String("hcloud.server_name", serverName),
+	)
+
+	servers, err := e.client.ListServers(ctx, serverName, "")
+	if err != nil {
+		return "", fmt.Errorf("find server %s: %w", serverName, err)
+	}
+	if len(servers) == 0 {
+		return "", nil
+	}
+	srv := servers[0]
+	id := strconv.FormatInt(srv.ID, 10)
+
+	switch srv.Status {
+	case statusDeleting:
+		return "", nil
+	case statusStopping, statusOff:
+		e.logger.Info("deleting leftover runner server that is off",
+			slog.String("name", key),
+			slog.String("id", id),
+			slog.String("status", srv.Status),
+		)
+		if err := e.DestroyRunner(ctx, id); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+
+	e.mu.Lock()
+	e.servers[key] = id
+	e.mu.Unlock()
+
+	e.logger.Info("adopted existing runner server",
+		slog.String("name", key),
+		slog.String("id", id),
+	)
+	return id, nil
+}
+
+// ListRunners implements engine.RunnerLister using a label selector.
+// Servers that are off are included so they are cleaned up too.
+func (e *Engine) ListRunners(ctx context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	servers, err := e.client.ListServers(ctx, "", labelSelector(labels))
+	if err != nil {
+		return nil, fmt.Errorf("list servers: %w", err)
+	}
+
+	runners := make([]engine.ListedRunner, 0, len(servers))
+	for _, srv := range servers {
+		runners = append(runners, engine.ListedRunner{
+			ID:     strconv.FormatInt(srv.ID, 10),
+			Name:   srv.Name,
+			Labels: srv.Labels,
+		})
+	}
+	return runners, nil
+}
+
+// labelSelector returns an equality-based selector matching all labels,
+// e.g. "scaleset-managed=true,scaleset-run-id=abc".
+func labelSelector(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Shutdown deletes all servers currently tracked by this engine
+// instance and returns every failure joined.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	ctx, span := e.tracer.Start(ctx, "engine.hcloud.Shutdown")
+	defer span.End()
+
+	e.mu.Lock()
+	snapshot := make(map[string]string, len(e.servers))
+	for k, v := range e.servers {
+		snapshot[k] = v
+	}
+	e.mu.Unlock()
+
+	span.SetAttributes(attribute.Int("hcloud.servers_count", len(snapshot)))
+
+	var errs []error
+	for name, id := range snapshot {
+		e.logger.Info("shutdown: deleting runner server",
+			slog.String("name", name),
+			slog.String("id", id),
+		)
+		if err := e.DestroyRunner(ctx, id); err != nil {
+			e.logger.Error("shutdown: failed to delete runner server",
+				slog.String("name", name),
+				slog.String("error", err.Error()),
+			)
+			errs = append(errs, fmt.Errorf("deleting %s: %w", name, err))
+		}
+	}
+
+	e.mu.Lock()
+	clear(e.servers)
+	e.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// removeFromTracking removes a server from the tracking map.
+func (e *Engine) removeFromTracking(id string) {
+	e.mu.Lock()
+	for name, serverID := range e.servers {
+		if serverID == id {
+			delete(e.servers, name)
+			break
+		}
+	}
+	e.mu.Unlock()
+}
@@ -0,0 +1,316 @@
+package hcloud
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// ---------------------------------------------------------------------------
+// Mock servers client (satisfies serversAPI)
+// ---------------------------------------------------------------------------
+
+type mockServers struct {
+	mu sync.Mutex
+
+	nextID    int64
+	servers   map[int64]*server
+	created   []*createServerRequest
+	deleted   []int64
+	selectors []string
+
+	createErr error
+	deleteErr error
+	listErr   error
+}
+
+func newMockServers() *mockServers {
+	return &mockServers{nextID: 100, servers: make(map[int64]*server)}
+}
+
+func (m *mockServers) CreateServer(_ context.Context, req *createServerRequest) (*server, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	m.nextID++
+	m.created = append(m.created, req)
+	srv := &server{ID: m.nextID, Name: req.Name, Status: "initializing", Labels: req.Labels}
+	m.servers[srv.ID] = srv
+	return srv, nil
+}
+
+func (m *mockServers) GetServer(_ context.Context, id int64) (*server, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	srv, ok := m.servers[id]
+	if !ok {
+		return nil, &apiError{StatusCode: http.StatusNotFound, Code: "not_found", Message: "server not found"}
+	}
+	return srv, nil
+}
+
+func (m *mockServers) ListServers(_ context.Context, name, labelSelector string) ([]server, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selectors = append(m.selectors, labelSelector)
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var out []server
+	for _, srv := range m.servers {
+		if name == "" || srv.Name == name {
+			out = append(out, *srv)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockServers) DeleteServer(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+	if _, ok := m.servers[id]; !ok {
+		return &apiError{StatusCode: http.StatusNotFound, Code: "not_found", Message: "server not found"}
+	}
+	delete(m.servers, id)
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Test suite
+// ---------------------------------------------------------------------------
+
+type HCloudEngineSuite struct {
+	suite.Suite
+	ctx    context.Context
+	client *mockServers
+	logger *slog.Logger
+}
+
+func TestHCloudEngineSuite(t *testing.T) {
+	suite.Run(t, new(HCloudEngineSuite))
+}
+
+func (s *HCloudEngineSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.client = newMockServers()
+	s.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func (s *HCloudEngineSuite) newEngine(cfg Config) *Engine {
+	return newEngine(s.client, cfg, s.logger)
+}
+
+// ---------------------------------------------------------------------------
+// StartRunner
+// ---------------------------------------------------------------------------
+
+func (s *HCloudEngineSuite) TestStartRunner_CreatesServer() {
+	e := s.newEngine(Config{
+		ServerType: "cax21",
+		Image:      "runner-snapshot",
+		Location:   "fsn1",
+		SSHKeys:    []string{"ops"},
+		PublicIPv4: true,
+		RunID:      "run-1",
+	})
+
+	id, err := e.StartRunner(s.ctx, "Runner_abc", "aml0LWRhdGE=")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "101", id)
+	assert.Equal(s.T(), "101", e.servers["Runner_abc"])
+
+	require.Len(s.T(), s.client.created, 1)
+	req := s.client.created[0]
+	assert.Equal(s.T(), "runner-abc", req.Name)
+	assert.Equal(s.T(), "cax21", req.ServerType)
+	assert.Equal(s.T(), "runner-snapshot", req.Image)
+	assert.Equal(s.T(), "fsn1", req.Location)
+	assert.Equal(s.T(), []string{"ops"}, req.SSHKeys)
+	assert.Equal(s.T(), engine.RunnerLabels("run-1"), req.Labels)
+	assert.True(s.T(), req.StartAfterCreate)
+	assert.Equal(s.T(), &publicNet{EnableIPv4: true, EnableIPv6: true}, req.PublicNet)
+}
+
+func (s *HCloudEngineSuite) TestStartRunner_Defaults() {
+	e := s.newEngine(Config{})
+
+	_, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.NoError(s.T(), err)
+
+	req := s.client.created[0]
+	assert.Equal(s.T(), "cx22", req.ServerType)
+	assert.Equal(s.T(), "ubuntu-24.04", req.Image)
+	assert.Empty(s.T(), req.Location, "Hetzner picks the location")
+	assert.False(s.T(), req.PublicNet.EnableIPv4)
+}
+
+func (s *HCloudEngineSuite) TestStartRunner_UserDataCarriesJITConfig() {
+	e := s.newEngine(Config{RunnerDir: "/opt/actions-runner/", RunnerUser: "gh"})
+
+	_, err := e.StartRunner(s.ctx, "runner-abc", "aml0LWRhdGE=")
+	require.NoError(s.T(), err)
+
+	ud := s.client.created[0].UserData
+	assert.True(s.T(), strings.HasPrefix(ud, "#cloud-config\n"))
+	assert.Contains(s.T(), ud, `content: "aml0LWRhdGE="`)
+	assert.Contains(s.T(), ud, `permissions: "0600"`)
+	assert.Contains(s.T(), ud, "runuser -u gh -- /opt/actions-runner/run.sh")
+	assert.NotContains(s.T(), ud, "run.sh --jitconfig", "the JIT config stays off the command line")
+}
+
+func (s *HCloudEngineSuite) TestStartRunner_CreateError() {
+	s.client.createErr = &apiError{StatusCode: http.StatusForbidden, Code: "resource_limit_exceeded", Message: "server limit reached"}
+	e := s.newEngine(Config{})
+
+	_, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "server limit reached")
+	assert.Empty(s.T(), e.servers)
+}
+
+func (s *HCloudEngineSuite) TestServerName() {
+	cases := map[string]string{
+		"runner-1a2b3c4d":                   "runner-1a2b3c4d",
+		"Runner_Build.42":                   "runner-build-42",
+		"-edge-":                            "edge",
+		"runner-" + strings.Repeat("a", 80): "runner-" + strings.Repeat("a", 56),
+	}
+	for in, want := range cases {
+		got := ServerName(in)
+		assert.Equal(s.T(), want, got, "ServerName(%q)", in)
+		assert.LessOrEqual(s.T(), len(got), maxServerNameLength)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// DestroyRunner / Shutdown
+// ---------------------------------------------------------------------------
+
+func (s *HCloudEngineSuite) TestDestroyRunner_DeletesServer() {
+	e := s.newEngine(Config{})
+	id, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, id))
+	assert.Equal(s.T(), []int64{101}, s.client.deleted)
+	assert.Empty(s.T(), e.servers)
+}
+
+func (s *HCloudEngineSuite) TestDestroyRunner_Idempotent() {
+	e := s.newEngine(Config{})
+	assert.NoError(s.T(), e.DestroyRunner(s.ctx, "4242"))
+}
+
+func (s *HCloudEngineSuite) TestDestroyRunner_Errors() {
+	e := s.newEngine(Config{})
+
+	err := e.DestroyRunner(s.ctx, "runner-abc")
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "invalid server id")
+
+	s.client.deleteErr = errors.New("connection refused")
+	err = e.DestroyRunner(s.ctx, "101")
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "connection refused")
+}
+
+func (s *HCloudEngineSuite) TestShutdown_DeletesTrackedServers() {
+	e := s.newEngine(Config{})
+	for _, name := range []string{"runner-a", "runner-b"} {
+		_, err := e.StartRunner(s.ctx, name, "jit")
+		require.NoError(s.T(), err)
+	}
+
+	require.NoError(s.T(), e.Shutdown(s.ctx))
+	assert.ElementsMatch(s.T(), []int64{101, 102}, s.client.deleted)
+	assert.Empty(s.T(), e.servers)
+}
+
+func (s *HCloudEngineSuite) TestShutdown_JoinsErrors() {
+	e := s.newEngine(Config{})
+	for _, name := range []string{"runner-a", "runner-b"} {
+		_, err := e.StartRunner(s.ctx, name, "jit")
+		require.NoError(s.T(), err)
+	}
+	s.client.deleteErr = errors.New("connection refused")
+
+	err := e.Shutdown(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "deleting runner-a")
+	assert.Contains(s.T(), err.Error(), "deleting runner-b")
+}
+
+// ---------------------------------------------------------------------------
+// Optional interfaces
+// ---------------------------------------------------------------------------
+
+func (s *HCloudEngineSuite) TestCheck() {
+	e := s.newEngine(Config{Location: "nbg1", RunID: "run-1"})
+	_, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.NoError(s.T(), err)
+
+	diags, err := e.Check(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "nbg1", diags["hcloud.location"])
+	assert.Equal(s.T(), "cx22", diags["hcloud.server_type"])
+	assert.Equal(s.T(), "1", diags["hcloud.runners"])
+	assert.Equal(s.T(), "1", diags["hcloud.servers"])
+	assert.Equal(s.T(), []string{"scaleset-managed=true,scaleset-run-id=run-1"}, s.client.selectors)
+
+	s.client.listErr = &apiError{StatusCode: http.StatusUnauthorized, Code: "unauthorized", Message: "unable to authenticate"}
+	_, err = e.Check(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "unable to authenticate")
+}
+
+func (s *HCloudEngineSuite) TestFindRunner() {
+	e := s.newEngine(Config{})
+	s.client.servers[7] = &server{ID: 7, Name: "runner-live", Status: "running"}
+	s.client.servers[8] = &server{ID: 8, Name: "runner-off", Status: statusOff}
+
+	id, err := e.FindRunner(s.ctx, "runner-live")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "7", id)
+	assert.Equal(s.T(), "7", e.servers["runner-live"], "adopted server is tracked")
+
+	id, err = e.FindRunner(s.ctx, "runner-off")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), id)
+	assert.Equal(s.T(), []int64{8}, s.client.deleted, "server that is off is removed")
+
+	id, err = e.FindRunner(s.ctx, "runner-missing")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), id)
+}
+
+func (s *HCloudEngineSuite) TestListRunners() {
+	e := s.newEngine(Config{})
+	s.client.servers[7] = &server{ID: 7, Name: "runner-a", Labels: engine.RunnerLabels("run-1")}
+
+	runners, err := e.ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []engine.ListedRunner{{
+		ID:     strconv.Itoa(7),
+		Name:   "runner-a",
+		Labels: engine.RunnerLabels("run-1"),
+	}}, runners)
+	assert.Equal(s.T(), []string{"scaleset-managed=true,scaleset-run-id=run-1"}, s.client.selectors)
+}
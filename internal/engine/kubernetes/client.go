@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/terrpan/scaleset/internal/kube"
+)
+
+// podsAPI abstracts the Kubernetes API calls the engine makes so that
+// tests can provide a mock implementation.  restClient satisfies it.
+type podsAPI interface {
+	CreatePod(ctx context.Context, namespace string, p *pod) error
+	GetPod(ctx context.Context, namespace, name string) (*pod, error)
+	ListPods(ctx context.Context, namespace, labelSelector string) ([]pod, error)
+	DeletePod(ctx context.Context, namespace, name string) error
+}
+
+// pod is the subset of the core/v1 Pod object the engine uses.  Only the
+// fields scaleset sets or reads are declared; the API server fills in
+// the rest.
+type pod struct {
+	APIVersion string     `json:"apiVersion,omitempty"`
+	Kind       string     `json:"kind,omitempty"`
+	Metadata   objectMeta `json:"metadata"`
+	Spec       podSpec    `json:"spec"`
+	Status     podStatus  `json:"status"`
+}
+
+type objectMeta struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type podSpec struct {
+	RestartPolicy                string            `json:"restartPolicy,omitempty"`
+	ServiceAccountName           string            `json:"serviceAccountName,omitempty"`
+	AutomountServiceAccountToken *bool             `json:"automountServiceAccountToken,omitempty"`
+	NodeSelector                 map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations                  []toleration      `json:"tolerations,omitempty"`
+	Containers                   []podContainer    `json:"containers"`
+}
+
+type podContainer struct {
+	Name      string               `json:"name"`
+	Image     string               `json:"image"`
+	Command   []string             `json:"command,omitempty"`
+	Env       []envVar             `json:"env,omitempty"`
+	Resources resourceRequirements `json:"resources"`
+}
+
+type envVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+type toleration struct {
+	Key      string `json:"key,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Effect   string `json:"effect,omitempty"`
+}
+
+type podStatus struct {
+	Phase string `json:"phase,omitempty"`
+}
+
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+// Pod phases in which the runner container has exited for good.
+const (
+	podSucceeded = "Succeeded"
+	podFailed    = "Failed"
+)
+
+// restClient implements podsAPI over the API server's REST API.
+type restClient struct {
+	*kube.Client
+}
+
+// Compile-time check.
+var _ podsAPI = (*restClient)(nil)
+
+// isNotFound reports whether err is a 404 from the API server.
+func isNotFound(err error) bool {
+	return kube.IsStatus(err, http.StatusNotFound)
+}
+
+func podsPath(namespace string) string {
+	return "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods"
+}
+
+func (c *restClient) CreatePod(ctx context.Context, namespace string, p *pod) error {
+	return c.Do(ctx, http.MethodPost, podsPath(namespace), nil, p, nil)
+}
+
+func (c *restClient) GetPod(ctx context.Context, namespace, name string) (*pod, error) {
+	var p pod
+	if err := c.Do(ctx, http.MethodGet, podsPath(namespace)+"/"+url.PathEscape(name), nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (c *restClient) ListPods(ctx context.Context, namespace, labelSelector string) ([]pod, error) {
+	var list podList
+	query := url.Values{"labelSelector": {labelSelector}}
+	if err := c.Do(ctx, http.MethodGet, podsPath(namespace), query, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c *restClient) DeletePod(ctx context.Context, namespace, name string) error {
+	return c.Do(ctx, http.MethodDelete, podsPath(namespace)+"/"+url.PathEscape(name), nil, nil, nil)
+}
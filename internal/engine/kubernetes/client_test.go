@@ -0,0 +1,85 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/kube"
+)
+
+// newTestClient returns a restClient for srv that authenticates with a
+// token file containing token.
+func newTestClient(t *testing.T, srv *httptest.Server, token string) *restClient {
+	t.Helper()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte(token+"\n"), 0o600))
+	return &restClient{kube.NewClient(srv.URL, tokenFile, srv.Client())}
+}
+
+func TestRestClient_CreatePod(t *testing.T) {
+	var got pod
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/runners/pods", r.URL.Path)
+		assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, "s3cret")
+	err := c.CreatePod(context.Background(), "runners", &pod{Metadata: objectMeta{Name: "runner-a"}})
+	require.NoError(t, err)
+	assert.Equal(t, "runner-a", got.Metadata.Name)
+}
+
+func TestRestClient_ListPods(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/runners/pods", r.URL.Path)
+		assert.Equal(t, "scaleset-managed=true", r.URL.Query().Get("labelSelector"))
+		_, _ = w.Write([]byte(`{"items":[{"metadata":{"name":"runner-a","labels":{"scaleset-managed":"true"}},"status":{"phase":"Running"}}]}`))
+	}))
+	defer srv.Close()
+
+	pods, err := newTestClient(t, srv, "t").ListPods(context.Background(), "runners", "scaleset-managed=true")
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "runner-a", pods[0].Metadata.Name)
+	assert.Equal(t, "Running", pods[0].Status.Phase)
+}
+
+func TestRestClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/runners/pods/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","message":"pods \"missing\" not found","code":404}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`forbidden`))
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv, "t")
+
+	err := c.DeletePod(context.Background(), "runners", "missing")
+	require.Error(t, err)
+	assert.True(t, isNotFound(err))
+	assert.Contains(t, err.Error(), `pods "missing" not found`)
+
+	_, err = c.GetPod(context.Background(), "runners", "other")
+	require.Error(t, err)
+	assert.False(t, isNotFound(err))
+	assert.Contains(t, err.Error(), "403 Forbidden: forbidden")
+}
@@ -0,0 +1,422 @@
+// Package kubernetes implements the engine.Engine interface by running
+// each ephemeral GitHub Actions runner as a Pod.
+//
+// The engine is meant to run inside the cluster: it talks to the API
+// server with the service account of the pod scaleset runs in, which
+// needs create, get, list and delete on pods in Config.Namespace.  No
+// Docker socket or VM API is involved.
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/kube"
+)
+
+// Config holds Kubernetes-specific engine settings.  The pod template
+// fields (Image, Resources, NodeSelector, Tolerations,
+// ServiceAccountName) apply to every runner pod.
+type Config struct {
+	// Namespace is where runner pods are created.  Default: the
+	// namespace scaleset runs in, else "default".
+	Namespace string
+
+	// Image is the runner container image.
+	// Default: "ghcr.io/actions/actions-runner:latest"
+	Image string
+
+	// Resources are the runner container's resource requests and
+	// limits, e.g. {"cpu": "2", "memory": "4Gi"}.
+	Resources Resources
+
+	// NodeSelector constrains runner pods to nodes with these labels.
+	NodeSelector map[string]string
+
+	// Tolerations let runner pods schedule onto tainted nodes.
+	Tolerations []Toleration
+
+	// ServiceAccountName is the service account runner pods run as.  If
+	// empty, pods use the namespace's default account and no token is
+	// mounted, so jobs get no API access.
+	ServiceAccountName string
+
+	// RunID is recorded on every runner pod with the engine.RunIDLabel
+	// label, next to engine.ManagedLabel, so the pods of a crashed
+	// process can be found by the cleanup command.
+	RunID string
+
+	// Env holds extra environment variables set in every runner
+	// container.  Variables the engine sets itself are never overridden.
+	Env map[string]string
+}
+
+// Resources are container resource requests and limits, keyed by
+// resource name ("cpu", "memory", "nvidia.com/gpu", ...) with quantity
+// strings as values.
+type Resources struct {
+	Requests map[string]string
+	Limits   map[string]string
+}
+
+// Toleration is a pod toleration (see the Kubernetes Toleration type).
+type Toleration struct {
+	Key      string
+	Operator string
+	Value    string
+	Effect   string
+}
+
+// runnerContainer is the name of the container that runs the runner.
+const runnerContainer = "runner"
+
+// Engine manages GitHub Actions runners as Kubernetes pods.
+type Engine struct {
+	client podsAPI
+	cfg    Config
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	pods map[string]string // runner name -> pod name
+
+	// OpenTelemetry instrumentation
+	tracer trace.Tracer
+}
+
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine       = (*Engine)(nil)
+	_ engine.Checker      = (*Engine)(nil)
+	_ engine.RunnerFinder = (*Engine)(nil)
+	_ engine.RunnerLister = (*Engine)(nil)
+)
+
+// New creates a Kubernetes engine that talks to the API server of the
+// cluster it runs in.
+func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Engine, error) {
+	client, err := kube.NewInCluster()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client: %w", err)
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = kube.InClusterNamespace()
+	}
+	return newEngine(&restClient{client}, cfg, logger), nil
+}
+
+// newEngine is the internal constructor used by New and by tests.
+func newEngine(client podsAPI, cfg Config, logger *slog.Logger) *Engine {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "default"
+	}
+	if cfg.Image == "" {
+		cfg.Image = "ghcr.io/actions/actions-runner:latest"
+	}
+
+	logger.Info("kubernetes engine initialized",
+		slog.String("namespace", cfg.Namespace),
+		slog.String("image", cfg.Image),
+	)
+
+	return &Engine{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+		pods:   make(map[string]string),
+		tracer: otel.Tracer("scaleset/engine/kubernetes"),
+	}
+}
+
+// StartRunner creates a pod that runs a GitHub Actions runner with the
+// provided JIT configuration.  It returns once the API server has
+// accepted the pod; scheduling and image pulls happen afterwards.
+func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string) (string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.kubernetes.StartRunner")
+	defer span.End()
+
+	podName := PodName(name)
+	span.SetAttributes(
+		attribute.String("runner.name", name),
+		attribute.String("kubernetes.namespace", e.cfg.Namespace),
+		attribute.String("kubernetes.pod_name", podName),
+	)
+
+	e.logger.Info("creating runner pod",
+		slog.String("name", name),
+		slog.String("pod", podName),
+		slog.String("namespace", e.cfg.Namespace),
+	)
+
+	if err := e.client.CreatePod(ctx, e.cfg.Namespace, e.runnerPod(podName, jitConfig)); err != nil {
+		return "", fmt.Errorf("create pod %s: %w", podName, err)
+	}
+
+	e.mu.Lock()
+	e.pods[name] = podName
+	e.mu.Unlock()
+
+	e.logger.Info("runner pod created",
+		slog.String("name", name),
+		slog.String("pod", podName),
+	)
+
+	// For Kubernetes, the pod name is the opaque ID.
+	return podName, nil
+}
+
+// runnerPod builds the pod for one runner from the configured template.
+// The pod never restarts: the runner is ephemeral, and once its
+// container exits the pod is only waiting to be deleted.
+func (e *Engine) runnerPod(podName, jitConfig string) *pod {
+	env := []envVar{{Name: "ACTIONS_RUNNER_INPUT_JITCONFIG", Value: jitConfig}}
+	keys := make([]string, 0, len(e.cfg.Env))
+	for k := range e.cfg.Env {
+		if k != "ACTIONS_RUNNER_INPUT_JITCONFIG" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, envVar{Name: k, Value: e.cfg.Env[k]})
+	}
+
+	tolerations := make([]toleration, len(e.cfg.Tolerations))
+	for i, t := range e.cfg.Tolerations {
+		tolerations[i] = toleration{Key: t.Key, Operator: t.Operator, Value: t.Value, Effect: t.Effect}
+	}
+
+	automountToken := e.cfg.ServiceAccountName != ""
+	return &pod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata: objectMeta{
+			Name:      podName,
+			Namespace: e.cfg.Namespace,
+			Labels:    engine.RunnerLabels(e.cfg.RunID),
+		},
+		Spec: podSpec{
+			RestartPolicy:                "Never",
+			ServiceAccountName:           e.cfg.ServiceAccountName,
+			AutomountServiceAccountToken: &automountToken,
+			NodeSelector:                 e.cfg.NodeSelector,
+			Tolerations:                  tolerations,
+			Containers: []podContainer{{
+				Name:    runnerContainer,
+				Image:   e.cfg.Image,
+				Command: []string{"/home/runner/run.sh"},
+				Env:     env,
+				Resources: resourceRequirements{
+					Requests: e.cfg.Resources.Requests,
+					Limits:   e.cfg.Resources.Limits,
+				},
+			}},
+		},
+	}
+}
+
+// maxPodNameLength keeps pod names valid DNS labels, which is also the
+// limit for the hostname the pod gets from its name.
+const maxPodNameLength = 63
+
+// PodName returns the pod name for a runner: the runner name lowercased,
+// with characters other than letters, digits and '-' replaced by '-',
+// trimmed to a valid DNS label.
+func PodName(runnerName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, runnerName)
+	if len(name) > maxPodNameLength {
+		name = name[:maxPodNameLength]
+	}
+	return strings.Trim(name, "-")
+}
+
+// DestroyRunner deletes the runner's pod.  Deleting a pod that no
+// longer exists is not an error.
+func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
+	ctx, span := e.tracer.Start(ctx, "engine.kubernetes.DestroyRunner")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("kubernetes.namespace", e.cfg.Namespace),
+		attribute.String("kubernetes.pod_name", id),
+	)
+
+	e.logger.Info("deleting runner pod", slog.String("pod", id))
+
+	if err := e.client.DeletePod(ctx, e.cfg.Namespace, id); err != nil {
+		if !isNotFound(err) {
+			return fmt.Errorf("delete pod %s: %w", id, err)
+		}
+		span.AddEvent("pod already deleted (idempotent)")
+		e.logger.Info("runner pod already deleted", slog.String("pod", id))
+	} else {
+		e.logger.Info("runner pod deleted", slog.String("pod", id))
+	}
+
+	e.removeFromTracking(id)
+	return nil
+}
+
+// Check implements engine.Checker.  It lists the engine's runner pods
+// to confirm the API server is reachable and the service account may
+// read pods in the namespace.
+func (e *Engine) Check(ctx context.Context) (map[string]string, error) {
+	e.mu.Lock()
+	tracked := len(e.pods)
+	e.mu.Unlock()
+
+	diags := map[string]string{
+		"kubernetes.namespace": e.cfg.Namespace,
+		"kubernetes.runners":   strconv.Itoa(tracked),
+	}
+
+	pods, err := e.client.ListPods(ctx, e.cfg.Namespace, labelSelector(engine.RunnerLabels(e.cfg.RunID)))
+	if err != nil {
+		return diags, fmt.Errorf("kubernetes API unreachable: %w", err)
+	}
+	diags["kubernetes.pods"] = strconv.Itoa(len(pods))
+	return diags, nil
+}
+
+// FindRunner implements engine.RunnerFinder.  Pod names are unique per
+// namespace, so the pod for key is looked up directly.  A pod whose
+// runner has already exited cannot serve a job and is deleted so the
+// name can be reused.
+func (e *Engine) FindRunner(ctx context.Context, key string) (string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.kubernetes.FindRunner")
+	defer span.End()
+
+	podName := PodName(key)
+	span.SetAttributes(
+		attribute.String("runner.name", key),
+		attribute.String("kubernetes.pod_name", podName),
+	)
+
+	p, err := e.client.GetPod(ctx, e.cfg.Namespace, podName)
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get pod %s: %w", podName, err)
+	}
+
+	if p.Status.Phase == podSucceeded || p.Status.Phase == podFailed {
+		e.logger.Info("deleting leftover runner pod that has exited",
+			slog.String("name", key),
+			slog.String("pod", podName),
+			slog.String("phase", p.Status.Phase),
+		)
+		if err := e.DestroyRunner(ctx, podName); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+
+	e.mu.Lock()
+	e.pods[key] = podName
+	e.mu.Unlock()
+
+	e.logger.Info("adopted existing runner pod",
+		slog.String("name", key),
+		slog.String("pod", podName),
+	)
+	return podName, nil
+}
+
+// ListRunners implements engine.RunnerLister using a label selector in
+// the engine's namespace.  Exited pods are included so they are cleaned
+// up too.
+func (e *Engine) ListRunners(ctx context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	pods, err := e.client.ListPods(ctx, e.cfg.Namespace, labelSelector(labels))
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	runners := make([]engine.ListedRunner, 0, len(pods))
+	for _, p := range pods {
+		runners = append(runners, engine.ListedRunner{
+			ID:     p.Metadata.Name,
+			Name:   p.Metadata.Name,
+			Labels: p.Metadata.Labels,
+		})
+	}
+	return runners, nil
+}
+
+// labelSelector returns an equality-based selector matching all labels,
+// e.g. "scaleset-managed=true,scaleset-run-id=abc".
+func labelSelector(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Shutdown deletes all pods currently tracked by this engine instance
+// and returns every failure joined.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	ctx, span := e.tracer.Start(ctx, "engine.kubernetes.Shutdown")
+	defer span.End()
+
+	e.mu.Lock()
+	snapshot := make(map[string]string, len(e.pods))
+	for k, v := range e.pods {
+		snapshot[k] = v
+	}
+	e.mu.Unlock()
+
+	span.SetAttributes(attribute.Int("kubernetes.pods_count", len(snapshot)))
+
+	var errs []error
+	for name, id := range snapshot {
+		e.logger.Info("shutdown: deleting runner pod",
+			slog.String("name", name),
+			slog.String("pod", id),
+		)
+		if err := e.DestroyRunner(ctx, id); err != nil {
+			e.logger.Error("shutdown: failed to delete runner pod",
+				slog.String("name", name),
+				slog.String("error", err.Error()),
+			)
+			errs = append(errs, fmt.Errorf("deleting %s: %w", name, err))
+		}
+	}
+
+	e.mu.Lock()
+	clear(e.pods)
+	e.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// removeFromTracking removes a pod from the tracking map.
+func (e *Engine) removeFromTracking(id string) {
+	e.mu.Lock()
+	for name, podName := range e.pods {
+		if podName == id {
+			delete(e.pods, name)
+			break
+		}
+	}
+	e.mu.Unlock()
+}
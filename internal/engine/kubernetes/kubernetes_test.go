@@ -0,0 +1,326 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/kube"
+)
+
+// ---------------------------------------------------------------------------
+// Mock pods client (satisfies podsAPI)
+// ---------------------------------------------------------------------------
+
+type mockPods struct {
+	mu sync.Mutex
+
+	pods      map[string]*pod // namespace/name -> pod
+	created   []*pod
+	deleted   []string
+	selectors []string
+
+	createErr error
+	deleteErr error
+	listErr   error
+}
+
+func newMockPods() *mockPods {
+	return &mockPods{pods: make(map[string]*pod)}
+}
+
+func (m *mockPods) CreatePod(_ context.Context, namespace string, p *pod) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.created = append(m.created, p)
+	m.pods[namespace+"/"+p.Metadata.Name] = p
+	return nil
+}
+
+func (m *mockPods) GetPod(_ context.Context, namespace, name string) (*pod, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.pods[namespace+"/"+name]
+	if !ok {
+		return nil, &kube.StatusError{StatusCode: http.StatusNotFound, Message: "not found"}
+	}
+	return p, nil
+}
+
+func (m *mockPods) ListPods(_ context.Context, _, labelSelector string) ([]pod, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selectors = append(m.selectors, labelSelector)
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var out []pod
+	for _, p := range m.pods {
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+func (m *mockPods) DeletePod(_ context.Context, namespace, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+	if _, ok := m.pods[namespace+"/"+name]; !ok {
+		return &kube.StatusError{StatusCode: http.StatusNotFound, Message: "not found"}
+	}
+	delete(m.pods, namespace+"/"+name)
+	m.deleted = append(m.deleted, name)
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Test suite
+// ---------------------------------------------------------------------------
+
+type KubernetesEngineSuite struct {
+	suite.Suite
+	ctx    context.Context
+	client *mockPods
+	logger *slog.Logger
+}
+
+func TestKubernetesEngineSuite(t *testing.T) {
+	suite.Run(t, new(KubernetesEngineSuite))
+}
+
+func (s *KubernetesEngineSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.client = newMockPods()
+	s.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func (s *KubernetesEngineSuite) newEngine(cfg Config) *Engine {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "runners"
+	}
+	return newEngine(s.client, cfg, s.logger)
+}
+
+// ---------------------------------------------------------------------------
+// StartRunner
+// ---------------------------------------------------------------------------
+
+func (s *KubernetesEngineSuite) TestStartRunner_CreatesPodFromTemplate() {
+	e := s.newEngine(Config{
+		Image: "ghcr.io/actions/actions-runner:2.323.0",
+		Resources: Resources{
+			Requests: map[string]string{"cpu": "1", "memory": "2Gi"},
+			Limits:   map[string]string{"memory": "4Gi"},
+		},
+		NodeSelector: map[string]string{"pool": "ci"},
+		Tolerations:  []Toleration{{Key: "ci", Operator: "Equal", Value: "true", Effect: "NoSchedule"}},
+		RunID:        "run-1",
+		Env:          map[string]string{"B": "2", "A": "1", "ACTIONS_RUNNER_INPUT_JITCONFIG": "override"},
+	})
+
+	id, err := e.StartRunner(s.ctx, "runner-abc", "jit-data")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "runner-abc", id)
+
+	require.Len(s.T(), s.client.created, 1)
+	p := s.client.created[0]
+	assert.Equal(s.T(), "runner-abc", p.Metadata.Name)
+	assert.Equal(s.T(), "runners", p.Metadata.Namespace)
+	assert.Equal(s.T(), engine.RunnerLabels("run-1"), p.Metadata.Labels)
+	assert.Equal(s.T(), "Never", p.Spec.RestartPolicy)
+	assert.Equal(s.T(), map[string]string{"pool": "ci"}, p.Spec.NodeSelector)
+	assert.Equal(s.T(), []toleration{{Key: "ci", Operator: "Equal", Value: "true", Effect: "NoSchedule"}}, p.Spec.Tolerations)
+	require.NotNil(s.T(), p.Spec.AutomountServiceAccountToken)
+	assert.False(s.T(), *p.Spec.AutomountServiceAccountToken, "no token without a service account")
+
+	require.Len(s.T(), p.Spec.Containers, 1)
+	c := p.Spec.Containers[0]
+	assert.Equal(s.T(), "ghcr.io/actions/actions-runner:2.323.0", c.Image)
+	assert.Equal(s.T(), []string{"/home/runner/run.sh"}, c.Command)
+	assert.Equal(s.T(), []envVar{
+		{Name: "ACTIONS_RUNNER_INPUT_JITCONFIG", Value: "jit-data"},
+		{Name: "A", Value: "1"},
+		{Name: "B", Value: "2"},
+	}, c.Env)
+	assert.Equal(s.T(), map[string]string{"cpu": "1", "memory": "2Gi"}, c.Resources.Requests)
+	assert.Equal(s.T(), map[string]string{"memory": "4Gi"}, c.Resources.Limits)
+}
+
+func (s *KubernetesEngineSuite) TestStartRunner_Defaults() {
+	e := newEngine(s.client, Config{}, s.logger)
+
+	_, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.NoError(s.T(), err)
+
+	p := s.client.created[0]
+	assert.Equal(s.T(), "default", p.Metadata.Namespace)
+	assert.Equal(s.T(), "ghcr.io/actions/actions-runner:latest", p.Spec.Containers[0].Image)
+}
+
+func (s *KubernetesEngineSuite) TestStartRunner_ServiceAccountMountsToken() {
+	e := s.newEngine(Config{ServiceAccountName: "runner"})
+
+	_, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.NoError(s.T(), err)
+
+	spec := s.client.created[0].Spec
+	assert.Equal(s.T(), "runner", spec.ServiceAccountName)
+	assert.True(s.T(), *spec.AutomountServiceAccountToken)
+}
+
+func (s *KubernetesEngineSuite) TestStartRunner_CreateError() {
+	s.client.createErr = &kube.StatusError{StatusCode: http.StatusForbidden, Message: "pods is forbidden"}
+	e := s.newEngine(Config{})
+
+	_, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "pods is forbidden")
+	assert.Empty(s.T(), e.pods)
+}
+
+func (s *KubernetesEngineSuite) TestPodName() {
+	cases := map[string]string{
+		"runner-1a2b3c4d":                   "runner-1a2b3c4d",
+		"Runner_Build.42":                   "runner-build-42",
+		"-edge-":                            "edge",
+		"runner-" + strings.Repeat("a", 80): "runner-" + strings.Repeat("a", 56),
+	}
+	for in, want := range cases {
+		got := PodName(in)
+		assert.Equal(s.T(), want, got, "PodName(%q)", in)
+		assert.LessOrEqual(s.T(), len(got), maxPodNameLength)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// DestroyRunner / Shutdown
+// ---------------------------------------------------------------------------
+
+func (s *KubernetesEngineSuite) TestDestroyRunner_DeletesPod() {
+	e := s.newEngine(Config{})
+	id, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), e.DestroyRunner(s.ctx, id))
+	assert.Equal(s.T(), []string{"runner-abc"}, s.client.deleted)
+	assert.Empty(s.T(), e.pods)
+}
+
+func (s *KubernetesEngineSuite) TestDestroyRunner_Idempotent() {
+	e := s.newEngine(Config{})
+	assert.NoError(s.T(), e.DestroyRunner(s.ctx, "already-gone"))
+}
+
+func (s *KubernetesEngineSuite) TestDestroyRunner_Error() {
+	e := s.newEngine(Config{})
+	s.client.deleteErr = errors.New("connection refused")
+
+	err := e.DestroyRunner(s.ctx, "runner-abc")
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "connection refused")
+}
+
+func (s *KubernetesEngineSuite) TestShutdown_DeletesTrackedPods() {
+	e := s.newEngine(Config{})
+	for _, name := range []string{"runner-a", "runner-b"} {
+		_, err := e.StartRunner(s.ctx, name, "jit")
+		require.NoError(s.T(), err)
+	}
+
+	require.NoError(s.T(), e.Shutdown(s.ctx))
+	assert.ElementsMatch(s.T(), []string{"runner-a", "runner-b"}, s.client.deleted)
+	assert.Empty(s.T(), e.pods)
+}
+
+func (s *KubernetesEngineSuite) TestShutdown_JoinsErrors() {
+	e := s.newEngine(Config{})
+	for _, name := range []string{"runner-a", "runner-b"} {
+		_, err := e.StartRunner(s.ctx, name, "jit")
+		require.NoError(s.T(), err)
+	}
+	s.client.deleteErr = errors.New("connection refused")
+
+	err := e.Shutdown(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "deleting runner-a")
+	assert.Contains(s.T(), err.Error(), "deleting runner-b")
+}
+
+// ---------------------------------------------------------------------------
+// Optional interfaces
+// ---------------------------------------------------------------------------
+
+func (s *KubernetesEngineSuite) TestCheck() {
+	e := s.newEngine(Config{RunID: "run-1"})
+	_, err := e.StartRunner(s.ctx, "runner-abc", "jit")
+	require.NoError(s.T(), err)
+
+	diags, err := e.Check(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "runners", diags["kubernetes.namespace"])
+	assert.Equal(s.T(), "1", diags["kubernetes.runners"])
+	assert.Equal(s.T(), "1", diags["kubernetes.pods"])
+	assert.Equal(s.T(), []string{"scaleset-managed=true,scaleset-run-id=run-1"}, s.client.selectors)
+
+	s.client.listErr = &kube.StatusError{StatusCode: http.StatusForbidden, Message: "pods is forbidden"}
+	_, err = e.Check(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "pods is forbidden")
+}
+
+func (s *KubernetesEngineSuite) TestFindRunner() {
+	e := s.newEngine(Config{})
+	s.client.pods["runners/runner-live"] = &pod{
+		Metadata: objectMeta{Name: "runner-live"},
+		Status:   podStatus{Phase: "Running"},
+	}
+	s.client.pods["runners/runner-done"] = &pod{
+		Metadata: objectMeta{Name: "runner-done"},
+		Status:   podStatus{Phase: podFailed},
+	}
+
+	id, err := e.FindRunner(s.ctx, "runner-live")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "runner-live", id)
+	assert.Equal(s.T(), "runner-live", e.pods["runner-live"], "adopted pod is tracked")
+
+	id, err = e.FindRunner(s.ctx, "runner-done")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), id)
+	assert.Equal(s.T(), []string{"runner-done"}, s.client.deleted, "exited pod is removed")
+
+	id, err = e.FindRunner(s.ctx, "runner-missing")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), id)
+}
+
+func (s *KubernetesEngineSuite) TestListRunners() {
+	e := s.newEngine(Config{})
+	s.client.pods["runners/runner-a"] = &pod{
+		Metadata: objectMeta{Name: "runner-a", Labels: engine.RunnerLabels("run-1")},
+	}
+
+	runners, err := e.ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []engine.ListedRunner{{
+		ID:     "runner-a",
+		Name:   "runner-a",
+		Labels: engine.RunnerLabels("run-1"),
+	}}, runners)
+	assert.Equal(s.T(), []string{"scaleset-managed=true,scaleset-run-id=run-1"}, s.client.selectors)
+}
@@ -0,0 +1,342 @@
+// Package plugin implements the engine.Engine interface with an
+// out-of-tree engine: a standalone binary that scaleset starts as a
+// child process and talks to over gRPC on a unix socket.  The protocol
+// and the library plugins are built with live in the public package
+// github.com/terrpan/scaleset/plugin.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	pluginsdk "github.com/terrpan/scaleset/plugin"
+)
+
+// Config holds plugin engine settings.
+type Config struct {
+	// Path is the plugin binary.
+	Path string
+
+	// Args are passed to the plugin binary.
+	Args []string
+
+	// Env names the variables of scaleset's environment that are passed
+	// through to the plugin.  The plugin otherwise only gets PATH, HOME
+	// and the plugin protocol's variables, so that scaleset's own
+	// secrets (SCALESET_*) do not reach it.
+	Env []string
+
+	// Config is passed to the plugin as is.  Its values must be
+	// representable in JSON.
+	Config map[string]any
+
+	// RunID is sent to the plugin, together with the engine.RunnerLabels
+	// it should set on what it creates.
+	RunID string
+
+	// StartTimeout bounds how long the plugin may take to listen on its
+	// socket and configure its engine.  Default: 30s.
+	StartTimeout time.Duration
+}
+
+// Default timeouts.
+const (
+	defaultStartTimeout = 30 * time.Second
+	// stopTimeout is how long the plugin gets to exit after Shutdown
+	// before it is killed.
+	stopTimeout = 10 * time.Second
+	// socketPollInterval is how often New looks for the plugin's socket.
+	socketPollInterval = 50 * time.Millisecond
+)
+
+// Engine forwards the engine contract to a plugin process.
+type Engine struct {
+	client *pluginsdk.Client
+	conn   *grpc.ClientConn
+	cmd    *exec.Cmd
+	dir    string // holds the socket
+	path   string
+	logger *slog.Logger
+
+	exited  chan struct{} // closed when the process has exited
+	waitErr error         // set before exited is closed
+
+	stopOnce sync.Once
+
+	// OpenTelemetry instrumentation
+	tracer trace.Tracer
+}
+
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine  = (*Engine)(nil)
+	_ engine.Checker = (*Engine)(nil)
+)
+
+// New starts the plugin binary, waits for it to listen and configures
+// its engine.  The plugin's output is logged line by line.
+func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Engine, error) {
+	if cfg.StartTimeout == 0 {
+		cfg.StartTimeout = defaultStartTimeout
+	}
+	dir, err := os.MkdirTemp("", "scaleset-plugin-")
+	if err != nil {
+		return nil, fmt.Errorf("plugin socket directory: %w", err)
+	}
+	socket := filepath.Join(dir, "plugin.sock")
+
+	out := &lineLogger{logger: logger.With(slog.String("plugin", filepath.Base(cfg.Path)))}
+	cmd := exec.Command(cfg.Path, cfg.Args...)
+	cmd.Env = pluginEnv(cfg.Env, socket)
+	cmd.Stdout, cmd.Stderr = out, out
+	if err := cmd.Start(); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("starting plugin %s: %w", cfg.Path, err)
+	}
+
+	e := &Engine{
+		cmd:    cmd,
+		dir:    dir,
+		path:   cfg.Path,
+		logger: logger,
+		exited: make(chan struct{}),
+		tracer: otel.Tracer("scaleset/engine/plugin"),
+	}
+	go func() {
+		e.waitErr = cmd.Wait()
+		out.flush()
+		close(e.exited)
+	}()
+
+	if err := e.connect(ctx, cfg, socket); err != nil {
+		e.stop()
+		return nil, err
+	}
+	logger.Info("plugin started",
+		slog.String("path", cfg.Path),
+		slog.Int("pid", cmd.Process.Pid),
+	)
+	return e, nil
+}
+
+// baseEnv are the variables of scaleset's environment every plugin gets.
+var baseEnv = []string{"PATH", "HOME"}
+
+// pluginEnv returns the plugin's environment: baseEnv and passthrough,
+// where set in scaleset's environment, and the protocol's variables.
+func pluginEnv(passthrough []string, socket string) []string {
+	var env []string
+	for _, name := range slices.Concat(baseEnv, passthrough) {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return append(env,
+		pluginsdk.SocketEnv+"="+socket,
+		pluginsdk.ProtocolEnv+"="+strconv.Itoa(pluginsdk.ProtocolVersion),
+	)
+}
+
+// connect waits for the plugin's socket, dials it and configures the
+// plugin's engine.
+func (e *Engine) connect(ctx context.Context, cfg Config, socket string) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.StartTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(socketPollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		select {
+		case <-e.exited:
+			return fmt.Errorf("plugin %s exited during startup: %v", cfg.Path, e.waitErr)
+		case <-ctx.Done():
+			return fmt.Errorf("plugin %s did not listen within %s", cfg.Path, cfg.StartTimeout)
+		case <-ticker.C:
+		}
+	}
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", cfg.Path, err)
+	}
+	e.conn = conn
+	e.client = pluginsdk.NewClient(conn)
+
+	err = e.client.Configure(ctx, pluginsdk.Settings{
+		Config: cfg.Config,
+		RunID:  cfg.RunID,
+		Labels: engine.RunnerLabels(cfg.RunID),
+	})
+	if err != nil {
+		return fmt.Errorf("configuring plugin %s: %w", cfg.Path, fromStatus(err))
+	}
+	return nil
+}
+
+// StartRunner asks the plugin to start a runner.
+func (e *Engine) StartRunner(ctx context.Context, name string, jitConfig string) (string, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.plugin.StartRunner")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("runner.name", name),
+		attribute.String("plugin.path", e.path),
+	)
+
+	id, err := e.client.StartRunner(ctx, name, jitConfig)
+	if err != nil {
+		return "", fmt.Errorf("plugin start %s: %w", name, fromStatus(err))
+	}
+	span.SetAttributes(attribute.String("plugin.runner_id", id))
+	e.logger.Info("runner started",
+		slog.String("name", name),
+		slog.String("id", id),
+	)
+	return id, nil
+}
+
+// DestroyRunner asks the plugin to destroy a runner.
+func (e *Engine) DestroyRunner(ctx context.Context, id string) error {
+	ctx, span := e.tracer.Start(ctx, "engine.plugin.DestroyRunner")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("plugin.runner_id", id))
+
+	e.logger.Info("destroying runner", slog.String("id", id))
+	if err := e.client.DestroyRunner(ctx, id); err != nil {
+		return fmt.Errorf("plugin destroy %s: %w", id, fromStatus(err))
+	}
+	return nil
+}
+
+// Shutdown asks the plugin to destroy its runners, then stops the
+// plugin process.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	ctx, span := e.tracer.Start(ctx, "engine.plugin.Shutdown")
+	defer span.End()
+
+	var err error
+	select {
+	case <-e.exited:
+		err = fmt.Errorf("plugin %s exited: %v", e.path, e.waitErr)
+	default:
+		if rpcErr := e.client.Shutdown(ctx); rpcErr != nil {
+			err = fmt.Errorf("plugin shutdown: %w", fromStatus(rpcErr))
+		}
+	}
+	e.stop()
+	return err
+}
+
+// Check implements engine.Checker.  It fails when the plugin process has
+// exited and otherwise reports the plugin engine's own diagnostics.
+func (e *Engine) Check(ctx context.Context) (map[string]string, error) {
+	diags := map[string]string{"plugin.path": e.path}
+	select {
+	case <-e.exited:
+		return diags, fmt.Errorf("plugin exited: %v", e.waitErr)
+	default:
+	}
+	d, err := e.client.Check(ctx)
+	for k, v := range d {
+		diags[k] = v
+	}
+	if err != nil {
+		return diags, fmt.Errorf("plugin unreachable: %w", fromStatus(err))
+	}
+	return diags, nil
+}
+
+// stop closes the connection, sends the process SIGTERM (a plugin that
+// answered Shutdown is exiting anyway), waits up to stopTimeout for it
+// to exit, kills it if it does not, and removes the socket directory.
+func (e *Engine) stop() {
+	e.stopOnce.Do(func() {
+		if e.conn != nil {
+			_ = e.conn.Close()
+		}
+		_ = e.cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-e.exited:
+		case <-time.After(stopTimeout):
+			e.logger.Warn("plugin did not exit, killing it", slog.String("path", e.path))
+			_ = e.cmd.Process.Kill()
+			<-e.exited
+		}
+		_ = os.RemoveAll(e.dir)
+	})
+}
+
+// fromStatus turns a gRPC status error into a plain error with the
+// plugin's message, keeping deadline and cancellation recognisable.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%s: %w", st.Message(), context.DeadlineExceeded)
+	case codes.Canceled:
+		return fmt.Errorf("%s: %w", st.Message(), context.Canceled)
+	case codes.Unknown:
+		return errors.New(st.Message())
+	}
+	return fmt.Errorf("%s (%s)", st.Message(), st.Code())
+}
+
+// lineLogger logs what the plugin writes, one entry per line.
+type lineLogger struct {
+	logger *slog.Logger
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.logger.Info("plugin output", slog.String("line", string(l.buf[:i])))
+		l.buf = l.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush logs a last line without a newline.
+func (l *lineLogger) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.buf) > 0 {
+		l.logger.Info("plugin output", slog.String("line", string(l.buf)))
+		l.buf = nil
+	}
+}
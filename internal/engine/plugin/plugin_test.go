@@ -0,0 +1,188 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	pluginsdk "github.com/terrpan/scaleset/plugin"
+)
+
+// helperEngine is the engine of the helper plugin.
+type helperEngine struct {
+	settings pluginsdk.Settings
+}
+
+func (h *helperEngine) StartRunner(_ context.Context, name, _ string) (string, error) {
+	if h.settings.Config["fail_start"] == true {
+		return "", errors.New("quota exceeded")
+	}
+	return "vm-" + name, nil
+}
+
+func (h *helperEngine) DestroyRunner(context.Context, string) error { return nil }
+
+func (h *helperEngine) Shutdown(context.Context) error { return nil }
+
+func (h *helperEngine) Check(context.Context) (map[string]string, error) {
+	return map[string]string{
+		"helper.run_id": h.settings.RunID,
+		"helper.secret": os.Getenv("SCALESET_TEST_SECRET"),
+		"helper.passed": os.Getenv("PLUGIN_TEST_PASSED"),
+	}, nil
+}
+
+// TestHelperPlugin is not a test: it is the plugin binary the tests
+// start, by running the test binary with -test.run=^TestHelperPlugin$.
+// Arguments after "--" select misbehaviour.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(pluginsdk.SocketEnv) == "" {
+		return
+	}
+	if slices.Contains(flag.Args(), "exit") {
+		fmt.Fprintln(os.Stderr, "helper: giving up")
+		os.Exit(3)
+	}
+	err := pluginsdk.Serve(func(_ context.Context, s pluginsdk.Settings) (pluginsdk.Engine, error) {
+		if s.Config["fail_configure"] == true {
+			return nil, errors.New("config.region is required")
+		}
+		return &helperEngine{settings: s}, nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+type PluginEngineSuite struct {
+	suite.Suite
+	logger *slog.Logger
+}
+
+func TestPluginEngineSuite(t *testing.T) {
+	suite.Run(t, new(PluginEngineSuite))
+}
+
+func (s *PluginEngineSuite) SetupTest() {
+	s.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func (s *PluginEngineSuite) helperConfig(config map[string]any, args ...string) Config {
+	return Config{
+		Path:         os.Args[0],
+		Args:         append([]string{"-test.run=^TestHelperPlugin$", "--"}, args...),
+		Config:       config,
+		RunID:        "run-1",
+		StartTimeout: 10 * time.Second,
+	}
+}
+
+func (s *PluginEngineSuite) TestLifecycle() {
+	ctx := s.T().Context()
+	e, err := New(ctx, s.helperConfig(nil), s.logger)
+	require.NoError(s.T(), err)
+
+	id, err := e.StartRunner(ctx, "runner-a", "jit")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "vm-runner-a", id)
+	require.NoError(s.T(), e.DestroyRunner(ctx, id))
+
+	diags, err := e.Check(ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "run-1", diags["helper.run_id"])
+	assert.Equal(s.T(), os.Args[0], diags["plugin.path"])
+
+	require.NoError(s.T(), e.Shutdown(ctx))
+	select {
+	case <-e.exited:
+		assert.NoError(s.T(), e.waitErr, "plugin exits cleanly after Shutdown")
+	default:
+		s.T().Fatal("plugin still running after Shutdown")
+	}
+	_, err = os.Stat(e.dir)
+	assert.True(s.T(), os.IsNotExist(err), "socket directory is removed")
+
+	_, err = e.Check(ctx)
+	assert.ErrorContains(s.T(), err, "plugin exited")
+}
+
+func (s *PluginEngineSuite) TestEnvironment() {
+	s.T().Setenv("SCALESET_TEST_SECRET", "hunter2")
+	s.T().Setenv("PLUGIN_TEST_PASSED", "yes")
+	ctx := s.T().Context()
+	cfg := s.helperConfig(nil)
+	cfg.Env = []string{"PLUGIN_TEST_PASSED", "PLUGIN_TEST_UNSET"}
+	e, err := New(ctx, cfg, s.logger)
+	require.NoError(s.T(), err)
+	defer func() { _ = e.Shutdown(ctx) }()
+
+	diags, err := e.Check(ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), diags["helper.secret"], "scaleset's environment is not inherited")
+	assert.Equal(s.T(), "yes", diags["helper.passed"], "listed variables are passed through")
+}
+
+func (s *PluginEngineSuite) TestStartError() {
+	ctx := s.T().Context()
+	e, err := New(ctx, s.helperConfig(map[string]any{"fail_start": true}), s.logger)
+	require.NoError(s.T(), err)
+	defer e.Shutdown(ctx)
+
+	_, err = e.StartRunner(ctx, "runner-a", "jit")
+	require.EqualError(s.T(), err, "plugin start runner-a: quota exceeded")
+}
+
+func (s *PluginEngineSuite) TestConfigureError() {
+	_, err := New(s.T().Context(), s.helperConfig(map[string]any{"fail_configure": true}), s.logger)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "config.region is required")
+}
+
+func (s *PluginEngineSuite) TestExitDuringStartup() {
+	_, err := New(s.T().Context(), s.helperConfig(nil, "exit"), s.logger)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "exited during startup")
+}
+
+func (s *PluginEngineSuite) TestMissingBinary() {
+	cfg := s.helperConfig(nil)
+	cfg.Path = "/nonexistent/scaleset-plugin"
+	_, err := New(s.T().Context(), cfg, s.logger)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "starting plugin")
+}
+
+func TestLineLogger(t *testing.T) {
+	var lines []string
+	l := &lineLogger{logger: slog.New(handlerFunc(func(r slog.Record) {
+		r.Attrs(func(a slog.Attr) bool {
+			lines = append(lines, a.Value.String())
+			return true
+		})
+	}))}
+	_, _ = l.Write([]byte("first\nsec"))
+	_, _ = l.Write([]byte("ond\nthird"))
+	l.flush()
+	assert.Equal(t, []string{"first", "second", "third"}, lines)
+}
+
+// handlerFunc is a slog.Handler that passes records to a function.
+type handlerFunc func(slog.Record)
+
+func (h handlerFunc) Enabled(context.Context, slog.Level) bool      { return true }
+func (h handlerFunc) Handle(_ context.Context, r slog.Record) error { h(r); return nil }
+func (h handlerFunc) WithAttrs([]slog.Attr) slog.Handler            { return h }
+func (h handlerFunc) WithGroup(string) slog.Handler                 { return h }
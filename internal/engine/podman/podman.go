@@ -0,0 +1,203 @@
+// Package podman implements the engine.Engine interface on Podman, which
+// runs ephemeral GitHub Actions runners as containers through the
+// Docker-compatible API of its REST socket.  Container handling is the
+// docker engine's; this package adds what rootless Podman needs: the
+// per-user socket, a user namespace mode, and a check that resource
+// limits can be enforced.
+package podman
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/system"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/engine/docker"
+)
+
+// Config holds Podman-specific settings.
+type Config struct {
+	// Image is the container image to use for runners.
+	// Default: "ghcr.io/actions/actions-runner:latest"
+	Image string
+
+	// Socket is the address of the Podman REST socket, e.g.
+	// "unix:///run/user/1000/podman/podman.sock".  Default:
+	// DefaultSocket().
+	Socket string
+
+	// UserNS is the user namespace mode of runner containers: "keep-id",
+	// "auto", "nomap" or "host", optionally with options such as
+	// "keep-id:uid=1001".  Empty uses Podman's default, which in rootless
+	// mode maps container root to the user running Podman.
+	UserNS string
+
+	// Init runs an init process as PID 1 in each runner container.
+	Init bool
+
+	// StopTimeout is the grace period given to a runner container to
+	// exit after SIGTERM before it is force-removed.
+	StopTimeout time.Duration
+
+	// RunID is recorded on every runner container, see docker.Config.
+	RunID string
+
+	// ScaleSetName is recorded on every runner container, see
+	// docker.Config.
+	ScaleSetName string
+
+	// Env holds extra environment variables set in every runner
+	// container.
+	Env map[string]string
+
+	// PinDigest creates every runner container from the digest the
+	// runner image resolved to at startup.
+	PinDigest bool
+
+	// Limits caps the resources of each runner container.  Rootless
+	// Podman enforces them only on cgroup v2 hosts where the user's
+	// systemd slice has the cpu, memory and pids controllers delegated.
+	Limits docker.Limits
+}
+
+// dockerConfig returns the docker engine settings of cfg.
+func (cfg Config) dockerConfig() docker.Config {
+	socket := cfg.Socket
+	if socket == "" {
+		socket = DefaultSocket()
+	}
+	return docker.Config{
+		Image:        cfg.Image,
+		Host:         socket,
+		Init:         cfg.Init,
+		StopTimeout:  cfg.StopTimeout,
+		RunID:        cfg.RunID,
+		Env:          cfg.Env,
+		ScaleSetName: cfg.ScaleSetName,
+		PinDigest:    cfg.PinDigest,
+		Limits:       cfg.Limits,
+		UsernsMode:   cfg.UserNS,
+	}
+}
+
+// DefaultSocket returns the address of the Podman socket of the current
+// user: CONTAINER_HOST if set, /run/podman/podman.sock for root, and
+// podman/podman.sock under XDG_RUNTIME_DIR (or /run/user/<uid>)
+// otherwise.  These are where "systemctl [--user] enable --now
+// podman.socket" creates it.
+func DefaultSocket() string {
+	return socketAddress(os.Getenv("CONTAINER_HOST"), os.Getenv("XDG_RUNTIME_DIR"), os.Getuid())
+}
+
+func socketAddress(containerHost, runtimeDir string, uid int) string {
+	if containerHost != "" {
+		return containerHost
+	}
+	if uid == 0 {
+		return "unix:///run/podman/podman.sock"
+	}
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", strconv.Itoa(uid))
+	}
+	return "unix://" + filepath.Join(runtimeDir, "podman", "podman.sock")
+}
+
+// userNSModes are the user namespace modes Podman accepts.
+var userNSModes = []string{"auto", "container", "host", "keep-id", "nomap", "ns", "private"}
+
+// ValidUserNS reports whether mode is a user namespace mode Podman
+// accepts.  Empty is valid and means Podman's default.
+func ValidUserNS(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	name, _, _ := strings.Cut(mode, ":")
+	return slices.Contains(userNSModes, name)
+}
+
+// Engine manages GitHub Actions runners as Podman containers.
+type Engine struct {
+	*docker.Engine
+
+	rootless      bool
+	cgroupVersion string
+}
+
+// Compile-time checks that Engine satisfies the engine interfaces.
+var (
+	_ engine.Engine       = (*Engine)(nil)
+	_ engine.Checker      = (*Engine)(nil)
+	_ engine.RunnerFinder = (*Engine)(nil)
+	_ engine.RunnerLister = (*Engine)(nil)
+)
+
+// New creates a Podman engine, connects to the socket and pulls the
+// runner image.  It fails when resource limits are set but rootless
+// Podman cannot enforce them (cgroup v1), since the limits would be
+// silently ignored.
+func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Engine, error) {
+	eng, err := docker.New(ctx, cfg.dockerConfig(), logger)
+	if err != nil {
+		return nil, err
+	}
+	info, err := eng.Info(ctx)
+	if err != nil {
+		_ = eng.Shutdown(ctx)
+		return nil, fmt.Errorf("podman info: %w", err)
+	}
+	e := &Engine{
+		Engine:        eng,
+		rootless:      isRootless(info),
+		cgroupVersion: info.CgroupVersion,
+	}
+	if err := e.checkLimits(cfg.Limits); err != nil {
+		_ = eng.Shutdown(ctx)
+		return nil, err
+	}
+	logger.Info("podman engine ready",
+		slog.String("version", info.ServerVersion),
+		slog.Bool("rootless", e.rootless),
+		slog.String("cgroup_version", e.cgroupVersion),
+	)
+	return e, nil
+}
+
+// Preflight checks that the engine New would create from cfg can work,
+// without pulling images or creating containers.
+func Preflight(ctx context.Context, cfg Config) (map[string]string, error) {
+	return docker.Preflight(ctx, cfg.dockerConfig())
+}
+
+// isRootless reports whether the daemon runs without root, which Podman
+// (like rootless Docker) reports as a security option.
+func isRootless(info system.Info) bool {
+	return slices.Contains(info.SecurityOptions, "name=rootless")
+}
+
+// checkLimits returns an error if cgroup limits are set but cannot be
+// enforced: rootless containers can only be limited through cgroup v2.
+// The /dev/shm size is a mount option and always applies.
+func (e *Engine) checkLimits(limits docker.Limits) error {
+	cgroupLimits := limits.CPUs > 0 || limits.Memory > 0 || limits.PidsLimit > 0
+	if cgroupLimits && e.rootless && e.cgroupVersion == "1" {
+		return fmt.Errorf("rootless podman cannot enforce cpus, memory or pids_limit on cgroup v1; they need cgroup v2")
+	}
+	return nil
+}
+
+// Check implements engine.Checker.  It adds whether Podman runs rootless
+// and its cgroup version to the docker engine's diagnostics.
+func (e *Engine) Check(ctx context.Context) (map[string]string, error) {
+	diags, err := e.Engine.Check(ctx)
+	diags["podman.rootless"] = strconv.FormatBool(e.rootless)
+	diags["podman.cgroup_version"] = e.cgroupVersion
+	return diags, err
+}
@@ -0,0 +1,77 @@
+package podman
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/system"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terrpan/scaleset/internal/engine/docker"
+)
+
+func TestSocketAddress(t *testing.T) {
+	tests := []struct {
+		name          string
+		containerHost string
+		runtimeDir    string
+		uid           int
+		want          string
+	}{
+		{"container host wins", "unix:///tmp/podman.sock", "/run/user/1000", 1000, "unix:///tmp/podman.sock"},
+		{"root", "", "", 0, "unix:///run/podman/podman.sock"},
+		{"rootless runtime dir", "", "/run/user/1000", 1000, "unix:///run/user/1000/podman/podman.sock"},
+		{"rootless without runtime dir", "", "", 1001, "unix:///run/user/1001/podman/podman.sock"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, socketAddress(tt.containerHost, tt.runtimeDir, tt.uid))
+		})
+	}
+}
+
+func TestValidUserNS(t *testing.T) {
+	for _, mode := range []string{"", "keep-id", "keep-id:uid=1001,gid=1001", "auto", "auto:size=65536", "nomap", "host"} {
+		assert.True(t, ValidUserNS(mode), mode)
+	}
+	for _, mode := range []string{"keepid", "root", ":keep-id"} {
+		assert.False(t, ValidUserNS(mode), mode)
+	}
+}
+
+func TestDockerConfig(t *testing.T) {
+	t.Setenv("CONTAINER_HOST", "unix:///tmp/podman.sock")
+
+	cfg := Config{UserNS: "keep-id", RunID: "run-1"}.dockerConfig()
+	assert.Equal(t, "unix:///tmp/podman.sock", cfg.Host)
+	assert.Equal(t, "keep-id", cfg.UsernsMode)
+	assert.False(t, cfg.Dind, "runners never get the host socket")
+
+	cfg = Config{Socket: "unix:///run/user/1000/podman/podman.sock"}.dockerConfig()
+	assert.Equal(t, "unix:///run/user/1000/podman/podman.sock", cfg.Host)
+}
+
+func TestCheckLimits(t *testing.T) {
+	limits := docker.Limits{Memory: 4 << 30}
+	tests := []struct {
+		name    string
+		info    system.Info
+		limits  docker.Limits
+		wantErr bool
+	}{
+		{"rootless cgroup v2", system.Info{SecurityOptions: []string{"name=rootless"}, CgroupVersion: "2"}, limits, false},
+		{"rootless cgroup v1", system.Info{SecurityOptions: []string{"name=rootless"}, CgroupVersion: "1"}, limits, true},
+		{"rootless cgroup v1 shm only", system.Info{SecurityOptions: []string{"name=rootless"}, CgroupVersion: "1"}, docker.Limits{ShmSize: 1 << 30}, false},
+		{"rootful cgroup v1", system.Info{SecurityOptions: []string{"name=seccomp"}, CgroupVersion: "1"}, limits, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{rootless: isRootless(tt.info), cgroupVersion: tt.info.CgroupVersion}
+			err := e.checkLimits(tt.limits)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "cgroup v2")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
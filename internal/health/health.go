@@ -1,10 +1,14 @@
-// Package health provides HTTP handlers for health checks.
+// Package health provides HTTP handlers for liveness and readiness checks
+// and the drain control endpoint.
 package health
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"runtime"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/terrpan/scaleset/internal/buildinfo"
@@ -12,22 +16,24 @@ import (
 
 // Response represents the health check response body.
 type Response struct {
-	Status       string    `json:"status"`
-	ServiceName  string    `json:"service_name"`
-	Version      string    `json:"version"`
-	Commit       string    `json:"commit"`
-	BuildTime    string    `json:"build_time"`
-	GoVersion    string    `json:"go_version"`
-	OS           string    `json:"os"`
-	Architecture string    `json:"architecture"`
-	Engine       string    `json:"engine"`
-	Timestamp    time.Time `json:"timestamp"`
-}
-
-// Handler responds to health check requests. It reports build info and the
-// enabled compute engine. The status is always "healthy" (200 OK) since this
-// is a liveness check with no external dependencies to verify.
-func Handler(engine string) http.HandlerFunc {
+	Status       string            `json:"status"`
+	ServiceName  string            `json:"service_name"`
+	Version      string            `json:"version"`
+	Commit       string            `json:"commit"`
+	BuildTime    string            `json:"build_time"`
+	GoVersion    string            `json:"go_version"`
+	OS           string            `json:"os"`
+	Architecture string            `json:"architecture"`
+	Engine       string            `json:"engine"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Timestamp    time.Time         `json:"timestamp"`
+}
+
+// Handler responds to health check requests. It reports build info, the
+// enabled compute engine and any operator-supplied static labels. The status
+// is always "healthy" (200 OK) since this is a liveness check with no
+// external dependencies to verify.
+func Handler(engine string, labels map[string]string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -42,9 +48,229 @@ func Handler(engine string) http.HandlerFunc {
 			OS:           runtime.GOOS,
 			Architecture: runtime.GOARCH,
 			Engine:       engine,
+			Labels:       labels,
 			Timestamp:    time.Now().UTC(),
 		}
 
 		_ = json.NewEncoder(w).Encode(response)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Readiness
+// ---------------------------------------------------------------------------
+
+// Checker reports the health of a backend dependency.  Check returns a
+// set of non-secret diagnostics (e.g. daemon version, quota headroom)
+// and a non-nil error when the dependency is unhealthy.  Engines that
+// implement engine.Checker satisfy this interface implicitly.
+type Checker interface {
+	Check(ctx context.Context) (map[string]string, error)
+}
+
+// Bounds applied to diagnostics so a misbehaving checker cannot blow up
+// the readiness payload.
+const (
+	maxDiagnostics      = 32
+	maxDiagnosticLength = 256
+	checkTimeout        = 5 * time.Second
+)
+
+// ReadyResponse represents the readiness check response body.
+type ReadyResponse struct {
+	Status      string            `json:"status"`
+	Engine      string            `json:"engine"`
+	Error       string            `json:"error,omitempty"`
+	Diagnostics map[string]string `json:"diagnostics,omitempty"`
+	Timestamp   time.Time         `json:"timestamp"`
+}
+
+// Readiness serves the /readyz endpoint.  It reports "starting" (503)
+// until MarkReady is called, then runs the installed Checker (if any) on
+// every request and reports "degraded" (503) when it fails, e.g. because
+// the engine's backend or the GitHub API is unreachable.  Liveness
+// (Handler) is unaffected, so a degraded process is taken out of service
+// but not restarted.
+type Readiness struct {
+	engine      string
+	diagnostics bool
+
+	mu      sync.RWMutex
+	ready   bool
+	checker Checker
+}
+
+// NewReadiness creates a Readiness for the named engine.  When
+// includeDiagnostics is false the checker still gates readiness but its
+// diagnostics are omitted from the response.
+func NewReadiness(engine string, includeDiagnostics bool) *Readiness {
+	return &Readiness{engine: engine, diagnostics: includeDiagnostics}
+}
+
+// MarkReady marks the process ready and installs the checker consulted
+// on each request.  checker may be nil when there is nothing to check.
+func (r *Readiness) MarkReady(checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ready = true
+	r.checker = checker
+}
+
+// Handler responds to readiness requests.
+func (r *Readiness) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		ready, checker := r.ready, r.checker
+		r.mu.RUnlock()
+
+		response := ReadyResponse{
+			Status:    "ready",
+			Engine:    r.engine,
+			Timestamp: time.Now().UTC(),
+		}
+		code := http.StatusOK
+
+		switch {
+		case !ready:
+			response.Status = "starting"
+			code = http.StatusServiceUnavailable
+		case checker != nil:
+			ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+			diags, err := checker.Check(ctx)
+			cancel()
+			if err != nil {
+				response.Status = "degraded"
+				response.Error = truncate(err.Error())
+				code = http.StatusServiceUnavailable
+			}
+			if r.diagnostics {
+				response.Diagnostics = boundDiagnostics(diags)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
+// boundDiagnostics keeps at most maxDiagnostics entries (by sorted key)
+// and truncates long values.
+func boundDiagnostics(in map[string]string) map[string]string {
+	if len(in) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) > maxDiagnostics {
+		keys = keys[:maxDiagnostics]
+	}
+
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		out[k] = truncate(in[k])
+	}
+	return out
+}
+
+func truncate(s string) string {
+	if len(s) <= maxDiagnosticLength {
+		return s
+	}
+	return s[:maxDiagnosticLength] + "..."
+}
+
+// ---------------------------------------------------------------------------
+// Drain
+// ---------------------------------------------------------------------------
+
+// Drainer stops starting new runners and reports when in-flight jobs
+// have finished.  Drain must be idempotent and return a channel that is
+// closed once drained.  *scaler.Scaler satisfies it.
+type Drainer interface {
+	Drain() <-chan struct{}
+}
+
+// DrainResponse represents the drain endpoint response body.
+type DrainResponse struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Drain serves the POST /drain control endpoint used by a Kubernetes
+// preStop hook: it puts the scaler into drain mode and blocks until no
+// runner is busy, the timeout expires or the client goes away, so the
+// pod is not terminated mid-job.  It complements SIGTERM handling, which
+// tears down whatever is left.
+type Drain struct {
+	timeout time.Duration
+
+	mu        sync.Mutex
+	requested bool
+	drainer   Drainer
+}
+
+// NewDrain creates a Drain.  timeout bounds how long a request waits;
+// zero waits until drained or the client disconnects.
+func NewDrain(timeout time.Duration) *Drain {
+	return &Drain{timeout: timeout}
+}
+
+// SetDrainer installs the drainer once the scaler exists.  A drain
+// requested before that is applied immediately.
+func (d *Drain) SetDrainer(drainer Drainer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.drainer = drainer
+	if d.requested {
+		drainer.Drain()
+	}
+}
+
+// Handler responds to drain requests with 200 "drained" once no runner is
+// busy, or 503 "draining" if the wait is cut short.  Only POST is
+// accepted.
+func (d *Drain) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		d.mu.Lock()
+		d.requested = true
+		var drained <-chan struct{}
+		if d.drainer != nil {
+			drained = d.drainer.Drain()
+		}
+		d.mu.Unlock()
+
+		ctx := req.Context()
+		if d.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d.timeout)
+			defer cancel()
+		}
+
+		response := DrainResponse{Status: "drained"}
+		code := http.StatusOK
+		// No drainer yet means no runners have been started.
+		if drained != nil {
+			select {
+			case <-drained:
+			case <-ctx.Done():
+				response.Status = "draining"
+				code = http.StatusServiceUnavailable
+			}
+		}
+		response.Timestamp = time.Now().UTC()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
@@ -1,18 +1,22 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestHandlerReturnsStatusOK(t *testing.T) {
-	handler := Handler("docker")
+	handler := Handler("docker", nil)
 	req := httptest.NewRequest("GET", "/healthz", nil)
 	w := httptest.NewRecorder()
 
@@ -23,7 +27,7 @@ func TestHandlerReturnsStatusOK(t *testing.T) {
 }
 
 func TestHandlerResponseStructure(t *testing.T) {
-	handler := Handler("docker")
+	handler := Handler("docker", nil)
 	req := httptest.NewRequest("GET", "/healthz", nil)
 	w := httptest.NewRecorder()
 
@@ -50,7 +54,7 @@ func TestHandlerWithDifferentEngines(t *testing.T) {
 
 	for _, eng := range engines {
 		t.Run(eng, func(t *testing.T) {
-			handler := Handler(eng)
+			handler := Handler(eng, nil)
 			req := httptest.NewRequest("GET", "/healthz", nil)
 			w := httptest.NewRecorder()
 
@@ -66,7 +70,7 @@ func TestHandlerWithDifferentEngines(t *testing.T) {
 }
 
 func TestHandlerResponseIsValidJSON(t *testing.T) {
-	handler := Handler("docker")
+	handler := Handler("docker", nil)
 	req := httptest.NewRequest("GET", "/healthz", nil)
 	w := httptest.NewRecorder()
 
@@ -84,7 +88,7 @@ func TestHandlerResponseIsValidJSON(t *testing.T) {
 }
 
 func TestHandlerHTTPMethod(t *testing.T) {
-	handler := Handler("docker")
+	handler := Handler("docker", nil)
 
 	t.Run("GET", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/healthz", nil)
@@ -110,7 +114,7 @@ func TestHandlerHTTPMethod(t *testing.T) {
 }
 
 func TestHandlerResponseBody(t *testing.T) {
-	handler := Handler("docker")
+	handler := Handler("docker", nil)
 	req := httptest.NewRequest("GET", "/healthz", nil)
 	w := httptest.NewRecorder()
 
@@ -126,3 +130,190 @@ func TestHandlerResponseBody(t *testing.T) {
 	assert.True(t, strings.Contains(body, "docker"))
 	assert.True(t, strings.Contains(body, "go_version"))
 }
+
+func TestHandlerIncludesLabels(t *testing.T) {
+	handler := Handler("gcp", map[string]string{
+		"environment": "production",
+		"region":      "europe-west1",
+		"team":        "platform",
+	})
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	var body struct {
+		Labels map[string]string `json:"labels"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]string{
+		"environment": "production",
+		"region":      "europe-west1",
+		"team":        "platform",
+	}, body.Labels)
+}
+
+func TestHandlerOmitsLabelsWhenUnset(t *testing.T) {
+	handler := Handler("docker", nil)
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	assert.NotContains(t, w.Body.String(), `"labels"`)
+}
+
+// ---------------------------------------------------------------------------
+// Readiness
+// ---------------------------------------------------------------------------
+
+type fakeChecker struct {
+	diags map[string]string
+	err   error
+}
+
+func (f *fakeChecker) Check(context.Context) (map[string]string, error) {
+	return f.diags, f.err
+}
+
+func serveReady(t *testing.T, r *Readiness) (int, ReadyResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.Handler()(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	var resp ReadyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestReadinessStartingUntilMarkedReady(t *testing.T) {
+	r := NewReadiness("docker", true)
+
+	code, resp := serveReady(t, r)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "starting", resp.Status)
+
+	r.MarkReady(nil)
+	code, resp = serveReady(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", resp.Status)
+	assert.Equal(t, "docker", resp.Engine)
+	assert.Empty(t, resp.Diagnostics)
+}
+
+func TestReadinessIncludesDiagnostics(t *testing.T) {
+	r := NewReadiness("docker", true)
+	r.MarkReady(&fakeChecker{diags: map[string]string{
+		"docker.version":         "27.3.1",
+		"docker.disk_free_bytes": "53687091200",
+	}})
+
+	code, resp := serveReady(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "27.3.1", resp.Diagnostics["docker.version"])
+	assert.Equal(t, "53687091200", resp.Diagnostics["docker.disk_free_bytes"])
+}
+
+func TestReadinessDegradedWhenCheckFails(t *testing.T) {
+	r := NewReadiness("gcp", true)
+	r.MarkReady(&fakeChecker{
+		diags: map[string]string{"gcp.project": "my-project"},
+		err:   errors.New("gcp region us-central1 unreachable"),
+	})
+
+	code, resp := serveReady(t, r)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "degraded", resp.Status)
+	assert.Equal(t, "gcp region us-central1 unreachable", resp.Error)
+	assert.Equal(t, "my-project", resp.Diagnostics["gcp.project"])
+}
+
+func TestReadinessOmitsDiagnosticsWhenDisabled(t *testing.T) {
+	r := NewReadiness("docker", false)
+	r.MarkReady(&fakeChecker{diags: map[string]string{"docker.version": "27.3.1"}})
+
+	code, resp := serveReady(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, resp.Diagnostics)
+}
+
+func TestReadinessBoundsDiagnostics(t *testing.T) {
+	diags := make(map[string]string, maxDiagnostics+10)
+	for i := range maxDiagnostics + 10 {
+		diags[fmt.Sprintf("key.%02d", i)] = "v"
+	}
+	diags["key.00"] = strings.Repeat("x", maxDiagnosticLength*2)
+
+	r := NewReadiness("docker", true)
+	r.MarkReady(&fakeChecker{diags: diags})
+
+	_, resp := serveReady(t, r)
+	assert.Len(t, resp.Diagnostics, maxDiagnostics)
+	assert.Len(t, resp.Diagnostics["key.00"], maxDiagnosticLength+len("..."))
+	assert.NotContains(t, resp.Diagnostics, fmt.Sprintf("key.%02d", maxDiagnostics))
+}
+
+// ---------------------------------------------------------------------------
+// Drain
+// ---------------------------------------------------------------------------
+
+type fakeDrainer struct {
+	calls   int
+	drained chan struct{}
+}
+
+func (f *fakeDrainer) Drain() <-chan struct{} {
+	f.calls++
+	return f.drained
+}
+
+func postDrain(t *testing.T, d *Drain) (int, DrainResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	d.Handler()(w, httptest.NewRequest("POST", "/drain", nil))
+
+	var resp DrainResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestDrainReturnsWhenDrained(t *testing.T) {
+	dr := &fakeDrainer{drained: make(chan struct{})}
+	close(dr.drained)
+	d := NewDrain(0)
+	d.SetDrainer(dr)
+
+	code, resp := postDrain(t, d)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "drained", resp.Status)
+	assert.Equal(t, 1, dr.calls)
+}
+
+func TestDrainTimesOut(t *testing.T) {
+	d := NewDrain(20 * time.Millisecond)
+	d.SetDrainer(&fakeDrainer{drained: make(chan struct{})})
+
+	code, resp := postDrain(t, d)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "draining", resp.Status)
+}
+
+func TestDrainBeforeDrainerIsApplied(t *testing.T) {
+	d := NewDrain(0)
+
+	code, resp := postDrain(t, d)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "drained", resp.Status)
+
+	dr := &fakeDrainer{drained: make(chan struct{})}
+	d.SetDrainer(dr)
+	assert.Equal(t, 1, dr.calls, "pending drain applied when the drainer is installed")
+}
+
+func TestDrainRejectsGet(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewDrain(0).Handler()(w, httptest.NewRequest("GET", "/drain", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "POST", w.Header().Get("Allow"))
+}
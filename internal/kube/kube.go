@@ -0,0 +1,150 @@
+// Package kube is a minimal client for the Kubernetes API server's REST
+// API, authenticated with the pod's service account the way client-go's
+// in-cluster config is.  The kubernetes engine and the Kubernetes Lease
+// lock of leader election need a handful of calls each, not client-go.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// In-cluster service account files, mounted into every pod that does not
+// opt out of the token.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// StatusError is a non-2xx response from the API server.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("kubernetes API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsStatus reports whether err is a response with the status code.
+func IsStatus(err error, code int) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == code
+}
+
+// Client sends requests to an API server.
+type Client struct {
+	baseURL   string
+	tokenFile string
+	http      *http.Client
+}
+
+// NewClient returns a client for the API server at baseURL that sends
+// the token in tokenFile, if any, with every request.
+func NewClient(baseURL, tokenFile string, hc *http.Client) *Client {
+	return &Client{baseURL: baseURL, tokenFile: tokenFile, http: hc}
+}
+
+// NewInCluster returns a client for the API server of the cluster this
+// process runs in, using KUBERNETES_SERVICE_HOST/_PORT and the mounted
+// service account token and CA.
+func NewInCluster() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+	caPEM, err := os.ReadFile(path.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("reading service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("service account CA contains no certificates")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return NewClient(
+		"https://"+net.JoinHostPort(host, port),
+		path.Join(serviceAccountDir, "token"),
+		&http.Client{Transport: transport},
+	), nil
+}
+
+// InClusterNamespace returns the namespace of the pod this process runs
+// in, or "" outside a cluster.
+func InClusterNamespace() string {
+	ns, err := os.ReadFile(path.Join(serviceAccountDir, "namespace"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(ns))
+}
+
+// Do sends a request for p with query, and a JSON body if in is
+// non-nil, and decodes a JSON response into out if non-nil.  A non-2xx
+// response is returned as a *StatusError.  The token is read on every
+// call because the kubelet rotates it.
+func (c *Client) Do(ctx context.Context, method, p string, query url.Values, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	u := c.baseURL + p
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.tokenFile != "" {
+		token, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return fmt.Errorf("reading service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Errors come back as a Status object; fall back to the raw body.
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var status struct {
+			Message string `json:"message"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &status) == nil && status.Message != "" {
+			msg = status.Message
+		}
+		return &StatusError{StatusCode: resp.StatusCode, Message: msg}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, p, err)
+	}
+	return nil
+}
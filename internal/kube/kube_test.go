@@ -0,0 +1,60 @@
+package kube
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo_SendsTokenAndJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/runners/pods", r.URL.Path)
+		assert.Equal(t, "a=b", r.URL.RawQuery)
+		assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		_, _ = w.Write([]byte(`{"name":"runner-a"}`))
+	}))
+	defer srv.Close()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600))
+
+	var out struct{ Name string }
+	c := NewClient(srv.URL, tokenFile, srv.Client())
+	err := c.Do(context.Background(), http.MethodPost, "/api/v1/namespaces/runners/pods",
+		url.Values{"a": {"b"}}, map[string]string{"name": "runner-a"}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "runner-a", out.Name)
+}
+
+func TestDo_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","message":"pods \"missing\" not found","code":404}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`forbidden`))
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "", srv.Client())
+
+	err := c.Do(context.Background(), http.MethodDelete, "/missing", nil, nil, nil)
+	require.Error(t, err)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	assert.Contains(t, err.Error(), `pods "missing" not found`)
+
+	err = c.Do(context.Background(), http.MethodGet, "/other", nil, nil, nil)
+	require.Error(t, err)
+	assert.False(t, IsStatus(err, http.StatusNotFound))
+	assert.Contains(t, err.Error(), "403 Forbidden: forbidden")
+}
@@ -0,0 +1,69 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// FileLock is an exclusive flock(2) on a file, for replicas on one host
+// or on a shared file system that supports it.  The kernel releases the
+// lock when the holder exits, however it exits, so a follower takes over
+// within one retry period; there is no lease to expire.
+type FileLock struct {
+	path     string
+	identity string
+
+	mu   sync.Mutex
+	file *os.File // open, and locked, while held
+}
+
+// NewFileLock returns a lock on the file at path, created if missing.
+// The holder writes identity into it for operators to read.
+func NewFileLock(path, identity string) *FileLock {
+	return &FileLock{path: path, identity: identity}
+}
+
+func (l *FileLock) Describe() string {
+	return "file " + l.path
+}
+
+func (l *FileLock) TryAcquire(context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		return true, nil
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, fmt.Errorf("opening lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, nil
+		}
+		return false, fmt.Errorf("locking %s: %w", l.path, err)
+	}
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(l.identity+"\n"), 0)
+	}
+	l.file = f
+	return true, nil
+}
+
+func (l *FileLock) Release(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	// Closing the file releases the lock.
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
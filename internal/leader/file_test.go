@@ -0,0 +1,46 @@
+package leader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	first := NewFileLock(path, "replica-1")
+	second := NewFileLock(path, "replica-2")
+
+	held, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	held, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held, "renewing a held lock")
+
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "replica-1\n", string(data))
+
+	require.NoError(t, first.Release(ctx))
+	require.NoError(t, first.Release(ctx), "releasing twice")
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	require.NoError(t, second.Release(ctx))
+}
+
+func TestFileLock_MissingDirectory(t *testing.T) {
+	lock := NewFileLock(filepath.Join(t.TempDir(), "missing", "leader.lock"), "replica-1")
+	_, err := lock.TryAcquire(context.Background())
+	assert.ErrorContains(t, err, "opening lock file")
+}
@@ -0,0 +1,209 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/kube"
+)
+
+// microTime is the layout of a Lease's MicroTime fields.
+const microTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// lease is a coordination.k8s.io/v1 Lease.
+type lease struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Metadata   leaseMeta `json:"metadata"`
+	Spec       leaseSpec `json:"spec"`
+}
+
+type leaseMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions,omitempty"`
+}
+
+// LeaseLock is a Kubernetes Lease, as used by client-go's leader
+// election, so kubectl shows the holder.  Whether another holder's lease
+// has expired is judged by when this replica last saw the lease change,
+// not by the holder's timestamps, so clocks need not agree.
+type LeaseLock struct {
+	namespace, name string
+	identity        string
+	duration        time.Duration
+	client          *leaseClient
+	now             func() time.Time
+
+	mu sync.Mutex
+	// observed is the last lease seen and observedAt when it was first
+	// seen with its current holder and renew time.
+	observed   *lease
+	observedAt time.Time
+}
+
+// NewLeaseLock returns a lock on the Lease name in namespace of the
+// cluster this process runs in, held for duration per renewal.  An
+// empty namespace is the pod's own.
+func NewLeaseLock(namespace, name, identity string, duration time.Duration) (*LeaseLock, error) {
+	client, err := kube.NewInCluster()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes lease: %w", err)
+	}
+	if namespace == "" {
+		namespace = kube.InClusterNamespace()
+	}
+	if namespace == "" {
+		return nil, errors.New("kubernetes lease: namespace is not set and the pod's namespace cannot be read")
+	}
+	return &LeaseLock{
+		namespace: namespace,
+		name:      name,
+		identity:  identity,
+		duration:  duration,
+		client:    &leaseClient{client},
+		now:       time.Now,
+	}, nil
+}
+
+func (l *LeaseLock) Describe() string {
+	return "kubernetes lease " + l.namespace + "/" + l.name
+}
+
+func (l *LeaseLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	cur, err := l.client.get(ctx, l.namespace, l.name)
+	if kube.IsStatus(err, http.StatusNotFound) {
+		next := l.record(nil, now)
+		err = l.client.create(ctx, l.namespace, next)
+		if kube.IsStatus(err, http.StatusConflict) {
+			return false, nil // created by another replica meanwhile
+		}
+		if err != nil {
+			return false, fmt.Errorf("creating lease: %w", err)
+		}
+		l.observe(next, now)
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting lease: %w", err)
+	}
+
+	l.observe(cur, now)
+	holder := cur.Spec.HolderIdentity
+	if holder != "" && holder != l.identity {
+		duration := time.Duration(cur.Spec.LeaseDurationSeconds) * time.Second
+		if now.Before(l.observedAt.Add(duration)) {
+			return false, nil
+		}
+	}
+	next := l.record(cur, now)
+	err = l.client.update(ctx, l.namespace, next)
+	if kube.IsStatus(err, http.StatusConflict) {
+		return false, nil // changed by another replica meanwhile
+	}
+	if err != nil {
+		return false, fmt.Errorf("updating lease: %w", err)
+	}
+	l.observe(next, now)
+	return true, nil
+}
+
+// record returns cur (nil for a new lease) held by this replica as of
+// now.
+func (l *LeaseLock) record(cur *lease, now time.Time) *lease {
+	next := &lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata:   leaseMeta{Name: l.name, Namespace: l.namespace},
+	}
+	if cur != nil {
+		next.Metadata.ResourceVersion = cur.Metadata.ResourceVersion
+		next.Spec = cur.Spec
+	}
+	if next.Spec.HolderIdentity != l.identity {
+		if next.Spec.HolderIdentity != "" {
+			next.Spec.LeaseTransitions++
+		}
+		next.Spec.HolderIdentity = l.identity
+		next.Spec.AcquireTime = now.UTC().Format(microTime)
+	}
+	next.Spec.LeaseDurationSeconds = max(1, int(l.duration/time.Second))
+	next.Spec.RenewTime = now.UTC().Format(microTime)
+	return next
+}
+
+// observe records ls as seen at now, restarting the expiry clock when
+// its holder or renew time changed.
+func (l *LeaseLock) observe(ls *lease, now time.Time) {
+	if l.observed == nil ||
+		l.observed.Spec.HolderIdentity != ls.Spec.HolderIdentity ||
+		l.observed.Spec.RenewTime != ls.Spec.RenewTime {
+		l.observedAt = now
+	}
+	l.observed = ls
+}
+
+func (l *LeaseLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cur, err := l.client.get(ctx, l.namespace, l.name)
+	if err != nil {
+		return fmt.Errorf("getting lease: %w", err)
+	}
+	if cur.Spec.HolderIdentity != l.identity {
+		return nil
+	}
+	// An empty holder with a 1s lease lets a follower take over on its
+	// next try, as client-go's ReleaseOnCancel does.
+	cur.Spec.HolderIdentity = ""
+	cur.Spec.LeaseDurationSeconds = 1
+	cur.Spec.RenewTime = l.now().UTC().Format(microTime)
+	if err := l.client.update(ctx, l.namespace, cur); err != nil {
+		return fmt.Errorf("updating lease: %w", err)
+	}
+	return nil
+}
+
+// leaseClient reads and writes Leases over the API server's REST API.
+type leaseClient struct {
+	*kube.Client
+}
+
+func leasesPath(namespace string) string {
+	return "/apis/coordination.k8s.io/v1/namespaces/" + url.PathEscape(namespace) + "/leases"
+}
+
+func (c *leaseClient) get(ctx context.Context, namespace, name string) (*lease, error) {
+	var ls lease
+	if err := c.Do(ctx, http.MethodGet, leasesPath(namespace)+"/"+url.PathEscape(name), nil, nil, &ls); err != nil {
+		return nil, err
+	}
+	return &ls, nil
+}
+
+func (c *leaseClient) create(ctx context.Context, namespace string, ls *lease) error {
+	return c.Do(ctx, http.MethodPost, leasesPath(namespace), nil, ls, nil)
+}
+
+// update replaces the lease; the API server rejects it with 409 if the
+// lease changed since ls was read (its resourceVersion).
+func (c *leaseClient) update(ctx context.Context, namespace string, ls *lease) error {
+	return c.Do(ctx, http.MethodPut, leasesPath(namespace)+"/"+url.PathEscape(ls.Metadata.Name), nil, ls, nil)
+}
@@ -0,0 +1,195 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/kube"
+)
+
+// fakeLeaseAPI serves one namespace's Leases with optimistic concurrency
+// on resourceVersion.
+type fakeLeaseAPI struct {
+	mu      sync.Mutex
+	leases  map[string]lease
+	version int
+}
+
+func (f *fakeLeaseAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	const prefix = "/apis/coordination.k8s.io/v1/namespaces/ci/leases"
+
+	var in lease
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&in)
+	}
+	status := func(code int) {
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": http.StatusText(code)})
+	}
+	save := func() {
+		f.version++
+		in.Metadata.ResourceVersion = strconv.Itoa(f.version)
+		f.leases[in.Metadata.Name] = in
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		ls, ok := f.leases[req.URL.Path[len(prefix)+1:]]
+		if !ok {
+			status(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ls)
+	case http.MethodPost:
+		if _, ok := f.leases[in.Metadata.Name]; ok {
+			status(http.StatusConflict)
+			return
+		}
+		save()
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		if f.leases[in.Metadata.Name].Metadata.ResourceVersion != in.Metadata.ResourceVersion {
+			status(http.StatusConflict)
+			return
+		}
+		save()
+	}
+}
+
+func (f *fakeLeaseAPI) get(name string) lease {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.leases[name]
+}
+
+// newTestLeaseLock returns a lock on the Lease "scaleset" in namespace
+// "ci" of api, at the time *now.
+func newTestLeaseLock(t *testing.T, srv *httptest.Server, identity string, now *time.Time) *LeaseLock {
+	t.Helper()
+	return &LeaseLock{
+		namespace: "ci",
+		name:      "scaleset",
+		identity:  identity,
+		duration:  15 * time.Second,
+		client:    &leaseClient{kube.NewClient(srv.URL, "", srv.Client())},
+		now:       func() time.Time { return *now },
+	}
+}
+
+func TestLeaseLock(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeLeaseAPI{leases: map[string]lease{}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	now := time.Date(2025, time.June, 2, 8, 0, 0, 0, time.UTC)
+	first := newTestLeaseLock(t, srv, "replica-1", &now)
+	second := newTestLeaseLock(t, srv, "replica-2", &now)
+
+	held, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held, "creates the lease")
+	ls := api.get("scaleset")
+	assert.Equal(t, "replica-1", ls.Spec.HolderIdentity)
+	assert.Equal(t, 15, ls.Spec.LeaseDurationSeconds)
+	assert.Equal(t, "2025-06-02T08:00:00.000000Z", ls.Spec.AcquireTime)
+
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	// Renewed within the lease: the follower keeps waiting, counting
+	// from when it saw the renewal.
+	now = now.Add(10 * time.Second)
+	held, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	now = now.Add(10 * time.Second)
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held, "a renewal restarts the expiry clock")
+
+	// Not renewed for a lease duration: the follower takes over.
+	now = now.Add(15 * time.Second)
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	ls = api.get("scaleset")
+	assert.Equal(t, "replica-2", ls.Spec.HolderIdentity)
+	assert.Equal(t, 1, ls.Spec.LeaseTransitions)
+
+	held, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held, "the old leader sees it lost the lease")
+}
+
+func TestLeaseLock_ReleaseHandsOverAtOnce(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeLeaseAPI{leases: map[string]lease{}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	now := time.Date(2025, time.June, 2, 8, 0, 0, 0, time.UTC)
+	first := newTestLeaseLock(t, srv, "replica-1", &now)
+	second := newTestLeaseLock(t, srv, "replica-2", &now)
+
+	_, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	held, err := second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, held)
+
+	require.NoError(t, second.Release(ctx), "releasing a lease held by another replica")
+	assert.Equal(t, "replica-1", api.get("scaleset").Spec.HolderIdentity)
+
+	require.NoError(t, first.Release(ctx))
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+}
+
+func TestLeaseLock_Conflict(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeLeaseAPI{leases: map[string]lease{}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	now := time.Date(2025, time.June, 2, 8, 0, 0, 0, time.UTC)
+	l := newTestLeaseLock(t, srv, "replica-1", &now)
+
+	_, err := l.TryAcquire(ctx)
+	require.NoError(t, err)
+	// Another writer bumps the version between our read and write.
+	api.mu.Lock()
+	ls := api.leases["scaleset"]
+	ls.Metadata.ResourceVersion = "99"
+	api.leases["scaleset"] = ls
+	api.mu.Unlock()
+	l.client = &leaseClient{kube.NewClient(srv.URL, "", srv.Client())}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		api.ServeHTTP(w, req)
+	})
+	held, err := l.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"leases.coordination.k8s.io \"scaleset\" is forbidden"}`))
+	})
+	_, err = l.TryAcquire(ctx)
+	assert.EqualError(t, err, `getting lease: kubernetes API: 403 Forbidden: leases.coordination.k8s.io "scaleset" is forbidden`)
+}
@@ -0,0 +1,182 @@
+// Package leader elects one leader among replicas of the scaleset
+// process, so that they can run for availability while only the leader
+// holds the scale sets' message sessions and scales.  The lock is
+// pluggable (see Lock): a Kubernetes Lease, a file lock or a Redis key.
+package leader
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLostLeadership is returned by Elector.Run, and is the cause of the
+// leader's context, when the lock could not be renewed in time.
+var ErrLostLeadership = errors.New("lost leadership")
+
+// Lock is a lock held by at most one replica at a time, for a bounded
+// lease that the holder renews.
+type Lock interface {
+	// TryAcquire acquires the lock, or renews it if already held, and
+	// reports whether it is held.  It does not wait for the lock.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release gives up the lock if held, so another replica can take
+	// over without waiting for the lease to expire.
+	Release(ctx context.Context) error
+	// Describe names the lock for logs, e.g. "kubernetes lease ci/scaleset".
+	Describe() string
+}
+
+// Config configures an Elector.
+type Config struct {
+	Lock Lock
+	// Identity names this replica in logs and, for locks that record it,
+	// in the lock.
+	Identity string
+	// RenewDeadline is how long the leader keeps leading without a
+	// successful renewal before it steps down.  It must be shorter than
+	// the lock's lease, so the leader stops before another replica can
+	// take over.
+	RenewDeadline time.Duration
+	// RetryPeriod is how often the lock is tried, by followers, and
+	// renewed, by the leader.
+	RetryPeriod time.Duration
+	Logger      *slog.Logger
+}
+
+// Elector runs a replica for leadership.
+type Elector struct {
+	cfg     Config
+	leading atomic.Bool
+}
+
+// New returns an Elector for cfg.
+func New(cfg Config) *Elector {
+	return &Elector{cfg: cfg}
+}
+
+// Leading reports whether this replica is the leader.
+func (e *Elector) Leading() bool {
+	return e.leading.Load()
+}
+
+// Run waits until this replica holds the lock, then runs lead with a
+// context that is cancelled with cause ErrLostLeadership if the lock is
+// lost, and returns lead's error.  The lock is released once lead
+// returns, unless it was lost, in which case ErrLostLeadership is
+// returned: the caller should exit rather than run for leadership
+// again, since what it was doing has been torn down.  Run returns nil
+// if ctx is done before this replica leads.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context) error) error {
+	logger := e.cfg.Logger.With(
+		slog.String("lock", e.cfg.Lock.Describe()),
+		slog.String("identity", e.cfg.Identity),
+	)
+	logger.Info("running for leadership")
+	if !e.acquire(ctx, logger) {
+		return nil
+	}
+	logger.Info("became the leader")
+	e.leading.Store(true)
+	defer e.leading.Store(false)
+
+	leadCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		e.renew(leadCtx, cancel, logger)
+	}()
+
+	err := lead(leadCtx)
+	cancel(nil)
+	<-renewed
+	if errors.Is(context.Cause(leadCtx), ErrLostLeadership) {
+		return ErrLostLeadership
+	}
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), e.cfg.RetryPeriod)
+	defer cancelRelease()
+	if rerr := e.cfg.Lock.Release(releaseCtx); rerr != nil {
+		logger.Warn("releasing leadership failed, another replica takes over once the lease expires",
+			slog.String("error", rerr.Error()),
+		)
+	} else {
+		logger.Info("released leadership")
+	}
+	return err
+}
+
+// acquire tries the lock every RetryPeriod until it is held, and reports
+// whether it is (false once ctx is done).
+func (e *Elector) acquire(ctx context.Context, logger *slog.Logger) bool {
+	ticker := time.NewTicker(e.cfg.RetryPeriod)
+	defer ticker.Stop()
+	standby := false
+	for {
+		held, err := e.try(ctx)
+		switch {
+		case held:
+			return true
+		case err != nil && ctx.Err() == nil:
+			logger.Warn("trying the leader lock failed", slog.String("error", err.Error()))
+		case !standby:
+			logger.Info("another replica leads, standing by")
+			standby = true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// renew renews the lock every RetryPeriod until ctx is done, cancelling
+// ctx with ErrLostLeadership when the lock is taken or has not been
+// renewed for RenewDeadline.
+func (e *Elector) renew(ctx context.Context, cancel context.CancelCauseFunc, logger *slog.Logger) {
+	ticker := time.NewTicker(e.cfg.RetryPeriod)
+	defer ticker.Stop()
+	lastRenew := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		held, err := e.try(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err == nil && held:
+			lastRenew = time.Now()
+			continue
+		case err == nil:
+			logger.Error("leadership taken by another replica, stepping down")
+		case time.Since(lastRenew) < e.cfg.RenewDeadline:
+			logger.Warn("renewing leadership failed, retrying",
+				slog.String("error", err.Error()),
+				slog.Duration("since_renewal", time.Since(lastRenew)),
+			)
+			continue
+		default:
+			logger.Error("leadership not renewed within the renew deadline, stepping down",
+				slog.String("error", err.Error()),
+				slog.Duration("renew_deadline", e.cfg.RenewDeadline),
+			)
+		}
+		cancel(ErrLostLeadership)
+		return
+	}
+}
+
+// try tries the lock, bounded by RetryPeriod so a hanging backend does
+// not stall renewal.
+func (e *Elector) try(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.RetryPeriod)
+	defer cancel()
+	return e.cfg.Lock.TryAcquire(ctx)
+}
@@ -0,0 +1,129 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLock returns the results of tries in order, then repeats the last.
+type fakeLock struct {
+	mu       sync.Mutex
+	results  []tryResult
+	tries    int
+	released int
+}
+
+type tryResult struct {
+	held bool
+	err  error
+}
+
+func (f *fakeLock) TryAcquire(context.Context) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := f.results[min(f.tries, len(f.results)-1)]
+	f.tries++
+	return r.held, r.err
+}
+
+func (f *fakeLock) Release(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.released++
+	return nil
+}
+
+func (f *fakeLock) Describe() string { return "fake" }
+
+func (f *fakeLock) releases() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.released
+}
+
+func newTestElector(lock Lock) *Elector {
+	return New(Config{
+		Lock:          lock,
+		Identity:      "replica-1",
+		RenewDeadline: 20 * time.Millisecond,
+		RetryPeriod:   time.Millisecond,
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+func TestRun_LeadsOnceAcquiredAndReleases(t *testing.T) {
+	lock := &fakeLock{results: []tryResult{{}, {err: errors.New("unreachable")}, {held: true}}}
+	e := newTestElector(lock)
+	errLead := errors.New("lead done")
+
+	err := e.Run(context.Background(), func(ctx context.Context) error {
+		assert.True(t, e.Leading())
+		assert.NoError(t, ctx.Err())
+		return errLead
+	})
+	assert.ErrorIs(t, err, errLead)
+	assert.False(t, e.Leading())
+	assert.Equal(t, 1, lock.releases())
+}
+
+func TestRun_StepsDownWhenLockTaken(t *testing.T) {
+	lock := &fakeLock{results: []tryResult{{held: true}, {held: true}, {held: false}}}
+	e := newTestElector(lock)
+
+	err := e.Run(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), ErrLostLeadership)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrLostLeadership)
+	assert.Zero(t, lock.releases(), "a lost lock is not released")
+}
+
+func TestRun_ToleratesRenewErrorsUntilDeadline(t *testing.T) {
+	lock := &fakeLock{results: []tryResult{{held: true}, {err: errors.New("timeout")}, {held: true}}}
+	e := newTestElector(lock)
+
+	err := e.Run(context.Background(), func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			t.Error("stepped down on a transient renew error")
+		case <-time.After(50 * time.Millisecond):
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	lock = &fakeLock{results: []tryResult{{held: true}, {err: errors.New("timeout")}}}
+	e = newTestElector(lock)
+	start := time.Now()
+	err = e.Run(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrLostLeadership)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond, "renew deadline")
+}
+
+func TestRun_CancelledWhileStandingBy(t *testing.T) {
+	lock := &fakeLock{results: []tryResult{{held: false}}}
+	e := newTestElector(lock)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	led := false
+	err := e.Run(ctx, func(context.Context) error {
+		led = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.False(t, led)
+	assert.Zero(t, lock.releases())
+}
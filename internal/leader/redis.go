@@ -0,0 +1,187 @@
+package leader
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scripts that change the key only while this replica holds it, so a
+// replica whose lease expired cannot renew or delete the next holder's.
+const (
+	redisRenewScript   = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) else return 0 end`
+	redisReleaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+)
+
+// RedisConfig is how to reach the Redis server of a RedisLock.
+type RedisConfig struct {
+	// Address is the server's host:port.
+	Address string
+	// Username and Password authenticate with AUTH; Username needs
+	// Redis 6 ACLs and may be empty.
+	Username string
+	Password string
+	// DB is the database number to SELECT.
+	DB int
+	// TLS connects over TLS, verifying the server with the system roots.
+	TLS bool
+}
+
+// RedisLock is a Redis key holding the leader's identity with a
+// server-side expiry, so it needs no agreement between clocks.  A single
+// Redis server is assumed; the lock is no stronger than its
+// availability.
+type RedisLock struct {
+	cfg      RedisConfig
+	key      string
+	identity string
+	duration time.Duration
+	dial     func(ctx context.Context) (net.Conn, error)
+}
+
+// NewRedisLock returns a lock on key, held for duration per renewal.
+func NewRedisLock(cfg RedisConfig, key, identity string, duration time.Duration) *RedisLock {
+	l := &RedisLock{cfg: cfg, key: key, identity: identity, duration: duration}
+	l.dial = func(ctx context.Context) (net.Conn, error) {
+		if cfg.TLS {
+			host, _, _ := net.SplitHostPort(cfg.Address)
+			d := &tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+			return d.DialContext(ctx, "tcp", cfg.Address)
+		}
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", cfg.Address)
+	}
+	return l
+}
+
+func (l *RedisLock) Describe() string {
+	return "redis key " + l.key + " on " + l.cfg.Address
+}
+
+func (l *RedisLock) TryAcquire(ctx context.Context) (bool, error) {
+	ms := strconv.FormatInt(l.duration.Milliseconds(), 10)
+	var held bool
+	err := l.session(ctx, func(c *redisConn) error {
+		renewed, err := c.do("EVAL", redisRenewScript, "1", l.key, l.identity, ms)
+		if err != nil {
+			return err
+		}
+		if renewed == int64(1) {
+			held = true
+			return nil
+		}
+		set, err := c.do("SET", l.key, l.identity, "NX", "PX", ms)
+		held = set == "OK"
+		return err
+	})
+	return held, err
+}
+
+func (l *RedisLock) Release(ctx context.Context) error {
+	return l.session(ctx, func(c *redisConn) error {
+		_, err := c.do("EVAL", redisReleaseScript, "1", l.key, l.identity)
+		return err
+	})
+}
+
+// session runs fn on a new connection, authenticated and on the
+// configured database.  Each try connects anew: tries are seconds apart
+// and a fresh connection never carries a broken state over.
+func (l *RedisLock) session(ctx context.Context, fn func(c *redisConn) error) error {
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("connecting to redis: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c := &redisConn{w: conn, r: bufio.NewReader(conn)}
+
+	if l.cfg.Password != "" {
+		args := []string{"AUTH", l.cfg.Password}
+		if l.cfg.Username != "" {
+			args = []string{"AUTH", l.cfg.Username, l.cfg.Password}
+		}
+		if _, err := c.do(args...); err != nil {
+			return fmt.Errorf("redis AUTH: %w", err)
+		}
+	}
+	if l.cfg.DB != 0 {
+		if _, err := c.do("SELECT", strconv.Itoa(l.cfg.DB)); err != nil {
+			return fmt.Errorf("redis SELECT: %w", err)
+		}
+	}
+	if err := fn(c); err != nil {
+		return fmt.Errorf("redis: %w", err)
+	}
+	return nil
+}
+
+// redisConn speaks the subset of RESP2 the lock needs: commands as
+// arrays of bulk strings; simple string, error, integer and bulk string
+// replies.
+type redisConn struct {
+	w io.Writer
+	r *bufio.Reader
+}
+
+// redisError is an error reply.
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// do sends a command and returns its reply: a string for simple and
+// bulk strings, an int64 for integers, nil for a null bulk string.
+func (c *redisConn) do(args ...string) (any, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(c.w, b.String()); err != nil {
+		return nil, err
+	}
+	return c.reply()
+}
+
+func (c *redisConn) reply() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	default:
+		return nil, fmt.Errorf("unsupported reply %q", line)
+	}
+}
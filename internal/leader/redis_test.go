@@ -0,0 +1,145 @@
+package leader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis answers the commands a RedisLock sends, on in-memory
+// connections, keeping keys without expiry.
+type fakeRedis struct {
+	mu       sync.Mutex
+	password string
+	keys     map[string]string
+	commands []string
+}
+
+func (f *fakeRedis) dial(context.Context) (net.Conn, error) {
+	client, server := net.Pipe()
+	go f.serve(server)
+	return client, nil
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write([]byte(f.handle(args))); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil { // $<len>
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func (f *fakeRedis) handle(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, args[0])
+	switch args[0] {
+	case "AUTH":
+		if args[len(args)-1] != f.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		return "+OK\r\n"
+	case "SET":
+		if _, ok := f.keys[args[1]]; ok {
+			return "$-1\r\n"
+		}
+		f.keys[args[1]] = args[2]
+		return "+OK\r\n"
+	case "EVAL":
+		key, identity := args[3], args[4]
+		if f.keys[key] != identity {
+			return ":0\r\n"
+		}
+		if args[1] == redisReleaseScript {
+			delete(f.keys, key)
+		}
+		return ":1\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func newTestRedisLock(f *fakeRedis, cfg RedisConfig, identity string) *RedisLock {
+	l := NewRedisLock(cfg, "scaleset-leader", identity, 15*time.Second)
+	l.dial = f.dial
+	return l
+}
+
+func TestRedisLock(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeRedis{keys: map[string]string{}}
+	first := newTestRedisLock(f, RedisConfig{Address: "redis:6379"}, "replica-1")
+	second := newTestRedisLock(f, RedisConfig{Address: "redis:6379"}, "replica-2")
+
+	held, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	held, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held, "renewing a held lock")
+
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	require.NoError(t, second.Release(ctx))
+	assert.Equal(t, "replica-1", f.keys["scaleset-leader"], "a follower cannot release the leader's key")
+
+	require.NoError(t, first.Release(ctx))
+	held, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, "redis key scaleset-leader on redis:6379", first.Describe())
+}
+
+func TestRedisLock_Auth(t *testing.T) {
+	f := &fakeRedis{keys: map[string]string{}, password: "secret"}
+
+	lock := newTestRedisLock(f, RedisConfig{Password: "secret"}, "replica-1")
+	held, err := lock.TryAcquire(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, "AUTH", f.commands[0])
+
+	lock = newTestRedisLock(f, RedisConfig{Password: "wrong"}, "replica-2")
+	_, err = lock.TryAcquire(context.Background())
+	assert.ErrorContains(t, err, "redis AUTH: WRONGPASS")
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	promexporter "go.opentelemetry.io/otel/exporters/prometheus"
@@ -41,8 +42,19 @@ type Config struct {
 	// application (e.g., in main.go). This configuration value is used to
 	// signal that Prometheus metrics should be collected and exported.
 	PrometheusPort int
+
+	// Engine, Region and Zone, when set, are recorded as resource
+	// attributes (scaleset.engine, cloud.provider, cloud.region,
+	// cloud.availability_zone) so every metric and span can be sliced by
+	// backend and location without adding per-metric labels.
+	Engine string
+	Region string
+	Zone   string
 }
 
+// EngineAttributeKey is the resource attribute holding the compute engine.
+const EngineAttributeKey = attribute.Key("scaleset.engine")
+
 // SetupOTelSDK configures the OpenTelemetry SDK with the given service name
 // and returns a shutdown function. Call this once at application startup.
 //
@@ -79,15 +91,8 @@ func SetupOTelSDK(
 		err = errors.Join(inErr, shutdown(ctx))
 	}
 
-	// Create resource with service name and version.
-	res, err := resource.Merge(
-		resource.Default(),
-		resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceName(serviceName),
-			semconv.ServiceVersion(buildinfo.Version),
-		),
-	)
+	// Create resource with service name, version and engine location.
+	res, err := newResource(serviceName, cfg)
 	if err != nil {
 		handleErr(err)
 		return
@@ -100,7 +105,7 @@ func SetupOTelSDK(
 			handleErr(tErr)
 			return
 		}
-		shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
+		shutdownFuncs = append(shutdownFuncs, flushAndShutdown(tracerProvider))
 		otel.SetTracerProvider(tracerProvider)
 	}
 
@@ -112,13 +117,65 @@ func SetupOTelSDK(
 			handleErr(mErr)
 			return
 		}
-		shutdownFuncs = append(shutdownFuncs, meterProvider.Shutdown)
+		shutdownFuncs = append(shutdownFuncs, flushAndShutdown(meterProvider))
 		otel.SetMeterProvider(meterProvider)
 	}
 
 	return
 }
 
+// newResource builds the resource shared by all providers.
+func newResource(serviceName string, cfg Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(buildinfo.Version),
+	}
+	if cfg.Engine != "" {
+		attrs = append(attrs, EngineAttributeKey.String(cfg.Engine))
+		switch cfg.Engine {
+		case "gcp":
+			attrs = append(attrs, semconv.CloudProviderGCP)
+		case "aws":
+			attrs = append(attrs, semconv.CloudProviderAWS)
+		case "azure":
+			attrs = append(attrs, semconv.CloudProviderAzure)
+		}
+	}
+	if cfg.Region != "" {
+		attrs = append(attrs, semconv.CloudRegion(cfg.Region))
+	}
+	if cfg.Zone != "" {
+		attrs = append(attrs, semconv.CloudAvailabilityZone(cfg.Zone))
+	}
+
+	return resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, attrs...),
+	)
+}
+
+// provider is the subset of the SDK tracer and meter providers used
+// during shutdown.
+type provider interface {
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// flushAndShutdown returns a shutdown function that exports any pending
+// spans/metrics before shutting the provider down.  Periodic readers only
+// export every interval, so without the explicit flush data recorded just
+// before exit (e.g. runners destroyed during shutdown) can be lost.  The
+// provider is shut down even when the flush fails.
+func flushAndShutdown(p provider) func(context.Context) error {
+	return func(ctx context.Context) error {
+		flushErr := p.ForceFlush(ctx)
+		if flushErr != nil {
+			flushErr = fmt.Errorf("flush: %w", flushErr)
+		}
+		return errors.Join(flushErr, p.Shutdown(ctx))
+	}
+}
+
 // newTraceProvider creates a TracerProvider with OTLP HTTP exporter.
 func newTraceProvider(ctx context.Context, res *resource.Resource, cfg Config) (*trace.TracerProvider, error) {
 	var exporters []trace.SpanExporter
@@ -0,0 +1,117 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+type fakeProvider struct {
+	calls    []string
+	flushErr error
+}
+
+func (f *fakeProvider) ForceFlush(context.Context) error {
+	f.calls = append(f.calls, "flush")
+	return f.flushErr
+}
+
+func (f *fakeProvider) Shutdown(context.Context) error {
+	f.calls = append(f.calls, "shutdown")
+	return nil
+}
+
+func TestFlushAndShutdown_FlushesFirst(t *testing.T) {
+	p := &fakeProvider{}
+	require.NoError(t, flushAndShutdown(p)(context.Background()))
+	assert.Equal(t, []string{"flush", "shutdown"}, p.calls)
+}
+
+func TestFlushAndShutdown_ShutsDownWhenFlushFails(t *testing.T) {
+	p := &fakeProvider{flushErr: errors.New("collector unreachable")}
+
+	err := flushAndShutdown(p)(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "collector unreachable")
+	assert.Equal(t, []string{"flush", "shutdown"}, p.calls)
+}
+
+// exportCounter is a metric exporter that counts export calls.
+type exportCounter struct {
+	exports int
+}
+
+func (e *exportCounter) Temporality(k metric.InstrumentKind) metricdata.Temporality {
+	return metric.DefaultTemporalitySelector(k)
+}
+
+func (e *exportCounter) Aggregation(k metric.InstrumentKind) metric.Aggregation {
+	return metric.DefaultAggregationSelector(k)
+}
+
+func (e *exportCounter) Export(context.Context, *metricdata.ResourceMetrics) error {
+	e.exports++
+	return nil
+}
+
+func (e *exportCounter) ForceFlush(context.Context) error { return nil }
+func (e *exportCounter) Shutdown(context.Context) error   { return nil }
+
+func TestFlushAndShutdown_ExportsPendingMetrics(t *testing.T) {
+	exp := &exportCounter{}
+	// An hour-long interval guarantees nothing is exported periodically.
+	mp := metric.NewMeterProvider(metric.WithReader(
+		metric.NewPeriodicReader(exp, metric.WithInterval(time.Hour))))
+
+	counter, err := mp.Meter("test").Int64Counter("runners")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+
+	require.NoError(t, flushAndShutdown(mp)(context.Background()))
+	assert.GreaterOrEqual(t, exp.exports, 1)
+}
+
+func resourceAttrs(t *testing.T, cfg Config) map[attribute.Key]string {
+	t.Helper()
+	res, err := newResource("scaleset", cfg)
+	require.NoError(t, err)
+
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range res.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func TestNewResource_EngineAndRegion(t *testing.T) {
+	attrs := resourceAttrs(t, Config{Engine: "gcp", Region: "us-central1", Zone: "us-central1-a"})
+
+	assert.Equal(t, "scaleset", attrs[semconv.ServiceNameKey])
+	assert.Equal(t, "gcp", attrs[EngineAttributeKey])
+	assert.Equal(t, "gcp", attrs[semconv.CloudProviderKey])
+	assert.Equal(t, "us-central1", attrs[semconv.CloudRegionKey])
+	assert.Equal(t, "us-central1-a", attrs[semconv.CloudAvailabilityZoneKey])
+}
+
+func TestNewResource_DockerHasNoCloudAttributes(t *testing.T) {
+	attrs := resourceAttrs(t, Config{Engine: "docker"})
+
+	assert.Equal(t, "docker", attrs[EngineAttributeKey])
+	assert.NotContains(t, attrs, semconv.CloudProviderKey)
+	assert.NotContains(t, attrs, semconv.CloudRegionKey)
+}
+
+func TestNewResource_EngineAttributesOptional(t *testing.T) {
+	attrs := resourceAttrs(t, Config{})
+
+	assert.Equal(t, "scaleset", attrs[semconv.ServiceNameKey])
+	assert.NotContains(t, attrs, EngineAttributeKey)
+}
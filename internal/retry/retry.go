@@ -0,0 +1,151 @@
+// Package retry runs an operation with exponential backoff and jitter.
+// It is the single retry helper shared by the call sites that retry
+// transient failures (scale set creation, container start, ...), so
+// they agree on backoff semantics and honour context cancellation the
+// same way.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter selects how a backoff delay is randomized.
+type Jitter string
+
+const (
+	// JitterNone waits exactly the computed delay.
+	JitterNone Jitter = "none"
+	// JitterFull waits a uniformly random duration in [0, delay).
+	JitterFull Jitter = "full"
+	// JitterEqual waits half the delay plus a uniformly random duration
+	// in [0, delay/2).
+	JitterEqual Jitter = "equal"
+)
+
+// DefaultMultiplier is the growth factor used when Options.Multiplier is
+// zero.
+const DefaultMultiplier = 2
+
+// randFloat returns a value in [0, 1).  Tests replace it to pin jitter.
+var randFloat = rand.Float64
+
+// Options configures Do.
+type Options struct {
+	// Retries is how many times fn is retried after the first attempt.
+	// Zero runs fn once.
+	Retries int
+
+	// Base is the delay before the first retry.
+	Base time.Duration
+
+	// Max caps each delay before jitter is applied.  Zero means no cap.
+	Max time.Duration
+
+	// Multiplier is the factor the delay grows by after each retry.
+	// 1 gives a constant delay.  Default: DefaultMultiplier.
+	Multiplier float64
+
+	// Jitter randomizes each delay.  Default: JitterNone.
+	Jitter Jitter
+
+	// Retryable reports whether err is worth retrying.  Nil retries
+	// every error.
+	Retryable func(err error) bool
+
+	// OnRetry, when set, is called before waiting for retry number
+	// attempt (1-based) with the error that caused it and the wait.
+	OnRetry func(attempt int, err error, wait time.Duration)
+}
+
+// Validate reports an invalid combination of options.
+func (o Options) Validate() error {
+	switch {
+	case o.Retries < 0:
+		return fmt.Errorf("retries must be >= 0, got %d", o.Retries)
+	case o.Base < 0:
+		return fmt.Errorf("base delay must be >= 0, got %s", o.Base)
+	case o.Max < 0:
+		return fmt.Errorf("max delay must be >= 0, got %s", o.Max)
+	case o.Multiplier != 0 && o.Multiplier < 1:
+		return fmt.Errorf("multiplier must be >= 1, got %g", o.Multiplier)
+	}
+	switch o.Jitter {
+	case "", JitterNone, JitterFull, JitterEqual:
+		return nil
+	}
+	return fmt.Errorf("jitter must be one of none, full, equal, got %q", o.Jitter)
+}
+
+// Backoff returns the delay before retry number attempt (1-based),
+// before jitter: Base * Multiplier^(attempt-1), capped at Max.
+func (o Options) Backoff(attempt int) time.Duration {
+	if attempt < 1 || o.Base <= 0 {
+		return 0
+	}
+	mult := o.Multiplier
+	if mult == 0 {
+		mult = DefaultMultiplier
+	}
+
+	d := float64(o.Base)
+	for range attempt - 1 {
+		d *= mult
+		if d >= float64(math.MaxInt64) {
+			break
+		}
+	}
+	if o.Max > 0 && d > float64(o.Max) {
+		return o.Max
+	}
+	if d >= float64(math.MaxInt64) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// Delay returns the wait before retry number attempt with jitter applied.
+func (o Options) Delay(attempt int) time.Duration {
+	d := o.Backoff(attempt)
+	switch o.Jitter {
+	case JitterFull:
+		return time.Duration(randFloat() * float64(d))
+	case JitterEqual:
+		half := d / 2
+		return half + time.Duration(randFloat()*float64(d-half))
+	default:
+		return d
+	}
+}
+
+// Do calls fn until it succeeds, returns an error Retryable rejects, or
+// Retries retries have been used, waiting Delay between attempts.  It
+// returns fn's last error, or ctx.Err() if the context ends while
+// waiting.
+func Do(ctx context.Context, fn func() error, opts Options) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= opts.Retries || (opts.Retryable != nil && !opts.Retryable(err)) {
+			return err
+		}
+
+		wait := opts.Delay(attempt + 1)
+		if opts.OnRetry != nil {
+			opts.OnRetry(attempt+1, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("transient")
+
+// pinRand makes jitter return v for the duration of the test.
+func pinRand(t *testing.T, v float64) {
+	t.Helper()
+	orig := randFloat
+	randFloat = func() float64 { return v }
+	t.Cleanup(func() { randFloat = orig })
+}
+
+func TestBackoff_ExponentialSequence(t *testing.T) {
+	o := Options{Base: 100 * time.Millisecond}
+	want := []time.Duration{100, 200, 400, 800, 1600}
+	for i, w := range want {
+		assert.Equal(t, w*time.Millisecond, o.Backoff(i+1), "attempt %d", i+1)
+	}
+}
+
+func TestBackoff_CustomMultiplier(t *testing.T) {
+	o := Options{Base: time.Second, Multiplier: 3}
+	assert.Equal(t, time.Second, o.Backoff(1))
+	assert.Equal(t, 3*time.Second, o.Backoff(2))
+	assert.Equal(t, 9*time.Second, o.Backoff(3))
+}
+
+func TestBackoff_ConstantWithMultiplierOne(t *testing.T) {
+	o := Options{Base: time.Second, Multiplier: 1}
+	for attempt := 1; attempt <= 5; attempt++ {
+		assert.Equal(t, time.Second, o.Backoff(attempt))
+	}
+}
+
+func TestBackoff_CappedAtMax(t *testing.T) {
+	o := Options{Base: time.Second, Max: 5 * time.Second}
+	assert.Equal(t, 4*time.Second, o.Backoff(3))
+	assert.Equal(t, 5*time.Second, o.Backoff(4))
+	assert.Equal(t, 5*time.Second, o.Backoff(100))
+}
+
+func TestBackoff_NoOverflow(t *testing.T) {
+	o := Options{Base: time.Second}
+	assert.Equal(t, time.Duration(math.MaxInt64), o.Backoff(1000))
+}
+
+func TestBackoff_ZeroForInvalidAttemptOrBase(t *testing.T) {
+	assert.Zero(t, Options{Base: time.Second}.Backoff(0))
+	assert.Zero(t, Options{}.Backoff(3))
+}
+
+func TestDelay_NoJitter(t *testing.T) {
+	pinRand(t, 0.5)
+	o := Options{Base: time.Second, Jitter: JitterNone}
+	assert.Equal(t, 2*time.Second, o.Delay(2))
+}
+
+func TestDelay_FullJitterBounds(t *testing.T) {
+	o := Options{Base: time.Second, Jitter: JitterFull}
+
+	pinRand(t, 0)
+	assert.Zero(t, o.Delay(2))
+
+	pinRand(t, 0.5)
+	assert.Equal(t, time.Second, o.Delay(2))
+
+	pinRand(t, 0.999999)
+	assert.Less(t, o.Delay(2), 2*time.Second)
+}
+
+func TestDelay_EqualJitterBounds(t *testing.T) {
+	o := Options{Base: time.Second, Jitter: JitterEqual}
+
+	pinRand(t, 0)
+	assert.Equal(t, time.Second, o.Delay(2))
+
+	pinRand(t, 0.999999)
+	d := o.Delay(2)
+	assert.GreaterOrEqual(t, d, time.Second)
+	assert.Less(t, d, 2*time.Second)
+}
+
+func TestDelay_JitterWithinBoundsUnpinned(t *testing.T) {
+	full := Options{Base: 10 * time.Millisecond, Max: 80 * time.Millisecond, Jitter: JitterFull}
+	equal := Options{Base: 10 * time.Millisecond, Max: 80 * time.Millisecond, Jitter: JitterEqual}
+	for attempt := 1; attempt <= 10; attempt++ {
+		for range 100 {
+			backoff := full.Backoff(attempt)
+			d := full.Delay(attempt)
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+			assert.LessOrEqual(t, d, backoff)
+
+			d = equal.Delay(attempt)
+			assert.GreaterOrEqual(t, d, backoff/2)
+			assert.LessOrEqual(t, d, backoff)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Options{}.Validate())
+	assert.NoError(t, Options{Retries: 3, Base: time.Second, Max: time.Minute, Multiplier: 1.5, Jitter: JitterEqual}.Validate())
+
+	assert.ErrorContains(t, Options{Retries: -1}.Validate(), "retries")
+	assert.ErrorContains(t, Options{Base: -time.Second}.Validate(), "base")
+	assert.ErrorContains(t, Options{Max: -time.Second}.Validate(), "max")
+	assert.ErrorContains(t, Options{Multiplier: 0.5}.Validate(), "multiplier")
+	assert.ErrorContains(t, Options{Jitter: "half"}.Validate(), "jitter")
+}
+
+func TestDo_SucceedsFirstTry(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), func() error {
+		calls++
+		return nil
+	}, Options{Retries: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	var waits []time.Duration
+	err := Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	}, Options{
+		Retries: 5,
+		Base:    time.Millisecond,
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			assert.Equal(t, len(waits)+1, attempt)
+			assert.ErrorIs(t, err, errTransient)
+			waits = append(waits, wait)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond}, waits)
+}
+
+func TestDo_ReturnsLastErrorWhenExhausted(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), func() error {
+		calls++
+		return errTransient
+	}, Options{Retries: 2, Base: time.Millisecond})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDo_ZeroRetriesRunsOnce(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), func() error {
+		calls++
+		return errTransient
+	}, Options{})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_StopsOnNonRetryableError(t *testing.T) {
+	permanent := errors.New("permanent")
+	calls := 0
+	err := Do(context.Background(), func() error {
+		calls++
+		return permanent
+	}, Options{
+		Retries:   5,
+		Base:      time.Millisecond,
+		Retryable: func(err error) bool { return errors.Is(err, errTransient) },
+	})
+	assert.ErrorIs(t, err, permanent)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_ContextCancelledDuringWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	start := time.Now()
+	err := Do(ctx, func() error {
+		calls++
+		cancel()
+		return errTransient
+	}, Options{Retries: 5, Base: time.Hour})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestDo_ContextDeadlineDuringWait(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := Do(ctx, func() error { return errTransient }, Options{Retries: 5, Base: time.Hour})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,65 @@
+package scaler
+
+import (
+	"log/slog"
+
+	"github.com/terrpan/scaleset/internal/audit"
+)
+
+// AuditLog records the scaler's decisions and actions for postmortems
+// (see the audit package).  *audit.Log satisfies it.
+type AuditLog interface {
+	Record(ev audit.Event) error
+}
+
+// Reasons a runner is destroyed, as recorded in the audit log.
+const (
+	destroyJobCompleted   = "job_completed"
+	destroyShutdown       = "shutdown"
+	destroyIdle           = "destroy_idle"
+	destroyRequested      = "requested"
+	destroyStartDeadline  = "start_deadline"
+	destroyEmptyID        = "empty_runner_id"
+	destroyStartupTimeout = "startup_timeout"
+	destroyLifetime       = "max_lifetime"
+	destroyMissedJob      = "missed_job_completion"
+	destroyOrphan         = "orphan"
+	destroyPreempted      = "preempted"
+	destroyLeftover       = "startup_cleanup"
+	destroyUnregistered   = "not_registered"
+)
+
+// audit records ev in the audit log, if any, stamped with the scale set
+// ID.  A failure to record is logged, never returned: the audit log must
+// not stop the scaler.
+func (s *Scaler) audit(ev audit.Event) {
+	if s.auditLog == nil {
+		return
+	}
+	ev.Time = s.now()
+	ev.ScaleSetID = s.scaleSetID
+	if err := s.auditLog.Record(ev); err != nil {
+		s.logger.Error("failed to write audit log",
+			slog.String("type", ev.Type),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// auditCreated records a runner started by the engine in the audit log.
+func (s *Scaler) auditCreated(name, id string) {
+	s.audit(audit.Event{Type: audit.TypeRunnerCreated, Runner: name, RunnerID: id, Reason: "scale_up"})
+}
+
+// auditError records the failure of op ("start_runner", "destroy_runner"
+// or "scale_up") in the audit log.
+func (s *Scaler) auditError(op, reason, runner, id string, err error) {
+	s.audit(audit.Event{
+		Type:     audit.TypeError,
+		Op:       op,
+		Runner:   runner,
+		RunnerID: id,
+		Reason:   reason,
+		Error:    err.Error(),
+	})
+}
@@ -0,0 +1,99 @@
+package scaler
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/audit"
+)
+
+type recordingAuditLog struct {
+	mu     sync.Mutex
+	events []audit.Event
+	err    error
+}
+
+func (r *recordingAuditLog) Record(ev audit.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+	return r.err
+}
+
+func (r *recordingAuditLog) types() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []string
+	for _, ev := range r.events {
+		out = append(out, ev.Type)
+	}
+	return out
+}
+
+func (s *ScalerSuite) TestAudit_RecordsLifecycle() {
+	log := &recordingAuditLog{}
+	sc := New(Config{ScaleSetID: 7, MinRunners: 0, MaxRunners: 2, ScalesetClient: s.jitGen, Engine: s.engine, Logger: s.logger, AuditLog: log})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	name := s.engine.getStarted()[0]
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{
+		JobMessageBase: scaleset.JobMessageBase{JobID: "job-1", RepositoryName: "app"},
+		RunnerName:     name,
+	}))
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{
+		JobMessageBase: scaleset.JobMessageBase{JobID: "job-1", RepositoryName: "app"},
+		RunnerName:     name,
+		Result:         "succeeded",
+	}))
+
+	assert.Equal(s.T(), []string{
+		audit.TypeScaleDecision,
+		audit.TypeRunnerCreated,
+		audit.TypeJobStarted,
+		audit.TypeJobCompleted,
+		audit.TypeRunnerDestroyed,
+	}, log.types())
+
+	ev := log.events
+	assert.Equal(s.T(), &audit.Decision{MinRunners: 0, MaxRunners: 2, Desired: 1, Current: 0, Target: 1, Action: "up", Delta: 1}, ev[0].Decision)
+	assert.Equal(s.T(), 7, ev[0].ScaleSetID)
+	assert.False(s.T(), ev[0].Time.IsZero())
+	assert.Equal(s.T(), name, ev[1].Runner)
+	assert.NotEmpty(s.T(), ev[1].RunnerID)
+	assert.Equal(s.T(), "app", ev[2].Repository)
+	assert.Equal(s.T(), "succeeded", ev[3].Result)
+	assert.Equal(s.T(), "job_completed", ev[4].Reason)
+	assert.Equal(s.T(), ev[1].RunnerID, ev[4].RunnerID)
+}
+
+func (s *ScalerSuite) TestAudit_RecordsErrors() {
+	log := &recordingAuditLog{err: errors.New("disk full")}
+	s.engine.startErr = errors.New("quota exceeded")
+	sc := New(Config{ScaleSetID: 1, MaxRunners: 1, ScalesetClient: s.jitGen, Engine: s.engine, Logger: s.logger, AuditLog: log})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err, "a failing audit log does not hide scaler errors")
+
+	require.Equal(s.T(), []string{audit.TypeScaleDecision, audit.TypeError, audit.TypeError}, log.types())
+	assert.Equal(s.T(), "start_runner", log.events[1].Op)
+	assert.Contains(s.T(), log.events[1].Error, "quota exceeded")
+	assert.NotEmpty(s.T(), log.events[1].Runner)
+	assert.Equal(s.T(), "scale_up", log.events[2].Op)
+}
+
+func (s *ScalerSuite) TestAudit_DestroyReason() {
+	log := &recordingAuditLog{}
+	sc := New(Config{ScaleSetID: 1, MinRunners: 1, MaxRunners: 1, ScalesetClient: s.jitGen, Engine: s.engine, Logger: s.logger, AuditLog: log})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+
+	sc.Shutdown(s.ctx)
+	last := log.events[len(log.events)-1]
+	assert.Equal(s.T(), audit.TypeRunnerDestroyed, last.Type)
+	assert.Equal(s.T(), "shutdown", last.Reason)
+}
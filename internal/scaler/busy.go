@@ -0,0 +1,119 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// StartBusyCheck runs CheckBusyRunners every Config.BusyCheckInterval
+// until ctx is done.  The returned stop func ends the loop and waits for
+// a pass in progress, so it must be called before Shutdown.  It does
+// nothing when the busy check is disabled.
+func (s *Scaler) StartBusyCheck(ctx context.Context) (stop func()) {
+	if s.busyCheckInterval <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.busyCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.CheckBusyRunners(ctx); err != nil && ctx.Err() == nil {
+					s.logger.Warn("checking busy runners failed", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// CheckBusyRunners looks up every busy runner in Config.RunnerRegistry.
+// GitHub removes an ephemeral runner once its job finishes, so a busy
+// runner it no longer lists is one whose JobCompleted message was lost:
+// once two consecutive passes find it unregistered it is destroyed, as
+// HandleJobCompleted would have, and the scaler rescales to the desired
+// count the listener last reported.  It returns the names of the
+// destroyed runners.
+func (s *Scaler) CheckBusyRunners(ctx context.Context) ([]string, error) {
+	if s.busyCheckInterval <= 0 {
+		return nil, nil
+	}
+	ctx, span := s.tracer.Start(ctx, "scaler.CheckBusyRunners")
+	defer span.End()
+
+	s.busyCheckMu.Lock()
+	defer s.busyCheckMu.Unlock()
+
+	s.mu.Lock()
+	busy := slices.Sorted(maps.Keys(s.busy))
+	s.mu.Unlock()
+
+	var stale []string
+	var errs []error
+	unregistered := make(map[string]bool)
+	for _, name := range busy {
+		ref, err := s.registry.GetRunnerByName(ctx, name)
+		switch {
+		case err != nil:
+			if s.unregisteredBusy[name] {
+				unregistered[name] = true
+			}
+			errs = append(errs, fmt.Errorf("look up runner %s: %w", name, err))
+			continue
+		case ref != nil:
+			continue
+		case !s.unregisteredBusy[name]:
+			unregistered[name] = true // first sighting; may be a lagging read
+			continue
+		}
+
+		id, _ := s.removeRunner(name)
+		if id == "" {
+			continue // its job completed in the meantime
+		}
+		s.logger.Warn("busy check: destroying busy runner GitHub no longer lists, its job completion was missed",
+			slog.String("name", name),
+			slog.String("id", id),
+		)
+		if s.staleBusy != nil {
+			s.staleBusy.Add(ctx, 1)
+		}
+		stale = append(stale, name)
+		if err := s.destroyRunner(ctx, name, id, destroyMissedJob); err != nil {
+			errs = append(errs, fmt.Errorf("destroy runner %s (%s): %w", name, id, err))
+			continue
+		}
+		if s.runnersDestroyed != nil {
+			s.runnersDestroyed.Add(ctx, 1)
+		}
+	}
+	s.unregisteredBusy = unregistered
+	span.SetAttributes(attribute.Int("scaleset.stale_busy", len(stale)))
+	if len(stale) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	s.scaleMu.Lock()
+	_, err := s.scaleTo(ctx, s.lastDesired)
+	s.scaleMu.Unlock()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("start replacements: %w", err))
+	}
+	return stale, errors.Join(errs...)
+}
@@ -0,0 +1,111 @@
+package scaler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *ScalerSuite) TestCheckBusyRunners_DestroysUnregistered() {
+	reader := s.withManualMeter()
+	registry := &mockRegistry{runners: map[string]int{}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.RunnerRegistry = registry
+		c.BusyCheckInterval = time.Minute
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+	started := s.engine.getStarted()
+	for _, name := range started {
+		registry.runners[name] = 1
+	}
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[0]}))
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[1]}))
+
+	// started[0]'s job finished and its JobCompleted was lost; idle
+	// runners are not looked up.
+	delete(registry.runners, started[0])
+	delete(registry.runners, started[2])
+
+	stale, err := sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), stale, "a runner must be seen unregistered twice")
+
+	stale, err = sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{started[0]}, stale)
+	assert.Equal(s.T(), []string{s.engine.ids[started[0]]}, s.engine.getDestroyed())
+	assert.Equal(s.T(), int64(1), s.counterValue(reader, "scaleset.runners.stale_busy"))
+	assert.NotContains(s.T(), sc.busy, started[0])
+	assert.Contains(s.T(), sc.busy, started[1])
+	assert.Contains(s.T(), sc.idle, started[2])
+
+	// A replacement is started for the last desired count.
+	assert.Equal(s.T(), 4, s.engine.startedCount())
+	assert.Equal(s.T(), 3, sc.runnerCount())
+}
+
+func (s *ScalerSuite) TestCheckBusyRunners_ReregisteredRunnerIsKept() {
+	registry := &mockRegistry{runners: map[string]int{}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.RunnerRegistry = registry
+		c.BusyCheckInterval = time.Minute
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	name := s.engine.getStarted()[0]
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: name}))
+
+	// Not listed once, then listed again: the first read lagged.
+	_, err = sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	registry.runners[name] = 1
+	_, err = sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	delete(registry.runners, name)
+	stale, err := sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), stale)
+	assert.Empty(s.T(), s.engine.getDestroyed())
+}
+
+func (s *ScalerSuite) TestCheckBusyRunners_LookupErrorKeepsRunner() {
+	registry := &mockRegistry{runners: map[string]int{}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.RunnerRegistry = registry
+		c.BusyCheckInterval = time.Minute
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	name := s.engine.getStarted()[0]
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: name}))
+
+	_, err = sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	registry.err = errors.New("api unavailable")
+	stale, err := sc.CheckBusyRunners(s.ctx)
+	assert.ErrorContains(s.T(), err, "api unavailable")
+	assert.Empty(s.T(), stale)
+	assert.Contains(s.T(), sc.busy, name)
+
+	// The earlier sighting still counts once the lookup works again.
+	registry.err = nil
+	stale, err = sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{name}, stale)
+}
+
+func (s *ScalerSuite) TestCheckBusyRunners_DisabledWithoutRegistry() {
+	sc := s.newScalerWith(func(c *Config) { c.BusyCheckInterval = time.Minute })
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: s.engine.getStarted()[0]}))
+
+	stale, err := sc.CheckBusyRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), stale)
+	sc.StartBusyCheck(s.ctx)()
+}
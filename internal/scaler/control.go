@@ -0,0 +1,190 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrUnknownRunner is returned by DestroyRunner for a runner the scaler
+// does not track.
+var ErrUnknownRunner = errors.New("unknown runner")
+
+// RunnerInfo describes a tracked runner (see Runners).
+type RunnerInfo struct {
+	Name string
+	// ID is the engine's id of the runner's resource; empty if the
+	// engine did not report one.
+	ID string
+	// Busy reports that the runner is running a job.
+	Busy bool
+	// RunID is the run ID the runner's resource is labelled with.
+	RunID string
+}
+
+// Runners returns the tracked runners, sorted by name.
+func (s *Scaler) Runners() []RunnerInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := slices.Sorted(maps.Keys(s.idle))
+	names = append(names, slices.Collect(maps.Keys(s.busy))...)
+	slices.Sort(names)
+
+	runners := make([]RunnerInfo, len(names))
+	for i, name := range names {
+		r := RunnerInfo{Name: name, RunID: s.runIDOfLocked(name)}
+		if id, ok := s.busy[name]; ok {
+			r.ID, r.Busy = id, true
+		} else {
+			r.ID = s.idle[name]
+		}
+		runners[i] = r
+	}
+	return runners
+}
+
+// RunnerLimits returns the min_runners and max_runners in effect (see
+// SetRunnerLimits).
+func (s *Scaler) RunnerLimits() (minRunners, maxRunners int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.minRunners, s.maxRunners
+}
+
+// Pause stops the scaler from starting new runners until Resume.  Unlike
+// Drain it is reversible; runners already started are unaffected and
+// are still destroyed as their jobs complete.
+func (s *Scaler) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		s.paused = true
+		s.logger.Info("scaling paused: no new runners will be started")
+	}
+}
+
+// Resume undoes Pause.  Scale-ups resume with the next desired runner
+// count.
+func (s *Scaler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		s.paused = false
+		s.logger.Info("scaling resumed")
+	}
+}
+
+// Paused reports whether scaling is paused.
+func (s *Scaler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Draining reports whether Drain was called.
+func (s *Scaler) Draining() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining
+}
+
+// DestroyIdleRunners forgets every idle runner and destroys its
+// resource, for a graceful drain that should not wait for idle runners
+// to be assigned jobs.  Busy runners are left to finish their jobs.  It
+// returns how many runners were destroyed and the destroy errors,
+// joined.
+func (s *Scaler) DestroyIdleRunners(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "scaler.DestroyIdleRunners")
+	defer span.End()
+
+	// Take the runners out of idle at once, so none is destroyed after
+	// a job started on it.
+	s.mu.Lock()
+	idle := maps.Clone(s.idle)
+	for name := range idle {
+		delete(s.idle, name)
+		delete(s.startedAt, name)
+		delete(s.createdAt, name)
+		delete(s.runnerRunID, name)
+	}
+	s.runnersChangedLocked()
+	s.mu.Unlock()
+	if len(idle) == 0 {
+		return 0, nil
+	}
+	s.logger.Info("destroying idle runners", slog.Int("count", len(idle)))
+
+	var (
+		mu        sync.Mutex
+		destroyed int
+		errs      []error
+		wg        sync.WaitGroup
+	)
+	for _, name := range slices.Sorted(maps.Keys(idle)) {
+		id := idle[name]
+		if id == "" {
+			continue // left to the engine's Shutdown
+		}
+		wg.Go(func() {
+			err := s.destroyRunner(ctx, name, id, destroyIdle)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("destroy runner %s (%s): %w", name, id, err))
+				return
+			}
+			destroyed++
+		})
+	}
+	wg.Wait()
+	if s.runnersDestroyed != nil && destroyed > 0 {
+		s.runnersDestroyed.Add(ctx, int64(destroyed))
+	}
+	span.SetAttributes(attribute.Int("runners.destroyed", destroyed))
+	return destroyed, errors.Join(errs...)
+}
+
+// DestroyRunner forgets the named runner and destroys its resource,
+// whether idle or busy; a busy runner's job fails.  It returns
+// ErrUnknownRunner if the runner is not tracked.  A runner the engine
+// never reported an id for is only forgotten, and left to the engine's
+// Shutdown.
+func (s *Scaler) DestroyRunner(ctx context.Context, name string) error {
+	ctx, span := s.tracer.Start(ctx, "scaler.DestroyRunner")
+	defer span.End()
+	span.SetAttributes(attribute.String("runner.name", name))
+
+	s.mu.Lock()
+	_, idle := s.idle[name]
+	_, busy := s.busy[name]
+	s.mu.Unlock()
+	if !idle && !busy {
+		return fmt.Errorf("%w: %s", ErrUnknownRunner, name)
+	}
+
+	// The runner may have completed meanwhile, in which case it is
+	// already being destroyed.
+	id, _ := s.removeRunner(name)
+	s.logger.Warn("destroying runner on request",
+		slog.String("runner", name),
+		slog.String("id", id),
+		slog.Bool("busy", busy),
+	)
+	if id == "" {
+		return nil
+	}
+	if err := s.destroyRunner(ctx, name, id, destroyRequested); err != nil {
+		return fmt.Errorf("destroy runner %s (%s): %w", name, id, err)
+	}
+	if s.runnersDestroyed != nil {
+		s.runnersDestroyed.Add(ctx, 1)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package scaler
+
+import (
+	"errors"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *ScalerSuite) TestRunners() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		RunID:          "run-1",
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	started := s.engine.getStarted()
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[1]}))
+
+	runners := sc.Runners()
+	require.Len(s.T(), runners, 2)
+	byName := map[string]RunnerInfo{runners[0].Name: runners[0], runners[1].Name: runners[1]}
+	assert.Equal(s.T(), RunnerInfo{Name: started[0], ID: s.engine.ids[started[0]], RunID: "run-1"}, byName[started[0]])
+	assert.Equal(s.T(), RunnerInfo{Name: started[1], ID: s.engine.ids[started[1]], Busy: true, RunID: "run-1"}, byName[started[1]])
+	assert.Less(s.T(), runners[0].Name, runners[1].Name)
+}
+
+func (s *ScalerSuite) TestPause_StopsScaleUpUntilResumed() {
+	sc := s.newScaler(0, 10)
+	sc.Pause()
+	assert.True(s.T(), sc.Paused())
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, count)
+	assert.Equal(s.T(), 0, s.engine.startedCount())
+
+	sc.Resume()
+	assert.False(s.T(), sc.Paused())
+	count, err = sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+}
+
+func (s *ScalerSuite) TestRunnerLimits() {
+	sc := s.newScaler(1, 5)
+	sc.SetRunnerLimits(2, 8)
+	minRunners, maxRunners := sc.RunnerLimits()
+	assert.Equal(s.T(), []int{2, 8}, []int{minRunners, maxRunners})
+}
+
+func (s *ScalerSuite) TestDestroyRunner() {
+	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	started := s.engine.getStarted()
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[0]}))
+
+	// Busy runners are destroyed too.
+	require.NoError(s.T(), sc.DestroyRunner(s.ctx, started[0]))
+	assert.Equal(s.T(), []string{s.engine.ids[started[0]]}, s.engine.getDestroyed())
+	assert.Empty(s.T(), sc.busy)
+	assert.Len(s.T(), sc.idle, 1)
+
+	err = sc.DestroyRunner(s.ctx, started[0])
+	assert.ErrorIs(s.T(), err, ErrUnknownRunner)
+
+	// A failed destroy is returned, and the runner is no longer tracked.
+	s.engine.destroyErr = errors.New("backend down")
+	err = sc.DestroyRunner(s.ctx, started[1])
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "backend down")
+	assert.Empty(s.T(), sc.idle)
+}
+
+func (s *ScalerSuite) TestDestroyIdleRunners() {
+	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+	started := s.engine.getStarted()
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[0]}))
+
+	destroyed, err := sc.DestroyIdleRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, destroyed)
+	assert.ElementsMatch(s.T(), []string{s.engine.ids[started[1]], s.engine.ids[started[2]]}, s.engine.getDestroyed())
+	assert.Empty(s.T(), sc.idle)
+	assert.Len(s.T(), sc.busy, 1, "busy runners finish their jobs")
+
+	destroyed, err = sc.DestroyIdleRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), destroyed)
+}
+
+func (s *ScalerSuite) TestDestroyIdleRunners_Errors() {
+	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+
+	s.engine.destroyErr = errors.New("backend down")
+	destroyed, err := sc.DestroyIdleRunners(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "backend down")
+	assert.Zero(s.T(), destroyed)
+	assert.Empty(s.T(), sc.idle, "failed runners are not tracked anymore")
+}
+
+func (s *ScalerSuite) TestDraining() {
+	sc := s.newScaler(0, 10)
+	assert.False(s.T(), sc.Draining())
+	sc.Drain()
+	assert.True(s.T(), sc.Draining())
+}
@@ -0,0 +1,118 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// StartLifetimeReaper runs ReapExpiredRunners periodically until ctx is
+// done.  The returned stop func ends the loop and waits for a pass in
+// progress, so it must be called before Shutdown.  It does nothing when
+// Config.MaxRunnerLifetime is zero.
+func (s *Scaler) StartLifetimeReaper(ctx context.Context) (stop func()) {
+	if s.maxRunnerLifetime <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(max(s.lifetimeCheckInterval, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.ReapExpiredRunners(ctx); err != nil && ctx.Err() == nil {
+					s.logger.Warn("reaping expired runners failed", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// ReapExpiredRunners destroys the runners, idle or busy, that have
+// existed for Config.MaxRunnerLifetime and rescales to the desired count
+// the listener last reported so that they are replaced.  A busy
+// runner's job is cancelled with it.  Runners restored from a state
+// saved before their creation time was recorded, and runners adopted by
+// the reconciler, count from when they were first tracked.  It returns
+// the names of the destroyed runners.
+func (s *Scaler) ReapExpiredRunners(ctx context.Context) ([]string, error) {
+	if s.maxRunnerLifetime <= 0 {
+		return nil, nil
+	}
+	ctx, span := s.tracer.Start(ctx, "scaler.ReapExpiredRunners")
+	defer span.End()
+
+	s.mu.Lock()
+	now := s.now()
+	var expired []string
+	for name, created := range s.createdAt {
+		_, idle := s.idle[name]
+		_, busy := s.busy[name]
+		if !idle && !busy {
+			delete(s.createdAt, name)
+			continue
+		}
+		if now.Sub(created) >= s.maxRunnerLifetime {
+			expired = append(expired, name)
+		}
+	}
+	s.mu.Unlock()
+	slices.Sort(expired)
+
+	var reaped []string
+	var errs []error
+	for _, name := range expired {
+		id, wasIdle := s.removeRunner(name)
+		if id == "" {
+			continue // its job completed in the meantime
+		}
+		runnerState := "busy"
+		if wasIdle {
+			runnerState = "idle"
+		}
+		s.logger.Warn("runner exceeded the max runner lifetime, replacing it",
+			slog.String("name", name),
+			slog.String("id", id),
+			slog.String("state", runnerState),
+			slog.Duration("max_runner_lifetime", s.maxRunnerLifetime),
+		)
+		if s.lifetimeExpired != nil {
+			s.lifetimeExpired.Add(ctx, 1, metric.WithAttributes(attribute.String("state", runnerState)))
+		}
+		reaped = append(reaped, name)
+		if err := s.destroyRunner(ctx, name, id, destroyLifetime); err != nil {
+			errs = append(errs, fmt.Errorf("destroy runner %s (%s): %w", name, id, err))
+			continue
+		}
+		if s.runnersDestroyed != nil {
+			s.runnersDestroyed.Add(ctx, 1)
+		}
+	}
+	span.SetAttributes(attribute.Int("scaleset.lifetime_expired", len(reaped)))
+	if len(reaped) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	s.scaleMu.Lock()
+	_, err := s.scaleTo(ctx, s.lastDesired)
+	s.scaleMu.Unlock()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("start replacements: %w", err))
+	}
+	return reaped, errors.Join(errs...)
+}
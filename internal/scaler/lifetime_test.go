@@ -0,0 +1,104 @@
+package scaler
+
+import (
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/state"
+)
+
+func (s *ScalerSuite) TestReapExpiredRunners() {
+	reader := s.withManualMeter()
+	sc := s.newScalerWith(func(c *Config) { c.MaxRunnerLifetime = 6 * time.Hour })
+	now := stopClock(sc)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	started := s.engine.getStarted()
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[0]}))
+
+	*now = now.Add(6*time.Hour - time.Minute)
+	reaped, err := sc.ReapExpiredRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), reaped, "not expired before the lifetime")
+
+	*now = now.Add(time.Minute)
+	reaped, err = sc.ReapExpiredRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.ElementsMatch(s.T(), started, reaped, "idle and busy runners are reaped")
+	assert.ElementsMatch(s.T(), []string{s.engine.ids[started[0]], s.engine.ids[started[1]]}, s.engine.getDestroyed())
+	assert.Equal(s.T(), int64(2), s.counterValue(reader, "scaleset.runners.lifetime_expired"))
+
+	// Replacements are started for the last desired count, each with its
+	// own lifetime.
+	assert.Equal(s.T(), 4, s.engine.startedCount())
+	assert.Equal(s.T(), 2, sc.runnerCount())
+	assert.Empty(s.T(), sc.busy)
+	reaped, err = sc.ReapExpiredRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), reaped)
+
+	// The reaped busy runner's job completing later is ignored.
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: started[0], Result: "canceled"}))
+	assert.Len(s.T(), s.engine.getDestroyed(), 2)
+}
+
+func (s *ScalerSuite) TestReapExpiredRunners_RestoredRunnersKeepCreationTime() {
+	created := time.Now().Add(-5 * time.Hour)
+	store := &memStore{snap: &state.Snapshot{Runners: map[string]state.Runner{
+		"runner-old": {ID: "c1", Busy: true, CreatedAt: created},
+		"runner-new": {ID: "c2"},
+	}}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.MaxRunnerLifetime = 6 * time.Hour
+		c.StateStore = store
+	})
+	now := stopClock(sc)
+	_, err := sc.Restore(s.ctx)
+	require.NoError(s.T(), err)
+	sc.flushState()
+	assert.Equal(s.T(), created, store.runners()["runner-old"].CreatedAt)
+	assert.Equal(s.T(), *now, store.runners()["runner-new"].CreatedAt, "runners saved without a creation time count from the restore")
+
+	*now = now.Add(time.Hour)
+	reaped, err := sc.ReapExpiredRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"runner-old"}, reaped)
+	assert.Equal(s.T(), []string{"c1"}, s.engine.getDestroyed())
+}
+
+func (s *ScalerSuite) TestReapExpiredRunners_Disabled() {
+	sc := s.newScaler(0, 10)
+	now := stopClock(sc)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	*now = now.Add(30 * 24 * time.Hour)
+	reaped, err := sc.ReapExpiredRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), reaped)
+	assert.Empty(s.T(), s.engine.getDestroyed())
+	sc.StartLifetimeReaper(s.ctx)()
+}
+
+func (s *ScalerSuite) TestStartLifetimeReaper_RunsPeriodically() {
+	sc := New(Config{
+		ScaleSetID:        1,
+		MaxRunners:        10,
+		ScalesetClient:    s.jitGen,
+		Engine:            s.engine,
+		Logger:            s.logger,
+		MaxRunnerLifetime: 20 * time.Millisecond,
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	stop := sc.StartLifetimeReaper(s.ctx)
+	assert.Eventually(s.T(), func() bool {
+		return len(s.engine.getDestroyed()) >= 1
+	}, time.Second, 5*time.Millisecond)
+	stop()
+	assert.Equal(s.T(), 1, sc.runnerCount(), "the expired runner was replaced")
+}
@@ -0,0 +1,84 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/actions/scaleset"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// errorClasses maps fragments of engine error messages, matched case
+// insensitively, to the class reported on the start failure counter.
+// Engines return their backend's errors wrapped, so the message is the
+// one thing all of them have in common.  The first match wins.
+var errorClasses = []struct {
+	class     string
+	fragments []string
+}{
+	{"quota", []string{"quota", "rate limit", "ratelimit", "too many requests"}},
+	{"capacity", []string{"resource_pool_exhausted", "insufficient", "out of capacity", "does not have enough resources", "no space left"}},
+	{"permission", []string{"permission", "forbidden", "unauthorized", "unauthenticated"}},
+	{"not_found", []string{"not found", "no such"}},
+	{"conflict", []string{"already exists", "conflict"}},
+}
+
+// errorClass returns a small, fixed set of labels for an engine start
+// error, so failures can be told apart without unbounded label values:
+// "timeout", "canceled", one of errorClasses, or "other".
+func errorClass(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case err == nil:
+		return "other"
+	}
+	msg := strings.ToLower(err.Error())
+	for _, c := range errorClasses {
+		for _, f := range c.fragments {
+			if strings.Contains(msg, f) {
+				return c.class
+			}
+		}
+	}
+	return "other"
+}
+
+// currentEngine returns the engine label of the engine metrics: the
+// backend runners are currently started on, if the engine reports it,
+// else Config.EngineName.
+func (s *Scaler) currentEngine() string {
+	if a, ok := s.engine.(interface{ Active() string }); ok {
+		return a.Active()
+	}
+	return s.engineName
+}
+
+// observeEngineCall records the latency of an engine call ("start",
+// "start_batch" or "destroy") that began at start and returned err.
+func (s *Scaler) observeEngineCall(ctx context.Context, op string, start time.Time, err error) {
+	if s.engineLatency == nil {
+		return
+	}
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	s.engineLatency.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("operation", op),
+		attribute.String("engine", s.currentEngine()),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// recordStatistics publishes the queue depth of the scale set from the
+// statistics attached to a listener message: jobs assigned to it that
+// are not running yet.
+func (s *Scaler) recordStatistics(stats *scaleset.RunnerScaleSetStatistic) {
+	s.queueDepth.Store(int64(max(stats.TotalAssignedJobs-stats.TotalRunningJobs, 0)))
+}
@@ -0,0 +1,124 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/actions/scaleset"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// CleanupOrphans destroys the scaleset-managed runner resources a
+// crashed process left behind, so that they do not accumulate across
+// restarts.  It must be called after Restore and before the scaler
+// handles any message: every managed resource of this scale set (of any
+// run ID) that the scaler is not tracking is then a leftover.
+//
+// Only resources scoped to this scale set are considered: those whose
+// engine.ScaleSetIDLabel is this scale set's ID, and, for resources
+// without that label, those of this process's or a restored run ID.
+// Anything else may be a live runner of another entry or deployment
+// sharing the engine and is never touched.  A leftover not registered
+// with GitHub, or registered as a runner of this scale set, is
+// destroyed; one registered with another scale set, or that cannot be
+// looked up, is kept.  Preempted leftovers are destroyed regardless.  It
+// does nothing when Config.CleanupOnStartup is unset, and returns the
+// names of the destroyed runners.
+func (s *Scaler) CleanupOrphans(ctx context.Context) ([]string, error) {
+	if !s.cleanupOnStartup {
+		return nil, nil
+	}
+	lister, ok := s.engine.(engine.RunnerLister)
+	if !ok {
+		return nil, errors.New("engine cannot list runners")
+	}
+	if s.registry == nil {
+		return nil, errors.New("no runner registry")
+	}
+	ctx, span := s.tracer.Start(ctx, "scaler.CleanupOrphans")
+	defer span.End()
+
+	listed, err := lister.ListRunners(ctx, engine.RunnerLabels(""))
+	if err != nil {
+		return nil, fmt.Errorf("list runners: %w", err)
+	}
+	slices.SortFunc(listed, func(a, b engine.ListedRunner) int { return strings.Compare(a.Name, b.Name) })
+
+	s.mu.Lock()
+	tracked := make(map[string]bool, len(s.idle)+len(s.busy))
+	for _, id := range s.idle {
+		tracked[id] = true
+	}
+	for _, id := range s.busy {
+		tracked[id] = true
+	}
+	s.mu.Unlock()
+
+	var destroyed []string
+	var errs []error
+	for _, lr := range listed {
+		// The engine filters by label already; check again so a lister
+		// that over-matches can never destroy unmanaged resources.
+		if tracked[lr.ID] || lr.Labels[engine.ManagedLabel] != "true" {
+			continue
+		}
+		if !s.ownsResource(lr.Labels) {
+			continue
+		}
+		logger := s.logger.With(
+			slog.String("name", lr.Name),
+			slog.String("id", lr.ID),
+			slog.String("run_id", lr.Labels[engine.RunIDLabel]),
+		)
+
+		var ref *scaleset.RunnerReference
+		if !lr.Preempted {
+			ref, err = s.registry.GetRunnerByName(ctx, lr.Name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("look up runner %s: %w", lr.Name, err))
+				continue
+			}
+		}
+		if ref != nil && ref.RunnerScaleSetID != s.scaleSetID {
+			logger.Debug("startup cleanup: runner belongs to another scale set, leaving it",
+				slog.Int("scale_set_id", ref.RunnerScaleSetID),
+			)
+			continue
+		}
+
+		logger.Info("startup cleanup: destroying leftover runner")
+		if err := s.destroyRunner(ctx, lr.Name, lr.ID, destroyLeftover); err != nil {
+			errs = append(errs, fmt.Errorf("destroy leftover runner %s (%s): %w", lr.Name, lr.ID, err))
+			continue
+		}
+		if s.runnersDestroyed != nil {
+			s.runnersDestroyed.Add(ctx, 1)
+		}
+		destroyed = append(destroyed, lr.Name)
+	}
+
+	s.logger.Info("startup cleanup complete",
+		slog.Int("listed", len(listed)),
+		slog.Int("destroyed", len(destroyed)),
+	)
+	span.SetAttributes(attribute.Int("scaleset.startup_cleanup.destroyed", len(destroyed)))
+	return destroyed, errors.Join(errs...)
+}
+
+// ownsResource reports whether a resource with the given labels is scoped
+// to this scale set: by its scale set ID label or, lacking one, by the
+// run ID of this process or one it restored.
+func (s *Scaler) ownsResource(labels map[string]string) bool {
+	if id, ok := labels[engine.ScaleSetIDLabel]; ok {
+		return id == strconv.Itoa(s.scaleSetID)
+	}
+	runID := labels[engine.RunIDLabel]
+	return runID != "" && (runID == s.runID || slices.Contains(s.restoredRunIDs, runID))
+}
@@ -0,0 +1,123 @@
+package scaler
+
+import (
+	"errors"
+	"maps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/state"
+)
+
+// addManaged adds a resource carrying the labels of runID and of scale
+// set 1.
+func (m *mockListingEngine) addManaged(name, id, runID string) {
+	m.addScoped(name, id, runID, engine.ScaleSetLabels("runners", 1))
+}
+
+// addScoped adds a resource carrying the labels of runID and the given
+// scale set labels.
+func (m *mockListingEngine) addScoped(name, id, runID string, scaleSet map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := engine.RunnerLabels(runID)
+	maps.Copy(labels, scaleSet)
+	m.extra[id] = engine.ListedRunner{ID: id, Name: name, Labels: labels}
+}
+
+func (s *ScalerSuite) TestCleanupOrphans() {
+	eng := newMockListingEngine()
+	eng.addManaged("runner-unregistered", "c1", "run-1")
+	eng.addManaged("runner-ours", "c2", "run-1")
+	eng.addManaged("runner-other-set", "c3", "run-9")
+	eng.addManaged("runner-restored", "c4", "run-1")
+	eng.addManaged("runner-preempted", "c5", "run-1")
+	eng.preempted["c5"] = true
+	eng.addResource("not-managed", "c6")
+	registry := &mockRegistry{runners: map[string]int{
+		"runner-ours":      1,
+		"runner-other-set": 2,
+		"runner-restored":  1,
+		"runner-preempted": 1,
+	}}
+	store := &memStore{snap: &state.Snapshot{Runners: map[string]state.Runner{
+		"runner-restored": {ID: "c4", RunID: "run-1"},
+	}}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = registry
+		c.StateStore = store
+		c.RunID = "run-2"
+		c.CleanupOnStartup = true
+	})
+	_, err := sc.Restore(s.ctx)
+	require.NoError(s.T(), err)
+
+	destroyed, err := sc.CleanupOrphans(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"runner-ours", "runner-preempted", "runner-unregistered"}, destroyed)
+	assert.ElementsMatch(s.T(), []string{"c1", "c2", "c5"}, eng.getDestroyed())
+	assert.Equal(s.T(), []map[string]string{engine.RunnerLabels("")}, eng.labels, "every run ID is listed")
+	assert.Equal(s.T(), map[string]string{"runner-restored": "c4"}, sc.idle, "restored runners are kept")
+}
+
+func (s *ScalerSuite) TestCleanupOrphans_LeavesResourcesOfOtherScaleSets() {
+	eng := newMockListingEngine()
+	eng.addScoped("runner-foreign", "c1", "run-9", engine.ScaleSetLabels("other", 2))
+	eng.addScoped("runner-legacy", "c2", "run-9", nil)
+	eng.addScoped("runner-legacy-own", "c3", "run-2", nil)
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{}
+		c.RunID = "run-2"
+		c.CleanupOnStartup = true
+	})
+
+	destroyed, err := sc.CleanupOrphans(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"runner-legacy-own"}, destroyed,
+		"unregistered resources not scoped to this scale set survive")
+	assert.Equal(s.T(), []string{"c3"}, eng.getDestroyed())
+}
+
+func (s *ScalerSuite) TestCleanupOrphans_LookupErrorKeepsRunner() {
+	eng := newMockListingEngine()
+	eng.addManaged("runner-old", "c1", "run-1")
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{err: errors.New("api unavailable")}
+		c.RunID = "run-2"
+		c.CleanupOnStartup = true
+	})
+
+	destroyed, err := sc.CleanupOrphans(s.ctx)
+	assert.ErrorContains(s.T(), err, "api unavailable")
+	assert.Empty(s.T(), destroyed)
+	assert.Empty(s.T(), eng.getDestroyed())
+}
+
+func (s *ScalerSuite) TestCleanupOrphans_Disabled() {
+	eng := newMockListingEngine()
+	eng.addManaged("runner-old", "c1", "run-1")
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{}
+	})
+
+	destroyed, err := sc.CleanupOrphans(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), destroyed)
+	assert.Empty(s.T(), eng.labels)
+}
+
+func (s *ScalerSuite) TestCleanupOrphans_EngineCannotList() {
+	sc := s.newScalerWith(func(c *Config) {
+		c.RunnerRegistry = &mockRegistry{}
+		c.RunID = "run-2"
+		c.CleanupOnStartup = true
+	})
+	_, err := sc.CleanupOrphans(s.ctx)
+	assert.ErrorContains(s.T(), err, "cannot list runners")
+}
@@ -0,0 +1,76 @@
+package scaler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket limits how fast runners are started.  Tokens refill at rate
+// per second up to burst; each start takes one.  Callers that find the
+// bucket empty reserve a future token (the balance goes negative) and
+// sleep until it is due, so concurrent waiters are served in order and
+// sustained demand is smoothed into a steady rate.
+type tokenBucket struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time // overridable for tests
+}
+
+// newTokenBucket returns a full bucket.  burst < 1 is treated as 1.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := float64(max(burst, 1))
+	return &tokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it (zero when one was available).
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens = min(b.burst, b.tokens+1)
+	b.mu.Unlock()
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
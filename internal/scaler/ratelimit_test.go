@@ -0,0 +1,53 @@
+package scaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket_BurstThenRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(2, 3) // 2/s, burst 3
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	// The burst is served immediately.
+	for range 3 {
+		assert.Zero(t, b.reserve())
+	}
+	// Then each start is spaced 1/rate apart.
+	assert.Equal(t, 500*time.Millisecond, b.reserve())
+	assert.Equal(t, time.Second, b.reserve())
+
+	// After idling, the bucket refills but never beyond burst.
+	now = now.Add(time.Hour)
+	for range 3 {
+		assert.Zero(t, b.reserve())
+	}
+	assert.Equal(t, 500*time.Millisecond, b.reserve())
+}
+
+func TestTokenBucket_BurstBelowOne(t *testing.T) {
+	b := newTokenBucket(1, 0)
+	assert.Zero(t, b.reserve())
+	assert.Positive(t, b.reserve())
+}
+
+func TestTokenBucket_WaitCancelledReturnsToken(t *testing.T) {
+	b := newTokenBucket(0.001, 1)
+	require.NoError(t, b.Wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, b.Wait(ctx), context.Canceled)
+
+	// The cancelled reservation was handed back: the balance is where it
+	// was after the first Wait, not one further in debt.
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	assert.InDelta(t, 0, b.tokens, 0.01)
+}
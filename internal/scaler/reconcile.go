@@ -0,0 +1,271 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/actions/scaleset"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// RunnerRegistry looks up runners registered with GitHub.  The real
+// *scaleset.Client satisfies it implicitly.
+type RunnerRegistry interface {
+	GetRunnerByName(ctx context.Context, name string) (*scaleset.RunnerReference, error)
+}
+
+// ReconcileResult lists the runners a reconciliation pass acted on, by
+// runner name.
+type ReconcileResult struct {
+	// Destroyed are resources that were neither tracked nor registered
+	// with GitHub (orphans), now destroyed.
+	Destroyed []string
+	// Adopted are resources registered as runners of this scale set that
+	// were not tracked (stragglers), now tracked as idle.
+	Adopted []string
+	// Forgotten are tracked runners whose resource no longer exists,
+	// now removed from the scaler's state.
+	Forgotten []string
+	// Preempted are tracked runners whose resource the backend reclaimed
+	// (e.g. a GCP spot VM), now destroyed and removed from the scaler's
+	// state so the next scale-up replaces them.
+	Preempted []string
+}
+
+// reconciler holds the state carried between reconciliation passes.  A
+// discrepancy is only acted on once two consecutive passes see it, so a
+// runner whose start is still in flight (resource created, not yet
+// tracked) or whose resource a backend lists late is left alone.
+type reconciler struct {
+	lister   engine.RunnerLister
+	interval time.Duration
+
+	mu        sync.Mutex      // serializes passes
+	untracked map[string]bool // resource ids seen untracked last pass
+	missing   map[string]bool // runner names seen without a resource last pass
+
+	actions metric.Int64Counter
+}
+
+// StartReconciler runs Reconcile every Config.ReconcileInterval until ctx
+// is done.  The returned stop func ends the loop and waits for a pass in
+// progress, so it must be called before Shutdown.  It does nothing when
+// reconciliation is disabled.
+func (s *Scaler) StartReconciler(ctx context.Context) (stop func()) {
+	if s.reconciler == nil {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.reconciler.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Reconcile(ctx); err != nil && ctx.Err() == nil {
+					s.logger.Warn("runner reconciliation failed", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Reconcile compares the runner resources the engine lists (by the
+// labels of this process's run ID and of any run ID restored by
+// Restore) with the scaler's idle/busy state and with
+// the runners registered with GitHub:
+//
+//   - an untracked resource registered as a runner of this scale set is
+//     adopted as idle;
+//   - an untracked resource not registered with GitHub is an orphan and
+//     is destroyed;
+//   - a tracked runner whose resource is gone is forgotten;
+//   - a tracked runner whose resource was preempted is destroyed and
+//     forgotten.
+//
+// Each case but preemption must be seen in two consecutive passes
+// before it is acted on.  Resources registered with another scale set
+// are left alone.
+func (s *Scaler) Reconcile(ctx context.Context) (ReconcileResult, error) {
+	var result ReconcileResult
+	r := s.reconciler
+	if r == nil {
+		return result, nil
+	}
+	ctx, span := s.tracer.Start(ctx, "scaler.Reconcile")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s.mu.Lock()
+	runIDs := append([]string{s.runID}, s.restoredRunIDs...)
+	s.mu.Unlock()
+	var listed []engine.ListedRunner
+	for _, runID := range runIDs {
+		runners, err := r.lister.ListRunners(ctx, engine.RunnerLabels(runID))
+		if err != nil {
+			return result, fmt.Errorf("list runners: %w", err)
+		}
+		listed = append(listed, runners...)
+	}
+
+	s.mu.Lock()
+	tracked := make(map[string]string, len(s.idle)+len(s.busy)) // id -> name
+	for name, id := range s.idle {
+		tracked[id] = name
+	}
+	for name, id := range s.busy {
+		tracked[id] = name
+	}
+	retrying := make(map[string]bool, len(s.failedStarts))
+	for name := range s.failedStarts {
+		retrying[name] = true
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	exists := make(map[string]bool, len(listed))
+	preempted := make(map[string]bool)
+	untracked := make(map[string]bool)
+	for _, lr := range listed {
+		if exists[lr.ID] {
+			continue // listed under more than one run ID
+		}
+		exists[lr.ID] = true
+		if lr.Preempted {
+			preempted[lr.ID] = true
+		}
+		if _, ok := tracked[lr.ID]; ok || retrying[lr.Name] {
+			continue
+		}
+		if !r.untracked[lr.ID] {
+			untracked[lr.ID] = true // first sighting; may still be starting
+			continue
+		}
+
+		// A preempted resource cannot run a job, so it is destroyed as
+		// an orphan whether or not it is registered.
+		var ref *scaleset.RunnerReference
+		var err error
+		if !lr.Preempted {
+			ref, err = s.registry.GetRunnerByName(ctx, lr.Name)
+		}
+		switch {
+		case err != nil:
+			untracked[lr.ID] = true
+			errs = append(errs, fmt.Errorf("look up runner %s: %w", lr.Name, err))
+		case ref == nil:
+			s.logger.Warn("reconcile: destroying orphaned runner resource",
+				slog.String("name", lr.Name),
+				slog.String("id", lr.ID),
+			)
+			if err := s.destroyRunner(ctx, lr.Name, lr.ID, destroyOrphan); err != nil {
+				untracked[lr.ID] = true
+				errs = append(errs, fmt.Errorf("destroy orphan %s (%s): %w", lr.Name, lr.ID, err))
+				continue
+			}
+			result.Destroyed = append(result.Destroyed, lr.Name)
+		case ref.RunnerScaleSetID != s.scaleSetID:
+			s.logger.Warn("reconcile: untracked runner belongs to another scale set, leaving it",
+				slog.String("name", lr.Name),
+				slog.String("id", lr.ID),
+				slog.Int("scale_set_id", ref.RunnerScaleSetID),
+			)
+		default:
+			if s.adoptRunner(lr.Name, lr.ID) {
+				s.logger.Info("reconcile: adopted untracked runner",
+					slog.String("name", lr.Name),
+					slog.String("id", lr.ID),
+				)
+				result.Adopted = append(result.Adopted, lr.Name)
+			}
+		}
+	}
+
+	missing := make(map[string]bool)
+	for id, name := range tracked {
+		if preempted[id] {
+			if removed, _ := s.removeRunner(name); removed == "" {
+				continue
+			}
+			s.logger.Warn("reconcile: replacing preempted runner",
+				slog.String("name", name),
+				slog.String("id", id),
+			)
+			// On failure the resource is now untracked and is destroyed
+			// as an orphan by a later pass.
+			if err := s.destroyRunner(ctx, name, id, destroyPreempted); err != nil {
+				errs = append(errs, fmt.Errorf("destroy preempted runner %s (%s): %w", name, id, err))
+			}
+			result.Preempted = append(result.Preempted, name)
+			continue
+		}
+		if exists[id] {
+			continue
+		}
+		if !r.missing[name] {
+			missing[name] = true
+			continue
+		}
+		if removed, _ := s.removeRunner(name); removed != "" {
+			s.logger.Warn("reconcile: forgetting runner whose resource no longer exists",
+				slog.String("name", name),
+				slog.String("id", id),
+			)
+			result.Forgotten = append(result.Forgotten, name)
+		}
+	}
+	r.untracked, r.missing = untracked, missing
+
+	r.count(ctx, "destroyed", len(result.Destroyed))
+	r.count(ctx, "adopted", len(result.Adopted))
+	r.count(ctx, "forgotten", len(result.Forgotten))
+	r.count(ctx, "preempted", len(result.Preempted))
+	span.SetAttributes(
+		attribute.Int("scaleset.reconcile.destroyed", len(result.Destroyed)),
+		attribute.Int("scaleset.reconcile.adopted", len(result.Adopted)),
+		attribute.Int("scaleset.reconcile.forgotten", len(result.Forgotten)),
+		attribute.Int("scaleset.reconcile.preempted", len(result.Preempted)),
+	)
+	return result, errors.Join(errs...)
+}
+
+// count adds n to the reconcile counter for action.
+func (r *reconciler) count(ctx context.Context, action string, n int) {
+	if r.actions != nil && n > 0 {
+		r.actions.Add(ctx, int64(n), metric.WithAttributes(attribute.String("action", action)))
+	}
+}
+
+// adoptRunner tracks a runner found by the reconciler as idle, unless it
+// became tracked in the meantime.
+func (s *Scaler) adoptRunner(name, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.idle[name]; ok {
+		return false
+	}
+	if _, ok := s.busy[name]; ok {
+		return false
+	}
+	s.idle[name] = id
+	s.createdAt[name] = s.now()
+	s.runnersChangedLocked()
+	return true
+}
@@ -0,0 +1,314 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// mockListingEngine is a mockEngine implementing engine.RunnerLister.  It
+// lists every runner it started and has not destroyed, plus extra
+// resources the scaler never started.
+type mockListingEngine struct {
+	*mockEngine
+	extra     map[string]engine.ListedRunner // id -> resource
+	gone      map[string]bool                // ids whose resource vanished
+	preempted map[string]bool                // ids whose resource was preempted
+	labels    []map[string]string
+}
+
+func newMockListingEngine() *mockListingEngine {
+	return &mockListingEngine{
+		mockEngine: newMockEngine(),
+		extra:      make(map[string]engine.ListedRunner),
+		gone:       make(map[string]bool),
+		preempted:  make(map[string]bool),
+	}
+}
+
+func (m *mockListingEngine) ListRunners(_ context.Context, labels map[string]string) ([]engine.ListedRunner, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.labels = append(m.labels, labels)
+
+	destroyed := make(map[string]bool, len(m.destroyed))
+	for _, id := range m.destroyed {
+		destroyed[id] = true
+	}
+	var out []engine.ListedRunner
+	for name, id := range m.ids {
+		if !destroyed[id] && !m.gone[id] {
+			out = append(out, engine.ListedRunner{ID: id, Name: name, Preempted: m.preempted[id]})
+		}
+	}
+	for id, r := range m.extra {
+		if !destroyed[id] {
+			r.Preempted = m.preempted[id]
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockListingEngine) addResource(name, id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.extra[id] = engine.ListedRunner{ID: id, Name: name}
+}
+
+// mockRegistry is a RunnerRegistry backed by a map of runner name to
+// scale set ID.
+type mockRegistry struct {
+	mu      sync.Mutex
+	runners map[string]int
+	err     error
+}
+
+func (m *mockRegistry) GetRunnerByName(_ context.Context, name string) (*scaleset.RunnerReference, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	scaleSetID, ok := m.runners[name]
+	if !ok {
+		return nil, nil
+	}
+	return &scaleset.RunnerReference{Name: name, RunnerScaleSetID: scaleSetID}, nil
+}
+
+// reconcileTwice runs two passes, asserting the first acts on nothing,
+// and returns the second's result.
+func (s *ScalerSuite) reconcileTwice(sc *Scaler) ReconcileResult {
+	first, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), ReconcileResult{}, first, "nothing is acted on after one sighting")
+
+	second, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+	return second
+}
+
+func (s *ScalerSuite) TestReconcile_DestroysOrphans() {
+	reader := s.withManualMeter()
+	eng := newMockListingEngine()
+	eng.addResource("runner-old", "old-1")
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+
+	result := s.reconcileTwice(sc)
+	assert.Equal(s.T(), []string{"runner-old"}, result.Destroyed)
+	assert.Equal(s.T(), []string{"old-1"}, eng.getDestroyed())
+	assert.Equal(s.T(), int64(1), s.counterValue(reader, "scaleset.reconcile.runners"))
+	assert.Equal(s.T(), []map[string]string{engine.RunnerLabels("run-1"), engine.RunnerLabels("run-1")}, eng.labels)
+}
+
+func (s *ScalerSuite) TestReconcile_AdoptsRegisteredStragglers() {
+	eng := newMockListingEngine()
+	eng.addResource("runner-kept", "kept-1")
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{runners: map[string]int{"runner-kept": 1}}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+
+	result := s.reconcileTwice(sc)
+	assert.Equal(s.T(), []string{"runner-kept"}, result.Adopted)
+	assert.Equal(s.T(), "kept-1", sc.idle["runner-kept"])
+	assert.Empty(s.T(), eng.getDestroyed())
+
+	// The adopted runner goes through the normal lifecycle.
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: "runner-kept"}))
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: "runner-kept", Result: "success"}))
+	assert.Equal(s.T(), []string{"kept-1"}, eng.getDestroyed())
+}
+
+func (s *ScalerSuite) TestReconcile_LeavesOtherScaleSetsRunners() {
+	eng := newMockListingEngine()
+	eng.addResource("runner-other", "other-1")
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{runners: map[string]int{"runner-other": 2}}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+
+	result := s.reconcileTwice(sc)
+	assert.Equal(s.T(), ReconcileResult{}, result)
+	assert.Empty(s.T(), eng.getDestroyed())
+	assert.Empty(s.T(), sc.idle)
+}
+
+func (s *ScalerSuite) TestReconcile_ForgetsRunnersWithoutResource() {
+	eng := newMockListingEngine()
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+
+	var vanished string
+	for name, id := range sc.idle {
+		vanished = name
+		eng.mu.Lock()
+		eng.gone[id] = true
+		eng.mu.Unlock()
+		break
+	}
+
+	result := s.reconcileTwice(sc)
+	assert.Equal(s.T(), []string{vanished}, result.Forgotten)
+	assert.NotContains(s.T(), sc.idle, vanished)
+	assert.Equal(s.T(), 1, sc.runnerCount())
+	assert.Empty(s.T(), eng.getDestroyed())
+}
+
+func (s *ScalerSuite) TestReconcile_ReplacesPreemptedRunners() {
+	reader := s.withManualMeter()
+	eng := newMockListingEngine()
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	started := eng.getStarted()
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[0]}))
+
+	id := eng.ids[started[0]]
+	eng.mu.Lock()
+	eng.preempted[id] = true
+	eng.mu.Unlock()
+
+	// Preemption is acted on after a single pass, busy or not.
+	result, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), ReconcileResult{Preempted: []string{started[0]}}, result)
+	assert.Equal(s.T(), []string{id}, eng.getDestroyed())
+	assert.Empty(s.T(), sc.busy)
+	assert.Equal(s.T(), int64(1), s.counterValue(reader, "scaleset.reconcile.runners"))
+
+	// The next scale-up replaces it.
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
+	assert.Len(s.T(), eng.getStarted(), 3)
+}
+
+func (s *ScalerSuite) TestReconcile_DestroysPreemptedStragglers() {
+	eng := newMockListingEngine()
+	eng.addResource("runner-kept", "kept-1")
+	eng.preempted["kept-1"] = true
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{runners: map[string]int{"runner-kept": 1}}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+
+	result := s.reconcileTwice(sc)
+	assert.Equal(s.T(), []string{"runner-kept"}, result.Destroyed, "a preempted resource is not adopted")
+	assert.Equal(s.T(), []string{"kept-1"}, eng.getDestroyed())
+	assert.Empty(s.T(), sc.idle)
+}
+
+func (s *ScalerSuite) TestReconcile_IgnoresStartsInFlight() {
+	eng := newMockListingEngine()
+	eng.addResource("runner-new", "new-1")
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = &mockRegistry{}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+
+	_, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+
+	// The start completes between passes.
+	sc.mu.Lock()
+	sc.idle["runner-new"] = "new-1"
+	sc.mu.Unlock()
+
+	result, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), ReconcileResult{}, result)
+	assert.Empty(s.T(), eng.getDestroyed())
+}
+
+func (s *ScalerSuite) TestReconcile_RegistryErrorKeepsResource() {
+	eng := newMockListingEngine()
+	eng.addResource("runner-old", "old-1")
+	registry := &mockRegistry{err: errors.New("github unavailable")}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.RunnerRegistry = registry
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+
+	_, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+	_, err = sc.Reconcile(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "github unavailable")
+	assert.Empty(s.T(), eng.getDestroyed())
+
+	registry.mu.Lock()
+	registry.err = nil
+	registry.mu.Unlock()
+	result, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"runner-old"}, result.Destroyed, "still a suspect after the failed lookup")
+}
+
+func (s *ScalerSuite) TestReconcile_DisabledWithoutLister() {
+	sc := s.newScalerWith(func(c *Config) {
+		c.RunnerRegistry = &mockRegistry{}
+		c.RunID = "run-1"
+		c.ReconcileInterval = time.Minute
+	})
+	assert.Nil(s.T(), sc.reconciler)
+
+	result, err := sc.Reconcile(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), ReconcileResult{}, result)
+	sc.StartReconciler(s.ctx)()
+}
+
+func (s *ScalerSuite) TestStartReconciler_RunsPeriodically() {
+	eng := newMockListingEngine()
+	eng.addResource("runner-old", "old-1")
+	sc := New(Config{
+		ScaleSetID:        1,
+		MaxRunners:        10,
+		ScalesetClient:    s.jitGen,
+		Engine:            eng,
+		Logger:            s.logger,
+		ReconcileInterval: 5 * time.Millisecond,
+		RunnerRegistry:    &mockRegistry{},
+	})
+
+	stop := sc.StartReconciler(s.ctx)
+	assert.Eventually(s.T(), func() bool {
+		return len(eng.getDestroyed()) == 1
+	}, time.Second, 5*time.Millisecond)
+	stop()
+}
@@ -5,9 +5,14 @@ package scaler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/actions/scaleset"
@@ -17,8 +22,11 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/terrpan/scaleset/internal/audit"
 	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/retry"
 )
 
 // JitConfigGenerator abstracts the scaleset client method used by the
@@ -41,6 +49,211 @@ type Config struct {
 	ScalesetClient JitConfigGenerator
 	Engine         engine.Engine
 	Logger         *slog.Logger
+
+	// MaxConcurrentDestroys bounds how many DestroyRunner calls may be
+	// in flight at once, so that bursts of destroys (Shutdown and
+	// DestroyIdle destroy in parallel; the watchers and the admin API run
+	// next to the message handlers) do not hammer the backend's delete
+	// API.  HandleJobCompleted destroys the runner before returning and
+	// its callers deliver one message at a time, so job completions alone
+	// never need more than one slot.  Zero means unbounded.
+	MaxConcurrentDestroys int
+
+	// StartRate limits runner starts to this many per second across all
+	// scale-ups (token bucket), smoothing sustained demand into a steady
+	// provisioning rate.  Zero means unlimited.
+	StartRate float64
+
+	// StartBurst is how many runners may start back to back before
+	// StartRate applies.  Values below 1 are treated as 1.
+	StartBurst int
+
+	// SlowMessageThreshold logs a warning when processing a listener
+	// message (receipt to handler return) takes longer than this, a sign
+	// the scaler is falling behind.  Zero disables the warning; the
+	// duration is always recorded as a metric.  Messages are only timed
+	// when the session client is wrapped with InstrumentClient.
+	SlowMessageThreshold time.Duration
+
+	// NameGenerator returns candidate runner names (sequential, templated,
+	// date-based, ...).  The scaler appends the engine's name suffix and
+	// retries when a candidate collides with a runner it already tracks.
+	// It must be safe for concurrent use when StartParallelism > 1.
+	// Default: DefaultNameGenerator.
+	NameGenerator func() string
+
+	// IdempotentStarts retries a start that failed in the engine under the
+	// same runner name (the idempotency key) and JIT config on the next
+	// scale-up.  If the engine implements engine.RunnerFinder, it is asked
+	// first whether the earlier attempt created the runner after all, so a
+	// start that timed out client-side is adopted instead of duplicated.
+	IdempotentStarts bool
+
+	// StartDeadline bounds each engine start: the engine call's context
+	// is cancelled after it, and a start that still takes longer (e.g. a
+	// stuck cloud insert that ignores cancellation) is treated as failed,
+	// its partial resource destroyed, and counted in
+	// scaleset.runners.start_failures with reason "deadline" rather than
+	// recorded as a slow start.  Zero disables the deadline.
+	StartDeadline time.Duration
+
+	// CapacityProbeInterval enables capacity limiting.  After an engine
+	// start fails (quota exhausted, host full), the scaler treats the
+	// number of runners it holds as the engine's capacity and stops
+	// starting more, so min_runners or demand beyond what the engine can
+	// satisfy does not retry on every message.  Once per interval it
+	// probes with a single start and logs a warning; a successful probe
+	// lifts the cap.  Zero disables capacity limiting.
+	CapacityProbeInterval time.Duration
+
+	// RunnerWorkFolder is the runner's work folder (where jobs check out
+	// and build), passed in each runner's JIT settings.  A relative path
+	// is resolved against the runner's install directory.  Empty keeps
+	// the runner's default ("_work").
+	RunnerWorkFolder string
+
+	// StartupDurationBuckets are the bucket boundaries (seconds) of the
+	// scaleset.runner.startup.duration histogram.  Containers start in
+	// seconds and VMs in minutes, so the best buckets depend on the
+	// engine.  Default: DefaultStartupDurationBuckets.
+	StartupDurationBuckets []float64
+
+	// StartParallelism is how many runners of a scale-up are started at
+	// once.  After a start fails no further starts are begun, but those
+	// in flight finish and their failures are returned together.  Batch
+	// starts (engine.BatchStarter) are unaffected, unless the engine
+	// declines the batch (engine.BatchDecider).  Values below 1 are
+	// treated as 1 (one at a time).
+	StartParallelism int
+
+	// StartRetries is how many times a failed runner start is retried,
+	// waiting StartRetryDelay, doubling up to StartRetryMaxDelay, with
+	// jitter, before the scale-up is abandoned.  Each retry is a new
+	// start: with IdempotentStarts it reuses the failed runner's name
+	// and JIT config.  Batch starts (engine.BatchStarter) are not
+	// retried, unless the engine declines the batch
+	// (engine.BatchDecider).  Zero disables retries.
+	StartRetries int
+
+	// StartRetryDelay is the base backoff between start retries.
+	StartRetryDelay time.Duration
+
+	// StartRetryMaxDelay caps the backoff between start retries.  Zero
+	// means no cap.
+	StartRetryMaxDelay time.Duration
+
+	// ShutdownRetries is how many times Shutdown retries destroying a
+	// runner after the first attempt fails.  Zero tries once.
+	ShutdownRetries int
+
+	// ShutdownRetryDelay is the base backoff between those retries; it
+	// doubles after each retry.
+	ShutdownRetryDelay time.Duration
+
+	// AdmissionCheck runs the engine's health check (engine.Checker)
+	// before each scale-up and skips the scale-up while it fails,
+	// logging a warning and counting it in scaleset.scale.skipped,
+	// rather than failing every runner start.  Ignored for engines that
+	// do not implement engine.Checker.  Skipped scale-ups are not start
+	// failures, so they do not trigger capacity limiting or engine
+	// failover.
+	AdmissionCheck bool
+
+	// AdmissionCheckTimeout bounds each admission check.  Zero leaves it
+	// bounded only by the message context.
+	AdmissionCheckTimeout time.Duration
+
+	// MaxRunnersPerRepo caps how many runners jobs from one repository
+	// (JobStarted.RepositoryName) may hold busy at once, so that in an
+	// organization scale set one repository cannot consume all capacity.
+	// Runners are provisioned before the job, and so its repository, is
+	// known, so the cap is enforced when a job starts: a job beyond its
+	// repository's cap still runs, but is logged, counted in
+	// scaleset.jobs.repo_limited, and held back from demand, so the
+	// scaler does not provision a replacement for the runner it occupies
+	// until a job of that repository finishes.  Zero means unlimited.
+	MaxRunnersPerRepo int
+
+	// RepoRunnerLimits overrides MaxRunnersPerRepo for individual
+	// repositories, keyed by repository name.  A limit of zero exempts
+	// the repository.
+	RepoRunnerLimits map[string]int
+
+	// ReconcileInterval enables the reconciler started by
+	// StartReconciler: every interval, the resources the engine lists
+	// with RunID's labels are compared with the scaler's state and with
+	// RunnerRegistry, destroying orphans and adopting stragglers (see
+	// Reconcile).  It requires an engine implementing
+	// engine.RunnerLister and a RunnerRegistry.  Zero disables it.
+	ReconcileInterval time.Duration
+
+	// RunnerRegistry looks up runners registered with GitHub for the
+	// reconciler, the busy check and Restore.
+	RunnerRegistry RunnerRegistry
+
+	// BusyCheckInterval enables the busy check started by StartBusyCheck:
+	// every interval each busy runner is looked up in RunnerRegistry,
+	// and one that GitHub no longer lists (its job finished but the
+	// JobCompleted message was lost) is destroyed (see
+	// CheckBusyRunners).  It requires a RunnerRegistry.  Zero disables
+	// it.
+	BusyCheckInterval time.Duration
+
+	// CleanupOnStartup makes CleanupOrphans destroy the managed runner
+	// resources a crashed process left behind.  It requires an engine
+	// implementing engine.RunnerLister and a RunnerRegistry.
+	CleanupOnStartup bool
+
+	// RunID is the run ID the engine labels this process's resources
+	// with; the reconciler only considers resources carrying it (or a
+	// run ID restored by Restore).
+	RunID string
+
+	// StartupTimeout is how long a runner started by the scaler may stay
+	// idle before it is assumed to have failed to come online (bad image,
+	// lost JIT config) and is destroyed and replaced by the startup watch
+	// (see StartStartupWatch).  Runners kept idle by MinRunners for
+	// longer than this while no job arrives are replaced too, so it
+	// should comfortably exceed the engine's boot time.  Zero disables
+	// it.
+	StartupTimeout time.Duration
+
+	// MaxRunnerLifetime is how long any runner, idle or busy, may exist
+	// before the lifetime reaper destroys and replaces it (see
+	// StartLifetimeReaper), so that a hung job or a forgotten VM cannot
+	// run up costs indefinitely.  A busy runner's job is cancelled.
+	// Zero disables it.
+	MaxRunnerLifetime time.Duration
+
+	// StateStore saves the tracked runners on every change, so that a
+	// process restarted after a crash can re-adopt them with Restore.
+	// Nil disables persistence.
+	StateStore StateStore
+
+	// TelemetryAttributes are set on the instrumentation scope of the
+	// scaler's metrics and spans, e.g. to tell apart the scalers of a
+	// process running several scale sets.
+	TelemetryAttributes []attribute.KeyValue
+
+	// EngineName labels the engine metrics (start failures, engine API
+	// latency).  An engine that reports the backend it currently starts
+	// runners on (failover) is labelled with that instead.
+	EngineName string
+
+	// AuditLog records every scaling decision, runner created or
+	// destroyed (with the reason), job started or completed, and failed
+	// start, destroy or scale-up.  Nil disables it.
+	AuditLog AuditLog
+}
+
+// DefaultStartupDurationBuckets are the runner startup histogram buckets
+// (seconds) used when Config.StartupDurationBuckets is empty.
+var DefaultStartupDurationBuckets = []float64{1, 5, 10, 30, 60, 120, 300}
+
+// DefaultNameGenerator returns "runner-" followed by 8 random hex
+// characters.
+func DefaultNameGenerator() string {
+	return fmt.Sprintf("runner-%s", uuid.NewString()[:8])
 }
 
 // Scaler implements listener.Scaler.  It tracks runner state (idle vs
@@ -49,14 +262,157 @@ type Scaler struct {
 	engine         engine.Engine
 	scalesetClient JitConfigGenerator
 	scaleSetID     int
-	minRunners     int
-	maxRunners     int
+	minRunners     int    // guarded by mu; see SetRunnerLimits
+	maxRunners     int    // guarded by mu
+	nameSuffix     string // from engine.NameSuffixer, already sanitized
+	nameGenerator  func() string
+	workFolder     string
 	logger         *slog.Logger
 
 	mu   sync.Mutex
 	idle map[string]string // runner name -> engine id
 	busy map[string]string // runner name -> engine id
 
+	// idleCount and busyCount mirror len(idle) and len(busy).  They are
+	// updated under mu on every change (see runnersChangedLocked) so the
+	// gauge callbacks read them without taking mu, keeping metric
+	// collection off the scaling path.
+	idleCount atomic.Int64
+	busyCount atomic.Int64
+
+	// destroying counts in-flight DestroyRunner calls (guarded by mu) so
+	// Shutdown can drain them; destroyDone is signalled when it hits zero.
+	destroying  int
+	destroyDone *sync.Cond
+
+	// destroySem bounds concurrent DestroyRunner calls (nil = unbounded).
+	destroySem chan struct{}
+
+	// starting holds the names of runners whose start is in flight, so
+	// that concurrent starts cannot pick the same name (guarded by mu).
+	starting map[string]bool
+
+	// failedStarts holds the JIT config of each start that failed in the
+	// engine, keyed by runner name, for retry under the same name (guarded
+	// by mu; nil when IdempotentStarts is disabled).
+	failedStarts map[string]string
+
+	// draining is set by Drain; no runners are started while it is set
+	// (guarded by mu).  drained is closed once draining and no runner is
+	// busy.
+	draining bool
+	drained  chan struct{}
+
+	// paused is set by Pause; like draining, it stops scale-ups (guarded
+	// by mu).
+	paused bool
+
+	// startLimiter rate-limits runner starts (nil = unlimited).
+	startLimiter *tokenBucket
+
+	// msgReceived is when the message being processed was received
+	// (guarded by mu; zero when no message is in flight).
+	msgReceived          time.Time
+	slowMessageThreshold time.Duration
+
+	startDeadline time.Duration
+
+	startParallelism   int
+	startRetries       int
+	startRetryDelay    time.Duration
+	startRetryMaxDelay time.Duration
+
+	shutdownRetries    int
+	shutdownRetryDelay time.Duration
+
+	// admissionChecker is the engine's health check consulted before
+	// each scale-up (nil when AdmissionCheck is off or unsupported).
+	admissionChecker      engine.Checker
+	admissionCheckTimeout time.Duration
+
+	// Per-repository limits (see Config.MaxRunnersPerRepo).  busyRepo maps
+	// each busy runner to its job's repository, repoBusy counts busy
+	// runners per repository, and overRepoLimit holds the busy runners
+	// whose job started beyond its repository's limit (guarded by mu).
+	maxRunnersPerRepo int
+	repoRunnerLimits  map[string]int
+	busyRepo          map[string]string
+	repoBusy          map[string]int
+	overRepoLimit     map[string]bool
+
+	// scaleMu serializes scaling decisions made for the listener and for
+	// the startup watch; lastDesired is the desired count last reported
+	// by the listener (guarded by scaleMu).
+	scaleMu     sync.Mutex
+	lastDesired int
+
+	// desiredRunners is the runner count the last scaling decision aimed
+	// for, and queueDepth the jobs assigned to the scale set but not yet
+	// running, from the last listener statistics.  Both back gauges.
+	desiredRunners atomic.Int64
+	queueDepth     atomic.Int64
+
+	// statusDesired is the desired count of the last scaling decision and
+	// events its most recent scale events, for Status (guarded by
+	// eventsMu).
+	eventsMu      sync.Mutex
+	statusDesired int
+	events        []ScaleEvent
+
+	// startupTimeout replaces idle runners that have not picked up a job
+	// in time (0 = disabled).  startedAt holds when each runner started
+	// by this scaler was tracked, until its job starts (guarded by mu).
+	startupTimeout       time.Duration
+	startupCheckInterval time.Duration
+	startedAt            map[string]time.Time
+
+	// maxRunnerLifetime replaces runners, idle or busy, older than it
+	// (0 = disabled).  createdAt holds when each tracked runner was
+	// started, restored or adopted (guarded by mu).
+	maxRunnerLifetime     time.Duration
+	lifetimeCheckInterval time.Duration
+	createdAt             map[string]time.Time
+
+	// busyCheckInterval is zero when the busy check is disabled.
+	// busyCheckMu serializes passes; unregisteredBusy holds the busy
+	// runners the last pass found unregistered (guarded by busyCheckMu).
+	busyCheckInterval time.Duration
+	busyCheckMu       sync.Mutex
+	unregisteredBusy  map[string]bool
+
+	// cleanupOnStartup enables CleanupOrphans.
+	cleanupOnStartup bool
+
+	// reconciler is nil when ReconcileInterval is zero or unsupported.
+	reconciler *reconciler
+	registry   RunnerRegistry
+	runID      string
+
+	// stateStore loads the runners saved by a previous process, and
+	// stateWriter saves them on every change, outside mu (both nil =
+	// disabled).  runnerRunID holds the run ID of each runner adopted by Restore from
+	// another process, and restoredRunIDs every run ID found in the
+	// restored state (guarded by mu).
+	stateStore     StateStore
+	stateWriter    *stateWriter
+	runnerRunID    map[string]string
+	restoredRunIDs []string
+
+	// auditLog records decisions and actions (nil = disabled).
+	auditLog AuditLog
+
+	// capacity is the runner count the engine could hold when a start
+	// last failed, or -1 when no limit has been observed (guarded by
+	// mu).  Starts beyond it wait for capacityProbeAt.  capacityGen
+	// counts the failures that set it, so that only starts begun after
+	// the latest one can lift it.
+	capacity              int
+	capacityGen           uint64
+	capacityProbeInterval time.Duration
+	capacityProbeAt       time.Time
+	capacityWarnedAt      time.Time
+	now                   func() time.Time
+
 	// OpenTelemetry instrumentation
 	tracer trace.Tracer
 	meter  metric.Meter
@@ -65,8 +421,20 @@ type Scaler struct {
 	runnersStarted        metric.Int64Counter
 	runnersDestroyed      metric.Int64Counter
 	jobsCompleted         metric.Int64Counter
+	jobsRepoLimited       metric.Int64Counter
 	scaleEvents           metric.Int64Counter
+	scaleSkipped          metric.Int64Counter
+	runnerStartFailures   metric.Int64Counter
+	runnerStartRetries    metric.Int64Counter
+	startupTimeouts       metric.Int64Counter
+	lifetimeExpired       metric.Int64Counter
+	staleBusy             metric.Int64Counter
+	completedWithoutStart metric.Int64Counter
+	unhandledMessages     metric.Int64Counter
 	runnerStartupDuration metric.Float64Histogram
+	messageDuration       metric.Float64Histogram
+	engineLatency         metric.Float64Histogram
+	engineName            string
 }
 
 // Compile-time check.
@@ -87,8 +455,86 @@ func New(cfg Config) *Scaler {
 		logger:         cfg.Logger,
 		idle:           make(map[string]string),
 		busy:           make(map[string]string),
-		tracer:         otel.Tracer("scaleset/scaler"),
-		meter:          otel.Meter("scaleset/scaler"),
+		tracer:         otel.Tracer("scaleset/scaler", trace.WithInstrumentationAttributes(cfg.TelemetryAttributes...)),
+		meter:          otel.Meter("scaleset/scaler", metric.WithInstrumentationAttributes(cfg.TelemetryAttributes...)),
+		engineName:     cfg.EngineName,
+
+		slowMessageThreshold: cfg.SlowMessageThreshold,
+		startDeadline:        cfg.StartDeadline,
+		startParallelism:     max(cfg.StartParallelism, 1),
+		startRetries:         cfg.StartRetries,
+		startRetryDelay:      cfg.StartRetryDelay,
+		startRetryMaxDelay:   cfg.StartRetryMaxDelay,
+
+		capacity:              -1,
+		capacityProbeInterval: cfg.CapacityProbeInterval,
+		now:                   time.Now,
+		nameGenerator:         cfg.NameGenerator,
+		workFolder:            cfg.RunnerWorkFolder,
+		shutdownRetries:       cfg.ShutdownRetries,
+		shutdownRetryDelay:    cfg.ShutdownRetryDelay,
+		admissionCheckTimeout: cfg.AdmissionCheckTimeout,
+		maxRunnersPerRepo:     cfg.MaxRunnersPerRepo,
+		repoRunnerLimits:      cfg.RepoRunnerLimits,
+		busyRepo:              make(map[string]string),
+		repoBusy:              make(map[string]int),
+		overRepoLimit:         make(map[string]bool),
+		registry:              cfg.RunnerRegistry,
+		cleanupOnStartup:      cfg.CleanupOnStartup,
+		runID:                 cfg.RunID,
+		stateStore:            cfg.StateStore,
+		auditLog:              cfg.AuditLog,
+		runnerRunID:           make(map[string]string),
+		startupTimeout:        cfg.StartupTimeout,
+		startupCheckInterval:  cfg.StartupTimeout / 4,
+		startedAt:             make(map[string]time.Time),
+		maxRunnerLifetime:     cfg.MaxRunnerLifetime,
+		lifetimeCheckInterval: min(cfg.MaxRunnerLifetime/4, time.Minute),
+		createdAt:             make(map[string]time.Time),
+		starting:              make(map[string]bool),
+	}
+	if s.nameGenerator == nil {
+		s.nameGenerator = DefaultNameGenerator
+	}
+	s.destroyDone = sync.NewCond(&s.mu)
+	if checker, ok := cfg.Engine.(engine.Checker); ok && cfg.AdmissionCheck {
+		s.admissionChecker = checker
+	}
+	if ns, ok := cfg.Engine.(engine.NameSuffixer); ok {
+		s.nameSuffix = sanitizeNameSuffix(ns.RunnerNameSuffix())
+	}
+	if cfg.MaxConcurrentDestroys > 0 {
+		s.destroySem = make(chan struct{}, cfg.MaxConcurrentDestroys)
+	}
+	if cfg.StateStore != nil {
+		s.stateWriter = newStateWriter(cfg.StateStore, s.logger)
+	}
+	if cfg.IdempotentStarts {
+		s.failedStarts = make(map[string]string)
+	}
+	if cfg.StartRate > 0 {
+		s.startLimiter = newTokenBucket(cfg.StartRate, cfg.StartBurst)
+	}
+	if cfg.BusyCheckInterval > 0 {
+		if cfg.RunnerRegistry == nil {
+			cfg.Logger.Warn("no runner registry, busy check disabled")
+		} else {
+			s.busyCheckInterval = cfg.BusyCheckInterval
+		}
+	}
+	if cfg.ReconcileInterval > 0 {
+		lister, ok := cfg.Engine.(engine.RunnerLister)
+		switch {
+		case !ok:
+			cfg.Logger.Warn("engine cannot list runners, reconciliation disabled")
+		case cfg.RunnerRegistry == nil:
+			cfg.Logger.Warn("no runner registry, reconciliation disabled")
+		default:
+			s.reconciler = &reconciler{
+				lister:   lister,
+				interval: cfg.ReconcileInterval,
+			}
+		}
 	}
 
 	// Initialize metrics (errors are logged but not fatal)
@@ -120,6 +566,15 @@ func New(cfg Config) *Scaler {
 		cfg.Logger.Warn("failed to create jobsCompleted counter", slog.String("error", err.Error()))
 	}
 
+	s.jobsRepoLimited, err = s.meter.Int64Counter(
+		"scaleset.jobs.repo_limited",
+		metric.WithDescription("Total number of jobs started beyond their repository's runner limit, by repository"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create jobsRepoLimited counter", slog.String("error", err.Error()))
+	}
+
 	s.scaleEvents, err = s.meter.Int64Counter(
 		"scaleset.scale.events",
 		metric.WithDescription("Total number of scale events"),
@@ -129,26 +584,120 @@ func New(cfg Config) *Scaler {
 		cfg.Logger.Warn("failed to create scaleEvents counter", slog.String("error", err.Error()))
 	}
 
+	s.scaleSkipped, err = s.meter.Int64Counter(
+		"scaleset.scale.skipped",
+		metric.WithDescription("Scale-ups skipped by admission control, by reason"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create scaleSkipped counter", slog.String("error", err.Error()))
+	}
+
+	if s.reconciler != nil {
+		s.reconciler.actions, err = s.meter.Int64Counter(
+			"scaleset.reconcile.runners",
+			metric.WithDescription("Runners acted on by reconciliation, by action"),
+			metric.WithUnit("1"),
+		)
+		if err != nil {
+			cfg.Logger.Warn("failed to create reconcile counter", slog.String("error", err.Error()))
+		}
+	}
+
+	startupBuckets := cfg.StartupDurationBuckets
+	if len(startupBuckets) == 0 {
+		startupBuckets = DefaultStartupDurationBuckets
+	}
 	s.runnerStartupDuration, err = s.meter.Float64Histogram(
 		"scaleset.runner.startup.duration",
 		metric.WithDescription("Time to start a runner (seconds)"),
 		metric.WithUnit("s"),
-		metric.WithExplicitBucketBoundaries(1, 5, 10, 30, 60, 120, 300),
+		metric.WithExplicitBucketBoundaries(startupBuckets...),
 	)
 	if err != nil {
 		cfg.Logger.Warn("failed to create runnerStartupDuration histogram", slog.String("error", err.Error()))
 	}
 
+	s.runnerStartFailures, err = s.meter.Int64Counter(
+		"scaleset.runners.start_failures",
+		metric.WithDescription("Total number of failed runner starts, by reason, engine and error class"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create runnerStartFailures counter", slog.String("error", err.Error()))
+	}
+
+	s.runnerStartRetries, err = s.meter.Int64Counter(
+		"scaleset.runners.start_retries",
+		metric.WithDescription("Failed runner starts retried, and retries exhausted, by outcome"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create runnerStartRetries counter", slog.String("error", err.Error()))
+	}
+
+	s.startupTimeouts, err = s.meter.Int64Counter(
+		"scaleset.runners.startup_timeouts",
+		metric.WithDescription("Total number of idle runners replaced after the startup timeout"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create startupTimeouts counter", slog.String("error", err.Error()))
+	}
+
+	s.lifetimeExpired, err = s.meter.Int64Counter(
+		"scaleset.runners.lifetime_expired",
+		metric.WithDescription("Total number of runners replaced after the max runner lifetime"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create lifetimeExpired counter", slog.String("error", err.Error()))
+	}
+
+	s.staleBusy, err = s.meter.Int64Counter(
+		"scaleset.runners.stale_busy",
+		metric.WithDescription("Total number of busy runners destroyed after GitHub stopped listing them"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create staleBusy counter", slog.String("error", err.Error()))
+	}
+
+	s.completedWithoutStart, err = s.meter.Int64Counter(
+		"scaleset.runners.completed_without_start",
+		metric.WithDescription("Total number of jobs completed on a runner never seen as busy"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create completedWithoutStart counter", slog.String("error", err.Error()))
+	}
+
+	s.unhandledMessages, err = s.meter.Int64Counter(
+		"scaleset.messages.unhandled",
+		metric.WithDescription("Total number of listener messages no scaler handler receives, by type"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create unhandledMessages counter", slog.String("error", err.Error()))
+	}
+
+	s.messageDuration, err = s.meter.Float64Histogram(
+		"scaleset.message.processing.duration",
+		metric.WithDescription("Time from receiving a listener message until its handlers return (seconds)"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.1, 0.5, 1, 5, 10, 30, 60, 120),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create messageDuration histogram", slog.String("error", err.Error()))
+	}
+
 	// Register observable gauges for idle/busy runner counts
 	_, err = s.meter.Int64ObservableGauge(
 		"scaleset.runners.idle",
 		metric.WithDescription("Current number of idle runners"),
 		metric.WithUnit("1"),
 		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
-			s.mu.Lock()
-			count := len(s.idle)
-			s.mu.Unlock()
-			o.Observe(int64(count))
+			o.Observe(s.idleCount.Load())
 			return nil
 		}),
 	)
@@ -161,10 +710,7 @@ func New(cfg Config) *Scaler {
 		metric.WithDescription("Current number of busy runners"),
 		metric.WithUnit("1"),
 		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
-			s.mu.Lock()
-			count := len(s.busy)
-			s.mu.Unlock()
-			o.Observe(int64(count))
+			o.Observe(s.busyCount.Load())
 			return nil
 		}),
 	)
@@ -172,6 +718,42 @@ func New(cfg Config) *Scaler {
 		cfg.Logger.Warn("failed to create busy gauge", slog.String("error", err.Error()))
 	}
 
+	_, err = s.meter.Int64ObservableGauge(
+		"scaleset.desired_runners",
+		metric.WithDescription("Runner count targeted by the last scaling decision"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(s.desiredRunners.Load())
+			return nil
+		}),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create desired runners gauge", slog.String("error", err.Error()))
+	}
+
+	_, err = s.meter.Int64ObservableGauge(
+		"scaleset.jobs.queue_depth",
+		metric.WithDescription("Jobs assigned to the scale set but not yet running, from listener statistics"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(s.queueDepth.Load())
+			return nil
+		}),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create queue depth gauge", slog.String("error", err.Error()))
+	}
+
+	s.engineLatency, err = s.meter.Float64Histogram(
+		"scaleset.engine.api.latency",
+		metric.WithDescription("Duration of engine start and destroy calls, by operation, engine and outcome"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.1, 0.5, 1, 5, 10, 30, 60, 120, 300),
+	)
+	if err != nil {
+		cfg.Logger.Warn("failed to create engineLatency histogram", slog.String("error", err.Error()))
+	}
+
 	return s
 }
 
@@ -184,12 +766,56 @@ func New(cfg Config) *Scaler {
 func (s *Scaler) HandleDesiredRunnerCount(ctx context.Context, count int) (int, error) {
 	ctx, span := s.tracer.Start(ctx, "scaler.HandleDesiredRunnerCount")
 	defer span.End()
+	// The listener calls HandleDesiredRunnerCount last for every message.
+	defer s.messageProcessed(ctx)
+
+	s.scaleMu.Lock()
+	defer s.scaleMu.Unlock()
+	s.lastDesired = count
+	n, err := s.scaleTo(ctx, count)
+	if err != nil {
+		s.auditError("scale_up", "", "", "", err)
+	}
+	return n, err
+}
+
+// RaiseDesiredRunnerCount scales up to count desired runners for a
+// source that sees only part of the demand, such as webhooks next to the
+// message session.  Unlike HandleDesiredRunnerCount it never lowers the
+// desired count the listener last reported, which stays authoritative.
+func (s *Scaler) RaiseDesiredRunnerCount(ctx context.Context, count int) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "scaler.RaiseDesiredRunnerCount")
+	defer span.End()
+
+	s.scaleMu.Lock()
+	defer s.scaleMu.Unlock()
+	if count <= s.lastDesired {
+		return s.runnerCount(), nil
+	}
+	n, err := s.scaleTo(ctx, count)
+	if err != nil {
+		s.auditError("scale_up", "", "", "", err)
+	}
+	return n, err
+}
+
+// scaleTo starts the runners needed for count desired runners.  Callers
+// hold scaleMu, so that concurrent decisions cannot both scale up.
+func (s *Scaler) scaleTo(ctx context.Context, count int) (int, error) {
+	span := trace.SpanFromContext(ctx)
 
 	s.mu.Lock()
 	currentCount := len(s.idle) + len(s.busy)
+	draining := s.draining
+	paused := s.paused
+	// Jobs beyond their repository's limit keep their runners, but the
+	// scaler does not provision on their behalf.
+	demand := max(count-len(s.overRepoLimit), 0)
+	lim := runnerLimits{min: s.minRunners, max: s.maxRunners}
 	s.mu.Unlock()
 
-	targetCount := min(s.maxRunners, s.minRunners+count)
+	targetCount := min(lim.max, lim.min+demand)
+	s.desiredRunners.Store(int64(targetCount))
 
 	span.SetAttributes(
 		attribute.Int("scaleset.desired_count", count),
@@ -199,6 +825,7 @@ func (s *Scaler) HandleDesiredRunnerCount(ctx context.Context, count int) (int,
 
 	switch {
 	case targetCount == currentCount:
+		s.logDecision(lim, count, currentCount, targetCount, "none", 0)
 		span.SetAttributes(attribute.String("scaleset.scale_action", "none"))
 		if s.scaleEvents != nil {
 			s.scaleEvents.Add(ctx, 1, metric.WithAttributes(attribute.String("action", "none")))
@@ -209,12 +836,41 @@ func (s *Scaler) HandleDesiredRunnerCount(ctx context.Context, count int) (int,
 		)
 		return currentCount, nil
 
+	case targetCount > currentCount && draining:
+		s.logDecision(lim, count, currentCount, targetCount, "none", 0)
+		span.SetAttributes(attribute.String("scaleset.scale_action", "none"))
+		s.logger.Info("draining, not scaling up",
+			slog.Int("current", currentCount),
+			slog.Int("target", targetCount),
+		)
+		return currentCount, nil
+
+	case targetCount > currentCount && paused:
+		s.logDecision(lim, count, currentCount, targetCount, "none", 0)
+		span.SetAttributes(attribute.String("scaleset.scale_action", "none"))
+		s.logger.Info("paused, not scaling up",
+			slog.Int("current", currentCount),
+			slog.Int("target", targetCount),
+		)
+		return currentCount, nil
+
 	case targetCount > currentCount:
-		delta := targetCount - currentCount
+		if !s.admit(ctx, targetCount-currentCount) {
+			s.logDecision(lim, count, currentCount, targetCount, "none", 0)
+			span.SetAttributes(attribute.String("scaleset.scale_action", "none"))
+			return currentCount, nil
+		}
+		delta := s.capacityAllowance(currentCount, targetCount)
+		if delta == 0 {
+			s.logDecision(lim, count, currentCount, targetCount, "none", 0)
+			span.SetAttributes(attribute.String("scaleset.scale_action", "none"))
+			return currentCount, nil
+		}
 		span.SetAttributes(
 			attribute.String("scaleset.scale_action", "up"),
 			attribute.Int("scaleset.scale_delta", delta),
 		)
+		s.logDecision(lim, count, currentCount, targetCount, "up", delta)
 		if s.scaleEvents != nil {
 			s.scaleEvents.Add(ctx, 1, metric.WithAttributes(attribute.String("action", "up")))
 		}
@@ -224,10 +880,26 @@ func (s *Scaler) HandleDesiredRunnerCount(ctx context.Context, count int) (int,
 			slog.Int("delta", delta),
 		)
 
-		for range delta {
-			if _, err := s.startRunner(ctx); err != nil {
-				return s.runnerCount(), fmt.Errorf("start runner: %w", err)
+		if batch, ok := s.batchStarter(delta); ok {
+			// Failed starts are retried one by one under their
+			// original names before the rest are batched.
+			for delta > 0 && s.hasFailedStarts() {
+				if _, err := s.startRunnerWithRetry(ctx); err != nil {
+					return s.runnerCount(), fmt.Errorf("start runner: %w", err)
+				}
+				delta--
+			}
+			if delta == 0 {
+				return s.runnerCount(), nil
 			}
+			if err := s.startRunnerBatch(ctx, batch, delta); err != nil {
+				return s.runnerCount(), fmt.Errorf("start runners: %w", err)
+			}
+			return s.runnerCount(), nil
+		}
+
+		if err := s.startRunners(ctx, delta); err != nil {
+			return s.runnerCount(), fmt.Errorf("start runner: %w", err)
 		}
 		return s.runnerCount(), nil
 
@@ -236,6 +908,7 @@ func (s *Scaler) HandleDesiredRunnerCount(ctx context.Context, count int) (int,
 		// are removed on JobCompleted.  If the desired count drops,
 		// we simply stop creating new ones -- the existing ones will
 		// drain naturally.
+		s.logDecision(lim, count, currentCount, targetCount, "down", targetCount-currentCount)
 		span.SetAttributes(attribute.String("scaleset.scale_action", "down"))
 		if s.scaleEvents != nil {
 			s.scaleEvents.Add(ctx, 1, metric.WithAttributes(attribute.String("action", "down")))
@@ -248,6 +921,67 @@ func (s *Scaler) HandleDesiredRunnerCount(ctx context.Context, count int) (int,
 	}
 }
 
+// logDecision logs, at debug level, how a scaling decision was reached:
+// the target is min(max_runners, min_runners + desired), where desired
+// is the count of jobs GitHub wants runners for.  The additive
+// min_runners term surprises people, so the formula is spelled out.
+// delta is the number of runners started (up) or the shortfall left to
+// drain naturally (down, negative).  The decision is also recorded for
+// Status.
+func (s *Scaler) logDecision(lim runnerLimits, desired, current, target int, action string, delta int) {
+	s.recordDecision(desired, current, target, action, delta)
+	s.audit(audit.Event{
+		Type: audit.TypeScaleDecision,
+		Decision: &audit.Decision{
+			MinRunners: lim.min,
+			MaxRunners: lim.max,
+			Desired:    desired,
+			Current:    current,
+			Target:     target,
+			Action:     action,
+			Delta:      delta,
+		},
+	})
+	s.logger.Debug("scaling decision",
+		slog.String("formula", fmt.Sprintf(
+			"min(maxRunners=%d, minRunners=%d + desired=%d) = target=%d; current=%d; action=%s; delta=%d",
+			lim.max, lim.min, desired, target, current, action, delta)),
+		slog.Int("maxRunners", lim.max),
+		slog.Int("minRunners", lim.min),
+		slog.Int("desired", desired),
+		slog.Int("target", target),
+		slog.Int("current", current),
+		slog.String("action", action),
+		slog.Int("delta", delta),
+	)
+}
+
+// runnerLimits are the min_runners and max_runners a scaling decision
+// was made with.
+type runnerLimits struct {
+	min, max int
+}
+
+// SetRunnerLimits changes min_runners and max_runners at runtime (config
+// reload).  They take effect with the next desired runner count; runners
+// above a lowered maximum are not destroyed but drain as their jobs
+// complete.
+func (s *Scaler) SetRunnerLimits(minRunners, maxRunners int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if minRunners == s.minRunners && maxRunners == s.maxRunners {
+		return
+	}
+	s.logger.Info("runner limits changed",
+		slog.Int("min_runners", minRunners),
+		slog.Int("max_runners", maxRunners),
+		slog.Int("previous_min_runners", s.minRunners),
+		slog.Int("previous_max_runners", s.maxRunners),
+	)
+	s.minRunners = minRunners
+	s.maxRunners = maxRunners
+}
+
 // HandleJobStarted is called when GitHub assigns a job to one of our
 // runners.
 func (s *Scaler) HandleJobStarted(ctx context.Context, jobInfo *scaleset.JobStarted) error {
@@ -268,6 +1002,12 @@ func (s *Scaler) HandleJobStarted(ctx context.Context, jobInfo *scaleset.JobStar
 		slog.String("jobDisplayName", jobInfo.JobDisplayName),
 		slog.String("repo", jobInfo.RepositoryName),
 	)
+	s.audit(audit.Event{
+		Type:       audit.TypeJobStarted,
+		Runner:     jobInfo.RunnerName,
+		JobID:      jobInfo.JobID,
+		Repository: jobInfo.RepositoryName,
+	})
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -282,10 +1022,61 @@ func (s *Scaler) HandleJobStarted(ctx context.Context, jobInfo *scaleset.JobStar
 		return nil
 	}
 	delete(s.idle, jobInfo.RunnerName)
+	delete(s.startedAt, jobInfo.RunnerName)
 	s.busy[jobInfo.RunnerName] = id
+	s.runnersChangedLocked()
+	s.admitRepoJobLocked(ctx, jobInfo)
 	return nil
 }
 
+// repoLimit returns the busy runner limit for repo, or 0 if unlimited.
+func (s *Scaler) repoLimit(repo string) int {
+	if limit, ok := s.repoRunnerLimits[repo]; ok {
+		return limit
+	}
+	return s.maxRunnersPerRepo
+}
+
+// admitRepoJobLocked records the repository of a job that just started
+// on a runner and, if the repository already holds as many busy runners
+// as its limit allows, marks the runner as over the limit so scale-ups
+// leave it out of demand.  Callers hold mu.
+func (s *Scaler) admitRepoJobLocked(ctx context.Context, jobInfo *scaleset.JobStarted) {
+	repo := jobInfo.RepositoryName
+	limit := s.repoLimit(repo)
+	if repo == "" || limit <= 0 {
+		return
+	}
+	if s.repoBusy[repo] >= limit {
+		s.overRepoLimit[jobInfo.RunnerName] = true
+		s.logger.Warn("repository over its runner limit, not provisioning on its behalf",
+			slog.String("repo", repo),
+			slog.Int("limit", limit),
+			slog.Int("busy", s.repoBusy[repo]+1),
+			slog.String("runner", jobInfo.RunnerName),
+		)
+		if s.jobsRepoLimited != nil {
+			s.jobsRepoLimited.Add(ctx, 1, metric.WithAttributes(attribute.String("repo", repo)))
+		}
+	}
+	s.busyRepo[jobInfo.RunnerName] = repo
+	s.repoBusy[repo]++
+}
+
+// releaseRepoLocked forgets the repository of a runner that is no longer
+// busy.  Callers hold mu.
+func (s *Scaler) releaseRepoLocked(name string) {
+	repo, ok := s.busyRepo[name]
+	if !ok {
+		return
+	}
+	delete(s.busyRepo, name)
+	delete(s.overRepoLimit, name)
+	if s.repoBusy[repo]--; s.repoBusy[repo] <= 0 {
+		delete(s.repoBusy, repo)
+	}
+}
+
 // HandleJobCompleted is called when a job finishes.  The runner is
 // ephemeral so we tear it down immediately.
 func (s *Scaler) HandleJobCompleted(ctx context.Context, jobInfo *scaleset.JobCompleted) error {
@@ -310,16 +1101,35 @@ func (s *Scaler) HandleJobCompleted(ctx context.Context, jobInfo *scaleset.JobCo
 		slog.String("result", jobInfo.Result),
 		slog.String("repo", jobInfo.RepositoryName),
 	)
+	s.audit(audit.Event{
+		Type:       audit.TypeJobCompleted,
+		Runner:     jobInfo.RunnerName,
+		JobID:      jobInfo.JobID,
+		Repository: jobInfo.RepositoryName,
+		Result:     jobInfo.Result,
+	})
 
-	id := s.removeRunner(jobInfo.RunnerName)
+	id, wasIdle := s.removeRunner(jobInfo.RunnerName)
 	if id == "" {
 		s.logger.Warn("job completed for unknown runner",
 			slog.String("runner", jobInfo.RunnerName),
 		)
 		return nil
 	}
+	if wasIdle {
+		// The JobStarted for this runner was missed (or arrived out of
+		// order).  The runner is ephemeral and has run its job, so it is
+		// destroyed like a busy one.
+		s.logger.Warn("job completed for runner that never started a job",
+			slog.String("runner", jobInfo.RunnerName),
+			slog.String("jobID", jobInfo.JobID),
+		)
+		if s.completedWithoutStart != nil {
+			s.completedWithoutStart.Add(ctx, 1)
+		}
+	}
 
-	if err := s.engine.DestroyRunner(ctx, id); err != nil {
+	if err := s.destroyRunner(ctx, jobInfo.RunnerName, id, destroyJobCompleted); err != nil {
 		return fmt.Errorf("destroy runner %s (%s): %w", jobInfo.RunnerName, id, err)
 	}
 
@@ -330,17 +1140,246 @@ func (s *Scaler) HandleJobCompleted(ctx context.Context, jobInfo *scaleset.JobCo
 	return nil
 }
 
-// Shutdown tears down all runners via the engine.
-func (s *Scaler) Shutdown(ctx context.Context) {
-	s.logger.Info("shutting down all runners")
+// Drain stops the scaler from starting new runners and returns a channel
+// that is closed once no runner is busy.  Jobs already running finish
+// normally; idle runners are left for Shutdown.  Calling Drain again
+// returns the same channel.
+func (s *Scaler) Drain() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.draining {
+		s.draining = true
+		s.drained = make(chan struct{})
+		s.logger.Info("draining: no new runners will be started",
+			slog.Int("busy", len(s.busy)),
+		)
+		s.checkDrainedLocked()
+	}
+	return s.drained
+}
+
+// checkDrainedLocked closes drained once draining and no runner is busy.
+// s.mu must be held.
+func (s *Scaler) checkDrainedLocked() {
+	if !s.draining || len(s.busy) > 0 {
+		return
+	}
+	select {
+	case <-s.drained:
+	default:
+		s.logger.Info("drained: no busy runners")
+		close(s.drained)
+	}
+}
+
+// ShutdownSummary reports the outcome of Shutdown.
+type ShutdownSummary struct {
+	// Destroyed is how many tracked runners were destroyed.
+	Destroyed int
+	// Leaked lists, sorted, the names of tracked runners whose destroy
+	// still failed after ShutdownRetries retries.  Their resources may
+	// outlive the process.
+	Leaked []string
+	// EngineErr is the error returned by the engine's Shutdown, which
+	// removes anything the scaler does not track (e.g. a runner still
+	// starting).
+	EngineErr error
+}
+
+// Shutdown waits for in-flight destroys to finish and then tears down
+// all remaining runners.  Each tracked runner is destroyed with up to
+// ShutdownRetries retries, since a resource leaked on shutdown is never
+// cleaned up by this process; the engine's Shutdown then removes
+// anything left.  Leaked runners stay in the state store, if any, for
+// the next process to restore.  The outcome is logged as "shutdown
+// summary" and returned.
+func (s *Scaler) Shutdown(ctx context.Context) ShutdownSummary {
+	s.logger.Info("waiting for in-flight runner destroys")
+	s.mu.Lock()
+	for s.destroying > 0 {
+		s.destroyDone.Wait()
+	}
+	runners := make(map[string]string, len(s.idle)+len(s.busy))
+	maps.Copy(runners, s.idle)
+	maps.Copy(runners, s.busy)
+	s.mu.Unlock()
+
+	s.logger.Info("shutting down all runners", slog.Int("count", len(runners)))
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		summary ShutdownSummary
+	)
+	for name, id := range runners {
+		if id == "" {
+			continue // never identified by the engine; left to its Shutdown
+		}
+		wg.Go(func() {
+			err := s.destroyWithRetry(ctx, name, id)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				s.logger.Error("shutdown: failed to destroy runner",
+					slog.String("name", name),
+					slog.String("id", id),
+					slog.String("error", err.Error()),
+				)
+				summary.Leaked = append(summary.Leaked, name)
+				return
+			}
+			summary.Destroyed++
+		})
+	}
+	wg.Wait()
+	slices.Sort(summary.Leaked)
+
 	if err := s.engine.Shutdown(ctx); err != nil {
 		s.logger.Error("engine shutdown error", slog.String("error", err.Error()))
+		summary.EngineErr = err
 	}
 
 	s.mu.Lock()
+	leakedIdle := make(map[string]string)
+	leakedBusy := make(map[string]string)
+	for _, name := range summary.Leaked {
+		if id, ok := s.idle[name]; ok {
+			leakedIdle[name] = id
+		} else if id, ok := s.busy[name]; ok {
+			leakedBusy[name] = id
+		}
+	}
 	clear(s.idle)
 	clear(s.busy)
+	clear(s.busyRepo)
+	clear(s.repoBusy)
+	clear(s.overRepoLimit)
+	s.runnersChangedLocked()
+	if len(summary.Leaked) > 0 {
+		// Keep the leaked runners for the next process to restore.
+		s.queueRunnersSaveLocked(leakedIdle, leakedBusy)
+	}
+	clear(s.runnerRunID)
+	s.mu.Unlock()
+	s.flushState()
+
+	attrs := []any{
+		slog.Int("destroyed", summary.Destroyed),
+		slog.Int("leaked", len(summary.Leaked)),
+		slog.Any("leaked_runners", summary.Leaked),
+		slog.Bool("engine_error", summary.EngineErr != nil),
+	}
+	if len(summary.Leaked) > 0 || summary.EngineErr != nil {
+		s.logger.Error("shutdown summary", attrs...)
+	} else {
+		s.logger.Info("shutdown summary", attrs...)
+	}
+	return summary
+}
+
+// destroyWithRetry destroys a runner during Shutdown, retrying failures
+// with backoff.
+func (s *Scaler) destroyWithRetry(ctx context.Context, name, id string) error {
+	return retry.Do(ctx, func() error {
+		return s.destroyRunner(ctx, name, id, destroyShutdown)
+	}, retry.Options{
+		Retries: s.shutdownRetries,
+		Base:    s.shutdownRetryDelay,
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			s.logger.Warn("shutdown: retrying runner destroy",
+				slog.String("name", name),
+				slog.Int("attempt", attempt),
+				slog.Duration("wait", wait),
+				slog.String("error", err.Error()),
+			)
+		},
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Message timing
+// ---------------------------------------------------------------------------
+
+// InstrumentClient wraps the listener's session client so that each
+// message's processing time -- from GetMessage returning it until the
+// scaler's handlers are done -- is recorded in
+// scaleset.message.processing.duration.
+//
+// It also counts messages that never reach a handler in
+// scaleset.messages.unhandled: the listener only dispatches JobStarted,
+// JobCompleted and statistics, so JobAssigned entries are dropped, and
+// the SDK rejects a top-level message type it does not know with an
+// "unsupported message type" error.  Job message types unknown to the
+// SDK are discarded while parsing and cannot be seen here.
+func (s *Scaler) InstrumentClient(c listener.Client) listener.Client {
+	return &timedClient{Client: c, scaler: s}
+}
+
+type timedClient struct {
+	listener.Client
+	scaler *Scaler
+}
+
+func (c *timedClient) GetMessage(ctx context.Context, lastMessageID, maxCapacity int) (*scaleset.RunnerScaleSetMessage, error) {
+	msg, err := c.Client.GetMessage(ctx, lastMessageID, maxCapacity)
+	if msg != nil {
+		c.scaler.mu.Lock()
+		c.scaler.msgReceived = time.Now()
+		c.scaler.mu.Unlock()
+
+		if msg.Statistics != nil {
+			c.scaler.recordStatistics(msg.Statistics)
+		}
+
+		if n := len(msg.JobAssignedMessages); n > 0 {
+			c.scaler.unhandledMessage(ctx, string(scaleset.MessageTypeJobAssigned), n)
+		}
+	}
+	if err != nil {
+		if _, typ, ok := strings.Cut(err.Error(), unsupportedMessagePrefix); ok {
+			typ, _, _ = strings.Cut(typ, " ")
+			c.scaler.unhandledMessage(ctx, typ, 1)
+		}
+	}
+	return msg, err
+}
+
+// unsupportedMessagePrefix precedes the type in the SDK's error for a
+// message type it cannot parse.
+const unsupportedMessagePrefix = "unsupported message type: "
+
+// unhandledMessage records n messages of type typ that no handler sees.
+func (s *Scaler) unhandledMessage(ctx context.Context, typ string, n int) {
+	s.logger.Debug("listener message not handled by the scaler",
+		slog.String("type", typ),
+		slog.Int("count", n),
+	)
+	if s.unhandledMessages != nil {
+		s.unhandledMessages.Add(ctx, int64(n), metric.WithAttributes(attribute.String("type", typ)))
+	}
+}
+
+// messageProcessed records the processing time of the in-flight message,
+// if any, and warns when it exceeds the configured threshold.
+func (s *Scaler) messageProcessed(ctx context.Context) {
+	s.mu.Lock()
+	received := s.msgReceived
+	s.msgReceived = time.Time{}
 	s.mu.Unlock()
+	if received.IsZero() {
+		return
+	}
+
+	elapsed := time.Since(received)
+	if s.messageDuration != nil {
+		s.messageDuration.Record(ctx, elapsed.Seconds())
+	}
+	if s.slowMessageThreshold > 0 && elapsed > s.slowMessageThreshold {
+		s.logger.Warn("slow message processing, scaler may be falling behind",
+			slog.Duration("duration", elapsed),
+			slog.Duration("threshold", s.slowMessageThreshold),
+		)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -351,25 +1390,70 @@ func (s *Scaler) startRunner(ctx context.Context) (string, error) {
 	ctx, span := s.tracer.Start(ctx, "scaler.startRunner")
 	defer span.End()
 
+	if err := s.waitStartToken(ctx); err != nil {
+		return "", err
+	}
+
 	startTime := time.Now()
 
-	name := fmt.Sprintf("runner-%s", uuid.NewString()[:8])
-	span.SetAttributes(attribute.String("runner.name", name))
+	name, jitConfig, retry := s.takeFailedStart()
+	if !retry {
+		var err error
+		name, err = s.newRunnerName()
+		if err != nil {
+			return "", err
+		}
+	}
+	defer s.releaseNames(name)
 
-	jit, err := s.scalesetClient.GenerateJitRunnerConfig(
-		ctx,
-		&scaleset.RunnerScaleSetJitRunnerSetting{
-			Name: name,
-		},
-		s.scaleSetID,
+	if !retry {
+		jit, err := s.scalesetClient.GenerateJitRunnerConfig(
+			ctx,
+			s.jitRunnerSetting(name),
+			s.scaleSetID,
+		)
+		if err != nil {
+			return "", fmt.Errorf("generate JIT config for %s: %w", name, err)
+		}
+		jitConfig = jit.EncodedJITConfig
+	}
+	span.SetAttributes(
+		attribute.String("runner.name", name),
+		attribute.Bool("runner.start_retry", retry),
 	)
-	if err != nil {
-		return "", fmt.Errorf("generate JIT config for %s: %w", name, err)
+
+	capacityGen := s.capacityGeneration()
+	var id string
+	if retry {
+		var err error
+		if id, err = s.findRunner(ctx, name); err != nil {
+			s.recordFailedStart(name, jitConfig)
+			return "", fmt.Errorf("find runner %s: %w", name, err)
+		}
 	}
+	if id == "" {
+		startCtx, cancel := s.startContext(ctx)
+		engineStart := time.Now()
+		var err error
+		id, err = s.engine.StartRunner(startCtx, name, jitConfig)
+		cancel()
+		s.observeEngineCall(ctx, "start", engineStart, err)
 
-	id, err := s.engine.StartRunner(ctx, name, jit.EncodedJITConfig)
-	if err != nil {
-		return "", fmt.Errorf("engine start %s: %w", name, err)
+		if s.pastStartDeadline(engineStart) {
+			s.abandonStart(ctx, name, id)
+			s.recordFailedStart(name, jitConfig)
+			return "", fmt.Errorf("engine start %s: exceeded start deadline of %s", name, s.startDeadline)
+		}
+		if err != nil {
+			s.countStartFailure(ctx, "error", err)
+			s.auditError("start_runner", "", name, "", err)
+			s.recordFailedStart(name, jitConfig)
+			return "", fmt.Errorf("engine start %s: %w", name, err)
+		}
+	}
+	if id == "" {
+		s.rejectEmptyID(ctx, name)
+		return "", fmt.Errorf("engine start %s: empty runner id", name)
 	}
 
 	// Record startup duration
@@ -384,24 +1468,616 @@ func (s *Scaler) startRunner(ctx context.Context) (string, error) {
 
 	s.mu.Lock()
 	s.idle[name] = id
+	s.startedAt[name] = s.now()
+	s.createdAt[name] = s.now()
+	s.runnersChangedLocked()
+	s.capacityRecoveredLocked(capacityGen)
 	s.mu.Unlock()
+	s.auditCreated(name, id)
 
 	return name, nil
 }
 
-func (s *Scaler) removeRunner(name string) string {
+// startRunners starts n runners individually, up to startParallelism at
+// a time.  Once a start fails no further starts are begun; the failures
+// of the starts already in flight are joined.
+func (s *Scaler) startRunners(ctx context.Context, n int) error {
+	var (
+		g      errgroup.Group
+		mu     sync.Mutex
+		errs   []error
+		failed atomic.Bool
+	)
+	g.SetLimit(s.startParallelism)
+	for range n {
+		g.Go(func() error {
+			if failed.Load() {
+				return nil
+			}
+			if _, err := s.startRunnerWithRetry(ctx); err != nil {
+				failed.Store(true)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return errors.Join(errs...)
+}
+
+// startRunnerWithRetry calls startRunner, retrying a failure with
+// exponential backoff and jitter up to StartRetries times.  Retries and
+// exhausted retries are counted in scaleset.runners.start_retries.
+func (s *Scaler) startRunnerWithRetry(ctx context.Context) (string, error) {
+	var name string
+	err := retry.Do(ctx, func() error {
+		var err error
+		name, err = s.startRunner(ctx)
+		return err
+	}, retry.Options{
+		Retries:   s.startRetries,
+		Base:      s.startRetryDelay,
+		Max:       s.startRetryMaxDelay,
+		Jitter:    retry.JitterEqual,
+		Retryable: func(error) bool { return ctx.Err() == nil },
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			s.logger.Warn("runner start failed, retrying",
+				slog.Int("attempt", attempt),
+				slog.Duration("wait", wait),
+				slog.String("error", err.Error()),
+			)
+			s.countStartRetry(ctx, "retried")
+		},
+	})
+	if err != nil && s.startRetries > 0 && ctx.Err() == nil {
+		s.countStartRetry(ctx, "exhausted")
+	}
+	return name, err
+}
+
+// countStartRetry records a start retry outcome ("retried" or
+// "exhausted").
+func (s *Scaler) countStartRetry(ctx context.Context, outcome string) {
+	if s.runnerStartRetries != nil {
+		s.runnerStartRetries.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+	}
+}
+
+// batchStarter returns the engine as a BatchStarter if it implements one
+// and, when it is also an engine.BatchDecider, wants a batch of n runners.
+func (s *Scaler) batchStarter(n int) (engine.BatchStarter, bool) {
+	batch, ok := s.engine.(engine.BatchStarter)
+	if !ok {
+		return nil, false
+	}
+	if d, ok := s.engine.(engine.BatchDecider); ok && !d.UseBatch(n) {
+		return nil, false
+	}
+	return batch, true
+}
+
+// startRunnerBatch generates JIT configs for n runners and starts them
+// with a single BatchStarter call.  If JIT generation fails part-way,
+// the runners generated so far are still started and the JIT error is
+// returned.
+func (s *Scaler) startRunnerBatch(ctx context.Context, batch engine.BatchStarter, n int) error {
+	ctx, span := s.tracer.Start(ctx, "scaler.startRunnerBatch")
+	defer span.End()
+
+	startTime := time.Now()
+
+	specs := make([]engine.RunnerSpec, 0, n)
+	var reserved []string
+	defer func() { s.releaseNames(reserved...) }()
+	var jitErr error
+	for range n {
+		if err := s.waitStartToken(ctx); err != nil {
+			jitErr = err
+			break
+		}
+		name, err := s.newRunnerName()
+		if err != nil {
+			jitErr = err
+			break
+		}
+		reserved = append(reserved, name)
+		jit, err := s.scalesetClient.GenerateJitRunnerConfig(
+			ctx,
+			s.jitRunnerSetting(name),
+			s.scaleSetID,
+		)
+		if err != nil {
+			jitErr = fmt.Errorf("generate JIT config for %s: %w", name, err)
+			break
+		}
+		specs = append(specs, engine.RunnerSpec{Name: name, JITConfig: jit.EncodedJITConfig})
+	}
+	span.SetAttributes(attribute.Int("scaleset.batch_size", len(specs)))
+
+	if len(specs) == 0 {
+		return jitErr
+	}
+
+	capacityGen := s.capacityGeneration()
+	startCtx, cancel := s.startContext(ctx)
+	engineStart := time.Now()
+	started, err := batch.StartRunners(startCtx, specs)
+	cancel()
+	s.observeEngineCall(ctx, "start_batch", engineStart, err)
+
+	if s.pastStartDeadline(engineStart) {
+		for _, spec := range specs {
+			s.abandonStart(ctx, spec.Name, started[spec.Name])
+			s.recordFailedStart(spec.Name, spec.JITConfig)
+		}
+		return errors.Join(
+			fmt.Errorf("engine start of %d runners: exceeded start deadline of %s", len(specs), s.startDeadline),
+			jitErr,
+		)
+	}
+	if err != nil {
+		for _, spec := range specs {
+			if _, ok := started[spec.Name]; !ok {
+				s.countStartFailure(ctx, "error", err)
+				s.auditError("start_runner", "", spec.Name, "", err)
+				s.recordFailedStart(spec.Name, spec.JITConfig)
+			}
+		}
+	}
+
+	duration := time.Since(startTime).Seconds()
+	for name, id := range started {
+		if id == "" {
+			s.rejectEmptyID(ctx, name)
+			err = errors.Join(err, fmt.Errorf("engine start %s: empty runner id", name))
+			continue
+		}
+
+		if s.runnerStartupDuration != nil {
+			s.runnerStartupDuration.Record(ctx, duration)
+		}
+		if s.runnersStarted != nil {
+			s.runnersStarted.Add(ctx, 1)
+		}
+
+		s.mu.Lock()
+		s.idle[name] = id
+		s.startedAt[name] = s.now()
+		s.createdAt[name] = s.now()
+		s.runnersChangedLocked()
+		s.capacityRecoveredLocked(capacityGen)
+		s.mu.Unlock()
+		s.auditCreated(name, id)
+	}
+
+	return errors.Join(err, jitErr)
+}
+
+// waitStartToken blocks until the start rate limit allows another runner.
+func (s *Scaler) waitStartToken(ctx context.Context) error {
+	if s.startLimiter == nil {
+		return nil
+	}
+	if err := s.startLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for start rate limit: %w", err)
+	}
+	return nil
+}
+
+// maxRunnerNameLength keeps runner names valid as GCE instance names
+// (RFC 1035 label, 63 chars), which is also within GitHub's runner name
+// limit.
+const maxRunnerNameLength = 63
+
+// startContext bounds an engine start call by StartDeadline.
+func (s *Scaler) startContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.startDeadline <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.startDeadline)
+}
+
+// pastStartDeadline reports whether an engine start that began at
+// engineStart has run past StartDeadline.
+func (s *Scaler) pastStartDeadline(engineStart time.Time) bool {
+	return s.startDeadline > 0 && time.Since(engineStart) >= s.startDeadline
+}
+
+// abandonStart counts an engine start that overran StartDeadline as
+// failed and destroys whatever it left behind (see reclaimStart).
+func (s *Scaler) abandonStart(ctx context.Context, name, id string) {
+	s.countStartFailure(ctx, "deadline", context.DeadlineExceeded)
+	s.auditError("start_runner", destroyStartDeadline, name, id, context.DeadlineExceeded)
+	s.logger.Warn("runner start exceeded deadline, abandoning",
+		slog.String("name", name),
+		slog.Duration("deadline", s.startDeadline),
+	)
+
+	s.reclaimStart(ctx, name, id, destroyStartDeadline)
+}
+
+// rejectEmptyID handles a start that reported success with an empty
+// engine id.  An empty id can never be passed to DestroyRunner, so the
+// runner is not tracked: the start counts as failed, and the resource,
+// if the engine finds one by name, is destroyed.
+func (s *Scaler) rejectEmptyID(ctx context.Context, name string) {
+	err := errors.New("engine returned empty runner id")
+	s.countStartFailure(ctx, "error", err)
+	s.auditError("start_runner", destroyEmptyID, name, "", err)
+	s.logger.Error("engine returned empty runner id",
+		slog.String("name", name),
+	)
+	s.reclaimStart(ctx, name, "", destroyEmptyID)
+}
+
+// reclaimStart destroys whatever a failed start left behind, for reason.
+// id is the engine id if the start returned one; otherwise the engine is
+// asked (via engine.RunnerFinder) whether the resource exists.  Cleanup
+// failures are logged, not returned.
+func (s *Scaler) reclaimStart(ctx context.Context, name, id, reason string) {
+	if id == "" {
+		finder, ok := s.engine.(engine.RunnerFinder)
+		if !ok {
+			return
+		}
+		var err error
+		if id, err = finder.FindRunner(ctx, name); err != nil {
+			s.logger.Error("failed to look up abandoned runner",
+				slog.String("name", name),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+		if id == "" {
+			return
+		}
+	}
+	if err := s.destroyRunner(ctx, name, id, reason); err != nil {
+		s.logger.Error("failed to destroy abandoned runner",
+			slog.String("name", name),
+			slog.String("id", id),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// countStartFailure records a failed runner start with its reason
+// ("error" or "deadline"), the engine and the class of err.
+func (s *Scaler) countStartFailure(ctx context.Context, reason string, err error) {
+	if s.runnerStartFailures != nil {
+		s.runnerStartFailures.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("reason", reason),
+			attribute.String("engine", s.currentEngine()),
+			attribute.String("class", errorClass(err)),
+		))
+	}
+	s.noteCapacityLimit()
+}
+
+// noteCapacityLimit caps scale-up at the current runner count after a
+// failed start, until the next capacity probe.
+func (s *Scaler) noteCapacityLimit() {
+	if s.capacityProbeInterval <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.idle) + len(s.busy)
+	if s.capacity < 0 {
+		s.logger.Info("engine start failed, capping scale-up at current runner count",
+			slog.Int("capacity", count),
+			slog.Duration("probe_interval", s.capacityProbeInterval),
+		)
+	}
+	s.capacity = count
+	s.capacityGen++
+	s.capacityProbeAt = s.now().Add(s.capacityProbeInterval)
+}
+
+// capacityGeneration returns the current capacityGen, to be passed to
+// capacityRecoveredLocked when the start begun now succeeds.
+func (s *Scaler) capacityGeneration() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capacityGen
+}
+
+// admit reports whether a scale-up of delta runners may proceed.  With
+// admission control enabled it runs the engine's health check and
+// refuses while the check fails, logging a warning and counting the
+// skipped scale-up.
+func (s *Scaler) admit(ctx context.Context, delta int) bool {
+	if s.admissionChecker == nil {
+		return true
+	}
+	checkCtx := ctx
+	if s.admissionCheckTimeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, s.admissionCheckTimeout)
+		defer cancel()
+	}
+	if _, err := s.admissionChecker.Check(checkCtx); err != nil {
+		s.logger.Warn("engine unhealthy, skipping scale-up",
+			slog.Int("delta", delta),
+			slog.String("error", err.Error()),
+		)
+		if s.scaleSkipped != nil {
+			s.scaleSkipped.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", "engine_unhealthy")))
+		}
+		return false
+	}
+	return true
+}
+
+// capacityAllowance returns how many of the runners needed to go from
+// currentCount to targetCount may be started under the observed
+// capacity: none beyond it, plus one probe per CapacityProbeInterval.
+// When the scale-up is cut short it logs a warning, at most once per
+// interval.
+func (s *Scaler) capacityAllowance(currentCount, targetCount int) int {
+	delta := targetCount - currentCount
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.capacity < 0 {
+		return delta
+	}
+
+	now := s.now()
+	allowed := max(0, s.capacity-currentCount)
+	if !now.Before(s.capacityProbeAt) {
+		allowed++
+		s.capacityProbeAt = now.Add(s.capacityProbeInterval)
+	}
+	allowed = min(allowed, delta)
+
+	if allowed < delta && now.Sub(s.capacityWarnedAt) >= s.capacityProbeInterval {
+		s.capacityWarnedAt = now
+		attrs := []any{
+			slog.Int("capacity", s.capacity),
+			slog.Int("target", targetCount),
+			slog.Time("next_probe", s.capacityProbeAt),
+		}
+		if s.minRunners > s.capacity {
+			s.logger.Warn("min_runners cannot be met: engine capacity reached",
+				append(attrs, slog.Int("min_runners", s.minRunners))...)
+		} else {
+			s.logger.Warn("engine capacity reached, deferring scale-up", attrs...)
+		}
+	}
+	return allowed
+}
+
+// capacityRecoveredLocked lifts the capacity cap once a start takes the
+// runner count past it.  gen is the capacityGen from when the start
+// began: a start begun before the latest failure only shows that the
+// engine held as many runners as it does now, so it raises the cap to
+// the runner count instead of lifting it.  s.mu must be held.
+func (s *Scaler) capacityRecoveredLocked(gen uint64) {
+	count := len(s.idle) + len(s.busy)
+	if s.capacity < 0 || count <= s.capacity {
+		return
+	}
+	if gen != s.capacityGen {
+		s.capacity = count
+		return
+	}
+	s.logger.Info("engine capacity recovered",
+		slog.Int("previous_capacity", s.capacity),
+	)
+	s.capacity = -1
+	s.capacityWarnedAt = time.Time{}
+}
+
+// recordFailedStart keeps a start that failed in the engine for retry
+// under the same name when IdempotentStarts is enabled.
+func (s *Scaler) recordFailedStart(name, jitConfig string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failedStarts == nil {
+		return
+	}
+	s.failedStarts[name] = jitConfig
+}
+
+// hasFailedStarts reports whether any failed start awaits retry.
+func (s *Scaler) hasFailedStarts() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.failedStarts) > 0
+}
+
+// takeFailedStart removes and returns a failed start awaiting retry,
+// reserving its name like newRunnerName.
+func (s *Scaler) takeFailedStart() (name, jitConfig string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, jitConfig = range s.failedStarts {
+		delete(s.failedStarts, name)
+		s.starting[name] = true
+		return name, jitConfig, true
+	}
+	return "", "", false
+}
+
+// findRunner asks the engine whether a runner started under name already
+// exists.  Engines without engine.RunnerFinder report none; their
+// backends reject a second resource with the same name anyway.
+func (s *Scaler) findRunner(ctx context.Context, name string) (string, error) {
+	finder, ok := s.engine.(engine.RunnerFinder)
+	if !ok {
+		return "", nil
+	}
+	id, err := finder.FindRunner(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		s.logger.Info("adopted runner from earlier failed start",
+			slog.String("name", name),
+			slog.String("id", id),
+		)
+	}
+	return id, nil
+}
+
+// maxNameAttempts bounds how many colliding names the generator may
+// return before a start is abandoned.
+const maxNameAttempts = 5
+
+// jitRunnerSetting returns the JIT runner settings for a runner named
+// name.
+func (s *Scaler) jitRunnerSetting(name string) *scaleset.RunnerScaleSetJitRunnerSetting {
+	return &scaleset.RunnerScaleSetJitRunnerSetting{
+		Name:       name,
+		WorkFolder: s.workFolder,
+	}
+}
+
+// newRunnerName returns a runner name from the name generator that is not
+// already tracked (idle or busy) nor being started, with the engine's
+// name suffix appended.  The name is reserved as being started until
+// the caller releases it with releaseNames.
+func (s *Scaler) newRunnerName() (string, error) {
+	for range maxNameAttempts {
+		name := s.withNameSuffix(s.nameGenerator())
+
+		s.mu.Lock()
+		_, idle := s.idle[name]
+		_, busy := s.busy[name]
+		_, failed := s.failedStarts[name]
+		inUse := idle || busy || failed || s.starting[name]
+		if !inUse {
+			s.starting[name] = true
+		}
+		s.mu.Unlock()
+		if !inUse {
+			return name, nil
+		}
+
+		s.logger.Warn("generated runner name already in use, retrying",
+			slog.String("name", name),
+		)
+	}
+	return "", fmt.Errorf("no unique runner name after %d attempts", maxNameAttempts)
+}
+
+// releaseNames ends the reservation of names taken by newRunnerName or
+// takeFailedStart, once their start is over.
+func (s *Scaler) releaseNames(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		delete(s.starting, name)
+	}
+}
+
+// withNameSuffix appends the engine's name suffix, truncating the suffix
+// (never the generated name) to keep within maxRunnerNameLength.
+func (s *Scaler) withNameSuffix(name string) string {
+	if s.nameSuffix == "" {
+		return name
+	}
+	room := maxRunnerNameLength - len(name) - 1
+	if room <= 0 {
+		return name
+	}
+	suffix := s.nameSuffix
+	if len(suffix) > room {
+		suffix = strings.TrimRight(suffix[:room], "-")
+	}
+	if suffix == "" {
+		return name
+	}
+	return name + "-" + suffix
+}
+
+// sanitizeNameSuffix lowercases suffix and replaces anything other than
+// [a-z0-9-] with "-", trimming leading and trailing dashes.
+func sanitizeNameSuffix(suffix string) string {
+	b := []byte(strings.ToLower(suffix))
+	for i, c := range b {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			b[i] = '-'
+		}
+	}
+	return strings.Trim(string(b), "-")
+}
+
+// destroyRunner calls the engine's DestroyRunner for the runner name
+// with engine id id, waiting for a free slot when MaxConcurrentDestroys
+// is set.  The outcome is recorded in the audit log with reason.
+func (s *Scaler) destroyRunner(ctx context.Context, name, id, reason string) error {
+	s.mu.Lock()
+	s.destroying++
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.destroying--
+		if s.destroying == 0 {
+			s.destroyDone.Broadcast()
+		}
+		s.mu.Unlock()
+	}()
+
+	if s.destroySem != nil {
+		select {
+		case s.destroySem <- struct{}{}:
+			defer func() { <-s.destroySem }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	destroyStart := time.Now()
+	err := s.engine.DestroyRunner(ctx, id)
+	s.observeEngineCall(ctx, "destroy", destroyStart, err)
+	if err != nil {
+		s.auditError("destroy_runner", reason, name, id, err)
+		return err
+	}
+	s.audit(audit.Event{Type: audit.TypeRunnerDestroyed, Runner: name, RunnerID: id, Reason: reason})
+	return nil
+}
+
+// removeRunner forgets the named runner and returns its id, or "" if it
+// is unknown.  wasIdle reports that it was still idle, i.e. its job
+// completed without a JobStarted being seen.
+func (s *Scaler) removeRunner(name string) (id string, wasIdle bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if id, ok := s.busy[name]; ok {
 		delete(s.busy, name)
-		return id
+		delete(s.createdAt, name)
+		delete(s.runnerRunID, name)
+		s.releaseRepoLocked(name)
+		s.runnersChangedLocked()
+		s.checkDrainedLocked()
+		return id, false
 	}
 	if id, ok := s.idle[name]; ok {
 		delete(s.idle, name)
-		return id
+		delete(s.startedAt, name)
+		delete(s.createdAt, name)
+		delete(s.runnerRunID, name)
+		s.runnersChangedLocked()
+		return id, true
 	}
-	return ""
+	return "", false
+}
+
+// runnersChangedLocked publishes len(idle) and len(busy) to idleCount
+// and busyCount and queues a snapshot of the runners for the state
+// writer, if any.  Callers hold mu and call it after every change to
+// either map.
+func (s *Scaler) runnersChangedLocked() {
+	s.idleCount.Store(int64(len(s.idle)))
+	s.busyCount.Store(int64(len(s.busy)))
+	s.queueStateSaveLocked()
 }
 
 func (s *Scaler) runnerCount() int {
@@ -1,18 +1,37 @@
 package scaler
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/actions/scaleset"
+	"github.com/actions/scaleset/listener"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/engine/fake"
+	"github.com/terrpan/scaleset/internal/health"
 )
 
 // ---------------------------------------------------------------------------
@@ -28,7 +47,25 @@ type mockEngine struct {
 
 	startErr   error // if set, StartRunner returns this error
 	destroyErr error // if set, DestroyRunner returns this error
+	emptyID    bool  // if set, StartRunner returns ("", nil)
 	nextID     int   // auto-incrementing ID
+
+	startDelay     time.Duration // if set, StartRunner takes this long
+	startHonorsCtx bool          // if set, startDelay is cut short by ctx
+	destroyDelay   time.Duration // if set, DestroyRunner sleeps this long
+	inFlight       int           // DestroyRunner calls currently running
+	maxInFlight    int           // high-water mark of inFlight
+
+	// destroyFailures makes DestroyRunner fail for an id this many more
+	// times before succeeding (transient failures).
+	destroyFailures map[string]int
+
+	// startFailures makes StartRunner fail this many more times before
+	// succeeding (transient failures).
+	startFailures int
+
+	startInFlight    int // StartRunner calls currently running
+	maxStartInFlight int // high-water mark of startInFlight
 }
 
 func newMockEngine() *mockEngine {
@@ -37,28 +74,72 @@ func newMockEngine() *mockEngine {
 	}
 }
 
-func (m *mockEngine) StartRunner(_ context.Context, name string, _ string) (string, error) {
+func (m *mockEngine) StartRunner(ctx context.Context, name string, _ string) (string, error) {
+	m.mu.Lock()
+	delay, honorsCtx := m.startDelay, m.startHonorsCtx
+	m.startInFlight++
+	m.maxStartInFlight = max(m.maxStartInFlight, m.startInFlight)
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.startInFlight--
+		m.mu.Unlock()
+	}()
+
+	if delay > 0 {
+		if honorsCtx {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(delay):
+			}
+		} else {
+			time.Sleep(delay)
+		}
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if m.startErr != nil {
 		return "", m.startErr
 	}
+	if m.startFailures > 0 {
+		m.startFailures--
+		return "", errors.New("transient start failure")
+	}
 
 	m.nextID++
 	id := fmt.Sprintf("mock-id-%d", m.nextID)
+	if m.emptyID {
+		id = ""
+	}
 	m.started = append(m.started, name)
 	m.ids[name] = id
 	return id, nil
 }
 
 func (m *mockEngine) DestroyRunner(_ context.Context, id string) error {
+	m.mu.Lock()
+	m.inFlight++
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+	delay := m.destroyDelay
+	m.mu.Unlock()
+
+	// Sleep outside the lock so concurrent destroys can overlap.
+	time.Sleep(delay)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.inFlight--
 
 	if m.destroyErr != nil {
 		return m.destroyErr
 	}
+	if m.destroyFailures[id] > 0 {
+		m.destroyFailures[id]--
+		return fmt.Errorf("transient destroy failure for %s", id)
+	}
 
 	m.destroyed = append(m.destroyed, id)
 	return nil
@@ -101,14 +182,38 @@ func (m *mockEngine) getStarted() []string {
 	return result
 }
 
+// mockBatchEngine is a mockEngine that also implements
+// engine.BatchStarter.
+type mockBatchEngine struct {
+	*mockEngine
+	batches [][]engine.RunnerSpec
+}
+
+func (m *mockBatchEngine) StartRunners(ctx context.Context, specs []engine.RunnerSpec) (map[string]string, error) {
+	m.mu.Lock()
+	m.batches = append(m.batches, specs)
+	m.mu.Unlock()
+
+	started := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		id, err := m.StartRunner(ctx, spec.Name, spec.JITConfig)
+		if err != nil {
+			return started, err
+		}
+		started[spec.Name] = id
+	}
+	return started, nil
+}
+
 // ---------------------------------------------------------------------------
 // Mock JIT config generator
 // ---------------------------------------------------------------------------
 
 type mockJitGenerator struct {
-	mu    sync.Mutex
-	calls int
-	err   error
+	mu       sync.Mutex
+	calls    int
+	err      error
+	settings []scaleset.RunnerScaleSetJitRunnerSetting
 }
 
 func (m *mockJitGenerator) GenerateJitRunnerConfig(
@@ -124,6 +229,7 @@ func (m *mockJitGenerator) GenerateJitRunnerConfig(
 	}
 
 	m.calls++
+	m.settings = append(m.settings, *setting)
 	return &scaleset.RunnerScaleSetJitRunnerConfig{
 		EncodedJITConfig: fmt.Sprintf("jit-config-for-%s", setting.Name),
 	}, nil
@@ -149,14 +255,32 @@ func (s *ScalerSuite) SetupTest() {
 }
 
 func (s *ScalerSuite) newScaler(min, max int) *Scaler {
-	return New(Config{
+	return s.newScalerWith(func(c *Config) {
+		c.MinRunners = min
+		c.MaxRunners = max
+	})
+}
+
+// newScalerWith returns a scaler of up to ten runners on the suite's
+// engine, with the fields a test cares about set by configure.
+func (s *ScalerSuite) newScalerWith(configure func(*Config)) *Scaler {
+	cfg := Config{
 		ScaleSetID:     1,
-		MinRunners:     min,
-		MaxRunners:     max,
+		MaxRunners:     10,
 		ScalesetClient: s.jitGen,
 		Engine:         s.engine,
 		Logger:         s.logger,
-	})
+	}
+	configure(&cfg)
+	return New(cfg)
+}
+
+// stopClock freezes sc's clock and returns the time it reports, for the
+// test to advance.
+func stopClock(sc *Scaler) *time.Time {
+	now := time.Now()
+	sc.now = func() time.Time { return now }
+	return &now
 }
 
 func TestScalerSuite(t *testing.T) {
@@ -228,6 +352,26 @@ func (s *ScalerSuite) TestScaleUp_MaxCapsMinPlusDesired() {
 	assert.Equal(s.T(), 5, s.engine.startedCount())
 }
 
+func (s *ScalerSuite) TestSetRunnerLimits() {
+	sc := s.newScaler(0, 2)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, s.engine.startedCount())
+
+	// Raised limits apply to the next desired count: min(4, 1+5) = 4
+	sc.SetRunnerLimits(1, 4)
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 4, count)
+
+	// A lowered max destroys nothing; the runners drain as jobs complete.
+	sc.SetRunnerLimits(0, 1)
+	count, err = sc.HandleDesiredRunnerCount(s.ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 4, count)
+	assert.Equal(s.T(), 0, s.engine.destroyedCount())
+}
+
 // ---------------------------------------------------------------------------
 // Scale-down tests
 // ---------------------------------------------------------------------------
@@ -264,6 +408,99 @@ func (s *ScalerSuite) TestNoScaling_WhenAtTarget() {
 	assert.Equal(s.T(), 3, s.engine.startedCount()) // still 3, no new starts
 }
 
+func (s *ScalerSuite) TestRaiseDesiredRunnerCount_NeverLowers() {
+	sc := s.newScaler(0, 10)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+
+	count, err := sc.RaiseDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+	assert.Equal(s.T(), 3, sc.lastDesired, "the listener's count stays authoritative")
+
+	count, err = sc.RaiseDesiredRunnerCount(s.ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 5, count)
+	assert.Equal(s.T(), 5, s.engine.startedCount())
+	assert.Equal(s.T(), 3, sc.lastDesired)
+}
+
+// ---------------------------------------------------------------------------
+// Scaling decision log
+// ---------------------------------------------------------------------------
+
+// decisions returns the "scaling decision" debug records logged to buf
+// by a JSON handler.
+func (s *ScalerSuite) decisions(buf *bytes.Buffer) []map[string]any {
+	var out []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var rec map[string]any
+		require.NoError(s.T(), dec.Decode(&rec))
+		if rec["msg"] == "scaling decision" {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+func (s *ScalerSuite) TestScalingDecision_LogsFormula() {
+	var buf bytes.Buffer
+	sc := New(Config{
+		ScaleSetID:     1,
+		MinRunners:     2,
+		MaxRunners:     5,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1) // up 3
+	require.NoError(s.T(), err)
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 1) // none
+	require.NoError(s.T(), err)
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 0) // down
+	require.NoError(s.T(), err)
+
+	recs := s.decisions(&buf)
+	require.Len(s.T(), recs, 3)
+
+	up := recs[0]
+	assert.Equal(s.T(),
+		"min(maxRunners=5, minRunners=2 + desired=1) = target=3; current=0; action=up; delta=3",
+		up["formula"])
+	assert.EqualValues(s.T(), 5, up["maxRunners"])
+	assert.EqualValues(s.T(), 2, up["minRunners"])
+	assert.EqualValues(s.T(), 1, up["desired"])
+	assert.EqualValues(s.T(), 3, up["target"])
+	assert.EqualValues(s.T(), 0, up["current"])
+	assert.Equal(s.T(), "up", up["action"])
+	assert.EqualValues(s.T(), 3, up["delta"])
+
+	assert.Equal(s.T(), "none", recs[1]["action"])
+	assert.EqualValues(s.T(), 0, recs[1]["delta"])
+
+	assert.Equal(s.T(),
+		"min(maxRunners=5, minRunners=2 + desired=0) = target=2; current=3; action=down; delta=-1",
+		recs[2]["formula"])
+}
+
+func (s *ScalerSuite) TestScalingDecision_NotLoggedAboveDebug() {
+	var buf bytes.Buffer
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     5,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         slog.New(slog.NewJSONHandler(&buf, nil)),
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), s.decisions(&buf))
+}
+
 // ---------------------------------------------------------------------------
 // Job lifecycle tests
 // ---------------------------------------------------------------------------
@@ -330,6 +567,56 @@ func (s *ScalerSuite) TestHandleJobCompleted_DestroysRunner() {
 	assert.Equal(s.T(), 1, s.engine.destroyedCount())
 }
 
+func (s *ScalerSuite) TestHandleJobCompleted_IdleRunnerIsAnomaly() {
+	reader := s.withManualMeter()
+	var buf bytes.Buffer
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         slog.New(slog.NewTextHandler(&buf, nil)),
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	var runnerName string
+	for name := range sc.idle {
+		runnerName = name
+	}
+
+	// No JobStarted: the job completes while the runner is still idle.
+	err = sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{
+		RunnerName: runnerName,
+		Result:     "success",
+	})
+	require.NoError(s.T(), err)
+
+	assert.Empty(s.T(), sc.idle)
+	assert.Equal(s.T(), 1, s.engine.destroyedCount())
+	assert.Contains(s.T(), buf.String(), "job completed for runner that never started a job")
+	assert.Equal(s.T(), int64(1), s.counterValue(reader, "scaleset.runners.completed_without_start"))
+}
+
+func (s *ScalerSuite) TestHandleJobCompleted_BusyRunnerIsNotAnomaly() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(0, 10)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	var runnerName string
+	for name := range sc.idle {
+		runnerName = name
+	}
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: runnerName}))
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: runnerName}))
+
+	assert.Equal(s.T(), 1, s.engine.destroyedCount())
+	assert.Zero(s.T(), s.counterValue(reader, "scaleset.runners.completed_without_start"))
+}
+
 func (s *ScalerSuite) TestHandleJobCompleted_UnknownRunner() {
 	sc := s.newScaler(0, 10)
 
@@ -478,118 +765,1870 @@ func (s *ScalerSuite) TestConcurrentScaling() {
 	assert.Equal(s.T(), 0, sc2.runnerCount())
 }
 
-// ---------------------------------------------------------------------------
-// Shutdown
-// ---------------------------------------------------------------------------
-
-func (s *ScalerSuite) TestShutdown_CleansUpAll() {
-	sc := s.newScaler(0, 10)
+func (s *ScalerSuite) TestConcurrentDestroys_RespectsBound() {
+	const N = 40
+	const bound = 4
+	s.engine.destroyDelay = 10 * time.Millisecond
+	sc := New(Config{
+		ScaleSetID:            1,
+		MinRunners:            0,
+		MaxRunners:            N,
+		ScalesetClient:        s.jitGen,
+		Engine:                s.engine,
+		Logger:                s.logger,
+		MaxConcurrentDestroys: bound,
+	})
 
-	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, N)
 	require.NoError(s.T(), err)
 
-	// Move one to busy
-	var firstRunner string
+	runners := make([]string, 0, N)
 	for name := range sc.idle {
-		firstRunner = name
-		break
+		runners = append(runners, name)
 	}
-	_ = sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: firstRunner})
-
-	assert.Equal(s.T(), 2, len(sc.idle))
-	assert.Equal(s.T(), 1, len(sc.busy))
 
-	sc.Shutdown(s.ctx)
+	// Simulate a matrix build finishing: every job completes at once.
+	var wg sync.WaitGroup
+	for _, name := range runners {
+		wg.Add(1)
+		go func(n string) {
+			defer wg.Done()
+			err := sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{
+				RunnerName: n,
+				Result:     "success",
+			})
+			assert.NoError(s.T(), err)
+		}(name)
+	}
+	wg.Wait()
 
-	assert.True(s.T(), s.engine.shutdown)
-	assert.Equal(s.T(), 0, len(sc.idle))
-	assert.Equal(s.T(), 0, len(sc.busy))
+	assert.Equal(s.T(), N, s.engine.destroyedCount())
+	s.engine.mu.Lock()
+	maxInFlight := s.engine.maxInFlight
+	s.engine.mu.Unlock()
+	assert.LessOrEqual(s.T(), maxInFlight, bound)
+	assert.Greater(s.T(), maxInFlight, 1, "destroys should overlap up to the bound")
 }
 
-// ---------------------------------------------------------------------------
-// Error handling
-// ---------------------------------------------------------------------------
+func (s *ScalerSuite) TestShutdown_DrainsInFlightDestroys() {
+	s.engine.destroyDelay = 50 * time.Millisecond
+	sc := New(Config{
+		ScaleSetID:            1,
+		MaxRunners:            10,
+		ScalesetClient:        s.jitGen,
+		Engine:                s.engine,
+		Logger:                s.logger,
+		MaxConcurrentDestroys: 1,
+	})
 
-func (s *ScalerSuite) TestScaleUp_EngineFailure() {
-	s.engine.startErr = fmt.Errorf("docker daemon unavailable")
-	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
 
-	count, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	runners := make([]string, 0, 2)
+	for name := range sc.idle {
+		runners = append(runners, name)
+	}
 
-	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "docker daemon unavailable")
-	// Should return partial count (0 since engine fails on first attempt)
-	assert.Equal(s.T(), 0, count)
-}
+	var wg sync.WaitGroup
+	for _, name := range runners {
+		wg.Add(1)
+		go func(n string) {
+			defer wg.Done()
+			_ = sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: n})
+		}(name)
+	}
 
-func (s *ScalerSuite) TestScaleUp_EngineFailureMidScale() {
-	sc := s.newScaler(0, 10)
+	// Give the destroys a moment to start before shutting down.
+	time.Sleep(10 * time.Millisecond)
+	sc.Shutdown(s.ctx)
 
-	// Let 2 runners succeed, then fail on the 3rd
-	callCount := 0
-	origStart := s.engine.StartRunner
-	_ = origStart // avoid unused
-	s.engine.startErr = nil
+	// Shutdown must not return before every in-flight destroy finished.
+	assert.Equal(s.T(), 2, s.engine.destroyedCount())
+	assert.True(s.T(), s.engine.shutdown)
+	wg.Wait()
+}
 
-	// We need a more nuanced mock. Let's just do two separate
-	// HandleDesiredRunnerCount calls to simulate partial success.
-	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
-	require.NoError(s.T(), err)
-	assert.Equal(s.T(), 2, sc.runnerCount())
-	_ = callCount
+func (s *ScalerSuite) TestScaleUp_UsesBatchStarter() {
+	batch := &mockBatchEngine{mockEngine: s.engine}
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         batch,
+		Logger:         s.logger,
+	})
 
-	// Now make engine fail
-	s.engine.startErr = fmt.Errorf("out of capacity")
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 4)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 4, count)
+	assert.Equal(s.T(), 4, len(sc.idle))
+
+	// All four runners went through one StartRunners call, each with
+	// its own JIT config.
+	require.Len(s.T(), batch.batches, 1)
+	require.Len(s.T(), batch.batches[0], 4)
+	for _, spec := range batch.batches[0] {
+		assert.Equal(s.T(), "jit-config-for-"+spec.Name, spec.JITConfig)
+		assert.Contains(s.T(), sc.idle, spec.Name)
+	}
+}
 
-	// Try to scale up by 3 more (current=2, target=min(10,0+5)=5, delta=3)
-	count, err := sc.HandleDesiredRunnerCount(s.ctx, 5)
-	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "out of capacity")
-	// Returns whatever count we managed (still 2)
-	assert.Equal(s.T(), 2, count)
+// mockDecidingBatchEngine is a mockBatchEngine that also implements
+// engine.BatchDecider, batching only scale-ups of at least min runners.
+type mockDecidingBatchEngine struct {
+	*mockBatchEngine
+	min int
 }
 
-func (s *ScalerSuite) TestScaleUp_JitConfigFailure() {
-	s.jitGen.err = fmt.Errorf("github API rate limited")
-	sc := s.newScaler(0, 10)
+func (m *mockDecidingBatchEngine) UseBatch(n int) bool { return n >= m.min }
+
+func (s *ScalerSuite) TestScaleUp_BatchDeclinedStartsInParallel() {
+	s.engine.startDelay = 20 * time.Millisecond
+	batch := &mockDecidingBatchEngine{mockBatchEngine: &mockBatchEngine{mockEngine: s.engine}, min: 5}
+	sc := New(Config{
+		ScaleSetID:       1,
+		MaxRunners:       10,
+		ScalesetClient:   s.jitGen,
+		Engine:           batch,
+		Logger:           s.logger,
+		StartParallelism: 3,
+	})
 
-	count, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 4)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), batch.batches, "a declined batch is started runner by runner")
+	assert.Equal(s.T(), 4, s.engine.startedCount())
+	assert.Greater(s.T(), s.engine.maxStartInFlight, 1)
 
-	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "github API rate limited")
-	assert.Equal(s.T(), 0, count)
-	// Engine should never have been called since JIT config failed
-	assert.Equal(s.T(), 0, s.engine.startedCount())
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 9)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), batch.batches, 1)
+	assert.Len(s.T(), batch.batches[0], 5)
 }
 
-func (s *ScalerSuite) TestHandleJobCompleted_DestroyError() {
-	s.engine.destroyErr = fmt.Errorf("container already gone")
-	sc := s.newScaler(0, 10)
+// ---------------------------------------------------------------------------
+// Start rate limit
+// ---------------------------------------------------------------------------
 
-	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+func (s *ScalerSuite) TestStartRate_ShapesSustainedDemand() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     20,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		StartRate:      50, // one start every 20ms after the burst
+		StartBurst:     2,
+	})
+
+	start := time.Now()
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 4)
 	require.NoError(s.T(), err)
+	// Burst of 2, then 2 more at 20ms intervals.
+	assert.GreaterOrEqual(s.T(), time.Since(start), 40*time.Millisecond)
 
-	var runnerName string
-	for name := range sc.idle {
-		runnerName = name
-	}
+	// Sustained demand keeps paying the steady rate: the burst has not
+	// had time to refill.
+	start = time.Now()
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 8)
+	require.NoError(s.T(), err)
+	assert.GreaterOrEqual(s.T(), time.Since(start), 70*time.Millisecond)
+	assert.Len(s.T(), sc.idle, 8)
+}
 
-	_ = sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: runnerName})
+func (s *ScalerSuite) TestStartRate_UnlimitedByDefault() {
+	start := time.Now()
+	_, err := s.newScaler(0, 20).HandleDesiredRunnerCount(s.ctx, 20)
+	require.NoError(s.T(), err)
+	assert.Less(s.T(), time.Since(start), time.Second)
+}
 
-	err = sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{
-		RunnerName: runnerName,
-		Result:     "success",
+func (s *ScalerSuite) TestStartRate_CancelledWhileWaiting() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		StartRate:      0.01,
+		StartBurst:     1,
 	})
-	assert.Error(s.T(), err)
-	assert.Contains(s.T(), err.Error(), "container already gone")
+	ctx, cancel := context.WithTimeout(s.ctx, 50*time.Millisecond)
+	defer cancel()
+
+	count, err := sc.HandleDesiredRunnerCount(ctx, 3)
+	require.Error(s.T(), err)
+	assert.ErrorIs(s.T(), err, context.DeadlineExceeded)
+	assert.Equal(s.T(), 1, count, "the burst token still starts one runner")
 }
 
 // ---------------------------------------------------------------------------
-// One-runner-per-job correctness tests
+// Message processing timing
 // ---------------------------------------------------------------------------
 
-func (s *ScalerSuite) TestOneRunnerPerJob_Sequential() {
-	// Test the core invariant: exactly one runner is created and destroyed
+// fakeSessionClient returns msg from every GetMessage call.
+type fakeSessionClient struct {
+	listener.Client
+	msg *scaleset.RunnerScaleSetMessage
+	err error
+}
+
+func (f *fakeSessionClient) GetMessage(context.Context, int, int) (*scaleset.RunnerScaleSetMessage, error) {
+	return f.msg, f.err
+}
+
+// withManualMeter installs a ManualReader-backed global meter provider for
+// the duration of the test.
+func (s *ScalerSuite) withManualMeter() *sdkmetric.ManualReader {
+	reader := sdkmetric.NewManualReader()
+	prev := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	s.T().Cleanup(func() { otel.SetMeterProvider(prev) })
+	return reader
+}
+
+func (s *ScalerSuite) messageDurationCount(reader *sdkmetric.ManualReader) uint64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "scaleset.message.processing.duration" {
+				continue
+			}
+			var count uint64
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				count += dp.Count
+			}
+			return count
+		}
+	}
+	return 0
+}
+
+// counterValue returns the total of the named int64 counter.
+func (s *ScalerSuite) counterValue(reader *sdkmetric.ManualReader, name string) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				total += dp.Value
+			}
+		}
+	}
+	return total
+}
+
+// startupBounds returns the bucket boundaries of the
+// scaleset.runner.startup.duration histogram.
+func (s *ScalerSuite) startupBounds(reader *sdkmetric.ManualReader) []float64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "scaleset.runner.startup.duration" {
+				continue
+			}
+			dps := m.Data.(metricdata.Histogram[float64]).DataPoints
+			require.NotEmpty(s.T(), dps)
+			return dps[0].Bounds
+		}
+	}
+	return nil
+}
+
+// gaugeValue returns the last observed value of the named int64 gauge.
+func (s *ScalerSuite) gaugeValue(reader *sdkmetric.ManualReader, name string) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			dps := m.Data.(metricdata.Gauge[int64]).DataPoints
+			require.Len(s.T(), dps, 1)
+			return dps[0].Value
+		}
+	}
+	s.Fail("gauge not collected", name)
+	return 0
+}
+
+func (s *ScalerSuite) TestRunnerGauges_MatchMapsUnderLoad() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(0, 200)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 100)
+	require.NoError(s.T(), err)
+	names := make([]string, 0, 100)
+	for name := range sc.idle {
+		names = append(names, name)
+	}
+
+	// Collect continuously while runners churn: start a job on every
+	// runner, complete half of them and scale back up.
+	stop := make(chan struct{})
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				var rm metricdata.ResourceMetrics
+				_ = reader.Collect(s.ctx, &rm)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() {
+			_ = sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: name})
+			if i%2 == 0 {
+				_ = sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: name})
+			}
+		})
+	}
+	wg.Wait()
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 70)
+	require.NoError(s.T(), err)
+	close(stop)
+	<-collected
+
+	sc.mu.Lock()
+	idle, busy := len(sc.idle), len(sc.busy)
+	sc.mu.Unlock()
+	assert.Equal(s.T(), 50, busy)
+	assert.Equal(s.T(), 20, idle)
+	assert.Equal(s.T(), int64(idle), sc.idleCount.Load())
+	assert.Equal(s.T(), int64(busy), sc.busyCount.Load())
+	assert.Equal(s.T(), int64(idle), s.gaugeValue(reader, "scaleset.runners.idle"))
+	assert.Equal(s.T(), int64(busy), s.gaugeValue(reader, "scaleset.runners.busy"))
+
+	sc.Shutdown(s.ctx)
+	assert.Zero(s.T(), s.gaugeValue(reader, "scaleset.runners.idle"))
+	assert.Zero(s.T(), s.gaugeValue(reader, "scaleset.runners.busy"))
+}
+
+func (s *ScalerSuite) TestTelemetryAttributes_SeparateScopes() {
+	reader := s.withManualMeter()
+	newScaler := func(name string) *Scaler {
+		return New(Config{
+			ScaleSetID:          1,
+			MaxRunners:          10,
+			ScalesetClient:      s.jitGen,
+			Engine:              s.engine,
+			Logger:              s.logger,
+			TelemetryAttributes: []attribute.KeyValue{attribute.String("scale_set", name)},
+		})
+	}
+	linux, gpu := newScaler("linux"), newScaler("gpu")
+	_, err := linux.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	_, err = gpu.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	idle := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		name, _ := sm.Scope.Attributes.Value("scale_set")
+		for _, m := range sm.Metrics {
+			if m.Name == "scaleset.runners.idle" {
+				idle[name.AsString()] = m.Data.(metricdata.Gauge[int64]).DataPoints[0].Value
+			}
+		}
+	}
+	assert.Equal(s.T(), map[string]int64{"linux": 2, "gpu": 1}, idle)
+}
+
+func (s *ScalerSuite) TestStartupDuration_ConfiguredBuckets() {
+	reader := s.withManualMeter()
+	buckets := []float64{0.5, 1, 2, 5}
+	sc := New(Config{
+		ScaleSetID:             1,
+		MaxRunners:             10,
+		ScalesetClient:         s.jitGen,
+		Engine:                 s.engine,
+		Logger:                 s.logger,
+		StartupDurationBuckets: buckets,
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), buckets, s.startupBounds(reader))
+}
+
+func (s *ScalerSuite) TestStartupDuration_DefaultBuckets() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(0, 10)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), DefaultStartupDurationBuckets, s.startupBounds(reader))
+}
+
+func (s *ScalerSuite) TestMessageProcessing_RecordsAndWarnsWhenSlow() {
+	reader := s.withManualMeter()
+	var buf bytes.Buffer
+	sc := New(Config{
+		ScaleSetID:           1,
+		MaxRunners:           10,
+		ScalesetClient:       s.jitGen,
+		Engine:               s.engine,
+		Logger:               slog.New(slog.NewTextHandler(&buf, nil)),
+		SlowMessageThreshold: 10 * time.Millisecond,
+	})
+	client := sc.InstrumentClient(&fakeSessionClient{msg: &scaleset.RunnerScaleSetMessage{MessageID: 1}})
+
+	// A fast message is recorded without a warning.
+	_, err := client.GetMessage(s.ctx, 0, 10)
+	require.NoError(s.T(), err)
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), uint64(1), s.messageDurationCount(reader))
+	assert.NotContains(s.T(), buf.String(), "slow message processing")
+
+	// A message whose handling exceeds the threshold warns.
+	_, err = client.GetMessage(s.ctx, 1, 10)
+	require.NoError(s.T(), err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), uint64(2), s.messageDurationCount(reader))
+	assert.Contains(s.T(), buf.String(), "slow message processing")
+}
+
+// unhandledMessages returns scaleset.messages.unhandled by type.
+func (s *ScalerSuite) unhandledMessages(reader *sdkmetric.ManualReader) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	out := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "scaleset.messages.unhandled" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				typ, _ := dp.Attributes.Value("type")
+				out[typ.AsString()] += dp.Value
+			}
+		}
+	}
+	return out
+}
+
+func (s *ScalerSuite) TestUnhandledMessages_JobAssignedCounted() {
+	reader := s.withManualMeter()
+	var buf bytes.Buffer
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	client := sc.InstrumentClient(&fakeSessionClient{msg: &scaleset.RunnerScaleSetMessage{
+		MessageID:           1,
+		JobAssignedMessages: []*scaleset.JobAssigned{{}, {}},
+		JobStartedMessages:  []*scaleset.JobStarted{{}},
+	}})
+
+	_, err := client.GetMessage(s.ctx, 0, 10)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), map[string]int64{"JobAssigned": 2}, s.unhandledMessages(reader))
+	assert.Contains(s.T(), buf.String(), "listener message not handled by the scaler")
+}
+
+func (s *ScalerSuite) TestUnhandledMessages_UnsupportedTypeCounted() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(0, 10)
+	client := sc.InstrumentClient(&fakeSessionClient{
+		err: fmt.Errorf("failed to parse message response: %w",
+			errors.New("unsupported message type: RunnerScaleSetPolicyUpdate")),
+	})
+
+	_, err := client.GetMessage(s.ctx, 0, 10)
+	require.Error(s.T(), err, "the error still reaches the listener")
+
+	assert.Equal(s.T(), map[string]int64{"RunnerScaleSetPolicyUpdate": 1}, s.unhandledMessages(reader))
+}
+
+func (s *ScalerSuite) TestUnhandledMessages_HandledTypesNotCounted() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(0, 10)
+	client := sc.InstrumentClient(&fakeSessionClient{msg: &scaleset.RunnerScaleSetMessage{
+		MessageID:            1,
+		JobStartedMessages:   []*scaleset.JobStarted{{}},
+		JobCompletedMessages: []*scaleset.JobCompleted{{}},
+	}})
+
+	_, err := client.GetMessage(s.ctx, 0, 10)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), s.unhandledMessages(reader))
+}
+
+func (s *ScalerSuite) TestMessageProcessing_NotRecordedWithoutMessage() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(0, 10)
+
+	// Initial statistics and empty polls call HandleDesiredRunnerCount
+	// without a message in flight.
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+
+	_, err = sc.InstrumentClient(&fakeSessionClient{}).GetMessage(s.ctx, 0, 10)
+	require.NoError(s.T(), err)
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+
+	assert.Zero(s.T(), s.messageDurationCount(reader))
+}
+
+// ---------------------------------------------------------------------------
+// Runner naming
+// ---------------------------------------------------------------------------
+
+// mockSuffixEngine adds a runner name suffix, like the GCP engine does
+// with zone_in_runner_name.
+type mockSuffixEngine struct {
+	*mockEngine
+	suffix string
+}
+
+func (m *mockSuffixEngine) RunnerNameSuffix() string { return m.suffix }
+
+// gceNamePattern is the RFC 1035 label format GCE requires for instance
+// names.
+var gceNamePattern = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+func (s *ScalerSuite) TestRunnerName_CarriesEngineSuffix() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         &mockSuffixEngine{mockEngine: s.engine, suffix: "us-central1-a"},
+		Logger:         s.logger,
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), sc.idle, 2)
+	for name := range sc.idle {
+		assert.True(s.T(), strings.HasSuffix(name, "-us-central1-a"), name)
+		assert.Regexp(s.T(), gceNamePattern, name)
+	}
+}
+
+func (s *ScalerSuite) TestRunnerName_SuffixSanitizedAndTruncated() {
+	cases := map[string]string{
+		"US_East-1a":                 "-us-east-1a",
+		"--zone--":                   "-zone",
+		strings.Repeat("abcd-", 20):  "",
+		"europe-west4-b" + "!!!!!!!": "-europe-west4-b",
+	}
+	for suffix, want := range cases {
+		sc := New(Config{
+			Engine: &mockSuffixEngine{mockEngine: s.engine, suffix: suffix},
+			Logger: s.logger,
+		})
+		name, err := sc.newRunnerName()
+		require.NoError(s.T(), err)
+		assert.LessOrEqual(s.T(), len(name), maxRunnerNameLength, name)
+		assert.Regexp(s.T(), gceNamePattern, name)
+		if want != "" {
+			assert.True(s.T(), strings.HasSuffix(name, want), "%q -> %q", suffix, name)
+		}
+	}
+}
+
+func (s *ScalerSuite) TestRunnerName_NoSuffixByDefault() {
+	name, err := s.newScaler(0, 10).newRunnerName()
+	require.NoError(s.T(), err)
+	assert.Regexp(s.T(), `^runner-[0-9a-f]{8}$`, name)
+}
+
+// sequentialNames returns a generator yielding names in order, then
+// repeating the last one.
+func sequentialNames(names ...string) func() string {
+	var mu sync.Mutex
+	i := 0
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		name := names[min(i, len(names)-1)]
+		i++
+		return name
+	}
+}
+
+func (s *ScalerSuite) TestNameGenerator_Custom() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		NameGenerator:  sequentialNames("ci-001", "ci-002"),
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Contains(s.T(), sc.idle, "ci-001")
+	assert.Contains(s.T(), sc.idle, "ci-002")
+}
+
+func (s *ScalerSuite) TestNameGenerator_RetriesOnCollision() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		// The second start first draws ci-001 again and must retry.
+		NameGenerator: sequentialNames("ci-001", "ci-001", "ci-002"),
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), sc.idle, 2)
+	assert.Contains(s.T(), sc.idle, "ci-002")
+}
+
+func (s *ScalerSuite) TestNameGenerator_CollisionWithinBatch() {
+	batch := &mockBatchEngine{mockEngine: s.engine}
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         batch,
+		Logger:         s.logger,
+		NameGenerator:  sequentialNames("ci-001", "ci-001", "ci-002"),
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), batch.batches, 1)
+	assert.Equal(s.T(), "ci-001", batch.batches[0][0].Name)
+	assert.Equal(s.T(), "ci-002", batch.batches[0][1].Name)
+}
+
+func (s *ScalerSuite) TestNameGenerator_GivesUpAfterRepeatedCollisions() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		NameGenerator:  sequentialNames("ci-001"),
+	})
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "no unique runner name")
+	assert.Equal(s.T(), 1, count)
+}
+
+func (s *ScalerSuite) TestJobCompleted_DuplicateDestroysOnce() {
+	s.engine.destroyDelay = 20 * time.Millisecond
+	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	var name string
+	for n := range sc.idle {
+		name = n
+	}
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: name}))
+
+	// Deliver the same JobCompleted several times concurrently, while the
+	// first destroy is still in flight.
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			errs <- sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: name, Result: "succeeded"})
+		}()
+	}
+	for range 3 {
+		assert.NoError(s.T(), <-errs)
+	}
+
+	assert.Equal(s.T(), 1, s.engine.destroyedCount(), "runner must be destroyed exactly once")
+	assert.Zero(s.T(), sc.runnerCount())
+}
+
+// ---------------------------------------------------------------------------
+// Idempotent starts
+// ---------------------------------------------------------------------------
+
+// mockFinderEngine is a mockEngine that implements engine.RunnerFinder.
+// While lostStarts > 0, StartRunner creates the runner but reports an
+// error, as when the backend finishes an insert after the caller's
+// context expired.
+type mockFinderEngine struct {
+	*mockEngine
+	lostStarts int
+	created    map[string]string // runner name -> id in the backend
+	finds      []string
+}
+
+func (m *mockFinderEngine) StartRunner(ctx context.Context, name, jitConfig string) (string, error) {
+	id, err := m.mockEngine.StartRunner(ctx, name, jitConfig)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.created[name] = id
+	if m.lostStarts > 0 {
+		m.lostStarts--
+		return "", fmt.Errorf("waiting for instance %s: context deadline exceeded", name)
+	}
+	return id, nil
+}
+
+func (m *mockFinderEngine) FindRunner(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finds = append(m.finds, key)
+	return m.created[key], nil
+}
+
+func (s *ScalerSuite) TestStartParallelism_BoundsConcurrentStarts() {
+	s.engine.startDelay = 20 * time.Millisecond
+	sc := s.newScalerWith(func(c *Config) { c.StartParallelism = 3 })
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 6)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 6, count)
+	assert.Greater(s.T(), s.engine.maxStartInFlight, 1)
+	assert.LessOrEqual(s.T(), s.engine.maxStartInFlight, 3)
+}
+
+func (s *ScalerSuite) TestStartParallelism_SerialByDefault() {
+	s.engine.startDelay = time.Millisecond
+	sc := s.newScaler(0, 10)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, s.engine.maxStartInFlight)
+}
+
+func (s *ScalerSuite) TestStartParallelism_JoinsInFlightFailures() {
+	reader := s.withManualMeter()
+	s.engine.startErr = errors.New("quota exceeded")
+	s.engine.startDelay = 20 * time.Millisecond
+	sc := s.newScalerWith(func(c *Config) { c.StartParallelism = 3 })
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 6)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), 3, strings.Count(err.Error(), "quota exceeded"), "one failure per start in flight")
+	assert.Equal(s.T(), int64(3), s.counterValue(reader, "scaleset.runners.start_failures"), "no starts begun after the failures")
+}
+
+func (s *ScalerSuite) TestStartParallelism_ReservesNamesInFlight() {
+	s.engine.startDelay = 20 * time.Millisecond
+	sc := s.newScalerWith(func(c *Config) {
+		c.StartParallelism = 2
+		c.NameGenerator = sequentialNames("ci-001", "ci-001", "ci-002")
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.ElementsMatch(s.T(), []string{"ci-001", "ci-002"}, s.engine.getStarted())
+}
+
+func (s *ScalerSuite) TestStartRetries_RecoverFromTransientFailures() {
+	reader := s.withManualMeter()
+	s.engine.startFailures = 2
+	sc := s.newScalerWith(func(c *Config) {
+		c.StartRetries = 2
+		c.StartRetryDelay = time.Millisecond
+	})
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
+	assert.Equal(s.T(), int64(2), s.counterValue(reader, "scaleset.runners.start_retries"))
+	assert.Equal(s.T(), int64(2), s.counterValue(reader, "scaleset.runners.start_failures"))
+}
+
+func (s *ScalerSuite) TestStartRetries_Exhausted() {
+	reader := s.withManualMeter()
+	s.engine.startErr = errors.New("quota exceeded")
+	sc := s.newScalerWith(func(c *Config) {
+		c.StartRetries = 2
+		c.StartRetryDelay = time.Millisecond
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "quota exceeded")
+	assert.Zero(s.T(), sc.runnerCount())
+	assert.Equal(s.T(), int64(3), s.counterValue(reader, "scaleset.runners.start_failures"), "first attempt plus two retries")
+	assert.Equal(s.T(), int64(3), s.counterValue(reader, "scaleset.runners.start_retries"), "two retried, one exhausted")
+}
+
+func (s *ScalerSuite) TestStartRetries_ReuseNameWithIdempotentStarts() {
+	s.engine.startFailures = 1
+	sc := s.newScalerWith(func(c *Config) {
+		c.StartRetries = 2
+		c.StartRetryDelay = time.Millisecond
+		c.IdempotentStarts = true
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), s.engine.getStarted(), 1)
+	assert.Equal(s.T(), 1, s.jitGen.calls, "the retry reuses the JIT config")
+}
+
+func (s *ScalerSuite) TestIdempotentStart_AdoptsRunnerFromLostStart() {
+	eng := &mockFinderEngine{mockEngine: s.engine, lostStarts: 1, created: map[string]string{}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.NameGenerator = sequentialNames("ci-001", "ci-002", "ci-003")
+		c.IdempotentStarts = true
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+	assert.Zero(s.T(), sc.runnerCount())
+
+	// The retried scale-up finds the runner the first attempt created.
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, count)
+	assert.Equal(s.T(), []string{"ci-001"}, eng.finds)
+	assert.Equal(s.T(), []string{"ci-001"}, s.engine.getStarted(), "no second resource created")
+	assert.Equal(s.T(), eng.created["ci-001"], sc.idle["ci-001"])
+	assert.Equal(s.T(), 1, s.jitGen.calls)
+}
+
+func (s *ScalerSuite) TestIdempotentStart_RestartsUnderSameNameWhenNotFound() {
+	s.engine.startErr = fmt.Errorf("daemon unavailable")
+	eng := &mockFinderEngine{mockEngine: s.engine, created: map[string]string{}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.NameGenerator = sequentialNames("ci-001", "ci-002", "ci-003")
+		c.IdempotentStarts = true
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+
+	s.engine.startErr = nil
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"ci-001"}, eng.finds)
+	assert.Equal(s.T(), []string{"ci-001"}, s.engine.getStarted())
+	assert.Contains(s.T(), sc.idle, "ci-001")
+	assert.Equal(s.T(), 1, s.jitGen.calls, "retry reuses the JIT config")
+}
+
+func (s *ScalerSuite) TestIdempotentStart_BatchRetriesFailedStartFirst() {
+	batch := &mockBatchEngine{mockEngine: s.engine}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = batch
+		c.NameGenerator = sequentialNames("ci-001", "ci-002", "ci-003")
+		c.IdempotentStarts = true
+	})
+
+	s.engine.startErr = fmt.Errorf("daemon unavailable")
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+
+	s.engine.startErr = nil
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"ci-001", "ci-002"}, s.engine.getStarted())
+	require.Len(s.T(), batch.batches, 2)
+	assert.Equal(s.T(), "ci-002", batch.batches[1][0].Name)
+}
+
+func (s *ScalerSuite) TestIdempotentStart_DisabledUsesNewName() {
+	s.engine.startErr = fmt.Errorf("daemon unavailable")
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		NameGenerator:  sequentialNames("ci-001", "ci-002"),
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+
+	s.engine.startErr = nil
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Contains(s.T(), sc.idle, "ci-002")
+	assert.Equal(s.T(), 2, s.jitGen.calls)
+}
+
+// ---------------------------------------------------------------------------
+// Start deadline
+// ---------------------------------------------------------------------------
+
+// startFailures returns scaleset.runners.start_failures by reason.
+func (s *ScalerSuite) startFailures(reader *sdkmetric.ManualReader) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	out := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "scaleset.runners.start_failures" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				reason, _ := dp.Attributes.Value("reason")
+				out[reason.AsString()] += dp.Value
+			}
+		}
+	}
+	return out
+}
+
+func (s *ScalerSuite) TestStartDeadline_StuckStartIsDestroyed() {
+	reader := s.withManualMeter()
+	// The engine ignores cancellation and returns a runner after the
+	// deadline, like an insert that completes long after it was abandoned.
+	s.engine.startDelay = 50 * time.Millisecond
+	sc := s.newScalerWith(func(c *Config) { c.StartDeadline = 20 * time.Millisecond })
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "exceeded start deadline")
+	assert.Zero(s.T(), count)
+	assert.Equal(s.T(), []string{"mock-id-1"}, s.engine.getDestroyed(), "partial resource must be cleaned up")
+	assert.Equal(s.T(), map[string]int64{"deadline": 1}, s.startFailures(reader))
+}
+
+func (s *ScalerSuite) TestStartDeadline_CancelsEngineStart() {
+	reader := s.withManualMeter()
+	s.engine.startDelay = time.Second
+	s.engine.startHonorsCtx = true
+	eng := &mockFinderEngine{mockEngine: s.engine, created: map[string]string{}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.StartDeadline = 20 * time.Millisecond
+	})
+
+	start := time.Now()
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+	assert.Less(s.T(), time.Since(start), 500*time.Millisecond, "engine start must be cancelled at the deadline")
+	assert.Len(s.T(), eng.finds, 1, "engine is asked for a partial resource")
+	assert.Zero(s.T(), sc.runnerCount())
+	assert.Equal(s.T(), map[string]int64{"deadline": 1}, s.startFailures(reader))
+}
+
+func (s *ScalerSuite) TestStartDeadline_FastStartSucceeds() {
+	reader := s.withManualMeter()
+	sc := s.newScalerWith(func(c *Config) { c.StartDeadline = 20 * time.Millisecond })
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, count)
+	assert.Empty(s.T(), s.startFailures(reader))
+}
+
+func (s *ScalerSuite) TestStartDeadline_BatchOverrunDestroysStarted() {
+	reader := s.withManualMeter()
+	s.engine.startDelay = 15 * time.Millisecond
+	batch := &mockBatchEngine{mockEngine: s.engine}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = batch
+		c.StartDeadline = 20 * time.Millisecond
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.Error(s.T(), err)
+	assert.Zero(s.T(), sc.runnerCount())
+	assert.Len(s.T(), s.engine.getDestroyed(), 2)
+	assert.Equal(s.T(), map[string]int64{"deadline": 2}, s.startFailures(reader))
+}
+
+func (s *ScalerSuite) TestStartFailure_CountsEngineErrors() {
+	reader := s.withManualMeter()
+	s.engine.startErr = fmt.Errorf("daemon unavailable")
+	sc := s.newScalerWith(func(c *Config) { c.StartDeadline = 20 * time.Millisecond })
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), map[string]int64{"error": 1}, s.startFailures(reader))
+}
+
+func (s *ScalerSuite) TestStartFailure_LabelsEngineAndClass() {
+	reader := s.withManualMeter()
+	s.engine.startErr = errors.New("googleapi: Error 403: Quota 'CPUS' exceeded")
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		EngineName:     "gcp",
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+
+	attrs := s.metricAttributes(reader, "scaleset.runners.start_failures")
+	require.Len(s.T(), attrs, 1)
+	assert.Equal(s.T(), map[string]string{"reason": "error", "engine": "gcp", "class": "quota"}, attrs[0])
+}
+
+func (s *ScalerSuite) TestErrorClass() {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("engine start: %w", context.DeadlineExceeded), "timeout"},
+		{context.Canceled, "canceled"},
+		{errors.New("Quota 'CPUS' exceeded"), "quota"},
+		{errors.New("ZONE_RESOURCE_POOL_EXHAUSTED"), "capacity"},
+		{errors.New("Error 403: Required 'compute.instances.create' permission"), "permission"},
+		{errors.New("Error response from daemon: No such image: runner:latest"), "not_found"},
+		{errors.New("Conflict. The container name is already in use"), "conflict"},
+		{errors.New("daemon unavailable"), "other"},
+	}
+	for _, tc := range cases {
+		assert.Equal(s.T(), tc.want, errorClass(tc.err), tc.err.Error())
+	}
+}
+
+func (s *ScalerSuite) TestEngineLatency_RecordsStartAndDestroy() {
+	reader := s.withManualMeter()
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		EngineName:     "docker",
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	for name := range sc.idle {
+		require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: name}))
+		require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: name, Result: "succeeded"}))
+		break
+	}
+
+	counts := map[string]uint64{}
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "scaleset.engine.api.latency" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				op, _ := dp.Attributes.Value("operation")
+				eng, _ := dp.Attributes.Value("engine")
+				outcome, _ := dp.Attributes.Value("outcome")
+				assert.Equal(s.T(), "docker", eng.AsString())
+				assert.Equal(s.T(), "ok", outcome.AsString())
+				counts[op.AsString()] += dp.Count
+			}
+		}
+	}
+	assert.Equal(s.T(), map[string]uint64{"start": 2, "destroy": 1}, counts)
+}
+
+func (s *ScalerSuite) TestDesiredRunnersGauge_FollowsTarget() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(1, 5)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 10)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(5), s.gaugeValue(reader, "scaleset.desired_runners"), "capped at max runners")
+
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), s.gaugeValue(reader, "scaleset.desired_runners"), "min runners kept")
+}
+
+func (s *ScalerSuite) TestQueueDepthGauge_FromStatistics() {
+	reader := s.withManualMeter()
+	sc := s.newScaler(0, 10)
+	client := &fakeSessionClient{msg: &scaleset.RunnerScaleSetMessage{
+		MessageID:  1,
+		Statistics: &scaleset.RunnerScaleSetStatistic{TotalAssignedJobs: 7, TotalRunningJobs: 3},
+	}}
+
+	_, err := sc.InstrumentClient(client).GetMessage(s.ctx, 0, 10)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(4), s.gaugeValue(reader, "scaleset.jobs.queue_depth"))
+
+	// A message without statistics leaves the last value in place.
+	client.msg = &scaleset.RunnerScaleSetMessage{MessageID: 2}
+	_, err = sc.InstrumentClient(client).GetMessage(s.ctx, 1, 10)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(4), s.gaugeValue(reader, "scaleset.jobs.queue_depth"))
+}
+
+// metricAttributes returns the attributes of every data point of the
+// named int64 counter.
+func (s *ScalerSuite) metricAttributes(reader *sdkmetric.ManualReader, name string) []map[string]string {
+	var rm metricdata.ResourceMetrics
+	require.NoError(s.T(), reader.Collect(s.ctx, &rm))
+	var out []map[string]string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				attrs := map[string]string{}
+				for _, kv := range dp.Attributes.ToSlice() {
+					attrs[string(kv.Key)] = kv.Value.AsString()
+				}
+				out = append(out, attrs)
+			}
+		}
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// JIT runner settings
+// ---------------------------------------------------------------------------
+
+func (s *ScalerSuite) TestJitRunnerSetting_CarriesWorkFolder() {
+	sc := New(Config{
+		ScaleSetID:       1,
+		MaxRunners:       10,
+		ScalesetClient:   s.jitGen,
+		Engine:           s.engine,
+		Logger:           s.logger,
+		RunnerWorkFolder: "/mnt/work",
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), s.jitGen.settings, 2)
+	for _, setting := range s.jitGen.settings {
+		assert.NotEmpty(s.T(), setting.Name)
+		assert.Equal(s.T(), "/mnt/work", setting.WorkFolder)
+	}
+}
+
+func (s *ScalerSuite) TestJitRunnerSetting_BatchCarriesWorkFolder() {
+	eng := &mockBatchEngine{mockEngine: s.engine}
+	sc := New(Config{
+		ScaleSetID:       1,
+		MaxRunners:       10,
+		ScalesetClient:   s.jitGen,
+		Engine:           eng,
+		Logger:           s.logger,
+		RunnerWorkFolder: "_work-ssd",
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), s.jitGen.settings, 3)
+	for _, setting := range s.jitGen.settings {
+		assert.Equal(s.T(), "_work-ssd", setting.WorkFolder)
+	}
+}
+
+func (s *ScalerSuite) TestJitRunnerSetting_DefaultWorkFolderEmpty() {
+	sc := s.newScaler(0, 10)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), s.jitGen.settings, 1)
+	assert.Empty(s.T(), s.jitGen.settings[0].WorkFolder)
+}
+
+// ---------------------------------------------------------------------------
+// Admission control
+// ---------------------------------------------------------------------------
+
+// mockCheckingEngine is a mockEngine implementing engine.Checker whose
+// health is set by the test.
+type mockCheckingEngine struct {
+	*mockEngine
+	checkErr error
+	checks   int
+}
+
+func (m *mockCheckingEngine) Check(context.Context) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks++
+	return nil, m.checkErr
+}
+
+func (m *mockCheckingEngine) setHealth(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkErr = err
+}
+
+func (s *ScalerSuite) TestAdmission_UnhealthyEngineSkipsScaleUp() {
+	reader := s.withManualMeter()
+	eng := &mockCheckingEngine{mockEngine: newMockEngine(), checkErr: errors.New("docker daemon unreachable")}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.AdmissionCheck = true
+		c.AdmissionCheckTimeout = time.Second
+	})
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err, "a skipped scale-up is not a failure")
+	assert.Equal(s.T(), 0, count)
+	assert.Zero(s.T(), eng.startedCount(), "no runner start is attempted")
+	assert.Equal(s.T(), 1, eng.checks)
+	assert.Equal(s.T(), int64(1), s.counterValue(reader, "scaleset.scale.skipped"))
+}
+
+func (s *ScalerSuite) TestAdmission_ScalesUpOnceHealthy() {
+	eng := &mockCheckingEngine{mockEngine: newMockEngine(), checkErr: errors.New("gcp unreachable")}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.AdmissionCheck = true
+		c.AdmissionCheckTimeout = time.Second
+	})
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, count)
+
+	eng.setHealth(nil)
+	count, err = sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+	assert.Equal(s.T(), 3, eng.startedCount())
+}
+
+func (s *ScalerSuite) TestAdmission_OnlyGatesScaleUp() {
+	eng := &mockCheckingEngine{mockEngine: newMockEngine()}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.AdmissionCheck = true
+		c.AdmissionCheckTimeout = time.Second
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+
+	eng.setHealth(errors.New("docker daemon unreachable"))
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+	assert.Equal(s.T(), 1, eng.checks, "no check when not scaling up")
+}
+
+func (s *ScalerSuite) TestAdmission_DisabledByDefault() {
+	eng := &mockCheckingEngine{mockEngine: newMockEngine(), checkErr: errors.New("docker daemon unreachable")}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.AdmissionCheckTimeout = time.Second
+	})
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
+	assert.Zero(s.T(), eng.checks)
+}
+
+// ---------------------------------------------------------------------------
+// Per-repository limits
+// ---------------------------------------------------------------------------
+
+// startJobs scales up by one runner per repo, on top of the busy ones,
+// and starts a job from each repo on the new runners.
+func (s *ScalerSuite) startJobs(sc *Scaler, repos ...string) []string {
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, len(sc.busy)+len(repos))
+	require.NoError(s.T(), err)
+	names := slices.Sorted(maps.Keys(sc.idle))
+	require.Len(s.T(), names, len(repos))
+	for i, name := range names {
+		require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{
+			RunnerName:     name,
+			JobMessageBase: scaleset.JobMessageBase{RepositoryName: repos[i]},
+		}))
+	}
+	return names
+}
+
+func (s *ScalerSuite) TestRepoLimit_RepoHittingItsCap() {
+	reader := s.withManualMeter()
+	sc := s.newScalerWith(func(c *Config) { c.MaxRunnersPerRepo = 2 })
+
+	s.startJobs(sc, "noisy", "noisy", "noisy", "quiet")
+	assert.Equal(s.T(), 4, len(sc.busy), "jobs beyond the cap still run")
+	assert.Equal(s.T(), map[string]int{"noisy": 3, "quiet": 1}, sc.repoBusy)
+	assert.Len(s.T(), sc.overRepoLimit, 1)
+	assert.Equal(s.T(), int64(1), s.counterValue(reader, "scaleset.jobs.repo_limited"))
+
+	// GitHub counts the four running jobs plus one queued; the runner
+	// held by the over-limit job is not replaced.
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 4, count)
+	assert.Equal(s.T(), 4, s.engine.startedCount())
+
+	count, err = sc.HandleDesiredRunnerCount(s.ctx, 6)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 5, count)
+}
+
+func (s *ScalerSuite) TestRepoLimit_CompletedJobFreesRepo() {
+	sc := s.newScalerWith(func(c *Config) { c.MaxRunnersPerRepo = 1 })
+	names := s.startJobs(sc, "noisy", "noisy")
+	require.Len(s.T(), sc.overRepoLimit, 1)
+
+	for _, name := range names {
+		require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{
+			RunnerName:     name,
+			Result:         "success",
+			JobMessageBase: scaleset.JobMessageBase{RepositoryName: "noisy"},
+		}))
+	}
+	assert.Empty(s.T(), sc.repoBusy)
+	assert.Empty(s.T(), sc.busyRepo)
+	assert.Empty(s.T(), sc.overRepoLimit)
+
+	s.startJobs(sc, "noisy")
+	assert.Empty(s.T(), sc.overRepoLimit, "repo is back under its cap")
+}
+
+func (s *ScalerSuite) TestRepoLimit_Overrides() {
+	sc := s.newScalerWith(func(c *Config) {
+		c.MaxRunnersPerRepo = 1
+		c.RepoRunnerLimits = map[string]int{"monorepo": 3, "trusted": 0}
+	})
+
+	s.startJobs(sc, "monorepo", "monorepo", "monorepo", "trusted", "trusted", "other")
+	assert.Empty(s.T(), sc.overRepoLimit)
+
+	s.startJobs(sc, "other")
+	assert.Len(s.T(), sc.overRepoLimit, 1, "default limit applies to repos without an override")
+}
+
+func (s *ScalerSuite) TestRepoLimit_DisabledByDefault() {
+	sc := s.newScaler(0, 10)
+	s.startJobs(sc, "noisy", "noisy", "noisy")
+	assert.Empty(s.T(), sc.repoBusy)
+	assert.Empty(s.T(), sc.overRepoLimit)
+}
+
+// ---------------------------------------------------------------------------
+// Capacity limiting
+// ---------------------------------------------------------------------------
+
+// mockCapacityEngine is a mockEngine that can hold at most capacity
+// live runners and counts every start attempt.
+type mockCapacityEngine struct {
+	*mockEngine
+	capacity int
+	attempts int
+}
+
+func (m *mockCapacityEngine) StartRunner(ctx context.Context, name, jitConfig string) (string, error) {
+	m.mu.Lock()
+	m.attempts++
+	full := len(m.started)-len(m.destroyed) >= m.capacity
+	m.mu.Unlock()
+	if full {
+		return "", fmt.Errorf("quota exceeded")
+	}
+	return m.mockEngine.StartRunner(ctx, name, jitConfig)
+}
+
+func (m *mockCapacityEngine) setCapacity(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capacity = n
+}
+
+func (m *mockCapacityEngine) startAttempts() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.attempts
+}
+
+func (s *ScalerSuite) TestCapacity_BoundsRetriesWhenMinRunnersExceedsCapacity() {
+	eng := &mockCapacityEngine{mockEngine: s.engine, capacity: 2}
+	var logs bytes.Buffer
+	sc := s.newScalerWith(func(c *Config) {
+		c.MinRunners = 5
+		c.Engine = eng
+		c.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+		c.CapacityProbeInterval = time.Minute
+	})
+	stopClock(sc)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), 3, eng.startAttempts(), "two starts plus the one that hit capacity")
+
+	for range 10 {
+		count, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), 2, count)
+	}
+	assert.Equal(s.T(), 3, eng.startAttempts(), "no retries until the next probe")
+	assert.Equal(s.T(), 1, strings.Count(logs.String(), "min_runners cannot be met"), "warning is throttled")
+}
+
+func (s *ScalerSuite) TestCapacity_ProbesOncePerInterval() {
+	eng := &mockCapacityEngine{mockEngine: s.engine, capacity: 2}
+	var logs bytes.Buffer
+	sc := s.newScalerWith(func(c *Config) {
+		c.MinRunners = 5
+		c.Engine = eng
+		c.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+		c.CapacityProbeInterval = time.Minute
+	})
+	now := stopClock(sc)
+
+	_, _ = sc.HandleDesiredRunnerCount(s.ctx, 0)
+	*now = now.Add(time.Minute)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.Error(s.T(), err, "probe still fails")
+	assert.Equal(s.T(), 4, eng.startAttempts())
+
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 4, eng.startAttempts(), "one probe per interval")
+}
+
+func (s *ScalerSuite) TestCapacity_ReconcilesUpwardWhenCapacityReturns() {
+	eng := &mockCapacityEngine{mockEngine: s.engine, capacity: 2}
+	var logs bytes.Buffer
+	sc := s.newScalerWith(func(c *Config) {
+		c.MinRunners = 5
+		c.Engine = eng
+		c.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+		c.CapacityProbeInterval = time.Minute
+	})
+	now := stopClock(sc)
+
+	_, _ = sc.HandleDesiredRunnerCount(s.ctx, 0)
+	eng.setCapacity(10)
+	*now = now.Add(time.Minute)
+
+	// The probe succeeds and lifts the cap ...
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+	assert.Contains(s.T(), logs.String(), "engine capacity recovered")
+
+	// ... so the next message fills up to min_runners.
+	count, err = sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 5, count)
+}
+
+// mockHeldCapacityEngine is a mockEngine that admits at most capacity
+// starts and holds the last admitted one until release is closed, so
+// that it finishes after the starts that hit capacity.
+type mockHeldCapacityEngine struct {
+	*mockEngine
+	capacity int
+	admitted int
+	attempts int
+	release  chan struct{}
+}
+
+func (m *mockHeldCapacityEngine) StartRunner(ctx context.Context, name, jitConfig string) (string, error) {
+	m.mu.Lock()
+	m.attempts++
+	n := m.admitted + 1
+	if n <= m.capacity {
+		m.admitted = n
+	}
+	m.mu.Unlock()
+	switch {
+	case n > m.capacity:
+		return "", fmt.Errorf("quota exceeded")
+	case n == m.capacity:
+		<-m.release
+	}
+	return m.mockEngine.StartRunner(ctx, name, jitConfig)
+}
+
+func (s *ScalerSuite) TestCapacity_StartBegunBeforeFailureDoesNotLiftCap() {
+	eng := &mockHeldCapacityEngine{mockEngine: s.engine, capacity: 3, release: make(chan struct{})}
+	sc := New(Config{
+		ScaleSetID:            1,
+		MinRunners:            5,
+		MaxRunners:            10,
+		ScalesetClient:        s.jitGen,
+		Engine:                eng,
+		Logger:                s.logger,
+		CapacityProbeInterval: time.Minute,
+		StartParallelism:      5,
+	})
+	now := time.Now()
+	sc.now = func() time.Time { return now }
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+		done <- err
+	}()
+	// Release the held start once a failed start set the cap.
+	require.Eventually(s.T(), func() bool {
+		sc.mu.Lock()
+		defer sc.mu.Unlock()
+		return sc.capacityGen > 0
+	}, time.Second, time.Millisecond)
+	close(eng.release)
+	require.Error(s.T(), <-done)
+
+	sc.mu.Lock()
+	assert.Equal(s.T(), 3, sc.capacity, "the late start raises the cap to the runners held")
+	sc.mu.Unlock()
+
+	attempts := eng.attempts
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+	assert.Equal(s.T(), attempts, eng.attempts, "no starts beyond the cap until the next probe")
+}
+
+func (s *ScalerSuite) TestCapacity_DisabledRetriesEveryMessage() {
+	eng := &mockCapacityEngine{mockEngine: s.engine, capacity: 2}
+	sc := New(Config{
+		ScaleSetID:     1,
+		MinRunners:     5,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         eng,
+		Logger:         s.logger,
+	})
+
+	for range 3 {
+		_, err := sc.HandleDesiredRunnerCount(s.ctx, 0)
+		require.Error(s.T(), err)
+	}
+	assert.Equal(s.T(), 5, eng.startAttempts())
+}
+
+// ---------------------------------------------------------------------------
+// Drain
+// ---------------------------------------------------------------------------
+
+func (s *ScalerSuite) TestDrain_StopsScaleUp() {
+	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	sc.Drain()
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, count)
+	assert.Equal(s.T(), 1, s.engine.startedCount())
+}
+
+func (s *ScalerSuite) TestDrain_NoBusyRunnersIsImmediatelyDrained() {
+	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+
+	select {
+	case <-sc.Drain():
+	default:
+		s.Fail("idle runners must not hold up the drain")
+	}
+}
+
+func (s *ScalerSuite) TestDrain_EndpointBlocksUntilBusyRunnersFinish() {
+	sc := s.newScaler(0, 10)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	var names []string
+	for name := range sc.idle {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: name}))
+	}
+
+	drain := health.NewDrain(0)
+	drain.SetDrainer(sc)
+	srv := httptest.NewServer(drain.Handler())
+	defer srv.Close()
+
+	done := make(chan int, 1)
+	go func() {
+		resp, err := http.Post(srv.URL, "application/json", nil)
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+
+	complete := func(name string) {
+		require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: name, Result: "succeeded"}))
+	}
+
+	complete(names[0])
+	select {
+	case <-done:
+		s.Fail("drain returned while a runner was still busy")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	complete(names[1])
+	select {
+	case code := <-done:
+		assert.Equal(s.T(), http.StatusOK, code)
+	case <-time.After(time.Second):
+		s.Fail("drain did not return after the last job completed")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Shutdown
+// ---------------------------------------------------------------------------
+
+func (s *ScalerSuite) TestShutdown_CleansUpAll() {
+	sc := s.newScaler(0, 10)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+	require.NoError(s.T(), err)
+
+	// Move one to busy
+	var firstRunner string
+	for name := range sc.idle {
+		firstRunner = name
+		break
+	}
+	_ = sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: firstRunner})
+
+	assert.Equal(s.T(), 2, len(sc.idle))
+	assert.Equal(s.T(), 1, len(sc.busy))
+
+	summary := sc.Shutdown(s.ctx)
+
+	assert.True(s.T(), s.engine.shutdown)
+	assert.Equal(s.T(), 0, len(sc.idle))
+	assert.Equal(s.T(), 0, len(sc.busy))
+	assert.Equal(s.T(), 3, summary.Destroyed)
+	assert.Empty(s.T(), summary.Leaked)
+	assert.Equal(s.T(), 3, s.engine.destroyedCount())
+}
+
+// shutdownWithFlakyDestroys starts four runners, makes destroying two of
+// them fail twice and one fail five times, and shuts down with the given
+// retries.
+func (s *ScalerSuite) shutdownWithFlakyDestroys(retries int, logger *slog.Logger) (ShutdownSummary, string) {
+	eng := newMockEngine()
+	sc := New(Config{
+		ScaleSetID:         1,
+		MaxRunners:         10,
+		ScalesetClient:     s.jitGen,
+		Engine:             eng,
+		Logger:             logger,
+		ShutdownRetries:    retries,
+		ShutdownRetryDelay: time.Millisecond,
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 4)
+	require.NoError(s.T(), err)
+
+	eng.mu.Lock()
+	eng.destroyFailures = map[string]int{
+		eng.ids[eng.started[0]]: 2,
+		eng.ids[eng.started[1]]: 2,
+		eng.ids[eng.started[2]]: 5,
+	}
+	persistent := eng.started[2]
+	eng.mu.Unlock()
+
+	return sc.Shutdown(s.ctx), persistent
+}
+
+func (s *ScalerSuite) TestShutdown_RetriesReduceLeakedRunners() {
+	noRetries, _ := s.shutdownWithFlakyDestroys(0, s.logger)
+	assert.Equal(s.T(), 1, noRetries.Destroyed)
+	assert.Len(s.T(), noRetries.Leaked, 3)
+
+	withRetries, persistent := s.shutdownWithFlakyDestroys(3, s.logger)
+	assert.Equal(s.T(), 3, withRetries.Destroyed)
+	assert.Equal(s.T(), []string{persistent}, withRetries.Leaked,
+		"only the runner failing more often than retries allow leaks")
+	assert.NoError(s.T(), withRetries.EngineErr)
+}
+
+func (s *ScalerSuite) TestShutdown_LogsSummary() {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	_, persistent := s.shutdownWithFlakyDestroys(3, logger)
+
+	var summary map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		require.NoError(s.T(), json.Unmarshal([]byte(line), &rec))
+		if rec["msg"] == "shutdown summary" {
+			summary = rec
+		}
+	}
+	require.NotNil(s.T(), summary, "shutdown summary not logged")
+	assert.Equal(s.T(), "ERROR", summary["level"])
+	assert.EqualValues(s.T(), 3, summary["destroyed"])
+	assert.EqualValues(s.T(), 1, summary["leaked"])
+	assert.Equal(s.T(), []any{persistent}, summary["leaked_runners"])
+	assert.Contains(s.T(), buf.String(), "shutdown: retrying runner destroy")
+}
+
+// ---------------------------------------------------------------------------
+// Error handling
+// ---------------------------------------------------------------------------
+
+func (s *ScalerSuite) TestScaleUp_EngineFailure() {
+	s.engine.startErr = fmt.Errorf("docker daemon unavailable")
+	sc := s.newScaler(0, 10)
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 3)
+
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "docker daemon unavailable")
+	// Should return partial count (0 since engine fails on first attempt)
+	assert.Equal(s.T(), 0, count)
+}
+
+func (s *ScalerSuite) TestScaleUp_EngineFailureMidScale() {
+	sc := s.newScaler(0, 10)
+
+	// Let 2 runners succeed, then fail on the 3rd
+	callCount := 0
+	origStart := s.engine.StartRunner
+	_ = origStart // avoid unused
+	s.engine.startErr = nil
+
+	// We need a more nuanced mock. Let's just do two separate
+	// HandleDesiredRunnerCount calls to simulate partial success.
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, sc.runnerCount())
+	_ = callCount
+
+	// Now make engine fail
+	s.engine.startErr = fmt.Errorf("out of capacity")
+
+	// Try to scale up by 3 more (current=2, target=min(10,0+5)=5, delta=3)
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 5)
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "out of capacity")
+	// Returns whatever count we managed (still 2)
+	assert.Equal(s.T(), 2, count)
+}
+
+func (s *ScalerSuite) TestScaleUp_JitConfigFailure() {
+	s.jitGen.err = fmt.Errorf("github API rate limited")
+	sc := s.newScaler(0, 10)
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "github API rate limited")
+	assert.Equal(s.T(), 0, count)
+	// Engine should never have been called since JIT config failed
+	assert.Equal(s.T(), 0, s.engine.startedCount())
+}
+
+func (s *ScalerSuite) TestScaleUp_EmptyEngineID() {
+	s.engine.emptyID = true
+	sc := s.newScaler(0, 10)
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "empty runner id")
+	assert.Equal(s.T(), 0, count)
+	// The engine was called, but the runner must not be tracked
+	assert.Equal(s.T(), 1, s.engine.startedCount())
+	assert.Equal(s.T(), 0, len(sc.idle))
+	assert.Equal(s.T(), 0, len(sc.busy))
+}
+
+// mockEmptyIDEngine is a mockEngine whose starts create a resource but
+// return an empty id, and that finds the resource by runner name.
+type mockEmptyIDEngine struct {
+	*mockEngine
+}
+
+func (m *mockEmptyIDEngine) StartRunner(ctx context.Context, name, jitConfig string) (string, error) {
+	_, err := m.mockEngine.StartRunner(ctx, name, jitConfig)
+	return "", err
+}
+
+func (m *mockEmptyIDEngine) FindRunner(_ context.Context, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ids[name], nil
+}
+
+func (s *ScalerSuite) TestScaleUp_EmptyEngineIDIsReclaimed() {
+	reader := s.withManualMeter()
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         &mockEmptyIDEngine{mockEngine: s.engine},
+		Logger:         s.logger,
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "empty runner id")
+	assert.Empty(s.T(), sc.idle)
+	assert.Equal(s.T(), []string{"mock-id-1"}, s.engine.getDestroyed(), "the resource found by name is destroyed")
+	assert.Equal(s.T(), map[string]int64{"error": 1}, s.startFailures(reader))
+}
+
+func (s *ScalerSuite) TestHandleJobCompleted_DestroyError() {
+	s.engine.destroyErr = fmt.Errorf("container already gone")
+	sc := s.newScaler(0, 10)
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	var runnerName string
+	for name := range sc.idle {
+		runnerName = name
+	}
+
+	_ = sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: runnerName})
+
+	err = sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{
+		RunnerName: runnerName,
+		Result:     "success",
+	})
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "container already gone")
+}
+
+// ---------------------------------------------------------------------------
+// One-runner-per-job correctness tests
+// ---------------------------------------------------------------------------
+
+func (s *ScalerSuite) TestOneRunnerPerJob_Sequential() {
+	// Test the core invariant: exactly one runner is created and destroyed
 	// per job, even when jobs run sequentially.
 	const N = 50
 	sc := s.newScaler(0, 100)
@@ -874,3 +2913,25 @@ func (s *ScalerSuite) TestOneRunnerPerJob_DuplicateEvents() {
 	}
 	assert.Len(s.T(), uniqueIDs, N)
 }
+
+func (s *ScalerSuite) TestFakeEngine_Pipeline() {
+	eng, err := fake.New(fake.Config{StartLatency: time.Millisecond, RunID: "run-1"}, s.logger)
+	require.NoError(s.T(), err)
+	sc := New(Config{ScaleSetID: 1, MaxRunners: 3, ScalesetClient: s.jitGen, Engine: eng, Logger: s.logger})
+
+	count, err := sc.HandleDesiredRunnerCount(s.ctx, 5)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+	listed, err := eng.ListRunners(s.ctx, engine.RunnerLabels("run-1"))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), listed, 3)
+
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: listed[0].Name}))
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: listed[0].Name, Result: "succeeded"}))
+	assert.Equal(s.T(), fake.Stats{Started: 3, Destroyed: 1}, eng.Stats())
+
+	summary := sc.Shutdown(s.ctx)
+	assert.Equal(s.T(), 2, summary.Destroyed)
+	assert.Empty(s.T(), summary.Leaked)
+	assert.Equal(s.T(), 3, eng.Stats().Destroyed)
+}
@@ -0,0 +1,122 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// StartStartupWatch runs ReplaceStuckRunners periodically until ctx is
+// done.  The returned stop func ends the loop and waits for a pass in
+// progress, so it must be called before Shutdown.  It does nothing when
+// Config.StartupTimeout is zero.
+func (s *Scaler) StartStartupWatch(ctx context.Context) (stop func()) {
+	if s.startupTimeout <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(max(s.startupCheckInterval, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.ReplaceStuckRunners(ctx); err != nil && ctx.Err() == nil {
+					s.logger.Warn("replacing stuck runners failed", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// ReplaceStuckRunners destroys the runners this scaler started that have
+// stayed idle for Config.StartupTimeout, i.e. never picked up a job, and
+// rescales to the desired count the listener last reported so that they
+// are replaced.  It returns the names of the destroyed runners.  Runners
+// adopted by Restore or the reconciler are not watched.
+func (s *Scaler) ReplaceStuckRunners(ctx context.Context) ([]string, error) {
+	if s.startupTimeout <= 0 {
+		return nil, nil
+	}
+	ctx, span := s.tracer.Start(ctx, "scaler.ReplaceStuckRunners")
+	defer span.End()
+
+	s.mu.Lock()
+	now := s.now()
+	var stuck []string
+	for name, started := range s.startedAt {
+		if now.Sub(started) >= s.startupTimeout {
+			stuck = append(stuck, name)
+		}
+	}
+	s.mu.Unlock()
+	slices.Sort(stuck)
+
+	var replaced []string
+	var errs []error
+	for _, name := range stuck {
+		id := s.removeIdleRunner(name)
+		if id == "" {
+			continue // its job started in the meantime
+		}
+		s.logger.Warn("runner did not pick up a job within the startup timeout, replacing it",
+			slog.String("name", name),
+			slog.String("id", id),
+			slog.Duration("startup_timeout", s.startupTimeout),
+		)
+		if s.startupTimeouts != nil {
+			s.startupTimeouts.Add(ctx, 1)
+		}
+		replaced = append(replaced, name)
+		if err := s.destroyRunner(ctx, name, id, destroyStartupTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("destroy runner %s (%s): %w", name, id, err))
+			continue
+		}
+		if s.runnersDestroyed != nil {
+			s.runnersDestroyed.Add(ctx, 1)
+		}
+	}
+	span.SetAttributes(attribute.Int("scaleset.startup_timeouts", len(replaced)))
+	if len(replaced) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	s.scaleMu.Lock()
+	_, err := s.scaleTo(ctx, s.lastDesired)
+	s.scaleMu.Unlock()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("start replacements: %w", err))
+	}
+	return replaced, errors.Join(errs...)
+}
+
+// removeIdleRunner forgets the named runner if it is still idle and
+// returns its id, or "" if it is not.
+func (s *Scaler) removeIdleRunner(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.idle[name]
+	if !ok {
+		delete(s.startedAt, name)
+		return ""
+	}
+	delete(s.idle, name)
+	delete(s.startedAt, name)
+	delete(s.createdAt, name)
+	delete(s.runnerRunID, name)
+	s.runnersChangedLocked()
+	return id
+}
@@ -0,0 +1,75 @@
+package scaler
+
+import (
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *ScalerSuite) TestReplaceStuckRunners() {
+	reader := s.withManualMeter()
+	sc := s.newScalerWith(func(c *Config) { c.StartupTimeout = 10 * time.Minute })
+	now := stopClock(sc)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	started := s.engine.getStarted()
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[0]}))
+
+	*now = now.Add(9 * time.Minute)
+	replaced, err := sc.ReplaceStuckRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), replaced, "not stuck before the timeout")
+
+	*now = now.Add(time.Minute)
+	replaced, err = sc.ReplaceStuckRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{started[1]}, replaced, "busy runners are left alone")
+	assert.Equal(s.T(), []string{s.engine.ids[started[1]]}, s.engine.getDestroyed())
+	assert.Equal(s.T(), int64(1), s.counterValue(reader, "scaleset.runners.startup_timeouts"))
+
+	// A replacement is started for the last desired count.
+	assert.Equal(s.T(), 3, s.engine.startedCount())
+	assert.Equal(s.T(), 2, sc.runnerCount())
+	assert.Contains(s.T(), sc.busy, started[0])
+
+	// The replacement gets its own timeout.
+	replaced, err = sc.ReplaceStuckRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), replaced)
+}
+
+func (s *ScalerSuite) TestReplaceStuckRunners_Disabled() {
+	sc := s.newScaler(0, 10)
+	now := stopClock(sc)
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	*now = now.Add(24 * time.Hour)
+	replaced, err := sc.ReplaceStuckRunners(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), replaced)
+	assert.Empty(s.T(), s.engine.getDestroyed())
+	sc.StartStartupWatch(s.ctx)()
+}
+
+func (s *ScalerSuite) TestStartStartupWatch_RunsPeriodically() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MaxRunners:     10,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+		StartupTimeout: 20 * time.Millisecond,
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+
+	stop := sc.StartStartupWatch(s.ctx)
+	assert.Eventually(s.T(), func() bool {
+		return len(s.engine.getDestroyed()) >= 1
+	}, time.Second, 5*time.Millisecond)
+	stop()
+	assert.Equal(s.T(), 1, sc.runnerCount(), "the stuck runner was replaced")
+}
@@ -0,0 +1,256 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/state"
+)
+
+// StateStore persists the scaler's runners so that a restarted process
+// can re-adopt them (see Restore).  *state.File satisfies it.
+type StateStore interface {
+	// Load returns the last saved snapshot, or nil if there is none.
+	Load() (*state.Snapshot, error)
+	// Save replaces the saved snapshot.
+	Save(snap *state.Snapshot) error
+}
+
+// RestoreResult lists the runners of the saved state that Restore acted
+// on, by runner name.
+type RestoreResult struct {
+	// Adopted are runners tracked again, idle or busy as saved.
+	Adopted []string
+	// Destroyed are runners no longer registered with GitHub (their job
+	// finished while no process was listening), now destroyed.
+	Destroyed []string
+	// Forgotten are runners whose resource no longer exists, or that are
+	// registered with another scale set.
+	Forgotten []string
+}
+
+// Restore re-adopts the runners recorded in the state store by a
+// previous process, so that a crash neither leaks them nor makes the
+// scaler provision duplicates.  It must be called before the scaler
+// handles any message, since every change overwrites the saved state.
+//
+// Each saved runner is checked against GitHub (Config.RunnerRegistry)
+// and the engine (engine.RunnerFinder) when available: a runner no
+// longer registered is destroyed, one registered with another scale set
+// or whose resource is gone is forgotten, and the rest are tracked idle
+// or busy as saved.  A runner that cannot be checked is adopted, so that
+// it is destroyed on Shutdown rather than leaked, and the error is
+// returned.  The reconciler also lists the resources of the restored
+// run IDs from then on.
+func (s *Scaler) Restore(ctx context.Context) (RestoreResult, error) {
+	var result RestoreResult
+	if s.stateStore == nil {
+		return result, nil
+	}
+	snap, err := s.stateStore.Load()
+	if err != nil {
+		return result, fmt.Errorf("load state: %w", err)
+	}
+	if snap == nil || len(snap.Runners) == 0 {
+		return result, nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "scaler.Restore")
+	defer span.End()
+
+	finder, _ := s.engine.(engine.RunnerFinder)
+	var errs []error
+	adopt := make(map[string]state.Runner)
+	runIDs := make(map[string]bool)
+	for _, name := range slices.Sorted(maps.Keys(snap.Runners)) {
+		r := snap.Runners[name]
+		if r.RunID != "" && r.RunID != s.runID {
+			runIDs[r.RunID] = true
+		}
+		logger := s.logger.With(slog.String("name", name), slog.String("id", r.ID))
+
+		if s.registry != nil {
+			ref, err := s.registry.GetRunnerByName(ctx, name)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("look up runner %s: %w", name, err))
+				adopt[name] = r
+				result.Adopted = append(result.Adopted, name)
+				continue
+			case ref == nil:
+				logger.Info("restore: destroying runner no longer registered")
+				if err := s.destroyRunner(ctx, name, r.ID, destroyUnregistered); err != nil {
+					errs = append(errs, fmt.Errorf("destroy runner %s (%s): %w", name, r.ID, err))
+					continue
+				}
+				result.Destroyed = append(result.Destroyed, name)
+				continue
+			case ref.RunnerScaleSetID != s.scaleSetID:
+				logger.Warn("restore: runner belongs to another scale set, leaving it",
+					slog.Int("scale_set_id", ref.RunnerScaleSetID),
+				)
+				result.Forgotten = append(result.Forgotten, name)
+				continue
+			}
+		}
+
+		if finder != nil {
+			id, err := finder.FindRunner(ctx, name)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("find runner %s: %w", name, err))
+			case id == "":
+				logger.Info("restore: forgetting runner whose resource no longer exists")
+				result.Forgotten = append(result.Forgotten, name)
+				continue
+			default:
+				r.ID = id
+			}
+		}
+
+		adopt[name] = r
+		result.Adopted = append(result.Adopted, name)
+	}
+
+	// Track the adopted runners at once, so the state is saved once and
+	// never with only some of them.
+	s.mu.Lock()
+	for name, r := range adopt {
+		if r.Busy {
+			s.busy[name] = r.ID
+		} else {
+			s.idle[name] = r.ID
+		}
+		if r.RunID != "" && r.RunID != s.runID {
+			s.runnerRunID[name] = r.RunID
+		}
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = s.now()
+		}
+		s.createdAt[name] = r.CreatedAt
+	}
+	s.restoredRunIDs = slices.Sorted(maps.Keys(runIDs))
+	s.runnersChangedLocked()
+	s.mu.Unlock()
+
+	s.logger.Info("restored runner state",
+		slog.Int("adopted", len(result.Adopted)),
+		slog.Int("destroyed", len(result.Destroyed)),
+		slog.Int("forgotten", len(result.Forgotten)),
+	)
+	span.SetAttributes(
+		attribute.Int("scaleset.restore.adopted", len(result.Adopted)),
+		attribute.Int("scaleset.restore.destroyed", len(result.Destroyed)),
+		attribute.Int("scaleset.restore.forgotten", len(result.Forgotten)),
+	)
+	return result, errors.Join(errs...)
+}
+
+// queueStateSaveLocked snapshots the tracked runners and hands the
+// snapshot to the state writer, which saves it outside mu.  s.mu must be
+// held.
+func (s *Scaler) queueStateSaveLocked() {
+	s.queueRunnersSaveLocked(s.idle, s.busy)
+}
+
+// queueRunnersSaveLocked snapshots the given idle and busy runners (name
+// -> engine id) and hands the snapshot to the state writer.  s.mu must be
+// held, so that snapshots reach the writer in the order of the changes.
+func (s *Scaler) queueRunnersSaveLocked(idle, busy map[string]string) {
+	if s.stateWriter == nil {
+		return
+	}
+	runners := make(map[string]state.Runner, len(idle)+len(busy))
+	for name, id := range idle {
+		runners[name] = state.Runner{ID: id, RunID: s.runIDOfLocked(name), CreatedAt: s.createdAt[name]}
+	}
+	for name, id := range busy {
+		runners[name] = state.Runner{ID: id, Busy: true, RunID: s.runIDOfLocked(name), CreatedAt: s.createdAt[name]}
+	}
+	s.stateWriter.queue(&state.Snapshot{Runners: runners})
+}
+
+// flushState waits until every queued snapshot has been saved.
+func (s *Scaler) flushState() {
+	if s.stateWriter != nil {
+		s.stateWriter.flush()
+	}
+}
+
+// stateWriter saves snapshots to a StateStore in the background.  Only
+// the latest queued snapshot is kept, so a burst of changes while a save
+// is in flight costs one more save rather than one per change.  A failure
+// is logged rather than returned: the runners keep working, and only
+// crash recovery is affected.
+type stateWriter struct {
+	store  StateStore
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	idle    *sync.Cond      // broadcast when writing turns false
+	pending *state.Snapshot // latest snapshot not yet saved
+	writing bool            // a goroutine is running write
+}
+
+func newStateWriter(store StateStore, logger *slog.Logger) *stateWriter {
+	w := &stateWriter{store: store, logger: logger}
+	w.idle = sync.NewCond(&w.mu)
+	return w
+}
+
+// queue replaces the pending snapshot and starts a writer if none runs.
+func (w *stateWriter) queue(snap *state.Snapshot) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = snap
+	if !w.writing {
+		w.writing = true
+		go w.write()
+	}
+}
+
+// write saves pending snapshots until there are none left.
+func (w *stateWriter) write() {
+	for {
+		w.mu.Lock()
+		snap := w.pending
+		w.pending = nil
+		if snap == nil {
+			w.writing = false
+			w.idle.Broadcast()
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+
+		if err := w.store.Save(snap); err != nil {
+			w.logger.Warn("failed to save runner state", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// flush waits until the writer is idle.
+func (w *stateWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.writing {
+		w.idle.Wait()
+	}
+}
+
+// runIDOfLocked returns the run ID whose labels the named runner's
+// resource carries.  s.mu must be held.
+func (s *Scaler) runIDOfLocked(name string) string {
+	if runID, ok := s.runnerRunID[name]; ok {
+		return runID
+	}
+	return s.runID
+}
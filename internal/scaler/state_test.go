@@ -0,0 +1,263 @@
+package scaler
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrpan/scaleset/internal/engine"
+	"github.com/terrpan/scaleset/internal/state"
+)
+
+// memStore is an in-memory StateStore.
+type memStore struct {
+	mu   sync.Mutex
+	snap *state.Snapshot
+}
+
+func (m *memStore) Load() (*state.Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snap, nil
+}
+
+func (m *memStore) Save(snap *state.Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snap = &state.Snapshot{Runners: maps.Clone(snap.Runners)}
+	return nil
+}
+
+func (m *memStore) runners() map[string]state.Runner {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.snap == nil {
+		return nil
+	}
+	return maps.Clone(m.snap.Runners)
+}
+
+func (s *ScalerSuite) TestState_SavedOnEveryChange() {
+	store := &memStore{}
+	sc := s.newScalerWith(func(c *Config) {
+		c.StateStore = store
+		c.RunID = "run-2"
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	sc.flushState()
+	saved := store.runners()
+	require.Len(s.T(), saved, 2)
+	names := slices.Sorted(maps.Keys(saved))
+	for _, name := range names {
+		assert.Equal(s.T(), state.Runner{ID: sc.idle[name], RunID: "run-2", CreatedAt: sc.createdAt[name]}, saved[name])
+	}
+
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: names[0]}))
+	sc.flushState()
+	assert.True(s.T(), store.runners()[names[0]].Busy)
+
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: names[0], Result: "success"}))
+	sc.flushState()
+	assert.Equal(s.T(), []string{names[1]}, slices.Collect(maps.Keys(store.runners())))
+
+	sc.Shutdown(s.ctx)
+	assert.Empty(s.T(), store.runners())
+}
+
+func (s *ScalerSuite) TestShutdown_KeepsLeakedRunnersInState() {
+	store := &memStore{}
+	sc := s.newScalerWith(func(c *Config) {
+		c.StateStore = store
+		c.RunID = "run-2"
+	})
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+	s.engine.destroyErr = errors.New("backend unavailable")
+
+	summary := sc.Shutdown(s.ctx)
+	require.Len(s.T(), summary.Leaked, 1)
+	assert.Equal(s.T(), summary.Leaked, slices.Collect(maps.Keys(store.runners())))
+}
+
+// blockingStore is a memStore whose Save blocks until release is closed
+// and counts its calls.
+type blockingStore struct {
+	memStore
+	entered chan struct{}
+	release chan struct{}
+	saves   atomic.Int32
+}
+
+func (b *blockingStore) Save(snap *state.Snapshot) error {
+	if b.saves.Add(1) == 1 {
+		close(b.entered)
+	}
+	<-b.release
+	return b.memStore.Save(snap)
+}
+
+func (s *ScalerSuite) TestState_SavedOutsideLockAndCoalesced() {
+	store := &blockingStore{entered: make(chan struct{}), release: make(chan struct{})}
+	sc := s.newScalerWith(func(c *Config) {
+		c.StateStore = store
+		c.RunID = "run-2"
+	})
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	<-store.entered
+	names := slices.Sorted(maps.Keys(sc.idle))
+
+	// A slow save does not block the scaler.
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: names[0]}))
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: names[1]}))
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: names[0], Result: "success"}))
+
+	close(store.release)
+	sc.flushState()
+	assert.Equal(s.T(), int32(2), store.saves.Load(), "changes queued during a save are coalesced into one")
+	assert.Equal(s.T(), map[string]state.Runner{
+		names[1]: {ID: sc.busy[names[1]], Busy: true, RunID: "run-2", CreatedAt: sc.createdAt[names[1]]},
+	}, store.runners())
+}
+
+// ---------------------------------------------------------------------------
+// Restore
+// ---------------------------------------------------------------------------
+
+func (s *ScalerSuite) TestRestore_AdoptsRunners() {
+	store := &memStore{snap: &state.Snapshot{Runners: map[string]state.Runner{
+		"runner-idle": {ID: "c1", RunID: "run-1"},
+		"runner-busy": {ID: "c2", Busy: true, RunID: "run-1"},
+	}}}
+	registry := &mockRegistry{runners: map[string]int{"runner-idle": 1, "runner-busy": 1}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.StateStore = store
+		c.RunnerRegistry = registry
+		c.RunID = "run-2"
+	})
+
+	result, err := sc.Restore(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"runner-busy", "runner-idle"}, result.Adopted)
+	assert.Equal(s.T(), map[string]string{"runner-idle": "c1"}, sc.idle)
+	assert.Equal(s.T(), map[string]string{"runner-busy": "c2"}, sc.busy)
+	sc.flushState()
+	assert.Equal(s.T(), "run-1", store.runners()["runner-idle"].RunID, "restored runners keep their run ID")
+
+	// Demand is met by the adopted runners instead of new ones.
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 2)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, s.engine.startedCount())
+
+	// The busy runner completes normally.
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: "runner-busy", Result: "success"}))
+	assert.Equal(s.T(), []string{"c2"}, s.engine.getDestroyed())
+}
+
+func (s *ScalerSuite) TestRestore_ChecksRegistry() {
+	store := &memStore{snap: &state.Snapshot{Runners: map[string]state.Runner{
+		"runner-done":  {ID: "c1", Busy: true},
+		"runner-other": {ID: "c2"},
+		"runner-ok":    {ID: "c3"},
+	}}}
+	registry := &mockRegistry{runners: map[string]int{"runner-other": 2, "runner-ok": 1}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.StateStore = store
+		c.RunnerRegistry = registry
+		c.RunID = "run-2"
+	})
+
+	result, err := sc.Restore(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), RestoreResult{
+		Adopted:   []string{"runner-ok"},
+		Destroyed: []string{"runner-done"},
+		Forgotten: []string{"runner-other"},
+	}, result)
+	assert.Equal(s.T(), []string{"c1"}, s.engine.getDestroyed())
+	sc.flushState()
+	assert.Equal(s.T(), []string{"runner-ok"}, slices.Collect(maps.Keys(store.runners())))
+}
+
+func (s *ScalerSuite) TestRestore_ForgetsRunnersWithoutResource() {
+	eng := &mockFinderEngine{mockEngine: s.engine, created: map[string]string{"runner-a": "c9"}}
+	store := &memStore{snap: &state.Snapshot{Runners: map[string]state.Runner{
+		"runner-a": {ID: "c1"},
+		"runner-b": {ID: "c2"},
+	}}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.Engine = eng
+		c.StateStore = store
+		c.RunID = "run-2"
+	})
+
+	result, err := sc.Restore(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"runner-a"}, result.Adopted)
+	assert.Equal(s.T(), []string{"runner-b"}, result.Forgotten)
+	assert.Equal(s.T(), map[string]string{"runner-a": "c9"}, sc.idle, "the engine's current id is used")
+}
+
+func (s *ScalerSuite) TestRestore_LookupErrorAdopts() {
+	store := &memStore{snap: &state.Snapshot{Runners: map[string]state.Runner{"runner-a": {ID: "c1"}}}}
+	sc := s.newScalerWith(func(c *Config) {
+		c.StateStore = store
+		c.RunnerRegistry = &mockRegistry{err: errors.New("github unavailable")}
+		c.RunID = "run-2"
+	})
+
+	result, err := sc.Restore(s.ctx)
+	require.Error(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "github unavailable")
+	assert.Equal(s.T(), []string{"runner-a"}, result.Adopted)
+	assert.Contains(s.T(), sc.idle, "runner-a", "tracked so Shutdown destroys it")
+}
+
+func (s *ScalerSuite) TestRestore_WithoutStateStore() {
+	sc := s.newScalerWith(func(c *Config) { c.RunID = "run-2" })
+	result, err := sc.Restore(s.ctx)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), RestoreResult{}, result)
+
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 1)
+	require.NoError(s.T(), err)
+}
+
+func (s *ScalerSuite) TestRestore_ReconcilerListsRestoredRunIDs() {
+	eng := newMockListingEngine()
+	eng.addResource("runner-a", "c1")
+	store := &memStore{snap: &state.Snapshot{Runners: map[string]state.Runner{
+		"runner-a": {ID: "c1", RunID: "run-1"},
+	}}}
+	sc := New(Config{
+		ScaleSetID:        1,
+		MaxRunners:        10,
+		ScalesetClient:    s.jitGen,
+		Engine:            eng,
+		Logger:            s.logger,
+		ReconcileInterval: time.Minute,
+		RunnerRegistry:    &mockRegistry{runners: map[string]int{"runner-a": 1}},
+		RunID:             "run-2",
+		StateStore:        store,
+	})
+
+	_, err := sc.Restore(s.ctx)
+	require.NoError(s.T(), err)
+
+	result := s.reconcileTwice(sc)
+	assert.Equal(s.T(), ReconcileResult{}, result, "the restored runner is listed, not forgotten")
+	assert.Equal(s.T(), []map[string]string{
+		engine.RunnerLabels("run-2"), engine.RunnerLabels("run-1"),
+		engine.RunnerLabels("run-2"), engine.RunnerLabels("run-1"),
+	}, eng.labels)
+}
@@ -0,0 +1,83 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/terrpan/scaleset/internal/engine"
+)
+
+// scaleEventHistory is how many scale events Status reports.
+const scaleEventHistory = 20
+
+// ScaleEvent is a scaling decision that started runners ("up") or left
+// runners to drain as their jobs complete ("down").
+type ScaleEvent struct {
+	Time time.Time
+	// Action is "up" or "down".
+	Action string
+	// Desired is the count of jobs GitHub wanted runners for, Current
+	// the runners tracked and Target the runner count aimed for.
+	Desired, Current, Target int
+	// Delta is the runners started (up) or the shortfall left to drain
+	// (down, negative).
+	Delta int
+}
+
+// Status is a snapshot of the scaler's scaling state.
+type Status struct {
+	// Desired is the desired count last reported, and Target the runner
+	// count the last scaling decision aimed for.
+	Desired, Target int
+	// Events are the most recent scale events, oldest first.
+	Events []ScaleEvent
+}
+
+// ErrNoEngineCheck is returned by CheckEngine when the engine cannot
+// check its health.
+var ErrNoEngineCheck = errors.New("engine has no health check")
+
+// Status returns the scaler's scaling state.
+func (s *Scaler) Status() Status {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+	return Status{
+		Desired: s.statusDesired,
+		Target:  int(s.desiredRunners.Load()),
+		Events:  slices.Clone(s.events),
+	}
+}
+
+// CheckEngine checks the engine's health (see engine.Checker) and returns
+// the details it reports.  It returns ErrNoEngineCheck if the engine
+// cannot check its health.
+func (s *Scaler) CheckEngine(ctx context.Context) (map[string]string, error) {
+	c, ok := s.engine.(engine.Checker)
+	if !ok {
+		return nil, ErrNoEngineCheck
+	}
+	return c.Check(ctx)
+}
+
+// recordDecision records a scaling decision for Status.
+func (s *Scaler) recordDecision(desired, current, target int, action string, delta int) {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+	s.statusDesired = desired
+	if action == "none" {
+		return
+	}
+	if len(s.events) == scaleEventHistory {
+		s.events = slices.Delete(s.events, 0, 1)
+	}
+	s.events = append(s.events, ScaleEvent{
+		Time:    s.now(),
+		Action:  action,
+		Desired: desired,
+		Current: current,
+		Target:  target,
+		Delta:   delta,
+	})
+}
@@ -0,0 +1,71 @@
+package scaler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCheckerEngine struct {
+	*mockEngine
+	err error
+}
+
+func (m *mockCheckerEngine) Check(context.Context) (map[string]string, error) {
+	return map[string]string{"daemon": "ok"}, m.err
+}
+
+func (s *ScalerSuite) TestStatus_RecordsScaleEvents() {
+	sc := New(Config{
+		ScaleSetID:     1,
+		MinRunners:     1,
+		MaxRunners:     3,
+		ScalesetClient: s.jitGen,
+		Engine:         s.engine,
+		Logger:         s.logger,
+	})
+	assert.Equal(s.T(), Status{}, sc.Status())
+
+	_, err := sc.HandleDesiredRunnerCount(s.ctx, 4)
+	require.NoError(s.T(), err)
+	started := s.engine.getStarted()
+	require.NoError(s.T(), sc.HandleJobStarted(s.ctx, &scaleset.JobStarted{RunnerName: started[0]}))
+	require.NoError(s.T(), sc.HandleJobCompleted(s.ctx, &scaleset.JobCompleted{RunnerName: started[0], Result: "succeeded"}))
+	_, err = sc.HandleDesiredRunnerCount(s.ctx, 0)
+	require.NoError(s.T(), err)
+
+	st := sc.Status()
+	assert.Equal(s.T(), 0, st.Desired)
+	assert.Equal(s.T(), 1, st.Target)
+	require.Len(s.T(), st.Events, 2, "decisions without an action are not recorded")
+	assert.Equal(s.T(), ScaleEvent{Time: st.Events[0].Time, Action: "up", Desired: 4, Current: 0, Target: 3, Delta: 3}, st.Events[0])
+	assert.Equal(s.T(), ScaleEvent{Time: st.Events[1].Time, Action: "down", Desired: 0, Current: 2, Target: 1, Delta: -1}, st.Events[1])
+	assert.False(s.T(), st.Events[0].Time.IsZero())
+}
+
+func (s *ScalerSuite) TestStatus_KeepsRecentEvents() {
+	sc := New(Config{ScaleSetID: 1, MaxRunners: 100, ScalesetClient: s.jitGen, Engine: s.engine, Logger: s.logger})
+	for i := 1; i <= scaleEventHistory+5; i++ {
+		_, err := sc.HandleDesiredRunnerCount(s.ctx, i)
+		require.NoError(s.T(), err)
+	}
+	events := sc.Status().Events
+	require.Len(s.T(), events, scaleEventHistory)
+	assert.Equal(s.T(), 6, events[0].Desired)
+	assert.Equal(s.T(), scaleEventHistory+5, events[len(events)-1].Desired)
+}
+
+func (s *ScalerSuite) TestCheckEngine() {
+	sc := New(Config{ScaleSetID: 1, MaxRunners: 1, ScalesetClient: s.jitGen, Engine: s.engine, Logger: s.logger})
+	_, err := sc.CheckEngine(s.ctx)
+	assert.ErrorIs(s.T(), err, ErrNoEngineCheck)
+
+	down := errors.New("daemon unreachable")
+	sc = New(Config{ScaleSetID: 1, MaxRunners: 1, ScalesetClient: s.jitGen, Engine: &mockCheckerEngine{mockEngine: s.engine, err: down}, Logger: s.logger})
+	details, err := sc.CheckEngine(s.ctx)
+	assert.ErrorIs(s.T(), err, down)
+	assert.Equal(s.T(), map[string]string{"daemon": "ok"}, details)
+}
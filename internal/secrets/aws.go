@@ -0,0 +1,205 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// awsService is the Secrets Manager signing name and endpoint prefix.
+const awsService = "secretsmanager"
+
+// aws reads the secret id (an ARN, or a name in AWS_REGION) from AWS
+// Secrets Manager with the credentials in AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.  With "#key" the secret
+// must be a JSON object and the key's value is returned.
+func (r *Resolver) aws(ctx context.Context, ref string) (string, error) {
+	id, key := splitField(ref)
+	if id == "" {
+		return "", errors.New("want aws-sm://<arn or name>[#<json key>]")
+	}
+	region := r.env("AWS_REGION", "AWS_DEFAULT_REGION")
+	if strings.HasPrefix(id, "arn:") {
+		// arn:partition:secretsmanager:region:account:secret:name
+		parts := strings.SplitN(id, ":", 7)
+		if len(parts) < 7 || parts[2] != awsService || parts[3] == "" {
+			return "", fmt.Errorf("%q is not a Secrets Manager secret ARN", id)
+		}
+		region = parts[3]
+	}
+	if region == "" {
+		return "", errors.New("AWS_REGION is not set and the secret is not named by ARN")
+	}
+	creds := awsCredentials{
+		accessKeyID:     r.env("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: r.env("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    r.env("AWS_SESSION_TOKEN"),
+	}
+	if creds.accessKeyID == "" || creds.secretAccessKey == "" {
+		return "", errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	endpoint := r.env("AWS_ENDPOINT_URL_SECRETS_MANAGER", "AWS_ENDPOINT_URL")
+	if endpoint == "" {
+		endpoint = "https://" + awsService + "." + region + ".amazonaws.com"
+		if strings.HasPrefix(region, "cn-") {
+			endpoint += ".cn"
+		}
+	}
+	client, err := r.httpClient()
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	creds.sign(req, body, region, time.Now())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+			Msg     string `json:"Message"`
+		}
+		_ = json.Unmarshal(data, &e)
+		return "", fmt.Errorf("secrets manager: %s: %s", resp.Status, strings.TrimSpace(e.Type+" "+e.Message+e.Msg))
+	}
+
+	var out struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("secrets manager: decoding response: %w", err)
+	}
+	value := out.SecretString
+	if value == "" && out.SecretBinary != "" {
+		b, err := base64.StdEncoding.DecodeString(out.SecretBinary)
+		if err != nil {
+			return "", fmt.Errorf("secrets manager: decoding SecretBinary: %w", err)
+		}
+		value = string(b)
+	}
+	if key == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, cannot select %q", key)
+	}
+	s, ok := fields[key].(string)
+	if !ok {
+		return "", fmt.Errorf("secret has no string key %q", key)
+	}
+	return s, nil
+}
+
+// awsCredentials signs requests with Signature Version 4.
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// sign adds the Signature Version 4 headers for body, sent to the
+// Secrets Manager endpoint of region at t, to req.
+func (c awsCredentials) sign(req *http.Request, body []byte, region string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+	scope := date + "/" + region + "/" + awsService + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(signingKey(c.secretAccessKey, date, region, awsService), stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+c.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// signingKey derives the Signature Version 4 key for a day, region and
+// service.
+func signingKey(secret, date, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), date)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func canonicalQuery(q url.Values) string {
+	// Encode sorts by key; AWS wants %20 rather than '+'.
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
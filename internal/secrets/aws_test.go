@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The derived key of the Signature Version 4 documentation's example.
+func TestSigningKey(t *testing.T) {
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
+
+func TestResolve_AWS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		auth := req.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+		assert.Contains(t, auth, "/eu-west-1/secretsmanager/aws4_request")
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target")
+		assert.Equal(t, "session", req.Header.Get("X-Amz-Security-Token"))
+		assert.Equal(t, "secretsmanager.GetSecretValue", req.Header.Get("X-Amz-Target"))
+
+		var in struct{ SecretId string }
+		_ = json.NewDecoder(req.Body).Decode(&in)
+		switch in.SecretId {
+		case "arn:aws:secretsmanager:eu-west-1:123456789012:secret:gh-AbCdEf":
+			_, _ = w.Write([]byte(`{"SecretString":"ghp_aws"}`))
+		case "gh-json":
+			_, _ = w.Write([]byte(`{"SecretString":"{\"token\":\"ghp_json\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","Message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer srv.Close()
+	r := newTestResolver(map[string]string{
+		"AWS_ACCESS_KEY_ID":                "AKID",
+		"AWS_SECRET_ACCESS_KEY":            "secret",
+		"AWS_SESSION_TOKEN":                "session",
+		"AWS_REGION":                       "eu-west-1",
+		"AWS_ENDPOINT_URL_SECRETS_MANAGER": srv.URL,
+	})
+	ctx := context.Background()
+
+	value, err := r.Resolve(ctx, "aws-sm://arn:aws:secretsmanager:eu-west-1:123456789012:secret:gh-AbCdEf")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_aws", value)
+
+	value, err = r.Resolve(ctx, "aws-sm://gh-json#token")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_json", value)
+
+	_, err = r.Resolve(ctx, "aws-sm://gh-json#missing")
+	assert.ErrorContains(t, err, `secret has no string key "missing"`)
+
+	_, err = r.Resolve(ctx, "aws-sm://other")
+	assert.ErrorContains(t, err, "ResourceNotFoundException Secrets Manager can't find the specified secret.")
+
+	_, err = r.Resolve(ctx, "aws-sm://arn:aws:iam::123456789012:user/x")
+	assert.ErrorContains(t, err, "is not a Secrets Manager secret ARN")
+}
+
+func TestResolve_AWSNoCredentials(t *testing.T) {
+	r := newTestResolver(map[string]string{"AWS_REGION": "eu-west-1"})
+	_, err := r.Resolve(context.Background(), "aws-sm://gh")
+	assert.ErrorContains(t, err, "AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+}
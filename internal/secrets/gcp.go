@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/option"
+	secretmanager "google.golang.org/api/secretmanager/v1"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// gcp accesses the Secret Manager secret version name
+// ("projects/p/secrets/s", latest version, or
+// "projects/p/secrets/s/versions/3") with Application Default
+// Credentials.
+func (r *Resolver) gcp(ctx context.Context, name string) (string, error) {
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets":
+		name += "/versions/latest"
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions":
+	default:
+		return "", errors.New("want gcp-sm://projects/<project>/secrets/<secret>[/versions/<version>]")
+	}
+
+	opts, err := r.gcpOptions(ctx)
+	if err != nil {
+		return "", err
+	}
+	svc, err := secretmanager.NewService(ctx, opts...)
+	if err != nil {
+		return "", fmt.Errorf("secret manager client: %w", err)
+	}
+	resp, err := svc.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	if resp.Payload == nil {
+		return "", nil
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decoding payload: %w", err)
+	}
+	return string(data), nil
+}
+
+// gcpOptions routes the Secret Manager client through the resolver's
+// egress settings, authenticating with Application Default Credentials.
+func (r *Resolver) gcpOptions(ctx context.Context) ([]option.ClientOption, error) {
+	if r.gcpEndpoint != "" {
+		return []option.ClientOption{option.WithEndpoint(r.gcpEndpoint), option.WithoutAuthentication()}, nil
+	}
+	if r.egress.IsZero() {
+		return nil, nil
+	}
+	base, err := r.egress.Transport()
+	if err != nil {
+		return nil, err
+	}
+	rt, err := htransport.NewTransport(ctx, base, option.WithScopes(secretmanager.CloudPlatformScope))
+	if err != nil {
+		return nil, fmt.Errorf("gcp transport: %w", err)
+	}
+	return []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: rt})}, nil
+}
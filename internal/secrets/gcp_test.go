@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve_GCP(t *testing.T) {
+	var accessed []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		accessed = append(accessed, req.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"name":    "projects/p/secrets/gh/versions/1",
+			"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("ghp_gcp"))},
+		})
+	}))
+	defer srv.Close()
+	r := newTestResolver(nil)
+	r.gcpEndpoint = srv.URL
+	ctx := context.Background()
+
+	value, err := r.Resolve(ctx, "gcp-sm://projects/p/secrets/gh")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_gcp", value)
+	_, err = r.Resolve(ctx, "gcp-sm://projects/p/secrets/gh/versions/2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/v1/projects/p/secrets/gh/versions/latest:access",
+		"/v1/projects/p/secrets/gh/versions/2:access",
+	}, accessed)
+
+	_, err = r.Resolve(ctx, "gcp-sm://secrets/gh")
+	assert.ErrorContains(t, err, "want gcp-sm://projects/<project>/secrets/<secret>")
+}
@@ -0,0 +1,105 @@
+// Package secrets resolves references to secrets kept in a secrets
+// manager, so credentials need not live in the config file, flags or
+// environment.  A reference names its backend by prefix:
+//
+//	vault:secret/data/gh#token                            HashiCorp Vault (KV v1 or v2)
+//	gcp-sm://projects/p/secrets/s[/versions/v]            GCP Secret Manager
+//	aws-sm://arn:aws:secretsmanager:...:secret:s[#key]    AWS Secrets Manager
+//
+// Any other value is not a reference and is used as is.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/terrpan/scaleset/internal/egress"
+)
+
+// Reference prefixes.
+const (
+	vaultPrefix = "vault:"
+	gcpPrefix   = "gcp-sm://"
+	awsPrefix   = "aws-sm://"
+)
+
+// IsReference reports whether value refers to a secret rather than
+// being one.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, vaultPrefix) ||
+		strings.HasPrefix(value, gcpPrefix) ||
+		strings.HasPrefix(value, awsPrefix)
+}
+
+// Resolver fetches referenced secrets.  Each backend reads its
+// credentials from the environment the way its own CLI does: VAULT_ADDR
+// and VAULT_TOKEN, Application Default Credentials, and the AWS_*
+// variables.
+type Resolver struct {
+	egress egress.Config
+	lookup func(key string) (string, bool)
+
+	// gcpEndpoint overrides the Secret Manager API endpoint, for tests.
+	gcpEndpoint string
+}
+
+// NewResolver returns a Resolver whose requests leave through eg.
+func NewResolver(eg egress.Config) *Resolver {
+	return &Resolver{egress: eg, lookup: os.LookupEnv}
+}
+
+// Resolve returns the secret ref refers to, or ref itself when it is not
+// a reference.  Errors name the reference but never the secret.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	var (
+		value string
+		err   error
+	)
+	switch {
+	case strings.HasPrefix(ref, vaultPrefix):
+		value, err = r.vault(ctx, strings.TrimPrefix(ref, vaultPrefix))
+	case strings.HasPrefix(ref, gcpPrefix):
+		value, err = r.gcp(ctx, strings.TrimPrefix(ref, gcpPrefix))
+	case strings.HasPrefix(ref, awsPrefix):
+		value, err = r.aws(ctx, strings.TrimPrefix(ref, awsPrefix))
+	default:
+		return ref, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	if value == "" {
+		return "", fmt.Errorf("resolving %s: secret is empty", ref)
+	}
+	return value, nil
+}
+
+// env returns the first of keys set to a non-empty value.
+func (r *Resolver) env(keys ...string) string {
+	for _, k := range keys {
+		if v, ok := r.lookup(k); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// httpClient returns a client that leaves through the resolver's egress
+// settings.
+func (r *Resolver) httpClient() (*http.Client, error) {
+	t, err := r.egress.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: t}, nil
+}
+
+// splitField splits "path#field" into its parts; field is "" without a
+// '#'.
+func splitField(ref string) (path, field string) {
+	path, field, _ = strings.Cut(ref, "#")
+	return path, field
+}
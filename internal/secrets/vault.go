@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// vault reads field of the secret at path ("secret/data/gh#token") from
+// the KV engine of the Vault server at VAULT_ADDR, with VAULT_TOKEN or
+// the token "vault login" saved in ~/.vault-token.  KV v2 paths include
+// the engine's "data/" segment, as in the HTTP API.
+func (r *Resolver) vault(ctx context.Context, ref string) (string, error) {
+	path, field := splitField(ref)
+	if path == "" || field == "" {
+		return "", errors.New(`want vault:<path>#<field>, e.g. vault:secret/data/github#token`)
+	}
+	addr := r.env("VAULT_ADDR")
+	if addr == "" {
+		return "", errors.New("VAULT_ADDR is not set")
+	}
+	token, err := r.vaultToken()
+	if err != nil {
+		return "", err
+	}
+	client, err := r.httpClient()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := r.env("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Errors []string `json:"errors"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &body) == nil && len(body.Errors) > 0 {
+			msg = strings.Join(body.Errors, "; ")
+		}
+		return "", fmt.Errorf("vault: %s: %s", resp.Status, msg)
+	}
+
+	// KV v2 nests the secret's fields under data.data, next to
+	// data.metadata; KV v1 has them directly under data.
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("vault: decoding response: %w", err)
+	}
+	fields := secret.Data
+	if inner, ok := fields["data"].(map[string]any); ok {
+		if _, v2 := fields["metadata"]; v2 {
+			fields = inner
+		}
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("vault: secret has no field %q", field)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault: field %q is not a string", field)
+	}
+	return s, nil
+}
+
+// vaultToken returns VAULT_TOKEN, else the token in ~/.vault-token.
+func (r *Resolver) vaultToken() (string, error) {
+	if token := r.env("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+		if err == nil && strings.TrimSpace(string(data)) != "" {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	return "", errors.New("VAULT_TOKEN is not set and there is no ~/.vault-token")
+}
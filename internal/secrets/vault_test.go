@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve_Vault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		assert.Equal(t, "team-a", req.Header.Get("X-Vault-Namespace"))
+		switch req.URL.Path {
+		case "/v1/secret/data/gh":
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"ghp_v2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/gh":
+			_, _ = w.Write([]byte(`{"data":{"token":"ghp_v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer srv.Close()
+	env := map[string]string{"VAULT_ADDR": srv.URL, "VAULT_TOKEN": "s.token", "VAULT_NAMESPACE": "team-a"}
+	r := newTestResolver(env)
+	ctx := context.Background()
+
+	value, err := r.Resolve(ctx, "vault:secret/data/gh#token")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_v2", value, "KV v2")
+
+	value, err = r.Resolve(ctx, "vault:kv/gh#token")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_v1", value, "KV v1")
+
+	_, err = r.Resolve(ctx, "vault:secret/data/gh#missing")
+	assert.ErrorContains(t, err, `resolving vault:secret/data/gh#missing: vault: secret has no field "missing"`)
+
+	_, err = r.Resolve(ctx, "vault:secret/data/other#token")
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	_, err = r.Resolve(ctx, "vault:secret/data/gh")
+	assert.ErrorContains(t, err, "want vault:<path>#<field>")
+
+	env["VAULT_TOKEN"] = "s.wrong"
+	_, err = r.Resolve(ctx, "vault:secret/data/gh#token")
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+func TestResolve_VaultNotConfigured(t *testing.T) {
+	_, err := newTestResolver(nil).Resolve(context.Background(), "vault:secret/data/gh#token")
+	assert.ErrorContains(t, err, "VAULT_ADDR is not set")
+}
@@ -0,0 +1,115 @@
+// Package state persists the scaler's runner state -- which runners it
+// holds, their engine ids and whether they are busy -- so that a process
+// restarted after a crash can re-adopt the runners of the one before it
+// instead of leaking them and provisioning duplicates.
+//
+// The state is small (one entry per runner) and rewritten as a whole on
+// every change, so it is kept in a single JSON file replaced atomically
+// (write to a temporary file, fsync, rename) rather than in a database.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// version is the format version written to the file.  Load rejects files
+// written by a newer format.
+const version = 1
+
+// Runner is the persisted state of one runner.
+type Runner struct {
+	// ID is the engine id, as accepted by DestroyRunner.
+	ID string `json:"id"`
+	// Busy reports that the runner was running a job.
+	Busy bool `json:"busy,omitempty"`
+	// RunID is the run ID of the process that started the runner, whose
+	// labels its resource carries.
+	RunID string `json:"run_id,omitempty"`
+	// CreatedAt is when the runner was started, or first tracked if it
+	// was adopted, for the max runner lifetime.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+// Snapshot is the runner state of a scaler at one point in time.
+type Snapshot struct {
+	Version int `json:"version"`
+	// Runners are keyed by runner name.
+	Runners map[string]Runner `json:"runners"`
+}
+
+// File stores a Snapshot in a JSON file.
+type File struct {
+	path string
+}
+
+// NewFile returns a File that stores its snapshot at path.  The file and
+// its directory are created on the first Save.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Load reads the snapshot.  It returns nil and no error when the file
+// does not exist yet.
+func (f *File) Load() (*Snapshot, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parse state %s: %w", f.path, err)
+	}
+	if snap.Version > version {
+		return nil, fmt.Errorf("state %s has version %d, newer than supported version %d", f.path, snap.Version, version)
+	}
+	return &snap, nil
+}
+
+// Save replaces the file with snap.  A crash during Save leaves either
+// the previous snapshot or the new one, never a partial file.
+func (f *File) Save(snap *Snapshot) error {
+	out := Snapshot{Version: version, Runners: snap.Runners}
+	if out.Runners == nil {
+		out.Runners = map[string]Runner{}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create state file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("replace state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_LoadMissing(t *testing.T) {
+	snap, err := NewFile(filepath.Join(t.TempDir(), "state.json")).Load()
+	require.NoError(t, err)
+	assert.Nil(t, snap)
+}
+
+func TestFile_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	f := NewFile(path)
+
+	want := map[string]Runner{
+		"runner-a": {ID: "c1", RunID: "run-1"},
+		"runner-b": {ID: "c2", Busy: true, RunID: "run-0"},
+	}
+	require.NoError(t, f.Save(&Snapshot{Runners: want}))
+
+	snap, err := f.Load()
+	require.NoError(t, err)
+	assert.Equal(t, version, snap.Version)
+	assert.Equal(t, want, snap.Runners)
+
+	// Save replaces the whole snapshot and leaves no temporary files.
+	require.NoError(t, f.Save(&Snapshot{}))
+	snap, err = f.Load()
+	require.NoError(t, err)
+	assert.Empty(t, snap.Runners)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "state.json", entries[0].Name())
+}
+
+func TestFile_LoadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte("{"), 0o600))
+	_, err := NewFile(corrupt).Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parse state")
+
+	newer := filepath.Join(dir, "newer.json")
+	require.NoError(t, os.WriteFile(newer, []byte(`{"version":99,"runners":{}}`), 0o600))
+	_, err = NewFile(newer).Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "newer than supported version 1")
+}
@@ -0,0 +1,385 @@
+// Package webhook scales the running scale sets from GitHub workflow_job
+// webhooks, next to or instead of the listener's long-poll message
+// session.  Installs behind a firewall that already relay GitHub
+// webhooks to an internal endpoint learn of a queued job as soon as it
+// is queued, without waiting for the next session message.
+//
+// Each delivery is authenticated with its X-Hub-Signature-256 HMAC and
+// routed to the scale set whose labels cover the job's runs-on labels.
+// The receiver keeps, per scale set, the jobs seen queued or running.
+// Scaling on webhooks alone, it reports their number as the desired
+// runner count, and jobs starting and completing on the scale set's
+// runners, as the listener does.  Next to a message session, which stays
+// the authority, it only ever raises the desired count.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actions/scaleset"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// maxBodySize is the largest payload GitHub delivers.
+const maxBodySize = 25 << 20
+
+// jobTTL is how long a job is counted without a delivery about it, and
+// how long a completed job is remembered.  GitHub cancels jobs queued
+// for longer, so a job past it has had its completed delivery lost; a
+// running job's in_progress delivery starts it again.  A completed job
+// is remembered so that a late or redelivered queued event for it does
+// not count it again.
+const jobTTL = 24 * time.Hour
+
+// expireInterval is how often a scale set's jobs are checked for expiry.
+const expireInterval = time.Minute
+
+// queueSize bounds the deliveries waiting for a scale set's worker.
+// Beyond it deliveries are refused with 503 so GitHub records them as
+// failed and they can be redelivered.
+const queueSize = 1024
+
+// Scaler is the subset of *scaler.Scaler the receiver drives: the
+// listener's, plus RaiseDesiredRunnerCount for scaling next to a message
+// session.
+type Scaler interface {
+	HandleJobStarted(ctx context.Context, jobInfo *scaleset.JobStarted) error
+	HandleJobCompleted(ctx context.Context, jobInfo *scaleset.JobCompleted) error
+	HandleDesiredRunnerCount(ctx context.Context, count int) (int, error)
+	RaiseDesiredRunnerCount(ctx context.Context, count int) (int, error)
+}
+
+// Event is the part of a workflow_job webhook payload the receiver uses.
+type Event struct {
+	Action      string      `json:"action"`
+	WorkflowJob WorkflowJob `json:"workflow_job"`
+	Repository  struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// WorkflowJob is the workflow_job object of an Event.
+type WorkflowJob struct {
+	ID           int64     `json:"id"`
+	RunID        int64     `json:"run_id"`
+	Name         string    `json:"name"`
+	WorkflowName string    `json:"workflow_name"`
+	Labels       []string  `json:"labels"`
+	RunnerID     int       `json:"runner_id"`
+	RunnerName   string    `json:"runner_name"`
+	Conclusion   string    `json:"conclusion"`
+	CreatedAt    time.Time `json:"created_at"`
+	StartedAt    time.Time `json:"started_at"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// Receiver serves the webhook endpoint for the scale sets registered
+// with it.
+type Receiver struct {
+	secret []byte
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	scaleSets []*scaleSet // in registration order; the first match wins
+
+	deliveries metric.Int64Counter
+}
+
+// scaleSet is a registered scale set.  Its deliveries are processed in
+// order by one worker, so a slow scale-up delays neither the response to
+// GitHub nor the other scale sets.
+type scaleSet struct {
+	name   string
+	labels map[string]bool // lowercased
+	scaler Scaler
+	only   bool // no message session
+
+	queue chan Event
+
+	// jobs holds the jobs queued or running and completed the jobs
+	// completed, each with the time of its last delivery (owned by the
+	// worker).  Both expire after jobTTL.
+	jobs      map[int64]time.Time
+	completed map[int64]time.Time
+	expiredAt time.Time
+}
+
+// New creates a Receiver that accepts deliveries signed with secret.
+func New(secret string, logger *slog.Logger) *Receiver {
+	r := &Receiver{secret: []byte(secret), logger: logger, now: time.Now}
+	var err error
+	r.deliveries, err = otel.Meter("scaleset").Int64Counter(
+		"scaleset.webhook.deliveries",
+		metric.WithDescription("Total number of webhook deliveries received"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		logger.Warn("failed to create webhook deliveries counter", slog.String("error", err.Error()))
+	}
+	return r
+}
+
+// Register routes the workflow_job events whose runs-on labels are all
+// among labels to s until the returned stop func is called.  The stop
+// func waits for the delivery in progress, so it must be called before
+// the scaler shuts down.  With only set, s scales on webhooks alone:
+// the job count is reported with HandleDesiredRunnerCount, and jobs
+// starting and completing on a runner as JobStarted and JobCompleted.
+// Otherwise a message session reports all that already, and a job
+// count grown by a delivery is only passed to RaiseDesiredRunnerCount.
+func (r *Receiver) Register(ctx context.Context, name string, labels []string, s Scaler, only bool) (stop func()) {
+	ss := &scaleSet{
+		name:      name,
+		labels:    make(map[string]bool, len(labels)),
+		scaler:    s,
+		only:      only,
+		queue:     make(chan Event, queueSize),
+		jobs:      make(map[int64]time.Time),
+		completed: make(map[int64]time.Time),
+	}
+	for _, l := range labels {
+		ss.labels[strings.ToLower(l)] = true
+	}
+
+	r.mu.Lock()
+	r.scaleSets = append(r.scaleSets, ss)
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev := <-ss.queue:
+				r.process(ctx, ss, ev)
+			}
+		}
+	}()
+	return func() {
+		r.mu.Lock()
+		r.scaleSets = slices.DeleteFunc(r.scaleSets, func(x *scaleSet) bool { return x == ss })
+		r.mu.Unlock()
+		cancel()
+		<-done
+	}
+}
+
+// ServeHTTP accepts a webhook delivery: it checks the signature, queues
+// workflow_job events for the matching scale set and responds at once,
+// well within GitHub's delivery timeout.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+	if err != nil || len(body) > maxBodySize {
+		r.count(req.Context(), "rejected")
+		http.Error(w, "unreadable or oversized body", http.StatusBadRequest)
+		return
+	}
+	if !r.validSignature(body, req.Header.Get("X-Hub-Signature-256")) {
+		r.count(req.Context(), "rejected")
+		r.logger.Warn("webhook: rejected delivery with a missing or invalid signature",
+			slog.String("delivery", req.Header.Get("X-GitHub-Delivery")),
+			slog.String("remote", req.RemoteAddr),
+		)
+		http.Error(w, "missing or invalid X-Hub-Signature-256", http.StatusUnauthorized)
+		return
+	}
+
+	switch event := req.Header.Get("X-GitHub-Event"); event {
+	case "workflow_job":
+	case "ping":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		r.count(req.Context(), "ignored")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var ev Event
+	if err := json.Unmarshal(body, &ev); err != nil {
+		r.count(req.Context(), "rejected")
+		http.Error(w, fmt.Sprintf("invalid payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	ss := r.match(ev.WorkflowJob.Labels)
+	if ss == nil {
+		r.count(req.Context(), "ignored")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	select {
+	case ss.queue <- ev:
+		r.count(req.Context(), "accepted")
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		r.count(req.Context(), "dropped")
+		r.logger.Warn("webhook: queue full, refusing delivery",
+			slog.String("scale_set", ss.name),
+			slog.String("delivery", req.Header.Get("X-GitHub-Delivery")),
+		)
+		http.Error(w, "too many deliveries queued", http.StatusServiceUnavailable)
+	}
+}
+
+// validSignature reports whether header is the "sha256=<hex>" HMAC of
+// body under the receiver's secret.
+func (r *Receiver) validSignature(body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// match returns the first registered scale set whose labels include
+// every one of labels, or nil.
+func (r *Receiver) match(labels []string) *scaleSet {
+	if len(labels) == 0 {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, ss := range r.scaleSets {
+		if !slices.ContainsFunc(labels, func(l string) bool { return !ss.labels[strings.ToLower(l)] }) {
+			return ss
+		}
+	}
+	return nil
+}
+
+// process applies one event to ss.  Scaler errors are logged: GitHub
+// cannot retry a delivery that was already acknowledged, and the next
+// event or session message corrects the count.
+func (r *Receiver) process(ctx context.Context, ss *scaleSet, ev Event) {
+	job := ev.WorkflowJob
+	logger := r.logger.With(
+		slog.String("scale_set", ss.name),
+		slog.String("action", ev.Action),
+		slog.Int64("job_id", job.ID),
+		slog.String("runner", job.RunnerName),
+	)
+	logger.Debug("webhook: workflow_job event")
+
+	now := r.now()
+	expired := ss.expire(now)
+	_, known := ss.jobs[job.ID]
+
+	switch ev.Action {
+	case "queued", "in_progress":
+		if _, done := ss.completed[job.ID]; done {
+			logger.Debug("webhook: ignoring event for a completed job")
+			return
+		}
+		ss.jobs[job.ID] = now
+		if ev.Action == "in_progress" && ss.only && job.RunnerName != "" {
+			if err := ss.scaler.HandleJobStarted(ctx, &scaleset.JobStarted{
+				RunnerID:       job.RunnerID,
+				RunnerName:     job.RunnerName,
+				JobMessageBase: jobMessage(ev),
+			}); err != nil {
+				logger.Warn("webhook: handling job started failed", slog.String("error", err.Error()))
+			}
+		}
+		if known && !expired {
+			return
+		}
+	case "completed":
+		delete(ss.jobs, job.ID)
+		ss.completed[job.ID] = now
+		if ss.only && job.RunnerName != "" {
+			if err := ss.scaler.HandleJobCompleted(ctx, &scaleset.JobCompleted{
+				Result:         job.Conclusion,
+				RunnerID:       job.RunnerID,
+				RunnerName:     job.RunnerName,
+				JobMessageBase: jobMessage(ev),
+			}); err != nil {
+				logger.Warn("webhook: handling job completed failed", slog.String("error", err.Error()))
+			}
+		}
+		if !ss.only {
+			return // the session lowers the count
+		}
+	default:
+		return // e.g. "waiting" for a deployment approval
+	}
+
+	report := ss.scaler.RaiseDesiredRunnerCount
+	if ss.only {
+		report = ss.scaler.HandleDesiredRunnerCount
+	}
+	if _, err := report(ctx, len(ss.jobs)); err != nil && ctx.Err() == nil {
+		logger.Warn("webhook: scaling failed", slog.String("error", err.Error()))
+	}
+}
+
+// expire forgets the jobs and completed jobs last seen more than jobTTL
+// before now, at most once per expireInterval.  It reports whether a job
+// was forgotten, which changes the job count.
+func (ss *scaleSet) expire(now time.Time) bool {
+	if now.Sub(ss.expiredAt) < expireInterval {
+		return false
+	}
+	ss.expiredAt = now
+	cutoff := now.Add(-jobTTL)
+	stale := func(_ int64, seen time.Time) bool { return seen.Before(cutoff) }
+	n := len(ss.jobs)
+	maps.DeleteFunc(ss.jobs, stale)
+	maps.DeleteFunc(ss.completed, stale)
+	return len(ss.jobs) < n
+}
+
+// jobMessage returns the job fields of ev the listener's messages carry.
+func jobMessage(ev Event) scaleset.JobMessageBase {
+	job := ev.WorkflowJob
+	return scaleset.JobMessageBase{
+		RepositoryName:   ev.Repository.Name,
+		OwnerName:        ev.Repository.Owner.Login,
+		JobID:            strconv.FormatInt(job.ID, 10),
+		JobDisplayName:   job.Name,
+		WorkflowRunID:    job.RunID,
+		RequestLabels:    job.Labels,
+		QueueTime:        job.CreatedAt,
+		RunnerAssignTime: job.StartedAt,
+		FinishTime:       job.CompletedAt,
+	}
+}
+
+func (r *Receiver) count(ctx context.Context, result string) {
+	if r.deliveries != nil {
+		r.deliveries.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+	}
+}
@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/scaleset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "It's a Secret to Everybody"
+
+// fakeScaler sends each call it receives to calls.
+type fakeScaler struct{ calls chan string }
+
+func newFakeScaler() *fakeScaler { return &fakeScaler{calls: make(chan string, 16)} }
+
+func (f *fakeScaler) HandleJobStarted(_ context.Context, j *scaleset.JobStarted) error {
+	f.calls <- fmt.Sprintf("started %s %s", j.RunnerName, j.JobID)
+	return nil
+}
+
+func (f *fakeScaler) HandleJobCompleted(_ context.Context, j *scaleset.JobCompleted) error {
+	f.calls <- fmt.Sprintf("completed %s %s %s", j.RunnerName, j.JobID, j.Result)
+	return nil
+}
+
+func (f *fakeScaler) HandleDesiredRunnerCount(_ context.Context, count int) (int, error) {
+	f.calls <- fmt.Sprintf("desired %d", count)
+	return count, nil
+}
+
+func (f *fakeScaler) RaiseDesiredRunnerCount(_ context.Context, count int) (int, error) {
+	f.calls <- fmt.Sprintf("raise %d", count)
+	return count, nil
+}
+
+func (f *fakeScaler) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case c := <-f.calls:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a scaler call")
+		return ""
+	}
+}
+
+func newReceiver() *Receiver {
+	return New(secret, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliver(t *testing.T, r *Receiver, event, body, signature string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", signature)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func jobEvent(action string, id int64, runner string, labels ...string) string {
+	return fmt.Sprintf(`{"action":%q,"workflow_job":{"id":%d,"run_id":7,"labels":["%s"],"runner_name":%q,"conclusion":"success"},"repository":{"name":"app","owner":{"login":"acme"}}}`,
+		action, id, strings.Join(labels, `","`), runner)
+}
+
+func TestServeHTTP_RejectsBadSignatures(t *testing.T) {
+	r := newReceiver()
+	body := jobEvent("queued", 1, "", "linux")
+	for name, sig := range map[string]string{
+		"missing":   "",
+		"no prefix": strings.TrimPrefix(sign(body), "sha256="),
+		"not hex":   "sha256=zz",
+		"wrong":     sign(body + " "),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, http.StatusUnauthorized, deliver(t, r, "workflow_job", body, sig).Code)
+		})
+	}
+}
+
+func TestServeHTTP_OnlyAcceptsPOST(t *testing.T) {
+	w := httptest.NewRecorder()
+	newReceiver().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/webhook", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+}
+
+func TestServeHTTP_IgnoresPingsOtherEventsAndUnmatchedJobs(t *testing.T) {
+	r := newReceiver()
+	s := newFakeScaler()
+	stop := r.Register(t.Context(), "linux", []string{"linux"}, s, false)
+	defer stop()
+
+	ping := `{"zen":"Keep it logically awesome."}`
+	assert.Equal(t, http.StatusNoContent, deliver(t, r, "ping", ping, sign(ping)).Code)
+	push := `{"ref":"refs/heads/main"}`
+	assert.Equal(t, http.StatusNoContent, deliver(t, r, "push", push, sign(push)).Code)
+	other := jobEvent("queued", 1, "", "linux", "gpu")
+	assert.Equal(t, http.StatusNoContent, deliver(t, r, "workflow_job", other, sign(other)).Code)
+	assert.Empty(t, s.calls)
+}
+
+func TestServeHTTP_RejectsInvalidPayloads(t *testing.T) {
+	body := `{"action":`
+	assert.Equal(t, http.StatusBadRequest, deliver(t, newReceiver(), "workflow_job", body, sign(body)).Code)
+}
+
+func TestProcess_TracksQueuedAndRunningJobs(t *testing.T) {
+	r := newReceiver()
+	s := newFakeScaler()
+	stop := r.Register(t.Context(), "linux", []string{"self-hosted", "Linux"}, s, false)
+	defer stop()
+
+	send := func(body string) {
+		t.Helper()
+		require.Equal(t, http.StatusAccepted, deliver(t, r, "workflow_job", body, sign(body)).Code)
+	}
+	send(jobEvent("queued", 1, "", "self-hosted", "linux"))
+	assert.Equal(t, "raise 1", s.next(t))
+	send(jobEvent("queued", 2, "", "linux"))
+	assert.Equal(t, "raise 2", s.next(t))
+	// Next to a session, which reports jobs starting and completing and
+	// lowers the count, only a job not seen before raises it.
+	send(jobEvent("in_progress", 1, "runner-a", "linux"))
+	send(jobEvent("waiting", 3, "", "linux"))
+	send(jobEvent("completed", 1, "runner-a", "linux"))
+	send(jobEvent("completed", 2, "", "linux"))
+	send(jobEvent("in_progress", 4, "runner-b", "linux"))
+	assert.Equal(t, "raise 1", s.next(t))
+	assert.Empty(t, s.calls)
+}
+
+func TestProcess_IgnoresLateEventsForCompletedJobs(t *testing.T) {
+	r := newReceiver()
+	s := newFakeScaler()
+	stop := r.Register(t.Context(), "linux", []string{"linux"}, s, true)
+	defer stop()
+
+	for _, body := range []string{
+		jobEvent("completed", 1, "", "linux"),
+		jobEvent("queued", 1, "", "linux"), // delivered late
+		jobEvent("queued", 2, "", "linux"),
+	} {
+		require.Equal(t, http.StatusAccepted, deliver(t, r, "workflow_job", body, sign(body)).Code)
+	}
+	assert.Equal(t, "desired 0", s.next(t))
+	assert.Equal(t, "desired 1", s.next(t))
+}
+
+func TestProcess_ExpiresJobs(t *testing.T) {
+	r := newReceiver()
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	s := newFakeScaler()
+	stop := r.Register(t.Context(), "linux", []string{"linux"}, s, true)
+	defer stop()
+
+	send := func(body string) {
+		t.Helper()
+		require.Equal(t, http.StatusAccepted, deliver(t, r, "workflow_job", body, sign(body)).Code)
+	}
+	send(jobEvent("queued", 1, "", "linux"))
+	assert.Equal(t, "desired 1", s.next(t))
+	send(jobEvent("queued", 2, "", "linux"))
+	assert.Equal(t, "desired 2", s.next(t))
+
+	// Job 1's completed delivery is lost; job 2 keeps being delivered.
+	now = now.Add(jobTTL / 2)
+	send(jobEvent("in_progress", 2, "runner-a", "linux"))
+	assert.Equal(t, "started runner-a 2", s.next(t))
+	now = now.Add(jobTTL/2 + time.Minute)
+	send(jobEvent("queued", 3, "", "linux"))
+	assert.Equal(t, "desired 2", s.next(t), "job 1 expired, jobs 2 and 3 count")
+}
+
+func TestProcess_ReportsJobEvents(t *testing.T) {
+	r := newReceiver()
+	s := newFakeScaler()
+	stop := r.Register(t.Context(), "linux", []string{"linux"}, s, true)
+	defer stop()
+
+	for _, body := range []string{
+		jobEvent("queued", 1, "", "linux"),
+		jobEvent("in_progress", 1, "runner-a", "linux"),
+		jobEvent("completed", 1, "runner-a", "linux"),
+	} {
+		require.Equal(t, http.StatusAccepted, deliver(t, r, "workflow_job", body, sign(body)).Code)
+	}
+	assert.Equal(t, "desired 1", s.next(t))
+	assert.Equal(t, "started runner-a 1", s.next(t))
+	assert.Equal(t, "completed runner-a 1 success", s.next(t))
+	assert.Equal(t, "desired 0", s.next(t))
+}
+
+func TestRegister_FirstMatchingScaleSetWins(t *testing.T) {
+	r := newReceiver()
+	linux, gpu := newFakeScaler(), newFakeScaler()
+	stopGPU := r.Register(t.Context(), "gpu", []string{"linux", "gpu"}, gpu, false)
+	stopLinux := r.Register(t.Context(), "linux", []string{"linux"}, linux, false)
+	defer stopLinux()
+
+	body := jobEvent("queued", 1, "", "linux")
+	require.Equal(t, http.StatusAccepted, deliver(t, r, "workflow_job", body, sign(body)).Code)
+	assert.Equal(t, "raise 1", gpu.next(t))
+
+	// Once unregistered, gpu no longer receives events.
+	stopGPU()
+	body = jobEvent("queued", 2, "", "linux", "gpu")
+	assert.Equal(t, http.StatusNoContent, deliver(t, r, "workflow_job", body, sign(body)).Code)
+	body = jobEvent("queued", 3, "", "linux")
+	require.Equal(t, http.StatusAccepted, deliver(t, r, "workflow_job", body, sign(body)).Code)
+	assert.Equal(t, "raise 1", linux.next(t))
+	assert.Empty(t, gpu.calls)
+}
@@ -0,0 +1,260 @@
+// Package plugin lets third parties ship scaleset engines as standalone
+// binaries.  scaleset starts the binary named by engine.plugin.path and
+// talks to it over gRPC on a unix socket; the binary implements Engine
+// and calls Serve from its main function:
+//
+//	func main() {
+//		err := plugin.Serve(func(ctx context.Context, s plugin.Settings) (plugin.Engine, error) {
+//			return newMyEngine(s.Config, s.Labels)
+//		})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The protocol is described in engine.proto.  Its messages are protobuf
+// well-known types, so plugins can be written in any language with gRPC
+// support.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ProtocolVersion is the version of the plugin protocol.  It changes
+// when the protocol changes incompatibly.
+const ProtocolVersion = 1
+
+// Environment variables scaleset starts a plugin with.
+const (
+	// SocketEnv is the path of the unix socket the plugin listens on.
+	SocketEnv = "SCALESET_PLUGIN_SOCKET"
+	// ProtocolEnv is the ProtocolVersion scaleset speaks.
+	ProtocolEnv = "SCALESET_PLUGIN_PROTOCOL"
+)
+
+// Engine is the contract of scaleset's engines: it starts and destroys
+// ephemeral runners.  All methods may be called concurrently.
+type Engine interface {
+	// StartRunner starts a runner that runs the GitHub Actions runner
+	// with the base64 JIT config jitConfig, and returns an ID that
+	// DestroyRunner accepts.  name is unique among live runners.
+	StartRunner(ctx context.Context, name, jitConfig string) (string, error)
+	// DestroyRunner permanently destroys a runner.  Destroying a runner
+	// that is already gone is not an error.
+	DestroyRunner(ctx context.Context, id string) error
+	// Shutdown destroys every runner the engine started.
+	Shutdown(ctx context.Context) error
+}
+
+// Checker is optionally implemented by an Engine to report whether its
+// backend is healthy, for scaleset's readiness probe.
+type Checker interface {
+	Check(ctx context.Context) (map[string]string, error)
+}
+
+// Settings configure a plugin's engine.
+type Settings struct {
+	// Config is engine.plugin.config of the scaleset configuration.
+	Config map[string]any
+	// RunID identifies the scaleset process.
+	RunID string
+	// Labels should be set on everything the engine creates, so
+	// leftovers of a crashed process can be found.
+	Labels map[string]string
+}
+
+func (s Settings) toStruct() (*structpb.Struct, error) {
+	labels := make(map[string]any, len(s.Labels))
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	config := s.Config
+	if config == nil {
+		config = map[string]any{}
+	}
+	st, err := structpb.NewStruct(map[string]any{
+		"config": config,
+		"run_id": s.RunID,
+		"labels": labels,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("plugin config: %w", err)
+	}
+	return st, nil
+}
+
+func settingsFromStruct(st *structpb.Struct) Settings {
+	f := st.GetFields()
+	labels := make(map[string]string)
+	for k, v := range f["labels"].GetStructValue().GetFields() {
+		labels[k] = v.GetStringValue()
+	}
+	return Settings{
+		Config: f["config"].GetStructValue().AsMap(),
+		RunID:  f["run_id"].GetStringValue(),
+		Labels: labels,
+	}
+}
+
+// Factory creates the plugin's engine from the settings scaleset sends.
+type Factory func(ctx context.Context, settings Settings) (Engine, error)
+
+// Serve serves the engine factory creates until scaleset shuts the
+// plugin down or the process receives SIGTERM or SIGINT.  It returns an
+// error if the binary was not started by scaleset.
+func Serve(factory Factory) error {
+	socket := os.Getenv(SocketEnv)
+	if socket == "" {
+		return fmt.Errorf("%s is not set: this is a scaleset engine plugin, set engine.plugin.path to run it", SocketEnv)
+	}
+	if v := os.Getenv(ProtocolEnv); v != strconv.Itoa(ProtocolVersion) {
+		return fmt.Errorf("scaleset speaks plugin protocol %q, this plugin speaks %d", v, ProtocolVersion)
+	}
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	return serve(ctx, lis, factory)
+}
+
+// serve serves on lis until ctx is done or Shutdown was called.
+func serve(ctx context.Context, lis net.Listener, factory Factory) error {
+	srv := &server{factory: factory, done: make(chan struct{})}
+	gs := grpc.NewServer()
+	gs.RegisterService(&serviceDesc, srv)
+
+	errc := make(chan error, 1)
+	go func() { errc <- gs.Serve(lis) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-srv.done:
+	case <-ctx.Done():
+	}
+	gs.GracefulStop()
+	return nil
+}
+
+// server implements rpcServer on the engine created by Configure.
+type server struct {
+	factory Factory
+
+	mu       sync.Mutex
+	engine   Engine
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (s *server) getEngine() (Engine, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.engine == nil {
+		return nil, status.Error(codes.FailedPrecondition, "plugin is not configured")
+	}
+	return s.engine, nil
+}
+
+func (s *server) configure(ctx context.Context, req *structpb.Struct) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.engine != nil {
+		return nil, status.Error(codes.FailedPrecondition, "plugin is already configured")
+	}
+	eng, err := s.factory(ctx, settingsFromStruct(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	s.engine = eng
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) startRunner(ctx context.Context, req *structpb.Struct) (*wrapperspb.StringValue, error) {
+	eng, err := s.getEngine()
+	if err != nil {
+		return nil, err
+	}
+	f := req.GetFields()
+	id, err := eng.StartRunner(ctx, f["name"].GetStringValue(), f["jit_config"].GetStringValue())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return wrapperspb.String(id), nil
+}
+
+func (s *server) destroyRunner(ctx context.Context, req *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	eng, err := s.getEngine()
+	if err != nil {
+		return nil, err
+	}
+	if err := eng.DestroyRunner(ctx, req.GetValue()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) shutdown(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	defer s.doneOnce.Do(func() { close(s.done) })
+	s.mu.Lock()
+	eng := s.engine
+	s.mu.Unlock()
+	if eng == nil {
+		return &emptypb.Empty{}, nil
+	}
+	if err := eng.Shutdown(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) check(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	eng, err := s.getEngine()
+	if err != nil {
+		return nil, err
+	}
+	checker, ok := eng.(Checker)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "engine has no check")
+	}
+	diags, err := checker.Check(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	st := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(diags))}
+	for k, v := range diags {
+		st.Fields[k] = structpb.NewStringValue(v)
+	}
+	return st, nil
+}
+
+// toStatus converts an engine error to a gRPC status, keeping the
+// status of errors that already have one and mapping context errors.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
@@ -0,0 +1,157 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type fakeEngine struct {
+	mu        sync.Mutex
+	started   map[string]string
+	destroyed []string
+	shutdown  bool
+	startErr  error
+}
+
+func (f *fakeEngine) StartRunner(_ context.Context, name, jitConfig string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.startErr != nil {
+		return "", f.startErr
+	}
+	f.started[name] = jitConfig
+	return "id-" + name, nil
+}
+
+func (f *fakeEngine) DestroyRunner(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.destroyed = append(f.destroyed, id)
+	return nil
+}
+
+func (f *fakeEngine) Shutdown(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.shutdown = true
+	return nil
+}
+
+type checkingEngine struct{ *fakeEngine }
+
+func (checkingEngine) Check(context.Context) (map[string]string, error) {
+	return map[string]string{"fake.backend": "ok"}, nil
+}
+
+// startServer serves factory on a unix socket and returns a client and
+// the error channel of serve.
+func startServer(t *testing.T, factory Factory) (*Client, <-chan error) {
+	t.Helper()
+	lis, err := net.Listen("unix", filepath.Join(t.TempDir(), "plugin.sock"))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	errc := make(chan error, 1)
+	go func() { errc <- serve(ctx, lis, factory) }()
+
+	conn, err := grpc.NewClient("unix://"+lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewClient(conn), errc
+}
+
+func TestServe_EngineContract(t *testing.T) {
+	eng := &fakeEngine{started: make(map[string]string)}
+	var got Settings
+	client, errc := startServer(t, func(_ context.Context, s Settings) (Engine, error) {
+		got = s
+		return eng, nil
+	})
+	ctx := t.Context()
+
+	_, err := client.StartRunner(ctx, "runner-a", "jit")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "calls before Configure fail")
+
+	require.NoError(t, client.Configure(ctx, Settings{
+		Config: map[string]any{"region": "eu", "size": 2, "tags": []any{"a"}},
+		RunID:  "run-1",
+		Labels: map[string]string{"scaleset-managed": "true"},
+	}))
+	assert.Equal(t, map[string]any{"region": "eu", "size": float64(2), "tags": []any{"a"}}, got.Config)
+	assert.Equal(t, "run-1", got.RunID)
+	assert.Equal(t, map[string]string{"scaleset-managed": "true"}, got.Labels)
+
+	id, err := client.StartRunner(ctx, "runner-a", "aml0")
+	require.NoError(t, err)
+	assert.Equal(t, "id-runner-a", id)
+	assert.Equal(t, map[string]string{"runner-a": "aml0"}, eng.started)
+
+	require.NoError(t, client.DestroyRunner(ctx, id))
+	assert.Equal(t, []string{"id-runner-a"}, eng.destroyed)
+
+	diags, err := client.Check(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, diags, "an engine without a check reports nothing")
+
+	require.NoError(t, client.Shutdown(ctx))
+	assert.True(t, eng.shutdown)
+	require.NoError(t, <-errc, "serve returns after Shutdown")
+}
+
+func TestServe_Errors(t *testing.T) {
+	eng := &fakeEngine{started: make(map[string]string), startErr: errors.New("quota exceeded for cpus")}
+	client, _ := startServer(t, func(context.Context, Settings) (Engine, error) {
+		return checkingEngine{eng}, nil
+	})
+	ctx := t.Context()
+	require.NoError(t, client.Configure(ctx, Settings{}))
+
+	_, err := client.StartRunner(ctx, "runner-a", "jit")
+	require.Error(t, err)
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.Contains(t, err.Error(), "quota exceeded for cpus")
+
+	eng.startErr = status.Error(codes.ResourceExhausted, "no capacity")
+	_, err = client.StartRunner(ctx, "runner-a", "jit")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "status errors keep their code")
+
+	err = client.Configure(ctx, Settings{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	diags, err := client.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"fake.backend": "ok"}, diags)
+}
+
+func TestServe_ConfigureError(t *testing.T) {
+	client, _ := startServer(t, func(context.Context, Settings) (Engine, error) {
+		return nil, errors.New("config.region is required")
+	})
+	err := client.Configure(t.Context(), Settings{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config.region is required")
+}
+
+func TestServe_RequiresScaleset(t *testing.T) {
+	t.Setenv(SocketEnv, "")
+	err := Serve(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "engine.plugin.path")
+
+	t.Setenv(SocketEnv, filepath.Join(t.TempDir(), "plugin.sock"))
+	t.Setenv(ProtocolEnv, "0")
+	err = Serve(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "protocol")
+}
@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ServiceName is the gRPC service a plugin serves, see engine.proto.
+const ServiceName = "scaleset.engine.v1.Engine"
+
+// rpcServer is the server side of the service.  It is the HandlerType of
+// serviceDesc.
+type rpcServer interface {
+	configure(ctx context.Context, req *structpb.Struct) (*emptypb.Empty, error)
+	startRunner(ctx context.Context, req *structpb.Struct) (*wrapperspb.StringValue, error)
+	destroyRunner(ctx context.Context, req *wrapperspb.StringValue) (*emptypb.Empty, error)
+	shutdown(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error)
+	check(ctx context.Context, req *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// serviceDesc describes the service to grpc.  The messages are protobuf
+// well-known types, so no generated code is needed on either side.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*rpcServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("Configure", rpcServer.configure),
+		unary("StartRunner", rpcServer.startRunner),
+		unary("DestroyRunner", rpcServer.destroyRunner),
+		unary("Shutdown", rpcServer.shutdown),
+		unary("Check", rpcServer.check),
+	},
+	Metadata: "engine.proto",
+}
+
+// unary returns the descriptor of a unary method that decodes a Req and
+// calls call on the registered rpcServer.
+func unary[Req any, PReq interface {
+	*Req
+	proto.Message
+}, Resp proto.Message](name string, call func(rpcServer, context.Context, PReq) (Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := PReq(new(Req))
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(rpcServer), ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + name}
+			return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return call(srv.(rpcServer), ctx, req.(PReq))
+			})
+		},
+	}
+}
+
+// Client calls a plugin over a gRPC connection.  scaleset uses it; it
+// is exported so plugins can be tested end to end.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient returns a client that calls the plugin served on conn.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp proto.Message) error {
+	return c.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp)
+}
+
+// Configure creates the plugin's engine from settings.  It must be the
+// first call.
+func (c *Client) Configure(ctx context.Context, settings Settings) error {
+	req, err := settings.toStruct()
+	if err != nil {
+		return err
+	}
+	return c.invoke(ctx, "Configure", req, &emptypb.Empty{})
+}
+
+// StartRunner starts a runner and returns its ID.
+func (c *Client) StartRunner(ctx context.Context, name, jitConfig string) (string, error) {
+	req := &structpb.Struct{Fields: map[string]*structpb.Value{
+		"name":       structpb.NewStringValue(name),
+		"jit_config": structpb.NewStringValue(jitConfig),
+	}}
+	resp := &wrapperspb.StringValue{}
+	if err := c.invoke(ctx, "StartRunner", req, resp); err != nil {
+		return "", err
+	}
+	return resp.GetValue(), nil
+}
+
+// DestroyRunner destroys the runner with the given ID.
+func (c *Client) DestroyRunner(ctx context.Context, id string) error {
+	return c.invoke(ctx, "DestroyRunner", wrapperspb.String(id), &emptypb.Empty{})
+}
+
+// Shutdown destroys every runner of the plugin's engine.  The plugin
+// exits once it has answered.
+func (c *Client) Shutdown(ctx context.Context) error {
+	return c.invoke(ctx, "Shutdown", &emptypb.Empty{}, &emptypb.Empty{})
+}
+
+// Check returns the diagnostics of the plugin's engine, or nil and no
+// error if the engine has no check.
+func (c *Client) Check(ctx context.Context) (map[string]string, error) {
+	resp := &structpb.Struct{}
+	if err := c.invoke(ctx, "Check", &emptypb.Empty{}, resp); err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, nil
+		}
+		return nil, err
+	}
+	diags := make(map[string]string, len(resp.GetFields()))
+	for k, v := range resp.GetFields() {
+		diags[k] = v.GetStringValue()
+	}
+	return diags, nil
+}